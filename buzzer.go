@@ -0,0 +1,348 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	_ "embed"
+	"encoding/hex"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"golang.org/x/net/websocket"
+
+	"github.com/m-uesaka/quiz-yaml-go/quiz_yaml_converter"
+)
+
+// 早押しの画面（プレイヤー・司会者）のHTML
+//
+//go:embed ui/buzzer.html
+var buzzerPage []byte
+
+// 1人のクライアントへの書き込みを待つ時間
+const buzzerWriteTimeout = 5 * time.Second
+
+// 1人のクライアントに送る前のメッセージを溜めておく数．溢れたクライアントは送信が追いつかないものとして切断する
+const buzzerSendQueueSize = 16
+
+// buzzerMessage はクライアントから受け取るメッセージ．
+// typeはjoin（name），host（key），buzz，answer（text），next，reveal，judge（judgement）のいずれか．
+type buzzerMessage struct {
+	Type      string `json:"type"`
+	Name      string `json:"name,omitempty"`
+	Key       string `json:"key,omitempty"`
+	Text      string `json:"text,omitempty"`
+	Judgement string `json:"judgement,omitempty"`
+}
+
+// buzzerEvent はクライアントに送るメッセージ．
+// typeはstate（進行の状態）かerror（直前のメッセージを処理できなかった理由）．
+type buzzerEvent struct {
+	Type    string                           `json:"type"`
+	State   *quiz_yaml_converter.BuzzerState `json:"state,omitempty"`
+	Item    *quiz_yaml_converter.QuizItem    `json:"item,omitempty"` // 司会者にだけ送る，答えと正誤判定を含む出題中の問題
+	Message string                           `json:"message,omitempty"`
+}
+
+// buzzerClient は接続しているプレイヤーか司会者．
+// 送るメッセージはsendに溜め，writeLoopが順に書き込む．
+type buzzerClient struct {
+	conn *websocket.Conn
+	name string // プレイヤー名（司会者の場合は空）
+	host bool
+	send chan buzzerEvent
+}
+
+// buzzerServer は早押しの進行をWebSocketで接続したクライアントと共有する．
+type buzzerServer struct {
+	hostKey string
+	log     *slog.Logger
+
+	mu      sync.Mutex
+	game    *quiz_yaml_converter.BuzzerGame
+	clients map[*buzzerClient]struct{}
+}
+
+// runBuzzer はbuzzerサブコマンドを実行する．
+// 問題をWebSocketで接続したプレイヤーに配信し，早押し・締め出し・正誤判定を行うサーバーを起動する．
+func runBuzzer(args []string) {
+	fs := flag.NewFlagSet("buzzer", flag.ExitOnError)
+	var (
+		addr      = fs.String("addr", ":8080", T("待ち受けるアドレス"))
+		hostKey   = fs.String("host-key", "", T("司会者の画面を開くためのキー（未指定時は起動のたびに生成）"))
+		quiet     = fs.Bool("quiet", false, T("エラー以外のメッセージを出力しない"))
+		verbose   = fs.Bool("verbose", false, T("ボタンや解答などの操作ごとのログを出力する"))
+		logFormat = fs.String("log-format", logFormatText, T("メッセージの出力形式（text, json）"))
+	)
+	addLangFlag(fs)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, T("使用法: %s buzzer [オプション] quiz.yaml [quiz2.yaml ...]\n\n"), filepath.Base(os.Args[0]))
+		fmt.Fprint(os.Stderr, T("ブラウザから参加できる早押しクイズのサーバーを起動します。\n"))
+		fmt.Fprint(os.Stderr, T("最初にボタンを押したプレイヤーが解答権を得て，入力した解答を答え・読み・criteriaで判定します。\n"))
+		fmt.Fprint(os.Stderr, T("誤答したプレイヤーはその問題ではボタンを押せなくなります。\n"))
+		fmt.Fprint(os.Stderr, T("司会者は起動時に表示されるURLから，出題の進行と口頭の解答の判定を行います。\n\n"))
+		fmt.Fprint(os.Stderr, T("オプション:\n"))
+		fs.PrintDefaults()
+		fmt.Fprint(os.Stderr, T("\n例:\n"))
+		fmt.Fprintf(os.Stderr, "  %s buzzer -addr :8080 quiz.yaml\n", filepath.Base(os.Args[0]))
+	}
+	fs.Parse(args)
+
+	log, err := newLogger(os.Stderr, *logFormat, *quiet, *verbose)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, T("エラー: %v\n"), err)
+		os.Exit(exitUsage)
+	}
+	if fs.NArg() == 0 {
+		log.Error(T("出題するYAMLファイルを指定してください"))
+		fs.Usage()
+		os.Exit(exitUsage)
+	}
+
+	items, err := quiz_yaml_converter.LoadYAMLFiles(fs.Args())
+	if err != nil {
+		log.Error(T("YAMLファイルの読み込みに失敗しました"), "error", err)
+		os.Exit(exitCodeFor(err))
+	}
+	game, err := quiz_yaml_converter.NewBuzzerGame(items)
+	if err != nil {
+		log.Error(T("出題の準備に失敗しました"), "error", err)
+		os.Exit(exitUsage)
+	}
+	if *hostKey == "" {
+		*hostKey = newHostKey()
+	}
+	server := &buzzerServer{hostKey: *hostKey, log: log, game: game, clients: map[*buzzerClient]struct{}{}}
+
+	srv := &http.Server{Addr: *addr, Handler: server.mux(), ReadHeaderTimeout: 10 * time.Second}
+
+	// Ctrl+CやSIGTERMで，接続中のクライアントを切断してから終了する
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	srv.BaseContext = func(net.Listener) context.Context { return ctx }
+	go func() {
+		<-ctx.Done()
+		server.closeAll()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		srv.Shutdown(shutdownCtx)
+	}()
+
+	log.Info(fmt.Sprintf(T("%d問を出題できます"), len(items)), "questions", len(items))
+	log.Info(fmt.Sprintf(T("プレイヤーの画面: http://%s/"), displayAddr(*addr)))
+	log.Info(fmt.Sprintf(T("司会者の画面: http://%s/?host=%s"), displayAddr(*addr), *hostKey))
+	if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		log.Error(T("サーバーの起動に失敗しました"), "error", err)
+		os.Exit(exitError)
+	}
+	log.Info(T("サーバーを停止しました"))
+}
+
+// mux は早押しの画面とWebSocketの接続を提供するハンドラーを返す．
+func (s *buzzerServer) mux() *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /{$}", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write(buzzerPage)
+	})
+	// ボットなどブラウザ以外のクライアントも接続できるよう，Originヘッダーは確かめない
+	mux.Handle("GET /ws", websocket.Server{Handler: s.serveConn, Handshake: func(*websocket.Config, *http.Request) error { return nil }})
+	return mux
+}
+
+// newHostKey は司会者の画面を開くためのランダムなキーを生成する．
+func newHostKey() string {
+	b := make([]byte, 6)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// serveConn は1つのWebSocket接続を処理する．
+// 最初のメッセージでプレイヤーとして参加（join）するか，司会者として接続（host）する必要がある．
+func (s *buzzerServer) serveConn(conn *websocket.Conn) {
+	defer conn.Close()
+	var hello buzzerMessage
+	if err := websocket.JSON.Receive(conn, &hello); err != nil {
+		return
+	}
+	client, err := s.register(conn, hello)
+	if err != nil {
+		sendBuzzerEvent(conn, buzzerEvent{Type: "error", Message: err.Error()})
+		return
+	}
+	defer s.unregister(client)
+
+	for {
+		var msg buzzerMessage
+		if err := websocket.JSON.Receive(conn, &msg); err != nil {
+			return
+		}
+		if err := s.handle(client, msg); err != nil {
+			s.log.Debug(T("操作を受け付けませんでした"), "player", client.name, "type", msg.Type, "error", err)
+			client.enqueue(buzzerEvent{Type: "error", Message: err.Error()})
+		}
+	}
+}
+
+// register は最初のメッセージに従ってクライアントを登録し，全員に状態を配信する．
+// 同じ名前のプレイヤーが接続中の場合はエラーとなる．接続が切れたプレイヤーと同じ名前で参加すると得点を引き継ぐ．
+func (s *buzzerServer) register(conn *websocket.Conn, hello buzzerMessage) (*buzzerClient, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	client := &buzzerClient{conn: conn, send: make(chan buzzerEvent, buzzerSendQueueSize)}
+	switch hello.Type {
+	case "host":
+		if hello.Key != s.hostKey {
+			return nil, fmt.Errorf("invalid host key")
+		}
+		client.host = true
+		s.log.Info(T("司会者が接続しました"))
+	case "join":
+		client.name = strings.TrimSpace(hello.Name)
+		for c := range s.clients {
+			if !c.host && c.name == client.name {
+				return nil, fmt.Errorf("player %q is already connected", client.name)
+			}
+		}
+		if err := s.game.Join(client.name); err != nil {
+			return nil, err
+		}
+		s.log.Info(fmt.Sprintf(T("プレイヤーが参加しました: %s"), client.name), "player", client.name)
+	default:
+		return nil, fmt.Errorf("the first message must be join or host: %q", hello.Type)
+	}
+	s.clients[client] = struct{}{}
+	go client.writeLoop()
+	s.broadcast()
+	return client, nil
+}
+
+// unregister は切断したクライアントの登録を解除し，全員に状態を配信する．
+// プレイヤーの得点は残し，締め出しの判定では切断したプレイヤーを数えない．
+func (s *buzzerServer) unregister(client *buzzerClient) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.clients, client)
+	close(client.send)
+	if !client.host {
+		s.game.Leave(client.name)
+		s.log.Info(fmt.Sprintf(T("プレイヤーが切断しました: %s"), client.name), "player", client.name)
+	}
+	s.broadcast()
+}
+
+// handle はクライアントからのメッセージを進行に反映し，全員に状態を配信する．
+func (s *buzzerServer) handle(client *buzzerClient, msg buzzerMessage) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var err error
+	switch {
+	case msg.Type == "buzz" && !client.host:
+		err = s.game.Buzz(client.name)
+	case msg.Type == "answer" && !client.host:
+		var j quiz_yaml_converter.Judgement
+		if j, err = s.game.Answer(client.name, msg.Text); err == nil {
+			s.log.Debug(T("解答を判定しました"), "player", client.name, "answer", msg.Text, "judgement", j)
+		}
+	case msg.Type == "next" && client.host:
+		if !s.game.Next() {
+			err = fmt.Errorf("no more questions")
+		}
+	case msg.Type == "reveal" && client.host:
+		s.game.Reveal()
+	case msg.Type == "judge" && client.host:
+		var j quiz_yaml_converter.Judgement
+		if j, err = parseJudgement(msg.Judgement); err == nil {
+			err = s.game.Judge(j)
+		}
+	default:
+		err = fmt.Errorf("unsupported message: %q", msg.Type)
+	}
+	if err != nil {
+		return err
+	}
+	s.broadcast()
+	return nil
+}
+
+// parseJudgement は司会者が送った判定（correct, incorrect, repeat）を読み取る．
+func parseJudgement(s string) (quiz_yaml_converter.Judgement, error) {
+	for _, j := range []quiz_yaml_converter.Judgement{quiz_yaml_converter.JudgeCorrect, quiz_yaml_converter.JudgeIncorrect, quiz_yaml_converter.JudgeRepeat} {
+		if j.String() == s {
+			return j, nil
+		}
+	}
+	return quiz_yaml_converter.JudgeIncorrect, fmt.Errorf("invalid judgement: %q", s)
+}
+
+// broadcast は接続中の全員に進行の状態を送る．司会者には出題中の問題も送る．s.muを保持して呼び出す．
+// 状態は呼び出した時点のものを送信待ちに加えるだけで，書き込みは待たない．
+func (s *buzzerServer) broadcast() {
+	state := s.game.State()
+	var item *quiz_yaml_converter.QuizItem
+	if current, ok := s.game.Item(); ok {
+		item = &current
+	}
+	for c := range s.clients {
+		event := buzzerEvent{Type: "state", State: &state}
+		if c.host {
+			event.Item = item
+		}
+		if !c.enqueue(event) {
+			s.log.Debug(T("状態を送れませんでした"), "player", c.name, "error", errBuzzerQueueFull)
+		}
+	}
+}
+
+// errBuzzerQueueFull は送信待ちのメッセージが溢れたことを表す．
+var errBuzzerQueueFull = errors.New("send queue is full")
+
+// enqueue はメッセージを送信待ちに加える．送信待ちが溢れた場合は，進行を止めないよう接続を閉じてfalseを返す．
+// sendを閉じるunregisterと並行に呼び出さない．
+func (c *buzzerClient) enqueue(event buzzerEvent) bool {
+	select {
+	case c.send <- event:
+		return true
+	default:
+		c.conn.Close()
+		return false
+	}
+}
+
+// writeLoop はsendのメッセージを順にクライアントに書き込む．sendが閉じられると戻る．
+// 書き込みに失敗した場合は接続を閉じ，unregisterでsendが閉じられるまで残りを読み捨てる．
+func (c *buzzerClient) writeLoop() {
+	for event := range c.send {
+		if err := sendBuzzerEvent(c.conn, event); err != nil {
+			c.conn.Close()
+			for range c.send {
+			}
+			return
+		}
+	}
+}
+
+// closeAll は接続中のクライアントをすべて切断する．
+func (s *buzzerServer) closeAll() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for c := range s.clients {
+		c.conn.Close()
+	}
+}
+
+// sendBuzzerEvent はクライアントにメッセージを送る．応答しないクライアントで進行が止まらないよう，時間を区切る．
+func sendBuzzerEvent(conn *websocket.Conn, event buzzerEvent) error {
+	conn.SetWriteDeadline(time.Now().Add(buzzerWriteTimeout))
+	return websocket.JSON.Send(conn, event)
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/net/websocket"
+
+	"github.com/m-uesaka/quiz-yaml-go/quiz_yaml_converter"
+)
+
+// newTestBuzzerServer はテスト用の早押しサーバーを起動する．
+func newTestBuzzerServer(t *testing.T, items []quiz_yaml_converter.QuizItem) *httptest.Server {
+	t.Helper()
+	game, err := quiz_yaml_converter.NewBuzzerGame(items)
+	if err != nil {
+		t.Fatalf("NewBuzzerGame() error = %v", err)
+	}
+	server := &buzzerServer{
+		hostKey: "secret",
+		log:     slog.New(slog.NewTextHandler(io.Discard, nil)),
+		game:    game,
+		clients: map[*buzzerClient]struct{}{},
+	}
+	ts := httptest.NewServer(server.mux())
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+// dialBuzzer はサーバーにWebSocketで接続し，最初のメッセージを送る．
+func dialBuzzer(t *testing.T, ts *httptest.Server, hello buzzerMessage) *websocket.Conn {
+	t.Helper()
+	url := "ws" + strings.TrimPrefix(ts.URL, "http") + "/ws"
+	conn, err := websocket.Dial(url, "", ts.URL)
+	if err != nil {
+		t.Fatalf("websocket.Dial() error = %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	if err := websocket.JSON.Send(conn, hello); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	return conn
+}
+
+// testBuzzerEvent はテストで受け取るメッセージ．JudgementはJSONから読み取れないため，直前の判定（last）は読まない．
+type testBuzzerEvent struct {
+	Type  string `json:"type"`
+	State *struct {
+		Index     int                                `json:"index"`
+		Answer    string                             `json:"answer"`
+		Closed    bool                               `json:"closed"`
+		Answering string                             `json:"answering"`
+		LockedOut []string                           `json:"locked_out"`
+		Players   []quiz_yaml_converter.BuzzerPlayer `json:"players"`
+	} `json:"state"`
+	Item *quiz_yaml_converter.QuizItem `json:"item"`
+}
+
+// receiveUntil はcondを満たすメッセージを受け取るまで読み進め，そのメッセージを返す．
+func receiveUntil(t *testing.T, conn *websocket.Conn, cond func(testBuzzerEvent) bool) testBuzzerEvent {
+	t.Helper()
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	for {
+		var event testBuzzerEvent
+		if err := websocket.JSON.Receive(conn, &event); err != nil {
+			t.Fatalf("Receive() error = %v", err)
+		}
+		if cond(event) {
+			return event
+		}
+	}
+}
+
+func TestBuzzerServerPage(t *testing.T) {
+	ts := newTestBuzzerServer(t, []quiz_yaml_converter.QuizItem{{Question: "問題", Answer: "答え"}})
+	tests := []struct {
+		path string
+		want int
+	}{
+		{"/", http.StatusOK},
+		{"/unknown", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			resp, err := http.Get(ts.URL + tt.path)
+			if err != nil {
+				t.Fatalf("GET error = %v", err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != tt.want {
+				t.Errorf("GET %s status = %d, want %d", tt.path, resp.StatusCode, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuzzerServerRegister(t *testing.T) {
+	ts := newTestBuzzerServer(t, []quiz_yaml_converter.QuizItem{{Question: "問題", Answer: "答え"}})
+	tests := []struct {
+		name  string
+		hello buzzerMessage
+	}{
+		{"invalid host key", buzzerMessage{Type: "host", Key: "wrong"}},
+		{"empty player name", buzzerMessage{Type: "join", Name: " "}},
+		{"unsupported first message", buzzerMessage{Type: "buzz"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn := dialBuzzer(t, ts, tt.hello)
+			receiveUntil(t, conn, func(e testBuzzerEvent) bool { return e.Type == "error" })
+		})
+	}
+
+	dialBuzzer(t, ts, buzzerMessage{Type: "join", Name: "山田"})
+	conn := dialBuzzer(t, ts, buzzerMessage{Type: "join", Name: "山田"})
+	receiveUntil(t, conn, func(e testBuzzerEvent) bool { return e.Type == "error" })
+}
+
+func TestBuzzerServerGame(t *testing.T) {
+	ts := newTestBuzzerServer(t, []quiz_yaml_converter.QuizItem{{Question: "日本一高い山は？", Answer: "富士山"}})
+	host := dialBuzzer(t, ts, buzzerMessage{Type: "host", Key: "secret"})
+	yamada := dialBuzzer(t, ts, buzzerMessage{Type: "join", Name: "山田"})
+	sato := dialBuzzer(t, ts, buzzerMessage{Type: "join", Name: "佐藤"})
+	receiveUntil(t, host, func(e testBuzzerEvent) bool { return e.State != nil && len(e.State.Players) == 2 })
+
+	websocket.JSON.Send(host, buzzerMessage{Type: "next"})
+	event := receiveUntil(t, host, func(e testBuzzerEvent) bool { return e.State != nil && e.State.Index == 1 })
+	if event.Item == nil || event.Item.Answer != "富士山" {
+		t.Errorf("host event item = %+v, want the current item", event.Item)
+	}
+	event = receiveUntil(t, yamada, func(e testBuzzerEvent) bool { return e.State != nil && e.State.Index == 1 })
+	if event.Item != nil || event.State.Answer != "" {
+		t.Errorf("player event = %+v, want no answer", event)
+	}
+
+	// 山田が誤答して締め出され，佐藤が切断すると接続中の全員が締め出されて締め切る
+	websocket.JSON.Send(yamada, buzzerMessage{Type: "buzz"})
+	receiveUntil(t, yamada, func(e testBuzzerEvent) bool { return e.State != nil && e.State.Answering == "山田" })
+	websocket.JSON.Send(yamada, buzzerMessage{Type: "answer", Text: "高尾山"})
+	receiveUntil(t, yamada, func(e testBuzzerEvent) bool { return e.State != nil && len(e.State.LockedOut) == 1 })
+	websocket.JSON.Send(yamada, buzzerMessage{Type: "buzz"})
+	receiveUntil(t, yamada, func(e testBuzzerEvent) bool { return e.Type == "error" })
+
+	sato.Close()
+	event = receiveUntil(t, host, func(e testBuzzerEvent) bool { return e.State != nil && e.State.Closed })
+	if event.State.Answer != "富士山" {
+		t.Errorf("State.Answer = %q, want 富士山", event.State.Answer)
+	}
+	for _, p := range event.State.Players {
+		if want := p.Name == "山田"; p.Connected != want {
+			t.Errorf("player %s connected = %v, want %v", p.Name, p.Connected, want)
+		}
+	}
+
+	// 同じ名前で再び参加すると接続中に戻る
+	sato = dialBuzzer(t, ts, buzzerMessage{Type: "join", Name: "佐藤"})
+	receiveUntil(t, sato, func(e testBuzzerEvent) bool {
+		return e.State != nil && len(e.State.Players) == 2 && e.State.Players[1].Connected
+	})
+}
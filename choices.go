@@ -0,0 +1,94 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/m-uesaka/quiz-yaml-go/quiz_yaml_converter"
+)
+
+// runChoices はchoicesサブコマンドを実行する．
+// 記述式の問題にほかの問題の答えから選んだ誤答を加えて多肢選択の問題にし，YAMLファイルに書き出す．
+func runChoices(args []string) {
+	fs := flag.NewFlagSet("choices", flag.ExitOnError)
+	var (
+		output    = fs.String("output", "", T("書き出すYAMLファイルのパス（必須）"))
+		count     = fs.Int("count", 4, T("答えを含む選択肢の数"))
+		seed      = fs.Int64("seed", 0, T("誤答の選び方と選択肢の並びを決める乱数のシード"))
+		quiet     = fs.Bool("quiet", false, T("エラー以外のメッセージを出力しない"))
+		verbose   = fs.Bool("verbose", false, T("詳細なメッセージを出力する"))
+		logFormat = fs.String("log-format", logFormatText, T("メッセージの出力形式（text, json）"))
+	)
+	addLangFlag(fs)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, T("使用法: %s choices [オプション] -output choices.yaml quiz.yaml [quiz2.yaml ...]\n\n"), filepath.Base(os.Args[0]))
+		fmt.Fprint(os.Stderr, T("記述式の問題に，ほかの問題の答えから選んだ誤答を加えて多肢選択の問題にします。\n"))
+		fmt.Fprint(os.Stderr, T("誤答は同じジャンル，同じ文字種，近い文字数の答えから優先して選び，choicesフィールドに書き出します。\n\n"))
+		fmt.Fprint(os.Stderr, T("オプション:\n"))
+		fs.PrintDefaults()
+		fmt.Fprint(os.Stderr, T("\n例:\n"))
+		fmt.Fprintf(os.Stderr, "  %s choices -output choices.yaml quiz.yaml\n", filepath.Base(os.Args[0]))
+		fmt.Fprintf(os.Stderr, "  %s choices -count 3 -seed 1 -output choices.yaml round1.yaml round2.yaml\n", filepath.Base(os.Args[0]))
+	}
+	fs.Parse(args)
+
+	log, err := newLogger(os.Stderr, *logFormat, *quiet, *verbose)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, T("エラー: %v\n"), err)
+		os.Exit(exitUsage)
+	}
+	if fs.NArg() == 0 {
+		log.Error(T("選択肢を作るYAMLファイルを指定してください"))
+		fs.Usage()
+		os.Exit(exitUsage)
+	}
+	if *output == "" {
+		log.Error(T("-outputを指定してください"))
+		fs.Usage()
+		os.Exit(exitUsage)
+	}
+	if *count < 2 {
+		log.Error(T("-countには2以上の数を指定してください"))
+		fs.Usage()
+		os.Exit(exitUsage)
+	}
+
+	// 問題に付いたコメントを残すよう，YAMLをノードのまま読み込んで書き換える
+	editors, err := quiz_yaml_converter.LoadYAMLEditors(fs.Args())
+	if err != nil {
+		log.Error(T("YAMLファイルの読み込みに失敗しました"), "error", err)
+		os.Exit(exitCodeFor(err))
+	}
+	var refs []quiz_yaml_converter.YAMLItemRef
+	var items []quiz_yaml_converter.QuizItem
+	for _, editor := range editors {
+		refs = append(refs, editor.Refs()...)
+		items = append(items, editor.Items...)
+	}
+	items, err = quiz_yaml_converter.GenerateChoices(items, quiz_yaml_converter.ChoiceOptions{Count: *count, Seed: *seed})
+	if err != nil {
+		for _, itemErr := range quiz_yaml_converter.ItemErrors(err) {
+			log.Error(fmt.Sprintf(T("問題 %d: %v"), itemErr.Index, itemErr.Err))
+		}
+		log.Error(T("選択肢の作成に失敗しました"), "error", err)
+		os.Exit(exitUsage)
+	}
+	for i, ref := range refs {
+		ref.Editor.Items[ref.Index] = items[i]
+	}
+	// 入力が1つの場合はファイル全体の形（ジャンル名のマッピングやmetadata）も残し，複数の場合は1つの問題のリストにまとめる
+	editor := editors[0]
+	if len(editors) > 1 {
+		if editor, err = quiz_yaml_converter.ComposeYAMLEditor(refs); err != nil {
+			log.Error(T("YAMLファイルの書き出しに失敗しました"), "error", err)
+			os.Exit(exitCodeFor(err))
+		}
+	}
+	if err := editor.WriteFile(*output); err != nil {
+		log.Error(T("YAMLファイルの書き出しに失敗しました"), "error", err)
+		os.Exit(exitCodeFor(err))
+	}
+	log.Info(fmt.Sprintf(T("%d問を%d択の問題にしました: %s"), len(items), *count, *output), "items", len(items), "output", *output)
+}
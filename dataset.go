@@ -0,0 +1,130 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/m-uesaka/quiz-yaml-go/quiz_yaml_converter"
+)
+
+// runDataset はdatasetサブコマンドを実行する．
+// 問題を学習・検証・評価データに分割し，それぞれをJSON Lines形式のファイルに書き出す．
+// -hfの場合はHugging Faceのdatasetsで読み込める構成（data/以下のJSON Linesとデータセットカード）で書き出す．
+func runDataset(args []string) {
+	fs := flag.NewFlagSet("dataset", flag.ExitOnError)
+	var (
+		train      = fs.Float64("train", 0.8, T("学習データ（train.jsonl）の割合"))
+		validation = fs.Float64("validation", 0.1, T("検証データ（validation.jsonl）の割合"))
+		test       = fs.Float64("test", 0.1, T("評価データ（test.jsonl）の割合"))
+		stratify   = fs.Bool("stratify", true, T("ジャンルごとに同じ割合で分割する"))
+		seed       = fs.Int64("seed", 0, T("分割に使う乱数のシード（0の場合は毎回異なる分割になる）"))
+		output     = fs.String("output", ".", T("分割したJSON Linesファイルを書き出すディレクトリ"))
+		hf         = fs.Bool("hf", false, T("Hugging Faceのdatasetsで読み込める構成（data/*.jsonlとREADME.mdのデータセットカード）で書き出す"))
+		name       = fs.String("name", "", T("-hfで書き出すデータセットカードの見出し（未指定時は出力ディレクトリの名前）"))
+		force      = fs.Bool("force", false, T("-hfで既存のデータセットカード（README.md）を上書きする"))
+		quiet      = fs.Bool("quiet", false, T("エラー以外のメッセージを出力しない"))
+		verbose    = fs.Bool("verbose", false, T("詳細なメッセージを出力する"))
+		logFormat  = fs.String("log-format", logFormatText, T("メッセージの出力形式（text, json）"))
+	)
+	addLangFlag(fs)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, T("使用法: %s dataset [オプション] quiz.yaml [quiz2.yaml ...]\n\n"), filepath.Base(os.Args[0]))
+		fmt.Fprint(os.Stderr, T("問題を学習・検証・評価データに無作為に分割し，train.jsonl，validation.jsonl，test.jsonlに書き出します。\n"))
+		fmt.Fprint(os.Stderr, T("割合が0のデータのファイルは書き出しません。idがない問題には問題文と答えから決まるIDを付けます。\n"))
+		fmt.Fprint(os.Stderr, T("-hfを指定すると，Hugging Faceのdatasetsで読み込めるdata/*.jsonlとデータセットカードの雛形を書き出します。\n\n"))
+		fmt.Fprint(os.Stderr, T("オプション:\n"))
+		fs.PrintDefaults()
+		fmt.Fprint(os.Stderr, T("\n例:\n"))
+		fmt.Fprintf(os.Stderr, "  %s dataset -seed 1 -output dataset quiz.yaml\n", filepath.Base(os.Args[0]))
+		fmt.Fprintf(os.Stderr, "  %s dataset -train 0.9 -validation 0 -test 0.1 -stratify=false quiz.yaml\n", filepath.Base(os.Args[0]))
+		fmt.Fprintf(os.Stderr, "  %s dataset -hf -seed 1 -name \"My Quiz\" -output my-quiz quiz.yaml\n", filepath.Base(os.Args[0]))
+	}
+	fs.Parse(args)
+
+	log, err := newLogger(os.Stderr, *logFormat, *quiet, *verbose)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, T("エラー: %v\n"), err)
+		os.Exit(exitUsage)
+	}
+	if fs.NArg() == 0 {
+		log.Error(T("分割するYAMLファイルを指定してください"))
+		fs.Usage()
+		os.Exit(exitUsage)
+	}
+
+	items, err := quiz_yaml_converter.LoadYAMLFiles(fs.Args())
+	if err != nil {
+		log.Error(T("YAMLファイルの読み込みに失敗しました"), "error", err)
+		os.Exit(exitCodeFor(err))
+	}
+	splits, err := quiz_yaml_converter.SplitDataset(items, quiz_yaml_converter.DatasetOptions{
+		Train:      *train,
+		Validation: *validation,
+		Test:       *test,
+		Stratify:   *stratify,
+		Seed:       *seed,
+	})
+	if err != nil {
+		log.Error(T("問題の分割に失敗しました"), "error", err)
+		os.Exit(exitUsage)
+	}
+
+	if *hf {
+		writeHFDataset(log, splits, *output, *name, *force)
+		return
+	}
+
+	if err := os.MkdirAll(*output, 0755); err != nil {
+		log.Error(T("出力ディレクトリの作成に失敗しました"), "error", err)
+		os.Exit(exitCodeFor(err))
+	}
+	opts := quiz_yaml_converter.ConvertOptions{Format: "jsonl", AssignIDs: true}
+	for _, split := range []struct {
+		name  string
+		ratio float64
+		items []quiz_yaml_converter.QuizItem
+	}{
+		{"train", *train, splits.Train},
+		{"validation", *validation, splits.Validation},
+		{"test", *test, splits.Test},
+	} {
+		if split.ratio == 0 {
+			continue
+		}
+		path := filepath.Join(*output, split.name+".jsonl")
+		if err := quiz_yaml_converter.ConvertItems(split.items, path, "", opts); err != nil {
+			log.Error(T("JSON Linesファイルの書き出しに失敗しました"), "error", err)
+			os.Exit(exitCodeFor(err))
+		}
+		log.Info(fmt.Sprintf(T("%s: %d問: %s"), split.name, len(split.items), path),
+			"split", split.name, "items", len(split.items), "output", path)
+	}
+}
+
+// writeHFDataset は分割した問題をHugging Faceのdatasetsで読み込める構成でdirに書き出す．
+// 既存のデータセットカードはforceでなければ残す．
+func writeHFDataset(log *slog.Logger, splits quiz_yaml_converter.DatasetSplits, dir, name string, force bool) {
+	if name == "" {
+		if abs, err := filepath.Abs(dir); err == nil {
+			name = filepath.Base(abs)
+		}
+	}
+	written, err := quiz_yaml_converter.WriteHFDataset(dir, splits, quiz_yaml_converter.HFDatasetOptions{Name: name, OverwriteCard: force})
+	for _, path := range written {
+		log.Info(fmt.Sprintf(T("書き出しました: %s"), path), "output", path)
+	}
+	if err != nil {
+		log.Error(T("Hugging Face形式のデータセットの書き出しに失敗しました"), "error", err)
+		os.Exit(exitCodeFor(err))
+	}
+	card := filepath.Join(dir, "README.md")
+	if !slices.Contains(written, card) {
+		log.Warn(fmt.Sprintf(T("既存のデータセットカードを残しました（上書きするには-forceを指定してください）: %s"), card), "output", card)
+	}
+	log.Info(fmt.Sprintf(T("train %d問，validation %d問，test %d問"), len(splits.Train), len(splits.Validation), len(splits.Test)),
+		"train", len(splits.Train), "validation", len(splits.Validation), "test", len(splits.Test))
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/m-uesaka/quiz-yaml-go/quiz_yaml_converter"
+)
+
+// runDiff はdiffサブコマンドを実行する．
+// 2つのYAMLファイルを比較し，追加・削除・変更された問題を標準出力に書き出す．
+func runDiff(args []string) {
+	fs := flag.NewFlagSet("diff", flag.ExitOnError)
+	var (
+		format   = fs.String("format", "text", T("出力形式（text, json）"))
+		exitCode = fs.Bool("exit-code", false, T("差分がある場合に終了コード8で終了する"))
+	)
+	addLangFlag(fs)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, T("使用法: %s diff [オプション] 変更前.yaml 変更後.yaml\n\n"), filepath.Base(os.Args[0]))
+		fmt.Fprint(os.Stderr, T("2つのYAMLファイルを比較し，追加・削除・変更された問題を表示します。\n"))
+		fmt.Fprint(os.Stderr, T("問題はidがあればidで，なければ問題文で対応付けます。\n\n"))
+		fmt.Fprint(os.Stderr, T("オプション:\n"))
+		fs.PrintDefaults()
+		fmt.Fprint(os.Stderr, T("\n例:\n"))
+		fmt.Fprintf(os.Stderr, "  %s diff old.yaml new.yaml\n", filepath.Base(os.Args[0]))
+		fmt.Fprintf(os.Stderr, "  %s diff -format json old.yaml new.yaml\n", filepath.Base(os.Args[0]))
+	}
+	fs.Parse(args)
+
+	if fs.NArg() != 2 {
+		fmt.Fprint(os.Stderr, T("エラー: 比較する2つのYAMLファイルを指定してください\n\n"))
+		fs.Usage()
+		os.Exit(exitUsage)
+	}
+	if *format != "text" && *format != "json" {
+		fmt.Fprintf(os.Stderr, T("エラー: サポートされていない出力形式です: %s（text, json）\n"), *format)
+		os.Exit(exitUsage)
+	}
+
+	oldFile, newFile := fs.Arg(0), fs.Arg(1)
+	oldItems, err := quiz_yaml_converter.LoadYAMLData(oldFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, T("エラー: %sの読み込みに失敗しました: %v\n"), oldFile, err)
+		os.Exit(exitCodeFor(err))
+	}
+	newItems, err := quiz_yaml_converter.LoadYAMLData(newFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, T("エラー: %sの読み込みに失敗しました: %v\n"), newFile, err)
+		os.Exit(exitCodeFor(err))
+	}
+
+	result := quiz_yaml_converter.DiffItems(oldItems, newItems)
+	if *format == "json" {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		enc.Encode(result)
+	} else {
+		writeDiffText(os.Stdout, result)
+	}
+
+	if *exitCode && result.HasChanges() {
+		os.Exit(exitDifferent)
+	}
+}
+
+// writeDiffText は差分を人が読むための形式で書き出す．
+func writeDiffText(w io.Writer, result quiz_yaml_converter.DiffResult) {
+	fmt.Fprintf(w, T("追加: %d問, 削除: %d問, 変更: %d問\n"), len(result.Added), len(result.Removed), len(result.Modified))
+	for _, d := range result.Removed {
+		fmt.Fprintf(w, T("\n- [問題 %d] %s\n"), d.OldIndex, d.Question)
+	}
+	for _, d := range result.Added {
+		fmt.Fprintf(w, T("\n+ [問題 %d] %s\n"), d.NewIndex, d.Question)
+	}
+	for _, d := range result.Modified {
+		fmt.Fprintf(w, T("\n~ [問題 %d → %d] %s\n"), d.OldIndex, d.NewIndex, d.Question)
+		for _, c := range d.Changes {
+			fmt.Fprintf(w, "    %s: %q → %q\n", c.Field, c.Old, c.New)
+		}
+	}
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+
+	quizyamlv1 "github.com/m-uesaka/quiz-yaml-go/proto/quizyaml/v1"
+	"github.com/m-uesaka/quiz-yaml-go/quiz_yaml_converter"
+)
+
+// runGRPC はgrpcサブコマンドを実行する．
+// proto/quizyaml/v1/quizyaml.protoで定義した変換・バリデーションのgRPCサーバーを起動する．
+func runGRPC(args []string) {
+	fs := flag.NewFlagSet("grpc", flag.ExitOnError)
+	var (
+		addr      = fs.String("addr", ":50051", T("待ち受けるアドレス"))
+		maxBody   = fs.Int("max-body", defaultMaxBodyBytes, T("リクエストの最大サイズ（バイト）"))
+		quiet     = fs.Bool("quiet", false, T("エラー以外のメッセージを出力しない"))
+		verbose   = fs.Bool("verbose", false, T("リクエストごとのログを出力する"))
+		logFormat = fs.String("log-format", logFormatText, T("メッセージの出力形式（text, json）"))
+	)
+	addLangFlag(fs)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, T("使用法: %s grpc [オプション]\n\n"), filepath.Base(os.Args[0]))
+		fmt.Fprint(os.Stderr, T("変換・バリデーションを行うgRPCサーバーを起動します（proto/quizyaml/v1/quizyaml.proto）。\n\n"))
+		fmt.Fprint(os.Stderr, T("オプション:\n"))
+		fs.PrintDefaults()
+		fmt.Fprint(os.Stderr, T("\n例:\n"))
+		fmt.Fprintf(os.Stderr, "  %s grpc -addr :50051\n", filepath.Base(os.Args[0]))
+	}
+	fs.Parse(args)
+
+	log, err := newLogger(os.Stderr, *logFormat, *quiet, *verbose)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, T("エラー: %v\n"), err)
+		os.Exit(exitUsage)
+	}
+
+	lis, err := net.Listen("tcp", *addr)
+	if err != nil {
+		log.Error(T("サーバーの起動に失敗しました"), "error", err)
+		os.Exit(exitError)
+	}
+	srv := grpc.NewServer(
+		grpc.MaxRecvMsgSize(*maxBody),
+		grpc.UnaryInterceptor(grpcLogInterceptor(log)),
+	)
+	quizyamlv1.RegisterQuizConverterServer(srv, &grpcServer{log: log})
+	// grpcurlなどからサービスの定義を参照できるようにする
+	reflection.Register(srv)
+
+	// Ctrl+CやSIGTERMで処理中のリクエストを待ってから終了する
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		srv.GracefulStop()
+	}()
+
+	log.Info(fmt.Sprintf(T("サーバーを起動しました: %s"), *addr), "addr", *addr)
+	if err := srv.Serve(lis); err != nil && !errors.Is(err, grpc.ErrServerStopped) {
+		log.Error(T("サーバーの起動に失敗しました"), "error", err)
+		os.Exit(exitError)
+	}
+	log.Info(T("サーバーを停止しました"))
+}
+
+// grpcLogInterceptor はリクエストごとのログを出力するインターセプター．
+func grpcLogInterceptor(log *slog.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		resp, err := handler(ctx, req)
+		log.Debug(info.FullMethod, "code", status.Code(err).String())
+		return resp, err
+	}
+}
+
+// grpcServer はQuizConverterサービスの実装．
+// 処理はserveサブコマンドのHTTP APIと同じで，ファイルシステムには触れない．
+type grpcServer struct {
+	quizyamlv1.UnimplementedQuizConverterServer
+	log        *slog.Logger
+	converters converterCache
+}
+
+// Validate はYAMLをバリデーションする．serveサブコマンドのPOST /validateと同じくValidateYAMLで検査する．
+func (s *grpcServer) Validate(ctx context.Context, req *quizyamlv1.ValidateRequest) (*quizyamlv1.ValidateResponse, error) {
+	msgLang, err := quiz_yaml_converter.ParseLanguage(req.GetLang())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	result := quiz_yaml_converter.ValidateYAML(req.GetYaml())
+
+	resp := &quizyamlv1.ValidateResponse{
+		Valid: result.IsValid,
+		Items: int32(result.Items),
+	}
+	for _, e := range result.ValidationErrors {
+		resp.Errors = append(resp.Errors, &quizyamlv1.ValidationError{
+			Index:   int32(e.Index),
+			Field:   e.Field,
+			Message: e.LocalizedMessage(msgLang),
+			Rule:    e.Rule,
+		})
+	}
+	return resp, nil
+}
+
+// Convert はYAMLを指定した出力形式に変換する．
+// serveサブコマンドのPOST /convertと同じくConverterで変換し，エラーを同じ分類でステータスに対応付ける．
+// exec:による外部コマンドの出力形式は使用できない．
+func (s *grpcServer) Convert(ctx context.Context, req *quizyamlv1.ConvertRequest) (*quizyamlv1.ConvertResponse, error) {
+	format := req.GetFormat()
+	if format == "" {
+		format = "csv"
+	}
+	params := map[string][]string{}
+	for key, value := range req.GetOptions() {
+		params[key] = []string{value}
+	}
+	converter, err := s.converters.get(format, params)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	out, err := converter.ConvertBytes(req.GetYaml())
+	if err != nil {
+		code := codes.Internal
+		switch {
+		case errors.Is(err, quiz_yaml_converter.ErrInvalidYAML), errors.Is(err, quiz_yaml_converter.ErrTransform):
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		case errors.Is(err, quiz_yaml_converter.ErrOutputTooLarge), errors.Is(err, quiz_yaml_converter.ErrTemplateTimeout):
+			// サーバーの上限（serverMaxOutputBytes，serverTemplateTimeout）を超えた
+			code = codes.ResourceExhausted
+		case errors.Is(err, quiz_yaml_converter.ErrTemplateExecute):
+			code = codes.InvalidArgument
+		}
+		s.log.Error(T("変換に失敗しました"), "error", err, "format", format)
+		return nil, status.Error(code, err.Error())
+	}
+	return &quizyamlv1.ConvertResponse{
+		Output:      out,
+		ContentType: converter.ContentType(),
+	}, nil
+}
+
+// ListFormats は使用できる出力形式の一覧を返す．
+func (s *grpcServer) ListFormats(ctx context.Context, req *quizyamlv1.ListFormatsRequest) (*quizyamlv1.ListFormatsResponse, error) {
+	return &quizyamlv1.ListFormatsResponse{Formats: quiz_yaml_converter.FormatterNames()}, nil
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net"
+	"slices"
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	quizyamlv1 "github.com/m-uesaka/quiz-yaml-go/proto/quizyaml/v1"
+)
+
+// newTestGRPCClient はメモリ上の接続（bufconn）でgrpcServerを起動し，接続したクライアントを返す．
+func newTestGRPCClient(t *testing.T) quizyamlv1.QuizConverterClient {
+	t.Helper()
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	lis := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer(grpc.UnaryInterceptor(grpcLogInterceptor(log)))
+	quizyamlv1.RegisterQuizConverterServer(srv, &grpcServer{log: log})
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("grpc.NewClient() error = %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return quizyamlv1.NewQuizConverterClient(conn)
+}
+
+func TestGRPCServerConvert(t *testing.T) {
+	client := newTestGRPCClient(t)
+	valid := []byte("- question: 日本の首都は？\n  answer: 東京\n")
+	tests := []struct {
+		name        string
+		req         *quizyamlv1.ConvertRequest
+		code        codes.Code
+		contentType string
+		contains    string
+	}{
+		{"default format", &quizyamlv1.ConvertRequest{Yaml: valid}, codes.OK, "text/csv", "東京"},
+		{"with options", &quizyamlv1.ConvertRequest{Yaml: valid, Format: "csv", Options: map[string]string{"no-header": "true"}}, codes.OK, "text/csv", "日本の首都は？,東京"},
+		{"json", &quizyamlv1.ConvertRequest{Yaml: valid, Format: "json"}, codes.OK, "application/json", `"answer": "東京"`},
+		{"unknown format", &quizyamlv1.ConvertRequest{Yaml: valid, Format: "pdf"}, codes.InvalidArgument, "", ""},
+		{"invalid option", &quizyamlv1.ConvertRequest{Yaml: valid, Options: map[string]string{"encoding": "ebcdic"}}, codes.InvalidArgument, "", ""},
+		{"output too large", &quizyamlv1.ConvertRequest{Yaml: valid, Format: endlessFormat}, codes.ResourceExhausted, "", ""},
+		{"invalid yaml", &quizyamlv1.ConvertRequest{Yaml: []byte("- question: [")}, codes.InvalidArgument, "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := client.Convert(context.Background(), tt.req)
+			if got := status.Code(err); got != tt.code {
+				t.Fatalf("Convert() code = %v, want %v (error: %v)", got, tt.code, err)
+			}
+			if err != nil {
+				return
+			}
+			if !strings.HasPrefix(resp.GetContentType(), tt.contentType) {
+				t.Errorf("Convert() content type = %q, want %q", resp.GetContentType(), tt.contentType)
+			}
+			if !strings.Contains(string(resp.GetOutput()), tt.contains) {
+				t.Errorf("Convert() output = %s, want it to contain %q", resp.GetOutput(), tt.contains)
+			}
+		})
+	}
+}
+
+func TestGRPCServerValidate(t *testing.T) {
+	client := newTestGRPCClient(t)
+	tests := []struct {
+		name   string
+		req    *quizyamlv1.ValidateRequest
+		code   codes.Code
+		valid  bool
+		items  int32
+		errors int
+		rule   string
+	}{
+		{"valid", &quizyamlv1.ValidateRequest{Yaml: []byte("- question: q\n  answer: a\n- question: q2\n  answer: a2\n")}, codes.OK, true, 2, 0, ""},
+		{"missing answer", &quizyamlv1.ValidateRequest{Yaml: []byte("- question: q\n"), Lang: "en"}, codes.OK, false, 1, 1, "required"},
+		{"unknown lang", &quizyamlv1.ValidateRequest{Yaml: []byte("- question: q\n  answer: a\n"), Lang: "fr"}, codes.InvalidArgument, false, 0, 0, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := client.Validate(context.Background(), tt.req)
+			if got := status.Code(err); got != tt.code {
+				t.Fatalf("Validate() code = %v, want %v (error: %v)", got, tt.code, err)
+			}
+			if err != nil {
+				return
+			}
+			if resp.GetValid() != tt.valid || resp.GetItems() != tt.items || len(resp.GetErrors()) != tt.errors {
+				t.Errorf("Validate() = valid %v, items %d, errors %v, want %v, %d, %d errors", resp.GetValid(), resp.GetItems(), resp.GetErrors(), tt.valid, tt.items, tt.errors)
+			}
+			if tt.errors > 0 && resp.GetErrors()[0].GetRule() != tt.rule {
+				t.Errorf("Validate() rule = %q, want %q", resp.GetErrors()[0].GetRule(), tt.rule)
+			}
+		})
+	}
+}
+
+func TestGRPCServerListFormats(t *testing.T) {
+	client := newTestGRPCClient(t)
+	resp, err := client.ListFormats(context.Background(), &quizyamlv1.ListFormatsRequest{})
+	if err != nil {
+		t.Fatalf("ListFormats() error = %v", err)
+	}
+	for _, format := range []string{"csv", "html", "json", "markdown"} {
+		if !slices.Contains(resp.GetFormats(), format) {
+			t.Errorf("ListFormats() = %v, want it to contain %q", resp.GetFormats(), format)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/m-uesaka/quiz-yaml-go/quiz_yaml_converter"
+)
+
+// runIDs はidsサブコマンドを実行する．
+// 問題文と答えから決まるIDを割り当て，YAMLファイルに書き戻す．
+func runIDs(args []string) {
+	fs := flag.NewFlagSet("ids", flag.ExitOnError)
+	var (
+		output    = fs.String("output", "", T("書き出すYAMLファイルのパス（未指定時は入力ファイルを上書き）"))
+		overwrite = fs.Bool("overwrite", false, T("既にidがある問題のidも置き換える"))
+		quiet     = fs.Bool("quiet", false, T("エラー以外のメッセージを出力しない"))
+		verbose   = fs.Bool("verbose", false, T("詳細なメッセージを出力する"))
+		logFormat = fs.String("log-format", logFormatText, T("メッセージの出力形式（text, json）"))
+	)
+	addLangFlag(fs)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, T("使用法: %s ids [オプション] quiz.yaml\n\n"), filepath.Base(os.Args[0]))
+		fmt.Fprint(os.Stderr, T("問題文と答えから決まるIDを各問題に割り当て，YAMLファイルに書き戻します。\n"))
+		fmt.Fprint(os.Stderr, T("同じ内容の問題が複数ある場合は警告を表示します。\n\n"))
+		fmt.Fprint(os.Stderr, T("オプション:\n"))
+		fs.PrintDefaults()
+		fmt.Fprint(os.Stderr, T("\n例:\n"))
+		fmt.Fprintf(os.Stderr, "  %s ids quiz.yaml\n", filepath.Base(os.Args[0]))
+		fmt.Fprintf(os.Stderr, "  %s ids -output quiz_with_ids.yaml quiz.yaml\n", filepath.Base(os.Args[0]))
+	}
+	fs.Parse(args)
+
+	log, err := newLogger(os.Stderr, *logFormat, *quiet, *verbose)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, T("エラー: %v\n"), err)
+		os.Exit(exitUsage)
+	}
+	if fs.NArg() != 1 {
+		log.Error(T("IDを割り当てるYAMLファイルを1つ指定してください"))
+		fs.Usage()
+		os.Exit(exitUsage)
+	}
+
+	inputFile := fs.Arg(0)
+	outputFile := *output
+	if outputFile == "" {
+		outputFile = inputFile
+	}
+
+	// 書き戻すときにYAML中のコメントを残すため，ノードごと読み込む
+	editor, err := quiz_yaml_converter.LoadYAMLEditor(inputFile)
+	if err != nil {
+		log.Error(T("YAMLファイルの読み込みに失敗しました"), "error", err)
+		os.Exit(exitCodeFor(err))
+	}
+	items := editor.Items
+
+	duplicates := quiz_yaml_converter.DuplicateContentIDs(items)
+	ids := make([]string, 0, len(duplicates))
+	for id := range duplicates {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return duplicates[ids[i]][0] < duplicates[ids[j]][0] })
+	for _, id := range ids {
+		log.Warn(fmt.Sprintf(T("同じ内容の問題があります: 問題 %v"), duplicates[id]), "id", id)
+	}
+
+	assigned := quiz_yaml_converter.AssignIDs(items, *overwrite)
+	if assigned == 0 && outputFile == inputFile {
+		log.Info(T("IDを割り当てる問題はありませんでした"), "input", inputFile)
+		return
+	}
+	if err := editor.WriteFile(outputFile); err != nil {
+		log.Error(T("YAMLファイルの書き出しに失敗しました"), "error", err)
+		os.Exit(exitCodeFor(err))
+	}
+	log.Info(fmt.Sprintf(T("%d問にIDを割り当てました: %s"), assigned, outputFile), "input", inputFile, "output", outputFile, "assigned", assigned)
+}
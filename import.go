@@ -0,0 +1,103 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/m-uesaka/quiz-yaml-go/quiz_yaml_converter"
+)
+
+// runImport はimportサブコマンドを実行する．
+// CSVファイルを読み込み，問題データをYAMLファイルに書き出す．
+func runImport(args []string) {
+	fs := flag.NewFlagSet("import", flag.ExitOnError)
+	var (
+		output      = fs.String("output", "", T("書き出すYAMLファイルのパス（必須）"))
+		mapping     = fs.String("map", "", T("CSVの列とフィールドの対応（例: question=1,answer=3,spell=4．番号の代わりにヘッダー行の列名も指定できる）"))
+		commentSep  = fs.String("comment-sep", "", T("CSVのcomments列で複数のコメントをつなぐ文字列（未指定時は改行）"))
+		criteriaSep = fs.String("criteria-sep", "", T("CSVのcriteria列で正誤判定の区分をつなぐ文字列（未指定時は／）"))
+		critItemSep = fs.String("criteria-item-sep", "", T("CSVのcriteria列で各区分の項目をつなぐ文字列（指定時は項目を「」で囲まない）"))
+		noHeader    = fs.Bool("no-header", false, T("CSVにヘッダー行がない"))
+		headers     = fs.String("header-labels", "", T("CSVのヘッダー名を変更（例: question=問題,answer=答え．jaで日本語ラベル）"))
+		encoding    = fs.String("encoding", "utf8", T("CSVの文字コード（utf8, utf8-bom, sjis）"))
+		newlines    = fs.String("newlines", "keep", T("CSVのフィールド内の改行の扱い（keep: そのまま，escape: \\nに置き換え，space: 空白にまとめる）"))
+		escapeFx    = fs.Bool("escape-formulas", false, T("CSVのフィールドの先頭の数式避けの'を取り除く"))
+		quiet       = fs.Bool("quiet", false, T("エラー以外のメッセージを出力しない"))
+		verbose     = fs.Bool("verbose", false, T("詳細なメッセージを出力する"))
+		logFormat   = fs.String("log-format", logFormatText, T("メッセージの出力形式（text, json）"))
+	)
+	addLangFlag(fs)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, T("使用法: %s import [オプション] -output quiz.yaml quiz.csv\n\n"), filepath.Base(os.Args[0]))
+		fmt.Fprint(os.Stderr, T("CSVファイルを読み込み，問題データをYAMLファイルに書き出します。\n"))
+		fmt.Fprint(os.Stderr, T("-mapで，列の並びが異なるCSVの各列をフィールドに対応付けられます。\n\n"))
+		fmt.Fprint(os.Stderr, T("オプション:\n"))
+		fs.PrintDefaults()
+		fmt.Fprint(os.Stderr, T("\n例:\n"))
+		fmt.Fprintf(os.Stderr, "  %s import -output quiz.yaml quiz.csv\n", filepath.Base(os.Args[0]))
+		fmt.Fprintf(os.Stderr, "  %s import -map question=1,answer=3,spell=4 -no-header -output quiz.yaml legacy.csv\n", filepath.Base(os.Args[0]))
+		fmt.Fprintf(os.Stderr, "  %s import -map question=問題文,answer=正解 -encoding sjis -output quiz.yaml legacy.csv\n", filepath.Base(os.Args[0]))
+	}
+	fs.Parse(args)
+
+	log, err := newLogger(os.Stderr, *logFormat, *quiet, *verbose)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, T("エラー: %v\n"), err)
+		os.Exit(exitUsage)
+	}
+	if fs.NArg() != 1 {
+		log.Error(T("読み込むCSVファイルを1つ指定してください"))
+		fs.Usage()
+		os.Exit(exitUsage)
+	}
+	if *output == "" {
+		log.Error(T("-outputを指定してください"))
+		fs.Usage()
+		os.Exit(exitUsage)
+	}
+
+	var opts quiz_yaml_converter.ConvertOptions
+	opts.CSV.CommentSeparator = *commentSep
+	opts.CSV.CriteriaSeparator = *criteriaSep
+	opts.CSV.CriteriaItemSeparator = *critItemSep
+	opts.CSV.NoHeader = *noHeader
+	opts.CSV.EscapeFormulas = *escapeFx
+	if opts.CSV.Encoding, err = quiz_yaml_converter.ParseEncoding(*encoding); err != nil {
+		log.Error(T("-encodingの指定が正しくありません"), "error", err)
+		os.Exit(exitUsage)
+	}
+	if opts.CSV.Newlines, err = quiz_yaml_converter.ParseNewlineMode(*newlines); err != nil {
+		log.Error(T("-newlinesの指定が正しくありません"), "error", err)
+		os.Exit(exitUsage)
+	}
+	if *headers != "" {
+		if opts.CSV.HeaderLabels, err = quiz_yaml_converter.ParseCSVHeaderLabels(*headers); err != nil {
+			log.Error(T("-header-labelsの指定が正しくありません"), "error", err)
+			os.Exit(exitUsage)
+		}
+	}
+	if *mapping != "" {
+		if opts.CSV.ColumnMapping, err = quiz_yaml_converter.ParseCSVColumnMapping(*mapping); err != nil {
+			log.Error(T("-mapの指定が正しくありません"), "error", err)
+			os.Exit(exitUsage)
+		}
+	}
+
+	inputFile := fs.Arg(0)
+	items, err := quiz_yaml_converter.LoadCSVFile(inputFile, opts)
+	if err != nil {
+		log.Error(T("CSVファイルの読み込みに失敗しました"), "input", inputFile, "error", err)
+		os.Exit(exitCodeFor(err))
+	}
+	// 読み込んだ問題の不備は，YAMLを書き出した後で直せるよう警告にとどめる
+	for _, msg := range quiz_yaml_converter.ValidateItems(items).LocalizedErrors(lang) {
+		log.Warn(msg, "input", inputFile)
+	}
+	if err := quiz_yaml_converter.WriteYAMLFile(*output, items); err != nil {
+		log.Error(T("YAMLファイルの書き出しに失敗しました"), "error", err)
+		os.Exit(exitCodeFor(err))
+	}
+	log.Info(fmt.Sprintf(T("%d問を読み込みました: %s"), len(items), *output), "input", inputFile, "output", *output, "items", len(items))
+}
@@ -0,0 +1,268 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/m-uesaka/quiz-yaml-go/quiz_yaml_converter"
+)
+
+// liveサブコマンドで正誤を切り替えられるプレイヤーの最大数（キー1〜9）
+const maxLivePlayers = 9
+
+// liveサブコマンドのキー操作
+const (
+	keyCtrlC  = 0x03
+	keyCtrlD  = 0x04
+	keyEscape = 0x1b
+)
+
+// liveAction はキー入力に対応する操作．
+type liveAction int
+
+const (
+	liveNone liveAction = iota
+	liveToggle
+	liveNext
+	livePrev
+	liveQuit
+)
+
+// runLive はliveサブコマンドを実行する．
+// 問題を1問ずつ端末に表示し，キー操作で各プレイヤーの正誤を記録して，終了時に成績のCSVを書き出す．
+func runLive(args []string) {
+	fs := flag.NewFlagSet("live", flag.ExitOnError)
+	var (
+		players   = fs.String("players", "", T("プレイヤー名をカンマ区切りで指定（必須．最大9人）"))
+		output    = fs.String("output", "", T("書き出す成績のCSVファイルのパス（必須）"))
+		quiet     = fs.Bool("quiet", false, T("エラー以外のメッセージを出力しない"))
+		verbose   = fs.Bool("verbose", false, T("詳細なメッセージを出力する"))
+		logFormat = fs.String("log-format", logFormatText, T("メッセージの出力形式（text, json）"))
+		noClobber = fs.Bool("no-clobber", false, T("出力ファイルが既に存在する場合は上書きせずにエラーにする"))
+		force     = fs.Bool("force", false, T("-no-clobberや入力ファイルと同じパスへの出力の確認を無視して上書きする"))
+	)
+	addLangFlag(fs)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, T("使用法: %s live [オプション] -players 名前1,名前2 -output results.csv quiz.yaml [quiz2.yaml ...]\n\n"), filepath.Base(os.Args[0]))
+		fmt.Fprint(os.Stderr, T("問題を1問ずつ表示し，キー操作で各プレイヤーの正誤を記録します。\n"))
+		fmt.Fprint(os.Stderr, T("終了すると，resultsサブコマンドやscoresサブコマンドで使える成績のCSVを書き出します。\n\n"))
+		fmt.Fprint(os.Stderr, T("キー操作:\n"))
+		fmt.Fprint(os.Stderr, T("  1〜9       プレイヤーの正誤を切り替える（○ → × → なし）\n"))
+		fmt.Fprint(os.Stderr, T("  Space, →, n 次の問題\n"))
+		fmt.Fprint(os.Stderr, T("  ←, p       前の問題\n"))
+		fmt.Fprint(os.Stderr, T("  q, Ctrl-C  終了して成績を書き出す\n\n"))
+		fmt.Fprint(os.Stderr, T("オプション:\n"))
+		fs.PrintDefaults()
+		fmt.Fprint(os.Stderr, T("\n例:\n"))
+		fmt.Fprintf(os.Stderr, "  %s live -players 山田,佐藤,鈴木 -output results.csv quiz.yaml\n", filepath.Base(os.Args[0]))
+	}
+	fs.Parse(args)
+
+	log, err := newLogger(os.Stderr, *logFormat, *quiet, *verbose)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, T("エラー: %v\n"), err)
+		os.Exit(exitUsage)
+	}
+	if fs.NArg() == 0 {
+		log.Error(T("出題するYAMLファイルを指定してください"))
+		fs.Usage()
+		os.Exit(exitUsage)
+	}
+	if *output == "" {
+		log.Error(T("-outputを指定してください"))
+		fs.Usage()
+		os.Exit(exitUsage)
+	}
+	var names []string
+	for _, name := range strings.Split(*players, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 || len(names) > maxLivePlayers {
+		log.Error(fmt.Sprintf(T("-playersには1〜%d人のプレイヤー名を指定してください"), maxLivePlayers))
+		fs.Usage()
+		os.Exit(exitUsage)
+	}
+
+	// 出題を終えてから書き出せないことが分からないよう，出力先は始める前に確認する
+	for _, input := range fs.Args() {
+		if err := checkOutputPath(input, *output, *noClobber, *force); err != nil {
+			log.Error(T("出力先を確認できませんでした"), "error", err)
+			os.Exit(exitCodeFor(err))
+		}
+	}
+
+	items, err := quiz_yaml_converter.LoadYAMLFiles(fs.Args())
+	if err != nil {
+		log.Error(T("YAMLファイルの読み込みに失敗しました"), "error", err)
+		os.Exit(exitCodeFor(err))
+	}
+	sheet, err := quiz_yaml_converter.NewScoresheet(items, names)
+	if err != nil {
+		log.Error(T("出題の準備に失敗しました"), "error", err)
+		os.Exit(exitUsage)
+	}
+
+	// 端末でない場合や対応していないOSでは，キーを押した後のEnterで入力を受け付ける
+	// os.Exitでは遅延実行されないため，終了する前にrestoreを明示的に呼んで端末の設定を戻す
+	raw := false
+	restore := func() {}
+	if r, err := makeRaw(int(os.Stdin.Fd())); err == nil {
+		raw = true
+		restore = r
+		defer restore()
+	} else {
+		log.Debug(T("端末を1文字ずつ入力するモードにできませんでした"), "error", err)
+	}
+
+	err = playLive(bufio.NewReader(os.Stdin), os.Stdout, sheet, raw)
+	if raw {
+		fmt.Fprint(os.Stdout, "\x1b[H\x1b[2J")
+	}
+	restore()
+	if err != nil {
+		log.Error(T("キー入力の読み込みに失敗しました"), "error", err)
+	}
+	results := sheet.Results()
+	if err := quiz_yaml_converter.SavePlayResults(results, *output); err != nil {
+		log.Error(T("成績のCSVファイルの書き出しに失敗しました"), "error", err)
+		saveLiveFallback(log, results, *output)
+		os.Exit(exitCodeFor(err))
+	}
+	log.Info(fmt.Sprintf(T("%d問・%d人の成績を書き出しました: %s"), len(results.IDs), len(names), *output), "items", len(results.IDs), "players", len(names), "output", *output)
+}
+
+// saveLiveFallback は-outputに書き出せなかった成績を，記録が失われないよう一時ディレクトリのファイルに書き出す．
+// そのファイルにも書き出せない場合は標準出力に書き出す．
+func saveLiveFallback(log *slog.Logger, results *quiz_yaml_converter.PlayResults, output string) {
+	name := strings.TrimSuffix(filepath.Base(output), filepath.Ext(output))
+	f, err := os.CreateTemp("", name+"-*.csv")
+	if err == nil {
+		err = quiz_yaml_converter.WritePlayResults(f, results)
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+		if err == nil {
+			log.Warn(fmt.Sprintf(T("成績を代わりに書き出しました: %s"), f.Name()), "output", f.Name())
+			return
+		}
+		os.Remove(f.Name())
+	}
+	log.Error(T("代わりのファイルにも書き出せなかったため，成績を標準出力に書き出します"), "error", err)
+	quiz_yaml_converter.WritePlayResults(os.Stdout, results)
+}
+
+// playLive はキー入力を読み込みながら問題と正誤を表示する．終了のキーか入力の終わりで戻る．
+// rawがtrueの場合は，表示のたびに画面を消去する．
+func playLive(in *bufio.Reader, out io.Writer, sheet *quiz_yaml_converter.Scoresheet, raw bool) error {
+	numbers := quiz_yaml_converter.QuestionNumbers(sheet.Items(), quiz_yaml_converter.NumberingOptions{})
+	message := ""
+	for {
+		if raw {
+			fmt.Fprint(out, "\x1b[H\x1b[2J")
+		}
+		renderLive(out, sheet, numbers, message)
+		message = ""
+
+		action, player, err := readLiveKey(in)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		switch action {
+		case liveToggle:
+			if player >= len(sheet.Players()) {
+				message = fmt.Sprintf(T("プレイヤー%dはいません"), player+1)
+			} else {
+				sheet.Toggle(player)
+			}
+		case liveNext:
+			if !sheet.Next() {
+				message = T("最後の問題です。qで終了して成績を書き出します")
+			}
+		case livePrev:
+			if !sheet.Prev() {
+				message = T("最初の問題です")
+			}
+		case liveQuit:
+			return nil
+		}
+	}
+}
+
+// readLiveKey はキー入力を1つ読み込み，対応する操作を返す．liveToggleの場合は0始まりのプレイヤーの番号も返す．
+func readLiveKey(in *bufio.Reader) (liveAction, int, error) {
+	r, _, err := in.ReadRune()
+	if err != nil {
+		return liveNone, 0, err
+	}
+	switch {
+	case r >= '1' && r <= '9':
+		return liveToggle, int(r - '1'), nil
+	case r == ' ' || r == 'n':
+		return liveNext, 0, nil
+	case r == 'p':
+		return livePrev, 0, nil
+	case r == 'q' || r == keyCtrlC || r == keyCtrlD:
+		return liveQuit, 0, nil
+	case r == keyEscape:
+		// 矢印キー（ESC [ C，ESC [ D）
+		if next, _, err := in.ReadRune(); err != nil || next != '[' {
+			return liveNone, 0, err
+		}
+		switch arrow, _, err := in.ReadRune(); {
+		case err != nil:
+			return liveNone, 0, err
+		case arrow == 'C':
+			return liveNext, 0, nil
+		case arrow == 'D':
+			return livePrev, 0, nil
+		}
+	}
+	return liveNone, 0, nil
+}
+
+// renderLive は出題中の問題とプレイヤーごとの正誤を書き出す．
+func renderLive(w io.Writer, sheet *quiz_yaml_converter.Scoresheet, numbers []string, message string) {
+	item := sheet.Item()
+	fmt.Fprintf(w, T("Q%s（%d / %d）"), numbers[sheet.Current()], sheet.Current()+1, sheet.Len())
+	if item.Genre != "" {
+		fmt.Fprintf(w, "　[%s]", item.Genre)
+	}
+	fmt.Fprint(w, "\n\n")
+	fmt.Fprintln(w, strings.Join(quiz_yaml_converter.QuestionSegments(item), quiz_yaml_converter.SegmentMarker))
+	fmt.Fprintln(w)
+	fmt.Fprintf(w, "A. %s\n", item.Answer)
+	if item.Reading != "" {
+		fmt.Fprintf(w, T("読み: %s\n"), item.Reading)
+	}
+	if item.HasCriteria() {
+		fmt.Fprintf(w, T("判定: %s\n"), quiz_yaml_converter.FormatCriteria(item.Criteria))
+	}
+	fmt.Fprintln(w)
+
+	scores := sheet.Scores()
+	outcomes := sheet.Outcomes()
+	for p, name := range sheet.Players() {
+		mark := outcomes[p].String()
+		if mark == "" {
+			mark = "　"
+		}
+		fmt.Fprintf(w, T("  %d %s  %s  正解 %d\n"), p+1, mark, name, scores[p])
+	}
+	fmt.Fprintln(w)
+	if message != "" {
+		fmt.Fprintln(w, message)
+	}
+	fmt.Fprintln(w, T("1〜9: 正誤を切り替え  Space/→: 次の問題  ←: 前の問題  q: 終了して保存"))
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"sync"
+)
+
+// ログの出力形式
+const (
+	logFormatText = "text" // 人間が読むためのテキスト形式
+	logFormatJSON = "json" // CIやスクリプトで扱うためのJSON Lines形式
+)
+
+// newLogger はフラグの指定に応じたロガーを作成する．
+// quietの場合はエラーのみ，verboseの場合はデバッグメッセージも出力する．
+func newLogger(w io.Writer, format string, quiet, verbose bool) (*slog.Logger, error) {
+	level := slog.LevelInfo
+	switch {
+	case quiet && verbose:
+		return nil, errors.New(T("-quietと-verboseは同時に指定できません"))
+	case quiet:
+		level = slog.LevelError
+	case verbose:
+		level = slog.LevelDebug
+	}
+
+	switch format {
+	case logFormatText:
+		return slog.New(&textHandler{w: w, level: level, mu: &sync.Mutex{}}), nil
+	case logFormatJSON:
+		return slog.New(slog.NewJSONHandler(w, &slog.HandlerOptions{Level: level})), nil
+	default:
+		return nil, fmt.Errorf(T("サポートされていないログ形式です: %s（text, json）"), format)
+	}
+}
+
+// textHandler はメッセージを1行ずつ書き出すだけの簡素なslog.Handler．
+// "error"属性はメッセージの後ろに付け，それ以外の属性はデバッグレベルが
+// 有効な場合のみ key=value 形式で出力する．
+type textHandler struct {
+	w     io.Writer
+	level slog.Level
+	mu    *sync.Mutex
+	attrs []slog.Attr
+}
+
+func (h *textHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.level
+}
+
+func (h *textHandler) Handle(_ context.Context, r slog.Record) error {
+	var b strings.Builder
+	switch {
+	case r.Level >= slog.LevelError:
+		b.WriteString(T("エラー: "))
+	case r.Level >= slog.LevelWarn:
+		b.WriteString(T("警告: "))
+	}
+	b.WriteString(r.Message)
+
+	var details []string
+	appendAttr := func(a slog.Attr) bool {
+		if a.Key == "error" {
+			b.WriteString(": ")
+			b.WriteString(a.Value.String())
+		} else if h.level <= slog.LevelDebug {
+			details = append(details, a.Key+"="+a.Value.String())
+		}
+		return true
+	}
+	for _, a := range h.attrs {
+		appendAttr(a)
+	}
+	r.Attrs(appendAttr)
+	if len(details) > 0 {
+		b.WriteString(" (")
+		b.WriteString(strings.Join(details, " "))
+		b.WriteString(")")
+	}
+	b.WriteString("\n")
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	_, err := io.WriteString(h.w, b.String())
+	return err
+}
+
+func (h *textHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	clone := *h
+	clone.attrs = append(append([]slog.Attr{}, h.attrs...), attrs...)
+	return &clone
+}
+
+func (h *textHandler) WithGroup(_ string) slog.Handler {
+	return h
+}
+
+// warningRecorder は警告のメッセージをrecordに渡してから，元のslog.Handlerに出力を任せるslog.Handler（-summary）．
+// -quietで警告を表示しない場合も記録する．
+type warningRecorder struct {
+	slog.Handler
+	record func(message string)
+}
+
+func (h *warningRecorder) Enabled(ctx context.Context, level slog.Level) bool {
+	return level == slog.LevelWarn || h.Handler.Enabled(ctx, level)
+}
+
+func (h *warningRecorder) Handle(ctx context.Context, r slog.Record) error {
+	if r.Level == slog.LevelWarn {
+		h.record(r.Message)
+	}
+	if !h.Handler.Enabled(ctx, r.Level) {
+		return nil
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h *warningRecorder) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &warningRecorder{Handler: h.Handler.WithAttrs(attrs), record: h.record}
+}
+
+func (h *warningRecorder) WithGroup(name string) slog.Handler {
+	return &warningRecorder{Handler: h.Handler.WithGroup(name), record: h.record}
+}
@@ -8,53 +8,232 @@
 //
 // Usage:
 //
+//	converter serve -addr :8080
+//	converter diff old.yaml new.yaml
+//	converter ids quiz.yaml
+//	converter migrate quiz.yaml
+//	converter roundtrip quiz.yaml
+//	converter rounds -rounds 3 quiz.yaml
+//	converter choices -output choices.yaml quiz.yaml
+//	converter results -results results.csv quiz.yaml
+//	converter scores -results results.csv -output report.html quiz.yaml
+//	converter live -players 山田,佐藤 -output results.csv quiz.yaml
+//	converter buzzer -addr :8080 quiz.yaml
+//	converter dataset -seed 1 -output dataset quiz.yaml
+//	converter import -map question=1,answer=3 -output quiz.yaml legacy.csv
 //	converter -input quiz.yaml -output quiz.csv
 //	converter -input quiz.yaml -output quiz.html -format html
 //	converter -input quiz.yaml -output quiz.md -format markdown
+//	converter -input quiz.yaml -output questions.html -answer-key answers.html
 //	converter -input quiz.yaml -output custom.html -template my_template.html
+//	converter -input round1.yaml -input round2.yaml -output all.csv
 //
 // YAMLフォーマットを変換するメインスクリプト
 package main
 
 import (
+	"bufio"
+	"errors"
 	"flag"
 	"fmt"
+	"io"
+	"log/slog"
+	"maps"
 	"os"
 	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/m-uesaka/quiz-yaml-go/quiz_yaml_converter" // Import the quiz YAML converter package
 )
 
 func main() {
+	// 表示言語はフラグの説明文にも使うため最初に決める
+	if err := setLanguage(os.Args[1:]); err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(exitUsage)
+	}
+
+	// サブコマンドの場合
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "serve":
+			runServe(os.Args[2:])
+			return
+		case "diff":
+			runDiff(os.Args[2:])
+			return
+		case "ids":
+			runIDs(os.Args[2:])
+			return
+		case "migrate":
+			runMigrate(os.Args[2:])
+			return
+		case "grpc":
+			runGRPC(os.Args[2:])
+			return
+		case "roundtrip":
+			runRoundTrip(os.Args[2:])
+			return
+		case "rounds":
+			runRounds(os.Args[2:])
+			return
+		case "import":
+			runImport(os.Args[2:])
+			return
+		case "choices":
+			runChoices(os.Args[2:])
+			return
+		case "results":
+			runResults(os.Args[2:])
+			return
+		case "scores":
+			runScores(os.Args[2:])
+			return
+		case "live":
+			runLive(os.Args[2:])
+			return
+		case "buzzer":
+			runBuzzer(os.Args[2:])
+			return
+		case "dataset":
+			runDataset(os.Args[2:])
+			return
+		}
+	}
+
 	// フラグの定義
+	var inputFiles inputList
+	flag.Var(&inputFiles, "input", T("入力するYAMLファイルのパス（-markdown-dir未指定時は必須．複数回またはカンマ区切りで指定すると順に連結する）"))
+	filter := flag.String("filter", "", T("出力する問題の絞り込み条件（例: genre == \"歴史\" and len(question) > 40）"))
+	sortBy := flag.String("sort", "", T("出力する問題の並べ替えの基準（reading: 答えの読みの五十音順．未指定時はYAMLの順序）"))
+	var transforms commandList
+	flag.Var(&transforms, "transform", T("出力の前に問題データを変換する外部コマンド（JSONを標準入力で受け取り標準出力に返す．複数回指定すると順に適用する）"))
+	var outputFiles commandList
+	flag.Var(&outputFiles, "output", T("出力ファイルのパス（必須）．複数回指定すると，1度の読み込みから拡張子（.csv, .json, .html, .md）に応じた形式でそれぞれに出力する"))
+	var templateVars commandList
+	flag.Var(&templateVars, "var", T("テンプレートに{{.Vars.名前}}として渡す変数（名前=値．=を省略すると同じ名前の環境変数の値を使う．複数回指定できる）"))
+	var questionEndings commandList
+	flag.Var(&questionEndings, "question-ending", T("問題文の末尾として認める形式の正規表現（例: でしょう？）．複数回指定でき，いずれにも一致しない問題に警告を表示する"))
+	var ngWordFiles commandList
+	flag.Var(&ngWordFiles, "ng-words", T("禁止語のリストのファイル（1行に1語）．複数回指定でき，問題文とコメントに含まれる禁止語に警告を表示する"))
+	onConflict := flag.String("on-conflict", "", T("複数の入力ファイルに同じidの問題がある場合の扱い（keep-first, keep-newest, error, interactive．未指定時はすべて残す）"))
+	maxSameAnswer := flag.Int("max-same-answer", 0, T("同じ答え（表記の揺れを除く）の問題の上限．超えた場合に警告を表示する（0はチェックしない）"))
+	var stopwordFiles commandList
+	flag.Var(&stopwordFiles, "glossary-stopwords", T("用語集に含めない語のリストのファイル（1行に1語）．複数回指定できる"))
+
 	var (
-		inputFile   = flag.String("input", "", "入力するYAMLファイルのパス（-markdown-dir未指定時は必須）")
-		markdownDir = flag.String("markdown-dir", "", "集約するMarkdownファイルが置かれたディレクトリのパス（指定時はMarkdown→YAML変換モードになる）")
-		recursive   = flag.Bool("recursive", false, "-markdown-dir指定時，サブディレクトリも再帰的に辿るかどうか")
-		outputFile  = flag.String("output", "", "出力ファイルのパス（必須）")
-		format      = flag.String("format", "csv", "出力フォーマット（csv, html, markdown）")
-		template    = flag.String("template", "", "テンプレートファイルのパス（formatに関係なく使用）")
-		validate    = flag.Bool("validate", false, "YAMLファイルのフォーマットをバリデーションのみ実行")
-		help        = flag.Bool("help", false, "ヘルプを表示")
+		markdownDir = flag.String("markdown-dir", "", T("集約するMarkdownファイルが置かれたディレクトリのパス（指定時はMarkdown→YAML変換モードになる）"))
+		recursive   = flag.Bool("recursive", false, T("-markdown-dir指定時，サブディレクトリも再帰的に辿るかどうか"))
+		format      = flag.String("format", "csv", fmt.Sprintf(T("出力フォーマット（%s，またはexec:コマンドで外部コマンド）"), strings.Join(quiz_yaml_converter.FormatterNames(), ", ")))
+		template    = flag.String("template", "", fmt.Sprintf(T("テンプレートファイルのパス（formatに関係なく使用．-で標準入力から読み込む．builtin:名前で組み込みのテンプレート（%s）を使う）"), strings.Join(quiz_yaml_converter.BuiltinTemplateNames(), ", ")))
+		tmplString  = flag.String("template-string", "", T("テンプレートの内容を直接指定する（-templateの代わりに使用）"))
+		layout      = flag.String("layout", "", T("-templateの基にするレイアウト（html, markdown，またはファイルのパス）．-templateではブロックを{{define}}で置き換える"))
+		tmplDelims  = flag.String("template-delims", "", T("テンプレートの左右の区切り文字をカンマでつないで指定（例: \"[[,]]\"．未指定時は{{と}}）"))
+		tmplTimeout = flag.Duration("template-timeout", 0, T("テンプレートの実行の制限時間（例: 10s．0は無制限）"))
+		maxOutput   = flag.String("max-output-size", "", T("テンプレートの出力の最大サイズ（例: 10MB．未指定時は無制限）"))
+		safeTmpl    = flag.Bool("safe-templates", false, T("テンプレートで実行環境に依存する関数（nowなど）を使えないようにする"))
+		validate    = flag.Bool("validate", false, T("YAMLファイルのフォーマットをバリデーションのみ実行"))
+		validateFmt = flag.String("validate-format", "text", T("-validateの結果の出力形式（text, json．jsonは標準出力に規則名や行番号を含むレポートを出力する）"))
+		keepOrder   = flag.Bool("preserve-criteria-order", false, T("正誤判定をYAMLに書かれたキー順序で出力する（既定はok→ng→repeat）"))
+		quotes      = flag.String("quotes", "", T("正誤判定とテンプレート関数addQuotesで項目を囲む引用符（例: 『』，\"“,”\"．未指定時は-criteria-localeの引用符）"))
+		readingPace = flag.Float64("reading-pace", quiz_yaml_converter.DefaultReadingPace, T("読み上げ時間の見積もりに使う読み上げの速さ（1秒あたりのモーラ数）"))
+		qrURL       = flag.String("qr-url", "", T("テンプレート関数qrURLで問題ごとのQRコードに埋め込むURL（{id}を問題IDに置き換える．未指定時は問題ID）"))
+		slugFrom    = flag.String("slug-from", "id", T("テンプレート関数slugで問題のスラッグの元にする値（id, answer）"))
+		rubyStyle   = flag.String("ruby-style", "ruby", T("HTML出力での読みの表示方法（ruby: ルビ，paren: 括弧書き）"))
+		criteriaLoc = flag.String("criteria-locale", "ja", T("正誤判定の語句と引用符の言語（ja, en．enでは\"X\" is incorrectのように出力する）"))
+		columns     = flag.String("columns", "", T("CSVに出力する列をカンマ区切りで指定（id, question, answer, reading, spell, genre, difficulty, tags, comments, criteria, source, reference, author, license, question_length, answer_length, ok_count）"))
+		comments    = flag.Bool("comments", false, T("CSVの末尾にcomments列を追加する"))
+		commentSep  = flag.String("comment-sep", "", T("CSVのcomments列で複数のコメントをつなぐ文字列（未指定時は改行）"))
+		textFields  = flag.String("text-fields", "", T("-format textで1行に出力する項目をカンマ区切りで指定（-columnsと同じ項目名．未指定時はquestion,answer）"))
+		textSep     = flag.String("text-sep", quiz_yaml_converter.DefaultTextSeparator, T("-format textで項目をつなぐ文字列"))
+		criteriaSep = flag.String("criteria-sep", "", T("CSVのcriteria列で正誤判定の区分をつなぐ文字列（未指定時は／）"))
+		critItemSep = flag.String("criteria-item-sep", "", T("CSVのcriteria列で各区分の項目をつなぐ文字列（指定時は項目を「」で囲まない）"))
+		noHeader    = flag.Bool("no-header", false, T("CSVのヘッダー行を出力しない"))
+		headers     = flag.String("header-labels", "", T("CSVのヘッダー名を変更（例: question=問題,answer=答え．jaで日本語ラベル）"))
+		encoding    = flag.String("encoding", "utf8", T("CSVの文字コード（utf8, utf8-bom, sjis）"))
+		newlines    = flag.String("newlines", "keep", T("CSVのフィールド内の改行の扱い（keep: そのまま，escape: \\nに置き換え，space: 空白にまとめる）"))
+		crlf        = flag.Bool("crlf", false, T("CSVの改行コードをCRLFにする"))
+		quoteAll    = flag.Bool("quote-all", false, T("CSVのすべてのフィールドを\"で囲む"))
+		escapeFx    = flag.Bool("escape-formulas", false, T("=, +, -, @で始まるCSVフィールドの先頭に'を付けて数式として解釈されないようにする"))
+		numStart    = flag.Int("number-start", 1, T("テンプレートに渡す問題番号の開始値"))
+		numWidth    = flag.Int("number-width", 0, T("問題番号をゼロ埋めする桁数（0はゼロ埋めしない．最大10）"))
+		numBy       = flag.String("number-by", "", T("問題番号を振り直す単位（genre: ジャンルごと，document: YAMLのドキュメント（---区切り）ごとに1-1, 1-2, 2-1…と番号を付ける）"))
+		fixSpace    = flag.Bool("fix-whitespace", false, T("問題文と答えの行末の空白，語の間の全角スペース，ゼロ幅文字，改行コードの混在を修正して出力する"))
+		punctuation = flag.String("punctuation", "", T("統一する句読点・記号の表記（academic: ，．，japanese: 、。，またはcomma=，,period=．,question=？,exclamation=！,parens=（）の形式）．異なる表記に警告を表示する"))
+		fixPunct    = flag.Bool("fix-punctuation", false, T("問題文と答えの句読点・記号を-punctuationの表記に修正して出力する"))
+		splitAnswer = flag.Bool("split-answer", false, T("答えの末尾の括弧書き（例: 国際連合（国連））を別解としてcriteria.okに移す"))
+		cloze       = flag.Bool("cloze", false, T("問題文に含まれる答えと別解をAnkiの穴埋め形式（{{c1::答え}}）に置き換える"))
+		requireSrc  = flag.Bool("require-sources", false, T("出典（sourceまたはreference）が書かれていない問題をエラーにする"))
+		readingCmd  = flag.String("reading-command", "", T("答えの読みを求める形態素解析のコマンド（例: \"mecab -Oyomi\"．-tags kagomeでビルドした場合はbuiltin:kagomeも指定可）．readingが未設定の問題に読みを補い，書かれた読みと異なる場合は警告を表示する"))
+		assignIDs   = flag.Bool("assign-ids", false, T("idが未設定の問題に問題文と答えから決まるIDを割り当てて出力する（CSVで-columns未指定時はid列を追加）"))
+		glossary    = flag.Bool("glossary", false, T("HTMLとMarkdownの末尾に，複数の問題の問題文に現れる語の一覧（用語集）を出力する"))
+		glossaryMin = flag.Int("glossary-min", 2, T("-glossaryで用語集に含める語が現れる問題の最小数"))
+		gojuonIndex = flag.Bool("gojuon-index", false, T("HTMLの末尾（-per-page指定時は目次）に答えの読みの五十音順の索引を出力する"))
+		perPage     = flag.Int("per-page", 0, T("HTMLを指定した問題数ごとのページに分割し，-outputのディレクトリにindex.htmlとpage-N.htmlを出力する"))
+		splitBy     = flag.String("split-by", "", T("タグ（tag）またはジャンル（genre）ごとに出力を分割し，-outputのディレクトリに1ファイルずつと問題数の一覧（index.csv）を出力する"))
+		answerKey   = flag.String("answer-key", "", T("答えを載せた解答のHTMLを書き出すパス．指定すると-outputには答えを載せない問題用紙のHTMLを書き出し，2つを互いにリンクする"))
+		bundle      = flag.Bool("bundle", false, T("-outputをzipファイルとし，変換結果と変換結果から参照している画像・音声ファイルを1つにまとめて出力する"))
+		noClobber   = flag.Bool("no-clobber", false, T("出力ファイルが既に存在する場合は上書きせずにエラーにする"))
+		force       = flag.Bool("force", false, T("-no-clobberや入力ファイルと同じパスへの出力の確認を無視して上書きする"))
+		validFirst  = flag.Bool("validate-first", false, T("変換の前に-validateと同じバリデーションを行い，エラーがある場合は何も出力せずに終了する"))
+		skipErrors  = flag.Bool("skip-errors", false, T("読み込めない問題やバリデーションに失敗する問題を取り除いて変換し，取り除いた問題を最後に表示する"))
+		summaryFile = flag.String("summary", "", T("変換の集計（読み込んだ・出力した・取り除いた問題の数，警告，出力ファイルのサイズ，所要時間）をJSONで書き出すファイルのパス（-で標準出力）"))
+		cacheFile   = flag.String("cache", "", T("変換結果のキャッシュファイルのパス．入力・テンプレート・引数が前回と同じで出力が存在する場合は変換を省略する"))
+		quiet       = flag.Bool("quiet", false, T("エラー以外のメッセージを出力しない"))
+		verbose     = flag.Bool("verbose", false, T("詳細なメッセージを出力する"))
+		logFormat   = flag.String("log-format", logFormatText, T("メッセージの出力形式（text, json）"))
+		help        = flag.Bool("help", false, T("ヘルプを表示"))
 	)
 
+	addLangFlag(flag.CommandLine)
+
 	// ヘルプメッセージをカスタマイズ
 	flag.Usage = func() {
-		fmt.Fprintf(os.Stderr, "使用法: %s [オプション]\n\n", filepath.Base(os.Args[0]))
-		fmt.Fprintf(os.Stderr, "クイズYAMLファイルを指定されたフォーマットに変換します。\n\n")
-		fmt.Fprintf(os.Stderr, "オプション:\n")
+		fmt.Fprintf(os.Stderr, T("使用法: %s [オプション]\n\n"), filepath.Base(os.Args[0]))
+		fmt.Fprint(os.Stderr, T("クイズYAMLファイルを指定されたフォーマットに変換します。\n\n"))
+		fmt.Fprint(os.Stderr, T("オプション:\n"))
 		flag.PrintDefaults()
-		fmt.Fprintf(os.Stderr, "\n例:\n")
+		fmt.Fprint(os.Stderr, T("\n例:\n"))
 		fmt.Fprintf(os.Stderr, "  %s -input quiz.yaml -output quiz.csv\n", filepath.Base(os.Args[0]))
 		fmt.Fprintf(os.Stderr, "  %s -input quiz.yaml -output quiz.html -format html\n", filepath.Base(os.Args[0]))
 		fmt.Fprintf(os.Stderr, "  %s -input quiz.yaml -output quiz.md -template custom.tmpl\n", filepath.Base(os.Args[0]))
+		fmt.Fprintf(os.Stderr, "  %s -input quiz.yaml -output quiz.csv -output quiz.html -output quiz.md\n", filepath.Base(os.Args[0]))
+		fmt.Fprintf(os.Stderr, "  %s -input quiz.yaml -output site -format html -per-page 20\n", filepath.Base(os.Args[0]))
+		fmt.Fprintf(os.Stderr, "  %s -input quiz.yaml -output questions.html -answer-key answers.html\n", filepath.Base(os.Args[0]))
+		fmt.Fprintf(os.Stderr, "  %s -input quiz.yaml -output by-tag -split-by tag\n", filepath.Base(os.Args[0]))
 		fmt.Fprintf(os.Stderr, "  %s -input quiz.yaml -validate\n", filepath.Base(os.Args[0]))
+		fmt.Fprintf(os.Stderr, "  %s -input quiz.yaml -validate -validate-format json\n", filepath.Base(os.Args[0]))
+		fmt.Fprintf(os.Stderr, "  %s -input round1.yaml,round2.yaml -output all.csv\n", filepath.Base(os.Args[0]))
 		fmt.Fprintf(os.Stderr, "  %s -markdown-dir path/to/quiz -output quiz.yaml\n", filepath.Base(os.Args[0]))
 		fmt.Fprintf(os.Stderr, "  %s -markdown-dir path/to/quiz -recursive -output quiz.yaml\n", filepath.Base(os.Args[0]))
+		fmt.Fprint(os.Stderr, T("\nサブコマンド:\n"))
+		fmt.Fprintf(os.Stderr, T("  serve    変換APIを提供するHTTPサーバーを起動する（詳細は %s serve -help）\n"), filepath.Base(os.Args[0]))
+		fmt.Fprintf(os.Stderr, T("  diff     2つのYAMLファイルの問題の差分を表示する（詳細は %s diff -help）\n"), filepath.Base(os.Args[0]))
+		fmt.Fprintf(os.Stderr, T("  ids      問題文と答えから決まるIDを割り当ててYAMLファイルに書き戻す（詳細は %s ids -help）\n"), filepath.Base(os.Args[0]))
+		fmt.Fprintf(os.Stderr, T("  migrate  古い形式のYAMLファイルを現在の形式に書き換える（詳細は %s migrate -help）\n"), filepath.Base(os.Args[0]))
+		fmt.Fprintf(os.Stderr, T("  grpc     変換APIを提供するgRPCサーバーを起動する（詳細は %s grpc -help）\n"), filepath.Base(os.Args[0]))
+		fmt.Fprintf(os.Stderr, T("  roundtrip YAML→CSV→YAMLの往復変換で失われるフィールドを表示する（詳細は %s roundtrip -help）\n"), filepath.Base(os.Args[0]))
+		fmt.Fprintf(os.Stderr, T("  rounds   問題をジャンルと難易度が偏らないように複数のラウンドに振り分ける（詳細は %s rounds -help）\n"), filepath.Base(os.Args[0]))
+		fmt.Fprintf(os.Stderr, T("  import   CSVファイルを読み込んでYAMLファイルに書き出す（詳細は %s import -help）\n"), filepath.Base(os.Args[0]))
+		fmt.Fprintf(os.Stderr, T("  choices  ほかの問題の答えを誤答として加え，多肢選択の問題にする（詳細は %s choices -help）\n"), filepath.Base(os.Args[0]))
+		fmt.Fprintf(os.Stderr, T("  results  成績のCSVから問題ごとの正答率を集計し，難易度を見積もる（詳細は %s results -help）\n"), filepath.Base(os.Args[0]))
+		fmt.Fprintf(os.Stderr, T("  scores   成績のCSVからプレイヤーごと・問題ごとの成績表を書き出す（詳細は %s scores -help）\n"), filepath.Base(os.Args[0]))
+		fmt.Fprintf(os.Stderr, T("  live     問題を1問ずつ表示し，プレイヤーごとの正誤を記録する（詳細は %s live -help）\n"), filepath.Base(os.Args[0]))
+		fmt.Fprintf(os.Stderr, T("  buzzer   ブラウザから参加できる早押しクイズのサーバーを起動する（詳細は %s buzzer -help）\n"), filepath.Base(os.Args[0]))
+		fmt.Fprintf(os.Stderr, T("  dataset  問題を学習・検証・評価データに分割してJSON Linesで書き出す（詳細は %s dataset -help）\n"), filepath.Base(os.Args[0]))
 	}
 
 	// フラグをパース
 	flag.Parse()
+	started := time.Now()
+	// -outputを複数回指定した場合，outputFileは最初の出力先となる
+	var outputFile string
+	if len(outputFiles) > 0 {
+		outputFile = outputFiles[0]
+	}
 
 	// ヘルプフラグがセットされている場合
 	if *help {
@@ -62,101 +241,1025 @@ func main() {
 		return
 	}
 
-	// Markdown→YAML集約モードの場合
-	if *markdownDir != "" {
-		if *inputFile != "" {
-			fmt.Fprintf(os.Stderr, "❌ エラー: -markdown-dirと-inputは同時に指定できません\n\n")
-			flag.Usage()
-			os.Exit(1)
+	log, err := newLogger(os.Stderr, *logFormat, *quiet, *verbose)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, T("エラー: %v\n"), err)
+		os.Exit(exitUsage)
+	}
+
+	// fail はエラーを出力して終了する．usageがtrueの場合は使用法も表示する．
+	// 問題ごとのエラー（ItemError）を含む場合は，問題ごとに1行ずつ表示する．
+	// 終了コードはエラーの種類に応じて決まる（exitCodeFor参照）．
+	fail := func(msg string, err error, usage bool) {
+		if itemErrs := quiz_yaml_converter.ItemErrors(err); len(itemErrs) > 0 {
+			for _, e := range itemErrs {
+				if e.Question != "" {
+					log.Error(fmt.Sprintf(T("問題 %d（%s）: %v"), e.Index, e.Question, e.Err), "item", e.Index, "question", e.Question)
+				} else {
+					log.Error(fmt.Sprintf(T("問題 %d: %v"), e.Index, e.Err), "item", e.Index)
+				}
+			}
+			log.Error(fmt.Sprintf(T("%s（%d問でエラー）"), msg, len(itemErrs)), "errors", len(itemErrs))
+		} else if err != nil {
+			log.Error(msg, "error", err)
+		} else {
+			log.Error(msg)
 		}
-		if *outputFile == "" {
-			fmt.Fprintf(os.Stderr, "❌ エラー: 出力ファイルが指定されていません\n\n")
+		if usage {
 			flag.Usage()
-			os.Exit(1)
+			os.Exit(exitUsage)
 		}
-		err := quiz_yaml_converter.ConvertMarkdownDirToYAML(*markdownDir, *outputFile, *recursive)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "❌ エラー: %v\n", err)
-			os.Exit(1)
+		os.Exit(exitCodeFor(err))
+	}
+
+	if *summaryFile != "" && (*markdownDir != "" || *validate) {
+		fail(T("-summaryは-markdown-dir，-validateと同時に指定できません"), nil, true)
+	}
+
+	// Markdown→YAML集約モードの場合
+	if *markdownDir != "" {
+		if len(inputFiles) > 0 {
+			fail(T("-markdown-dirと-inputは同時に指定できません"), nil, true)
 		}
-		fmt.Printf("✅ Markdown集約完了: %s → %s\n", *markdownDir, *outputFile)
+		runMarkdownDir(log, fail, *markdownDir, *recursive, outputFiles, *noClobber, *force)
 		return
 	}
 
 	// 必須パラメータの検証
-	if *inputFile == "" {
-		fmt.Fprintf(os.Stderr, "❌ エラー: 入力ファイルが指定されていません\n\n")
-		flag.Usage()
-		os.Exit(1)
+	if len(inputFiles) == 0 {
+		fail(T("入力ファイルが指定されていません"), nil, true)
+	}
+	inputFile := inputFiles.String()
+
+	checks := itemChecks{
+		fail:           fail,
+		requireSources: *requireSrc,
+		splitAnswer:    *splitAnswer,
+		cloze:          *cloze,
+		fixPunctuation: *fixPunct,
+		maxSameAnswer:  *maxSameAnswer,
+	}
+	if checks.punctuation, err = quiz_yaml_converter.ParsePunctuationStyle(*punctuation); err != nil {
+		fail(T("-punctuationの指定が正しくありません"), err, true)
+	}
+	if *fixPunct && checks.punctuation.IsZero() {
+		fail(T("-fix-punctuationは-punctuationと合わせて指定してください"), nil, true)
+	}
+	if checks.endings, err = quiz_yaml_converter.ParseQuestionEndings(questionEndings); err != nil {
+		fail(T("-question-endingの指定が正しくありません"), err, true)
+	}
+	if checks.ngWords, err = quiz_yaml_converter.LoadNGWords(ngWordFiles); err != nil {
+		fail(T("-ng-wordsのファイルを読み込めませんでした"), err, false)
+	}
+	if *maxSameAnswer < 0 {
+		fail(T("-max-same-answerには0以上の値を指定してください"), nil, true)
+	}
+	if *readingCmd != "" {
+		if checks.reading, err = quiz_yaml_converter.ParseReadingAnalyzer(*readingCmd); err != nil {
+			fail(T("-reading-commandの指定が正しくありません"), err, true)
+		}
 	}
 
 	// バリデーションのみの場合
 	if *validate {
-		fmt.Printf("🔍 YAMLファイルをバリデーションしています: %s\n", *inputFile)
-		result := quiz_yaml_converter.ValidateYAMLFile(*inputFile)
-
-		if result.IsValid {
-			fmt.Printf("✅ バリデーション成功: %d問のクイズデータが正しく読み込めました\n", result.Items)
-		} else {
-			fmt.Printf("❌ バリデーション失敗: %d個のエラーが見つかりました\n", len(result.Errors))
-			for _, err := range result.Errors {
-				fmt.Fprintf(os.Stderr, "  • %s\n", err)
-			}
-			os.Exit(1)
-		}
+		runValidate(log, fail, inputFiles, checks, *validateFmt)
 		return
 	}
 
 	// 変換モードの場合は出力ファイルが必須
-	if *outputFile == "" {
-		fmt.Fprintf(os.Stderr, "❌ エラー: 出力ファイルが指定されていません\n\n")
-		flag.Usage()
-		os.Exit(1)
+	if outputFile == "" {
+		fail(T("出力ファイルが指定されていません"), nil, true)
+	}
+	// products は変換で書き出すファイル（-answer-keyの解答を含む）．出力先の確認，キャッシュ，-summaryに使う
+	products := slices.Clone(outputFiles)
+	if *answerKey != "" {
+		products = append(products, *answerKey)
 	}
 
-	// テンプレートファイルが指定されている場合はテンプレート変換を実行
-	if *template != "" {
-		err := quiz_yaml_converter.Convert(*inputFile, *outputFile, *template)
+	// -summary指定時は，変換に成功して関数を抜けるときに集計を書き出す（失敗時はos.Exitで終了するため書き出さない）．
+	// 警告は表示するメッセージをそのまま記録する
+	var summary *quiz_yaml_converter.ConversionSummary
+	if *summaryFile != "" {
+		summary = quiz_yaml_converter.NewConversionSummary(inputFiles, started)
+		log = slog.New(&warningRecorder{Handler: log.Handler(), record: summary.AddWarning})
+		defer func() {
+			err := summary.Finish(products, time.Now())
+			switch {
+			case err != nil:
+			case *summaryFile == "-":
+				err = summary.WriteJSON(os.Stdout)
+			default:
+				err = summary.WriteJSONFile(*summaryFile)
+			}
+			if err != nil {
+				fail(T("変換の集計を書き出せませんでした"), err, false)
+			}
+		}()
+	}
+
+	// 標準入力や-template-stringで渡したテンプレートは，templateTextとしてファイルの代わりに使う．
+	// *templateはテンプレートの有無の判定とメッセージの表示に使う名前となる
+	var templateText string
+	switch {
+	case *tmplString != "" && *template != "":
+		fail(T("-templateと-template-stringは同時に指定できません"), nil, true)
+	case *tmplString != "":
+		templateText, *template = *tmplString, "-template-string"
+	case *template == "-":
+		content, err := io.ReadAll(os.Stdin)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "❌ エラー: %v\n", err)
-			os.Exit(1)
+			fail(T("標準入力からテンプレートを読み込めませんでした"), err, false)
 		}
-		fmt.Printf("✅ テンプレート変換完了: %s + %s → %s\n", *inputFile, *template, *outputFile)
-		return
+		if len(content) == 0 {
+			fail(T("標準入力から読み込んだテンプレートが空です"), nil, true)
+		}
+		templateText = string(content)
+	}
+	// 環境変数から値を取る-varもあるため，キャッシュの確認の前に値を決める
+	vars, err := quiz_yaml_converter.ParseTemplateVars(templateVars, os.LookupEnv)
+	if err != nil {
+		fail(T("-varの指定が正しくありません"), err, true)
 	}
 
-	// フォーマットに基づいて変換処理を実行
-	switch *format {
-	case "csv":
-		err := quiz_yaml_converter.Convert(*inputFile, *outputFile, "")
+	// キャッシュを使う場合，前回の変換から入力・テンプレート・引数が変わっていなければ変換を省略する．
+	// 変換に成功して関数を抜けるときにキャッシュを更新する（失敗時はos.Exitで終了するため更新しない）
+	if *cacheFile != "" {
+		cache, err := quiz_yaml_converter.LoadBuildCache(*cacheFile)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "❌ エラー: %v\n", err)
-			os.Exit(1)
+			fail(T("キャッシュファイルを読み込めませんでした"), err, false)
+		}
+		args := cacheArgs()
+		if templateText != "" {
+			args = append(args, "template-text="+templateText)
+		}
+		for _, name := range slices.Sorted(maps.Keys(vars)) {
+			args = append(args, "var "+name+"="+vars[name])
 		}
-		fmt.Printf("✅ CSV変換完了: %s → %s\n", *inputFile, *outputFile)
+		key, err := quiz_yaml_converter.BuildKey(cacheFiles(inputFiles, *template, *layout), args)
+		if err != nil {
+			fail(T("YAMLファイルの読み込みに失敗しました"), err, false)
+		}
+		upToDate := !*force
+		for _, output := range products {
+			upToDate = upToDate && cache.UpToDate(output, key)
+		}
+		if upToDate {
+			if summary != nil {
+				summary.Cached = true
+			}
+			log.Info(fmt.Sprintf(T("出力は最新のため変換を省略しました: %s"), strings.Join(products, ", ")), "input", inputFile, "output", products.String())
+			return
+		}
+		defer func() {
+			for _, output := range products {
+				cache.Record(output, key)
+			}
+			if err := cache.Save(); err != nil {
+				log.Warn(T("キャッシュファイルを書き出せませんでした"), "error", err)
+			}
+		}()
+	}
 
-	case "html":
-		templatePath := "templates/quiz_template.html"
-		err := quiz_yaml_converter.Convert(*inputFile, *outputFile, templatePath)
+	// 不完全なデータから出力しないよう，何かを書き出す前にバリデーションする
+	if *validFirst {
+		if *skipErrors {
+			fail(T("-validate-firstと-skip-errorsは同時に指定できません"), nil, true)
+		}
+		log.Debug(fmt.Sprintf(T("YAMLファイルをバリデーションしています: %s"), inputFile), "input", inputFile)
+		if result := quiz_yaml_converter.ValidateYAMLFiles(inputFiles); !result.IsValid {
+			logValidationErrors(log, result, inputFile)
+			os.Exit(exitCodeFor(result.Err()))
+		}
+	}
+
+	// ページ分割時や出力の分割時は-outputがディレクトリとなるため，ファイルごとに-no-clobberを確認する
+	if *perPage == 0 && *splitBy == "" {
+		for _, input := range inputFiles {
+			for _, output := range products {
+				if err := checkOutputPath(input, output, *noClobber, *force); err != nil {
+					fail(T("出力先を確認できませんでした"), err, false)
+				}
+			}
+		}
+	}
+
+	opts := quiz_yaml_converter.ConvertOptions{
+		PreserveCriteriaOrder: *keepOrder,
+		NoClobber:             *noClobber && !*force,
+		TemplateText:          templateText,
+		Vars:                  vars,
+		AssignIDs:             *assignIDs,
+		GojuonIndex:           *gojuonIndex,
+		Glossary:              quiz_yaml_converter.GlossaryOptions{Enabled: *glossary, MinQuestions: *glossaryMin},
+	}
+	if opts.Glossary.Stopwords, err = quiz_yaml_converter.LoadStopwords(stopwordFiles); err != nil {
+		fail(T("-glossary-stopwordsのファイルを読み込めませんでした"), err, false)
+	}
+	opts.CSV.IncludeComments = *comments
+	opts.CSV.CommentSeparator = *commentSep
+	opts.CSV.CriteriaSeparator = *criteriaSep
+	opts.CSV.CriteriaItemSeparator = *critItemSep
+	opts.CSV.NoHeader = *noHeader
+	opts.CSV.CRLF = *crlf
+	opts.CSV.AlwaysQuote = *quoteAll
+	opts.CSV.EscapeFormulas = *escapeFx
+	enc, err := quiz_yaml_converter.ParseEncoding(*encoding)
+	if err != nil {
+		fail(T("-encodingの指定が正しくありません"), err, false)
+	}
+	opts.CSV.Encoding = enc
+	if opts.CSV.Newlines, err = quiz_yaml_converter.ParseNewlineMode(*newlines); err != nil {
+		fail(T("-newlinesの指定が正しくありません"), err, false)
+	}
+	if opts.CriteriaLanguage, err = quiz_yaml_converter.ParseLanguage(*criteriaLoc); err != nil {
+		fail(T("-criteria-localeの指定が正しくありません"), err, false)
+	}
+	if *readingPace <= 0 {
+		fail(T("-reading-paceには正の数を指定してください"), nil, false)
+	}
+	opts.ReadingPace = *readingPace
+	opts.QRURL = *qrURL
+	if opts.SlugFrom, err = quiz_yaml_converter.ParseSlugSource(*slugFrom); err != nil {
+		fail(T("-slug-fromの指定が正しくありません"), err, false)
+	}
+	if opts.RubyStyle, err = quiz_yaml_converter.ParseRubyStyle(*rubyStyle); err != nil {
+		fail(T("-ruby-styleの指定が正しくありません"), err, false)
+	}
+	if *quotes != "" {
+		if opts.Quotes, err = quiz_yaml_converter.ParseQuotes(*quotes); err != nil {
+			fail(T("-quotesの指定が正しくありません"), err, false)
+		}
+	}
+	if *headers != "" {
+		labels, err := quiz_yaml_converter.ParseCSVHeaderLabels(*headers)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "❌ エラー: %v\n", err)
-			os.Exit(1)
+			fail(T("-header-labelsの指定が正しくありません"), err, false)
 		}
-		fmt.Printf("✅ HTML変換完了: %s → %s\n", *inputFile, *outputFile)
+		opts.CSV.HeaderLabels = labels
+	}
+	if *textFields != "" {
+		if opts.Text.Fields, err = quiz_yaml_converter.ParseTextFields(*textFields); err != nil {
+			fail(T("-text-fieldsの指定が正しくありません"), err, false)
+		}
+	}
+	opts.Text.Separator = *textSep
+	if *columns != "" {
+		cols, err := quiz_yaml_converter.ParseCSVColumns(*columns)
+		if err != nil {
+			fail(T("-columnsの指定が正しくありません"), err, false)
+		}
+		opts.CSV.Columns = cols
+	}
+	if *layout != "" {
+		if *template == "" {
+			fail(T("-layoutは-templateと合わせて指定してください"), nil, true)
+		}
+		opts.Layout = *layout
+	}
+	if *tmplDelims != "" {
+		if *template == "" {
+			fail(T("-template-delimsは-templateと合わせて指定してください"), nil, true)
+		}
+		if opts.TemplateDelims, err = quiz_yaml_converter.ParseTemplateDelims(*tmplDelims); err != nil {
+			fail(T("-template-delimsの指定が正しくありません"), err, true)
+		}
+	}
+	opts.TemplateTimeout = *tmplTimeout
+	opts.SafeTemplates = *safeTmpl
+	if *maxOutput != "" {
+		if opts.MaxOutputBytes, err = quiz_yaml_converter.ParseByteSize(*maxOutput); err != nil {
+			fail(T("-max-output-sizeの指定が正しくありません"), err, true)
+		}
+	}
+	if *requireSrc {
+		opts.Pipeline = append(opts.Pipeline, quiz_yaml_converter.RequireSources)
+	}
+	if *fixSpace {
+		opts.Pipeline = append(opts.Pipeline, quiz_yaml_converter.FixWhitespace)
+	}
+	checks.logWarnings(log, inputFiles)
+	if *fixPunct {
+		opts.Pipeline = append(opts.Pipeline, quiz_yaml_converter.FixPunctuation(checks.punctuation))
+	}
+	if *splitAnswer {
+		opts.Pipeline = append(opts.Pipeline, quiz_yaml_converter.SplitAnswerAlternatives)
+	}
+	if *cloze {
+		opts.Pipeline = append(opts.Pipeline, quiz_yaml_converter.ClozeDeletion)
+	}
+	if checks.reading != nil {
+		opts.Pipeline = append(opts.Pipeline, quiz_yaml_converter.FillReadings(checks.reading))
+	}
+	if *filter != "" {
+		expr, err := quiz_yaml_converter.ParseFilter(*filter)
+		if err != nil {
+			fail(T("-filterの指定が正しくありません"), err, true)
+		}
+		opts.Pipeline = append(opts.Pipeline, expr.Stage())
+	}
+	for _, spec := range transforms {
+		stage, err := quiz_yaml_converter.ParseExecTransform(spec)
+		if err != nil {
+			fail(T("-transformの指定が正しくありません"), err, true)
+		}
+		opts.Pipeline = append(opts.Pipeline, stage)
+	}
+	sortKey, err := quiz_yaml_converter.ParseSortKey(*sortBy)
+	if err != nil {
+		fail(T("-sortの指定が正しくありません"), err, true)
+	}
+	if sortKey != quiz_yaml_converter.SortNone {
+		opts.Pipeline = append(opts.Pipeline, quiz_yaml_converter.SortItems(sortKey))
+	}
+	if *numWidth < 0 || *numWidth > quiz_yaml_converter.MaxNumberWidth {
+		fail(fmt.Sprintf(T("-number-widthには0から%dまでの数を指定してください"), quiz_yaml_converter.MaxNumberWidth), nil, true)
+	}
+	opts.Numbering = quiz_yaml_converter.NewNumberingOptions(*numStart)
+	opts.Numbering.Width = *numWidth
+	if opts.Numbering.SectionBy, err = quiz_yaml_converter.ParseNumberSection(*numBy); err != nil {
+		fail(T("-number-byの指定が正しくありません"), err, false)
+	}
+	if summary != nil {
+		opts = summary.TrackItems(opts)
+	}
 
-	case "markdown", "md":
-		templatePath := "templates/quiz_template.md"
-		err := quiz_yaml_converter.Convert(*inputFile, *outputFile, templatePath)
+	conflictStrategy, err := quiz_yaml_converter.ParseConflictStrategy(*onConflict)
+	if err != nil {
+		fail(T("-on-conflictの指定が正しくありません"), err, true)
+	}
+	if conflictStrategy != quiz_yaml_converter.ConflictKeepAll && *skipErrors {
+		fail(T("-on-conflictと-skip-errorsは同時に指定できません"), nil, true)
+	}
+	if conflictStrategy == quiz_yaml_converter.ConflictInteractive && *template == "-" {
+		fail(T("-on-conflict interactiveと-template -は同時に指定できません（どちらも標準入力を使います）"), nil, true)
+	}
+
+	run := &convertRun{
+		log:         log,
+		fail:        fail,
+		inputFiles:  inputFiles,
+		inputFile:   inputFile,
+		outputFiles: outputFiles,
+		outputFile:  outputFile,
+		format:      *format,
+		template:    *template,
+		opts:        opts,
+		onConflict:  conflictStrategy,
+		skipErrors:  *skipErrors,
+		summary:     summary,
+		answerKey:   *answerKey,
+		bundle:      *bundle,
+		perPage:     *perPage,
+		splitBy:     *splitBy,
+	}
+	// -skip-errorsで取り除いた問題は変換の完了後に表示する
+	defer func() { reportSkipped(log, run.skipped) }()
+
+	switch {
+	case conflictStrategy != quiz_yaml_converter.ConflictKeepAll && isYAMLFile(outputFile):
+		run.mergeYAML()
+	case *answerKey != "":
+		run.convertAnswerKey()
+	case *bundle:
+		run.convertBundle()
+	case len(outputFiles) > 1:
+		run.convertMultiOutput()
+	case *perPage != 0:
+		run.convertPaginated()
+	case *splitBy != "":
+		run.convertSplit()
+	case *template != "":
+		run.convertTemplate()
+	default:
+		run.convertFormat()
+	}
+}
+
+// failFunc はエラーを出力して終了する関数（mainのfail参照）．
+type failFunc func(msg string, err error, usage bool)
+
+// runMarkdownDir はdirのMarkdownファイルを集約して，-outputのYAMLファイルに書き出す（-markdown-dir）．
+func runMarkdownDir(log *slog.Logger, fail failFunc, dir string, recursive bool, outputFiles commandList, noClobber, force bool) {
+	var outputFile string
+	if len(outputFiles) > 0 {
+		outputFile = outputFiles[0]
+	}
+	if outputFile == "" {
+		fail(T("出力ファイルが指定されていません"), nil, true)
+	}
+	if len(outputFiles) > 1 {
+		fail(T("-markdown-dirでは-outputを1つだけ指定してください"), nil, true)
+	}
+	if err := checkOutputPath("", outputFile, noClobber, force); err != nil {
+		fail(T("出力先を確認できませんでした"), err, false)
+	}
+	log.Debug(T("Markdownを集約しています"), "markdown_dir", dir, "recursive", recursive)
+	if err := quiz_yaml_converter.ConvertMarkdownDirToYAML(dir, outputFile, recursive); err != nil {
+		fail(T("Markdownの集約に失敗しました"), err, false)
+	}
+	log.Info(fmt.Sprintf(T("Markdown集約完了: %s → %s"), dir, outputFile), "markdown_dir", dir, "output", outputFile)
+}
+
+// runValidate は入力ファイルをバリデーションし，結果をformat（text, json）で出力する（-validate）．
+// エラーがある場合はエラーの種類に応じた終了コードで終了する．
+func runValidate(log *slog.Logger, fail failFunc, inputFiles inputList, checks itemChecks, format string) {
+	inputFile := inputFiles.String()
+	log.Debug(fmt.Sprintf(T("YAMLファイルをバリデーションしています: %s"), inputFile), "input", inputFile)
+	result := quiz_yaml_converter.ValidateYAMLFiles(inputFiles)
+	if result.IsValid {
+		checks.validate(&result, inputFiles)
+	}
+
+	switch format {
+	case "text":
+	case "json":
+		report, err := result.LocalizedJSON(lang)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "❌ エラー: %v\n", err)
-			os.Exit(1)
+			fail(T("バリデーション結果を出力できませんでした"), err, false)
+		}
+		fmt.Println(string(report))
+		if !result.IsValid {
+			os.Exit(exitCodeFor(result.Err()))
+		}
+		return
+	default:
+		fail(fmt.Sprintf(T("サポートされていない出力形式です: %s（text, json）"), format), nil, true)
+	}
+	for _, msg := range result.LocalizedWarnings(lang) {
+		log.Warn(msg, "input", inputFile)
+	}
+
+	if result.IsValid {
+		log.Info(fmt.Sprintf(T("バリデーション成功: %d問のクイズデータが正しく読み込めました"), result.Items), "input", inputFile, "items", result.Items)
+	} else {
+		logValidationErrors(log, result, inputFile)
+		os.Exit(exitCodeFor(result.Err()))
+	}
+}
+
+// itemChecks は-validateと変換の前に入力ファイルの問題を調べる項目（-require-sources，-punctuationなど）．
+type itemChecks struct {
+	fail           failFunc // -reading-commandのコマンドを実行できない場合に使う
+	requireSources bool
+	splitAnswer    bool
+	cloze          bool
+	punctuation    quiz_yaml_converter.PunctuationStyle
+	fixPunctuation bool // 表記を修正するため，変換時は-punctuationの警告を表示しない
+	endings        quiz_yaml_converter.QuestionEndings
+	ngWords        quiz_yaml_converter.NGWords
+	maxSameAnswer  int
+	reading        quiz_yaml_converter.ReadingAnalyzer
+}
+
+// readingWarnings は-reading-commandで求めた読みと異なる読みの警告を返す．
+func (c itemChecks) readingWarnings(data []quiz_yaml_converter.QuizItem) []quiz_yaml_converter.ValidationError {
+	warnings, err := quiz_yaml_converter.ReadingWarnings(data, c.reading)
+	if err != nil {
+		c.fail(T("読みを求めるコマンドを実行できませんでした"), err, false)
+	}
+	return warnings
+}
+
+func (c itemChecks) punctuationWarnings(data []quiz_yaml_converter.QuizItem) []quiz_yaml_converter.ValidationError {
+	return quiz_yaml_converter.PunctuationWarnings(data, c.punctuation)
+}
+
+func (c itemChecks) questionEndingWarnings(data []quiz_yaml_converter.QuizItem) []quiz_yaml_converter.ValidationError {
+	return quiz_yaml_converter.QuestionEndingWarnings(data, c.endings)
+}
+
+func (c itemChecks) ngWordWarnings(data []quiz_yaml_converter.QuizItem) []quiz_yaml_converter.ValidationError {
+	return quiz_yaml_converter.NGWordWarnings(data, c.ngWords)
+}
+
+func (c itemChecks) duplicateAnswerWarnings(data []quiz_yaml_converter.QuizItem) []quiz_yaml_converter.ValidationError {
+	return quiz_yaml_converter.DuplicateAnswerWarnings(data, c.maxSameAnswer)
+}
+
+// itemCheck は問題データを調べて警告を返す関数．
+type itemCheck func([]quiz_yaml_converter.QuizItem) []quiz_yaml_converter.ValidationError
+
+// warningChecks は指定された項目の警告を調べる関数を返す．
+// convertingがtrueの場合は，変換時に-fix-punctuationで修正する表記の警告を含めない．
+func (c itemChecks) warningChecks(converting bool) []itemCheck {
+	var checks []itemCheck
+	if c.splitAnswer {
+		checks = append(checks, quiz_yaml_converter.AnswerAlternativeWarnings)
+	}
+	if c.cloze {
+		checks = append(checks, quiz_yaml_converter.ClozeWarnings)
+	}
+	if c.reading != nil {
+		checks = append(checks, c.readingWarnings)
+	}
+	if !c.punctuation.IsZero() && !(converting && c.fixPunctuation) {
+		checks = append(checks, c.punctuationWarnings)
+	}
+	if len(c.endings) > 0 {
+		checks = append(checks, c.questionEndingWarnings)
+	}
+	if len(c.ngWords) > 0 {
+		checks = append(checks, c.ngWordWarnings)
+	}
+	if c.maxSameAnswer > 0 {
+		checks = append(checks, c.duplicateAnswerWarnings)
+	}
+	return checks
+}
+
+// validate は-validateの結果に，指定された項目で調べたエラー（-require-sources）と警告を加える．
+// すべての項目を同じ問題データで調べるよう，入力ファイルは1度だけ読み込む．
+func (c itemChecks) validate(result *quiz_yaml_converter.ValidationResult, inputFiles []string) {
+	checks := c.warningChecks(false)
+	if !c.requireSources && len(checks) == 0 {
+		return
+	}
+	// 読み込めない場合はresultがエラーとなっているため，何も加えない
+	data, err := quiz_yaml_converter.LoadYAMLFiles(inputFiles)
+	if err != nil {
+		return
+	}
+	if c.requireSources {
+		for _, e := range quiz_yaml_converter.MissingSourceErrors(data) {
+			result.AddError(e)
+		}
+	}
+	if !result.IsValid {
+		return
+	}
+	for _, check := range checks {
+		result.Warnings = append(result.Warnings, check(data)...)
+	}
+}
+
+// logWarnings は変換の前に，指定された項目で調べた警告を表示する．
+// すべての項目を同じ問題データで調べるよう，入力ファイルは1度だけ読み込む．
+func (c itemChecks) logWarnings(log *slog.Logger, inputFiles inputList) {
+	checks := c.warningChecks(true)
+	if len(checks) == 0 {
+		return
+	}
+	// 読み込めないファイルがある場合は，変換時にエラーとなるため警告は表示しない
+	data, err := quiz_yaml_converter.LoadYAMLFiles(inputFiles)
+	if err != nil {
+		return
+	}
+	for _, check := range checks {
+		for _, w := range check(data) {
+			log.Warn(w.LocalizedError(lang), "input", inputFiles.String())
 		}
-		fmt.Printf("✅ Markdown変換完了: %s → %s\n", *inputFile, *outputFile)
+	}
+}
+
+// convertRun は変換モードの引数と変換オプション．モード（-answer-key，-bundleなど）ごとのメソッドで変換する．
+type convertRun struct {
+	log         *slog.Logger
+	fail        failFunc
+	inputFiles  inputList
+	inputFile   string // 表示に使う入力ファイルの一覧
+	outputFiles commandList
+	outputFile  string // 最初の出力先
+	format      string
+	template    string
+	opts        quiz_yaml_converter.ConvertOptions
+	onConflict  quiz_yaml_converter.ConflictStrategy
+	skipErrors  bool
+	summary     *quiz_yaml_converter.ConversionSummary // -summary未指定時はnil
+	answerKey   string
+	bundle      bool
+	perPage     int
+	splitBy     string
+
+	skipped []quiz_yaml_converter.ValidationError // -skip-errorsで取り除いた問題のエラー
+}
+
+// loadItems は入力ファイルの問題データを読み込む．
+// -skip-errors指定時は不備のある問題を取り除いて読み込み，取り除いた問題をr.skippedに記録する．
+// -on-conflict指定時は同じidの問題を1つにまとめて読み込み，採用した問題を表示する．
+func (r *convertRun) loadItems() ([]quiz_yaml_converter.QuizItem, error) {
+	if r.onConflict != quiz_yaml_converter.ConflictKeepAll {
+		data, conflicts, err := quiz_yaml_converter.MergeYAMLFiles(r.inputFiles, quiz_yaml_converter.MergeOptions{
+			Strategy: r.onConflict,
+			Resolve:  promptConflict(bufio.NewReader(os.Stdin), os.Stderr),
+		})
+		if err == nil {
+			reportConflicts(r.log, conflicts)
+		}
+		if r.summary != nil {
+			for _, c := range conflicts {
+				r.summary.AddMerged(len(c.Candidates) - 1)
+			}
+		}
+		return data, err
+	}
+	if !r.skipErrors {
+		return quiz_yaml_converter.LoadYAMLFiles(r.inputFiles)
+	}
+	data, errs, err := quiz_yaml_converter.LoadValidItems(r.inputFiles)
+	r.skipped = errs
+	if r.summary != nil {
+		r.summary.AddSkipped(skippedItems(errs))
+	}
+	return data, err
+}
+
+// load は入力ファイルの問題データを読み込む（loadItems参照）．読み込めない場合はエラーを表示して終了する．
+func (r *convertRun) load() []quiz_yaml_converter.QuizItem {
+	data, err := r.loadItems()
+	if err != nil {
+		r.fail(T("YAMLファイルの読み込みに失敗しました"), err, false)
+	}
+	return data
+}
+
+// convertFiles は入力ファイルを-outputに変換する．問題を取り除いたりまとめたりしない場合は，読み込みも変換に任せる．
+func (r *convertRun) convertFiles(template string) error {
+	if !r.skipErrors && r.onConflict == quiz_yaml_converter.ConflictKeepAll {
+		return quiz_yaml_converter.ConvertFilesWithOptions(r.inputFiles, r.outputFile, template, r.opts)
+	}
+	data, err := r.loadItems()
+	if err != nil {
+		return err
+	}
+	return quiz_yaml_converter.ConvertItems(data, r.outputFile, template, r.opts)
+}
+
+// resolveFormat は-formatが登録された出力形式かを確かめ，変換オプションに設定する．
+func (r *convertRun) resolveFormat() {
+	if _, err := quiz_yaml_converter.ResolveFormatter(r.format); err != nil {
+		r.fail(fmt.Sprintf(T("%v: %s（サポートされているフォーマット: %s）"), quiz_yaml_converter.ErrUnsupportedFormat, r.format, strings.Join(quiz_yaml_converter.FormatterNames(), ", ")), nil, true)
+	}
+	r.opts.Format = r.format
+}
+
+// mergeYAML は-on-conflictで統合した問題をYAMLファイル（-outputの拡張子が.yaml，.yml）に書き出す．
+// 問題に付いたコメントを残すよう，YAMLをノードのまま統合する．
+func (r *convertRun) mergeYAML() {
+	if r.template != "" || len(r.outputFiles) > 1 || r.perPage != 0 || r.splitBy != "" || r.bundle || r.answerKey != "" || r.summary != nil {
+		r.fail(T("YAMLファイルへの統合は-template，-outputの複数指定，-per-page，-split-by，-bundle，-answer-key，-summaryと同時に指定できません"), nil, true)
+	}
+	r.log.Debug(T("YAMLファイルを統合します"), "input", r.inputFile, "output", r.outputFile, "on_conflict", r.onConflict)
+	editor, conflicts, err := quiz_yaml_converter.MergeYAMLEditors(r.inputFiles, quiz_yaml_converter.MergeOptions{
+		Strategy: r.onConflict,
+		Resolve:  promptConflict(bufio.NewReader(os.Stdin), os.Stderr),
+	})
+	if err != nil {
+		r.fail(T("YAMLファイルの統合に失敗しました"), err, false)
+	}
+	reportConflicts(r.log, conflicts)
+	if err := editor.WriteFile(r.outputFile); err != nil {
+		r.fail(T("YAMLファイルの書き出しに失敗しました"), err, false)
+	}
+	r.log.Info(fmt.Sprintf(T("統合完了: %s → %s（%d問）"), r.inputFile, r.outputFile, len(editor.Items)), "input", r.inputFile, "output", r.outputFile, "items", len(editor.Items))
+}
+
+// convertAnswerKey は答えを載せない問題用紙（-output）と解答（-answer-key）を組み込みのレイアウトで組にして出力する．
+func (r *convertRun) convertAnswerKey() {
+	if r.template != "" || len(r.outputFiles) > 1 || r.perPage != 0 || r.splitBy != "" || r.bundle || r.format != "csv" && r.format != "html" {
+		r.fail(T("-answer-keyは-template，-format html以外の-format，-outputの複数指定，-per-page，-split-by，-bundleと同時に指定できません"), nil, true)
+	}
+	r.log.Debug(T("問題用紙と解答を出力します"), "input", r.inputFile, "output", r.outputFile, "answer_key", r.answerKey)
+	if err := quiz_yaml_converter.ConvertToAnswerKeyPair(r.load(), r.outputFile, r.answerKey, r.opts); err != nil {
+		r.fail(T("問題用紙と解答の出力に失敗しました"), err, false)
+	}
+	r.log.Info(fmt.Sprintf(T("問題用紙と解答の出力完了: %s → %s，%s"), r.inputFile, r.outputFile, r.answerKey), "input", r.inputFile, "output", r.outputFile, "answer_key", r.answerKey)
+}
+
+// convertBundle は変換結果と参照している画像・音声ファイルを1つのzipファイルにまとめる（-bundle）．
+// zipファイル内の変換結果のファイル名は-outputの拡張子を出力形式に応じて変えたものとする．
+func (r *convertRun) convertBundle() {
+	if len(r.outputFiles) > 1 || r.perPage != 0 || r.splitBy != "" {
+		r.fail(T("-bundleは-outputの複数指定，-per-page，-split-byと同時に指定できません"), nil, true)
+	}
+	if r.template == "" {
+		r.resolveFormat()
+	}
+	name := strings.TrimSuffix(filepath.Base(r.outputFile), filepath.Ext(r.outputFile)) + outputExt(r.format, r.template)
+	r.log.Debug(T("バンドルを作成します"), "input", r.inputFile, "output", r.outputFile, "name", name)
+	files, err := quiz_yaml_converter.ConvertItemsToBundle(r.load(), r.outputFile, r.template, r.opts, quiz_yaml_converter.BundleOptions{
+		Name:    name,
+		BaseDir: filepath.Dir(r.inputFiles[0]),
+	})
+	if err != nil {
+		r.fail(T("バンドルの作成に失敗しました"), err, false)
+	}
+	for _, f := range files {
+		r.log.Debug(fmt.Sprintf("%s → %s", f.Source, f.Path), "reference", f.Reference, "source", f.Source, "path", f.Path)
+	}
+	r.log.Info(fmt.Sprintf(T("バンドル作成完了: %s → %s（%s，メディアファイル%d個）"), r.inputFile, r.outputFile, name, len(files)), "input", r.inputFile, "output", r.outputFile, "name", name, "media", len(files))
+}
+
+// convertMultiOutput は1度読み込んだ問題データを，複数の-outputに拡張子に応じた形式でそれぞれ出力する．
+func (r *convertRun) convertMultiOutput() {
+	formatSet := false
+	flag.Visit(func(f *flag.Flag) {
+		formatSet = formatSet || f.Name == "format"
+	})
+	if formatSet || r.template != "" || r.perPage != 0 || r.splitBy != "" {
+		r.fail(T("-outputを複数指定した場合は-format，-template，-per-page，-split-byを使用できません"), nil, true)
+	}
+	r.log.Debug(T("複数の出力先に変換します"), "input", r.inputFile, "output", r.outputFiles.String())
+	if err := quiz_yaml_converter.ConvertItemsToFiles(r.load(), r.outputFiles, r.opts); err != nil {
+		r.fail(T("変換に失敗しました"), err, false)
+	}
+	r.log.Info(fmt.Sprintf(T("変換完了: %s → %s"), r.inputFile, strings.Join(r.outputFiles, ", ")), "input", r.inputFile, "output", r.outputFiles.String(), "files", len(r.outputFiles))
+}
 
+// convertPaginated はページ分割したHTMLを-outputのディレクトリに出力する（-per-page）．
+func (r *convertRun) convertPaginated() {
+	if r.perPage < 0 {
+		r.fail(T("-per-pageには1以上の数を指定してください"), nil, true)
+	}
+	if r.template == "" && r.format != "html" {
+		r.fail(T("-per-pageはHTML形式（-format html）または-template指定時のみ使用できます"), nil, true)
+	}
+	r.log.Debug(T("ページ分割したHTMLを出力します"), "input", r.inputFile, "output", r.outputFile, "per_page", r.perPage, "template", r.template)
+	if err := quiz_yaml_converter.ConvertToPaginatedHTML(r.load(), r.outputFile, r.template, r.perPage, r.opts); err != nil {
+		r.fail(T("ページ分割したHTMLの出力に失敗しました"), err, false)
+	}
+	r.log.Info(fmt.Sprintf(T("HTML変換完了: %s → %s/%s"), r.inputFile, r.outputFile, quiz_yaml_converter.PaginationIndexFile), "input", r.inputFile, "output", r.outputFile, "per_page", r.perPage)
+}
+
+// convertSplit はタグやジャンルごとに分割した出力を-outputのディレクトリに出力する（-split-by）．
+func (r *convertRun) convertSplit() {
+	by, err := quiz_yaml_converter.ParseGroupBy(r.splitBy)
+	if err != nil {
+		r.fail(T("-split-byの指定が正しくありません"), err, true)
+	}
+	if r.perPage != 0 {
+		r.fail(T("-split-byと-per-pageは同時に指定できません"), nil, true)
+	}
+	if r.template == "" {
+		r.resolveFormat()
+	}
+	r.log.Debug(T("出力を分割します"), "input", r.inputFile, "output", r.outputFile, "split_by", by)
+	groups, err := quiz_yaml_converter.ConvertGrouped(r.load(), r.outputFile, outputExt(r.format, r.template), r.template, by, r.opts)
+	if err != nil {
+		r.fail(T("分割した出力に失敗しました"), err, false)
+	}
+	for _, group := range groups {
+		r.log.Debug(fmt.Sprintf(T("%s: %d問"), group.File, len(group.Items)), "file", group.File, "items", len(group.Items))
+	}
+	r.log.Info(fmt.Sprintf(T("分割出力完了: %s → %s（%dファイル）"), r.inputFile, r.outputFile, len(groups)), "input", r.inputFile, "output", r.outputFile, "files", len(groups))
+}
+
+// convertTemplate は-templateのテンプレートで変換する．
+func (r *convertRun) convertTemplate() {
+	r.log.Debug(T("テンプレート変換を開始します"), "input", r.inputFile, "template", r.template, "output", r.outputFile)
+	if err := r.convertFiles(r.template); err != nil {
+		r.fail(T("テンプレート変換に失敗しました"), err, false)
+	}
+	r.log.Info(fmt.Sprintf(T("テンプレート変換完了: %s + %s → %s"), r.inputFile, r.template, r.outputFile), "input", r.inputFile, "template", r.template, "output", r.outputFile)
+}
+
+// convertFormat は-formatの出力形式で変換する．
+func (r *convertRun) convertFormat() {
+	r.resolveFormat()
+	label := formatLabel(r.format)
+	r.log.Debug(fmt.Sprintf(T("%s変換を開始します"), label), "input", r.inputFile, "output", r.outputFile, "format", r.format)
+	if err := r.convertFiles(""); err != nil {
+		r.fail(fmt.Sprintf(T("%s変換に失敗しました"), label), err, false)
+	}
+	r.log.Info(fmt.Sprintf(T("%s変換完了: %s → %s"), label, r.inputFile, r.outputFile), "input", r.inputFile, "output", r.outputFile, "format", r.format)
+}
+
+// logValidationErrors はバリデーションのエラーとエラーの数を表示する（-validate，-validate-first）．
+func logValidationErrors(log *slog.Logger, result quiz_yaml_converter.ValidationResult, inputFile string) {
+	for _, msg := range result.LocalizedErrors(lang) {
+		log.Error(msg, "input", inputFile)
+	}
+	log.Error(fmt.Sprintf(T("バリデーション失敗: %d個のエラーが見つかりました"), len(result.Errors)), "input", inputFile, "errors", len(result.Errors))
+}
+
+// reportSkipped は-skip-errorsで取り除いた問題のエラーと，取り除いた問題の数を警告として表示する．
+func reportSkipped(log *slog.Logger, skipped []quiz_yaml_converter.ValidationError) {
+	for _, e := range skipped {
+		log.Warn(e.LocalizedError(lang), "file", e.File, "line", e.Line, "rule", e.Rule)
+	}
+	if n := skippedItems(skipped); n > 0 {
+		log.Warn(fmt.Sprintf(T("不備のある%d問を取り除いて変換しました"), n), "skipped", n)
+	}
+}
+
+// skippedItems は-skip-errorsで取り除いた問題の数を返す（1問に複数のエラーがある場合も1問と数える）．
+func skippedItems(skipped []quiz_yaml_converter.ValidationError) int {
+	items := map[int]bool{}
+	for _, e := range skipped {
+		items[e.Index] = true
+	}
+	return len(items)
+}
+
+// isYAMLFile はパスの拡張子がYAMLファイルのもの（.yaml，.yml）かを返す．
+func isYAMLFile(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return true
+	}
+	return false
+}
+
+// reportConflicts は-on-conflictで同じidの問題のうちどれを採用したかを表示する．
+func reportConflicts(log *slog.Logger, conflicts []quiz_yaml_converter.Conflict) {
+	for _, c := range conflicts {
+		locations := make([]string, len(c.Candidates))
+		for i, candidate := range c.Candidates {
+			locations[i] = candidate.String()
+		}
+		chosen := c.Candidates[c.Chosen].String()
+		log.Warn(fmt.Sprintf(T("id %q の問題が重複しています（%s）: %s の問題を採用しました"), c.ID, strings.Join(locations, ", "), chosen),
+			"id", c.ID, "chosen", chosen)
+	}
+}
+
+// promptConflict は-on-conflict interactiveで，同じidの問題の候補をoutに表示し，採用する問題の番号をinから読み取る関数を返す．
+func promptConflict(in *bufio.Reader, out io.Writer) quiz_yaml_converter.ConflictResolver {
+	return func(c quiz_yaml_converter.Conflict) (int, error) {
+		fmt.Fprintf(out, T("id %q の問題が重複しています:\n"), c.ID)
+		for i, candidate := range c.Candidates {
+			fmt.Fprintf(out, "  %d) %s  %s / %s\n", i+1, candidate, quiz_yaml_converter.QuestionSnippet(candidate.Item), candidate.Item.Answer)
+		}
+		for {
+			fmt.Fprintf(out, T("採用する問題の番号を入力してください [1-%d]: "), len(c.Candidates))
+			line, err := in.ReadString('\n')
+			if n, convErr := strconv.Atoi(strings.TrimSpace(line)); convErr == nil && 1 <= n && n <= len(c.Candidates) {
+				return n - 1, nil
+			}
+			if err != nil {
+				return 0, fmt.Errorf("no choice for id %q: %w", c.ID, err)
+			}
+		}
+	}
+}
+
+// cacheFiles は-cacheのハッシュの計算に含めるファイル（入力ファイルとテンプレート・レイアウトのファイル）を返す．
+// 組み込みのレイアウト（html, markdown）はファイルではないため含めない．
+func cacheFiles(inputFiles []string, template, layout string) []string {
+	files := append([]string{}, inputFiles...)
+	for _, path := range []string{template, layout} {
+		if info, err := os.Stat(path); err == nil && info.Mode().IsRegular() {
+			files = append(files, path)
+		}
+	}
+	return files
+}
+
+// cacheArgs は-cacheのハッシュの計算に含める，指定されたフラグとその値を返す．
+// メッセージの表示にのみ影響するフラグと，キャッシュ自体に関するフラグは含めない．
+func cacheArgs() []string {
+	var args []string
+	flag.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "cache", "force", "quiet", "verbose", "log-format", "lang", "summary":
+			return
+		}
+		args = append(args, "-"+f.Name+"="+f.Value.String())
+	})
+	return args
+}
+
+// outputExt は-split-byで出力するファイルの拡張子を返す．
+// テンプレートを指定した場合はテンプレートファイルの拡張子（.tmplは除く），
+// 組み込みのテンプレートの場合はそのテンプレートで出力する拡張子を使う．
+func outputExt(format, template string) string {
+	if ext, ok := quiz_yaml_converter.BuiltinTemplateExt(template); ok {
+		return ext
+	}
+	if template != "" {
+		name := strings.TrimSuffix(filepath.Base(template), ".tmpl")
+		if ext := filepath.Ext(name); ext != "" {
+			return ext
+		}
+		return ".txt"
+	}
+	switch strings.ToLower(format) {
+	case "markdown", "md":
+		return ".md"
+	case "csv", "html", "json", "jsonl", "msgpack", "parquet", "srt":
+		return "." + strings.ToLower(format)
+	case "teleprompter":
+		return ".html"
+	}
+	return ".txt"
+}
+
+// formatLabel は-formatで指定された出力形式のメッセージ用の表示名を返す．
+// 組み込み以外の出力形式は名前をそのまま使う．
+func formatLabel(format string) string {
+	if strings.HasPrefix(format, quiz_yaml_converter.ExecFormatPrefix) {
+		return T("外部コマンド")
+	}
+	switch strings.ToLower(format) {
+	case "csv":
+		return "CSV"
+	case "html":
+		return "HTML"
+	case "markdown", "md":
+		return "Markdown"
+	case "json":
+		return "JSON"
+	case "jsonl":
+		return "JSON Lines"
+	case "msgpack":
+		return "MessagePack"
+	case "parquet":
+		return "Parquet"
+	case "srt":
+		return "SRT"
+	case "teleprompter":
+		return T("読み上げ原稿")
+	case "text":
+		return T("テキスト")
 	default:
-		fmt.Fprintf(os.Stderr, "❌ エラー: サポートされていないフォーマットです: %s\n", *format)
-		fmt.Fprintf(os.Stderr, "サポートされているフォーマット: csv, html, markdown\n\n")
-		flag.Usage()
-		os.Exit(1)
+		return format
+	}
+}
+
+// checkOutputPath は出力先に書き込んでよいかを確認する．
+// 入力ファイルと同じパスへの出力や，-no-clobber指定時の既存ファイルへの出力は
+// -forceが指定されていない限りエラーにする．
+func checkOutputPath(inputFile, outputFile string, noClobber, force bool) error {
+	if force {
+		return nil
+	}
+	outInfo, err := os.Stat(outputFile)
+	if err != nil {
+		// 出力ファイルが存在しない場合は問題なし
+		return nil
+	}
+	if noClobber {
+		return fmt.Errorf(T("%w: %s（上書きする場合は-forceを指定してください）"), quiz_yaml_converter.ErrOutputExists, outputFile)
+	}
+	if inputFile != "" {
+		if inInfo, err := os.Stat(inputFile); err == nil && os.SameFile(inInfo, outInfo) {
+			return fmt.Errorf(T("出力ファイルが入力ファイルと同じです: %s（上書きする場合は-forceを指定してください）"), outputFile)
+		}
+	}
+	return nil
+}
+
+// 終了コード
+const (
+	exitOK                = 0 // 正常終了
+	exitError             = 1 // その他のエラー
+	exitUsage             = 2 // コマンドライン引数の誤り
+	exitInvalidYAML       = 3 // YAMLの構文エラー
+	exitValidation        = 4 // バリデーションエラー
+	exitTemplate          = 5 // テンプレートの構文エラー・実行エラー
+	exitUnsupportedFormat = 6 // サポートされていない出力フォーマット
+	exitOutputExists      = 7 // 出力ファイルが既に存在する
+	exitDifferent         = 8 // 差分がある（diff -exit-code指定時）
+)
+
+// exitCodeFor はエラーの種類に応じた終了コードを返す．
+func exitCodeFor(err error) int {
+	switch {
+	case err == nil:
+		return exitOK
+	case errors.Is(err, quiz_yaml_converter.ErrInvalidYAML),
+		errors.Is(err, quiz_yaml_converter.ErrUnsupportedVersion):
+		return exitInvalidYAML
+	case errors.Is(err, quiz_yaml_converter.ErrValidation),
+		errors.Is(err, quiz_yaml_converter.ErrIDConflict):
+		return exitValidation
+	case errors.Is(err, quiz_yaml_converter.ErrTemplateParse),
+		errors.Is(err, quiz_yaml_converter.ErrTemplateExecute):
+		return exitTemplate
+	case errors.Is(err, quiz_yaml_converter.ErrUnsupportedFormat),
+		errors.Is(err, quiz_yaml_converter.ErrTemplateRequired):
+		return exitUnsupportedFormat
+	case errors.Is(err, quiz_yaml_converter.ErrOutputExists):
+		return exitOutputExists
+	default:
+		return exitError
+	}
+}
+
+// commandList は-transform，-question-ending，-ng-words，-output，-varのように複数回指定できるフラグの値．
+// コマンドの引数や正規表現，パスにカンマが含まれることがあるため，カンマでは区切らない．
+type commandList []string
+
+func (l *commandList) String() string {
+	return strings.Join(*l, "; ")
+}
+
+func (l *commandList) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
+// inputList は-inputを複数回，またはカンマ区切りで指定するためのフラグの値．
+type inputList []string
+
+func (l *inputList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *inputList) Set(value string) error {
+	for _, path := range strings.Split(value, ",") {
+		if path = strings.TrimSpace(path); path != "" {
+			*l = append(*l, path)
+		}
 	}
+	return nil
 }
@@ -0,0 +1,482 @@
+package main
+
+import (
+	"flag"
+	"os"
+	"strings"
+
+	"github.com/m-uesaka/quiz-yaml-go/quiz_yaml_converter"
+)
+
+// 表示言語を指定する環境変数
+const langEnv = "QUIZ_YAML_LANG"
+
+// lang はメッセージの表示言語．main()の最初にsetLanguageで設定する．
+var lang = quiz_yaml_converter.LanguageJapanese
+
+// setLanguage はコマンドライン引数の-lang，環境変数QUIZ_YAML_LANGの順に
+// 表示言語を決める．フラグの説明文も翻訳するため，フラグの定義より前に
+// 引数を直接調べる．
+func setLanguage(args []string) error {
+	name := os.Getenv(langEnv)
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			break
+		}
+		switch {
+		case arg == "-lang" || arg == "--lang":
+			if i+1 < len(args) {
+				name = args[i+1]
+				i++
+			}
+		case strings.HasPrefix(arg, "-lang="), strings.HasPrefix(arg, "--lang="):
+			name = arg[strings.Index(arg, "=")+1:]
+		}
+	}
+	l, err := quiz_yaml_converter.ParseLanguage(name)
+	if err != nil {
+		return err
+	}
+	lang = l
+	return nil
+}
+
+// addLangFlag はフラグセットに-langを登録する．値はsetLanguageで既に
+// 反映されているため，ここでは使用法の表示と解析エラーの防止のためだけに登録する．
+func addLangFlag(fs *flag.FlagSet) {
+	fs.String("lang", string(lang), T("メッセージの言語（ja, en）．環境変数QUIZ_YAML_LANGでも指定できる"))
+}
+
+// T は日本語のメッセージ（書式文字列）を表示言語に翻訳する．
+// 翻訳がない場合はそのまま返す．
+func T(format string) string {
+	if translated, ok := cliMessages[lang][format]; ok {
+		return translated
+	}
+	return quiz_yaml_converter.Translate(lang, format)
+}
+
+// cliMessages はコマンドラインツールのメッセージの翻訳．
+var cliMessages = map[quiz_yaml_converter.Language]map[string]string{
+	quiz_yaml_converter.LanguageEnglish: {
+		// 共通
+		"メッセージの言語（ja, en）．環境変数QUIZ_YAML_LANGでも指定できる": "message language (ja, en); can also be set with QUIZ_YAML_LANG",
+		"エラー以外のメッセージを出力しない":                          "suppress all messages except errors",
+		"詳細なメッセージを出力する":                              "print detailed messages",
+		"メッセージの出力形式（text, json）":                     "message output format (text, json)",
+		"オプション:\n":  "Options:\n",
+		"\n例:\n":    "\nExamples:\n",
+		"エラー: %v\n": "error: %v\n",
+		"エラー: ":     "error: ",
+		"警告: ":      "warning: ",
+		"エラー":       "Error",
+		"-quietと-verboseは同時に指定できません":       "-quiet and -verbose cannot be used together",
+		"サポートされていないログ形式です: %s（text, json）": "unsupported log format: %s (text, json)",
+		"YAMLファイルの読み込みに失敗しました":             "failed to load YAML file",
+
+		// 変換
+		"入力するYAMLファイルのパス（-markdown-dir未指定時は必須．複数回またはカンマ区切りで指定すると順に連結する）":               "path to the input YAML file (required unless -markdown-dir is given; repeat or separate with commas to concatenate files in order)",
+		"集約するMarkdownファイルが置かれたディレクトリのパス（指定時はMarkdown→YAML変換モードになる）":                    "directory containing Markdown files to aggregate (switches to Markdown to YAML mode)",
+		"-markdown-dir指定時，サブディレクトリも再帰的に辿るかどうか":                                         "with -markdown-dir, also walk subdirectories",
+		"出力ファイルのパス（必須）．複数回指定すると，1度の読み込みから拡張子（.csv, .json, .html, .md）に応じた形式でそれぞれに出力する": "path to the output file (required); when repeated, the input is read once and written to each file in the format given by its extension (.csv, .json, .html, .md)",
+		"出力フォーマット（%s，またはexec:コマンドで外部コマンド）":                                             "output format (%s, or exec:<command> for an external command)",
+		"外部コマンド": "external command",
+		"読み上げ原稿": "teleprompter",
+		"出力の前に問題データを変換する外部コマンド（JSONを標準入力で受け取り標準出力に返す．複数回指定すると順に適用する）": "external command that transforms the items before output (reads JSON on stdin and writes JSON to stdout; repeat to apply in order)",
+		"-transformの指定が正しくありません":                                   "invalid -transform",
+		"出力する問題の絞り込み条件（例: genre == \"歴史\" and len(question) > 40）": "condition for selecting the items to output (e.g. genre == \"歴史\" and len(question) > 40)",
+		"-filterの指定が正しくありません":                                      "invalid -filter",
+		"-sortの指定が正しくありません":                                        "invalid -sort",
+		"出力する問題の並べ替えの基準（reading: 答えの読みの五十音順．未指定時はYAMLの順序）":         "key for sorting the items to output (reading: gojūon order of the answer reading; YAML order if omitted)",
+		"-templateの基にするレイアウト（html, markdown，またはファイルのパス）．-templateではブロックを{{define}}で置き換える": "base layout for -template (html, markdown, or a file path); -template overrides its blocks with {{define}}",
+		"テンプレートの実行の制限時間（例: 10s．0は無制限）":                                                    "time limit for executing the template (e.g. 10s; 0 means no limit)",
+		"テンプレートの左右の区切り文字をカンマでつないで指定（例: \"[[,]]\"．未指定時は{{と}}）":                             "left and right template delimiters separated by a comma (e.g. \"[[,]]\"; {{ and }} if omitted)",
+		"-template-delimsは-templateと合わせて指定してください":                                         "-template-delims requires -template",
+		"-template-delimsの指定が正しくありません":                                                    "invalid -template-delims",
+		"テンプレートに{{.Vars.名前}}として渡す変数（名前=値．=を省略すると同じ名前の環境変数の値を使う．複数回指定できる）":                 "variable passed to templates as {{.Vars.name}} (name=value; without =, the value of the environment variable of the same name is used; can be repeated)",
+		"-varの指定が正しくありません":                                                                "invalid -var",
+		"テンプレートの内容を直接指定する（-templateの代わりに使用）":                                              "template text given directly (instead of -template)",
+		"-templateと-template-stringは同時に指定できません":                                           "-template and -template-string cannot be used together",
+		"標準入力からテンプレートを読み込めませんでした":                                                         "failed to read the template from standard input",
+		"標準入力から読み込んだテンプレートが空です":                                                           "the template read from standard input is empty",
+		"-markdown-dirでは-outputを1つだけ指定してください":                                             "-markdown-dir accepts only one -output",
+		"-outputを複数指定した場合は-format，-template，-per-page，-split-byを使用できません":                  "-format, -template, -per-page and -split-by cannot be used with multiple -output",
+		"複数の出力先に変換します":                                                                    "converting to multiple outputs",
+		"変換に失敗しました":                                                                       "conversion failed",
+		"変換完了: %s → %s":                                                                   "conversion complete: %s → %s",
+		"テンプレートの出力の最大サイズ（例: 10MB．未指定時は無制限）":                                               "maximum size of the template output (e.g. 10MB; no limit if omitted)",
+		"テンプレートで実行環境に依存する関数（nowなど）を使えないようにする":                                             "disallow template functions that depend on the environment, such as now",
+		"-max-output-sizeの指定が正しくありません":                                                    "invalid -max-output-size",
+		"-layoutは-templateと合わせて指定してください":                                                  "-layout requires -template",
+		"テンプレートファイルのパス（formatに関係なく使用．-で標準入力から読み込む．builtin:名前で組み込みのテンプレート（%s）を使う）":  "path to a template file (used regardless of -format; - reads it from standard input; builtin:NAME uses a built-in template (%s))",
+		"YAMLファイルのフォーマットをバリデーションのみ実行":                                              "only validate the YAML file",
+		"正誤判定をYAMLに書かれたキー順序で出力する（既定はok→ng→repeat）":                                 "write criteria in the key order used in the YAML (default: ok, ng, repeat)",
+		"正誤判定の語句と引用符の言語（ja, en．enでは\"X\" is incorrectのように出力する）":                    "language of the criteria phrases and quotes (ja, en; en renders \"X\" is incorrect)",
+		"正誤判定とテンプレート関数addQuotesで項目を囲む引用符（例: 『』，\"“,”\"．未指定時は-criteria-localeの引用符）": "quotes around criteria items and addQuotes in templates (e.g. 『』, \"“,”\"; defaults to the quotes of -criteria-locale)",
+		"読み上げ時間の見積もりに使う読み上げの速さ（1秒あたりのモーラ数）":                                        "reading pace in morae per second used to estimate reading times",
+		"テンプレート関数qrURLで問題ごとのQRコードに埋め込むURL（{id}を問題IDに置き換える．未指定時は問題ID）":              "URL embedded in per-item QR codes by the qrURL template function ({id} is replaced with the item ID; the ID itself if omitted)",
+		"テンプレート関数slugで問題のスラッグの元にする値（id, answer）":                                   "Value the slug template function builds item slugs from (id, answer)",
+		"HTML出力での読みの表示方法（ruby: ルビ，paren: 括弧書き）":                                    "how readings are shown in HTML output (ruby: ruby annotations, paren: in parentheses)",
+		"-format textで1行に出力する項目をカンマ区切りで指定（-columnsと同じ項目名．未指定時はquestion,answer）":    "fields written on each line with -format text, comma-separated (same names as -columns; question,answer if omitted)",
+		"-format textで項目をつなぐ文字列":   "string joining the fields with -format text",
+		"-text-fieldsの指定が正しくありません": "invalid -text-fields",
+		"テキスト": "text",
+		"-slug-fromの指定が正しくありません":            "invalid -slug-from",
+		"-number-widthには0から%dまでの数を指定してください": "-number-width must be between 0 and %d",
+		"-ruby-styleの指定が正しくありません":           "invalid -ruby-style",
+		"CSVに出力する列をカンマ区切りで指定（id, question, answer, reading, spell, genre, difficulty, tags, comments, criteria, source, reference, author, license, question_length, answer_length, ok_count）": "comma-separated CSV columns (id, question, answer, reading, spell, genre, difficulty, tags, comments, criteria, source, reference, author, license, question_length, answer_length, ok_count)",
+		"CSVの末尾にcomments列を追加する":                                                            "append a comments column to the CSV",
+		"CSVのcomments列で複数のコメントをつなぐ文字列（未指定時は改行）":                                            "separator for multiple comments in the CSV comments column (default: newline)",
+		"CSVのcriteria列で正誤判定の区分をつなぐ文字列（未指定時は／）":                                             "string joining the ok/ng/repeat sections in the CSV criteria column (default: ／)",
+		"CSVのcriteria列で各区分の項目をつなぐ文字列（指定時は項目を「」で囲まない）":                                      "string joining the items of each section in the CSV criteria column (items are not wrapped in 「」 when set)",
+		"CSVのヘッダー行を出力しない":                                                                  "omit the CSV header row",
+		"CSVのヘッダー名を変更（例: question=問題,answer=答え．jaで日本語ラベル）":                                 "rename CSV headers (e.g. question=Q,answer=A; ja for Japanese labels)",
+		"CSVの文字コード（utf8, utf8-bom, sjis）":                                                  "CSV encoding (utf8, utf8-bom, sjis)",
+		"CSVのフィールド内の改行の扱い（keep: そのまま，escape: \\nに置き換え，space: 空白にまとめる）":                     "how to handle newlines inside CSV fields (keep: as is, escape: replace with \\n, space: collapse to a space)",
+		"CSVの改行コードをCRLFにする":                                                                "use CRLF line endings in the CSV",
+		"CSVのすべてのフィールドを\"で囲む":                                                              "quote every CSV field with \"",
+		"=, +, -, @で始まるCSVフィールドの先頭に'を付けて数式として解釈されないようにする":                                  "prefix CSV fields starting with =, +, -, @ with ' so they are not treated as formulas",
+		"テンプレートに渡す問題番号の開始値":                                                                "first question number passed to templates",
+		"問題番号をゼロ埋めする桁数（0はゼロ埋めしない．最大10）":                                                    "zero-pad question numbers to this width (0: no padding, up to 10)",
+		"問題番号を振り直す単位（genre: ジャンルごと，document: YAMLのドキュメント（---区切り）ごとに1-1, 1-2, 2-1…と番号を付ける）": "restart question numbers per section (genre: per genre, document: per YAML document separated by ---; numbered as 1-1, 1-2, 2-1, ...)",
+		"idが未設定の問題に問題文と答えから決まるIDを割り当てて出力する（CSVで-columns未指定時はid列を追加）":                       "assign content-based IDs to items without an id in the output (adds an id column to the CSV unless -columns is given)",
+		"HTMLの末尾（-per-page指定時は目次）に答えの読みの五十音順の索引を出力する":                                      "output an index of the items in gojūon order of the answer reading at the end of the HTML (on the table of contents with -per-page)",
+		"タグ（tag）またはジャンル（genre）ごとに出力を分割し，-outputのディレクトリに1ファイルずつと問題数の一覧（index.csv）を出力する":     "split the output by tag or genre, writing one file per group and a summary of item counts (index.csv) into the -output directory",
+		"-split-byの指定が正しくありません":                                                            "invalid -split-by",
+		"-split-byと-per-pageは同時に指定できません":                                                   "-split-by and -per-page cannot be used together",
+		"出力を分割します":      "splitting the output",
+		"分割した出力に失敗しました": "failed to write the split output",
+		"%s: %d問": "%s: %d items",
+		"分割出力完了: %s → %s（%dファイル）": "split output done: %s → %s (%d files)",
+		"HTMLを指定した問題数ごとのページに分割し，-outputのディレクトリにindex.htmlとpage-N.htmlを出力する":       "split HTML into pages of this many items and write index.html and page-N.html into the -output directory",
+		"出力ファイルが既に存在する場合は上書きせずにエラーにする":                                            "fail instead of overwriting an existing output file",
+		"-no-clobberや入力ファイルと同じパスへの出力の確認を無視して上書きする":                                "overwrite even with -no-clobber or when the output is the input file",
+		"変換結果のキャッシュファイルのパス．入力・テンプレート・引数が前回と同じで出力が存在する場合は変換を省略する":                  "path to a conversion cache file; skips conversion when the inputs, template, and arguments are unchanged and the output exists",
+		"変換の集計（読み込んだ・出力した・取り除いた問題の数，警告，出力ファイルのサイズ，所要時間）をJSONで書き出すファイルのパス（-で標準出力）": "path to write a JSON summary of the conversion to (items read, written, and skipped, warnings, output sizes, and duration; - for standard output)",
+		"読み込めない問題やバリデーションに失敗する問題を取り除いて変換し，取り除いた問題を最後に表示する":                        "drop quiz items that cannot be read or fail validation, and list them at the end",
+		"変換の前に-validateと同じバリデーションを行い，エラーがある場合は何も出力せずに終了する":                        "validate the input as -validate does before converting, and exit without writing anything if there are errors",
+		"-validate-firstと-skip-errorsは同時に指定できません":                                 "-validate-first and -skip-errors cannot be used together",
+		"問題 %d: %v":     "item %d: %v",
+		"問題 %d（%s）: %v": "item %d (%s): %v",
+		"%s（%d問でエラー）":   "%s (errors in %d items)",
+		"不備のある%d問を取り除いて変換しました":  "converted without %d invalid quiz items",
+		"キャッシュファイルを読み込めませんでした":  "failed to read the cache file",
+		"キャッシュファイルを書き出せませんでした":  "failed to write the cache file",
+		"変換の集計を書き出せませんでした":      "failed to write the conversion summary",
+		"出力は最新のため変換を省略しました: %s": "output is up to date, skipped conversion: %s",
+		"ヘルプを表示":              "show help",
+		"使用法: %s [オプション]\n\n": "Usage: %s [options]\n\n",
+		"クイズYAMLファイルを指定されたフォーマットに変換します。\n\n": "Converts quiz YAML files to the specified format.\n\n",
+		"\nサブコマンド:\n": "\nSubcommands:\n",
+		"  serve    変換APIを提供するHTTPサーバーを起動する（詳細は %s serve -help）\n":        "  serve    start an HTTP server providing the conversion API (see %s serve -help)\n",
+		"  diff     2つのYAMLファイルの問題の差分を表示する（詳細は %s diff -help）\n":          "  diff     show question differences between two YAML files (see %s diff -help)\n",
+		"  ids      問題文と答えから決まるIDを割り当ててYAMLファイルに書き戻す（詳細は %s ids -help）\n": "  ids      assign content-based IDs and write them back to a YAML file (see %s ids -help)\n",
+		"  grpc     変換APIを提供するgRPCサーバーを起動する（詳細は %s grpc -help）\n":         "  grpc     start a gRPC server that provides the conversion API (see %s grpc -help)\n",
+		"-markdown-dirと-inputは同時に指定できません":                                 "-markdown-dir and -input cannot be used together",
+		"出力ファイルが指定されていません":                                                "no output file specified",
+		"出力先を確認できませんでした":                                                  "cannot write to the output path",
+		"Markdownを集約しています":                                                "aggregating Markdown files",
+		"Markdownの集約に失敗しました":                                              "failed to aggregate Markdown files",
+		"Markdown集約完了: %s → %s":                                           "aggregated Markdown: %s → %s",
+		"入力ファイルが指定されていません":                                                "no input file specified",
+		"YAMLファイルをバリデーションしています: %s":                                       "validating YAML file: %s",
+		"バリデーション成功: %d問のクイズデータが正しく読み込めました":                                "validation succeeded: %d quiz items loaded",
+		"バリデーション失敗: %d個のエラーが見つかりました":                                      "validation failed: %d errors found",
+		"-validateの結果の出力形式（text, json．jsonは標準出力に規則名や行番号を含むレポートを出力する）":     "output format of -validate results (text, json; json writes a report with rule names and line numbers to stdout)",
+		"バリデーション結果を出力できませんでした":                                            "failed to write the validation report",
+		"サポートされていない出力形式です: %s（text, json）":                                "unsupported output format: %s (text, json)",
+		"-encodingの指定が正しくありません":                                           "invalid -encoding",
+		"-criteria-localeの指定が正しくありません":                                    "invalid -criteria-locale",
+		"-quotesの指定が正しくありません":                                             "invalid -quotes",
+		"-reading-paceには正の数を指定してください":                                     "-reading-pace must be a positive number",
+		"-newlinesの指定が正しくありません":                                           "invalid -newlines",
+		"-header-labelsの指定が正しくありません":                                      "invalid -header-labels",
+		"-columnsの指定が正しくありません":                                            "invalid -columns",
+		"-number-byの指定が正しくありません":                                          "invalid -number-by",
+		"問題文と答えの行末の空白，語の間の全角スペース，ゼロ幅文字，改行コードの混在を修正して出力する":                 "fix trailing whitespace, full-width spaces between words, zero-width characters, and mixed line endings in questions and answers before output",
+		"統一する句読点・記号の表記（academic: ，．，japanese: 、。，またはcomma=，,period=．,question=？,exclamation=！,parens=（）の形式）．異なる表記に警告を表示する": "punctuation style to enforce (academic: ，．, japanese: 、。, or comma=，,period=．,question=？,exclamation=！,parens=（）); warns about other styles",
+		"問題文と答えの句読点・記号を-punctuationの表記に修正して出力する":                                                                           "rewrite punctuation in questions and answers to the -punctuation style before output",
+		"-punctuationの指定が正しくありません":                   "invalid -punctuation",
+		"-fix-punctuationは-punctuationと合わせて指定してください": "-fix-punctuation requires -punctuation",
+		"問題文の末尾として認める形式の正規表現（例: でしょう？）．複数回指定でき，いずれにも一致しない問題に警告を表示する": "regular expression for an allowed question ending (e.g. でしょう？); can be repeated, and questions matching none of them are warned about",
+		"-question-endingの指定が正しくありません": "invalid -question-ending",
+		"禁止語のリストのファイル（1行に1語）．複数回指定でき，問題文とコメントに含まれる禁止語に警告を表示する": "NG word list file (one word per line); can be repeated; warns about NG words in questions and comments",
+		"-ng-wordsのファイルを読み込めませんでした":                                                                       "failed to read the -ng-words file",
+		"同じ答え（表記の揺れを除く）の問題の上限．超えた場合に警告を表示する（0はチェックしない）":                                                   "maximum number of items with the same answer (ignoring notation differences); a warning is shown when exceeded (0 disables the check)",
+		"-max-same-answerには0以上の値を指定してください":                                                                "-max-same-answer must be 0 or greater",
+		"複数の入力ファイルに同じidの問題がある場合の扱い（keep-first, keep-newest, error, interactive．未指定時はすべて残す）":               "how to handle items with the same id across input files (keep-first, keep-newest, error, interactive; all items are kept if omitted)",
+		"-on-conflictの指定が正しくありません":                                                                        "invalid -on-conflict",
+		"YAMLファイルへの統合は-template，-outputの複数指定，-per-page，-split-by，-bundle，-answer-key，-summaryと同時に指定できません": "merging into a YAML file cannot be combined with -template, multiple -output, -per-page, -split-by, -bundle, -answer-key or -summary",
+		"YAMLファイルを統合します":     "merging YAML files",
+		"YAMLファイルの統合に失敗しました": "failed to merge the YAML files",
+		"統合完了: %s → %s（%d問）": "merged: %s → %s (%d items)",
+		"-outputをzipファイルとし，変換結果と変換結果から参照している画像・音声ファイルを1つにまとめて出力する":             "write -output as a zip file that bundles the converted output with the image and audio files it references",
+		"答えを載せた解答のHTMLを書き出すパス．指定すると-outputには答えを載せない問題用紙のHTMLを書き出し，2つを互いにリンクする": "path to write an HTML answer key to; -output then gets a question-only HTML sheet, and the two link to each other",
+		"-bundleは-outputの複数指定，-per-page，-split-byと同時に指定できません":                  "-bundle cannot be used with multiple -output, -per-page or -split-by",
+		"バンドルを作成します":                           "creating a bundle",
+		"バンドルの作成に失敗しました":                       "failed to create the bundle",
+		"バンドル作成完了: %s → %s（%s，メディアファイル%d個）":    "bundle created: %s → %s (%s, %d media files)",
+		"問題用紙と解答を出力します":                        "writing the question sheet and the answer key",
+		"問題用紙と解答の出力に失敗しました":                    "failed to write the question sheet and the answer key",
+		"問題用紙と解答の出力完了: %s → %s，%s":             "question sheet and answer key written: %s → %s, %s",
+		"-on-conflictと-skip-errorsは同時に指定できません": "-on-conflict and -skip-errors cannot be used together",
+		"-answer-keyは-template，-format html以外の-format，-outputの複数指定，-per-page，-split-by，-bundleと同時に指定できません": "-answer-key cannot be used with -template, a -format other than html, multiple -output, -per-page, -split-by, or -bundle",
+		"-summaryは-markdown-dir，-validateと同時に指定できません":                                                      "-summary cannot be used with -markdown-dir or -validate",
+		"-on-conflict interactiveと-template -は同時に指定できません（どちらも標準入力を使います）":                                   "-on-conflict interactive and -template - cannot be used together (both read standard input)",
+		"id %q の問題が重複しています（%s）: %s の問題を採用しました":                                                             "duplicate id %q (%s): kept the item at %s",
+		"id %q の問題が重複しています:\n":                                                                             "duplicate id %q:\n",
+		"採用する問題の番号を入力してください [1-%d]: ":                                                                      "choose the item to keep [1-%d]: ",
+		"-glossary-stopwordsのファイルを読み込めませんでした":                                                              "failed to read the -glossary-stopwords file",
+		"用語集に含めない語のリストのファイル（1行に1語）．複数回指定できる":                                                               "file listing words to exclude from the glossary (one word per line); can be repeated",
+		"HTMLとMarkdownの末尾に，複数の問題の問題文に現れる語の一覧（用語集）を出力する":                                                    "output a glossary of terms that appear in several questions at the end of the HTML and Markdown",
+		"-glossaryで用語集に含める語が現れる問題の最小数":                                                                     "minimum number of questions a term must appear in to be listed by -glossary",
+		"答えの末尾の括弧書き（例: 国際連合（国連））を別解としてcriteria.okに移す":                                                      "move a parenthesized part at the end of the answer (e.g. 国際連合（国連）) to criteria.ok as alternatives",
+		"問題文に含まれる答えと別解をAnkiの穴埋め形式（{{c1::答え}}）に置き換える":                                                       "replace the answer and its alternatives in the question with Anki cloze deletions ({{c1::answer}})",
+		"出典（sourceまたはreference）が書かれていない問題をエラーにする":                                                          "treat items without a source or reference as errors",
+		"答えの読みを求める形態素解析のコマンド（例: \"mecab -Oyomi\"．-tags kagomeでビルドした場合はbuiltin:kagomeも指定可）．readingが未設定の問題に読みを補い，書かれた読みと異なる場合は警告を表示する": "morphological analyzer command that produces answer readings (e.g. \"mecab -Oyomi\", or builtin:kagome when built with -tags kagome); fills in missing readings and warns when a written reading differs",
+		"-reading-commandの指定が正しくありません":                          "invalid -reading-command",
+		"読みを求めるコマンドを実行できませんでした":                                 "failed to run the reading command",
+		"-per-pageには1以上の数を指定してください":                             "-per-page must be 1 or greater",
+		"-per-pageはHTML形式（-format html）または-template指定時のみ使用できます": "-per-page can only be used with -format html or -template",
+		"ページ分割したHTMLを出力します":                                     "writing paginated HTML",
+		"ページ分割したHTMLの出力に失敗しました":                                 "failed to write paginated HTML",
+		"HTML変換完了: %s → %s/%s":                                  "HTML conversion complete: %s → %s/%s",
+		"テンプレート変換を開始します":                                        "starting template conversion",
+		"テンプレート変換に失敗しました":                                       "template conversion failed",
+		"テンプレート変換完了: %s + %s → %s":                              "template conversion complete: %s + %s → %s",
+		"%s変換を開始します":                                            "starting %s conversion",
+		"%s変換に失敗しました":                                           "%s conversion failed",
+		"%s変換完了: %s → %s":                                       "%s conversion complete: %s → %s",
+		"%v: %s（サポートされているフォーマット: %s）":                           "%v: %s (supported formats: %s)",
+		"%w: %s（上書きする場合は-forceを指定してください）":                       "%w: %s (use -force to overwrite)",
+		"出力ファイルが入力ファイルと同じです: %s（上書きする場合は-forceを指定してください）": "output file is the same as the input file: %s (use -force to overwrite)",
+
+		// serve
+		"待ち受けるアドレス": "address to listen on",
+		"/questionsで出題するYAMLファイルのパス（複数回またはカンマ区切りで指定可能）": "YAML files to serve from /questions (repeatable or comma-separated)",
+		"%d問を出題できます":                      "%d quiz items are available",
+		"問題を選びました":                        "chose quiz items",
+		"リクエストボディの最大サイズ（バイト）":             "maximum request body size in bytes",
+		"リクエストごとのログを出力する":                 "log every request",
+		"テンプレートのプレビュー画面を有効にする（-inputが必要）": "enable the template preview UI (requires -input)",
+		"-ui指定時にプレビューするYAMLファイルのパス":       "YAML file to preview with -ui",
+		"-ui指定時に使用するテンプレートファイルのパス（未指定時は-formatの組み込みテンプレート）":                            "template file to use with -ui (default: built-in template for -format)",
+		"-ui指定時に-templateが未指定の場合の出力フォーマット":                                             "output format used with -ui when -template is not given",
+		"使用法: %s serve [オプション]\n\n":                                                    "Usage: %s serve [options]\n\n",
+		"変換・バリデーションを行うHTTP APIサーバーを起動します。\n\n":                                         "Starts an HTTP API server for conversion and validation.\n\n",
+		"使用法: %s grpc [オプション]\n\n":                                                     "Usage: %s grpc [options]\n\n",
+		"変換・バリデーションを行うgRPCサーバーを起動します（proto/quizyaml/v1/quizyaml.proto）。\n\n":           "Starts a gRPC server for conversion and validation (proto/quizyaml/v1/quizyaml.proto).\n\n",
+		"リクエストの最大サイズ（バイト）":                                                             "maximum request size in bytes",
+		"\nエンドポイント:\n":                                                                 "\nEndpoints:\n",
+		"  POST /convert?format=csv|html|markdown  YAMLを受け取り変換結果を返す\n":                 "  POST /convert?format=csv|html|markdown  convert the YAML request body\n",
+		"  POST /validate                          YAMLを受け取りバリデーション結果をJSONで返す\n":       "  POST /validate                          validate the YAML request body and return JSON\n",
+		"  GET  /healthz                           稼働確認\n":                             "  GET  /healthz                           health check\n",
+		"  GET  /questions/random?genre=&exclude=  無作為に選んだ問題をJSONで返す（-questions指定時）\n": "  GET  /questions/random?genre=&exclude=  return randomly chosen quiz items as JSON (with -questions)\n",
+		"  GET  /questions/{id}                    IDで指定した問題をJSONで返す（-questions指定時）\n": "  GET  /questions/{id}                    return the quiz item with the given ID as JSON (with -questions)\n",
+		"  GET  /                                  プレビュー画面（-ui指定時）\n":                  "  GET  /                                  preview UI (with -ui)\n",
+		"エラー: -uiを指定する場合は-inputが必要です\n\n":                                              "error: -ui requires -input\n\n",
+		"エラー: プレビューできないフォーマットです: %s（%s）\n":                                             "error: format cannot be previewed: %s (%s)\n",
+		"プレビュー画面: http://%s/":                                                          "preview: http://%s/",
+		"サーバーを起動しました: %s":                                                              "server started: %s",
+		"サーバーの起動に失敗しました":                                                               "failed to start server",
+		"サーバーを停止しました":                                                                  "server stopped",
+		"変換しました":                                                                       "converted",
+		"バリデーションしました":                                                                  "validated",
+		"プレビューの描画に失敗しました":                                                              "failed to render preview",
+		"ファイルの変更を検出しました":                                                               "file change detected",
+
+		// diff
+		"出力形式（text, json）":    "output format (text, json)",
+		"差分がある場合に終了コード8で終了する": "exit with code 8 if there are differences",
+		"  roundtrip YAML→CSV→YAMLの往復変換で失われるフィールドを表示する（詳細は %s roundtrip -help）\n": "  roundtrip show fields lost in a YAML→CSV→YAML round trip (see %s roundtrip -help)\n",
+		"  rounds   問題をジャンルと難易度が偏らないように複数のラウンドに振り分ける（詳細は %s rounds -help）\n":      "  rounds   distribute items into rounds balanced by genre and difficulty (see %s rounds -help)\n",
+		"  import   CSVファイルを読み込んでYAMLファイルに書き出す（詳細は %s import -help）\n":            "  import   read a CSV file and write it as a YAML file (see %s import -help)\n",
+		"  choices  ほかの問題の答えを誤答として加え，多肢選択の問題にする（詳細は %s choices -help）\n":          "  choices  turn items into multiple-choice items with other answers as distractors (see %s choices -help)\n",
+		"  results  成績のCSVから問題ごとの正答率を集計し，難易度を見積もる（詳細は %s results -help）\n":        "  results  compute per-item correct rates from a results CSV and estimate difficulty (see %s results -help)\n",
+		"  scores   成績のCSVからプレイヤーごと・問題ごとの成績表を書き出す（詳細は %s scores -help）\n":         "  scores   write per-player and per-item score reports from a results CSV (see %s scores -help)\n",
+		"  live     問題を1問ずつ表示し，プレイヤーごとの正誤を記録する（詳細は %s live -help）\n":              "  live     present items one at a time and record each player's correct/incorrect (see %s live -help)\n",
+		"  buzzer   ブラウザから参加できる早押しクイズのサーバーを起動する（詳細は %s buzzer -help）\n":           "  buzzer   start a buzzer quiz server players join from a browser (see %s buzzer -help)\n",
+		"  dataset  問題を学習・検証・評価データに分割してJSON Linesで書き出す（詳細は %s dataset -help）\n":   "  dataset  split items into train/validation/test sets written as JSON Lines (see %s dataset -help)\n",
+		"CSVから読み戻した問題データを書き出すYAMLファイルのパス":                                         "path of a YAML file to write the items read back from CSV",
+		"失われるフィールドがある場合に終了コード8で終了する":                                              "exit with code 8 if any field is lost",
+		"使用法: %s roundtrip [オプション] quiz.yaml\n\n":                                 "Usage: %s roundtrip [options] quiz.yaml\n\n",
+		"YAMLをCSVに変換してから読み戻し，元のYAMLと比較します。\n":                                     "Converts YAML to CSV, reads it back and compares the result with the original YAML.\n",
+		"CSVへの出力で失われる，または値が変わるフィールドを表示します。\n\n":                                   "Shows the fields that are lost or changed by the CSV export.\n\n",
+		"エラー: 往復変換するYAMLファイルを1つ指定してください\n\n":                                      "error: specify one YAML file to round-trip\n\n",
+		"エラー: 往復変換に失敗しました: %v\n":                                                  "error: round trip failed: %v\n",
+		"エラー: %sの書き出しに失敗しました: %v\n":                                               "error: failed to write %s: %v\n",
+		"%d問すべてのフィールドがCSVから復元できました\n":                                             "all fields of %d items were restored from CSV\n",
+		"%d問中%d問で値が失われました: %s\n":                                                  "%d items, %d with lost values: %s\n",
+		"\n~ [問題 %d] %s\n": "\n~ [item %d] %s\n",
+		"使用法: %s diff [オプション] 変更前.yaml 変更後.yaml\n\n": "Usage: %s diff [options] old.yaml new.yaml\n\n",
+		"2つのYAMLファイルを比較し，追加・削除・変更された問題を表示します。\n":     "Compares two YAML files and shows added, removed and modified questions.\n",
+		"問題はidがあればidで，なければ問題文で対応付けます。\n\n":           "Questions are matched by id if present, otherwise by question text.\n\n",
+		"エラー: 比較する2つのYAMLファイルを指定してください\n\n":          "error: specify two YAML files to compare\n\n",
+		"エラー: サポートされていない出力形式です: %s（text, json）\n":    "error: unsupported output format: %s (text, json)\n",
+		"エラー: %sの読み込みに失敗しました: %v\n":                  "error: failed to load %s: %v\n",
+		"追加: %d問, 削除: %d問, 変更: %d問\n":                "added: %d, removed: %d, modified: %d\n",
+		"\n- [問題 %d] %s\n":      "\n- [item %d] %s\n",
+		"\n+ [問題 %d] %s\n":      "\n+ [item %d] %s\n",
+		"\n~ [問題 %d → %d] %s\n": "\n~ [item %d → %d] %s\n",
+
+		// ids
+		"書き出すYAMLファイルのパス（未指定時は入力ファイルを上書き）":          "path to write the YAML file to (default: overwrite the input file)",
+		"既にidがある問題のidも置き換える":                        "also replace existing ids",
+		"使用法: %s ids [オプション] quiz.yaml\n\n":         "Usage: %s ids [options] quiz.yaml\n\n",
+		"問題文と答えから決まるIDを各問題に割り当て，YAMLファイルに書き戻します。\n": "Assigns each item an ID derived from its question and answer and writes it back to the YAML file.\n",
+		"同じ内容の問題が複数ある場合は警告を表示します。\n\n":              "Warns when several items have the same content.\n\n",
+		"IDを割り当てるYAMLファイルを1つ指定してください":               "specify one YAML file to assign IDs to",
+		"同じ内容の問題があります: 問題 %v":                       "items with the same content: %v",
+		"IDを割り当てる問題はありませんでした":                       "no items needed an ID",
+		"YAMLファイルの書き出しに失敗しました":                      "failed to write YAML file",
+		"%d問にIDを割り当てました: %s":                        "assigned IDs to %d items: %s",
+
+		// migrate
+		"  migrate  古い形式のYAMLファイルを現在の形式に書き換える（詳細は %s migrate -help）\n":      "  migrate  upgrade a YAML file in an older layout to the current one (see %s migrate -help)\n",
+		"書き換えずに，書き換えが必要な箇所を表示する（必要な場合は終了コード8で終了する）":                         "show what needs upgrading without writing anything (exits with code 8 if something does)",
+		"使用法: %s migrate [オプション] quiz.yaml\n\n":                             "Usage: %s migrate [options] quiz.yaml\n\n",
+		"古い形式（ジャンル名をキーとしたマッピング，文字列で書いたcriteriaなど）のYAMLファイルを現在の形式に書き換えます。\n": "Upgrades a YAML file in an older layout (items nested under genre names, criteria written as strings, ...) to the current one.\n",
+		"metadataのversionは%dになります。YAML中のコメントは残ります。\n\n":                     "The metadata version is set to %d. Comments in the YAML are kept.\n\n",
+		"書き換えるYAMLファイルを1つ指定してください（-check指定時は複数指定できます）":                      "specify one YAML file to upgrade (several with -check)",
+		"YAMLファイルの書き換えに失敗しました":                                              "failed to upgrade YAML file",
+		"%d個のファイルは書き換えが必要です":                                                "%d files need upgrading",
+		"すべてのファイルが現在の形式です":                                                  "all files are in the current layout",
+		"書き換えは不要です（現在の形式です）: %s":                                            "nothing to upgrade (already in the current layout): %s",
+		"%d箇所を書き換えました: %s":                                                  "made %d changes: %s",
+
+		// rounds
+		"ラウンド数（必須）": "number of rounds (required)",
+		"1ラウンドあたりの問題数（未指定時はすべての問題を振り分ける）":                                            "number of items per round (default: distribute all items)",
+		"ラウンドごとのYAMLファイル（round-1.yamlなど）を書き出すディレクトリ":                                 "directory to write the per-round YAML files (round-1.yaml, ...) to",
+		"問題を選ぶ順序をシャッフルする乱数のシード（0の場合は入力の順に選ぶ）":                                        "random seed for shuffling the order items are picked in (0 picks them in input order)",
+		"使用法: %s rounds [オプション] quiz.yaml [quiz2.yaml ...]\n\n":                      "Usage: %s rounds [options] quiz.yaml [quiz2.yaml ...]\n\n",
+		"問題を複数のラウンドに振り分け，ラウンドごとのYAMLファイルに書き出します。\n":                                  "Distributes items into several rounds and writes one YAML file per round.\n",
+		"各ラウンドのジャンルの数と難易度（difficulty）の合計ができるだけ揃うように振り分けます。\n\n":                      "Rounds get as even a genre mix and difficulty total as possible.\n\n",
+		"振り分けるYAMLファイルを指定してください":                                                     "specify the YAML files to distribute",
+		"-roundsには1以上の数を指定してください":                                                    "-rounds must be 1 or more",
+		"問題の振り分けに失敗しました":                                                             "failed to distribute items into rounds",
+		"出力ディレクトリの作成に失敗しました":                                                         "failed to create output directory",
+		"学習データ（train.jsonl）の割合":                                                      "ratio of the training set (train.jsonl)",
+		"検証データ（validation.jsonl）の割合":                                                 "ratio of the validation set (validation.jsonl)",
+		"評価データ（test.jsonl）の割合":                                                       "ratio of the test set (test.jsonl)",
+		"ジャンルごとに同じ割合で分割する":                                                           "split each genre in the same ratios",
+		"分割に使う乱数のシード（0の場合は毎回異なる分割になる）":                                               "random seed for the split (0 gives a different split each run)",
+		"分割したJSON Linesファイルを書き出すディレクトリ":                                              "directory to write the split JSON Lines files to",
+		"使用法: %s dataset [オプション] quiz.yaml [quiz2.yaml ...]\n\n":                     "Usage: %s dataset [options] quiz.yaml [quiz2.yaml ...]\n\n",
+		"問題を学習・検証・評価データに無作為に分割し，train.jsonl，validation.jsonl，test.jsonlに書き出します。\n":   "Randomly splits items into training, validation and test sets and writes train.jsonl, validation.jsonl and test.jsonl.\n",
+		"割合が0のデータのファイルは書き出しません。idがない問題には問題文と答えから決まるIDを付けます。\n":                       "Sets with a ratio of 0 are not written. Items without an id get one derived from the question and answer.\n",
+		"-hfを指定すると，Hugging Faceのdatasetsで読み込めるdata/*.jsonlとデータセットカードの雛形を書き出します。\n\n": "With -hf, writes data/*.jsonl and a dataset card skeleton loadable by Hugging Face datasets.\n\n",
+		"Hugging Faceのdatasetsで読み込める構成（data/*.jsonlとREADME.mdのデータセットカード）で書き出す":       "write the layout loadable by Hugging Face datasets (data/*.jsonl and a README.md dataset card)",
+		"-hfで書き出すデータセットカードの見出し（未指定時は出力ディレクトリの名前）":                                    "title of the dataset card written with -hf (the output directory name if omitted)",
+		"-hfで既存のデータセットカード（README.md）を上書きする":                                          "overwrite an existing dataset card (README.md) with -hf",
+		"書き出しました: %s": "wrote %s",
+		"Hugging Face形式のデータセットの書き出しに失敗しました":              "failed to write the Hugging Face dataset",
+		"既存のデータセットカードを残しました（上書きするには-forceを指定してください）: %s": "kept the existing dataset card (use -force to overwrite): %s",
+		"train %d問，validation %d問，test %d問":              "train %d items, validation %d items, test %d items",
+		"分割するYAMLファイルを指定してください":                          "specify the YAML files to split",
+		"問題の分割に失敗しました":                                   "failed to split items",
+		"JSON Linesファイルの書き出しに失敗しました":                     "failed to write JSON Lines file",
+		"%s: %d問: %s": "%s: %d items: %s",
+		"ラウンド %d: %d問（%s，難易度の合計 %d）: %s": "round %d: %d items (%s, difficulty total %d): %s",
+		"ジャンルなし": "no genre",
+		"，":      ", ",
+
+		// import
+		"書き出すYAMLファイルのパス（必須）": "path to write the YAML file to (required)",
+		"CSVの列とフィールドの対応（例: question=1,answer=3,spell=4．番号の代わりにヘッダー行の列名も指定できる）": "mapping from fields to CSV columns (e.g. question=1,answer=3,spell=4; header names can be used instead of numbers)",
+		"CSVにヘッダー行がない":                                          "the CSV has no header row",
+		"CSVのフィールドの先頭の数式避けの'を取り除く":                              "remove the ' added to CSV fields to prevent formula evaluation",
+		"使用法: %s import [オプション] -output quiz.yaml quiz.csv\n\n": "Usage: %s import [options] -output quiz.yaml quiz.csv\n\n",
+		"CSVファイルを読み込み，問題データをYAMLファイルに書き出します。\n":                 "Reads a CSV file and writes its items to a YAML file.\n",
+		"-mapで，列の並びが異なるCSVの各列をフィールドに対応付けられます。\n\n":              "Use -map to assign the columns of CSVs with a different layout to fields.\n\n",
+		"読み込むCSVファイルを1つ指定してください":                                "specify one CSV file to read",
+		"-outputを指定してください":                                      "specify -output",
+		"-mapの指定が正しくありません":                                      "invalid -map",
+		"CSVファイルの読み込みに失敗しました":                                   "failed to read CSV file",
+		"%d問を読み込みました: %s":                                       "imported %d items: %s",
+
+		// choices
+		"答えを含む選択肢の数":                                                                  "number of choices including the answer",
+		"誤答の選び方と選択肢の並びを決める乱数のシード":                                                     "random seed for picking distractors and ordering the choices",
+		"使用法: %s choices [オプション] -output choices.yaml quiz.yaml [quiz2.yaml ...]\n\n": "Usage: %s choices [options] -output choices.yaml quiz.yaml [quiz2.yaml ...]\n\n",
+		"記述式の問題に，ほかの問題の答えから選んだ誤答を加えて多肢選択の問題にします。\n":                                   "Turns free-answer items into multiple-choice items using other items' answers as distractors.\n",
+		"誤答は同じジャンル，同じ文字種，近い文字数の答えから優先して選び，choicesフィールドに書き出します。\n\n":                   "Distractors from the same genre, the same script and a similar length are preferred; the result is written to the choices field.\n\n",
+		"選択肢を作るYAMLファイルを指定してください":                                                     "specify the YAML files to make choices for",
+		"-countには2以上の数を指定してください":                                                      "-count must be 2 or more",
+		"選択肢の作成に失敗しました":                                                               "failed to make choices",
+		"%d問を%d択の問題にしました: %s":                                                         "made %d items into %d-choice items: %s",
+
+		// results
+		"成績のCSVファイル（必須）":                                             "results CSV file (required)",
+		"正答率から見積もった難易度（difficulty）をYAMLファイルに書き戻す":                    "write the difficulty estimated from the correct rate back to the YAML file",
+		"見積もる難易度の段階数":                                                "number of difficulty levels to estimate",
+		"-write-difficulty で書き出すYAMLファイルのパス（未指定時は入力ファイルを上書き）":        "path to write the YAML file to with -write-difficulty (overwrites the input file if omitted)",
+		"使用法: %s results [オプション] -results results.csv quiz.yaml\n\n": "Usage: %s results [options] -results results.csv quiz.yaml\n\n",
+		"成績のCSV（1列目が問題ID，2列目以降がプレイヤーごとの正誤）を読み込み，問題ごとの正答率を表示します。\n":   "Reads a results CSV (question id in the first column, each player's correct/incorrect in the others) and shows the correct rate of each item.\n",
+		"問題はidがあればidで，なければ問題文と答えから決まるIDで対応付けます。\n\n":                 "Items are matched by id, or by the ID derived from the question and answer if they have none.\n\n",
+		"成績のCSVファイル（-results）とYAMLファイルを1つ指定してください":                   "specify a results CSV file (-results) and one YAML file",
+		"難易度の段階数は1以上を指定してください: %d":                                   "the number of difficulty levels must be 1 or more: %d",
+		"成績のCSVファイルの読み込みに失敗しました":                                     "failed to read results CSV file",
+		"成績の問題IDに対応する問題がありません: %s":                                   "no item matches the question id in the results: %s",
+		"難易度を変更する問題はありませんでした":                                        "no item difficulty changed",
+		"%d問の難易度を書き換えました: %s":                                        "updated the difficulty of %d items: %s",
+		"プレイヤー: %d人, 成績のある問題: %d / %d問\n":                            "players: %d, items with results: %d / %d\n",
+		"\n[問題 %d] %s\n    成績なし\n":                                   "\n[item %d] %s\n    no results\n",
+		"\n[問題 %d] %s\n    正答率: %.1f%%（%d / %d）, 難易度: %d\n":          "\n[item %d] %s\n    correct rate: %.1f%% (%d / %d), difficulty: %d\n",
+
+		// scores
+		"書き出す成績表のパス（必須）":                                                                                 "path to write the score report to (required)",
+		"成績表のテンプレートファイル（未指定時は組み込みのHTMLテンプレート）":                                                           "template file for the score report (default: built-in HTML template)",
+		"使用法: %s scores [オプション] -results results.csv -output report.html quiz.yaml [quiz2.yaml ...]\n\n": "Usage: %s scores [options] -results results.csv -output report.html quiz.yaml [quiz2.yaml ...]\n\n",
+		"問題データと成績のCSVから，プレイヤーごと・問題ごとの成績表を書き出します。\n":                                                      "Writes per-player and per-item score reports from quiz items and a results CSV.\n",
+		"成績のCSVの形式はresultsサブコマンドと同じです。\n\n":                                                              "The results CSV has the same format as for the results subcommand.\n\n",
+		"成績のCSVファイル（-results）とYAMLファイルを指定してください":                                                         "specify a results CSV file (-results) and YAML files",
+		"成績表の書き出しに失敗しました":                                                                                "failed to write the score report",
+		"%d人・%d問の成績表を書き出しました: %s":                                                                        "wrote a score report for %d players and %d items: %s",
+
+		// live
+		"プレイヤー名をカンマ区切りで指定（必須．最大9人）":                                                                "comma-separated player names (required, up to 9)",
+		"書き出す成績のCSVファイルのパス（必須）":                                                                    "path to write the results CSV to (required)",
+		"使用法: %s live [オプション] -players 名前1,名前2 -output results.csv quiz.yaml [quiz2.yaml ...]\n\n": "Usage: %s live [options] -players name1,name2 -output results.csv quiz.yaml [quiz2.yaml ...]\n\n",
+		"問題を1問ずつ表示し，キー操作で各プレイヤーの正誤を記録します。\n":                                                       "Presents items one at a time and records each player's correct/incorrect with key presses.\n",
+		"終了すると，resultsサブコマンドやscoresサブコマンドで使える成績のCSVを書き出します。\n\n":                                   "On exit, writes a results CSV for the results and scores subcommands.\n\n",
+		"キー操作:\n": "Keys:\n",
+		"  1〜9       プレイヤーの正誤を切り替える（○ → × → なし）\n": "  1-9        cycle a player's result (correct → incorrect → none)\n",
+		"  Space, →, n 次の問題\n":                                  "  Space, →, n next item\n",
+		"  ←, p       前の問題\n":                                   "  ←, p       previous item\n",
+		"  q, Ctrl-C  終了して成績を書き出す\n\n":                          "  q, Ctrl-C  quit and write the results\n\n",
+		"出題するYAMLファイルを指定してください":                                 "specify the YAML files to present",
+		"-playersには1〜%d人のプレイヤー名を指定してください":                       "-players must list 1 to %d player names",
+		"出題の準備に失敗しました":                                          "failed to prepare the items",
+		"司会者の画面を開くためのキー（未指定時は起動のたびに生成）":                         "key to open the host screen (generated at each start if omitted)",
+		"ボタンや解答などの操作ごとのログを出力する":                                 "log each buzz, answer and other action",
+		"使用法: %s buzzer [オプション] quiz.yaml [quiz2.yaml ...]\n\n": "usage: %s buzzer [options] quiz.yaml [quiz2.yaml ...]\n\n",
+		"ブラウザから参加できる早押しクイズのサーバーを起動します。\n":                       "Starts a buzzer quiz server that players join from a browser.\n",
+		"最初にボタンを押したプレイヤーが解答権を得て，入力した解答を答え・読み・criteriaで判定します。\n": "The first player to buzz gets to answer, and the typed answer is judged against the answer, reading and criteria.\n",
+		"誤答したプレイヤーはその問題ではボタンを押せなくなります。\n":                       "A player who answers incorrectly is locked out of that question.\n",
+		"司会者は起動時に表示されるURLから，出題の進行と口頭の解答の判定を行います。\n\n":           "The host advances questions and judges spoken answers from the URL shown at startup.\n\n",
+		"プレイヤーの画面: http://%s/":                "player screen: http://%s/",
+		"司会者の画面: http://%s/?host=%s":          "host screen: http://%s/?host=%s",
+		"司会者が接続しました":                          "the host connected",
+		"プレイヤーが参加しました: %s":                    "player joined: %s",
+		"プレイヤーが切断しました: %s":                    "player left: %s",
+		"操作を受け付けませんでした":                       "rejected an action",
+		"解答を判定しました":                           "judged an answer",
+		"状態を送れませんでした":                         "failed to send the state",
+		"端末を1文字ずつ入力するモードにできませんでした":            "could not put the terminal into raw mode",
+		"キー入力の読み込みに失敗しました":                    "failed to read key input",
+		"成績のCSVファイルの書き出しに失敗しました":              "failed to write results CSV file",
+		"成績を代わりに書き出しました: %s":                  "wrote the results to a fallback file instead: %s",
+		"代わりのファイルにも書き出せなかったため，成績を標準出力に書き出します": "could not write a fallback file either; writing the results to standard output",
+		"%d問・%d人の成績を書き出しました: %s":              "wrote results for %d items and %d players: %s",
+		"プレイヤー%dはいません":                        "there is no player %d",
+		"最後の問題です。qで終了して成績を書き出します":             "this is the last item; press q to quit and write the results",
+		"最初の問題です":                             "this is the first item",
+		"Q%s（%d / %d）":                        "Q%s (%d / %d)",
+		"読み: %s\n":                            "reading: %s\n",
+		"判定: %s\n":                            "criteria: %s\n",
+		"  %d %s  %s  正解 %d\n":                "  %d %s  %s  correct %d\n",
+		"1〜9: 正誤を切り替え  Space/→: 次の問題  ←: 前の問題  q: 終了して保存": "1-9: toggle result  Space/→: next  ←: previous  q: quit and save",
+	},
+}
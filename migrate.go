@@ -0,0 +1,93 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/m-uesaka/quiz-yaml-go/quiz_yaml_converter"
+)
+
+// runMigrate はmigrateサブコマンドを実行する．
+// 古い形式のYAMLファイルを現在の形式（quiz_yaml_converter.CurrentSchemaVersion）に書き換える．
+func runMigrate(args []string) {
+	fs := flag.NewFlagSet("migrate", flag.ExitOnError)
+	var (
+		output    = fs.String("output", "", T("書き出すYAMLファイルのパス（未指定時は入力ファイルを上書き）"))
+		check     = fs.Bool("check", false, T("書き換えずに，書き換えが必要な箇所を表示する（必要な場合は終了コード8で終了する）"))
+		quiet     = fs.Bool("quiet", false, T("エラー以外のメッセージを出力しない"))
+		verbose   = fs.Bool("verbose", false, T("詳細なメッセージを出力する"))
+		logFormat = fs.String("log-format", logFormatText, T("メッセージの出力形式（text, json）"))
+	)
+	addLangFlag(fs)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, T("使用法: %s migrate [オプション] quiz.yaml\n\n"), filepath.Base(os.Args[0]))
+		fmt.Fprint(os.Stderr, T("古い形式（ジャンル名をキーとしたマッピング，文字列で書いたcriteriaなど）のYAMLファイルを現在の形式に書き換えます。\n"))
+		fmt.Fprintf(os.Stderr, T("metadataのversionは%dになります。YAML中のコメントは残ります。\n\n"), quiz_yaml_converter.CurrentSchemaVersion)
+		fmt.Fprint(os.Stderr, T("オプション:\n"))
+		fs.PrintDefaults()
+		fmt.Fprint(os.Stderr, T("\n例:\n"))
+		fmt.Fprintf(os.Stderr, "  %s migrate quiz.yaml\n", filepath.Base(os.Args[0]))
+		fmt.Fprintf(os.Stderr, "  %s migrate -check archive/*.yaml\n", filepath.Base(os.Args[0]))
+	}
+	fs.Parse(args)
+
+	log, err := newLogger(os.Stderr, *logFormat, *quiet, *verbose)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, T("エラー: %v\n"), err)
+		os.Exit(exitUsage)
+	}
+	if fs.NArg() == 0 || fs.NArg() > 1 && !*check {
+		log.Error(T("書き換えるYAMLファイルを1つ指定してください（-check指定時は複数指定できます）"))
+		fs.Usage()
+		os.Exit(exitUsage)
+	}
+
+	if *check {
+		outdated := 0
+		for _, inputFile := range fs.Args() {
+			yamlData, err := os.ReadFile(inputFile)
+			if err != nil {
+				log.Error(T("YAMLファイルの読み込みに失敗しました"), "input", inputFile, "error", err)
+				os.Exit(exitError)
+			}
+			_, changes, err := quiz_yaml_converter.MigrateYAML(yamlData)
+			if err != nil {
+				log.Error(T("YAMLファイルの書き換えに失敗しました"), "input", inputFile, "error", err)
+				os.Exit(exitCodeFor(err))
+			}
+			for _, c := range changes {
+				log.Warn(fmt.Sprintf("%s: %s", inputFile, c.Localized(lang)), "input", inputFile, "line", c.Line)
+			}
+			if len(changes) > 0 {
+				outdated++
+			}
+		}
+		if outdated > 0 {
+			log.Warn(fmt.Sprintf(T("%d個のファイルは書き換えが必要です"), outdated), "files", outdated)
+			os.Exit(exitDifferent)
+		}
+		log.Info(T("すべてのファイルが現在の形式です"))
+		return
+	}
+
+	inputFile := fs.Arg(0)
+	outputFile := *output
+	if outputFile == "" {
+		outputFile = inputFile
+	}
+	changes, err := quiz_yaml_converter.MigrateYAMLFile(inputFile, outputFile)
+	if err != nil {
+		log.Error(T("YAMLファイルの書き換えに失敗しました"), "input", inputFile, "error", err)
+		os.Exit(exitCodeFor(err))
+	}
+	for _, c := range changes {
+		log.Debug(c.Localized(lang), "input", inputFile, "line", c.Line)
+	}
+	if len(changes) == 0 {
+		log.Info(fmt.Sprintf(T("書き換えは不要です（現在の形式です）: %s"), inputFile), "input", inputFile)
+		return
+	}
+	log.Info(fmt.Sprintf(T("%d箇所を書き換えました: %s"), len(changes), outputFile), "input", inputFile, "output", outputFile, "changes", len(changes))
+}
@@ -0,0 +1,202 @@
+package main
+
+import (
+	"bytes"
+	_ "embed"
+	"fmt"
+	"html"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/m-uesaka/quiz-yaml-go/quiz_yaml_converter"
+)
+
+// プレビュー画面のHTML
+//
+//go:embed ui/preview.html
+var previewPage []byte
+
+// ファイルの変更を確認する間隔
+const previewPollInterval = 500 * time.Millisecond
+
+// previewUI は入力YAMLとテンプレートを描画し，ファイルの変更を
+// Server-Sent Eventsでブラウザに通知するプレビュー機能．
+type previewUI struct {
+	inputFile    string
+	templateFile string // 空の場合はformatの出力形式で描画する
+	format       string
+	templates    *quiz_yaml_converter.TemplateCache // 変更されていないテンプレートは解析し直さない
+	log          *slog.Logger
+
+	mu          sync.Mutex
+	subscribers map[chan struct{}]struct{}
+}
+
+func newPreviewUI(inputFile, templateFile, format string, log *slog.Logger) *previewUI {
+	return &previewUI{
+		inputFile:    inputFile,
+		templateFile: templateFile,
+		format:       format,
+		templates:    quiz_yaml_converter.NewTemplateCache(quiz_yaml_converter.ConvertOptions{}),
+		log:          log,
+		subscribers:  map[chan struct{}]struct{}{},
+	}
+}
+
+// register はプレビュー用のエンドポイントを登録する．
+func (p *previewUI) register(mux *http.ServeMux) {
+	mux.HandleFunc("GET /{$}", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write(previewPage)
+	})
+	mux.HandleFunc("GET /preview", p.handlePreview)
+	mux.HandleFunc("GET /preview/info", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]string{"input": p.inputFile, "template": p.templateFile, "format": p.format})
+	})
+	mux.HandleFunc("GET /events", p.handleEvents)
+}
+
+// handlePreview は現在のYAMLとテンプレートで描画した結果を返す．
+// エラーが発生した場合はエラーメッセージを表示するページを返す．
+func (p *previewUI) handlePreview(w http.ResponseWriter, r *http.Request) {
+	var buf bytes.Buffer
+	contentType, err := p.render(&buf)
+	if err != nil {
+		p.log.Debug(T("プレビューの描画に失敗しました"), "error", err)
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		fmt.Fprintf(w, "<!DOCTYPE html><meta charset=\"UTF-8\"><h2 style=\"color:#c0392b\">%s</h2><pre>%s</pre>", T("エラー"), html.EscapeString(err.Error()))
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Write(buf.Bytes())
+}
+
+// render は入力YAMLをテンプレートまたは出力形式で描画し，結果のContent-Typeを返す．
+func (p *previewUI) render(w io.Writer) (string, error) {
+	data, err := quiz_yaml_converter.LoadYAMLData(p.inputFile)
+	if err != nil {
+		return "", err
+	}
+	if p.templateFile != "" {
+		contentType := "text/plain; charset=utf-8"
+		if ext := strings.ToLower(p.templateFile); strings.HasSuffix(ext, ".html") || strings.HasSuffix(ext, ".htm") {
+			contentType = "text/html; charset=utf-8"
+		}
+		tmpl, err := p.templates.Get(p.templateFile)
+		if err != nil {
+			return "", err
+		}
+		return contentType, tmpl.Execute(w, data)
+	}
+
+	f, err := quiz_yaml_converter.LookupFormatter(p.format)
+	if err != nil {
+		return "", err
+	}
+	return quiz_yaml_converter.ContentType(f, quiz_yaml_converter.ConvertOptions{}), f.Format(w, data, quiz_yaml_converter.ConvertOptions{})
+}
+
+// handleEvents はファイルが変更されるたびにreloadイベントを送る．
+func (p *previewUI) handleEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	fmt.Fprint(w, ": connected\n\n")
+	flusher.Flush()
+
+	ch := make(chan struct{}, 1)
+	p.mu.Lock()
+	p.subscribers[ch] = struct{}{}
+	p.mu.Unlock()
+	defer func() {
+		p.mu.Lock()
+		delete(p.subscribers, ch)
+		p.mu.Unlock()
+	}()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-ch:
+			fmt.Fprint(w, "event: reload\ndata: {}\n\n")
+			flusher.Flush()
+		}
+	}
+}
+
+// fileStamp はファイルの変更を検出するための更新日時とサイズ．
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+func (s fileStamp) equal(other fileStamp) bool {
+	return s.modTime.Equal(other.modTime) && s.size == other.size
+}
+
+func statStamp(path string) fileStamp {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fileStamp{}
+	}
+	return fileStamp{modTime: info.ModTime(), size: info.Size()}
+}
+
+// watch は入力YAMLとテンプレートの変更を定期的に確認し，変更があれば
+// 接続中のブラウザに通知する．stopが閉じられるまで処理を続ける．
+func (p *previewUI) watch(stop <-chan struct{}) {
+	paths := []string{p.inputFile}
+	if p.templateFile != "" {
+		paths = append(paths, p.templateFile)
+	}
+	stamps := make([]fileStamp, len(paths))
+	for i, path := range paths {
+		stamps[i] = statStamp(path)
+	}
+
+	ticker := time.NewTicker(previewPollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+
+		changed := false
+		for i, path := range paths {
+			if stamp := statStamp(path); !stamp.equal(stamps[i]) {
+				stamps[i] = stamp
+				changed = true
+				p.log.Debug(T("ファイルの変更を検出しました"), "path", path)
+			}
+		}
+		if changed {
+			p.notify()
+		}
+	}
+}
+
+// notify は接続中のすべてのブラウザに再読み込みを通知する．
+func (p *previewUI) notify() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for ch := range p.subscribers {
+		select {
+		case ch <- struct{}{}:
+		default:
+			// 通知済みで未処理のものがある場合はまとめる
+		}
+	}
+}
@@ -0,0 +1,514 @@
+// クイズYAML変換ツールのgRPCサービス定義．
+// converter grpcで起動したサーバーに対して，変換とバリデーションを呼び出せます．
+//
+// コードの生成:
+//
+//   protoc --go_out=. --go_opt=paths=source_relative \
+//     --go-grpc_out=. --go-grpc_opt=paths=source_relative \
+//     proto/quizyaml/v1/quizyaml.proto
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.9
+// 	protoc        v5.29.3
+// source: proto/quizyaml/v1/quizyaml.proto
+
+package quizyamlv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type ValidateRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// クイズYAMLの内容
+	Yaml []byte `protobuf:"bytes,1,opt,name=yaml,proto3" json:"yaml,omitempty"`
+	// メッセージの言語（ja, en）．空の場合はja
+	Lang          string `protobuf:"bytes,2,opt,name=lang,proto3" json:"lang,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ValidateRequest) Reset() {
+	*x = ValidateRequest{}
+	mi := &file_proto_quizyaml_v1_quizyaml_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ValidateRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ValidateRequest) ProtoMessage() {}
+
+func (x *ValidateRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_quizyaml_v1_quizyaml_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ValidateRequest.ProtoReflect.Descriptor instead.
+func (*ValidateRequest) Descriptor() ([]byte, []int) {
+	return file_proto_quizyaml_v1_quizyaml_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *ValidateRequest) GetYaml() []byte {
+	if x != nil {
+		return x.Yaml
+	}
+	return nil
+}
+
+func (x *ValidateRequest) GetLang() string {
+	if x != nil {
+		return x.Lang
+	}
+	return ""
+}
+
+type ValidationError struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// 問題の番号（1始まり）．ファイル全体に関するエラーの場合は0
+	Index int32 `protobuf:"varint,1,opt,name=index,proto3" json:"index,omitempty"`
+	// エラーの対象となったフィールド（例: criteria.ok[0]）
+	Field string `protobuf:"bytes,2,opt,name=field,proto3" json:"field,omitempty"`
+	// エラーメッセージ
+	Message string `protobuf:"bytes,3,opt,name=message,proto3" json:"message,omitempty"`
+	// 違反した規則の名前（例: required）．HTTP APIのruleと同じ
+	Rule          string `protobuf:"bytes,4,opt,name=rule,proto3" json:"rule,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ValidationError) Reset() {
+	*x = ValidationError{}
+	mi := &file_proto_quizyaml_v1_quizyaml_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ValidationError) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ValidationError) ProtoMessage() {}
+
+func (x *ValidationError) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_quizyaml_v1_quizyaml_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ValidationError.ProtoReflect.Descriptor instead.
+func (*ValidationError) Descriptor() ([]byte, []int) {
+	return file_proto_quizyaml_v1_quizyaml_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ValidationError) GetIndex() int32 {
+	if x != nil {
+		return x.Index
+	}
+	return 0
+}
+
+func (x *ValidationError) GetField() string {
+	if x != nil {
+		return x.Field
+	}
+	return ""
+}
+
+func (x *ValidationError) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *ValidationError) GetRule() string {
+	if x != nil {
+		return x.Rule
+	}
+	return ""
+}
+
+type ValidateResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Valid bool                   `protobuf:"varint,1,opt,name=valid,proto3" json:"valid,omitempty"`
+	// 読み込まれた問題数
+	Items         int32              `protobuf:"varint,2,opt,name=items,proto3" json:"items,omitempty"`
+	Errors        []*ValidationError `protobuf:"bytes,3,rep,name=errors,proto3" json:"errors,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ValidateResponse) Reset() {
+	*x = ValidateResponse{}
+	mi := &file_proto_quizyaml_v1_quizyaml_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ValidateResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ValidateResponse) ProtoMessage() {}
+
+func (x *ValidateResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_quizyaml_v1_quizyaml_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ValidateResponse.ProtoReflect.Descriptor instead.
+func (*ValidateResponse) Descriptor() ([]byte, []int) {
+	return file_proto_quizyaml_v1_quizyaml_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ValidateResponse) GetValid() bool {
+	if x != nil {
+		return x.Valid
+	}
+	return false
+}
+
+func (x *ValidateResponse) GetItems() int32 {
+	if x != nil {
+		return x.Items
+	}
+	return 0
+}
+
+func (x *ValidateResponse) GetErrors() []*ValidationError {
+	if x != nil {
+		return x.Errors
+	}
+	return nil
+}
+
+type ConvertRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// クイズYAMLの内容
+	Yaml []byte `protobuf:"bytes,1,opt,name=yaml,proto3" json:"yaml,omitempty"`
+	// 出力形式（csv, html, markdown, jsonなど）．空の場合はcsv
+	Format string `protobuf:"bytes,2,opt,name=format,proto3" json:"format,omitempty"`
+	// 変換オプション．キーはserveの/convertのクエリパラメータと同じ
+	// （例: columns=question,answer, encoding=sjis, number-by=genre）
+	Options       map[string]string `protobuf:"bytes,3,rep,name=options,proto3" json:"options,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ConvertRequest) Reset() {
+	*x = ConvertRequest{}
+	mi := &file_proto_quizyaml_v1_quizyaml_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ConvertRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ConvertRequest) ProtoMessage() {}
+
+func (x *ConvertRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_quizyaml_v1_quizyaml_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ConvertRequest.ProtoReflect.Descriptor instead.
+func (*ConvertRequest) Descriptor() ([]byte, []int) {
+	return file_proto_quizyaml_v1_quizyaml_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ConvertRequest) GetYaml() []byte {
+	if x != nil {
+		return x.Yaml
+	}
+	return nil
+}
+
+func (x *ConvertRequest) GetFormat() string {
+	if x != nil {
+		return x.Format
+	}
+	return ""
+}
+
+func (x *ConvertRequest) GetOptions() map[string]string {
+	if x != nil {
+		return x.Options
+	}
+	return nil
+}
+
+type ConvertResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// 変換結果
+	Output []byte `protobuf:"bytes,1,opt,name=output,proto3" json:"output,omitempty"`
+	// 変換結果のContent-Type（例: text/csv; charset=utf-8）
+	ContentType   string `protobuf:"bytes,2,opt,name=content_type,json=contentType,proto3" json:"content_type,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ConvertResponse) Reset() {
+	*x = ConvertResponse{}
+	mi := &file_proto_quizyaml_v1_quizyaml_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ConvertResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ConvertResponse) ProtoMessage() {}
+
+func (x *ConvertResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_quizyaml_v1_quizyaml_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ConvertResponse.ProtoReflect.Descriptor instead.
+func (*ConvertResponse) Descriptor() ([]byte, []int) {
+	return file_proto_quizyaml_v1_quizyaml_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ConvertResponse) GetOutput() []byte {
+	if x != nil {
+		return x.Output
+	}
+	return nil
+}
+
+func (x *ConvertResponse) GetContentType() string {
+	if x != nil {
+		return x.ContentType
+	}
+	return ""
+}
+
+type ListFormatsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListFormatsRequest) Reset() {
+	*x = ListFormatsRequest{}
+	mi := &file_proto_quizyaml_v1_quizyaml_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListFormatsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListFormatsRequest) ProtoMessage() {}
+
+func (x *ListFormatsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_quizyaml_v1_quizyaml_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListFormatsRequest.ProtoReflect.Descriptor instead.
+func (*ListFormatsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_quizyaml_v1_quizyaml_proto_rawDescGZIP(), []int{5}
+}
+
+type ListFormatsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Formats       []string               `protobuf:"bytes,1,rep,name=formats,proto3" json:"formats,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListFormatsResponse) Reset() {
+	*x = ListFormatsResponse{}
+	mi := &file_proto_quizyaml_v1_quizyaml_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListFormatsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListFormatsResponse) ProtoMessage() {}
+
+func (x *ListFormatsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_quizyaml_v1_quizyaml_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListFormatsResponse.ProtoReflect.Descriptor instead.
+func (*ListFormatsResponse) Descriptor() ([]byte, []int) {
+	return file_proto_quizyaml_v1_quizyaml_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *ListFormatsResponse) GetFormats() []string {
+	if x != nil {
+		return x.Formats
+	}
+	return nil
+}
+
+var File_proto_quizyaml_v1_quizyaml_proto protoreflect.FileDescriptor
+
+const file_proto_quizyaml_v1_quizyaml_proto_rawDesc = "" +
+	"\n" +
+	" proto/quizyaml/v1/quizyaml.proto\x12\vquizyaml.v1\"9\n" +
+	"\x0fValidateRequest\x12\x12\n" +
+	"\x04yaml\x18\x01 \x01(\fR\x04yaml\x12\x12\n" +
+	"\x04lang\x18\x02 \x01(\tR\x04lang\"k\n" +
+	"\x0fValidationError\x12\x14\n" +
+	"\x05index\x18\x01 \x01(\x05R\x05index\x12\x14\n" +
+	"\x05field\x18\x02 \x01(\tR\x05field\x12\x18\n" +
+	"\amessage\x18\x03 \x01(\tR\amessage\x12\x12\n" +
+	"\x04rule\x18\x04 \x01(\tR\x04rule\"t\n" +
+	"\x10ValidateResponse\x12\x14\n" +
+	"\x05valid\x18\x01 \x01(\bR\x05valid\x12\x14\n" +
+	"\x05items\x18\x02 \x01(\x05R\x05items\x124\n" +
+	"\x06errors\x18\x03 \x03(\v2\x1c.quizyaml.v1.ValidationErrorR\x06errors\"\xbc\x01\n" +
+	"\x0eConvertRequest\x12\x12\n" +
+	"\x04yaml\x18\x01 \x01(\fR\x04yaml\x12\x16\n" +
+	"\x06format\x18\x02 \x01(\tR\x06format\x12B\n" +
+	"\aoptions\x18\x03 \x03(\v2(.quizyaml.v1.ConvertRequest.OptionsEntryR\aoptions\x1a:\n" +
+	"\fOptionsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"L\n" +
+	"\x0fConvertResponse\x12\x16\n" +
+	"\x06output\x18\x01 \x01(\fR\x06output\x12!\n" +
+	"\fcontent_type\x18\x02 \x01(\tR\vcontentType\"\x14\n" +
+	"\x12ListFormatsRequest\"/\n" +
+	"\x13ListFormatsResponse\x12\x18\n" +
+	"\aformats\x18\x01 \x03(\tR\aformats2\xf0\x01\n" +
+	"\rQuizConverter\x12G\n" +
+	"\bValidate\x12\x1c.quizyaml.v1.ValidateRequest\x1a\x1d.quizyaml.v1.ValidateResponse\x12D\n" +
+	"\aConvert\x12\x1b.quizyaml.v1.ConvertRequest\x1a\x1c.quizyaml.v1.ConvertResponse\x12P\n" +
+	"\vListFormats\x12\x1f.quizyaml.v1.ListFormatsRequest\x1a .quizyaml.v1.ListFormatsResponseB?Z=github.com/m-uesaka/quiz-yaml-go/proto/quizyaml/v1;quizyamlv1b\x06proto3"
+
+var (
+	file_proto_quizyaml_v1_quizyaml_proto_rawDescOnce sync.Once
+	file_proto_quizyaml_v1_quizyaml_proto_rawDescData []byte
+)
+
+func file_proto_quizyaml_v1_quizyaml_proto_rawDescGZIP() []byte {
+	file_proto_quizyaml_v1_quizyaml_proto_rawDescOnce.Do(func() {
+		file_proto_quizyaml_v1_quizyaml_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_proto_quizyaml_v1_quizyaml_proto_rawDesc), len(file_proto_quizyaml_v1_quizyaml_proto_rawDesc)))
+	})
+	return file_proto_quizyaml_v1_quizyaml_proto_rawDescData
+}
+
+var file_proto_quizyaml_v1_quizyaml_proto_msgTypes = make([]protoimpl.MessageInfo, 8)
+var file_proto_quizyaml_v1_quizyaml_proto_goTypes = []any{
+	(*ValidateRequest)(nil),     // 0: quizyaml.v1.ValidateRequest
+	(*ValidationError)(nil),     // 1: quizyaml.v1.ValidationError
+	(*ValidateResponse)(nil),    // 2: quizyaml.v1.ValidateResponse
+	(*ConvertRequest)(nil),      // 3: quizyaml.v1.ConvertRequest
+	(*ConvertResponse)(nil),     // 4: quizyaml.v1.ConvertResponse
+	(*ListFormatsRequest)(nil),  // 5: quizyaml.v1.ListFormatsRequest
+	(*ListFormatsResponse)(nil), // 6: quizyaml.v1.ListFormatsResponse
+	nil,                         // 7: quizyaml.v1.ConvertRequest.OptionsEntry
+}
+var file_proto_quizyaml_v1_quizyaml_proto_depIdxs = []int32{
+	1, // 0: quizyaml.v1.ValidateResponse.errors:type_name -> quizyaml.v1.ValidationError
+	7, // 1: quizyaml.v1.ConvertRequest.options:type_name -> quizyaml.v1.ConvertRequest.OptionsEntry
+	0, // 2: quizyaml.v1.QuizConverter.Validate:input_type -> quizyaml.v1.ValidateRequest
+	3, // 3: quizyaml.v1.QuizConverter.Convert:input_type -> quizyaml.v1.ConvertRequest
+	5, // 4: quizyaml.v1.QuizConverter.ListFormats:input_type -> quizyaml.v1.ListFormatsRequest
+	2, // 5: quizyaml.v1.QuizConverter.Validate:output_type -> quizyaml.v1.ValidateResponse
+	4, // 6: quizyaml.v1.QuizConverter.Convert:output_type -> quizyaml.v1.ConvertResponse
+	6, // 7: quizyaml.v1.QuizConverter.ListFormats:output_type -> quizyaml.v1.ListFormatsResponse
+	5, // [5:8] is the sub-list for method output_type
+	2, // [2:5] is the sub-list for method input_type
+	2, // [2:2] is the sub-list for extension type_name
+	2, // [2:2] is the sub-list for extension extendee
+	0, // [0:2] is the sub-list for field type_name
+}
+
+func init() { file_proto_quizyaml_v1_quizyaml_proto_init() }
+func file_proto_quizyaml_v1_quizyaml_proto_init() {
+	if File_proto_quizyaml_v1_quizyaml_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proto_quizyaml_v1_quizyaml_proto_rawDesc), len(file_proto_quizyaml_v1_quizyaml_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   8,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_proto_quizyaml_v1_quizyaml_proto_goTypes,
+		DependencyIndexes: file_proto_quizyaml_v1_quizyaml_proto_depIdxs,
+		MessageInfos:      file_proto_quizyaml_v1_quizyaml_proto_msgTypes,
+	}.Build()
+	File_proto_quizyaml_v1_quizyaml_proto = out.File
+	file_proto_quizyaml_v1_quizyaml_proto_goTypes = nil
+	file_proto_quizyaml_v1_quizyaml_proto_depIdxs = nil
+}
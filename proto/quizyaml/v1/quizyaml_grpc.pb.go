@@ -0,0 +1,218 @@
+// クイズYAML変換ツールのgRPCサービス定義．
+// converter grpcで起動したサーバーに対して，変換とバリデーションを呼び出せます．
+//
+// コードの生成:
+//
+//   protoc --go_out=. --go_opt=paths=source_relative \
+//     --go-grpc_out=. --go-grpc_opt=paths=source_relative \
+//     proto/quizyaml/v1/quizyaml.proto
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             v5.29.3
+// source: proto/quizyaml/v1/quizyaml.proto
+
+package quizyamlv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	QuizConverter_Validate_FullMethodName    = "/quizyaml.v1.QuizConverter/Validate"
+	QuizConverter_Convert_FullMethodName     = "/quizyaml.v1.QuizConverter/Convert"
+	QuizConverter_ListFormats_FullMethodName = "/quizyaml.v1.QuizConverter/ListFormats"
+)
+
+// QuizConverterClient is the client API for QuizConverter service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// QuizConverter はクイズYAMLの変換とバリデーションを提供するサービス．
+type QuizConverterClient interface {
+	// YAMLをバリデーションする．バリデーションに失敗した場合もエラーにはならず，
+	// validがfalseになる．
+	Validate(ctx context.Context, in *ValidateRequest, opts ...grpc.CallOption) (*ValidateResponse, error)
+	// YAMLを指定した出力形式に変換する．
+	Convert(ctx context.Context, in *ConvertRequest, opts ...grpc.CallOption) (*ConvertResponse, error)
+	// 使用できる出力形式の一覧を返す．
+	ListFormats(ctx context.Context, in *ListFormatsRequest, opts ...grpc.CallOption) (*ListFormatsResponse, error)
+}
+
+type quizConverterClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewQuizConverterClient(cc grpc.ClientConnInterface) QuizConverterClient {
+	return &quizConverterClient{cc}
+}
+
+func (c *quizConverterClient) Validate(ctx context.Context, in *ValidateRequest, opts ...grpc.CallOption) (*ValidateResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ValidateResponse)
+	err := c.cc.Invoke(ctx, QuizConverter_Validate_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *quizConverterClient) Convert(ctx context.Context, in *ConvertRequest, opts ...grpc.CallOption) (*ConvertResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ConvertResponse)
+	err := c.cc.Invoke(ctx, QuizConverter_Convert_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *quizConverterClient) ListFormats(ctx context.Context, in *ListFormatsRequest, opts ...grpc.CallOption) (*ListFormatsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListFormatsResponse)
+	err := c.cc.Invoke(ctx, QuizConverter_ListFormats_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// QuizConverterServer is the server API for QuizConverter service.
+// All implementations must embed UnimplementedQuizConverterServer
+// for forward compatibility.
+//
+// QuizConverter はクイズYAMLの変換とバリデーションを提供するサービス．
+type QuizConverterServer interface {
+	// YAMLをバリデーションする．バリデーションに失敗した場合もエラーにはならず，
+	// validがfalseになる．
+	Validate(context.Context, *ValidateRequest) (*ValidateResponse, error)
+	// YAMLを指定した出力形式に変換する．
+	Convert(context.Context, *ConvertRequest) (*ConvertResponse, error)
+	// 使用できる出力形式の一覧を返す．
+	ListFormats(context.Context, *ListFormatsRequest) (*ListFormatsResponse, error)
+	mustEmbedUnimplementedQuizConverterServer()
+}
+
+// UnimplementedQuizConverterServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedQuizConverterServer struct{}
+
+func (UnimplementedQuizConverterServer) Validate(context.Context, *ValidateRequest) (*ValidateResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Validate not implemented")
+}
+func (UnimplementedQuizConverterServer) Convert(context.Context, *ConvertRequest) (*ConvertResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Convert not implemented")
+}
+func (UnimplementedQuizConverterServer) ListFormats(context.Context, *ListFormatsRequest) (*ListFormatsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListFormats not implemented")
+}
+func (UnimplementedQuizConverterServer) mustEmbedUnimplementedQuizConverterServer() {}
+func (UnimplementedQuizConverterServer) testEmbeddedByValue()                       {}
+
+// UnsafeQuizConverterServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to QuizConverterServer will
+// result in compilation errors.
+type UnsafeQuizConverterServer interface {
+	mustEmbedUnimplementedQuizConverterServer()
+}
+
+func RegisterQuizConverterServer(s grpc.ServiceRegistrar, srv QuizConverterServer) {
+	// If the following call pancis, it indicates UnimplementedQuizConverterServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&QuizConverter_ServiceDesc, srv)
+}
+
+func _QuizConverter_Validate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ValidateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(QuizConverterServer).Validate(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: QuizConverter_Validate_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(QuizConverterServer).Validate(ctx, req.(*ValidateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _QuizConverter_Convert_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ConvertRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(QuizConverterServer).Convert(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: QuizConverter_Convert_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(QuizConverterServer).Convert(ctx, req.(*ConvertRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _QuizConverter_ListFormats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListFormatsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(QuizConverterServer).ListFormats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: QuizConverter_ListFormats_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(QuizConverterServer).ListFormats(ctx, req.(*ListFormatsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// QuizConverter_ServiceDesc is the grpc.ServiceDesc for QuizConverter service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var QuizConverter_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "quizyaml.v1.QuizConverter",
+	HandlerType: (*QuizConverterServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Validate",
+			Handler:    _QuizConverter_Validate_Handler,
+		},
+		{
+			MethodName: "Convert",
+			Handler:    _QuizConverter_Convert_Handler,
+		},
+		{
+			MethodName: "ListFormats",
+			Handler:    _QuizConverter_ListFormats_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "proto/quizyaml/v1/quizyaml.proto",
+}
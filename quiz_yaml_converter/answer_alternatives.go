@@ -0,0 +1,127 @@
+package quiz_yaml_converter
+
+import (
+	"slices"
+	"strings"
+)
+
+// 答えの括弧書きの開き括弧と閉じ括弧の対応（全角・半角）
+var answerParens = map[rune]rune{'（': '）', '(': ')'}
+
+// 括弧内で複数の別解を区切る文字
+const answerAlternativeSeparators = "／、，,"
+
+// SplitAnswer は答えの末尾の括弧書きを別解として取り出す．
+// 「アルテ・マイスター絵画館（古典絵画館）」は主要な答え「アルテ・マイスター絵画館」と
+// 別解「古典絵画館」に分け，括弧内が／や、で区切られている場合は複数の別解とする．
+// 括弧がない場合や，括弧の使い方が曖昧な場合（AnswerAlternativeWarnings参照）は
+// 答えをそのまま返し，別解はnilとなる．
+func SplitAnswer(answer string) (primary string, alternates []string) {
+	primary, alternates, _ = splitAnswer(answer)
+	return primary, alternates
+}
+
+// splitAnswer はSplitAnswerの処理を行い，分割しなかった理由となる警告の書式もあわせて返す．
+func splitAnswer(answer string) (primary string, alternates []string, warning string) {
+	answer = strings.TrimSpace(answer)
+	runes := []rune(answer)
+
+	// 括弧の対応と，トップレベルの括弧書きの位置を調べる
+	type group struct{ open, close int }
+	var groups []group
+	var stack []int
+	nested := false
+	for i, r := range runes {
+		if _, ok := answerParens[r]; ok {
+			if len(stack) > 0 {
+				nested = true
+			}
+			stack = append(stack, i)
+			continue
+		}
+		if !isClosingParen(r) {
+			continue
+		}
+		if len(stack) == 0 || answerParens[runes[stack[len(stack)-1]]] != r {
+			return answer, nil, "答え (answer) の括弧の対応が取れていません"
+		}
+		open := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if len(stack) == 0 {
+			groups = append(groups, group{open, i})
+		}
+	}
+
+	switch {
+	case len(stack) > 0:
+		return answer, nil, "答え (answer) の括弧の対応が取れていません"
+	case len(groups) == 0:
+		return answer, nil, ""
+	case len(groups) > 1:
+		return answer, nil, "答え (answer) に括弧書きが複数あります"
+	case nested:
+		return answer, nil, "答え (answer) の括弧書きが入れ子になっています"
+	}
+
+	g := groups[0]
+	if g.close != len(runes)-1 {
+		return answer, nil, "答え (answer) の括弧書きが末尾にありません"
+	}
+	primary = strings.TrimSpace(string(runes[:g.open]))
+	if primary == "" {
+		return answer, nil, "答え (answer) 全体が括弧で囲まれています"
+	}
+	inner := string(runes[g.open+1 : g.close])
+	for _, alt := range strings.FieldsFunc(inner, func(r rune) bool {
+		return strings.ContainsRune(answerAlternativeSeparators, r)
+	}) {
+		if alt = strings.TrimSpace(alt); alt != "" {
+			alternates = append(alternates, alt)
+		}
+	}
+	if len(alternates) == 0 {
+		return answer, nil, "答え (answer) の括弧の中が空です"
+	}
+	return primary, alternates, ""
+}
+
+// isClosingParen はrがanswerParensの閉じ括弧かを返す．
+func isClosingParen(r rune) bool {
+	for _, c := range answerParens {
+		if r == c {
+			return true
+		}
+	}
+	return false
+}
+
+// SplitAnswerAlternatives は答えの末尾の括弧書きを別解としてcriteria.okに移すStage．
+// 答えは括弧書きを除いた主要な答えに置き換わる．既にcriteria.okにある別解は追加しない．
+var SplitAnswerAlternatives Stage = EachItem(func(_ int, item *QuizItem) (bool, error) {
+	primary, alternates := SplitAnswer(item.Answer)
+	if len(alternates) == 0 {
+		return true, nil
+	}
+	item.Answer = primary
+	if item.Criteria == nil {
+		item.Criteria = map[string][]string{}
+	}
+	for _, alt := range alternates {
+		if alt != primary && !slices.Contains(item.Criteria["ok"], alt) {
+			item.Criteria["ok"] = append(item.Criteria["ok"], alt)
+		}
+	}
+	return true, nil
+})
+
+// AnswerAlternativeWarnings はSplitAnswerAlternativesで答えを分割できない，
+// 曖昧な括弧書きを含む問題についての警告を返す．
+func AnswerAlternativeWarnings(data []QuizItem) []ValidationError {
+	var warnings []ValidationError
+	for i, item := range data {
+		if _, _, warning := splitAnswer(item.Answer); warning != "" {
+			warnings = append(warnings, ValidationError{Index: i + 1, Field: "answer", Rule: RuleAnswerParens, Message: warning})
+		}
+	}
+	return warnings
+}
@@ -0,0 +1,81 @@
+package quiz_yaml_converter
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitAnswer(t *testing.T) {
+	tests := []struct {
+		answer     string
+		primary    string
+		alternates []string
+		warning    bool
+	}{
+		{"アルテ・マイスター絵画館（古典絵画館）", "アルテ・マイスター絵画館", []string{"古典絵画館"}, false},
+		{"富士山", "富士山", nil, false},
+		{"ポケミス（HPB／ハヤカワ・ミステリ）", "ポケミス", []string{"HPB", "ハヤカワ・ミステリ"}, false},
+		{"国際連合 (国連、UN)", "国際連合", []string{"国連", "UN"}, false},
+		{"A（B）C", "A（B）C", nil, true},
+		{"A（B）（C）", "A（B）（C）", nil, true},
+		{"A（B（C））", "A（B（C））", nil, true},
+		{"A（B", "A（B", nil, true},
+		{"A）", "A）", nil, true},
+		{"A（B)", "A（B)", nil, true},
+		{"（A）", "（A）", nil, true},
+		{"A（ ）", "A（ ）", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.answer, func(t *testing.T) {
+			primary, alternates, warning := splitAnswer(tt.answer)
+			if primary != tt.primary || !reflect.DeepEqual(alternates, tt.alternates) {
+				t.Errorf("splitAnswer(%q) = %q, %q, want %q, %q", tt.answer, primary, alternates, tt.primary, tt.alternates)
+			}
+			if (warning != "") != tt.warning {
+				t.Errorf("splitAnswer(%q) warning = %q, want warning %v", tt.answer, warning, tt.warning)
+			}
+		})
+	}
+}
+
+func TestSplitAnswerAlternatives(t *testing.T) {
+	data := []QuizItem{
+		{Question: "q1", Answer: "アルテ・マイスター絵画館（古典絵画館）"},
+		{Question: "q2", Answer: "ポケミス（HPB／ポケミス）", Criteria: map[string][]string{"ok": {"HPB"}, "ng": {"ハヤカワ"}}},
+		{Question: "q3", Answer: "A（B）C"},
+	}
+	result, err := Pipeline{SplitAnswerAlternatives}.Apply(data)
+	if err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+
+	expected := []QuizItem{
+		{Question: "q1", Answer: "アルテ・マイスター絵画館", Criteria: map[string][]string{"ok": {"古典絵画館"}}},
+		{Question: "q2", Answer: "ポケミス", Criteria: map[string][]string{"ok": {"HPB"}, "ng": {"ハヤカワ"}}},
+		{Question: "q3", Answer: "A（B）C"},
+	}
+	for i := range expected {
+		if result[i].Answer != expected[i].Answer || !reflect.DeepEqual(result[i].Criteria, expected[i].Criteria) {
+			t.Errorf("item %d = %+v, want %+v", i+1, result[i], expected[i])
+		}
+	}
+	if data[0].Answer != "アルテ・マイスター絵画館（古典絵画館）" {
+		t.Errorf("Apply() modified input: %q", data[0].Answer)
+	}
+}
+
+func TestAnswerAlternativeWarnings(t *testing.T) {
+	data := []QuizItem{
+		{Answer: "アルテ・マイスター絵画館（古典絵画館）"},
+		{Answer: "A（B）C"},
+		{Answer: "A（B"},
+	}
+	warnings := AnswerAlternativeWarnings(data)
+	if len(warnings) != 2 || warnings[0].Index != 2 || warnings[1].Index != 3 {
+		t.Fatalf("AnswerAlternativeWarnings() = %+v, want warnings for items 2 and 3", warnings)
+	}
+	if got := warnings[1].LocalizedError(LanguageEnglish); got != "item 3: answer has unbalanced parentheses" {
+		t.Errorf("LocalizedError() = %q", got)
+	}
+}
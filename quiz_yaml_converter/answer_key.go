@@ -0,0 +1,81 @@
+// 問題だけを載せた問題用紙と，答えを載せた解答の2つのHTMLを1度に出力する処理です．
+// ほとんどの大会で必要になる組を，組み込みのレイアウトから同じ問題・同じ番号で作ります．
+package quiz_yaml_converter
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+)
+
+// SheetPair は問題用紙と解答の組で，出力するファイルから互いのファイルへのリンク（相対パス）．
+// 問題用紙のテンプレートでは.Pair.AnswerKey，解答のテンプレートでは.Pair.QuestionSheetでもう一方を参照できる．
+type SheetPair struct {
+	QuestionSheet string // 問題用紙のファイルへのリンク
+	AnswerKey     string // 解答のファイルへのリンク
+}
+
+// ConvertToAnswerKeyPair は問題データを，答えを載せない問題用紙（questionSheetPath）と，
+// 答え・原語表記・判定・出典を載せた解答（answerKeyPath）の2つのHTMLに組み込みのレイアウトで書き出す．
+// 2つのファイルは互いにリンクし，各問題の番号からもう一方の同じ問題に移動できる．
+// opts.Pipelineは1度だけ適用するため，2つのファイルの問題と番号は常に一致する．
+func ConvertToAnswerKeyPair(data []QuizItem, questionSheetPath, answerKeyPath string, opts ConvertOptions) error {
+	if samePath(questionSheetPath, answerKeyPath) {
+		return fmt.Errorf("the question sheet and the answer key must be different files: %s", answerKeyPath)
+	}
+	questionTmpl, err := parseLayout("layouts/questions.html", opts)
+	if err != nil {
+		return err
+	}
+	answerKeyTmpl, err := parseLayout("layouts/answer_key.html", opts)
+	if err != nil {
+		return err
+	}
+
+	if data, err = opts.Pipeline.Apply(data); err != nil {
+		return err
+	}
+	data = withAssignedIDs(data, opts)
+	td := TemplateData{Items: data, Numbers: QuestionNumbers(data, opts.Numbering), Vars: opts.Vars}
+
+	td.Pair = sheetPair(questionSheetPath, questionSheetPath, answerKeyPath)
+	if err := writeTemplateFile(questionSheetPath, questionTmpl, td, opts); err != nil {
+		return err
+	}
+	td.Pair = sheetPair(answerKeyPath, questionSheetPath, answerKeyPath)
+	return writeTemplateFile(answerKeyPath, answerKeyTmpl, td, opts)
+}
+
+// sheetPair はfromのファイルから見た問題用紙と解答へのリンクを返す．
+func sheetPair(from, questionSheetPath, answerKeyPath string) *SheetPair {
+	return &SheetPair{
+		QuestionSheet: relativeLink(from, questionSheetPath),
+		AnswerKey:     relativeLink(from, answerKeyPath),
+	}
+}
+
+// relativeLink はfromのファイルからtoのファイルへのリンク（/区切りの相対パスをURLとしてエスケープしたもの）を返す．
+// 相対パスにできない場合（Windowsで別のドライブにある場合など）はtoの絶対パスを使う．
+func relativeLink(from, to string) string {
+	link := to
+	absFrom, errFrom := filepath.Abs(from)
+	absTo, errTo := filepath.Abs(to)
+	if errFrom == nil && errTo == nil {
+		link = absTo
+		if rel, err := filepath.Rel(filepath.Dir(absFrom), absTo); err == nil {
+			link = rel
+		}
+	}
+	return (&url.URL{Path: filepath.ToSlash(link)}).String()
+}
+
+// samePath は2つのパスが同じファイルを指すかを返す．
+func samePath(a, b string) bool {
+	if filepath.Clean(a) == filepath.Clean(b) {
+		return true
+	}
+	infoA, errA := os.Stat(a)
+	infoB, errB := os.Stat(b)
+	return errA == nil && errB == nil && os.SameFile(infoA, infoB)
+}
@@ -0,0 +1,92 @@
+package quiz_yaml_converter
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestConvertToAnswerKeyPair(t *testing.T) {
+	dir := t.TempDir()
+	questionSheet := filepath.Join(dir, "packet.html")
+	answerKey := filepath.Join(dir, "key", "answers.html")
+	if err := os.Mkdir(filepath.Dir(answerKey), 0755); err != nil {
+		t.Fatal(err)
+	}
+	data := []QuizItem{
+		{Question: "日本の首都は／どこ？", Answer: "東京", Spell: "Tokyo", Criteria: map[string][]string{"ok": {"東京都"}}},
+		{Question: "江戸幕府を開いたのは？", Answer: "徳川家康", Genre: "歴史"},
+		{Question: "赤い惑星は？", Answer: "火星", Choices: []string{"金星", "火星", "木星"}},
+	}
+	opts := ConvertOptions{
+		Vars:     map[string]string{"title": "第1回大会"},
+		Pipeline: Pipeline{Filter(func(item QuizItem) bool { return item.Genre != "歴史" })},
+	}
+	if err := ConvertToAnswerKeyPair(data, questionSheet, answerKey, opts); err != nil {
+		t.Fatalf("ConvertToAnswerKeyPair() error = %v", err)
+	}
+
+	tests := []struct {
+		path          string
+		want, notWant []string
+	}{
+		{
+			questionSheet,
+			[]string{"第1回大会（問題）", `href="key/answers.html"`, `href="key/answers.html#q2"`, "日本の首都はどこ？", "<li>木星</li>"},
+			[]string{"東京", "Tokyo", "火星</div>", "江戸幕府", "／"},
+		},
+		{
+			answerKey,
+			[]string{"第1回大会（解答）", `href="../packet.html"`, `href="../packet.html#q1"`, `id="q2"`, "東京", "Tokyo", "東京都", "火星"},
+			[]string{"江戸幕府"},
+		},
+	}
+	for _, tt := range tests {
+		content, err := os.ReadFile(tt.path)
+		if err != nil {
+			t.Fatalf("Failed to read %s: %v", tt.path, err)
+		}
+		for _, want := range tt.want {
+			if !strings.Contains(string(content), want) {
+				t.Errorf("%s does not contain %q", filepath.Base(tt.path), want)
+			}
+		}
+		for _, notWant := range tt.notWant {
+			if strings.Contains(string(content), notWant) {
+				t.Errorf("%s should not contain %q", filepath.Base(tt.path), notWant)
+			}
+		}
+	}
+}
+
+func TestConvertToAnswerKeyPair_Errors(t *testing.T) {
+	dir := t.TempDir()
+	data := []QuizItem{{Question: "問題", Answer: "答え"}}
+	path := filepath.Join(dir, "packet.html")
+	if err := ConvertToAnswerKeyPair(data, path, filepath.Join(dir, ".", "packet.html"), ConvertOptions{}); err == nil {
+		t.Error("ConvertToAnswerKeyPair() should fail when both outputs are the same file")
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("ConvertToAnswerKeyPair() wrote %s on error", path)
+	}
+	if err := ConvertToAnswerKeyPair(data, path, filepath.Join(dir, "missing", "answers.html"), ConvertOptions{}); err == nil {
+		t.Error("ConvertToAnswerKeyPair() should fail when the answer key cannot be written")
+	}
+}
+
+func TestRelativeLink(t *testing.T) {
+	tests := []struct {
+		from, to, want string
+	}{
+		{"out/packet.html", "out/answers.html", "answers.html"},
+		{"out/packet.html", "out/key/answers.html", "key/answers.html"},
+		{"out/key/answers.html", "out/packet.html", "../packet.html"},
+		{"問題 用紙.html", "解答 #1.html", "%E8%A7%A3%E7%AD%94%20%231.html"},
+	}
+	for _, tt := range tests {
+		if got := relativeLink(filepath.FromSlash(tt.from), filepath.FromSlash(tt.to)); got != tt.want {
+			t.Errorf("relativeLink(%q, %q) = %q, want %q", tt.from, tt.to, got, tt.want)
+		}
+	}
+}
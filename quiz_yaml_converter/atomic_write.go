@@ -0,0 +1,62 @@
+// 出力ファイルを安全に書き出すための処理です．
+// 一時ファイルに書き出してからリネームすることで，変換の途中でエラーが
+// 発生しても既存の出力ファイルを壊さないようにします．
+package quiz_yaml_converter
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// 新規作成する出力ファイルのパーミッション
+const defaultOutputFileMode fs.FileMode = 0o644
+
+// writeFileAtomic はwriteが書き出した内容でpathを置き換える．
+// 内容は同じディレクトリの一時ファイルに書き出してからリネームするため，
+// writeがエラーを返した場合は既存のファイルはそのまま残る．
+// noClobberがtrueでpathが既に存在する場合はErrOutputExistsを返す．
+func writeFileAtomic(path string, noClobber bool, write func(w io.Writer) error) (err error) {
+	mode := defaultOutputFileMode
+	info, statErr := os.Stat(path)
+	switch {
+	case statErr == nil:
+		if noClobber {
+			return fmt.Errorf("%w: %s", ErrOutputExists, path)
+		}
+		if info.IsDir() {
+			return fmt.Errorf("output path is a directory: %s", path)
+		}
+		mode = info.Mode().Perm()
+	case !errors.Is(statErr, fs.ErrNotExist):
+		return fmt.Errorf("failed to stat output file: %w", statErr)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to create output file: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			tmp.Close()
+			os.Remove(tmp.Name())
+		}
+	}()
+
+	if err := write(tmp); err != nil {
+		return err
+	}
+	if err := tmp.Chmod(mode); err != nil {
+		return fmt.Errorf("failed to set output file mode: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to close output file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to replace output file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+package quiz_yaml_converter
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteFileAtomic(t *testing.T) {
+	tempDir := t.TempDir()
+	path := filepath.Join(tempDir, "out.txt")
+
+	err := writeFileAtomic(path, false, func(w io.Writer) error {
+		_, err := io.WriteString(w, "new content")
+		return err
+	})
+	if err != nil {
+		t.Fatalf("writeFileAtomic() error = %v", err)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read output file: %v", err)
+	}
+	if string(content) != "new content" {
+		t.Errorf("content = %q, want %q", string(content), "new content")
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("Failed to stat output file: %v", err)
+	}
+	if info.Mode().Perm() != defaultOutputFileMode {
+		t.Errorf("mode = %v, want %v", info.Mode().Perm(), defaultOutputFileMode)
+	}
+}
+
+func TestWriteFileAtomic_KeepsExistingFileOnError(t *testing.T) {
+	tempDir := t.TempDir()
+	path := filepath.Join(tempDir, "out.txt")
+	if err := os.WriteFile(path, []byte("old content"), 0644); err != nil {
+		t.Fatalf("Failed to create existing file: %v", err)
+	}
+
+	writeErr := errors.New("write failed")
+	err := writeFileAtomic(path, false, func(w io.Writer) error {
+		io.WriteString(w, "partial")
+		return writeErr
+	})
+	if !errors.Is(err, writeErr) {
+		t.Fatalf("writeFileAtomic() error = %v, want %v", err, writeErr)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read output file: %v", err)
+	}
+	if string(content) != "old content" {
+		t.Errorf("content = %q, want existing content to be kept", string(content))
+	}
+	entries, err := os.ReadDir(tempDir)
+	if err != nil {
+		t.Fatalf("Failed to read temp dir: %v", err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("temporary file was not removed: %v", entries)
+	}
+}
+
+func TestWriteFileAtomic_NoClobber(t *testing.T) {
+	tempDir := t.TempDir()
+	path := filepath.Join(tempDir, "out.txt")
+	if err := os.WriteFile(path, []byte("old content"), 0644); err != nil {
+		t.Fatalf("Failed to create existing file: %v", err)
+	}
+
+	err := writeFileAtomic(path, true, func(w io.Writer) error {
+		_, err := io.WriteString(w, "new content")
+		return err
+	})
+	if !errors.Is(err, ErrOutputExists) {
+		t.Errorf("writeFileAtomic() error = %v, want ErrOutputExists", err)
+	}
+}
+
+func TestConvertToTemplate_TemplateErrorKeepsOutput(t *testing.T) {
+	tempDir := t.TempDir()
+	templateFile := filepath.Join(tempDir, "template.txt")
+	outputFile := filepath.Join(tempDir, "output.txt")
+	if err := os.WriteFile(templateFile, []byte(`{{range .Items}}{{.Unknown}}{{end}}`), 0644); err != nil {
+		t.Fatalf("Failed to create template file: %v", err)
+	}
+	if err := os.WriteFile(outputFile, []byte("previous output"), 0644); err != nil {
+		t.Fatalf("Failed to create output file: %v", err)
+	}
+
+	err := ConvertToTemplate([]QuizItem{{Question: "q", Answer: "a"}}, templateFile, outputFile)
+	if err == nil {
+		t.Fatal("Expected error, but got nil")
+	}
+
+	content, err := os.ReadFile(outputFile)
+	if err != nil {
+		t.Fatalf("Failed to read output file: %v", err)
+	}
+	if string(content) != "previous output" {
+		t.Errorf("output = %q, want previous output to be kept", string(content))
+	}
+}
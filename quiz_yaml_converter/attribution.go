@@ -0,0 +1,67 @@
+// 問題の作者とライセンス（クレジット表記）を扱う処理です．
+package quiz_yaml_converter
+
+import "strings"
+
+// YAMLのジャンル名をキーとしたマッピングで，ファイルのメタデータを書くキー
+const metadataKey = "metadata"
+
+// FileMetadata はYAMLファイルのmetadataキーに書く，ファイル内の問題に共通する情報．
+// 問題集を共有するときに作者とライセンスを1か所に書けるようにするためのもので，
+// 以降の問題のうちauthor，licenseが未設定のものに同じ値が設定される．
+//
+//	metadata:
+//	  author: 山田太郎
+//	  license: CC BY 4.0
+//	歴史:
+//	  - question: ...
+//
+// 問題のリストの形式のファイルでは，metadataだけのドキュメントを先頭に書き，---で区切る．
+type FileMetadata struct {
+	Version int    `yaml:"version,omitempty" json:"version,omitempty"` // ファイルの形式のバージョン（CurrentSchemaVersion参照）
+	Author  string `yaml:"author,omitempty" json:"author,omitempty"`   // 問題の作者
+	License string `yaml:"license,omitempty" json:"license,omitempty"` // 問題のライセンス
+}
+
+// apply はauthor，licenseが未設定の問題にメタデータの値を設定する．
+func (m *FileMetadata) apply(item *QuizItem) {
+	if m == nil {
+		return
+	}
+	if item.Author == "" {
+		item.Author = m.Author
+	}
+	if item.License == "" {
+		item.License = m.License
+	}
+}
+
+// CreditEntry はクレジット表記の1項目．作者とライセンスが同じ問題をまとめたもの．
+type CreditEntry struct {
+	Author  string   // 問題の作者
+	License string   // 問題のライセンス
+	Numbers []string // この作者・ライセンスの問題の番号（出力順）
+}
+
+// Credits は問題の作者とライセンスを，その組ごとに初めて現れた順にまとめたクレジット表記の一覧を返す．
+// 作者もライセンスも書かれていない問題は含まない．テンプレートでは{{range .Credits}}のように使う．
+func (td TemplateData) Credits() []CreditEntry {
+	var entries []CreditEntry
+	positions := map[[2]string]int{}
+	for i, item := range td.Items {
+		key := [2]string{strings.TrimSpace(item.Author), strings.TrimSpace(item.License)}
+		if key == [2]string{} {
+			continue
+		}
+		pos, ok := positions[key]
+		if !ok {
+			pos = len(entries)
+			positions[key] = pos
+			entries = append(entries, CreditEntry{Author: key[0], License: key[1]})
+		}
+		if i < len(td.Numbers) {
+			entries[pos].Numbers = append(entries[pos].Numbers, td.Numbers[i])
+		}
+	}
+	return entries
+}
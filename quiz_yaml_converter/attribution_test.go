@@ -0,0 +1,115 @@
+package quiz_yaml_converter
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseYAMLData_Metadata(t *testing.T) {
+	tests := []struct {
+		name     string
+		yaml     string
+		expected []QuizItem
+	}{
+		{
+			"genres",
+			"metadata:\n  author: 山田\n  license: CC BY 4.0\n歴史:\n  - question: q1\n    answer: a1\n  - question: q2\n    answer: a2\n    author: 佐藤\n",
+			[]QuizItem{
+				{Question: "q1", Answer: "a1", Genre: "歴史", Author: "山田", License: "CC BY 4.0"},
+				{Question: "q2", Answer: "a2", Genre: "歴史", Author: "佐藤", License: "CC BY 4.0"},
+			},
+		},
+		{
+			"separate document",
+			"metadata:\n  license: CC0\n---\n- question: q1\n  answer: a1\n",
+			[]QuizItem{{Question: "q1", Answer: "a1", License: "CC0"}},
+		},
+		{
+			"genre named metadata",
+			"metadata:\n  - question: q1\n    answer: a1\n",
+			[]QuizItem{{Question: "q1", Answer: "a1", Genre: "metadata"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ParseYAMLData([]byte(tt.yaml))
+			if err != nil {
+				t.Fatalf("ParseYAMLData() error = %v", err)
+			}
+			for i := range result {
+				result[i].Document = 0
+			}
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("ParseYAMLData() = %+v, want %+v", result, tt.expected)
+			}
+
+			var streamed []QuizItem
+			err = DecodeYAMLItems(strings.NewReader(tt.yaml), func(item QuizItem) error {
+				streamed = append(streamed, item)
+				return nil
+			})
+			if err != nil {
+				t.Fatalf("DecodeYAMLItems() error = %v", err)
+			}
+			if !reflect.DeepEqual(streamed, tt.expected) {
+				t.Errorf("DecodeYAMLItems() = %+v, want %+v", streamed, tt.expected)
+			}
+		})
+	}
+
+	if _, err := ParseYAMLData([]byte("metadata:\n  author: [a, b]\n")); err == nil {
+		t.Error("ParseYAMLData() with invalid metadata expected error")
+	}
+}
+
+func TestTemplateData_Credits(t *testing.T) {
+	td := TemplateData{
+		Items: []QuizItem{
+			{Question: "q1", Author: "山田", License: "CC BY 4.0"},
+			{Question: "q2"},
+			{Question: "q3", License: "CC0"},
+			{Question: "q4", Author: "山田 ", License: "CC BY 4.0"},
+		},
+		Numbers: []string{"1", "2", "3", "4"},
+	}
+	expected := []CreditEntry{
+		{Author: "山田", License: "CC BY 4.0", Numbers: []string{"1", "4"}},
+		{License: "CC0", Numbers: []string{"3"}},
+	}
+	if got := td.Credits(); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Credits() = %+v, want %+v", got, expected)
+	}
+}
+
+func TestBuiltinTemplates_Credits(t *testing.T) {
+	items := []QuizItem{
+		{Question: "q1", Answer: "a1", Author: "山田", License: "CC BY 4.0"},
+		{Question: "q2", Answer: "a2", License: "CC0"},
+	}
+	tests := []struct {
+		format   string
+		expected []string
+	}{
+		{"markdown", []string{"## Credits\n\n- Author: 山田, License: CC BY 4.0 (Q1)\n- License: CC0 (Q2)\n"}},
+		{"html", []string{"<li>作者: 山田，ライセンス: CC BY 4.0（Q1）</li>", "<li>ライセンス: CC0（Q2）</li>"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			f, err := LookupFormatter(tt.format)
+			if err != nil {
+				t.Fatalf("LookupFormatter() error = %v", err)
+			}
+			var buf bytes.Buffer
+			if err := f.Format(&buf, items, ConvertOptions{}); err != nil {
+				t.Fatalf("Format() error = %v", err)
+			}
+			for _, s := range tt.expected {
+				if !strings.Contains(buf.String(), s) {
+					t.Errorf("output does not contain %q:\n%s", s, buf.String())
+				}
+			}
+		})
+	}
+}
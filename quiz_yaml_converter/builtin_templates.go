@@ -0,0 +1,78 @@
+// 組み込みのテンプレート（-template builtin:名前）を扱う処理です．
+// テンプレートファイルを用意しなくても，よく使う形式のページをすぐに出力できるようにします．
+package quiz_yaml_converter
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/m-uesaka/quiz-yaml-go/templates"
+)
+
+// BuiltinTemplatePrefix は組み込みのテンプレートを使う場合のテンプレートファイルのパスの接頭辞．
+// "builtin:flashcards"のように指定する．
+const BuiltinTemplatePrefix = "builtin:"
+
+// builtinTemplate は組み込みのテンプレートの内容と，出力するファイルの拡張子．
+type builtinTemplate struct {
+	text string
+	ext  string
+}
+
+// builtinTemplates は組み込みのテンプレートの一覧．
+var builtinTemplates = map[string]builtinTemplate{
+	"flashcards": {templates.Flashcards, ".html"},
+	"print":      {templates.Print, ".html"},
+	"vertical":   {templates.Vertical, ".html"},
+}
+
+// BuiltinTemplateNames は組み込みのテンプレートの名前を名前順に返す．
+func BuiltinTemplateNames() []string {
+	names := make([]string, 0, len(builtinTemplates))
+	for name := range builtinTemplates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// IsBuiltinTemplate はテンプレートファイルのパスが組み込みのテンプレートの指定かを返す．
+func IsBuiltinTemplate(templateFilePath string) bool {
+	return strings.HasPrefix(templateFilePath, BuiltinTemplatePrefix)
+}
+
+// BuiltinTemplateExt は組み込みのテンプレートで出力するファイルの拡張子を返す．
+// 組み込みのテンプレートの指定でない場合や，存在しない名前の場合はfalseを返す．
+func BuiltinTemplateExt(templateFilePath string) (string, bool) {
+	t, ok := lookupBuiltinTemplate(templateFilePath)
+	return t.ext, ok
+}
+
+// lookupBuiltinTemplate は"builtin:名前"の指定から組み込みのテンプレートを探す．
+func lookupBuiltinTemplate(templateFilePath string) (builtinTemplate, bool) {
+	if !IsBuiltinTemplate(templateFilePath) {
+		return builtinTemplate{}, false
+	}
+	t, ok := builtinTemplates[strings.TrimPrefix(templateFilePath, BuiltinTemplatePrefix)]
+	return t, ok
+}
+
+// readTemplateFile はテンプレートファイルを読み込む．
+// "builtin:名前"の場合はファイルの代わりに組み込みのテンプレートの内容を返す．
+func readTemplateFile(templateFilePath string) ([]byte, error) {
+	if IsBuiltinTemplate(templateFilePath) {
+		t, ok := lookupBuiltinTemplate(templateFilePath)
+		if !ok {
+			return nil, fmt.Errorf("%w: unknown builtin template %q (available: %s)", ErrUnsupportedFormat,
+				strings.TrimPrefix(templateFilePath, BuiltinTemplatePrefix), strings.Join(BuiltinTemplateNames(), ", "))
+		}
+		return []byte(t.text), nil
+	}
+	content, err := os.ReadFile(templateFilePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read template file: %w", err)
+	}
+	return content, nil
+}
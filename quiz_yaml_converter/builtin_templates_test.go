@@ -0,0 +1,118 @@
+package quiz_yaml_converter
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestBuiltinTemplates(t *testing.T) {
+	data := []QuizItem{
+		{Question: "日本の首都は／どこ？", Answer: "東京", Spell: "Tokyo", Criteria: map[string][]string{"ok": {"東京都"}}},
+		{Question: "赤い惑星は？", Answer: "火星", Choices: []string{"金星", "火星", "木星"}, Comments: []string{"太陽系の第4惑星"}},
+	}
+	tests := []struct {
+		name string
+		vars map[string]string
+		want []string
+	}{
+		{
+			"flashcards",
+			map[string]string{"title": "第1回大会"},
+			[]string{
+				"<title>第1回大会</title>",
+				`id="q1"`, "日本の首都はどこ？", `<div class="answer">東京</div>`, "Tokyo", "東京都",
+				`id="q2"`, "<li>木星</li>", "太陽系の第4惑星",
+				"2枚",
+			},
+		},
+		{
+			"print",
+			map[string]string{"title": `春の"大会"`, "date": "2026-04-01", "per_page": "3"},
+			[]string{
+				"size: A4;",
+				`@top-left { content: "春の\"大会\""`, `@top-right { content: "2026-04-01"`, `counter(page) " / " counter(pages)`,
+				".quiz-item:nth-of-type(3n)", "<h1>春の\"大会\"</h1>",
+				`id="q1"`, `<span class="pivot">／</span>どこ？`, "A. 東京", "Tokyo", "東京都", "太陽系の第4惑星",
+			},
+		},
+		{
+			"vertical",
+			map[string]string{"title": "第1回大会"},
+			[]string{
+				"writing-mode: vertical-rl", "<title>第1回大会</title>",
+				`id="q1"`, "問１", `<span class="pivot">／</span>どこ？`, "答　東京", "Tokyo", "「東京都」",
+				`id="q2"`, "※太陽系の第４惑星",
+			},
+		},
+		{
+			"print",
+			nil,
+			[]string{`@top-left { content: ""`, ".quiz-item:nth-of-type(5n)", "<h1>クイズ問題集</h1>"},
+		},
+	}
+	for _, tt := range tests {
+		output := filepath.Join(t.TempDir(), tt.name+".html")
+		// 組み込みのテンプレートは-template-delimsの指定に関係なく解析できる
+		opts := ConvertOptions{Vars: tt.vars, TemplateDelims: [2]string{"[[", "]]"}}
+		if err := ConvertItems(data, output, BuiltinTemplatePrefix+tt.name, opts); err != nil {
+			t.Fatalf("ConvertItems(%s) error = %v", tt.name, err)
+		}
+		content, err := os.ReadFile(output)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, want := range tt.want {
+			if !strings.Contains(string(content), want) {
+				t.Errorf("%s output does not contain %q", tt.name, want)
+			}
+		}
+	}
+}
+
+func TestBuiltinTemplate_Unknown(t *testing.T) {
+	_, err := CompileTemplate("builtin:missing", ConvertOptions{})
+	if !errors.Is(err, ErrUnsupportedFormat) {
+		t.Fatalf("CompileTemplate() error = %v, want ErrUnsupportedFormat", err)
+	}
+	if !strings.Contains(err.Error(), "flashcards") {
+		t.Errorf("CompileTemplate() error = %v, want the available names", err)
+	}
+}
+
+func TestBuiltinTemplateExt(t *testing.T) {
+	tests := []struct {
+		path   string
+		want   string
+		wantOK bool
+	}{
+		{"builtin:flashcards", ".html", true},
+		{"builtin:print", ".html", true},
+		{"builtin:vertical", ".html", true},
+		{"builtin:missing", "", false},
+		{"flashcards.html", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := BuiltinTemplateExt(tt.path)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("BuiltinTemplateExt(%q) = %q, %v, want %q, %v", tt.path, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestTemplateCache_Builtin(t *testing.T) {
+	cache := NewTemplateCache(ConvertOptions{})
+	first, err := cache.Get("builtin:flashcards")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	second, err := cache.Get("builtin:flashcards")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if first != second {
+		t.Error("Get() parsed the builtin template again")
+	}
+}
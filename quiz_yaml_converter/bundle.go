@@ -0,0 +1,220 @@
+// 変換結果と，変換結果から参照している画像・音声ファイルを1つのzipファイル（バンドル）にまとめる処理です．
+// 大会のスタッフなどに，そのまま開ける問題集を1ファイルで渡すために使います．
+package quiz_yaml_converter
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// DefaultBundleMediaDir はバンドル内でメディアファイルを置く既定のディレクトリ．
+const DefaultBundleMediaDir = "media"
+
+// mediaExtensions はバンドルに含める画像・音声・動画ファイルの拡張子．
+var mediaExtensions = map[string]bool{
+	".png": true, ".jpg": true, ".jpeg": true, ".gif": true, ".svg": true, ".webp": true, ".bmp": true,
+	".mp3": true, ".wav": true, ".ogg": true, ".oga": true, ".m4a": true, ".aac": true, ".flac": true,
+	".mp4": true, ".webm": true,
+}
+
+// mediaReferencePattern はHTMLの属性（src, href, poster）とMarkdownの画像（![説明](パス)）による参照に一致する．
+// 参照の前後と参照そのものをサブマッチとして取り出す．
+var mediaReferencePattern = regexp.MustCompile(`(\b(?:src|href|poster)\s*=\s*")([^"]+)(")|(\b(?:src|href|poster)\s*=\s*')([^']+)(')|(!\[[^\]]*\]\()([^)\s]+)()`)
+
+// BundleOptions はバンドルの作成に関するオプション．
+type BundleOptions struct {
+	Name     string // バンドル内の変換結果のファイル名（必須）
+	BaseDir  string // 相対パスの参照を解決するディレクトリ（空の場合はカレントディレクトリ）
+	MediaDir string // バンドル内でメディアファイルを置くディレクトリ（空の場合はDefaultBundleMediaDir）
+}
+
+// BundledFile はバンドルに含めたメディアファイル．
+type BundledFile struct {
+	Reference string // 変換結果に書かれていた参照
+	Source    string // 読み込んだファイルのパス
+	Path      string // バンドル内のパス（書き換えた参照）
+}
+
+// mediaFilePath は参照がバンドルに含めるローカルのメディアファイルであればそのファイルパスを返す．
+// URL（http:やdata:など），ページ内のリンク，拡張子がメディアファイルでない参照は対象外とする．
+func mediaFilePath(ref string) (string, bool) {
+	u, err := url.Parse(ref)
+	if err != nil || u.Scheme != "" && !filepath.IsAbs(ref) || u.Host != "" || u.Path == "" {
+		return "", false
+	}
+	if !mediaExtensions[strings.ToLower(path.Ext(u.Path))] {
+		return "", false
+	}
+	return filepath.FromSlash(u.Path), true
+}
+
+// MediaReferences は変換結果の中で参照しているローカルの画像・音声・動画ファイルを，重複を除いて現れた順に返す．
+// HTMLのsrc・href・poster属性と，Markdownの画像（![説明](パス)）を対象とする．
+func MediaReferences(content []byte) []string {
+	var refs []string
+	seen := map[string]bool{}
+	for _, m := range mediaReferencePattern.FindAllSubmatch(content, -1) {
+		ref := string(bytes.Join([][]byte{m[2], m[5], m[8]}, nil))
+		if _, ok := mediaFilePath(ref); ok && !seen[ref] {
+			seen[ref] = true
+			refs = append(refs, ref)
+		}
+	}
+	return refs
+}
+
+// WriteBundle は変換結果contentと，contentから参照しているメディアファイル（MediaReferences参照）をzipファイルとしてwに書き出す．
+// メディアファイルはopts.MediaDirの下に置き，contentの参照をバンドル内のパス（URLとしてエスケープしたもの）に書き換える．
+// ファイル名が重なる場合は"-2"のような番号を付ける．参照しているファイルが見つからない場合はエラーを返す．
+func WriteBundle(w io.Writer, content []byte, opts BundleOptions) ([]BundledFile, error) {
+	if opts.Name == "" {
+		return nil, errors.New("bundle entry name is required")
+	}
+	mediaDir := opts.MediaDir
+	if mediaDir == "" {
+		mediaDir = DefaultBundleMediaDir
+	}
+
+	var files []BundledFile
+	paths := map[string]string{} // 読み込むファイルのパス → バンドル内のパス
+	used := map[string]bool{path.Clean(opts.Name): true}
+	rewritten := map[string]string{}
+	for _, ref := range MediaReferences(content) {
+		name, _ := mediaFilePath(ref)
+		source := name
+		if !filepath.IsAbs(source) {
+			source = filepath.Join(opts.BaseDir, source)
+		}
+		source = filepath.Clean(source)
+		info, err := os.Stat(source)
+		if err != nil {
+			return nil, fmt.Errorf("referenced media file not found: %s: %w", ref, err)
+		}
+		if !info.Mode().IsRegular() {
+			return nil, fmt.Errorf("referenced media file is not a regular file: %s", ref)
+		}
+		bundled, ok := paths[source]
+		if !ok {
+			bundled = uniqueBundlePath(mediaDir, filepath.Base(source), used)
+			paths[source] = bundled
+		}
+		rewritten[ref] = (&url.URL{Path: bundled}).String()
+		files = append(files, BundledFile{Reference: ref, Source: source, Path: bundled})
+	}
+
+	content = mediaReferencePattern.ReplaceAllFunc(content, func(match []byte) []byte {
+		m := mediaReferencePattern.FindSubmatch(match)
+		for i := 1; i < len(m); i += 3 {
+			if bundled, ok := rewritten[string(m[i+1])]; ok && m[i] != nil {
+				return bytes.Join([][]byte{m[i], []byte(bundled), m[i+2]}, nil)
+			}
+		}
+		return match
+	})
+
+	zw := zip.NewWriter(w)
+	entry, err := zw.Create(opts.Name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to write bundle: %w", err)
+	}
+	if _, err := entry.Write(content); err != nil {
+		return nil, fmt.Errorf("failed to write bundle: %w", err)
+	}
+	added := map[string]bool{}
+	for _, f := range files {
+		if added[f.Path] {
+			continue
+		}
+		added[f.Path] = true
+		if err := addBundleFile(zw, f); err != nil {
+			return nil, err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return nil, fmt.Errorf("failed to write bundle: %w", err)
+	}
+	return files, nil
+}
+
+// uniqueBundlePath はdirの下でまだ使われていないファイル名のパスを返し，使用済みとして記録する．
+func uniqueBundlePath(dir, name string, used map[string]bool) string {
+	ext := path.Ext(name)
+	stem := strings.TrimSuffix(name, ext)
+	p := path.Join(dir, name)
+	for n := 2; used[p]; n++ {
+		p = path.Join(dir, stem+"-"+strconv.Itoa(n)+ext)
+	}
+	used[p] = true
+	return p
+}
+
+// addBundleFile はメディアファイルの内容をバンドルに追加する．
+func addBundleFile(zw *zip.Writer, f BundledFile) error {
+	src, err := os.Open(f.Source)
+	if err != nil {
+		return fmt.Errorf("failed to open media file: %w", err)
+	}
+	defer src.Close()
+	entry, err := zw.Create(f.Path)
+	if err != nil {
+		return fmt.Errorf("failed to write bundle: %w", err)
+	}
+	if _, err := io.Copy(entry, src); err != nil {
+		return fmt.Errorf("failed to write bundle: %s: %w", f.Source, err)
+	}
+	return nil
+}
+
+// ConvertItemsToBundle は問題データを変換し，変換結果と参照しているメディアファイルをbundlePathのzipファイルに書き出す．
+// 出力形式はConvertItemsと同じく，templateFilePath，opts.Format，bundle.Nameの拡張子の順に決める．
+// opts.Pipelineが指定されている場合は，出力の前に問題データに適用する．
+func ConvertItemsToBundle(data []QuizItem, bundlePath, templateFilePath string, opts ConvertOptions, bundle BundleOptions) ([]BundledFile, error) {
+	data, err := opts.Pipeline.Apply(data)
+	if err != nil {
+		return nil, err
+	}
+	var content bytes.Buffer
+	if err := writeConverted(&content, data, bundle.Name, templateFilePath, opts); err != nil {
+		return nil, err
+	}
+	var files []BundledFile
+	err = writeFileAtomic(bundlePath, opts.NoClobber, func(w io.Writer) error {
+		var err error
+		files, err = WriteBundle(w, content.Bytes(), bundle)
+		return err
+	})
+	return files, err
+}
+
+// writeConverted は問題データをConvertItemsと同じ規則で決めた出力形式でwに書き出す．
+// nameは出力形式を拡張子から決める場合に使うファイル名．
+func writeConverted(w io.Writer, data []QuizItem, name, templateFilePath string, opts ConvertOptions) error {
+	if templateFilePath == "" && opts.Format != "" {
+		f, err := ResolveFormatter(opts.Format)
+		if err != nil {
+			return err
+		}
+		return f.Format(w, data, opts)
+	}
+	switch format := DetectOutputFormat(name, templateFilePath); format {
+	case FormatCSV:
+		return WriteCSV(w, data, opts)
+	case FormatTemplate:
+		if templateFilePath == "" {
+			return ErrTemplateRequired
+		}
+		return WriteTemplate(w, data, templateFilePath, opts)
+	default:
+		return fmt.Errorf("%w: %s", ErrUnsupportedFormat, format)
+	}
+}
@@ -0,0 +1,135 @@
+package quiz_yaml_converter
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMediaReferences(t *testing.T) {
+	tests := []struct {
+		content  string
+		expected []string
+	}{
+		{`<img src="images/flag.png" alt="旗">`, []string{"images/flag.png"}},
+		{`<audio src='sounds/intro.MP3' controls></audio><video poster="p.jpg" src="v.mp4">`, []string{"sounds/intro.MP3", "p.jpg", "v.mp4"}},
+		{"![旗](images/flag.png) と ![旗](images/flag.png \"タイトル\")", []string{"images/flag.png"}},
+		{`<img src="https://example.com/a.png"><img src="data:image/png;base64,AAAA"><a href="#q1">`, nil},
+		{`<a href="quiz.html">問題</a><link href="style.css">`, nil},
+		{`<img src="my%20image.png?v=2">`, []string{"my%20image.png?v=2"}},
+	}
+	for _, tt := range tests {
+		if got := MediaReferences([]byte(tt.content)); !reflect.DeepEqual(got, tt.expected) {
+			t.Errorf("MediaReferences(%q) = %q, want %q", tt.content, got, tt.expected)
+		}
+	}
+}
+
+// readBundle はzipファイルの内容をファイル名ごとに返す．
+func readBundle(t *testing.T, data []byte) map[string]string {
+	t.Helper()
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("zip.NewReader() error = %v", err)
+	}
+	entries := map[string]string{}
+	for _, f := range zr.File {
+		r, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := io.ReadAll(r)
+		r.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		entries[f.Name] = string(b)
+	}
+	return entries
+}
+
+func TestWriteBundle(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"images/flag.png":  "flag",
+		"other/flag.png":   "other flag",
+		"sounds/intro.mp3": "intro",
+		"my image.png":     "space",
+	} {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	content := `<img src="images/flag.png"><img src="./images/flag.png"><img src='other/flag.png'>` +
+		"\n![音](sounds/intro.mp3)\n" + `<img src="my%20image.png"><img src="https://example.com/a.png">`
+
+	var buf bytes.Buffer
+	files, err := WriteBundle(&buf, []byte(content), BundleOptions{Name: "quiz.html", BaseDir: dir})
+	if err != nil {
+		t.Fatalf("WriteBundle() error = %v", err)
+	}
+	var paths []string
+	for _, f := range files {
+		paths = append(paths, f.Path)
+	}
+	if want := []string{"media/flag.png", "media/flag.png", "media/flag-2.png", "media/intro.mp3", "media/my image.png"}; !reflect.DeepEqual(paths, want) {
+		t.Errorf("WriteBundle() paths = %q, want %q", paths, want)
+	}
+
+	entries := readBundle(t, buf.Bytes())
+	expected := map[string]string{
+		"quiz.html": `<img src="media/flag.png"><img src="media/flag.png"><img src='media/flag-2.png'>` +
+			"\n![音](media/intro.mp3)\n" + `<img src="media/my%20image.png"><img src="https://example.com/a.png">`,
+		"media/flag.png":     "flag",
+		"media/flag-2.png":   "other flag",
+		"media/intro.mp3":    "intro",
+		"media/my image.png": "space",
+	}
+	if !reflect.DeepEqual(entries, expected) {
+		t.Errorf("bundle entries = %q, want %q", entries, expected)
+	}
+
+	if _, err := WriteBundle(io.Discard, []byte(`<img src="missing.png">`), BundleOptions{Name: "quiz.html", BaseDir: dir}); err == nil || !strings.Contains(err.Error(), "missing.png") {
+		t.Errorf("WriteBundle() error = %v, want an error about missing.png", err)
+	}
+	if _, err := WriteBundle(io.Discard, []byte(content), BundleOptions{BaseDir: dir}); err == nil {
+		t.Error("WriteBundle() without Name should fail")
+	}
+}
+
+func TestConvertItemsToBundle(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "flag.png"), []byte("flag"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	items := []QuizItem{{Question: "この旗の国は？ ![旗](flag.png)", Answer: "日本"}}
+	bundlePath := filepath.Join(dir, "packet.zip")
+	files, err := ConvertItemsToBundle(items, bundlePath, "", ConvertOptions{Format: "markdown"}, BundleOptions{Name: "packet.md", BaseDir: dir, MediaDir: "assets"})
+	if err != nil {
+		t.Fatalf("ConvertItemsToBundle() error = %v", err)
+	}
+	if len(files) != 1 || files[0].Path != "assets/flag.png" {
+		t.Errorf("ConvertItemsToBundle() = %+v, want assets/flag.png", files)
+	}
+	data, err := os.ReadFile(bundlePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries := readBundle(t, data)
+	if !strings.Contains(entries["packet.md"], "![旗](assets/flag.png)") || entries["assets/flag.png"] != "flag" {
+		t.Errorf("bundle entries = %q", entries)
+	}
+
+	if _, err := ConvertItemsToBundle(items, bundlePath, "", ConvertOptions{Format: "markdown", NoClobber: true}, BundleOptions{Name: "packet.md", BaseDir: dir}); err == nil {
+		t.Error("ConvertItemsToBundle() with NoClobber should fail for an existing file")
+	}
+}
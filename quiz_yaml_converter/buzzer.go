@@ -0,0 +1,247 @@
+// 早押しの進行（ボタンを押す順番，誤答したプレイヤーの締め出し，解答の判定と得点）を管理する処理です．
+package quiz_yaml_converter
+
+import (
+	"fmt"
+	"strings"
+)
+
+// BuzzerGame は早押しクイズの進行を管理する．
+// 出題中の問題でボタンを押した（Buzz）最初のプレイヤーが解答権を得て，
+// 正解すると得点し，誤答するとその問題では再びボタンを押せなくなる（締め出し）．
+// 並行に呼び出す場合は呼び出し側で排他制御を行う．
+type BuzzerGame struct {
+	items     []QuizItem
+	current   int // 出題中の問題の位置（0始まり，開始前は-1）
+	players   []BuzzerPlayer
+	answering string          // 解答権を持つプレイヤー（いない場合は空）
+	lockedOut map[string]bool // 出題中の問題で締め出されたプレイヤー
+	closed    bool            // 出題中の問題の解答を締め切ったか
+	last      *BuzzerResult
+}
+
+// BuzzerPlayer は早押しに参加しているプレイヤーの得点．
+type BuzzerPlayer struct {
+	Name      string `json:"name"`
+	Score     int    `json:"score"`     // 正解した問題の数
+	Misses    int    `json:"misses"`    // 誤答した回数
+	Connected bool   `json:"connected"` // 接続しているか（Leaveで切断したプレイヤーはfalse）
+}
+
+// BuzzerResult は直前の解答の判定．
+type BuzzerResult struct {
+	Player    string    `json:"player"`
+	Answer    string    `json:"answer,omitempty"` // 入力された解答（司会者が判定した場合は空）
+	Judgement Judgement `json:"judgement"`
+}
+
+// BuzzerState はプレイヤーに配信する進行の状態．
+// 出題中の問題の答えは，解答を締め切るまで含めない．
+type BuzzerState struct {
+	Index     int            `json:"index"` // 出題中の問題の番号（1始まり，開始前は0）
+	Total     int            `json:"total"`
+	Finished  bool           `json:"finished"` // 最後の問題の解答を締め切ったか
+	Question  string         `json:"question,omitempty"`
+	Genre     string         `json:"genre,omitempty"`
+	Answer    string         `json:"answer,omitempty"`
+	Reading   string         `json:"reading,omitempty"`
+	Closed    bool           `json:"closed"`
+	Answering string         `json:"answering,omitempty"`
+	LockedOut []string       `json:"locked_out"`
+	Players   []BuzzerPlayer `json:"players"`
+	Last      *BuzzerResult  `json:"last,omitempty"`
+}
+
+// NewBuzzerGame は問題から早押しの進行を作成する．問題は1つ以上必要．
+// 作成直後は開始前で，Nextで最初の問題を出題する．
+func NewBuzzerGame(items []QuizItem) (*BuzzerGame, error) {
+	if len(items) == 0 {
+		return nil, fmt.Errorf("no items")
+	}
+	return &BuzzerGame{items: items, current: -1, lockedOut: map[string]bool{}, closed: true}, nil
+}
+
+// Join はプレイヤーを参加させる．既に参加しているプレイヤーの場合はRejoinと同じく，得点を引き継いで接続中に戻す．
+func (g *BuzzerGame) Join(name string) error {
+	if strings.TrimSpace(name) == "" {
+		return fmt.Errorf("player name is empty")
+	}
+	if g.player(name) == nil {
+		g.players = append(g.players, BuzzerPlayer{Name: name, Connected: true})
+		return nil
+	}
+	return g.Rejoin(name)
+}
+
+// Leave はプレイヤーが切断したことを記録する．得点は残し，Rejoinで再び参加できる．
+// 解答権を持っていた場合は解答権を取り消し，残りの接続中のプレイヤーが全員締め出されている場合は解答を締め切る．
+func (g *BuzzerGame) Leave(name string) error {
+	p := g.player(name)
+	if p == nil {
+		return fmt.Errorf("player %q has not joined", name)
+	}
+	p.Connected = false
+	if g.answering == name {
+		g.answering = ""
+	}
+	if !g.closed && g.allLockedOut() {
+		g.closed = true
+	}
+	return nil
+}
+
+// Rejoin はLeaveで切断したプレイヤーを接続中に戻す．得点と出題中の問題での締め出しは引き継ぐ．
+func (g *BuzzerGame) Rejoin(name string) error {
+	p := g.player(name)
+	if p == nil {
+		return fmt.Errorf("player %q has not joined", name)
+	}
+	p.Connected = true
+	return nil
+}
+
+// allLockedOut は締め出されたプレイヤーがいて，接続中のプレイヤーが全員締め出されているかを返す．
+// 切断したプレイヤーはボタンを押せないため数えない．
+func (g *BuzzerGame) allLockedOut() bool {
+	if len(g.lockedOut) == 0 {
+		return false
+	}
+	for _, p := range g.players {
+		if p.Connected && !g.lockedOut[p.Name] {
+			return false
+		}
+	}
+	return true
+}
+
+// player は参加しているプレイヤーを返す．参加していない場合はnilを返す．
+func (g *BuzzerGame) player(name string) *BuzzerPlayer {
+	for i := range g.players {
+		if g.players[i].Name == name {
+			return &g.players[i]
+		}
+	}
+	return nil
+}
+
+// Item は出題中の問題を返す．開始前の場合はfalseを返す．
+func (g *BuzzerGame) Item() (QuizItem, bool) {
+	if g.current < 0 {
+		return QuizItem{}, false
+	}
+	return g.items[g.current], true
+}
+
+// Next は次の問題を出題する．最後の問題の場合は何もせずfalseを返す．
+func (g *BuzzerGame) Next() bool {
+	if g.current+1 >= len(g.items) {
+		return false
+	}
+	g.current++
+	g.answering = ""
+	g.lockedOut = map[string]bool{}
+	g.closed = false
+	g.last = nil
+	return true
+}
+
+// Buzz はプレイヤーがボタンを押したことを記録し，解答権を与える．
+// 出題中でない場合，解答を締め切った場合，他のプレイヤーが解答権を持っている場合，
+// 締め出されている場合はエラーとなる．
+func (g *BuzzerGame) Buzz(name string) error {
+	switch {
+	case g.player(name) == nil:
+		return fmt.Errorf("player %q has not joined", name)
+	case !g.player(name).Connected:
+		return fmt.Errorf("player %q has left", name)
+	case g.current < 0 || g.closed:
+		return fmt.Errorf("no question is open")
+	case g.answering != "":
+		return fmt.Errorf("%q is answering", g.answering)
+	case g.lockedOut[name]:
+		return fmt.Errorf("player %q is locked out of this question", name)
+	}
+	g.answering = name
+	return nil
+}
+
+// Answer は解答権を持つプレイヤーの解答をJudgeAnswerで判定し，結果を反映する．
+func (g *BuzzerGame) Answer(name, answer string) (Judgement, error) {
+	if g.answering == "" || g.answering != name {
+		return JudgeIncorrect, fmt.Errorf("player %q does not have the right to answer", name)
+	}
+	j := JudgeAnswer(g.items[g.current], answer)
+	g.apply(BuzzerResult{Player: name, Answer: answer, Judgement: j})
+	return j, nil
+}
+
+// Judge は司会者が口頭の解答などを判定した結果を，解答権を持つプレイヤーに反映する．
+func (g *BuzzerGame) Judge(j Judgement) error {
+	if g.answering == "" {
+		return fmt.Errorf("no player is answering")
+	}
+	g.apply(BuzzerResult{Player: g.answering, Judgement: j})
+	return nil
+}
+
+// apply は判定を反映する．正解の場合は得点して解答を締め切り，誤答の場合はそのプレイヤーを締め出す．
+// 接続中のプレイヤーが全員締め出された場合も解答を締め切る．もう一度の場合は解答権をそのまま残す．
+func (g *BuzzerGame) apply(result BuzzerResult) {
+	g.last = &result
+	p := g.player(result.Player)
+	switch result.Judgement {
+	case JudgeCorrect:
+		p.Score++
+		g.answering = ""
+		g.closed = true
+	case JudgeIncorrect:
+		p.Misses++
+		g.answering = ""
+		g.lockedOut[result.Player] = true
+		if g.allLockedOut() {
+			g.closed = true
+		}
+	}
+}
+
+// Reveal は出題中の問題の解答を締め切り，答えを公開する．
+func (g *BuzzerGame) Reveal() {
+	g.answering = ""
+	g.closed = true
+}
+
+// Players は参加しているプレイヤーを参加順に返す．
+func (g *BuzzerGame) Players() []BuzzerPlayer {
+	return append([]BuzzerPlayer(nil), g.players...)
+}
+
+// State はプレイヤーに配信する進行の状態を返す．
+func (g *BuzzerGame) State() BuzzerState {
+	s := BuzzerState{
+		Index:     g.current + 1,
+		Total:     len(g.items),
+		Finished:  g.current == len(g.items)-1 && g.closed,
+		Closed:    g.closed,
+		Answering: g.answering,
+		LockedOut: []string{},
+		Players:   g.Players(),
+		Last:      g.last,
+	}
+	if s.Players == nil {
+		s.Players = []BuzzerPlayer{}
+	}
+	for _, p := range g.players {
+		if g.lockedOut[p.Name] {
+			s.LockedOut = append(s.LockedOut, p.Name)
+		}
+	}
+	if item, ok := g.Item(); ok {
+		s.Question = item.Question
+		s.Genre = item.Genre
+		if g.closed {
+			s.Answer = item.Answer
+			s.Reading = item.Reading
+		}
+	}
+	return s
+}
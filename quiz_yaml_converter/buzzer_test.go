@@ -0,0 +1,168 @@
+package quiz_yaml_converter
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestBuzzerGame(t *testing.T) {
+	items := []QuizItem{
+		{Question: "日本一高い山は？", Answer: "富士山", Reading: "ふじさん"},
+		{Question: "日本一長い川は？", Answer: "信濃川", Criteria: map[string][]string{"repeat": {"信濃"}}},
+	}
+	if _, err := NewBuzzerGame(nil); err == nil {
+		t.Error("NewBuzzerGame() without items expected error")
+	}
+	g, err := NewBuzzerGame(items)
+	if err != nil {
+		t.Fatalf("NewBuzzerGame() error = %v", err)
+	}
+	for _, name := range []string{"山田", "佐藤", "山田"} {
+		if err := g.Join(name); err != nil {
+			t.Fatalf("Join(%q) error = %v", name, err)
+		}
+	}
+	if err := g.Join(" "); err == nil {
+		t.Error("Join() with an empty name expected error")
+	}
+	if err := g.Buzz("山田"); err == nil {
+		t.Error("Buzz() before the first question expected error")
+	}
+
+	// 1問目: 山田が誤答して締め出され，佐藤が読みで正解する
+	if !g.Next() {
+		t.Fatal("Next() = false, want true")
+	}
+	if state := g.State(); state.Index != 1 || state.Answer != "" || state.Closed {
+		t.Errorf("State() before answering = %+v", state)
+	}
+	if err := g.Buzz("鈴木"); err == nil {
+		t.Error("Buzz() by a player who has not joined expected error")
+	}
+	if err := g.Buzz("山田"); err != nil {
+		t.Fatalf("Buzz() error = %v", err)
+	}
+	if err := g.Buzz("佐藤"); err == nil {
+		t.Error("Buzz() while another player is answering expected error")
+	}
+	if _, err := g.Answer("佐藤", "富士山"); err == nil {
+		t.Error("Answer() without the right to answer expected error")
+	}
+	if j, _ := g.Answer("山田", "高尾山"); j != JudgeIncorrect {
+		t.Errorf("Answer() = %v, want incorrect", j)
+	}
+	if err := g.Buzz("山田"); err == nil {
+		t.Error("Buzz() by a locked out player expected error")
+	}
+	if err := g.Buzz("佐藤"); err != nil {
+		t.Fatalf("Buzz() error = %v", err)
+	}
+	if j, _ := g.Answer("佐藤", "フジサン"); j != JudgeCorrect {
+		t.Errorf("Answer() = %v, want correct", j)
+	}
+	state := g.State()
+	if !state.Closed || state.Answer != "富士山" || state.Finished {
+		t.Errorf("State() after the correct answer = %+v", state)
+	}
+	if !reflect.DeepEqual(state.LockedOut, []string{"山田"}) {
+		t.Errorf("State().LockedOut = %v, want [山田]", state.LockedOut)
+	}
+	if err := g.Buzz("佐藤"); err == nil {
+		t.Error("Buzz() after the question is closed expected error")
+	}
+
+	// 2問目: もう一度の後，司会者が誤答と判定し，全員が締め出されて締め切る
+	if !g.Next() {
+		t.Fatal("Next() = false, want true")
+	}
+	g.Buzz("佐藤")
+	if j, _ := g.Answer("佐藤", "信濃"); j != JudgeRepeat || g.State().Answering != "佐藤" {
+		t.Errorf("Answer() = %v, answering = %q, want repeat and 佐藤", j, g.State().Answering)
+	}
+	if err := g.Judge(JudgeIncorrect); err != nil {
+		t.Fatalf("Judge() error = %v", err)
+	}
+	g.Buzz("山田")
+	g.Judge(JudgeIncorrect)
+	state = g.State()
+	if !state.Closed || !state.Finished || state.Answer != "信濃川" {
+		t.Errorf("State() after everyone is locked out = %+v", state)
+	}
+	if err := g.Judge(JudgeCorrect); err == nil {
+		t.Error("Judge() without an answering player expected error")
+	}
+	if g.Next() {
+		t.Error("Next() at the last question = true")
+	}
+
+	expected := []BuzzerPlayer{{Name: "山田", Score: 0, Misses: 2, Connected: true}, {Name: "佐藤", Score: 1, Misses: 1, Connected: true}}
+	if !reflect.DeepEqual(g.Players(), expected) {
+		t.Errorf("Players() = %+v, want %+v", g.Players(), expected)
+	}
+}
+
+func TestBuzzerGameReveal(t *testing.T) {
+	g, _ := NewBuzzerGame([]QuizItem{{Question: "問題", Answer: "答え"}})
+	g.Join("山田")
+	g.Next()
+	g.Buzz("山田")
+	g.Reveal()
+	if state := g.State(); !state.Closed || state.Answering != "" || state.Answer != "答え" {
+		t.Errorf("State() after Reveal() = %+v", state)
+	}
+}
+
+func TestBuzzerGameLeave(t *testing.T) {
+	g, _ := NewBuzzerGame([]QuizItem{{Question: "問題1", Answer: "答え1"}, {Question: "問題2", Answer: "答え2"}})
+	for _, name := range []string{"山田", "佐藤", "鈴木"} {
+		g.Join(name)
+	}
+	if err := g.Leave("田中"); err == nil {
+		t.Error("Leave() by a player who has not joined expected error")
+	}
+
+	// 1問目: 解答権を持つ鈴木が切断すると解答権が取り消され，山田の誤答で接続中の全員が締め出される
+	g.Next()
+	g.Buzz("佐藤")
+	g.Judge(JudgeIncorrect)
+	g.Buzz("鈴木")
+	if err := g.Leave("鈴木"); err != nil {
+		t.Fatalf("Leave() error = %v", err)
+	}
+	if state := g.State(); state.Answering != "" || state.Closed {
+		t.Errorf("State() after the answering player left = %+v", state)
+	}
+	if err := g.Buzz("鈴木"); err == nil {
+		t.Error("Buzz() by a player who has left expected error")
+	}
+	g.Buzz("山田")
+	g.Judge(JudgeIncorrect)
+	if state := g.State(); !state.Closed {
+		t.Errorf("State() after every connected player is locked out = %+v", state)
+	}
+
+	// 2問目: 鈴木が戻ると再びボタンを押せ，佐藤が切断すると残りの全員が締め出された状態で締め切る
+	g.Next()
+	if err := g.Rejoin("鈴木"); err != nil {
+		t.Fatalf("Rejoin() error = %v", err)
+	}
+	if err := g.Rejoin("田中"); err == nil {
+		t.Error("Rejoin() by a player who has not joined expected error")
+	}
+	g.Buzz("山田")
+	g.Judge(JudgeIncorrect)
+	g.Buzz("鈴木")
+	g.Judge(JudgeIncorrect)
+	if g.State().Closed {
+		t.Fatal("State().Closed = true while a connected player can still buzz")
+	}
+	g.Leave("佐藤")
+	if !g.State().Closed {
+		t.Error("State().Closed = false after the last player who could buzz left")
+	}
+
+	expected := []BuzzerPlayer{{Name: "山田", Misses: 2, Connected: true}, {Name: "佐藤", Misses: 1}, {Name: "鈴木", Misses: 1, Connected: true}}
+	if !reflect.DeepEqual(g.Players(), expected) {
+		t.Errorf("Players() = %+v, want %+v", g.Players(), expected)
+	}
+}
@@ -0,0 +1,85 @@
+// 変換結果のキャッシュを扱う処理です．
+// 入力・テンプレート・オプションが前回の変換と同じ場合に変換を省略し，
+// 多数のファイルを繰り返し変換するときの時間を短縮します．
+package quiz_yaml_converter
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// BuildCache は変換結果のキャッシュ．出力ファイルごとに，
+// その出力を生成したときの入力ファイル・テンプレート・オプションのハッシュ（BuildKey）を記録する．
+type BuildCache struct {
+	path    string
+	Entries map[string]string `json:"entries"` // 出力ファイルのパスからハッシュへの対応
+}
+
+// LoadBuildCache はキャッシュファイルを読み込む．ファイルが存在しない場合は空のキャッシュを返す．
+func LoadBuildCache(path string) (*BuildCache, error) {
+	cache := &BuildCache{path: path, Entries: map[string]string{}}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return cache, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cache file: %w", err)
+	}
+	if err := json.Unmarshal(data, cache); err != nil {
+		return nil, fmt.Errorf("failed to parse cache file %s: %w", path, err)
+	}
+	if cache.Entries == nil {
+		cache.Entries = map[string]string{}
+	}
+	return cache, nil
+}
+
+// BuildKey はfilesの各ファイルのパスと内容，およびargsからハッシュを計算する．
+// 入力ファイルとテンプレートファイルをfilesに，出力に影響するオプションをargsに渡す．
+// いずれかが変わるとハッシュも変わる．
+func BuildKey(files []string, args []string) (string, error) {
+	h := sha256.New()
+	for _, path := range files {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		// 区切りが曖昧にならないよう，長さを付けて書き込む
+		fmt.Fprintf(h, "file %d:%s\n%d:", len(path), path, len(data))
+		h.Write(data)
+	}
+	for _, arg := range args {
+		fmt.Fprintf(h, "arg %d:%s\n", len(arg), arg)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// UpToDate は出力ファイルが存在し，前回記録したハッシュがkeyと同じかを返す．
+func (c *BuildCache) UpToDate(output, key string) bool {
+	if c.Entries[filepath.Clean(output)] != key {
+		return false
+	}
+	_, err := os.Stat(output)
+	return err == nil
+}
+
+// Record は出力ファイルを生成したときのハッシュを記録する．ファイルへの書き出しはSaveで行う．
+func (c *BuildCache) Record(output, key string) {
+	c.Entries[filepath.Clean(output)] = key
+}
+
+// Save はキャッシュをファイルに書き出す．
+func (c *BuildCache) Save() error {
+	return writeFileAtomic(c.path, false, func(w io.Writer) error {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(c)
+	})
+}
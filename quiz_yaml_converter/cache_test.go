@@ -0,0 +1,93 @@
+package quiz_yaml_converter
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestBuildKey(t *testing.T) {
+	tempDir := t.TempDir()
+	input := filepath.Join(tempDir, "quiz.yaml")
+	tmpl := filepath.Join(tempDir, "quiz.tmpl")
+	if err := os.WriteFile(input, []byte("- question: q1\n  answer: a1\n"), 0644); err != nil {
+		t.Fatalf("Failed to create YAML file: %v", err)
+	}
+	if err := os.WriteFile(tmpl, []byte("{{range .}}{{.Question}}{{end}}"), 0644); err != nil {
+		t.Fatalf("Failed to create template file: %v", err)
+	}
+
+	base, err := BuildKey([]string{input, tmpl}, []string{"-format=csv"})
+	if err != nil {
+		t.Fatalf("BuildKey() error = %v", err)
+	}
+	if again, _ := BuildKey([]string{input, tmpl}, []string{"-format=csv"}); again != base {
+		t.Errorf("BuildKey() = %q, then %q for the same inputs", base, again)
+	}
+	if key, _ := BuildKey([]string{input, tmpl}, []string{"-format=html"}); key == base {
+		t.Errorf("BuildKey() did not change when the arguments changed")
+	}
+	if key, _ := BuildKey([]string{input, tmpl}, []string{"-format", "=csv"}); key == base {
+		t.Errorf("BuildKey() did not change when the arguments were split differently")
+	}
+	if err := os.WriteFile(tmpl, []byte("{{range .}}{{.Answer}}{{end}}"), 0644); err != nil {
+		t.Fatalf("Failed to update template file: %v", err)
+	}
+	if key, _ := BuildKey([]string{input, tmpl}, []string{"-format=csv"}); key == base {
+		t.Errorf("BuildKey() did not change when the template changed")
+	}
+	if _, err := BuildKey([]string{filepath.Join(tempDir, "missing.yaml")}, nil); err == nil {
+		t.Errorf("BuildKey() with a missing file expected error, got nil")
+	}
+}
+
+func TestBuildCache(t *testing.T) {
+	tempDir := t.TempDir()
+	cachePath := filepath.Join(tempDir, "cache.json")
+	output := filepath.Join(tempDir, "quiz.csv")
+
+	cache, err := LoadBuildCache(cachePath)
+	if err != nil {
+		t.Fatalf("LoadBuildCache() error = %v", err)
+	}
+	cache.Record(output, "key1")
+	if cache.UpToDate(output, "key1") {
+		t.Errorf("UpToDate() = true for a missing output, want false")
+	}
+	if err := os.WriteFile(output, []byte("question,answer\n"), 0644); err != nil {
+		t.Fatalf("Failed to create output file: %v", err)
+	}
+	if err := cache.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	loaded, err := LoadBuildCache(cachePath)
+	if err != nil {
+		t.Fatalf("LoadBuildCache() error = %v", err)
+	}
+	tests := []struct {
+		name     string
+		output   string
+		key      string
+		expected bool
+	}{
+		{"same key", output, "key1", true},
+		{"unclean path", filepath.Join(tempDir, ".", "quiz.csv"), "key1", true},
+		{"changed key", output, "key2", false},
+		{"other output", filepath.Join(tempDir, "quiz.html"), "key1", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := loaded.UpToDate(tt.output, tt.key); got != tt.expected {
+				t.Errorf("UpToDate(%q, %q) = %v, want %v", tt.output, tt.key, got, tt.expected)
+			}
+		})
+	}
+
+	if err := os.WriteFile(cachePath, []byte("{"), 0644); err != nil {
+		t.Fatalf("Failed to write cache file: %v", err)
+	}
+	if _, err := LoadBuildCache(cachePath); err == nil {
+		t.Errorf("LoadBuildCache() with a broken file expected error, got nil")
+	}
+}
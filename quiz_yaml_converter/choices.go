@@ -0,0 +1,163 @@
+// 記述式の問題から多肢選択の問題を作る処理です．
+package quiz_yaml_converter
+
+import (
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// 既定の選択肢の数
+const defaultChoiceCount = 4
+
+// ChoiceOptions は選択肢を作るときの設定．
+type ChoiceOptions struct {
+	Count int   // 答えを含む選択肢の数（0の場合は4）
+	Seed  int64 // 誤答の候補の選び方と選択肢の並びを決める乱数のシード．同じシードからは同じ結果になる
+}
+
+// GenerateChoices はchoicesが未設定の問題に，ほかの問題の答えから選んだ誤答の選択肢を加えて
+// opts.Count択の問題にした問題データを返す．元のスライスは変更しない．
+// 誤答は，同じジャンルの答え，同じ文字種（漢字・カタカナ・ひらがな・英字など）の答え，
+// 文字数が近い答えの順に優先して選び，答えや別解（criteria.ok）と同じものは使わない．
+// 候補が足りない問題があった場合は，そのすべての問題のItemErrorをまとめて返す．
+func GenerateChoices(data []QuizItem, opts ChoiceOptions) ([]QuizItem, error) {
+	count := opts.Count
+	if count == 0 {
+		count = defaultChoiceCount
+	}
+	if count < 2 {
+		return nil, fmt.Errorf("number of choices must be at least 2: %d", count)
+	}
+	r := rand.New(rand.NewPCG(uint64(opts.Seed), 0))
+
+	// 誤答の候補となる答え（重複を除き，入力の順）
+	type candidate struct {
+		answer string
+		genre  string
+		script string
+		length int
+	}
+	var candidates []candidate
+	seen := map[string]bool{}
+	for _, item := range data {
+		answer := strings.TrimSpace(item.Answer)
+		if answer == "" || seen[answer] {
+			continue
+		}
+		seen[answer] = true
+		candidates = append(candidates, candidate{answer, strings.TrimSpace(item.Genre), answerScript(answer), utf8.RuneCountInString(answer)})
+	}
+
+	items := cloneItems(data)
+	var errs []error
+	for i := range items {
+		item := &items[i]
+		if len(item.Choices) > 0 {
+			continue
+		}
+		answer := strings.TrimSpace(item.Answer)
+		genre := strings.TrimSpace(item.Genre)
+		script := answerScript(answer)
+		length := utf8.RuneCountInString(answer)
+		accepted := item.AllAcceptedAnswers()
+
+		var pool []candidate
+		for _, c := range candidates {
+			if !slices.Contains(accepted, c.answer) {
+				pool = append(pool, c)
+			}
+		}
+		if len(pool) < count-1 {
+			errs = append(errs, &ItemError{Index: i + 1, Err: fmt.Errorf("not enough distractors: need %d, found %d", count-1, len(pool))})
+			continue
+		}
+		r.Shuffle(len(pool), func(a, b int) { pool[a], pool[b] = pool[b], pool[a] })
+		sort.SliceStable(pool, func(a, b int) bool {
+			if ga, gb := pool[a].genre == genre, pool[b].genre == genre; ga != gb {
+				return ga
+			}
+			if sa, sb := pool[a].script == script, pool[b].script == script; sa != sb {
+				return sa
+			}
+			return abs(pool[a].length-length) < abs(pool[b].length-length)
+		})
+
+		choices := []string{answer}
+		for _, c := range pool[:count-1] {
+			choices = append(choices, c.answer)
+		}
+		r.Shuffle(len(choices), func(a, b int) { choices[a], choices[b] = choices[b], choices[a] })
+		item.Choices = choices
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return items, nil
+}
+
+// answerScript は答えに最も多く含まれる文字種（han, katakana, hiragana, latin, digit, other）を返す．
+// 長音符や中黒，空白や記号は数えない．
+func answerScript(s string) string {
+	counts := map[string]int{}
+	for _, r := range s {
+		switch {
+		case unicode.Is(unicode.Han, r):
+			counts["han"]++
+		case r == 'ー' || r == '・':
+		case unicode.Is(unicode.Katakana, r):
+			counts["katakana"]++
+		case unicode.Is(unicode.Hiragana, r):
+			counts["hiragana"]++
+		case unicode.Is(unicode.Latin, r):
+			counts["latin"]++
+		case unicode.IsDigit(r):
+			counts["digit"]++
+		}
+	}
+	script, best := "other", 0
+	for _, name := range []string{"han", "katakana", "hiragana", "latin", "digit"} {
+		if counts[name] > best {
+			script, best = name, counts[name]
+		}
+	}
+	return script
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// validateChoices は選択肢（choices）をバリデーションする．
+// 選択肢が空の場合や，答えも別解も選択肢に含まれていない場合はエラーとする．
+func validateChoices(item QuizItem, index int) []ValidationError {
+	if len(item.Choices) == 0 {
+		return nil
+	}
+	var errors []ValidationError
+	for j, choice := range item.Choices {
+		if strings.TrimSpace(choice) == "" {
+			field := fmt.Sprintf("choices[%d]", j)
+			errors = append(errors, newValidationError(index, field, RuleEmpty, nil, "%s が空です", field))
+		}
+	}
+	for _, answer := range item.AllAcceptedAnswers() {
+		for _, choice := range item.Choices {
+			if strings.TrimSpace(choice) == answer {
+				return errors
+			}
+		}
+	}
+	if strings.TrimSpace(item.Answer) != "" {
+		errors = append(errors, newValidationError(index, "choices", RuleChoices, nil, "選択肢 (choices) に答え (answer) が含まれていません"))
+	}
+	return errors
+}
@@ -0,0 +1,117 @@
+package quiz_yaml_converter
+
+import (
+	"reflect"
+	"slices"
+	"testing"
+)
+
+func TestAnswerScript(t *testing.T) {
+	tests := []struct {
+		answer   string
+		expected string
+	}{
+		{"富士山", "han"},
+		{"ジョン・アタナソフ", "katakana"},
+		{"ひらがな", "hiragana"},
+		{"ENIAC", "latin"},
+		{"1942", "digit"},
+		{"リンド数学パピルス", "katakana"},
+		{"！？", "other"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.answer, func(t *testing.T) {
+			if result := answerScript(tt.answer); result != tt.expected {
+				t.Errorf("answerScript(%q) = %q, want %q", tt.answer, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestGenerateChoices(t *testing.T) {
+	data := []QuizItem{
+		{Question: "q1", Answer: "徳川家康", Genre: "歴史"},
+		{Question: "q2", Answer: "豊臣秀吉", Genre: "歴史"},
+		{Question: "q3", Answer: "織田信長", Genre: "歴史", Criteria: map[string][]string{"ok": {"信長"}}},
+		{Question: "q4", Answer: "ナポレオン", Genre: "歴史"},
+		{Question: "q5", Answer: "富士山", Genre: "地理"},
+		{Question: "q6", Answer: "信長", Genre: "地理"},
+		{Question: "q7", Answer: "A", Genre: "地理", Choices: []string{"A", "B"}},
+	}
+
+	result, err := GenerateChoices(data, ChoiceOptions{Seed: 1})
+	if err != nil {
+		t.Fatalf("GenerateChoices() error = %v", err)
+	}
+
+	// 同じジャンルで同じ文字種の答えが優先される
+	want := map[int][]string{
+		0: {"徳川家康", "豊臣秀吉", "織田信長", "ナポレオン"},
+		2: {"織田信長", "徳川家康", "豊臣秀吉", "ナポレオン"},
+	}
+	for i, expected := range want {
+		got := slices.Clone(result[i].Choices)
+		slices.Sort(got)
+		slices.Sort(expected)
+		if !reflect.DeepEqual(got, expected) {
+			t.Errorf("item %d choices = %q, want %q in any order", i+1, result[i].Choices, expected)
+		}
+	}
+	for i, item := range result[:6] {
+		if len(item.Choices) != 4 || !slices.Contains(item.Choices, item.Answer) {
+			t.Errorf("item %d choices = %q, want 4 choices including %q", i+1, item.Choices, item.Answer)
+		}
+	}
+	if !reflect.DeepEqual(result[6].Choices, []string{"A", "B"}) {
+		t.Errorf("item 7 choices = %q, want the existing choices kept", result[6].Choices)
+	}
+	if data[0].Choices != nil {
+		t.Errorf("GenerateChoices() modified the original data: %q", data[0].Choices)
+	}
+
+	again, _ := GenerateChoices(data, ChoiceOptions{Seed: 1})
+	if !reflect.DeepEqual(again, result) {
+		t.Errorf("GenerateChoices() with the same seed returned different choices")
+	}
+}
+
+func TestGenerateChoices_Errors(t *testing.T) {
+	data := []QuizItem{{Question: "q1", Answer: "a1"}, {Question: "q2", Answer: "a2"}}
+	_, err := GenerateChoices(data, ChoiceOptions{Count: 3})
+	if itemErrs := ItemErrors(err); len(itemErrs) != 2 {
+		t.Errorf("GenerateChoices() error = %v, want errors for both items", err)
+	}
+	if _, err := GenerateChoices(data, ChoiceOptions{Count: 1}); err == nil {
+		t.Errorf("GenerateChoices() with 1 choice expected error, got nil")
+	}
+	if result, err := GenerateChoices(data, ChoiceOptions{Count: 2}); err != nil || !reflect.DeepEqual(result[0].Choices, []string{"a1", "a2"}) && !reflect.DeepEqual(result[0].Choices, []string{"a2", "a1"}) {
+		t.Errorf("GenerateChoices() = %v, %v, want 2 choices", result, err)
+	}
+}
+
+func TestValidateChoices(t *testing.T) {
+	tests := []struct {
+		name  string
+		item  QuizItem
+		rules []string
+	}{
+		{"no choices", QuizItem{Question: "q", Answer: "a"}, nil},
+		{"valid", QuizItem{Question: "q", Answer: "a", Choices: []string{"b", "a"}}, nil},
+		{"alternative", QuizItem{Question: "q", Answer: "a", Choices: []string{"b", "c"}, Criteria: map[string][]string{"ok": {"c"}}}, nil},
+		{"missing answer", QuizItem{Question: "q", Answer: "a", Choices: []string{"b", "c"}}, []string{RuleChoices}},
+		{"empty choice", QuizItem{Question: "q", Answer: "a", Choices: []string{"a", " "}}, []string{RuleEmpty}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var rules []string
+			for _, e := range validateQuizItem(tt.item, 1) {
+				rules = append(rules, e.Rule)
+			}
+			if !reflect.DeepEqual(rules, tt.rules) {
+				t.Errorf("validateQuizItem() rules = %v, want %v", rules, tt.rules)
+			}
+		})
+	}
+}
@@ -0,0 +1,109 @@
+// 問題文からAnkiの穴埋め（cloze）形式のカードを作る処理です．
+package quiz_yaml_converter
+
+import (
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+// RuleClozeNoMatch は問題文に答えが含まれず，穴埋めを作れないことを表す警告の規則名．
+const RuleClozeNoMatch = "cloze-no-match"
+
+// ClozeTerms は問題文の中で穴埋めにする語句を返す．
+// 答え，答えの末尾の括弧書きを除いた主要な答えとその別解（SplitAnswer参照），criteria.okの別解の順で，
+// 重複と空文字列は除く．「ポケミス」（おまけ）のように「」の後ろに注記の付いた別解は「」の中だけを使う．
+func ClozeTerms(item QuizItem) []string {
+	var terms []string
+	add := func(term string) {
+		if term = strings.TrimSpace(term); term != "" && !slices.Contains(terms, term) {
+			terms = append(terms, term)
+		}
+	}
+	add(item.Answer)
+	primary, alternates := SplitAnswer(item.Answer)
+	add(primary)
+	for _, alt := range alternates {
+		add(alt)
+	}
+	for _, ok := range item.Criteria["ok"] {
+		add(unquoteCriteriaItem(ok))
+	}
+	return terms
+}
+
+// unquoteCriteriaItem は「」で始まる正誤判定の項目から，最初の「」の中の文字列を取り出す．
+// 「」で始まらない項目はそのまま返す．
+func unquoteCriteriaItem(s string) string {
+	s = strings.TrimSpace(s)
+	if !strings.HasPrefix(s, "「") {
+		return s
+	}
+	depth := 0
+	for i, r := range s {
+		switch r {
+		case '「':
+			depth++
+		case '」':
+			if depth--; depth == 0 {
+				return s[len("「"):i]
+			}
+		}
+	}
+	return s
+}
+
+// Cloze はtextに含まれるtermsをAnkiの穴埋め形式{{c1::語句}}に置き換える．
+// 同じ位置で複数の語句が一致する場合は長い語句を優先し，すべての箇所を1枚目のカード（c1）の穴とする．
+// 置き換えた箇所がない場合はtextをそのまま返し，okはfalseとなる．
+func Cloze(text string, terms []string) (result string, ok bool) {
+	terms = slices.Clone(terms)
+	slices.SortStableFunc(terms, func(a, b string) int { return len(b) - len(a) })
+
+	var b strings.Builder
+	for i := 0; i < len(text); {
+		matched := ""
+		for _, term := range terms {
+			if term != "" && strings.HasPrefix(text[i:], term) {
+				matched = term
+				break
+			}
+		}
+		if matched == "" {
+			_, size := utf8.DecodeRuneInString(text[i:])
+			b.WriteString(text[i : i+size])
+			i += size
+			continue
+		}
+		b.WriteString("{{c1::" + matched + "}}")
+		i += len(matched)
+		ok = true
+	}
+	if !ok {
+		return text, false
+	}
+	return b.String(), true
+}
+
+// ClozeDeletion は問題文に含まれる答えと別解（ClozeTerms参照）をAnkiの穴埋め形式に置き換えるStage．
+// 問題文に答えが含まれない問題はそのまま残す（ClozeWarnings参照）．
+var ClozeDeletion Stage = EachItem(func(_ int, item *QuizItem) (bool, error) {
+	item.Question, _ = Cloze(item.Question, ClozeTerms(*item))
+	return true, nil
+})
+
+// ClozeWarnings は問題文に答えも別解も含まれず，ClozeDeletionで穴埋めにできない問題についての警告を返す．
+// 問題文が空の問題はバリデーションエラーとなるため対象外とする．
+func ClozeWarnings(data []QuizItem) []ValidationError {
+	var warnings []ValidationError
+	for i, item := range data {
+		if strings.TrimSpace(item.Question) == "" {
+			continue
+		}
+		if _, ok := Cloze(item.Question, ClozeTerms(item)); !ok {
+			warnings = append(warnings, newValidationError(i+1, "question", RuleClozeNoMatch, nil,
+				"問題文 (question) に答え (answer) と別解が含まれていないため，穴埋めにできません"))
+		}
+	}
+	return warnings
+}
@@ -0,0 +1,86 @@
+package quiz_yaml_converter
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestClozeTerms(t *testing.T) {
+	tests := []struct {
+		name     string
+		item     QuizItem
+		expected []string
+	}{
+		{"answer only", QuizItem{Answer: "富士山"}, []string{"富士山"}},
+		{
+			"answer with alternatives",
+			QuizItem{Answer: "国際連合（国連）", Criteria: map[string][]string{"ok": {"UN", "国連"}, "ng": {"国際連盟"}}},
+			[]string{"国際連合（国連）", "国際連合", "国連", "UN"},
+		},
+		{
+			"quoted criteria with notes",
+			QuizItem{Answer: "ハヤカワ・ポケット・ミステリ", Criteria: map[string][]string{"ok": {"「ポケミス」（おまけ）", "「「HPB」の略称」"}}},
+			[]string{"ハヤカワ・ポケット・ミステリ", "ポケミス", "「HPB」の略称"},
+		},
+		{"empty", QuizItem{}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := ClozeTerms(tt.item); !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("ClozeTerms() = %q, want %q", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestCloze(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		terms    []string
+		expected string
+		ok       bool
+	}{
+		{"single", "日本一高い山は富士山である。", []string{"富士山"}, "日本一高い山は{{c1::富士山}}である。", true},
+		{"every occurrence", "国連とは国連のことだ", []string{"国連"}, "{{c1::国連}}とは{{c1::国連}}のことだ", true},
+		{"longest first", "国際連合の本部はニューヨークにある", []string{"国際", "国際連合"}, "{{c1::国際連合}}の本部はニューヨークにある", true},
+		{"alternative", "UNの本部", []string{"国際連合", "UN"}, "{{c1::UN}}の本部", true},
+		{"no match", "日本一高い山は何でしょう？", []string{"富士山"}, "日本一高い山は何でしょう？", false},
+		{"empty term", "abc", []string{""}, "abc", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, ok := Cloze(tt.text, tt.terms)
+			if result != tt.expected || ok != tt.ok {
+				t.Errorf("Cloze(%q, %q) = %q, %v, want %q, %v", tt.text, tt.terms, result, ok, tt.expected, tt.ok)
+			}
+		})
+	}
+}
+
+func TestClozeDeletion(t *testing.T) {
+	data := []QuizItem{
+		{Question: "1867年に大政奉還を行ったのは徳川慶喜である。", Answer: "徳川慶喜"},
+		{Question: "日本一高い山は何でしょう？", Answer: "富士山"},
+	}
+	result, err := Pipeline{ClozeDeletion}.Apply(data)
+	if err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	expected := []string{"1867年に大政奉還を行ったのは{{c1::徳川慶喜}}である。", "日本一高い山は何でしょう？"}
+	for i, question := range expected {
+		if result[i].Question != question {
+			t.Errorf("item %d question = %q, want %q", i+1, result[i].Question, question)
+		}
+	}
+	if data[0].Question != "1867年に大政奉還を行ったのは徳川慶喜である。" {
+		t.Errorf("ClozeDeletion modified the original data: %q", data[0].Question)
+	}
+
+	warnings := ClozeWarnings(data)
+	if len(warnings) != 1 || warnings[0].Index != 2 || warnings[0].Rule != RuleClozeNoMatch {
+		t.Errorf("ClozeWarnings() = %+v, want a warning for item 2", warnings)
+	}
+}
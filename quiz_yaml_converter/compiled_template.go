@@ -0,0 +1,95 @@
+// 解析済みのテンプレートを繰り返し使うための処理です．
+// 同じテンプレートで何度も変換するバッチ処理やサーバーで，テンプレートファイルの読み込みと解析を省きます．
+package quiz_yaml_converter
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"text/template"
+	"time"
+)
+
+// CompiledTemplate は解析済みのテンプレート．CompileTemplateで作成する．
+// 複数のゴルーチンから同時に使用できる．
+type CompiledTemplate struct {
+	tmpl *template.Template
+	opts ConvertOptions
+}
+
+// CompileTemplate はテンプレートファイル（opts.TemplateTextが指定されている場合はその内容）を解析する．
+// テンプレートの関数や実行の制限などはoptsで決まり，実行時にも同じoptsを使う．
+// opts.Layoutが指定されている場合は，レイアウトのブロックを置き換えるテンプレートとして解析する．
+func CompileTemplate(templateFilePath string, opts ConvertOptions) (*CompiledTemplate, error) {
+	tmpl, err := parseTemplateFile(templateFilePath, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &CompiledTemplate{tmpl: tmpl, opts: opts}, nil
+}
+
+// Execute は問題データをテンプレートに従って整形し，wに書き出す（WriteTemplateと同じ）．
+// opts.Pipelineは適用しない．
+func (t *CompiledTemplate) Execute(w io.Writer, data []QuizItem) error {
+	return executeTemplate(w, t.tmpl, data, t.opts)
+}
+
+// WriteFile は問題データをテンプレートに従って整形し，outputFilePathに書き出す（ConvertToTemplateWithOptionsと同じ）．
+func (t *CompiledTemplate) WriteFile(data []QuizItem, outputFilePath string) error {
+	return writeFileAtomic(outputFilePath, t.opts.NoClobber, func(w io.Writer) error {
+		return t.Execute(w, data)
+	})
+}
+
+// TemplateCache はテンプレートファイルのパスごとに解析済みのテンプレートを保持する．
+// ファイルの更新日時か大きさが変わった場合は解析し直す．複数のゴルーチンから同時に使用できる．
+// レイアウトファイル（opts.Layout）の変更は検出しない．
+type TemplateCache struct {
+	opts ConvertOptions
+
+	mu      sync.Mutex
+	entries map[string]templateCacheEntry
+}
+
+// templateCacheEntry は解析したときのファイルの状態と解析済みのテンプレート．
+type templateCacheEntry struct {
+	modTime time.Time
+	size    int64
+	tmpl    *CompiledTemplate
+}
+
+// NewTemplateCache はoptsでテンプレートを解析するTemplateCacheを作成する．
+// ファイルから読み込むため，opts.TemplateTextは使わない．
+func NewTemplateCache(opts ConvertOptions) *TemplateCache {
+	opts.TemplateText = ""
+	return &TemplateCache{opts: opts, entries: map[string]templateCacheEntry{}}
+}
+
+// Get はテンプレートファイルの解析済みのテンプレートを返す．
+// 初めて使うファイルと，前回の解析から変更されたファイルは読み込んで解析する．
+func (c *TemplateCache) Get(templateFilePath string) (*CompiledTemplate, error) {
+	// 組み込みのテンプレート（builtin:名前）は変更されないため，ファイルの状態を0とする
+	var modTime time.Time
+	var size int64
+	if !IsBuiltinTemplate(templateFilePath) {
+		info, err := os.Stat(templateFilePath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read template file: %w", err)
+		}
+		modTime, size = info.ModTime(), info.Size()
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, ok := c.entries[templateFilePath]; ok && e.modTime.Equal(modTime) && e.size == size {
+		return e.tmpl, nil
+	}
+	tmpl, err := CompileTemplate(templateFilePath, c.opts)
+	if err != nil {
+		delete(c.entries, templateFilePath)
+		return nil, err
+	}
+	c.entries[templateFilePath] = templateCacheEntry{modTime: modTime, size: size, tmpl: tmpl}
+	return tmpl, nil
+}
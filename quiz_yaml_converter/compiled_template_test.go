@@ -0,0 +1,148 @@
+package quiz_yaml_converter
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+func TestCompileTemplate(t *testing.T) {
+	tempDir := t.TempDir()
+	templateFile := filepath.Join(tempDir, "quiz.tmpl")
+	if err := os.WriteFile(templateFile, []byte(`{{range .Items}}{{.Question}}={{.Answer | addQuotes}};{{end}}`), 0644); err != nil {
+		t.Fatalf("Failed to create template file: %v", err)
+	}
+	tmpl, err := CompileTemplate(templateFile, ConvertOptions{})
+	if err != nil {
+		t.Fatalf("CompileTemplate() error = %v", err)
+	}
+	// 解析済みのテンプレートはファイルを読み直さない
+	if err := os.Remove(templateFile); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, data := range [][]QuizItem{
+		{{Question: "q1", Answer: "a1"}},
+		{{Question: "q2", Answer: "a2"}, {Question: "q3", Answer: "a3"}},
+	} {
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, data); err != nil {
+			t.Fatalf("Execute() error = %v", err)
+		}
+		var want bytes.Buffer
+		for _, item := range data {
+			want.WriteString(item.Question + "=「" + item.Answer + "」;")
+		}
+		if buf.String() != want.String() {
+			t.Errorf("Execute() = %q, want %q", buf.String(), want.String())
+		}
+	}
+
+	outputFile := filepath.Join(tempDir, "out.txt")
+	if err := tmpl.WriteFile([]QuizItem{{Question: "q", Answer: "a"}}, outputFile); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	if content, _ := os.ReadFile(outputFile); string(content) != "q=「a」;" {
+		t.Errorf("WriteFile() wrote %q", content)
+	}
+
+	if _, err := CompileTemplate("", ConvertOptions{TemplateText: "{{"}); !errors.Is(err, ErrTemplateParse) {
+		t.Errorf("CompileTemplate() error = %v, want ErrTemplateParse", err)
+	}
+}
+
+func TestCompiledTemplate_Concurrent(t *testing.T) {
+	tmpl, err := CompileTemplate("", ConvertOptions{TemplateText: `{{range .Items}}{{.Answer}}{{end}}`})
+	if err != nil {
+		t.Fatalf("CompileTemplate() error = %v", err)
+	}
+	var wg sync.WaitGroup
+	for i := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			answer := string(rune('a' + i))
+			var buf bytes.Buffer
+			if err := tmpl.Execute(&buf, []QuizItem{{Question: "q", Answer: answer}}); err != nil || buf.String() != answer {
+				t.Errorf("Execute() = %q, %v, want %q", buf.String(), err, answer)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestTemplateCache(t *testing.T) {
+	templateFile := filepath.Join(t.TempDir(), "quiz.tmpl")
+	write := func(content string) {
+		t.Helper()
+		if err := os.WriteFile(templateFile, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create template file: %v", err)
+		}
+	}
+	render := func(tmpl *CompiledTemplate) string {
+		t.Helper()
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, []QuizItem{{Question: "q", Answer: "a"}}); err != nil {
+			t.Fatalf("Execute() error = %v", err)
+		}
+		return buf.String()
+	}
+
+	cache := NewTemplateCache(ConvertOptions{TemplateText: "ignored"})
+	write(`{{range .Items}}{{.Question}}{{end}}`)
+	first, err := cache.Get(templateFile)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if got := render(first); got != "q" {
+		t.Errorf("Execute() = %q, want %q", got, "q")
+	}
+	if again, err := cache.Get(templateFile); err != nil || again != first {
+		t.Errorf("Get() = %p, %v, want the cached template %p", again, err, first)
+	}
+
+	// 変更されたファイルは解析し直す
+	write(`{{range .Items}}{{.Question}}/{{.Answer}}{{end}}`)
+	updated, err := cache.Get(templateFile)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if got := render(updated); got != "q/a" {
+		t.Errorf("Execute() = %q, want %q", got, "q/a")
+	}
+
+	write(`{{range .Items}`)
+	if _, err := cache.Get(templateFile); !errors.Is(err, ErrTemplateParse) {
+		t.Errorf("Get() error = %v, want ErrTemplateParse", err)
+	}
+	if _, err := cache.Get(filepath.Join(filepath.Dir(templateFile), "missing.tmpl")); err == nil {
+		t.Error("Get() should fail for a missing file")
+	}
+}
+
+func BenchmarkWriteTemplate(b *testing.B) {
+	templateFile := filepath.Join(b.TempDir(), "quiz.tmpl")
+	if err := os.WriteFile(templateFile, []byte(`{{range .Items}}{{.Question}}{{formatItemCriteria .QuizItem}}{{end}}`), 0644); err != nil {
+		b.Fatalf("Failed to create template file: %v", err)
+	}
+	data := []QuizItem{{Question: "q", Answer: "a", Criteria: map[string][]string{"ok": {"b"}}}}
+
+	b.Run("uncached", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			WriteTemplate(&bytes.Buffer{}, data, templateFile, ConvertOptions{})
+		}
+	})
+	b.Run("compiled", func(b *testing.B) {
+		tmpl, err := CompileTemplate(templateFile, ConvertOptions{})
+		if err != nil {
+			b.Fatal(err)
+		}
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			tmpl.Execute(&bytes.Buffer{}, data)
+		}
+	})
+}
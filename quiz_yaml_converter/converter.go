@@ -7,14 +7,17 @@
 package quiz_yaml_converter
 
 import (
-	"encoding/csv"
+	"bytes"
+	"errors"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"text/template"
 	"time"
+	"unicode/utf8"
 
 	"gopkg.in/yaml.v3"
 )
@@ -22,18 +25,192 @@ import (
 // 1問ごとのエントリを表す構造体
 // 問題文、答え、原語表記、コメント、および判定基準を含む。
 type QuizItem struct {
-	Question string              `yaml:"question"`           // 問題文
-	Answer   string              `yaml:"answer"`             // 答え
-	Spell    string              `yaml:"spell"`              // 原語表記（英語表記）
-	Tags     []string            `yaml:"tags,omitempty"`     // タグ
-	Comments []string            `yaml:"comments,omitempty"` // コメント
-	Criteria map[string][]string `yaml:"criteria,omitempty"` // 判定基準（ok/ng/repeat）
+	ID         string              `yaml:"id,omitempty" json:"id,omitempty"`                 // 問題ID
+	Question   string              `yaml:"question" json:"question"`                         // 問題文
+	Segments   []string            `yaml:"segments,omitempty" json:"segments,omitempty"`     // 問題文の区切り（早押しポイント）
+	Answer     string              `yaml:"answer" json:"answer"`                             // 答え
+	Reading    string              `yaml:"reading,omitempty" json:"reading,omitempty"`       // 答えの読み（仮名）
+	Spell      string              `yaml:"spell" json:"spell"`                               // 原語表記（英語表記）．複数言語の場合は最初の言語の表記
+	Spells     map[string]string   `yaml:"-" json:"spells,omitempty"`                        // 言語コードごとの原語表記（spellをマッピングで書いた場合）
+	Genre      string              `yaml:"genre,omitempty" json:"genre,omitempty"`           // ジャンル
+	Difficulty int                 `yaml:"difficulty,omitempty" json:"difficulty,omitempty"` // 難易度（数が大きいほど難しい．0は未設定）
+	Tags       []string            `yaml:"tags,omitempty" json:"tags,omitempty"`             // タグ
+	Comments   []string            `yaml:"comments,omitempty" json:"comments,omitempty"`     // コメント
+	Criteria   map[string][]string `yaml:"criteria,omitempty" json:"criteria,omitempty"`     // 判定基準（ok/ng/repeat）
+	Choices    []string            `yaml:"choices,omitempty" json:"choices,omitempty"`       // 多肢選択の選択肢（答えを含む）
+	Source     string              `yaml:"source,omitempty" json:"source,omitempty"`         // 出典（書籍・記事・Webサイトの名前など）
+	Reference  string              `yaml:"reference,omitempty" json:"reference,omitempty"`   // 出典の参照先（URLやページ番号など）
+	Author     string              `yaml:"author,omitempty" json:"author,omitempty"`         // 問題の作者
+	License    string              `yaml:"license,omitempty" json:"license,omitempty"`       // 問題のライセンス（例: CC BY 4.0）
+
+	// YAML上でcriteriaのキーが書かれていた順序．読み込み時にのみ設定され，
+	// 既定の順序（ok → ng → repeat）と同じ場合はnilのままとなる．
+	CriteriaOrder []string `yaml:"-" json:"-"`
+
+	// 複数のドキュメント（---区切り）を含むYAMLで，問題が含まれていたドキュメントの番号（1始まり）．
+	// 読み込み時にのみ設定され，ドキュメントが1つの場合は0のままとなる．
+	Document int `yaml:"-" json:"document,omitempty"`
+
+	// YAML上でspellの言語が書かれていた順序（SpellLanguages参照）
+	spellOrder []string
+}
+
+// UnmarshalYAML はQuizItemをデコードし，あわせてcriteriaのキー順序を記録する．
+// map[string][]stringへのデコードでは順序が失われるため，ノードから直接読み取る．
+// spellが言語コードから表記へのマッピングの場合は，Spellsと最初の言語の表記（Spell）を設定する．
+func (q *QuizItem) UnmarshalYAML(value *yaml.Node) error {
+	type rawQuizItem QuizItem
+	var raw rawQuizItem
+	rest, spells, spellOrder, err := extractSpells(value)
+	if err != nil {
+		return err
+	}
+	if err := rest.Decode(&raw); err != nil {
+		return err
+	}
+	*q = QuizItem(raw)
+	if spells != nil {
+		q.Spells = spells
+		q.spellOrder = spellOrder
+		q.Spell = q.primarySpell()
+	}
+	if order := criteriaKeyOrder(value); !isDefaultCriteriaOrder(order) {
+		q.CriteriaOrder = order
+	}
+	return nil
+}
+
+// MarshalYAML はcriteriaのキーをCriteriaOrder（未設定の場合は既定の順序）に
+// 従って並べて出力する．mapをそのまま出力するとキーがアルファベット順になり，
+// 書き出したYAMLを読み直したときに順序が変わってしまうため．
+// Spellsが設定されている場合，spellは言語コードから表記へのマッピングとして出力する．
+func (q QuizItem) MarshalYAML() (interface{}, error) {
+	type rawQuizItem QuizItem
+	var node yaml.Node
+	if err := node.Encode(rawQuizItem(q)); err != nil {
+		return nil, err
+	}
+	order := q.CriteriaOrder
+	if order == nil {
+		order = defaultCriteriaOrder
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		switch node.Content[i].Value {
+		case "criteria":
+			sortMappingKeys(node.Content[i+1], order)
+		case "spell":
+			if len(q.Spells) > 0 {
+				node.Content[i+1] = q.spellMappingNode()
+			}
+		}
+	}
+	return &node, nil
+}
+
+// criteriaKeyOrder は問題のマッピングノードからcriteriaのキー順序を取り出す．
+func criteriaKeyOrder(item *yaml.Node) []string {
+	if item.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(item.Content); i += 2 {
+		if item.Content[i].Value != "criteria" {
+			continue
+		}
+		criteria := item.Content[i+1]
+		if criteria.Kind != yaml.MappingNode {
+			return nil
+		}
+		var order []string
+		for j := 0; j+1 < len(criteria.Content); j += 2 {
+			order = append(order, criteria.Content[j].Value)
+		}
+		return order
+	}
+	return nil
+}
+
+// isDefaultCriteriaOrder はキーの並びが既定の順序と矛盾しないかを返す．
+func isDefaultCriteriaOrder(order []string) bool {
+	last := -1
+	for _, key := range order {
+		pos := -1
+		for i, def := range defaultCriteriaOrder {
+			if key == def {
+				pos = i
+			}
+		}
+		if pos < last {
+			return false
+		}
+		last = pos
+	}
+	return true
+}
+
+// sortMappingKeys はマッピングノードのキーをorderの順に並べ替える．
+// orderに含まれないキーは元の順序のまま末尾に残す．
+func sortMappingKeys(mapping *yaml.Node, order []string) {
+	if mapping.Kind != yaml.MappingNode {
+		return
+	}
+	rank := func(key string) int {
+		for i, k := range order {
+			if k == key {
+				return i
+			}
+		}
+		return len(order)
+	}
+	type pair struct{ key, value *yaml.Node }
+	pairs := make([]pair, 0, len(mapping.Content)/2)
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		pairs = append(pairs, pair{mapping.Content[i], mapping.Content[i+1]})
+	}
+	sort.SliceStable(pairs, func(i, j int) bool {
+		return rank(pairs[i].key.Value) < rank(pairs[j].key.Value)
+	})
+	mapping.Content = mapping.Content[:0]
+	for _, p := range pairs {
+		mapping.Content = append(mapping.Content, p.key, p.value)
+	}
 }
 
 // テンプレート処理用のデータ構造体
 // 問題データのリストを含む。
 type TemplateData struct {
-	Items []QuizItem // 問題データのリスト
+	Items   []QuizItem // 問題データのリスト
+	Numbers []string   // 各問題の番号（Itemsと同じ順序）
+
+	Vars map[string]string // 変換時に指定した変数（ConvertOptions.Vars）
+
+	ShowGojuonIndex bool // 組み込みのHTMLで五十音順の索引（GojuonIndex）を出力するか（ConvertOptions.GojuonIndex）
+	ShowGlossary    bool // 組み込みのテンプレートで用語集（テンプレート関数glossary）を出力するか（ConvertOptions.Glossary.Enabled）
+
+	// ページ分割して出力する場合のみ設定される（ConvertToPaginatedHTML参照）
+	Page  *PageInfo  // 出力中のページ（各ページ）
+	Pages []PageInfo // すべてのページ（目次ページ）
+
+	// 問題用紙と解答を組にして出力する場合のみ設定される（ConvertToAnswerKeyPair参照）
+	Pair *SheetPair
+}
+
+// TemplateItem はレイアウトのitemブロックに渡す1問分のデータ．
+// QuizItemのフィールドに加えて，問題の位置と番号を参照できる．
+type TemplateItem struct {
+	QuizItem
+	Index  int               // Itemsの中での位置（0始まり）
+	Number string            // 問題番号
+	Vars   map[string]string // 変換時に指定した変数（TemplateData.Varsと同じ）
+}
+
+// Item はi番目の問題をTemplateItemとして返す．
+// テンプレートでは{{block "item" ($.Item $index)}}のように使う．
+func (td TemplateData) Item(i int) TemplateItem {
+	item := TemplateItem{QuizItem: td.Items[i], Index: i, Vars: td.Vars}
+	if i < len(td.Numbers) {
+		item.Number = td.Numbers[i]
+	}
+	return item
 }
 
 // 出力される文字列
@@ -45,73 +222,194 @@ const (
 	FormatTemplate OutputFormat = "template" // テンプレート形式
 )
 
+// 変換処理のオプション
+type ConvertOptions struct {
+	// trueの場合，正誤判定をYAMLに書かれたキー順序で出力する．
+	// falseの場合は常にok → ng → repeatの順で出力する．
+	PreserveCriteriaOrder bool
+
+	// 正誤判定を出力するときの語句（「は誤答」など）と引用符の言語．空の場合は日本語とする．
+	// LanguageEnglishの場合は"X" is incorrect / "Y" — ask againのように出力する．
+	CriteriaLanguage Language
+
+	// 正誤判定とテンプレート関数addQuotesで項目を囲む開き・閉じの引用符（ParseQuotes参照）．
+	// 空の場合はCriteriaLanguageの引用符（日本語では「」）を使う．
+	Quotes [2]string
+
+	// 問題文の読み上げ時間の見積もり（ReadingTime）に使う読み上げの速さ（1秒あたりのモーラ数）．
+	// 0の場合はDefaultReadingPaceとする．
+	ReadingPace float64
+
+	// テンプレート関数qrURLで問題ごとのQRコードに埋め込むURLの書式．"{id}"を問題ID（idがなければContentID）に置き換える．
+	// 空の場合は問題IDそのものを埋め込む（QRURL参照）．
+	QRURL string
+
+	// テンプレート関数slugで問題のスラッグを作るときに元にする値（ItemSlug参照）．空の場合はSlugFromIDとする．
+	SlugFrom SlugSource
+
+	// テンプレート関数rubyとfuriganaで読みを表示する方法．空の場合はRubyTagsとする．
+	RubyStyle RubyStyle
+
+	// trueの場合，出力ファイルが既に存在するときは上書きせずにErrOutputExistsを返す．
+	NoClobber bool
+
+	// trueの場合，IDが未設定の問題にContentIDを割り当てて出力する．
+	// CSVで列を指定していない場合は先頭にid列を追加する．
+	AssignIDs bool
+
+	// trueの場合，組み込みのHTMLのテンプレートとページ分割の目次に，答えの読みの五十音順の索引（TemplateData.GojuonIndex）を出力する．
+	GojuonIndex bool
+
+	// trueの場合，ConvertFilesWithOptionsは出力の前にValidateYAMLFilesで入力ファイルをバリデーションし，
+	// エラーがある場合は何も書き出さずにValidationResult.Errのエラーを返す．
+	ValidateFirst bool
+
+	// テンプレートに渡す問題番号の付け方
+	Numbering NumberingOptions
+
+	// 用語集（テンプレート関数glossary）の作成に関するオプション
+	Glossary GlossaryOptions
+
+	// CSV出力に関するオプション
+	CSV CSVOptions
+
+	// テキスト出力（-format text）に関するオプション
+	Text TextOptions
+
+	// 出力形式の名前（RegisterFormatterで登録したもの，または"exec:コマンド"）．
+	// 空の場合は出力ファイルの拡張子から判断する．
+	Format string
+
+	// 読み込んだ問題データに出力の前に適用する処理（ConvertItems，ConvertToPaginatedHTMLで使用）
+	Pipeline Pipeline
+
+	// テンプレートファイルの基にするレイアウト（組み込みのhtml, markdown，またはファイルのパス）．
+	// 指定した場合，テンプレートファイルはレイアウトのブロックを置き換える定義として扱う．
+	Layout string
+
+	// テンプレートに{{.Vars.名前}}として渡す変数（大会名や日付など）
+	Vars map[string]string
+
+	// テンプレートの内容．空でない場合はテンプレートファイルを読み込まずにこの内容を使い，
+	// templateFilePathはテンプレートの名前としてのみ扱う（標準入力や文字列で渡したテンプレートに使う）．
+	TemplateText string
+
+	// テンプレートファイルとレイアウトファイルの左右の区切り文字（ParseTemplateDelims参照）．
+	// 空の場合は{{と}}となる．組み込みのテンプレートとレイアウトには適用しない．
+	TemplateDelims [2]string
+
+	// テンプレートの1回の実行の制限時間（0は無制限）．超えた場合はErrTemplateTimeoutを返す．
+	// 制限時間を超えた実行は取り消されず，以降の出力を捨てて待つのをやめるだけである．
+	// 何も書き出さずにループし続けるテンプレートは，変換から戻った後もゴルーチンでCPUを使い続ける．
+	// 実行を確実に止める必要がある場合は，別のプロセスで変換してプロセスごと終了させる．
+	TemplateTimeout time.Duration
+
+	// テンプレートの1回の実行で書き出す最大バイト数（0は無制限）．超えた場合はErrOutputTooLargeを返す．
+	// Converterではテンプレート以外の出力形式にも適用する．
+	MaxOutputBytes int64
+
+	// trueの場合，利用者のテンプレートとレイアウトファイルではsafeTemplateFuncsの関数だけを使えるようにする．
+	// 実行環境によって結果が変わる関数（nowなど）は使えず，使っているテンプレートは解析時にErrTemplateParseとなる．
+	// 組み込みのテンプレートとレイアウトはこれまでどおりすべての関数を使う．
+	// 使える関数を限定するだけで，テンプレートを隔離して実行するものではない（実行時間はTemplateTimeout参照）．
+	SafeTemplates bool
+}
+
 // 必要に応じて「」を追加する．
 //
 // - 既に"「"で始まり"」"で終わっている場合はそのまま返す
 // - "「"で始まっているが"」"で終わっていない場合
 //   - "」"がどこかに含まれている場合はそのまま返す
 //   - 含まれていない場合は"」"を追加して返す
+// - "「"で始まっていないが途中に"「"を含む場合（A「B」Cなど）は"『"と"』"で囲んで返す（NestQuotes参照）
 // - "」"を含むが"「"で始まっていない場合は"「"を追加して返す
 // - どちらも含まれていない場合は"「"と"」"を最初と最後に追加して返す．
 func AddQuotesIfNeeded(item string) string {
-	// Already starts with 「 and ends with 」
-	if strings.HasPrefix(item, "「") && strings.HasSuffix(item, "」") {
-		return item
-	}
-	// Starts with 「 but doesn't end with 」
-	if strings.HasPrefix(item, "「") {
-		// Contains 」 somewhere (like 「美術館」（おまけ））
-		if strings.Contains(item, "」") {
-			return item
-		} else {
-			// Starts with 「 but no 」, add 」
-			return item + "」"
-		}
-	}
-	// Ends with 」 but doesn't start with 「
-	if !strings.HasPrefix(item, "「") && strings.HasSuffix(item, "」") {
-		return "「" + item
-	}
-	// No quotes at all, add both
-	return "「" + item + "」"
+	return NestQuotes(item, japaneseQuotes, japaneseNestedQuotes)
 }
 
 // 正誤判定の文字列をフォーマットするための補助関数
-// 別解などの単語に適切に「」を追加して羅列し，最後に指定された文字列を追加する．
-func formatCriteriaSection(items []string, suffix string) string {
+// 別解などの単語に適切に引用符（日本語では「」）を追加して羅列し，最後に指定された文字列を追加する．
+func formatCriteriaSection(items []string, suffix string, p criteriaPhrases) string {
 	if len(items) == 0 {
 		return ""
 	}
 
 	var formattedItems []string
 	for _, item := range items {
-		formattedItems = append(formattedItems, AddQuotesIfNeeded(item))
+		formattedItems = append(formattedItems, NestQuotes(item, p.quotes, p.nested))
 	}
-	return strings.Join(formattedItems, "") + suffix
+	return strings.Join(formattedItems, p.join) + suffix
+}
+
+// 正誤判定のキーと，その後ろに付ける文字列の対応．
+var criteriaSuffixes = map[string]string{
+	"ok":     "",
+	"ng":     "は誤答",
+	"repeat": "はもう一度",
 }
 
+// 既定の正誤判定の出力順序．
+var defaultCriteriaOrder = []string{"ok", "ng", "repeat"}
+
 // 正誤判定のフォーマットを行う．
 //
 // 形式は「別解1」「別解2」／「誤答1」は誤答／「もう一度1」はもう一度という形式で返す．
 func FormatCriteria(criteria map[string][]string) string {
+	return FormatCriteriaInOrder(criteria, defaultCriteriaOrder)
+}
+
+// 指定されたキー順序で正誤判定のフォーマットを行う．
+// orderに含まれないok/ng/repeatのキーは，既定の順序で末尾に出力する．
+func FormatCriteriaInOrder(criteria map[string][]string, order []string) string {
+	return formatCriteria(criteria, order, criteriaPhrasesFor(LanguageJapanese), "")
+}
+
+// 既定の正誤判定の区切り文字
+const defaultCriteriaSeparator = "／"
+
+// formatCriteria はFormatCriteriaInOrderと同じ順序で，pの語句を使って正誤判定をフォーマットする．
+// itemSepが空の場合は各項目を引用符で囲んで羅列し，空でない場合は引用符で囲まずにitemSepでつなぐ．
+func formatCriteria(criteria map[string][]string, order []string, p criteriaPhrases, itemSep string) string {
 	var parts []string
+	seen := map[string]bool{}
 
-	// Process ok
-	if ok, exists := criteria["ok"]; exists && len(ok) > 0 {
-		parts = append(parts, formatCriteriaSection(ok, ""))
+	emit := func(key string) {
+		items := criteria[key]
+		suffix, known := p.suffix(key, len(items))
+		if !known || seen[key] {
+			return
+		}
+		seen[key] = true
+		if len(items) > 0 {
+			if itemSep == "" {
+				parts = append(parts, formatCriteriaSection(items, suffix, p))
+			} else {
+				parts = append(parts, strings.Join(items, itemSep)+suffix)
+			}
+		}
 	}
 
-	// Process ng
-	if ng, exists := criteria["ng"]; exists && len(ng) > 0 {
-		parts = append(parts, formatCriteriaSection(ng, "は誤答"))
+	for _, key := range order {
+		emit(key)
 	}
-
-	// Process repeat
-	if repeat, exists := criteria["repeat"]; exists && len(repeat) > 0 {
-		parts = append(parts, formatCriteriaSection(repeat, "はもう一度"))
+	for _, key := range defaultCriteriaOrder {
+		emit(key)
 	}
 
-	return strings.Join(parts, "／")
+	return strings.Join(parts, p.separator)
+}
+
+// オプションに従って問題の正誤判定をフォーマットする．
+func formatItemCriteria(item QuizItem, opts ConvertOptions) string {
+	if item.Criteria == nil {
+		return ""
+	}
+	order := defaultCriteriaOrder
+	if opts.PreserveCriteriaOrder {
+		order = item.CriteriaOrder
+	}
+	return formatCriteria(item.Criteria, order, opts.criteriaPhrases(), "")
 }
 
 // 出力されるファイルのフォーマットを返す．
@@ -143,110 +441,292 @@ func LoadYAMLData(yamlFilePath string) ([]QuizItem, error) {
 		return nil, fmt.Errorf("failed to read YAML file: %w", err)
 	}
 
+	return ParseYAMLData(yamlData)
+}
+
+// LoadYAMLFiles は複数のYAMLファイルを読み込み，指定した順に連結した問題データを返す．
+// エラーにはどのファイルで発生したかが含まれる．
+func LoadYAMLFiles(yamlFilePaths []string) ([]QuizItem, error) {
+	if len(yamlFilePaths) == 1 {
+		return LoadYAMLData(yamlFilePaths[0])
+	}
 	var data []QuizItem
-	err = yaml.Unmarshal(yamlData, &data)
+	for _, path := range yamlFilePaths {
+		items, err := LoadYAMLData(path)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		data = append(data, items...)
+	}
+	return data, nil
+}
+
+// メモリ上のYAMLデータを問題データとして解析する．
+// 先頭にUTF-8のBOMがある場合は取り除いてから解析する．
+// ---で区切られた複数のドキュメントを含む場合は，すべてのドキュメントの問題を順に連結し，
+// 各問題のDocumentにドキュメントの番号を設定する．
+func ParseYAMLData(yamlData []byte) ([]QuizItem, error) {
+	documents, err := ParseYAMLDocuments(yamlData)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse YAML: %w", err)
+		return nil, err
+	}
+	switch len(documents) {
+	case 0:
+		return nil, nil
+	case 1:
+		return documents[0], nil
 	}
 
+	var data []QuizItem
+	for i, items := range documents {
+		for j := range items {
+			items[j].Document = i + 1
+		}
+		data = append(data, items...)
+	}
 	return data, nil
 }
 
+// ParseYAMLDocuments はメモリ上のYAMLデータをドキュメントごとの問題データとして解析する．
+// 各ドキュメントは問題のリスト，またはジャンル名から問題のリストへのマッピングとする（decodeQuizItems参照）．
+// 問題を含まない空のドキュメント（末尾の---や，metadataだけのドキュメントなど）は無視する．
+func ParseYAMLDocuments(yamlData []byte) ([][]QuizItem, error) {
+	decoder := yaml.NewDecoder(bytes.NewReader(bytes.TrimPrefix(yamlData, []byte(utf8BOM))))
+	var documents [][]QuizItem
+	var meta FileMetadata
+	for {
+		var node yaml.Node
+		if err := decoder.Decode(&node); err != nil {
+			if errors.Is(err, io.EOF) {
+				return documents, nil
+			}
+			return nil, fmt.Errorf("%w: %w", ErrInvalidYAML, err)
+		}
+		items, err := decodeQuizItems(&node, &meta)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidYAML, err)
+		}
+		if len(items) > 0 {
+			documents = append(documents, items)
+		}
+	}
+}
+
 // ValidationResult はバリデーション結果を表す構造体
 type ValidationResult struct {
-	IsValid bool     // バリデーションが成功したかどうか
-	Errors  []string // エラーメッセージのリスト
-	Items   int      // 読み込まれたアイテム数
+	IsValid          bool              // バリデーションが成功したかどうか
+	Errors           []string          // エラーメッセージのリスト
+	ValidationErrors []ValidationError // エラーの詳細のリスト（Errorsと同じ順序）
+	Warnings         []ValidationError // 変換には影響しない警告のリスト（IsValidには影響しない）
+	Items            int               // 読み込まれたアイテム数
+}
+
+// AddError はバリデーションエラーを結果に追加し，IsValidをfalseにする．
+// -require-sourcesのような，ValidateYAMLFilesの後に行う追加のチェックの結果を加えるのに使う．
+func (r *ValidationResult) AddError(e ValidationError) {
+	r.IsValid = false
+	r.ValidationErrors = append(r.ValidationErrors, e)
+	r.Errors = append(r.Errors, e.Error())
+}
+
+// Err はバリデーションエラーをまとめたerrorを返す．成功した場合はnilを返す．
+// 返されるエラーに対してerrors.Is(err, ErrValidation)はtrueとなり，
+// errors.Asで個々の*ValidationErrorを取り出すことができる．
+func (r ValidationResult) Err() error {
+	if r.IsValid {
+		return nil
+	}
+	errs := make([]error, 0, len(r.ValidationErrors))
+	for i := range r.ValidationErrors {
+		errs = append(errs, &r.ValidationErrors[i])
+	}
+	if len(errs) == 0 {
+		return ErrValidation
+	}
+	return errors.Join(errs...)
 }
 
 // ValidateYAMLFile はYAMLファイルの構造と内容をバリデーションする
 func ValidateYAMLFile(yamlFilePath string) ValidationResult {
-	result := ValidationResult{
-		IsValid: true,
-		Errors:  []string{},
-		Items:   0,
+	return ValidateYAMLFiles([]string{yamlFilePath})
+}
+
+// ValidateYAMLFiles は複数のYAMLファイルを順に連結した問題データをバリデーションする．
+// 問題の番号はファイルをまたいで通し番号となる．読み込めないファイルがある場合は
+// 内容のバリデーションは行わない．先頭にBOMがあるファイルはWarningsで報告する．
+func ValidateYAMLFiles(yamlFilePaths []string) ValidationResult {
+	v := newYAMLValidation()
+	for _, path := range yamlFilePaths {
+		// ファイルの存在確認
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			v.result.AddError(newValidationError(0, "", RuleFileNotFound, err, "ファイルが存在しません: %s", path))
+			continue
+		}
+
+		// YAMLデータの読み込み
+		content, err := os.ReadFile(path)
+		if err != nil {
+			err = fmt.Errorf("failed to read YAML file: %w", err)
+		}
+		v.add(path, content, err, len(yamlFilePaths) > 1)
 	}
+	return v.finish()
+}
 
-	// ファイルの存在確認
-	if _, err := os.Stat(yamlFilePath); os.IsNotExist(err) {
-		result.IsValid = false
-		result.Errors = append(result.Errors, fmt.Sprintf("ファイルが存在しません: %s", yamlFilePath))
-		return result
+// ValidateYAML はメモリ上のYAMLデータの構造と内容をバリデーションする．
+// サーバーやWASMのように内容を既に受け取っている場合に，一時ファイルに書き出さずに使う．
+// 結果はValidateYAMLFileと同じで，エラーと警告のFileは空となる．
+func ValidateYAML(data []byte) ValidationResult {
+	v := newYAMLValidation()
+	v.add("", data, nil, false)
+	return v.finish()
+}
+
+// ValidateReader はrから読み込んだYAMLデータの構造と内容をバリデーションする．
+// 読み込みに失敗した場合はRuleReadのエラーを返す．
+func ValidateReader(r io.Reader) ValidationResult {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		err = fmt.Errorf("failed to read YAML data: %w", err)
 	}
+	v := newYAMLValidation()
+	v.add("", data, err, false)
+	return v.finish()
+}
+
+// yamlValidation はYAMLデータを順に読み込みながらバリデーションする途中の状態．
+type yamlValidation struct {
+	result    ValidationResult
+	data      []QuizItem
+	warnings  []ValidationError
+	locations []itemLocation
+}
+
+func newYAMLValidation() *yamlValidation {
+	return &yamlValidation{result: ValidationResult{IsValid: true, Errors: []string{}}}
+}
 
-	// YAMLデータの読み込み
-	data, err := LoadYAMLData(yamlFilePath)
+// add は読み込んだYAMLデータを解析して問題を加える．pathはファイルのパス（メモリ上のデータの場合は空），
+// errは読み込みのエラーで，解析できない場合はエラーを記録する．
+// multipleの場合は，どのファイルのエラーかわかるようエラーにパスを付ける．
+func (v *yamlValidation) add(path string, content []byte, err error, multiple bool) {
+	if err == nil && bytes.HasPrefix(content, []byte(utf8BOM)) {
+		w := newValidationError(0, "", RuleBOM, nil, "ファイルの先頭にBOMがあります（取り除いて読み込みました）: %s", path)
+		if path == "" {
+			w = newValidationError(0, "", RuleBOM, nil, "YAMLデータの先頭にBOMがあります（取り除いて読み込みました）")
+		}
+		w.File, w.Line = path, 1
+		v.warnings = append(v.warnings, w)
+	}
+	var items []QuizItem
+	if err == nil {
+		items, err = ParseYAMLData(content)
+	}
 	if err != nil {
-		result.IsValid = false
-		result.Errors = append(result.Errors, fmt.Sprintf("YAMLファイルの読み込みエラー: %v", err))
-		return result
+		if multiple {
+			err = fmt.Errorf("%s: %w", path, err)
+		}
+		e := newValidationError(0, "", RuleRead, err, "YAMLファイルの読み込みエラー: %v", err)
+		if errors.Is(err, ErrInvalidYAML) {
+			e.Rule, e.Line = RuleSyntax, errorLine(err)
+		}
+		e.File = path
+		v.result.AddError(e)
+		return
 	}
+	v.data = append(v.data, items...)
 
-	result.Items = len(data)
+	// エラーの位置を示すため，各問題の開始行を記録する
+	lines := yamlItemLines(content)
+	for i := range items {
+		location := itemLocation{file: path}
+		if len(lines) == len(items) {
+			location.line = lines[i]
+		}
+		v.locations = append(v.locations, location)
+	}
+}
+
+// finish は読み込めたすべての問題の内容をバリデーションし，結果を返す．
+// 読み込めなかったデータがある場合は内容のバリデーションは行わない．
+func (v *yamlValidation) finish() ValidationResult {
+	if !v.result.IsValid {
+		v.result.Warnings = v.warnings
+		return v.result
+	}
+	result := ValidateItems(v.data)
+	locate(result.ValidationErrors, v.locations)
+	locate(result.Warnings, v.locations)
+	result.Warnings = append(v.warnings, result.Warnings...)
+	return result
+}
+
+// ValidateItems は読み込み済みの問題データの内容をバリデーションする．
+// 空白や不可視文字についての警告（WhitespaceWarnings）はWarningsに入る．
+func ValidateItems(data []QuizItem) ValidationResult {
+	result := ValidationResult{
+		IsValid: true,
+		Errors:  []string{},
+		Items:   len(data),
+	}
 
 	// 各アイテムのバリデーション
 	for i, item := range data {
-		itemErrors := validateQuizItem(item, i+1)
-		if len(itemErrors) > 0 {
-			result.IsValid = false
-			result.Errors = append(result.Errors, itemErrors...)
+		for _, e := range validateQuizItem(item, i+1) {
+			result.AddError(e)
 		}
 	}
 
 	// 配列が空でないことを確認
 	if len(data) == 0 {
-		result.IsValid = false
-		result.Errors = append(result.Errors, "YAMLファイルにクイズデータが含まれていません")
+		result.AddError(newValidationError(0, "", RuleNoItems, nil, "YAMLファイルにクイズデータが含まれていません"))
 	}
 
+	result.Warnings = WhitespaceWarnings(data)
 	return result
 }
 
 // validateQuizItem は個々のクイズアイテムをバリデーションする
-func validateQuizItem(item QuizItem, index int) []string {
-	var errors []string
-	prefix := fmt.Sprintf("問題 %d: ", index)
+func validateQuizItem(item QuizItem, index int) []ValidationError {
+	var errors []ValidationError
+	add := func(field, rule, format string, args ...any) {
+		errors = append(errors, newValidationError(index, field, rule, nil, format, args...))
+	}
 
 	// 必須フィールドのチェック
 	if strings.TrimSpace(item.Question) == "" {
-		errors = append(errors, prefix+"問題文 (question) が空です")
+		add("question", RuleRequired, "問題文 (question) が空です")
 	}
 
 	if strings.TrimSpace(item.Answer) == "" {
-		errors = append(errors, prefix+"答え (answer) が空です")
+		add("answer", RuleRequired, "答え (answer) が空です")
 	}
 
+	// 問題文の区切りのバリデーション
+	errors = append(errors, validateSegments(item, index)...)
+
+	// 複数言語の原語表記のバリデーション
+	errors = append(errors, validateSpells(item, index)...)
+
 	// criteriaフィールドのバリデーション
 	if item.Criteria != nil {
-		if ok, exists := item.Criteria["ok"]; exists {
-			for j, answer := range ok {
-				if strings.TrimSpace(answer) == "" {
-					errors = append(errors, fmt.Sprintf("%scriteria.ok[%d] が空です", prefix, j))
-				}
-			}
-		}
-
-		if ng, exists := item.Criteria["ng"]; exists {
-			for j, answer := range ng {
+		for _, key := range defaultCriteriaOrder {
+			for j, answer := range item.Criteria[key] {
 				if strings.TrimSpace(answer) == "" {
-					errors = append(errors, fmt.Sprintf("%scriteria.ng[%d] が空です", prefix, j))
+					field := fmt.Sprintf("criteria.%s[%d]", key, j)
+					add(field, RuleEmpty, "%s が空です", field)
 				}
 			}
 		}
 
-		if repeat, exists := item.Criteria["repeat"]; exists {
-			for j, answer := range repeat {
-				if strings.TrimSpace(answer) == "" {
-					errors = append(errors, fmt.Sprintf("%scriteria.repeat[%d] が空です", prefix, j))
-				}
-			}
-		}
+		// 答えと正誤判定の矛盾のチェック
+		errors = append(errors, validateCriteriaConflicts(item, index)...)
 
 		// 不正なcriteriaキーのチェック
 		validKeys := map[string]bool{"ok": true, "ng": true, "repeat": true}
 		for key := range item.Criteria {
 			if !validKeys[key] {
-				errors = append(errors, fmt.Sprintf("%s不正なcriteriaキー: '%s' (使用可能: ok, ng, repeat)", prefix, key))
+				add("criteria."+key, RuleCriteriaKey, "不正なcriteriaキー: '%s' (使用可能: ok, ng, repeat)", key)
 			}
 		}
 	}
@@ -254,36 +734,116 @@ func validateQuizItem(item QuizItem, index int) []string {
 	// commentsフィールドのバリデーション
 	for j, comment := range item.Comments {
 		if strings.TrimSpace(comment) == "" {
-			errors = append(errors, fmt.Sprintf("%scomments[%d] が空です", prefix, j))
+			field := fmt.Sprintf("comments[%d]", j)
+			add(field, RuleEmpty, "%s が空です", field)
 		}
 	}
 
 	// tagsフィールドのバリデーション
 	for j, tag := range item.Tags {
 		if strings.TrimSpace(tag) == "" {
-			errors = append(errors, fmt.Sprintf("%stags[%d] が空です", prefix, j))
+			field := fmt.Sprintf("tags[%d]", j)
+			add(field, RuleEmpty, "%s が空です", field)
 		}
 	}
 
+	// choicesフィールドのバリデーション
+	errors = append(errors, validateChoices(item, index)...)
+
 	return errors
 }
-// 問題データとテンプレートファイルから出力ファイルを生成する．
-// テンプレートはGoのtext/templateパッケージを使用し，日本語クイズフォーマット用のカスタム関数を提供する．
-func ConvertToTemplate(data []QuizItem, templateFilePath, outputFilePath string) error {
-	// Read template file
-	templateContent, err := os.ReadFile(templateFilePath)
-	if err != nil {
-		return fmt.Errorf("failed to read template file: %w", err)
-	}
 
-	// Create template with custom functions
-	tmpl, err := template.New("quiz").Funcs(template.FuncMap{
-		"formatCriteria": FormatCriteria,
-		"addQuotes":      AddQuotesIfNeeded,
-		"join":           strings.Join,
-		"upper":          strings.ToUpper,
-		"lower":          strings.ToLower,
-		"replace":        strings.ReplaceAll,
+// テンプレートで利用できるカスタム関数を返す．
+func templateFuncs(opts ConvertOptions) template.FuncMap {
+	return template.FuncMap{
+		"formatCriteria": func(criteria map[string][]string) string {
+			return formatCriteria(criteria, defaultCriteriaOrder, opts.criteriaPhrases(), "")
+		},
+		"formatCriteriaInOrder": func(criteria map[string][]string, order []string) string {
+			return formatCriteria(criteria, order, opts.criteriaPhrases(), "")
+		},
+		"formatItemCriteria": func(v any) (string, error) {
+			item, err := templateQuizItem(v)
+			return formatItemCriteria(item, opts), err
+		},
+		"segments": func(v any) ([]string, error) {
+			item, err := templateQuizItem(v)
+			return QuestionSegments(item), err
+		},
+		"plainQuestion": func(v any) (string, error) {
+			item, err := templateQuizItem(v)
+			return PlainQuestion(item), err
+		},
+		"addQuotes": func(item string) string {
+			p := opts.criteriaPhrases()
+			return NestQuotes(item, p.quotes, p.nested)
+		},
+		"nestQuotes": AddQuotesIfNeeded,
+		"quoteWith": func(open, close, item string) string {
+			return AddQuotesWith(item, open, close)
+		},
+		"ruby": func(text, reading string) string {
+			return Ruby(text, reading, opts.RubyStyle)
+		},
+		"furigana": func(text string) string {
+			return Furigana(text, opts.RubyStyle)
+		},
+		"tateChuYoko": TateChuYoko,
+		"runeCount":   utf8.RuneCountInString,
+		"moraCount":   MoraCount,
+		"maskAnswer":  MaskAnswer,
+		"percent":     formatPercent,
+		"toRomaji": func(s string, style ...string) (string, error) {
+			var name string
+			if len(style) > 0 {
+				name = style[0]
+			}
+			romajiStyle, err := ParseRomajiStyle(name)
+			if err != nil {
+				return "", err
+			}
+			return ToRomaji(s, romajiStyle), nil
+		},
+		"join":    strings.Join,
+		"upper":   strings.ToUpper,
+		"lower":   strings.ToLower,
+		"replace": strings.ReplaceAll,
+		"readingTime": func(v any) (time.Duration, error) {
+			item, err := templateQuizItem(v)
+			return ReadingTime(item, opts.ReadingPace), err
+		},
+		"totalReadingTime": func(items []QuizItem) time.Duration {
+			return TotalReadingTime(items, opts.ReadingPace)
+		},
+		"glossary": func(td TemplateData) []GlossaryEntry {
+			return BuildGlossary(td.Items, td.Numbers, opts.Glossary)
+		},
+		"formatDuration": FormatDuration,
+		"slug": func(v any) (string, error) {
+			if s, ok := v.(string); ok {
+				return Slug(s), nil
+			}
+			item, err := templateQuizItem(v)
+			return ItemSlug(item, opts.SlugFrom), err
+		},
+		"qrURL": func(v any) (string, error) {
+			item, err := templateQuizItem(v)
+			return QRURL(item, opts.QRURL), err
+		},
+		"qrSVG": func(text string) (string, error) {
+			q, err := EncodeQR(text)
+			if err != nil {
+				return "", err
+			}
+			return q.SVG(), nil
+		},
+		"qrDataURI": func(text string) (string, error) {
+			q, err := EncodeQR(text)
+			if err != nil {
+				return "", err
+			}
+			return q.DataURI()
+		},
 		"add": func(a, b int) int {
 			return a + b
 		},
@@ -293,83 +853,229 @@ func ConvertToTemplate(data []QuizItem, templateFilePath, outputFilePath string)
 		"now": func() string {
 			return time.Now().Format("2006年01月02日 15:04:05")
 		},
-	}).Parse(string(templateContent))
-	if err != nil {
-		return fmt.Errorf("failed to parse template: %w", err)
 	}
+}
 
-	// Create output file
-	outputFile, err := os.Create(outputFilePath)
-	if err != nil {
-		return fmt.Errorf("failed to create output file: %w", err)
-	}
-	defer outputFile.Close()
+// 問題データとテンプレートファイルから出力ファイルを生成する．
+// テンプレートはGoのtext/templateパッケージを使用し，日本語クイズフォーマット用のカスタム関数を提供する．
+func ConvertToTemplate(data []QuizItem, templateFilePath, outputFilePath string) error {
+	return ConvertToTemplateWithOptions(data, templateFilePath, outputFilePath, ConvertOptions{})
+}
 
-	// Execute template
-	templateData := TemplateData{Items: data}
-	err = tmpl.Execute(outputFile, templateData)
+// オプションを指定して問題データとテンプレートファイルから出力ファイルを生成する．
+func ConvertToTemplateWithOptions(data []QuizItem, templateFilePath, outputFilePath string, opts ConvertOptions) error {
+	tmpl, err := CompileTemplate(templateFilePath, opts)
 	if err != nil {
-		return fmt.Errorf("failed to execute template: %w", err)
+		return err
 	}
 
-	return nil
+	// Execute template into a temporary file and replace the output file
+	return tmpl.WriteFile(data, outputFilePath)
 }
 
-// YAMLファイルをCSVファイルに変換する．
-// CSV出力には問題文、答え、原語表記、およびフォーマットされた正誤判定が含まれる．
-func ConvertYAMLToCSV(yamlFilePath, csvFilePath string) error {
-	data, err := LoadYAMLData(yamlFilePath)
+// WriteTemplate は問題データをテンプレートファイルに従って整形し，wに書き出す．
+func WriteTemplate(w io.Writer, data []QuizItem, templateFilePath string, opts ConvertOptions) error {
+	tmpl, err := CompileTemplate(templateFilePath, opts)
 	if err != nil {
 		return err
 	}
+	return tmpl.Execute(w, data)
+}
 
-	// Create CSV file
-	csvFile, err := os.Create(csvFilePath)
-	if err != nil {
-		return fmt.Errorf("failed to create CSV file: %w", err)
+// parseTemplateFile はテンプレートファイル（opts.TemplateTextが指定されている場合はその内容）を
+// カスタム関数付きで解析する．
+// opts.Layoutが指定されている場合は，レイアウトのブロックを置き換えるテンプレートとして解析する．
+func parseTemplateFile(templateFilePath string, opts ConvertOptions) (*template.Template, error) {
+	templateContent := []byte(opts.TemplateText)
+	if opts.TemplateText == "" {
+		// Read template file
+		var err error
+		if templateContent, err = readTemplateFile(templateFilePath); err != nil {
+			return nil, err
+		}
+		if IsBuiltinTemplate(templateFilePath) {
+			// 組み込みのテンプレートは{{ }}で書かれているため，-template-delimsの指定に関係なく既定の区切り文字を使う
+			opts.TemplateDelims = [2]string{}
+		}
 	}
-	defer csvFile.Close()
 
-	writer := csv.NewWriter(csvFile)
-	defer writer.Flush()
+	if opts.Layout != "" {
+		return parseWithLayout(templateFilePath, string(templateContent), opts)
+	}
 
-	// Write header
-	err = writer.Write([]string{"question", "answer", "spell", "criteria"})
+	// Create template with custom functions
+	tmpl, err := template.New("quiz").Delims(opts.TemplateDelims[0], opts.TemplateDelims[1]).Funcs(userTemplateFuncs(opts)).Parse(string(templateContent))
 	if err != nil {
-		return fmt.Errorf("failed to write CSV header: %w", err)
+		return nil, fmt.Errorf("%w: %w", ErrTemplateParse, err)
+	}
+	return tmpl, nil
+}
+
+// ParseTemplateDelims は"[[,]]"のように左右の区切り文字をカンマでつないだ指定を解析する．
+// 他のテンプレート言語の{{ }}を含むテキストを出力するテンプレートなどで使う．
+func ParseTemplateDelims(spec string) ([2]string, error) {
+	left, right, ok := strings.Cut(spec, ",")
+	left, right = strings.TrimSpace(left), strings.TrimSpace(right)
+	if !ok || left == "" || right == "" || strings.Contains(right, ",") {
+		return [2]string{}, fmt.Errorf("invalid template delimiters %q: expected left and right delimiters separated by a comma (e.g. \"[[,]]\")", spec)
 	}
+	return [2]string{left, right}, nil
+}
 
-	// Write data rows
-	for _, item := range data {
-		criteriaText := ""
-		if item.Criteria != nil {
-			criteriaText = FormatCriteria(item.Criteria)
+// ParseTemplateVars は"名前=値"形式の指定からテンプレートに渡す変数を作成する．
+// "="を含まない指定は環境変数の名前として扱い，lookupEnv（通常はos.LookupEnv）で値を取得する．
+// 同じ名前を複数回指定した場合は後の指定を使う．
+func ParseTemplateVars(specs []string, lookupEnv func(name string) (string, bool)) (map[string]string, error) {
+	vars := make(map[string]string, len(specs))
+	for _, spec := range specs {
+		name, value, ok := strings.Cut(spec, "=")
+		name = strings.TrimSpace(name)
+		if name == "" {
+			return nil, fmt.Errorf("invalid variable %q: expected name=value or the name of an environment variable", spec)
+		}
+		if !ok {
+			if value, ok = lookupEnv(name); !ok {
+				return nil, fmt.Errorf("environment variable %s is not set", name)
+			}
 		}
+		vars[name] = value
+	}
+	return vars, nil
+}
 
-		err = writer.Write([]string{
-			item.Question,
-			item.Answer,
-			item.Spell,
-			criteriaText,
-		})
+// templateQuizItem はテンプレート関数の引数（QuizItemまたはTemplateItem）から問題を取り出す．
+func templateQuizItem(v any) (QuizItem, error) {
+	switch v := v.(type) {
+	case QuizItem:
+		return v, nil
+	case *QuizItem:
+		return *v, nil
+	case TemplateItem:
+		return v.QuizItem, nil
+	case *TemplateItem:
+		return v.QuizItem, nil
+	}
+	return QuizItem{}, fmt.Errorf("expected a quiz item, got %T", v)
+}
+
+// executeTemplate はテンプレートに問題データを適用してwに書き出す．
+func executeTemplate(w io.Writer, tmpl *template.Template, data []QuizItem, opts ConvertOptions) error {
+	data = withAssignedIDs(data, opts)
+	td := TemplateData{Items: data, Numbers: QuestionNumbers(data, opts.Numbering), Vars: opts.Vars, ShowGojuonIndex: opts.GojuonIndex, ShowGlossary: opts.Glossary.Enabled}
+	return runTemplate(w, tmpl, td, opts)
+}
+
+// runTemplate はテンプレートにtdを適用してwに書き出す（実行時間と出力の制限はexecuteWithLimits参照）．
+// 実行に失敗した場合，問題に起因するエラーであれば，最初のエラーで止めずに
+// 失敗するすべての問題のItemErrorをまとめてErrTemplateExecuteとともに返す．
+func runTemplate(w io.Writer, tmpl *template.Template, td TemplateData, opts ConvertOptions) error {
+	started := time.Now()
+	err := executeWithLimits(w, tmpl, td, opts)
+	if err == nil {
+		return nil
+	}
+	// 制限を超えた場合は，実行し直すとさらに時間がかかるため問題ごとの原因は調べない
+	if errors.Is(err, ErrTemplateTimeout) || errors.Is(err, ErrOutputTooLarge) {
+		return fmt.Errorf("%w: %w", ErrTemplateExecute, err)
+	}
+	if opts.TemplateTimeout > 0 {
+		// 問題ごとの実行し直しも含めて，全体でopts.TemplateTimeoutまでとする
+		opts.TemplateTimeout -= time.Since(started)
+	}
+	if itemErrs := templateItemErrors(tmpl, td, opts); len(itemErrs) > 0 {
+		err = errors.Join(itemErrs...)
+	}
+	return fmt.Errorf("%w: %w", ErrTemplateExecute, err)
+}
+
+// templateItemErrors はテンプレートに問題を1問ずつ渡して実行し直し，失敗する問題ごとのItemErrorを返す．
+// 問題を含まないデータでも失敗する場合は，問題に起因するエラーではないとしてnilを返す．
+// ページ分割時の問題の番号は，すべての問題の中での番号とする．
+// 実行し直しにもoptsの制限を適用し，opts.TemplateTimeoutの時間を使い切った場合や
+// 出力の上限を超えた場合は，それまでに見つかったItemErrorだけを返す．
+func templateItemErrors(tmpl *template.Template, td TemplateData, opts ConvertOptions) []error {
+	var deadline time.Time
+	if opts.TemplateTimeout > 0 {
+		deadline = time.Now().Add(opts.TemplateTimeout)
+	}
+	// execute は残りの時間を制限時間として実行し直す．制限を超えた場合はfalseを返す
+	execute := func(data TemplateData) (bool, error) {
+		limits := opts
+		if !deadline.IsZero() {
+			if limits.TemplateTimeout = time.Until(deadline); limits.TemplateTimeout <= 0 {
+				return false, nil
+			}
+		}
+		err := executeWithLimits(io.Discard, tmpl, data, limits)
+		if errors.Is(err, ErrTemplateTimeout) || errors.Is(err, ErrOutputTooLarge) {
+			return false, nil
+		}
+		return true, err
+	}
+
+	empty := td
+	empty.Items, empty.Numbers = nil, nil
+	if ok, err := execute(empty); err != nil || !ok {
+		return nil
+	}
+	offset := 0
+	if td.Page != nil {
+		offset = td.Page.start
+	}
+	var errs []error
+	for i := range td.Items {
+		single := td
+		single.Items, single.Numbers = td.Items[i:i+1], nil
+		if i < len(td.Numbers) {
+			single.Numbers = td.Numbers[i : i+1]
+		}
+		ok, err := execute(single)
+		if !ok {
+			break
+		}
 		if err != nil {
-			return fmt.Errorf("failed to write CSV row: %w", err)
+			errs = append(errs, &ItemError{Index: offset + i + 1, Question: QuestionSnippet(td.Items[i]), Err: err})
 		}
 	}
+	return errs
+}
 
-	return nil
+// YAMLファイルをCSVファイルに変換する．
+// CSV出力には問題文、答え、原語表記、およびフォーマットされた正誤判定が含まれる．
+func ConvertYAMLToCSV(yamlFilePath, csvFilePath string) error {
+	return ConvertYAMLToCSVWithOptions(yamlFilePath, csvFilePath, ConvertOptions{})
+}
+
+// オプションを指定してYAMLファイルをCSVファイルに変換する．
+// 問題はYAMLから1問ずつ読み込みながら書き出すため，問題データ全体をメモリに保持しない．
+func ConvertYAMLToCSVWithOptions(yamlFilePath, csvFilePath string, opts ConvertOptions) error {
+	return writeFileAtomic(csvFilePath, opts.NoClobber, func(w io.Writer) error {
+		return streamYAMLFilesToCSV(w, []string{yamlFilePath}, opts)
+	})
 }
 
 // QuizItemのスライスをYAMLファイルとして書き出す．
+//
+// Deprecated: WriteYAMLFileを使う．
 func SaveYAMLData(items []QuizItem, yamlFilePath string) error {
-	out, err := yaml.Marshal(items)
-	if err != nil {
-		return fmt.Errorf("failed to marshal YAML: %w", err)
+	return WriteYAMLFile(yamlFilePath, items)
+}
+
+// splitDocuments は連続する同じDocumentの問題ごとに分割する．
+// Documentが設定されていない場合は全体を1つのドキュメントとする．
+func splitDocuments(items []QuizItem) [][]QuizItem {
+	var documents [][]QuizItem
+	start := 0
+	for i := 1; i <= len(items); i++ {
+		if i == len(items) || items[i].Document != items[start].Document {
+			documents = append(documents, items[start:i])
+			start = i
+		}
 	}
-	if err := os.WriteFile(yamlFilePath, out, 0o644); err != nil {
-		return fmt.Errorf("failed to write YAML file: %w", err)
+	if len(documents) == 0 {
+		documents = append(documents, items)
 	}
-	return nil
+	return documents
 }
 
 // ConvertMarkdownDirToYAML はMarkdownディレクトリを1つのYAMLファイルに集約する
@@ -379,28 +1085,123 @@ func ConvertMarkdownDirToYAML(mdDirPath, yamlFilePath string, recursive bool) er
 	if err != nil {
 		return err
 	}
-	return SaveYAMLData(items, yamlFilePath)
+	return WriteYAMLFile(yamlFilePath, items)
 }
 
 // 全体の変換処理を行うエントリーポイント．
 // 出力ファイルのフォーマットを検出し，CSV形式またはテンプレート形式に変換する．
 // 出力ファイルの拡張子やテンプレートファイルの有無に基づいて適切な変換関数を呼び出す．
 func Convert(yamlFilePath, outputFilePath, templateFilePath string) error {
+	return ConvertWithOptions(yamlFilePath, outputFilePath, templateFilePath, ConvertOptions{})
+}
+
+// オプションを指定して全体の変換処理を行う．
+func ConvertWithOptions(yamlFilePath, outputFilePath, templateFilePath string, opts ConvertOptions) error {
+	return ConvertFilesWithOptions([]string{yamlFilePath}, outputFilePath, templateFilePath, opts)
+}
+
+// ConvertFilesWithOptions は複数のYAMLファイルを指定した順に連結し，1つの問題データとして変換する．
+func ConvertFilesWithOptions(yamlFilePaths []string, outputFilePath, templateFilePath string, opts ConvertOptions) error {
+	// 読み込みの前に出力形式の指定誤りを検出する
+	if templateFilePath == "" && opts.Format != "" {
+		if _, err := ResolveFormatter(opts.Format); err != nil {
+			return err
+		}
+	} else if DetectOutputFormat(outputFilePath, templateFilePath) == FormatTemplate && templateFilePath == "" {
+		return ErrTemplateRequired
+	}
+	if opts.ValidateFirst {
+		if err := ValidateYAMLFiles(yamlFilePaths).Err(); err != nil {
+			return err
+		}
+	}
+
+	// CSVへの変換は問題を1問ずつ読み込みながら書き出す
+	if canStreamCSV(outputFilePath, templateFilePath, opts) {
+		return writeFileAtomic(outputFilePath, opts.NoClobber, func(w io.Writer) error {
+			return streamYAMLFilesToCSV(w, yamlFilePaths, opts)
+		})
+	}
+
+	data, err := LoadYAMLFiles(yamlFilePaths)
+	if err != nil {
+		return err
+	}
+	return ConvertItems(data, outputFilePath, templateFilePath, opts)
+}
+
+// ConvertItemsToFiles は読み込み済みの問題データを複数の出力ファイルに書き出す．
+// 各ファイルの出力形式は拡張子から決める（FormatForFile参照）．opts.Pipelineは最初に1度だけ適用する．
+// 出力形式を決められないファイルがある場合は，何も書き出さずにエラーを返す．
+func ConvertItemsToFiles(data []QuizItem, outputFilePaths []string, opts ConvertOptions) error {
+	formats := make([]string, len(outputFilePaths))
+	for i, path := range outputFilePaths {
+		format, err := FormatForFile(path)
+		if err != nil {
+			return err
+		}
+		formats[i] = format
+	}
+
+	data, err := opts.Pipeline.Apply(data)
+	if err != nil {
+		return err
+	}
+	opts.Pipeline = nil
+	for i, path := range outputFilePaths {
+		opts.Format = formats[i]
+		if err := ConvertItems(data, path, "", opts); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	return nil
+}
+
+// ConvertItems は読み込み済みの問題データを出力ファイルのフォーマットに応じて変換する．
+// テンプレートが指定されておらずopts.Formatが指定されている場合は，登録された出力形式を使用する．
+// opts.Pipelineが指定されている場合は，出力の前に問題データに適用する．
+func ConvertItems(data []QuizItem, outputFilePath, templateFilePath string, opts ConvertOptions) error {
+	data, err := opts.Pipeline.Apply(data)
+	if err != nil {
+		return err
+	}
+
+	if templateFilePath == "" && opts.Format != "" {
+		f, err := ResolveFormatter(opts.Format)
+		if err != nil {
+			return err
+		}
+		return writeFileAtomic(outputFilePath, opts.NoClobber, func(w io.Writer) error {
+			return f.Format(w, data, opts)
+		})
+	}
+
 	format := DetectOutputFormat(outputFilePath, templateFilePath)
 
 	switch format {
 	case FormatCSV:
-		return ConvertYAMLToCSV(yamlFilePath, outputFilePath)
+		return writeFileAtomic(outputFilePath, opts.NoClobber, func(w io.Writer) error {
+			return WriteCSV(w, data, opts)
+		})
 	case FormatTemplate:
 		if templateFilePath == "" {
-			return fmt.Errorf("template file is required for non-CSV output")
-		}
-		data, err := LoadYAMLData(yamlFilePath)
-		if err != nil {
-			return err
+			return ErrTemplateRequired
 		}
-		return ConvertToTemplate(data, templateFilePath, outputFilePath)
+		return ConvertToTemplateWithOptions(data, templateFilePath, outputFilePath, opts)
 	default:
-		return fmt.Errorf("unsupported output format")
+		return fmt.Errorf("%w: %s", ErrUnsupportedFormat, format)
+	}
+}
+
+// ConvertBytes はメモリ上のYAMLデータを変換し，出力をバイト列で返す．ファイルは読み書きしない．
+// formatには登録された出力形式の名前（FormatCSVなど．空の場合はCSV）を指定する．
+// FormatTemplateの場合はopts.TemplateTextのテンプレートを使い，空の場合はErrTemplateRequiredを返す．
+// 外部コマンド（exec:）は使えない．opts.Pipelineが指定されている場合は，出力の前に問題データに適用する．
+// エラーの場合は途中までの出力は返さない．同じ設定で繰り返し変換する場合はNewConverterを使う．
+func ConvertBytes(yamlData []byte, format OutputFormat, opts ConvertOptions) ([]byte, error) {
+	c, err := NewConverter(format, "", opts)
+	if err != nil {
+		return nil, err
 	}
+	return c.ConvertBytes(yamlData)
 }
@@ -1,9 +1,15 @@
 package quiz_yaml_converter
 
 import (
+	"bytes"
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
+	"testing/iotest"
 )
 
 func TestAddQuotesIfNeeded(t *testing.T) {
@@ -42,6 +48,16 @@ func TestAddQuotesIfNeeded(t *testing.T) {
 			input:    "「test」something",
 			expected: "「test」something",
 		},
+		{
+			name:     "quotes inside",
+			input:    "映画「test」の原作",
+			expected: "『映画「test」の原作』",
+		},
+		{
+			name:     "ends with quoted part",
+			input:    "映画「test」",
+			expected: "『映画「test」』",
+		},
 	}
 
 	for _, tt := range tests {
@@ -101,7 +117,7 @@ func TestFormatCriteriaSection(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := formatCriteriaSection(tt.items, tt.suffix)
+			result := formatCriteriaSection(tt.items, tt.suffix, criteriaPhrasesFor(LanguageJapanese))
 			if result != tt.expected {
 				t.Errorf("formatCriteriaSection(%v, %q) = %q, want %q", tt.items, tt.suffix, result, tt.expected)
 			}
@@ -519,3 +535,699 @@ func BenchmarkConvertYAMLToCSV(b *testing.B) {
 		}
 	}
 }
+
+func TestFormatCriteriaInOrder(t *testing.T) {
+	criteria := map[string][]string{
+		"ok":     {"ok1"},
+		"ng":     {"ng1"},
+		"repeat": {"rep1"},
+	}
+
+	tests := []struct {
+		name     string
+		order    []string
+		expected string
+	}{
+		{
+			name:     "nil order falls back to default",
+			order:    nil,
+			expected: "「ok1」／「ng1」は誤答／「rep1」はもう一度",
+		},
+		{
+			name:     "ng first",
+			order:    []string{"ng", "ok", "repeat"},
+			expected: "「ng1」は誤答／「ok1」／「rep1」はもう一度",
+		},
+		{
+			name:     "partial order appends the rest in default order",
+			order:    []string{"repeat"},
+			expected: "「rep1」はもう一度／「ok1」／「ng1」は誤答",
+		},
+		{
+			name:     "unknown and duplicated keys are ignored",
+			order:    []string{"unknown", "ng", "ng"},
+			expected: "「ng1」は誤答／「ok1」／「rep1」はもう一度",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := FormatCriteriaInOrder(criteria, tt.order)
+			if result != tt.expected {
+				t.Errorf("FormatCriteriaInOrder(%v) = %q, want %q", tt.order, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestLoadYAMLData_RecordsCriteriaOrder(t *testing.T) {
+	tempDir := t.TempDir()
+	yamlFile := filepath.Join(tempDir, "order.yaml")
+	yamlContent := `- question: 問題1
+  answer: 答え1
+  criteria:
+    ng:
+      - ng1
+    ok:
+      - ok1
+- question: 問題2
+  answer: 答え2`
+	if err := os.WriteFile(yamlFile, []byte(yamlContent), 0644); err != nil {
+		t.Fatalf("Failed to create test YAML file: %v", err)
+	}
+
+	data, err := LoadYAMLData(yamlFile)
+	if err != nil {
+		t.Fatalf("LoadYAMLData() error = %v", err)
+	}
+
+	if got := data[0].CriteriaOrder; len(got) != 2 || got[0] != "ng" || got[1] != "ok" {
+		t.Errorf("CriteriaOrder = %v, want [ng ok]", got)
+	}
+	if data[1].CriteriaOrder != nil {
+		t.Errorf("CriteriaOrder = %v, want nil", data[1].CriteriaOrder)
+	}
+}
+
+func TestConvertYAMLToCSVWithOptions_PreserveCriteriaOrder(t *testing.T) {
+	tempDir := t.TempDir()
+	yamlFile := filepath.Join(tempDir, "order.yaml")
+	csvFile := filepath.Join(tempDir, "order.csv")
+	yamlContent := `- question: 問題1
+  answer: 答え1
+  spell: ""
+  criteria:
+    ng:
+      - ng1
+    ok:
+      - ok1`
+	if err := os.WriteFile(yamlFile, []byte(yamlContent), 0644); err != nil {
+		t.Fatalf("Failed to create test YAML file: %v", err)
+	}
+
+	err := ConvertYAMLToCSVWithOptions(yamlFile, csvFile, ConvertOptions{PreserveCriteriaOrder: true})
+	if err != nil {
+		t.Fatalf("ConvertYAMLToCSVWithOptions() error = %v", err)
+	}
+
+	csvContent, err := os.ReadFile(csvFile)
+	if err != nil {
+		t.Fatalf("Failed to read CSV file: %v", err)
+	}
+	expected := "question,answer,spell,criteria\n問題1,答え1,,「ng1」は誤答／「ok1」\n"
+	if string(csvContent) != expected {
+		t.Errorf("CSV output = %q, want %q", string(csvContent), expected)
+	}
+}
+
+func TestSaveYAMLData_KeepsCriteriaOrder(t *testing.T) {
+	tempDir := t.TempDir()
+	yamlFile := filepath.Join(tempDir, "saved.yaml")
+	items := []QuizItem{
+		{
+			Question:      "問題1",
+			Answer:        "答え1",
+			Criteria:      map[string][]string{"ok": {"ok1"}, "ng": {"ng1"}},
+			CriteriaOrder: []string{"ng", "ok"},
+		},
+	}
+
+	if err := SaveYAMLData(items, yamlFile); err != nil {
+		t.Fatalf("SaveYAMLData() error = %v", err)
+	}
+	loaded, err := LoadYAMLData(yamlFile)
+	if err != nil {
+		t.Fatalf("LoadYAMLData() error = %v", err)
+	}
+
+	if got := FormatCriteriaInOrder(loaded[0].Criteria, loaded[0].CriteriaOrder); got != "「ng1」は誤答／「ok1」" {
+		t.Errorf("criteria after round-trip = %q", got)
+	}
+}
+
+func TestValidateItems(t *testing.T) {
+	data, err := ParseYAMLData([]byte(`- question: 問題1
+  answer: ""
+  tags:
+    - ""`))
+	if err != nil {
+		t.Fatalf("ParseYAMLData() error = %v", err)
+	}
+
+	result := ValidateItems(data)
+
+	if result.IsValid {
+		t.Fatal("IsValid = true, want false")
+	}
+	expected := []string{"問題 1: 答え (answer) が空です", "問題 1: tags[0] が空です"}
+	if len(result.Errors) != len(expected) {
+		t.Fatalf("Errors = %v, want %v", result.Errors, expected)
+	}
+	for i := range expected {
+		if result.Errors[i] != expected[i] {
+			t.Errorf("Errors[%d] = %q, want %q", i, result.Errors[i], expected[i])
+		}
+	}
+}
+
+func TestWriteTemplate(t *testing.T) {
+	tempDir := t.TempDir()
+	templateFile := filepath.Join(tempDir, "template.txt")
+	if err := os.WriteFile(templateFile, []byte(`{{range .Items}}{{.Question}}={{.Answer}};{{end}}`), 0644); err != nil {
+		t.Fatalf("Failed to create template file: %v", err)
+	}
+
+	var buf strings.Builder
+	err := WriteTemplate(&buf, []QuizItem{{Question: "q1", Answer: "a1"}, {Question: "q2", Answer: "a2"}}, templateFile, ConvertOptions{})
+	if err != nil {
+		t.Fatalf("WriteTemplate() error = %v", err)
+	}
+
+	if buf.String() != "q1=a1;q2=a2;" {
+		t.Errorf("WriteTemplate() = %q, want %q", buf.String(), "q1=a1;q2=a2;")
+	}
+}
+
+func TestLoadYAMLFiles(t *testing.T) {
+	tempDir := t.TempDir()
+	first := filepath.Join(tempDir, "first.yaml")
+	second := filepath.Join(tempDir, "second.yaml")
+	if err := os.WriteFile(first, []byte("- question: q1\n  answer: a1\n- question: q2\n  answer: a2\n"), 0644); err != nil {
+		t.Fatalf("Failed to create YAML file: %v", err)
+	}
+	if err := os.WriteFile(second, []byte("- question: q3\n  answer: a3\n"), 0644); err != nil {
+		t.Fatalf("Failed to create YAML file: %v", err)
+	}
+
+	data, err := LoadYAMLFiles([]string{second, first})
+	if err != nil {
+		t.Fatalf("LoadYAMLFiles() error = %v", err)
+	}
+	var questions []string
+	for _, item := range data {
+		questions = append(questions, item.Question)
+	}
+	if got := strings.Join(questions, ","); got != "q3,q1,q2" {
+		t.Errorf("LoadYAMLFiles() questions = %q, want %q", got, "q3,q1,q2")
+	}
+
+	missing := filepath.Join(tempDir, "missing.yaml")
+	if _, err := LoadYAMLFiles([]string{first, missing}); err == nil || !strings.Contains(err.Error(), missing) {
+		t.Errorf("LoadYAMLFiles() error = %v, want error mentioning %s", err, missing)
+	}
+}
+
+func TestValidateYAMLFiles_ContinuesIndices(t *testing.T) {
+	tempDir := t.TempDir()
+	first := filepath.Join(tempDir, "first.yaml")
+	second := filepath.Join(tempDir, "second.yaml")
+	if err := os.WriteFile(first, []byte("- question: q1\n  answer: a1\n"), 0644); err != nil {
+		t.Fatalf("Failed to create YAML file: %v", err)
+	}
+	if err := os.WriteFile(second, []byte("- question: q2\n  answer: \"\"\n"), 0644); err != nil {
+		t.Fatalf("Failed to create YAML file: %v", err)
+	}
+
+	result := ValidateYAMLFiles([]string{first, second})
+	if result.IsValid {
+		t.Fatal("ValidateYAMLFiles() should fail")
+	}
+	if result.Items != 2 {
+		t.Errorf("Items = %d, want 2", result.Items)
+	}
+	if len(result.ValidationErrors) != 1 || result.ValidationErrors[0].Index != 2 {
+		t.Errorf("ValidationErrors = %+v, want one error for item 2", result.ValidationErrors)
+	}
+}
+
+func TestValidateYAMLFiles_BOM(t *testing.T) {
+	tempDir := t.TempDir()
+	withBOM := filepath.Join(tempDir, "bom.yaml")
+	withoutBOM := filepath.Join(tempDir, "plain.yaml")
+	if err := os.WriteFile(withBOM, []byte(utf8BOM+"- question: q1\n  answer: a1\n"), 0644); err != nil {
+		t.Fatalf("Failed to create YAML file: %v", err)
+	}
+	if err := os.WriteFile(withoutBOM, []byte("- question: q2\n  answer: a2\n"), 0644); err != nil {
+		t.Fatalf("Failed to create YAML file: %v", err)
+	}
+
+	result := ValidateYAMLFiles([]string{withBOM, withoutBOM})
+	if !result.IsValid {
+		t.Fatalf("ValidateYAMLFiles() errors = %v", result.Errors)
+	}
+	if len(result.Warnings) != 1 || !strings.Contains(result.Warnings[0].Message, withBOM) {
+		t.Errorf("Warnings = %+v, want one warning for %s", result.Warnings, withBOM)
+	}
+	if got := result.LocalizedWarnings(LanguageEnglish); len(got) != 1 || !strings.HasPrefix(got[0], "file starts with a BOM") {
+		t.Errorf("LocalizedWarnings() = %v", got)
+	}
+
+	if result := ValidateYAMLFiles([]string{withoutBOM}); len(result.Warnings) != 0 {
+		t.Errorf("Warnings = %+v, want none", result.Warnings)
+	}
+}
+
+func TestValidateYAML(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		valid    bool
+		items    int
+		rule     string
+		line     int
+		warnings int
+	}{
+		{"valid", "- question: q1\n  answer: a1\n", true, 1, "", 0, 0},
+		{"empty answer", "- question: q1\n  answer: a1\n- question: q2\n  answer: \"\"\n", false, 2, RuleRequired, 3, 0},
+		{"syntax error", "- question: q1\n  answer: [a1\n", false, 0, RuleSyntax, 0, 0},
+		{"no items", "", false, 0, RuleNoItems, 0, 0},
+		{"BOM", utf8BOM + "- question: q1\n  answer: a1\n", true, 1, "", 0, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for name, result := range map[string]ValidationResult{
+				"ValidateYAML":   ValidateYAML([]byte(tt.input)),
+				"ValidateReader": ValidateReader(strings.NewReader(tt.input)),
+			} {
+				if result.IsValid != tt.valid || result.Items != tt.items {
+					t.Fatalf("%s() IsValid = %v, Items = %d, want %v, %d (errors: %v)", name, result.IsValid, result.Items, tt.valid, tt.items, result.Errors)
+				}
+				if tt.rule != "" {
+					e := result.ValidationErrors[0]
+					if e.Rule != tt.rule || e.File != "" || (tt.line > 0 && e.Line != tt.line) {
+						t.Errorf("%s() ValidationErrors[0] = %+v, want rule %s at line %d", name, e, tt.rule, tt.line)
+					}
+				}
+				if len(result.Warnings) != tt.warnings {
+					t.Errorf("%s() Warnings = %+v, want %d", name, result.Warnings, tt.warnings)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateReader_ReadError(t *testing.T) {
+	result := ValidateReader(iotest.ErrReader(errors.New("broken")))
+	if result.IsValid || len(result.ValidationErrors) != 1 || result.ValidationErrors[0].Rule != RuleRead {
+		t.Errorf("ValidateReader() = %+v, want one %s error", result, RuleRead)
+	}
+}
+
+func TestParseYAMLData_BOM(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{"sequence", utf8BOM + "- question: q1\n  answer: a1\n"},
+		{"leading comment", utf8BOM + "# comment\n- question: q1\n  answer: a1\n"},
+		{"flow style", utf8BOM + `[{"question": "q1", "answer": "a1"}]`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := ParseYAMLData([]byte(tt.input))
+			if err != nil {
+				t.Fatalf("ParseYAMLData() error = %v", err)
+			}
+			if len(data) != 1 || data[0].Question != "q1" || data[0].Answer != "a1" {
+				t.Errorf("ParseYAMLData() = %+v", data)
+			}
+		})
+	}
+}
+
+func TestParseYAMLData_MultipleDocuments(t *testing.T) {
+	input := `- question: q1
+  answer: a1
+- question: q2
+  answer: a2
+---
+# 第2ラウンド
+- question: q3
+  answer: a3
+---
+`
+	data, err := ParseYAMLData([]byte(input))
+	if err != nil {
+		t.Fatalf("ParseYAMLData() error = %v", err)
+	}
+	var got []string
+	for _, item := range data {
+		got = append(got, fmt.Sprintf("%s@%d", item.Question, item.Document))
+	}
+	if want := "q1@1,q2@1,q3@2"; strings.Join(got, ",") != want {
+		t.Errorf("ParseYAMLData() = %v, want %s", got, want)
+	}
+
+	documents, err := ParseYAMLDocuments([]byte(input))
+	if err != nil {
+		t.Fatalf("ParseYAMLDocuments() error = %v", err)
+	}
+	if len(documents) != 2 || len(documents[0]) != 2 || len(documents[1]) != 1 {
+		t.Errorf("ParseYAMLDocuments() = %+v, want 2 documents with 2 and 1 items", documents)
+	}
+
+	single, err := ParseYAMLData([]byte("---\n- question: q1\n  answer: a1\n"))
+	if err != nil {
+		t.Fatalf("ParseYAMLData() error = %v", err)
+	}
+	if len(single) != 1 || single[0].Document != 0 {
+		t.Errorf("ParseYAMLData() single document = %+v, want Document 0", single)
+	}
+
+	if _, err := ParseYAMLData([]byte("- question: q1\n---\n- question: [\n")); !errors.Is(err, ErrInvalidYAML) {
+		t.Errorf("ParseYAMLData() error = %v, want ErrInvalidYAML", err)
+	}
+}
+
+func TestSaveYAMLData_MultipleDocuments(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rounds.yaml")
+	items := []QuizItem{
+		{Question: "q1", Answer: "a1", Document: 1},
+		{Question: "q2", Answer: "a2", Document: 2},
+		{Question: "q3", Answer: "a3", Document: 2},
+	}
+	if err := SaveYAMLData(items, path); err != nil {
+		t.Fatalf("SaveYAMLData() error = %v", err)
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read output: %v", err)
+	}
+	if n := strings.Count(string(content), "---\n"); n != 1 {
+		t.Errorf("output has %d separators, want 1:\n%s", n, content)
+	}
+	loaded, err := LoadYAMLData(path)
+	if err != nil {
+		t.Fatalf("LoadYAMLData() error = %v", err)
+	}
+	if !reflect.DeepEqual(loaded, items) {
+		t.Errorf("LoadYAMLData() = %+v, want %+v", loaded, items)
+	}
+}
+
+func TestConvertFilesWithOptions(t *testing.T) {
+	tempDir := t.TempDir()
+	first := filepath.Join(tempDir, "first.yaml")
+	second := filepath.Join(tempDir, "second.yaml")
+	output := filepath.Join(tempDir, "out.csv")
+	if err := os.WriteFile(first, []byte("- question: q1\n  answer: a1\n"), 0644); err != nil {
+		t.Fatalf("Failed to create YAML file: %v", err)
+	}
+	if err := os.WriteFile(second, []byte("- question: q2\n  answer: a2\n"), 0644); err != nil {
+		t.Fatalf("Failed to create YAML file: %v", err)
+	}
+
+	opts := ConvertOptions{CSV: CSVOptions{Columns: []string{"question", "answer"}, NoHeader: true}}
+	if err := ConvertFilesWithOptions([]string{first, second}, output, "", opts); err != nil {
+		t.Fatalf("ConvertFilesWithOptions() error = %v", err)
+	}
+	content, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatalf("Failed to read output: %v", err)
+	}
+	if string(content) != "q1,a1\nq2,a2\n" {
+		t.Errorf("output = %q, want %q", content, "q1,a1\nq2,a2\n")
+	}
+}
+
+func TestConvertFilesWithOptions_ValidateFirst(t *testing.T) {
+	tempDir := t.TempDir()
+	input := filepath.Join(tempDir, "quiz.yaml")
+	if err := os.WriteFile(input, []byte("- question: q1\n  answer: a1\n- question: q2\n  answer: \"\"\n"), 0644); err != nil {
+		t.Fatalf("Failed to create YAML file: %v", err)
+	}
+
+	tests := []struct {
+		name          string
+		validateFirst bool
+		wantErr       bool
+	}{
+		{"without validation", false, false},
+		{"with validation", true, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output := filepath.Join(t.TempDir(), "out.csv")
+			err := ConvertFilesWithOptions([]string{input}, output, "", ConvertOptions{ValidateFirst: tt.validateFirst})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ConvertFilesWithOptions() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				var ve *ValidationError
+				if !errors.As(err, &ve) || ve.Index != 2 || ve.Line != 3 {
+					t.Errorf("ConvertFilesWithOptions() error = %v, want a validation error for item 2 on line 3", err)
+				}
+			}
+			if _, statErr := os.Stat(output); (statErr == nil) == tt.wantErr {
+				t.Errorf("output exists = %v, want %v", statErr == nil, !tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestConvertToTemplate_ItemErrors(t *testing.T) {
+	tempDir := t.TempDir()
+	data := []QuizItem{
+		{Question: "q1", Answer: "a1", Criteria: map[string][]string{"ok": {"ok1"}}},
+		{Question: "q2", Answer: "a2"},
+		{Question: "q3", Answer: "a3", Criteria: map[string][]string{"ok": {"ok3"}}},
+		{Question: "q4", Answer: "a4"},
+	}
+	tests := []struct {
+		name     string
+		template string
+		indices  []int
+	}{
+		{"item errors", `{{range .Items}}{{index .Criteria.ok 0}}{{end}}`, []int{2, 4}},
+		{"error outside items", `{{range .Items}}{{.Question}}{{end}}{{.Missing}}`, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			templateFile := filepath.Join(tempDir, "quiz.tmpl")
+			if err := os.WriteFile(templateFile, []byte(tt.template), 0644); err != nil {
+				t.Fatalf("Failed to create template file: %v", err)
+			}
+			err := ConvertToTemplate(data, templateFile, filepath.Join(tempDir, "out.txt"))
+			if !errors.Is(err, ErrTemplateExecute) {
+				t.Fatalf("ConvertToTemplate() error = %v, want ErrTemplateExecute", err)
+			}
+			var indices []int
+			for _, e := range ItemErrors(err) {
+				indices = append(indices, e.Index)
+				if e.Question != data[e.Index-1].Question || !strings.Contains(err.Error(), fmt.Sprintf("item %d (%q)", e.Index, e.Question)) {
+					t.Errorf("ItemError = %+v, want the question of item %d in %v", e, e.Index, err)
+				}
+			}
+			if !reflect.DeepEqual(indices, tt.indices) {
+				t.Errorf("ItemErrors() indices = %v, want %v (error = %v)", indices, tt.indices, err)
+			}
+		})
+	}
+}
+
+func TestParseTemplateDelims(t *testing.T) {
+	tests := []struct {
+		spec     string
+		expected [2]string
+		wantErr  bool
+	}{
+		{"[[,]]", [2]string{"[[", "]]"}, false},
+		{" <% , %> ", [2]string{"<%", "%>"}, false},
+		{"[[", [2]string{}, true},
+		{",]]", [2]string{}, true},
+		{"[[,", [2]string{}, true},
+		{"[[,]],", [2]string{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			got, err := ParseTemplateDelims(tt.spec)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseTemplateDelims(%q) error = %v, wantErr %v", tt.spec, err, tt.wantErr)
+			}
+			if got != tt.expected {
+				t.Errorf("ParseTemplateDelims(%q) = %q, want %q", tt.spec, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestConvertToTemplateWithOptions_Delims(t *testing.T) {
+	tempDir := t.TempDir()
+	templateFile := filepath.Join(tempDir, "jinja.tmpl")
+	if err := os.WriteFile(templateFile, []byte(`[[range .Items]]{{ '[[.Answer]]' | upper }}
+[[end]]`), 0644); err != nil {
+		t.Fatalf("Failed to create template file: %v", err)
+	}
+	output := filepath.Join(tempDir, "out.j2")
+	data := []QuizItem{{Question: "q1", Answer: "a1"}, {Question: "q2", Answer: "a2"}}
+	opts := ConvertOptions{TemplateDelims: [2]string{"[[", "]]"}}
+	if err := ConvertToTemplateWithOptions(data, templateFile, output, opts); err != nil {
+		t.Fatalf("ConvertToTemplateWithOptions() error = %v", err)
+	}
+	content, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatalf("Failed to read output: %v", err)
+	}
+	expected := "{{ 'a1' | upper }}\n{{ 'a2' | upper }}\n"
+	if string(content) != expected {
+		t.Errorf("output = %q, want %q", content, expected)
+	}
+}
+
+func TestConvertItemsToFiles(t *testing.T) {
+	tempDir := t.TempDir()
+	data := []QuizItem{{Question: "q1", Answer: "a1"}, {Question: "q2", Answer: "a2"}}
+	calls := 0
+	opts := ConvertOptions{Pipeline: Pipeline{StageFunc(func(items []QuizItem) ([]QuizItem, error) {
+		calls++
+		return items[:1], nil
+	})}}
+
+	outputs := []string{filepath.Join(tempDir, "quiz.csv"), filepath.Join(tempDir, "quiz.json"), filepath.Join(tempDir, "quiz.html")}
+	if err := ConvertItemsToFiles(data, outputs, opts); err != nil {
+		t.Fatalf("ConvertItemsToFiles() error = %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("Pipeline was applied %d times, want 1", calls)
+	}
+	for _, output := range outputs {
+		content, err := os.ReadFile(output)
+		if err != nil {
+			t.Fatalf("Failed to read %s: %v", output, err)
+		}
+		if !strings.Contains(string(content), "q1") || strings.Contains(string(content), "q2") {
+			t.Errorf("%s = %q, want only the filtered item", filepath.Base(output), content)
+		}
+	}
+	if csv, _ := os.ReadFile(outputs[0]); !strings.HasPrefix(string(csv), "question,answer") {
+		t.Errorf("quiz.csv = %q, want CSV output", csv)
+	}
+
+	unknown := []string{filepath.Join(tempDir, "other.csv"), filepath.Join(tempDir, "other.tex")}
+	if err := ConvertItemsToFiles(data, unknown, ConvertOptions{}); !errors.Is(err, ErrUnsupportedFormat) {
+		t.Errorf("ConvertItemsToFiles() with an unknown extension error = %v, want ErrUnsupportedFormat", err)
+	}
+	if _, err := os.Stat(unknown[0]); err == nil {
+		t.Errorf("ConvertItemsToFiles() wrote %s before failing on an unknown extension", unknown[0])
+	}
+}
+
+func TestConvertToTemplateWithOptions_TemplateText(t *testing.T) {
+	tempDir := t.TempDir()
+	output := filepath.Join(tempDir, "answers.txt")
+	data := []QuizItem{{Question: "q1", Answer: "a1"}, {Question: "q2", Answer: "a2"}}
+	opts := ConvertOptions{TemplateText: `{{range .Items}}{{.Answer}};{{end}}`}
+	// TemplateTextを指定した場合，テンプレートファイルは読み込まない
+	if err := ConvertToTemplateWithOptions(data, filepath.Join(tempDir, "missing.tmpl"), output, opts); err != nil {
+		t.Fatalf("ConvertToTemplateWithOptions() error = %v", err)
+	}
+	content, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatalf("Failed to read output: %v", err)
+	}
+	if string(content) != "a1;a2;" {
+		t.Errorf("output = %q, want %q", content, "a1;a2;")
+	}
+}
+
+func TestParseTemplateVars(t *testing.T) {
+	env := map[string]string{"EVENT_DATE": "2026-10-17"}
+	lookupEnv := func(name string) (string, bool) {
+		v, ok := env[name]
+		return v, ok
+	}
+	tests := []struct {
+		name     string
+		specs    []string
+		expected map[string]string
+		wantErr  bool
+	}{
+		{"values", []string{"event=秋の例会", "round=2", "note=a=b"}, map[string]string{"event": "秋の例会", "round": "2", "note": "a=b"}, false},
+		{"empty value", []string{"subtitle="}, map[string]string{"subtitle": ""}, false},
+		{"environment", []string{"EVENT_DATE"}, map[string]string{"EVENT_DATE": "2026-10-17"}, false},
+		{"later wins", []string{"round=1", "round=2"}, map[string]string{"round": "2"}, false},
+		{"unset environment", []string{"MISSING"}, nil, true},
+		{"empty name", []string{"=value"}, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseTemplateVars(tt.specs, lookupEnv)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseTemplateVars() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("ParseTemplateVars() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestWriteTemplate_Vars(t *testing.T) {
+	data := []QuizItem{{Question: "q1", Answer: "a1"}}
+	vars := map[string]string{"event": "秋の例会", "round": "2"}
+	tests := []struct {
+		name     string
+		template string
+		layout   string
+		expected string
+	}{
+		{"template", `{{.Vars.event}} 第{{.Vars.round}}R{{range .Items}} {{.Answer}}{{end}}{{index .Vars "missing"}}`, "", "秋の例会 第2R a1"},
+		{"layout item block", `{{define "item"}}- {{.Vars.event}}: {{.Question}}{{end}}`, "markdown", "- 秋の例会: q1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			opts := ConvertOptions{TemplateText: tt.template, Layout: tt.layout, Vars: vars}
+			if err := WriteTemplate(&buf, data, "vars.tmpl", opts); err != nil {
+				t.Fatalf("WriteTemplate() error = %v", err)
+			}
+			if tt.layout == "" && buf.String() != tt.expected {
+				t.Errorf("WriteTemplate() = %q, want %q", buf.String(), tt.expected)
+			}
+			if !strings.Contains(buf.String(), tt.expected) {
+				t.Errorf("WriteTemplate() = %q, want it to contain %q", buf.String(), tt.expected)
+			}
+		})
+	}
+}
+
+func TestConvertBytes(t *testing.T) {
+	input := []byte("- question: 日本の首都は？\n  answer: 東京\n- question: 1+1は？\n  answer: \"2\"\n")
+	tests := []struct {
+		name     string
+		input    []byte
+		format   OutputFormat
+		opts     ConvertOptions
+		expected string
+		err      error
+	}{
+		{"default csv", input, "", ConvertOptions{}, "日本の首都は？,東京", nil},
+		{"json", input, "json", ConvertOptions{}, `"answer": "東京"`, nil},
+		{"template", input, FormatTemplate, ConvertOptions{TemplateText: "{{range .Items}}{{.Answer}};{{end}}"}, "東京;2;", nil},
+		{"pipeline", input, "jsonl", ConvertOptions{Pipeline: Pipeline{EachItem(func(index int, item *QuizItem) (bool, error) {
+			return index == 2, nil
+		})}}, `"answer":"2"`, nil},
+		{"template without text", input, FormatTemplate, ConvertOptions{}, "", ErrTemplateRequired},
+		{"unknown format", input, "unknown", ConvertOptions{}, "", ErrUnsupportedFormat},
+		{"invalid yaml", []byte("- question: [q\n"), "csv", ConvertOptions{}, "", ErrInvalidYAML},
+		{"template error", input, FormatTemplate, ConvertOptions{TemplateText: "{{.Missing}}"}, "", ErrTemplateExecute},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := ConvertBytes(tt.input, tt.format, tt.opts)
+			if tt.err != nil {
+				if !errors.Is(err, tt.err) || out != nil {
+					t.Fatalf("ConvertBytes() = %q, %v, want error %v", out, err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ConvertBytes() error = %v", err)
+			}
+			if !strings.Contains(string(out), tt.expected) {
+				t.Errorf("ConvertBytes() = %q, want it to contain %q", out, tt.expected)
+			}
+		})
+	}
+}
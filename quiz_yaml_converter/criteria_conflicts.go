@@ -0,0 +1,48 @@
+package quiz_yaml_converter
+
+import (
+	"fmt"
+	"strings"
+)
+
+// 答えと正誤判定の矛盾に関するエラーの規則名（ValidationError.Rule）
+const (
+	RuleAnswerInNG   = "answer-in-ng"   // 答えがcriteria.ngにも含まれている
+	RuleOKIsAnswer   = "ok-is-answer"   // criteria.okの別解が答えと同じ
+	RuleOKNGConflict = "ok-ng-conflict" // 同じ文字列がcriteria.okとcriteria.ngの両方にある
+)
+
+// validateCriteriaConflicts は答えと正誤判定の矛盾をバリデーションする．
+// 答えがcriteria.ngにも含まれている場合，criteria.okに答えと同じ別解がある場合，
+// 同じ文字列がcriteria.okとcriteria.ngの両方にある場合をエラーとする．
+// 文字列は前後の空白を除いて比較し，空の文字列は対象外とする（空のエラーは別に報告する）．
+func validateCriteriaConflicts(item QuizItem, index int) []ValidationError {
+	var errors []ValidationError
+	answer := strings.TrimSpace(item.Answer)
+
+	ok := map[string]bool{}
+	for j, alt := range item.Criteria["ok"] {
+		alt = strings.TrimSpace(alt)
+		if alt == "" {
+			continue
+		}
+		ok[alt] = true
+		if alt == answer {
+			field := fmt.Sprintf("criteria.ok[%d]", j)
+			errors = append(errors, newValidationError(index, field, RuleOKIsAnswer, nil, "%s が答え (answer) と同じです", field))
+		}
+	}
+	for j, wrong := range item.Criteria["ng"] {
+		wrong = strings.TrimSpace(wrong)
+		if wrong == "" {
+			continue
+		}
+		field := fmt.Sprintf("criteria.ng[%d]", j)
+		if wrong == answer {
+			errors = append(errors, newValidationError(index, field, RuleAnswerInNG, nil, "答え (answer) が %s にも含まれています", field))
+		} else if ok[wrong] {
+			errors = append(errors, newValidationError(index, field, RuleOKNGConflict, nil, "「%s」がcriteria.okとcriteria.ngの両方に含まれています", wrong))
+		}
+	}
+	return errors
+}
@@ -0,0 +1,72 @@
+package quiz_yaml_converter
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestValidateCriteriaConflicts(t *testing.T) {
+	tests := []struct {
+		name     string
+		item     QuizItem
+		expected []string
+	}{
+		{
+			name: "no conflicts",
+			item: QuizItem{Answer: "ベルリン", Criteria: map[string][]string{"ok": {"Berlin"}, "ng": {"ボン"}}},
+		},
+		{
+			name:     "answer in ng",
+			item:     QuizItem{Answer: "ベルリン", Criteria: map[string][]string{"ng": {"ボン", " ベルリン"}}},
+			expected: []string{"問題 1: 答え (answer) が criteria.ng[1] にも含まれています"},
+		},
+		{
+			name:     "ok is answer",
+			item:     QuizItem{Answer: "ベルリン", Criteria: map[string][]string{"ok": {"ベルリン"}}},
+			expected: []string{"問題 1: criteria.ok[0] が答え (answer) と同じです"},
+		},
+		{
+			name:     "ok and ng",
+			item:     QuizItem{Answer: "ベルリン", Criteria: map[string][]string{"ok": {"Berlin"}, "ng": {"Berlin"}}},
+			expected: []string{"問題 1: 「Berlin」がcriteria.okとcriteria.ngの両方に含まれています"},
+		},
+		{
+			name: "all",
+			item: QuizItem{Answer: "ベルリン", Criteria: map[string][]string{"ok": {"ベルリン", "Berlin"}, "ng": {"ベルリン", "Berlin"}}},
+			expected: []string{
+				"問題 1: criteria.ok[0] が答え (answer) と同じです",
+				"問題 1: 答え (answer) が criteria.ng[0] にも含まれています",
+				"問題 1: 「Berlin」がcriteria.okとcriteria.ngの両方に含まれています",
+			},
+		},
+		{
+			name: "empty values are ignored",
+			item: QuizItem{Answer: "", Criteria: map[string][]string{"ok": {""}, "ng": {""}}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, e := range validateCriteriaConflicts(tt.item, 1) {
+				got = append(got, e.Error())
+			}
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("validateCriteriaConflicts() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestValidateItems_CriteriaConflicts(t *testing.T) {
+	result := ValidateItems([]QuizItem{{Question: "q", Answer: "a", Criteria: map[string][]string{"ng": {"a"}}}})
+	if result.IsValid {
+		t.Fatal("ValidateItems() should fail")
+	}
+	e := result.ValidationErrors[0]
+	if e.Rule != RuleAnswerInNG || e.Field != "criteria.ng[0]" {
+		t.Errorf("error = {Rule: %q, Field: %q}, want {%q, criteria.ng[0]}", e.Rule, e.Field, RuleAnswerInNG)
+	}
+	if got := e.LocalizedError(LanguageEnglish); got != "item 1: answer also appears in criteria.ng[0]" {
+		t.Errorf("LocalizedError(en) = %q", got)
+	}
+}
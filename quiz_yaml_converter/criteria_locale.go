@@ -0,0 +1,108 @@
+// 正誤判定を出力するときの語句と引用符を言語ごとに切り替える処理です．
+package quiz_yaml_converter
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// criteriaPhrases は正誤判定をフォーマットするときの語句と記号．
+type criteriaPhrases struct {
+	quotes    [2]string         // 各項目を囲む開き・閉じの引用符
+	nested    [2]string         // 項目が途中に開き引用符を含む場合に代わりに囲む引用符（NestQuotes参照）
+	join      string            // 同じ区分の項目をつなぐ文字列
+	separator string            // ok/ng/repeatの区分をつなぐ文字列
+	suffixes  map[string]string // 区分のキーと，その後ろに付ける文字列
+	plurals   map[string]string // 項目が複数の場合に後ろに付ける文字列（suffixesと異なるもののみ）
+}
+
+// 日本語の引用符と，「」を含む項目を囲む引用符
+var (
+	japaneseQuotes       = [2]string{"「", "」"}
+	japaneseNestedQuotes = [2]string{"『", "』"}
+)
+
+// 言語ごとの正誤判定の語句．
+var criteriaLocales = map[Language]criteriaPhrases{
+	LanguageJapanese: {
+		quotes:    japaneseQuotes,
+		nested:    japaneseNestedQuotes,
+		separator: defaultCriteriaSeparator,
+		suffixes:  criteriaSuffixes,
+	},
+	LanguageEnglish: {
+		quotes:    [2]string{`"`, `"`},
+		nested:    [2]string{"'", "'"},
+		join:      ", ",
+		separator: " / ",
+		suffixes:  map[string]string{"ok": "", "ng": " is incorrect", "repeat": " — ask again"},
+		plurals:   map[string]string{"ng": " are incorrect"},
+	},
+}
+
+// criteriaPhrasesFor は言語の正誤判定の語句を返す．対応していない言語や空の場合は日本語とする．
+func criteriaPhrasesFor(lang Language) criteriaPhrases {
+	if p, ok := criteriaLocales[lang]; ok {
+		return p
+	}
+	return criteriaLocales[LanguageJapanese]
+}
+
+// criteriaPhrases はオプションの言語と引用符に従った正誤判定の語句を返す．
+func (o ConvertOptions) criteriaPhrases() criteriaPhrases {
+	p := criteriaPhrasesFor(o.CriteriaLanguage)
+	if o.Quotes != [2]string{} && o.Quotes != p.quotes {
+		p.quotes, p.nested = o.Quotes, [2]string{}
+	}
+	return p
+}
+
+// suffix は区分のキーの後ろに付ける文字列を返す．countは区分の項目数．
+func (p criteriaPhrases) suffix(key string, count int) (string, bool) {
+	if s, ok := p.plurals[key]; ok && count > 1 {
+		return s, true
+	}
+	s, ok := p.suffixes[key]
+	return s, ok
+}
+
+// AddQuotesWith はAddQuotesIfNeededと同じ規則で，必要に応じて「」の代わりにopenとcloseで囲む．
+// 『』や“”，« »など，入れ子の引用や日本語以外の出力で別の括弧を使うときに使う．
+func AddQuotesWith(item, open, close string) string {
+	if rest, ok := strings.CutPrefix(item, open); ok {
+		// 閉じ引用符がどこかに含まれている場合（「美術館」（おまけ）など）はそのまま返す
+		if strings.Contains(rest, close) {
+			return item
+		}
+		return item + close
+	}
+	if strings.HasSuffix(item, close) {
+		return open + item
+	}
+	return open + item + close
+}
+
+// NestQuotes はAddQuotesWithと同じ規則で項目をquotesで囲む．ただし，項目がquotesの開き引用符で始まらずに
+// 途中に含む場合（A「B」Cなど）は，日本語の組版の慣例に従って「「…」」のようにならないようnestedで囲む．
+// nestedが空の場合は常にquotesで囲む．
+func NestQuotes(item string, quotes, nested [2]string) string {
+	if nested != [2]string{} && !strings.HasPrefix(item, quotes[0]) && strings.Contains(item, quotes[0]) {
+		return AddQuotesWith(item, nested[0], nested[1])
+	}
+	return AddQuotesWith(item, quotes[0], quotes[1])
+}
+
+// ParseQuotes は"『』"のような2文字，または"“,”"や"« »"のようにカンマか空白で区切った開き・閉じの引用符の指定を解析する．
+func ParseQuotes(spec string) ([2]string, error) {
+	parts := strings.FieldsFunc(spec, func(r rune) bool { return r == ',' || unicode.IsSpace(r) })
+	if len(parts) == 1 && utf8.RuneCountInString(parts[0]) == 2 {
+		r := []rune(parts[0])
+		parts = []string{string(r[0]), string(r[1])}
+	}
+	if len(parts) != 2 {
+		return [2]string{}, fmt.Errorf("invalid quotes %q: expected an opening and a closing quote (e.g. \"『』\" or \"“,”\")", spec)
+	}
+	return [2]string{parts[0], parts[1]}, nil
+}
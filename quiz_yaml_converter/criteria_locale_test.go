@@ -0,0 +1,199 @@
+package quiz_yaml_converter
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestAddQuotesWith(t *testing.T) {
+	tests := []struct {
+		item, open, close string
+		expected          string
+	}{
+		{"答え", "「", "」", "「答え」"},
+		{"「美術館」（おまけ）", "「", "」", "「美術館」（おまけ）"},
+		{"answer", `"`, `"`, `"answer"`},
+		{`"answer"`, `"`, `"`, `"answer"`},
+		{`"answer`, `"`, `"`, `"answer"`},
+		{`answer"`, `"`, `"`, `"answer"`},
+		{`"Louvre" (bonus)`, `"`, `"`, `"Louvre" (bonus)`},
+		{`"`, `"`, `"`, `""`},
+		{"『答え』", "『", "』", "『答え』"},
+		{"答え", "« ", " »", "« 答え »"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.item, func(t *testing.T) {
+			if got := AddQuotesWith(tt.item, tt.open, tt.close); got != tt.expected {
+				t.Errorf("AddQuotesWith(%q, %q, %q) = %q, want %q", tt.item, tt.open, tt.close, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestNestQuotes(t *testing.T) {
+	tests := []struct {
+		item           string
+		quotes, nested [2]string
+		expected       string
+	}{
+		{"答え", japaneseQuotes, japaneseNestedQuotes, "「答え」"},
+		{"A「B」C", japaneseQuotes, japaneseNestedQuotes, "『A「B」C』"},
+		{"「A」の別名", japaneseQuotes, japaneseNestedQuotes, "「A」の別名"},
+		{"『A「B」』", japaneseQuotes, japaneseNestedQuotes, "『A「B」』"},
+		{"『A』", japaneseQuotes, japaneseNestedQuotes, "「『A』」"},
+		{"A」", japaneseQuotes, japaneseNestedQuotes, "「A」"},
+		{"A「B」C", japaneseQuotes, [2]string{}, "「A「B」C」"},
+		{`say "hi"`, [2]string{`"`, `"`}, [2]string{"'", "'"}, `'say "hi"'`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.item, func(t *testing.T) {
+			if got := NestQuotes(tt.item, tt.quotes, tt.nested); got != tt.expected {
+				t.Errorf("NestQuotes(%q, %q, %q) = %q, want %q", tt.item, tt.quotes, tt.nested, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParseQuotes(t *testing.T) {
+	tests := []struct {
+		spec     string
+		expected [2]string
+		wantErr  bool
+	}{
+		{"『』", [2]string{"『", "』"}, false},
+		{"“,”", [2]string{"“", "”"}, false},
+		{"« »", [2]string{"«", "»"}, false},
+		{"<<,>>", [2]string{"<<", ">>"}, false},
+		{"「", [2]string{}, true},
+		{"「」』", [2]string{}, true},
+		{"a,b,c", [2]string{}, true},
+		{"", [2]string{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			got, err := ParseQuotes(tt.spec)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseQuotes(%q) error = %v, wantErr %v", tt.spec, err, tt.wantErr)
+			}
+			if got != tt.expected {
+				t.Errorf("ParseQuotes(%q) = %q, want %q", tt.spec, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestFormatItemCriteria_Language(t *testing.T) {
+	item := QuizItem{
+		Criteria:      map[string][]string{"ok": {"alt"}, "ng": {"wrong1", "wrong2"}, "repeat": {"partial"}},
+		CriteriaOrder: []string{"repeat", "ok", "ng"},
+	}
+	tests := []struct {
+		name     string
+		opts     ConvertOptions
+		expected string
+	}{
+		{"default", ConvertOptions{}, "「alt」／「wrong1」「wrong2」は誤答／「partial」はもう一度"},
+		{"japanese", ConvertOptions{CriteriaLanguage: LanguageJapanese}, "「alt」／「wrong1」「wrong2」は誤答／「partial」はもう一度"},
+		{"english", ConvertOptions{CriteriaLanguage: LanguageEnglish}, `"alt" / "wrong1", "wrong2" are incorrect / "partial" — ask again`},
+		{
+			"english preserve order",
+			ConvertOptions{CriteriaLanguage: LanguageEnglish, PreserveCriteriaOrder: true},
+			`"partial" — ask again / "alt" / "wrong1", "wrong2" are incorrect`,
+		},
+		{"unknown language", ConvertOptions{CriteriaLanguage: "fr"}, "「alt」／「wrong1」「wrong2」は誤答／「partial」はもう一度"},
+		{"quotes", ConvertOptions{Quotes: [2]string{"『", "』"}}, "『alt』／『wrong1』『wrong2』は誤答／『partial』はもう一度"},
+		{"japanese quotes", ConvertOptions{Quotes: japaneseQuotes}, "「alt」／「wrong1」「wrong2」は誤答／「partial」はもう一度"},
+		{
+			"english with quotes",
+			ConvertOptions{CriteriaLanguage: LanguageEnglish, Quotes: [2]string{"“", "”"}},
+			"“alt” / “wrong1”, “wrong2” are incorrect / “partial” — ask again",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := formatItemCriteria(item, tt.opts); got != tt.expected {
+				t.Errorf("formatItemCriteria() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+
+	nested := QuizItem{Criteria: map[string][]string{"ok": {"映画「A」の原作"}, "ng": {"「A」"}}}
+	if got, want := formatItemCriteria(nested, ConvertOptions{}), "『映画「A」の原作』／「A」は誤答"; got != want {
+		t.Errorf("formatItemCriteria() = %q, want %q", got, want)
+	}
+	if got, want := formatItemCriteria(nested, ConvertOptions{Quotes: [2]string{"“", "”"}}), "“映画「A」の原作”／“「A」”は誤答"; got != want {
+		t.Errorf("formatItemCriteria() = %q, want %q", got, want)
+	}
+
+	single := QuizItem{Criteria: map[string][]string{"ng": {"wrong"}}}
+	if got := formatItemCriteria(single, ConvertOptions{CriteriaLanguage: LanguageEnglish}); got != `"wrong" is incorrect` {
+		t.Errorf("formatItemCriteria() = %q, want %q", got, `"wrong" is incorrect`)
+	}
+}
+
+func TestBuiltinTemplates_CriteriaLanguage(t *testing.T) {
+	items := []QuizItem{{
+		Question: "q1", Answer: "a1",
+		Criteria:      map[string][]string{"ok": {"alt"}, "ng": {"wrong"}},
+		CriteriaOrder: []string{"ng", "ok"},
+	}}
+	opts := ConvertOptions{CriteriaLanguage: LanguageEnglish}
+	tests := []struct {
+		format   string
+		expected string
+	}{
+		{"markdown", `**Criteria:** "alt" / "wrong" is incorrect`},
+		{"html", `<strong>判定:</strong> "alt" / "wrong" is incorrect`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			f, err := LookupFormatter(tt.format)
+			if err != nil {
+				t.Fatalf("LookupFormatter() error = %v", err)
+			}
+			var buf bytes.Buffer
+			if err := f.Format(&buf, items, opts); err != nil {
+				t.Fatalf("Format() error = %v", err)
+			}
+			if !strings.Contains(buf.String(), tt.expected) {
+				t.Errorf("output does not contain %q:\n%s", tt.expected, buf.String())
+			}
+		})
+	}
+
+	opts.TemplateText = `{{range .Items}}{{formatCriteria .Criteria}}|{{formatCriteriaInOrder .Criteria .CriteriaOrder}}{{end}}`
+	var buf bytes.Buffer
+	if err := WriteTemplate(&buf, items, "criteria.txt", opts); err != nil {
+		t.Fatalf("WriteTemplate() error = %v", err)
+	}
+	if want := `"alt" / "wrong" is incorrect|"wrong" is incorrect / "alt"`; buf.String() != want {
+		t.Errorf("template output = %q, want %q", buf.String(), want)
+	}
+}
+
+func TestTemplateFuncs_Quotes(t *testing.T) {
+	items := []QuizItem{{Question: "q1", Answer: "答え"}, {Question: "q2", Answer: "映画「A」"}}
+	tests := []struct {
+		name     string
+		opts     ConvertOptions
+		expected string
+	}{
+		{"default", ConvertOptions{}, "「答え」|『答え』|“答え”|「答え」\n『映画「A」』|『映画「A」』|“映画「A」”|『映画「A」』\n"},
+		{"quotes", ConvertOptions{Quotes: [2]string{"“", "”"}}, "“答え”|『答え』|“答え”|「答え」\n“映画「A」”|『映画「A」』|“映画「A」”|『映画「A」』\n"},
+		{"english", ConvertOptions{CriteriaLanguage: LanguageEnglish}, `"答え"|『答え』|“答え”|「答え」` + "\n" + `"映画「A」"|『映画「A」』|“映画「A」”|『映画「A」』` + "\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.opts.TemplateText = `{{range .Items}}{{addQuotes .Answer}}|{{quoteWith "『" "』" .Answer}}|{{.Answer | quoteWith "“" "”"}}|{{nestQuotes .Answer}}
+{{end}}`
+			var buf bytes.Buffer
+			if err := WriteTemplate(&buf, items, "quotes.txt", tt.opts); err != nil {
+				t.Fatalf("WriteTemplate() error = %v", err)
+			}
+			if buf.String() != tt.expected {
+				t.Errorf("template output = %q, want %q", buf.String(), tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,525 @@
+// CSV出力の列構成を扱う処理です．
+package quiz_yaml_converter
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding/japanese"
+	"golang.org/x/text/transform"
+)
+
+// CSV出力のオプション
+type CSVOptions struct {
+	// 出力する列とその順序．空の場合はDefaultCSVColumnsを使用する．
+	Columns []string
+
+	// trueの場合，Columnsにcommentsが含まれていなくても末尾にcomments列を追加する．
+	IncludeComments bool
+
+	// comments列で複数のコメントをつなぐ文字列．空の場合は改行でつなぐ．
+	CommentSeparator string
+
+	// criteria列でok/ng/repeatの区分をつなぐ文字列．空の場合は"／"（CriteriaLanguageが英語の場合は" / "）でつなぐ．
+	CriteriaSeparator string
+
+	// criteria列で各区分の項目をつなぐ文字列．空の場合は各項目を「」（CriteriaLanguageが英語の場合は""）で囲んで羅列し，
+	// 指定した場合は引用符で囲まずにこの文字列でつなぐ．
+	CriteriaItemSeparator string
+
+	// trueの場合，ヘッダー行を出力しない．
+	NoHeader bool
+
+	// 列名からヘッダー行に表示するラベルへの対応．
+	// 含まれない列は列名がそのままラベルになる．
+	HeaderLabels map[string]string
+
+	// 出力する文字コード．空の場合はEncodingUTF8として扱う．
+	Encoding Encoding
+
+	// trueの場合，改行コードをCRLFにする．
+	CRLF bool
+
+	// trueの場合，すべてのフィールドを"で囲む．
+	AlwaysQuote bool
+
+	// フィールド内の改行の扱い．空の場合はNewlinesKeepとして扱う．
+	Newlines NewlineMode
+
+	// trueの場合，=, +, -, @（およびタブ・CR）で始まるフィールドの先頭に'を付け，
+	// 表計算ソフトで数式として解釈されないようにする．
+	EscapeFormulas bool
+
+	// 読み込み時のCSVの列と問題データのフィールドの対応（ReadCSVでのみ使用）．
+	// 指定した場合は，対応に含まれない列を無視する．
+	ColumnMapping CSVColumnMapping
+}
+
+// CSV出力の文字コード
+type Encoding string
+
+// 文字コード
+const (
+	EncodingUTF8     Encoding = "utf8"     // UTF-8（BOMなし）
+	EncodingUTF8BOM  Encoding = "utf8-bom" // BOM付きUTF-8（Excel向け）
+	EncodingShiftJIS Encoding = "sjis"     // Shift_JIS（CP932）
+)
+
+// CSVのフィールド内の改行の扱い
+type NewlineMode string
+
+// 改行の扱い
+const (
+	NewlinesKeep   NewlineMode = "keep"   // 改行をそのまま残し，フィールドを"で囲む
+	NewlinesEscape NewlineMode = "escape" // 改行を\nの2文字に置き換える
+	NewlinesSpace  NewlineMode = "space"  // 連続する改行を1つの空白にまとめる
+)
+
+// ParseNewlineMode は改行の扱いの名前を解析する．
+func ParseNewlineMode(name string) (NewlineMode, error) {
+	switch mode := NewlineMode(strings.ToLower(strings.TrimSpace(name))); mode {
+	case "":
+		return NewlinesKeep, nil
+	case NewlinesKeep, NewlinesEscape, NewlinesSpace:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("unsupported newline mode: %q (available: keep, escape, space)", name)
+	}
+}
+
+// replaceNewlines はmodeに従ってフィールド内の改行を置き換える．
+// CRLF，LF，CRのいずれも1つの改行として扱う．
+func replaceNewlines(field string, mode NewlineMode) string {
+	if !strings.ContainsAny(field, "\r\n") {
+		return field
+	}
+	switch mode {
+	case NewlinesEscape:
+		return newlineReplacer.Replace(field)
+	case NewlinesSpace:
+		lines := strings.FieldsFunc(field, func(r rune) bool { return r == '\r' || r == '\n' })
+		return strings.Join(lines, " ")
+	default:
+		return field
+	}
+}
+
+// 改行を\nの2文字に置き換えるReplacer
+var newlineReplacer = strings.NewReplacer("\r\n", `\n`, "\r", `\n`, "\n", `\n`)
+
+// UTF-8のBOM
+const utf8BOM = "\uFEFF"
+
+// ParseEncoding は文字コード名を解析する．
+// "utf-8"や"cp932"などの表記揺れも受け付ける．
+func ParseEncoding(name string) (Encoding, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "", "utf8", "utf-8":
+		return EncodingUTF8, nil
+	case "utf8-bom", "utf-8-bom", "utf8bom":
+		return EncodingUTF8BOM, nil
+	case "sjis", "shift_jis", "shift-jis", "cp932", "windows-31j":
+		return EncodingShiftJIS, nil
+	default:
+		return "", fmt.Errorf("unsupported encoding: %q (available: utf8, utf8-bom, sjis)", name)
+	}
+}
+
+// checkShiftJIS はShift_JISで表現できない文字が含まれていないかを調べる．
+func checkShiftJIS(s string) error {
+	encoder := japanese.ShiftJIS.NewEncoder()
+	if _, err := encoder.String(s); err == nil {
+		return nil
+	}
+	for _, r := range s {
+		if _, err := encoder.String(string(r)); err != nil {
+			return fmt.Errorf("character %q (U+%04X) cannot be encoded in Shift_JIS", r, r)
+		}
+	}
+	return fmt.Errorf("text cannot be encoded in Shift_JIS")
+}
+
+// 既定のCSV列構成
+var DefaultCSVColumns = []string{"question", "answer", "spell", "criteria"}
+
+// 列名ごとに，1問分のデータからセルの値を取り出す関数
+var csvColumnValues = map[string]func(item QuizItem, opts ConvertOptions) string{
+	"id":       func(item QuizItem, _ ConvertOptions) string { return item.ID },
+	"question": func(item QuizItem, _ ConvertOptions) string { return item.Question },
+	"answer":   func(item QuizItem, _ ConvertOptions) string { return item.Answer },
+	"reading":  func(item QuizItem, _ ConvertOptions) string { return item.Reading },
+	"spell":    func(item QuizItem, _ ConvertOptions) string { return item.FlatSpell() },
+	"genre":    func(item QuizItem, _ ConvertOptions) string { return item.Genre },
+	"difficulty": func(item QuizItem, _ ConvertOptions) string {
+		if item.Difficulty == 0 {
+			return ""
+		}
+		return strconv.Itoa(item.Difficulty)
+	},
+	"tags": func(item QuizItem, _ ConvertOptions) string { return strings.Join(item.Tags, ",") },
+	"comments": func(item QuizItem, opts ConvertOptions) string {
+		return strings.Join(item.Comments, opts.CSV.commentSeparator())
+	},
+	"criteria": func(item QuizItem, opts ConvertOptions) string {
+		if item.Criteria == nil {
+			return ""
+		}
+		order := defaultCriteriaOrder
+		if opts.PreserveCriteriaOrder {
+			order = item.CriteriaOrder
+		}
+		p := opts.criteriaPhrases()
+		if opts.CSV.CriteriaSeparator != "" {
+			p.separator = opts.CSV.CriteriaSeparator
+		}
+		return formatCriteria(item.Criteria, order, p, opts.CSV.CriteriaItemSeparator)
+	},
+	"source":    func(item QuizItem, _ ConvertOptions) string { return item.Source },
+	"reference": func(item QuizItem, _ ConvertOptions) string { return item.Reference },
+	"author":    func(item QuizItem, _ ConvertOptions) string { return item.Author },
+	"license":   func(item QuizItem, _ ConvertOptions) string { return item.License },
+	// 問題のバランスを表計算ソフトで確認するための，計算で求める列
+	"question_length": func(item QuizItem, _ ConvertOptions) string {
+		return strconv.Itoa(utf8.RuneCountInString(PlainQuestion(item)))
+	},
+	"answer_length": func(item QuizItem, _ ConvertOptions) string {
+		return strconv.Itoa(utf8.RuneCountInString(item.Answer))
+	},
+	"ok_count": func(item QuizItem, _ ConvertOptions) string { return strconv.Itoa(len(item.Criteria["ok"])) },
+}
+
+// CSVの列として指定可能な列名の一覧（表示用）
+var availableCSVColumns = []string{"id", "question", "answer", "reading", "spell", "genre", "difficulty", "tags", "comments", "criteria", "source", "reference", "author", "license", "question_length", "answer_length", "ok_count"}
+
+// 日本語のヘッダーラベル．-header-labels jaで使用する．
+var JapaneseCSVHeaderLabels = map[string]string{
+	"id":         "ID",
+	"question":   "問題",
+	"answer":     "答え",
+	"reading":    "読み",
+	"spell":      "原語",
+	"genre":      "ジャンル",
+	"difficulty": "難易度",
+	"tags":       "タグ",
+	"comments":   "コメント",
+	"criteria":   "判定",
+	"source":     "出典",
+	"reference":  "参照先",
+	"author":     "作者",
+	"license":    "ライセンス",
+
+	"question_length": "問題文字数",
+	"answer_length":   "答え文字数",
+	"ok_count":        "別解数",
+}
+
+// ParseCSVColumns はカンマ区切りの列名リストを解析する．
+// 空白は無視し，未知の列名や重複した列名が含まれている場合はエラーを返す．
+func ParseCSVColumns(spec string) ([]string, error) {
+	var columns []string
+	seen := map[string]bool{}
+	for _, name := range strings.Split(spec, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		if _, ok := csvColumnValues[name]; !ok {
+			return nil, fmt.Errorf("unknown CSV column: %q (available: %s)", name, strings.Join(availableCSVColumns, ", "))
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("duplicate CSV column: %q", name)
+		}
+		seen[name] = true
+		columns = append(columns, name)
+	}
+	if len(columns) == 0 {
+		return nil, fmt.Errorf("no CSV columns specified")
+	}
+	return columns, nil
+}
+
+// ParseCSVHeaderLabels は"question=問題,answer=答え"形式のヘッダーラベル指定を解析する．
+// "ja"を指定した場合はJapaneseCSVHeaderLabelsを返す．
+func ParseCSVHeaderLabels(spec string) (map[string]string, error) {
+	if strings.TrimSpace(spec) == "ja" {
+		return JapaneseCSVHeaderLabels, nil
+	}
+
+	labels := map[string]string{}
+	for _, pair := range strings.Split(spec, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		name, label, found := strings.Cut(pair, "=")
+		if !found {
+			return nil, fmt.Errorf("invalid header label: %q (expected column=label)", pair)
+		}
+		name = strings.ToLower(strings.TrimSpace(name))
+		if _, ok := csvColumnValues[name]; !ok {
+			return nil, fmt.Errorf("unknown CSV column: %q (available: %s)", name, strings.Join(availableCSVColumns, ", "))
+		}
+		labels[name] = strings.TrimSpace(label)
+	}
+	return labels, nil
+}
+
+// headerRow はヘッダー行に出力するラベルを返す．
+func (o CSVOptions) headerRow(columns []string) []string {
+	header := make([]string, len(columns))
+	for i, name := range columns {
+		header[i] = name
+		if label, ok := o.HeaderLabels[name]; ok {
+			header[i] = label
+		}
+	}
+	return header
+}
+
+// 既定のコメントの区切り文字
+const defaultCommentSeparator = "\n"
+
+// commentSeparator はcomments列で使用する区切り文字を返す．
+func (o CSVOptions) commentSeparator() string {
+	if o.CommentSeparator == "" {
+		return defaultCommentSeparator
+	}
+	return o.CommentSeparator
+}
+
+// criteriaSeparator はcriteria列で区分をつなぐ文字列を返す．
+func (o CSVOptions) criteriaSeparator() string {
+	if o.CriteriaSeparator == "" {
+		return defaultCriteriaSeparator
+	}
+	return o.CriteriaSeparator
+}
+
+// csvColumns はオプションで指定された列構成を返す．
+func (o CSVOptions) csvColumns() ([]string, error) {
+	columns := o.Columns
+	if len(columns) == 0 {
+		columns = DefaultCSVColumns
+	}
+	hasComments := false
+	for _, name := range columns {
+		if _, ok := csvColumnValues[name]; !ok {
+			return nil, fmt.Errorf("unknown CSV column: %q (available: %s)", name, strings.Join(availableCSVColumns, ", "))
+		}
+		if name == "comments" {
+			hasComments = true
+		}
+	}
+	if o.IncludeComments && !hasComments {
+		columns = append(append([]string{}, columns...), "comments")
+	}
+	return columns, nil
+}
+
+// WriteCSV は問題データをCSV形式でwに書き出す．
+// 出力する列はopts.CSV.Columnsに従う．
+func WriteCSV(w io.Writer, data []QuizItem, opts ConvertOptions) error {
+	cw, err := newCSVItemWriter(w, opts)
+	if err != nil {
+		return err
+	}
+	for _, item := range data {
+		if err := cw.Write(item); err != nil {
+			return err
+		}
+	}
+	return cw.Close()
+}
+
+// csvItemWriter は問題データを1問ずつCSVの行として書き出す．
+// 作成時にヘッダー行を書き出し，Closeで残りの出力を書き出す．
+type csvItemWriter struct {
+	writer  *csvRecordWriter
+	encoder io.WriteCloser // Shift_JISへの変換（UTF-8の場合はnil）
+	opts    ConvertOptions
+	columns []string
+	row     []string
+	index   int // 書き出した問題の数
+}
+
+// newCSVItemWriter はwに書き出すcsvItemWriterを作成し，BOMとヘッダー行を書き出す．
+func newCSVItemWriter(w io.Writer, opts ConvertOptions) (*csvItemWriter, error) {
+	columns, err := opts.CSV.csvColumns()
+	if err != nil {
+		return nil, err
+	}
+	if opts.AssignIDs && len(opts.CSV.Columns) == 0 {
+		columns = append([]string{"id"}, columns...)
+	}
+
+	var out io.Writer = w
+	var encoder io.WriteCloser
+	switch opts.CSV.Encoding {
+	case "", EncodingUTF8:
+	case EncodingUTF8BOM:
+		if _, err := io.WriteString(w, utf8BOM); err != nil {
+			return nil, fmt.Errorf("failed to write BOM: %w", err)
+		}
+	case EncodingShiftJIS:
+		encoder = transform.NewWriter(w, japanese.ShiftJIS.NewEncoder())
+		out = encoder
+	default:
+		return nil, fmt.Errorf("unsupported encoding: %q", opts.CSV.Encoding)
+	}
+
+	writer := newCSVRecordWriter(out, opts.CSV)
+
+	// Write header
+	if !opts.CSV.NoHeader {
+		if err := writer.Write(opts.CSV.headerRow(columns)); err != nil {
+			return nil, fmt.Errorf("failed to write CSV header: %w", err)
+		}
+	}
+	return &csvItemWriter{writer: writer, encoder: encoder, opts: opts, columns: columns, row: make([]string, len(columns))}, nil
+}
+
+// Write は1問分の行を書き出す．opts.AssignIDsがtrueの場合はidが未設定の問題にIDを割り当てる．
+// Shift_JISで表せない文字を含む場合は，列名を含むエラーをItemErrorとして返す．
+func (cw *csvItemWriter) Write(item QuizItem) error {
+	cw.index++
+	if cw.opts.AssignIDs && item.ID == "" {
+		item.ID = ContentID(item)
+	}
+	for i, name := range cw.columns {
+		cw.row[i] = csvColumnValues[name](item, cw.opts)
+		if cw.opts.CSV.Encoding == EncodingShiftJIS && !isASCII(cw.row[i]) {
+			if err := checkShiftJIS(cw.row[i]); err != nil {
+				return &ItemError{Index: cw.index, Question: QuestionSnippet(item), Err: fmt.Errorf("%s: %w", name, err)}
+			}
+		}
+	}
+	if err := cw.writer.Write(cw.row); err != nil {
+		return fmt.Errorf("failed to write CSV row: %w", err)
+	}
+	return nil
+}
+
+// Close はバッファに残った出力を書き出す．wは閉じない．
+func (cw *csvItemWriter) Close() error {
+	cw.writer.Flush()
+	if err := cw.writer.Error(); err != nil {
+		return fmt.Errorf("failed to write CSV: %w", err)
+	}
+	if cw.encoder != nil {
+		if err := cw.encoder.Close(); err != nil {
+			return fmt.Errorf("failed to encode CSV: %w", err)
+		}
+	}
+	return nil
+}
+
+// isASCII は文字列がASCII文字のみで構成されているかを返す．
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
+
+// csvRecordWriter はencoding/csvのWriterと同じ規則でCSVを書き出しつつ，
+// 全フィールドのクォートや数式のエスケープに対応したWriter．
+type csvRecordWriter struct {
+	w    *bufio.Writer
+	opts CSVOptions
+	err  error
+}
+
+func newCSVRecordWriter(w io.Writer, opts CSVOptions) *csvRecordWriter {
+	return &csvRecordWriter{w: bufio.NewWriter(w), opts: opts}
+}
+
+// 表計算ソフトで数式の開始とみなされる文字
+const formulaPrefixes = "=+-@\t\r"
+
+// escapeFormula は数式として解釈されうるフィールドの先頭に'を付ける．
+func escapeFormula(field string) string {
+	if field != "" && strings.ContainsRune(formulaPrefixes, rune(field[0])) {
+		return "'" + field
+	}
+	return field
+}
+
+// fieldNeedsQuotes はフィールドを"で囲む必要があるかを返す．
+// 判定規則はencoding/csvに合わせている．
+func fieldNeedsQuotes(field string) bool {
+	if field == "" {
+		return false
+	}
+	if field == `\.` {
+		return true
+	}
+	if strings.ContainsAny(field, ",\"\r\n") {
+		return true
+	}
+	r, _ := utf8.DecodeRuneInString(field)
+	return unicode.IsSpace(r)
+}
+
+// Write は1行分のレコードを書き出す．
+func (c *csvRecordWriter) Write(record []string) error {
+	if c.err != nil {
+		return c.err
+	}
+	for i, field := range record {
+		if i > 0 {
+			c.w.WriteByte(',')
+		}
+		field = replaceNewlines(field, c.opts.Newlines)
+		if c.opts.EscapeFormulas {
+			field = escapeFormula(field)
+		}
+		if !c.opts.AlwaysQuote && !fieldNeedsQuotes(field) {
+			c.w.WriteString(field)
+			continue
+		}
+		c.w.WriteByte('"')
+		for _, r := range field {
+			switch r {
+			case '"':
+				c.w.WriteString(`""`)
+			case '\r':
+				if !c.opts.CRLF {
+					c.w.WriteByte('\r')
+				}
+			case '\n':
+				if c.opts.CRLF {
+					c.w.WriteString("\r\n")
+				} else {
+					c.w.WriteByte('\n')
+				}
+			default:
+				c.w.WriteRune(r)
+			}
+		}
+		c.w.WriteByte('"')
+	}
+	if c.opts.CRLF {
+		_, c.err = c.w.WriteString("\r\n")
+	} else {
+		c.err = c.w.WriteByte('\n')
+	}
+	return c.err
+}
+
+// Flush はバッファに残っているデータを書き出す．
+func (c *csvRecordWriter) Flush() {
+	if err := c.w.Flush(); err != nil && c.err == nil {
+		c.err = err
+	}
+}
+
+// Error はこれまでの書き込みで発生したエラーを返す．
+func (c *csvRecordWriter) Error() error {
+	return c.err
+}
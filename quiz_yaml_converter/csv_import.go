@@ -0,0 +1,402 @@
+// CSVから問題データを読み込む処理です．
+package quiz_yaml_converter
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding/japanese"
+	"golang.org/x/text/transform"
+)
+
+// ReadCSV はWriteCSVで書き出した形式のCSVを読み込み，問題データを返す．
+// ヘッダー行の列名は列名そのもの，opts.CSV.HeaderLabelsのラベル，JapaneseCSVHeaderLabelsの
+// ラベルのいずれでもよい．opts.CSV.NoHeaderがtrueの場合は，WriteCSVと同じ規則で
+// 決まる列構成として読み込む．先頭のBOMは文字コードの指定に関係なく取り除く．
+//
+// opts.CSV.ColumnMappingを指定した場合は，ヘッダー行の代わりにその対応で列を決める．
+// この場合は対応に含まれない列を無視し，列の数が行ごとに異なっていてもよい（足りない列は空とする）．
+// 対応するセルがすべて空の行は読み飛ばす．
+func ReadCSV(r io.Reader, opts ConvertOptions) ([]QuizItem, error) {
+	switch opts.CSV.Encoding {
+	case "", EncodingUTF8, EncodingUTF8BOM:
+	case EncodingShiftJIS:
+		r = transform.NewReader(r, japanese.ShiftJIS.NewDecoder())
+	default:
+		return nil, fmt.Errorf("unsupported encoding: %q", opts.CSV.Encoding)
+	}
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSV: %w", err)
+	}
+	content = bytes.TrimPrefix(content, []byte(utf8BOM))
+
+	reader := csv.NewReader(bytes.NewReader(content))
+	reader.FieldsPerRecord = -1
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse CSV: %w", err)
+	}
+
+	mapped := len(opts.CSV.ColumnMapping) > 0
+	var columns []string
+	if mapped {
+		var header []string
+		if !opts.CSV.NoHeader {
+			if len(records) == 0 {
+				return nil, fmt.Errorf("CSV has no header row")
+			}
+			header = records[0]
+			records = records[1:]
+		}
+		if columns, err = opts.CSV.ColumnMapping.columns(header); err != nil {
+			return nil, err
+		}
+	} else if opts.CSV.NoHeader {
+		if columns, err = opts.CSV.csvColumns(); err != nil {
+			return nil, err
+		}
+		if opts.AssignIDs && len(opts.CSV.Columns) == 0 {
+			columns = append([]string{"id"}, columns...)
+		}
+	} else {
+		if len(records) == 0 {
+			return nil, fmt.Errorf("CSV has no header row")
+		}
+		if columns, err = csvHeaderColumns(records[0], opts.CSV); err != nil {
+			return nil, err
+		}
+		records = records[1:]
+	}
+
+	items := make([]QuizItem, 0, len(records))
+	for i, record := range records {
+		if !mapped && len(record) != len(columns) {
+			return nil, fmt.Errorf("CSV row %d: expected %d fields, got %d", i+1, len(columns), len(record))
+		}
+		if mapped && isBlankCSVRecord(record, columns) {
+			continue
+		}
+		var item QuizItem
+		for j, name := range columns {
+			if name == "" {
+				continue
+			}
+			var field string
+			if j < len(record) {
+				field = record[j]
+			}
+			if opts.CSV.EscapeFormulas {
+				field = unescapeFormula(field)
+			}
+			if opts.CSV.Newlines == NewlinesEscape {
+				field = strings.ReplaceAll(field, `\n`, "\n")
+			}
+			if err := setCSVColumnValue(&item, name, field, opts); err != nil {
+				return nil, fmt.Errorf("CSV row %d: %w", i+1, err)
+			}
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// LoadCSVFile はCSVファイルを読み込み，問題データを返す．
+func LoadCSVFile(csvFilePath string, opts ConvertOptions) ([]QuizItem, error) {
+	f, err := os.Open(csvFilePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open CSV file: %w", err)
+	}
+	defer f.Close()
+	return ReadCSV(f, opts)
+}
+
+// csvHeaderColumns はヘッダー行の各ラベルに対応する列名を返す．
+func csvHeaderColumns(header []string, opts CSVOptions) ([]string, error) {
+	labels := map[string]string{}
+	for name, label := range JapaneseCSVHeaderLabels {
+		labels[label] = name
+	}
+	for name, label := range opts.HeaderLabels {
+		labels[label] = name
+	}
+
+	columns := make([]string, len(header))
+	seen := map[string]bool{}
+	for i, label := range header {
+		label = strings.TrimSpace(label)
+		name, ok := labels[label]
+		if !ok {
+			name = strings.ToLower(label)
+			if _, known := csvColumnValues[name]; !known {
+				return nil, fmt.Errorf("unknown CSV column: %q (available: %s)", label, strings.Join(availableCSVColumns, ", "))
+			}
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("duplicate CSV column: %q", label)
+		}
+		seen[name] = true
+		columns[i] = name
+	}
+	return columns, nil
+}
+
+// CSVColumnMapping は読み込むCSVの列と問題データのフィールドの対応．
+// キーは列名（question, answerなど），値はCSVの列の位置（1始まりの番号）またはヘッダー行の列名．
+type CSVColumnMapping map[string]string
+
+// ParseCSVColumnMapping は"question=1,answer=3,spell=4"形式の列の対応を解析する．
+// 値に番号以外を書いた場合は，ヘッダー行の列名として扱う（例: question=問題文,answer=正解）．
+func ParseCSVColumnMapping(spec string) (CSVColumnMapping, error) {
+	mapping := CSVColumnMapping{}
+	for _, pair := range strings.Split(spec, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		name, column, found := strings.Cut(pair, "=")
+		name, column = strings.ToLower(strings.TrimSpace(name)), strings.TrimSpace(column)
+		if !found || column == "" {
+			return nil, fmt.Errorf("invalid column mapping: %q (expected field=column)", pair)
+		}
+		if _, ok := csvColumnValues[name]; !ok {
+			return nil, fmt.Errorf("unknown CSV column: %q (available: %s)", name, strings.Join(availableCSVColumns, ", "))
+		}
+		if _, ok := mapping[name]; ok {
+			return nil, fmt.Errorf("duplicate CSV column: %q", name)
+		}
+		if n, err := strconv.Atoi(column); err == nil && n < 1 {
+			return nil, fmt.Errorf("invalid column number for %s: %d (columns start at 1)", name, n)
+		}
+		mapping[name] = column
+	}
+	if len(mapping) == 0 {
+		return nil, fmt.Errorf("no CSV columns specified")
+	}
+	return mapping, nil
+}
+
+// columns はCSVの各列に対応する列名を返す．対応のない列は空文字列とする．
+// headerがnilの場合（ヘッダー行がない場合）は，列の位置で指定した対応だけを使える．
+func (m CSVColumnMapping) columns(header []string) ([]string, error) {
+	columns := make([]string, len(header))
+	// 対応の解決順序を一定にするため，列名の順に処理する
+	for _, name := range availableCSVColumns {
+		column, ok := m[name]
+		if !ok {
+			continue
+		}
+		i, err := strconv.Atoi(column)
+		if err != nil {
+			if header == nil {
+				return nil, fmt.Errorf("column %q for %s needs a header row", column, name)
+			}
+			i = slices.IndexFunc(header, func(label string) bool { return strings.EqualFold(strings.TrimSpace(label), column) })
+			if i < 0 {
+				return nil, fmt.Errorf("CSV column not found: %q", column)
+			}
+		} else {
+			i--
+		}
+		for len(columns) <= i {
+			columns = append(columns, "")
+		}
+		if columns[i] != "" {
+			return nil, fmt.Errorf("CSV column %q is mapped to both %s and %s", column, columns[i], name)
+		}
+		columns[i] = name
+	}
+	return columns, nil
+}
+
+// isBlankCSVRecord は対応のある列のセルがすべて空の行かを返す．
+func isBlankCSVRecord(record, columns []string) bool {
+	for j, name := range columns {
+		if name != "" && j < len(record) && strings.TrimSpace(record[j]) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// setCSVColumnValue はCSVのセルの値を問題データの対応するフィールドに設定する．
+// csvColumnValuesの逆の変換を行う．計算で求める列（question_length，answer_length，ok_count）は無視する．
+func setCSVColumnValue(item *QuizItem, name, value string, opts ConvertOptions) error {
+	switch name {
+	case "id":
+		item.ID = value
+	case "question":
+		item.Question = value
+	case "answer":
+		item.Answer = value
+	case "reading":
+		item.Reading = value
+	case "spell":
+		item.Spell = value
+	case "genre":
+		item.Genre = value
+	case "source":
+		item.Source = value
+	case "reference":
+		item.Reference = value
+	case "author":
+		item.Author = value
+	case "license":
+		item.License = value
+	case "difficulty":
+		if strings.TrimSpace(value) == "" {
+			break
+		}
+		difficulty, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil {
+			return fmt.Errorf("invalid difficulty: %q", value)
+		}
+		item.Difficulty = difficulty
+	case "tags":
+		item.Tags = splitCSVList(value, ",")
+	case "comments":
+		item.Comments = splitCSVList(value, opts.CSV.commentSeparator())
+	case "criteria":
+		item.Criteria = parseCriteria(value, opts.CSV.criteriaSeparator(), opts.CSV.CriteriaItemSeparator)
+	}
+	return nil
+}
+
+// splitCSVList はsepでつながれたセルの値をリストに戻す．空のセルはnilとする．
+func splitCSVList(value, sep string) []string {
+	if value == "" {
+		return nil
+	}
+	parts := strings.Split(value, sep)
+	if sep == "," {
+		for i := range parts {
+			parts[i] = strings.TrimSpace(parts[i])
+		}
+	}
+	return parts
+}
+
+// unescapeFormula はescapeFormulaで先頭に付けた'を取り除く．
+func unescapeFormula(field string) string {
+	if len(field) > 1 && field[0] == '\'' && strings.ContainsRune(formulaPrefixes, rune(field[1])) {
+		return field[1:]
+	}
+	return field
+}
+
+// ParseCriteria はFormatCriteriaでフォーマットした正誤判定の文字列を解析する．
+// 「別解1」「別解2」／「誤答1」は誤答／「もう一度1」はもう一度の形式を受け付け（「」を含む項目は『』で囲む），
+// 引用符の外側にある文字列は直前の項目に含める．空文字列の場合はnilを返す．
+func ParseCriteria(s string) map[string][]string {
+	return parseCriteria(s, defaultCriteriaSeparator, "")
+}
+
+// parseCriteria はformatCriteriaでsepとitemSepを指定してフォーマットした正誤判定の文字列を解析する．
+// itemSepが空の場合は各項目を「」で囲んだ形式として扱う．
+func parseCriteria(s, sep, itemSep string) map[string][]string {
+	if strings.TrimSpace(s) == "" {
+		return nil
+	}
+	criteria := map[string][]string{}
+	var sections []string
+	if itemSep == "" {
+		sections = splitOutsideQuotes(s, sep)
+	} else {
+		sections = strings.Split(s, sep)
+	}
+	for _, section := range sections {
+		key := "ok"
+		for k, suffix := range criteriaSuffixes {
+			if suffix != "" && strings.HasSuffix(section, suffix) {
+				key = k
+				section = strings.TrimSuffix(section, suffix)
+				break
+			}
+		}
+		if itemSep == "" {
+			criteria[key] = append(criteria[key], splitQuotedItems(section)...)
+			continue
+		}
+		for _, item := range strings.Split(section, itemSep) {
+			if item != "" {
+				criteria[key] = append(criteria[key], item)
+			}
+		}
+	}
+	return criteria
+}
+
+// closingQuotes は正誤判定の項目を囲む開き引用符と閉じ引用符の対応．
+// 「」を含む項目は『』で囲まれる（NestQuotes参照）．
+var closingQuotes = map[rune]rune{'「': '」', '『': '』'}
+
+// splitOutsideQuotes は「」と『』の外側にあるsepで文字列を分割する．
+func splitOutsideQuotes(s, sep string) []string {
+	var parts []string
+	depth, start := 0, 0
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		switch {
+		case r == '「' || r == '『':
+			depth++
+		case (r == '」' || r == '』') && depth > 0:
+			depth--
+		case depth == 0 && strings.HasPrefix(s[i:], sep):
+			parts = append(parts, s[start:i])
+			i += len(sep)
+			start = i
+			continue
+		}
+		i += size
+	}
+	return append(parts, s[start:])
+}
+
+// splitQuotedItems は「項目1」『項目2』の形式の文字列を項目のリストに分割する．
+// 項目を囲む引用符の内側に入れ子になった引用符はそのまま残す．
+func splitQuotedItems(s string) []string {
+	var items []string
+	var current strings.Builder
+	var open, close rune
+	depth := 0
+	flush := func() {
+		if current.Len() > 0 {
+			items = append(items, current.String())
+			current.Reset()
+		}
+	}
+	for _, r := range s {
+		switch {
+		case depth == 0 && closingQuotes[r] != 0:
+			flush()
+			open, close = r, closingQuotes[r]
+			depth++
+		case depth > 0 && r == open:
+			depth++
+			current.WriteRune(r)
+		case r == close && depth == 1:
+			depth--
+			items = append(items, current.String())
+			current.Reset()
+		case r == close && depth > 1:
+			depth--
+			current.WriteRune(r)
+		default:
+			if depth == 0 && len(items) > 0 && current.Len() == 0 {
+				// 引用符の後ろに続く文字列は直前の項目に含める
+				items[len(items)-1] = AddQuotesIfNeeded(items[len(items)-1])
+				current.WriteString(items[len(items)-1])
+				items = items[:len(items)-1]
+			}
+			current.WriteRune(r)
+		}
+	}
+	flush()
+	return items
+}
@@ -0,0 +1,269 @@
+package quiz_yaml_converter
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseCriteria(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected map[string][]string
+	}{
+		{"empty", "", nil},
+		{"ok only", "「別解1」「別解2」", map[string][]string{"ok": {"別解1", "別解2"}}},
+		{
+			"all sections",
+			"「別解」／「誤答1」「誤答2」は誤答／「もう一度」はもう一度",
+			map[string][]string{"ok": {"別解"}, "ng": {"誤答1", "誤答2"}, "repeat": {"もう一度"}},
+		},
+		{"ng first", "「誤答」は誤答／「別解」", map[string][]string{"ng": {"誤答"}, "ok": {"別解"}}},
+		{"text after quotes", "「美術館」（おまけ）「別解」", map[string][]string{"ok": {"「美術館」（おまけ）", "別解"}}},
+		{"nested quotes", "「「A」の別名」", map[string][]string{"ok": {"「A」の別名"}}},
+		{"separator inside quotes", "「A／B」", map[string][]string{"ok": {"A／B"}}},
+		{"nested quotes outside", "『Aの「B」』「C」", map[string][]string{"ok": {"Aの「B」", "C"}}},
+		{"nested quotes with text after", "『A「B」』（おまけ）", map[string][]string{"ok": {"『A「B」』（おまけ）"}}},
+		{"separator inside nested quotes", "『A「B」／C』は誤答", map[string][]string{"ng": {"A「B」／C"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := ParseCriteria(tt.input)
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("ParseCriteria(%q) = %v, want %v", tt.input, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParseCriteria_FormatCriteria(t *testing.T) {
+	criteria := map[string][]string{"ok": {"別解", "Aの「B」", "「C」（おまけ）"}, "ng": {"誤答", "『D』"}, "repeat": {"もう一度"}}
+	if result := ParseCriteria(FormatCriteria(criteria)); !reflect.DeepEqual(result, criteria) {
+		t.Errorf("ParseCriteria(FormatCriteria(%v)) = %v", criteria, result)
+	}
+}
+
+func TestReadCSV(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		opts     ConvertOptions
+		expected []QuizItem
+	}{
+		{
+			name:  "default columns",
+			input: "question,answer,spell,criteria\n問題1,答え1,Answer,「別解」／「誤答」は誤答\n",
+			expected: []QuizItem{{
+				Question: "問題1", Answer: "答え1", Spell: "Answer",
+				Criteria: map[string][]string{"ok": {"別解"}, "ng": {"誤答"}},
+			}},
+		},
+		{
+			name:  "japanese labels with BOM",
+			input: utf8BOM + "ID,問題,答え,タグ,コメント\nq1,問題1,答え1,\"a, b\",\"c1\nc2\"\n",
+			expected: []QuizItem{{
+				ID: "q1", Question: "問題1", Answer: "答え1",
+				Tags: []string{"a", "b"}, Comments: []string{"c1", "c2"},
+			}},
+		},
+		{
+			name:     "computed columns",
+			input:    "question,answer,question_length,答え文字数,ok_count\n問題1,答え1,3,3,0\n",
+			expected: []QuizItem{{Question: "問題1", Answer: "答え1"}},
+		},
+		{
+			name:  "custom labels and comment separator",
+			input: "Q,A,comments\n問題1,答え1,c1 | c2\n",
+			opts: ConvertOptions{CSV: CSVOptions{
+				HeaderLabels:     map[string]string{"question": "Q", "answer": "A"},
+				CommentSeparator: " | ",
+			}},
+			expected: []QuizItem{{Question: "問題1", Answer: "答え1", Comments: []string{"c1", "c2"}}},
+		},
+		{
+			name:  "criteria separators",
+			input: "question,answer,criteria\n問題1,答え1,別解1;別解2|誤答は誤答\n",
+			opts:  ConvertOptions{CSV: CSVOptions{CriteriaSeparator: "|", CriteriaItemSeparator: ";"}},
+			expected: []QuizItem{{
+				Question: "問題1", Answer: "答え1",
+				Criteria: map[string][]string{"ok": {"別解1", "別解2"}, "ng": {"誤答"}},
+			}},
+		},
+		{
+			name:     "escaped newlines",
+			input:    "question,answer,comments\n1行目\\n2行目,答え1,c1\\nc2\n",
+			opts:     ConvertOptions{CSV: CSVOptions{Newlines: NewlinesEscape}},
+			expected: []QuizItem{{Question: "1行目\n2行目", Answer: "答え1", Comments: []string{"c1", "c2"}}},
+		},
+		{
+			name:     "difficulty",
+			input:    "question,answer,難易度\n問題1,答え1,3\n問題2,答え2,\n",
+			expected: []QuizItem{{Question: "問題1", Answer: "答え1", Difficulty: 3}, {Question: "問題2", Answer: "答え2"}},
+		},
+		{
+			name:     "no header",
+			input:    "問題1,答え1,,\n",
+			opts:     ConvertOptions{CSV: CSVOptions{NoHeader: true}},
+			expected: []QuizItem{{Question: "問題1", Answer: "答え1"}},
+		},
+		{
+			name:     "escaped formulas",
+			input:    "question,answer\n'=1+1,'-1\n",
+			opts:     ConvertOptions{CSV: CSVOptions{EscapeFormulas: true}},
+			expected: []QuizItem{{Question: "=1+1", Answer: "-1"}},
+		},
+		{
+			name:     "header only",
+			input:    "question,answer\n",
+			expected: []QuizItem{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ReadCSV(strings.NewReader(tt.input), tt.opts)
+			if err != nil {
+				t.Fatalf("ReadCSV() error = %v", err)
+			}
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("ReadCSV() = %#v, want %#v", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestReadCSV_ShiftJIS(t *testing.T) {
+	opts := ConvertOptions{CSV: CSVOptions{Encoding: EncodingShiftJIS}}
+	data := []QuizItem{{Question: "日本語の問題", Answer: "答え"}}
+	var buf bytes.Buffer
+	if err := WriteCSV(&buf, data, opts); err != nil {
+		t.Fatalf("WriteCSV() error = %v", err)
+	}
+	result, err := ReadCSV(&buf, opts)
+	if err != nil {
+		t.Fatalf("ReadCSV() error = %v", err)
+	}
+	if !reflect.DeepEqual(result, data) {
+		t.Errorf("ReadCSV() = %#v, want %#v", result, data)
+	}
+}
+
+func TestReadCSV_Invalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{"empty", ""},
+		{"unknown column", "question,unknown\n"},
+		{"duplicate column", "question,問題\n"},
+		{"field count", "question,answer\n問題1\n"},
+		{"invalid difficulty", "question,answer,difficulty\n問題1,答え1,hard\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ReadCSV(strings.NewReader(tt.input), ConvertOptions{}); err == nil {
+				t.Errorf("ReadCSV(%q) expected error, got nil", tt.input)
+			}
+		})
+	}
+}
+
+func TestParseCSVColumnMapping(t *testing.T) {
+	tests := []struct {
+		name     string
+		spec     string
+		expected CSVColumnMapping
+		wantErr  bool
+	}{
+		{"numbers", "question=1,answer=3,spell=4", CSVColumnMapping{"question": "1", "answer": "3", "spell": "4"}, false},
+		{"header names", " Question = 問題文 , answer=正解", CSVColumnMapping{"question": "問題文", "answer": "正解"}, false},
+		{"empty", "", nil, true},
+		{"missing column", "question", nil, true},
+		{"unknown field", "title=1", nil, true},
+		{"duplicate field", "question=1,question=2", nil, true},
+		{"zero", "question=0", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ParseCSVColumnMapping(tt.spec)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseCSVColumnMapping(%q) error = %v, wantErr %v", tt.spec, err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("ParseCSVColumnMapping(%q) = %v, want %v", tt.spec, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestReadCSV_ColumnMapping(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		mapping  CSVColumnMapping
+		noHeader bool
+		expected []QuizItem
+	}{
+		{
+			name:     "numbers without header",
+			input:    "1,問題1,メモ,答え1,Answer\n2,問題2,,答え2\n,,,,\n",
+			mapping:  CSVColumnMapping{"question": "2", "answer": "4", "spell": "5"},
+			noHeader: true,
+			expected: []QuizItem{
+				{Question: "問題1", Answer: "答え1", Spell: "Answer"},
+				{Question: "問題2", Answer: "答え2"},
+			},
+		},
+		{
+			name:    "header names",
+			input:   "No.,問題文,正解,出典\n1,問題1,答え1,本\n",
+			mapping: CSVColumnMapping{"question": "問題文", "answer": "正解", "comments": "出典"},
+			expected: []QuizItem{
+				{Question: "問題1", Answer: "答え1", Comments: []string{"本"}},
+			},
+		},
+		{
+			name:     "numbers with header",
+			input:    "Q,A\n問題1,答え1\n",
+			mapping:  CSVColumnMapping{"question": "1", "answer": "2"},
+			expected: []QuizItem{{Question: "問題1", Answer: "答え1"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := ConvertOptions{CSV: CSVOptions{ColumnMapping: tt.mapping, NoHeader: tt.noHeader}}
+			result, err := ReadCSV(strings.NewReader(tt.input), opts)
+			if err != nil {
+				t.Fatalf("ReadCSV() error = %v", err)
+			}
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("ReadCSV() = %#v, want %#v", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestReadCSV_ColumnMappingInvalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		mapping  CSVColumnMapping
+		noHeader bool
+	}{
+		{"header not found", "問題文,正解\n", CSVColumnMapping{"question": "問題", "answer": "正解"}, false},
+		{"header name without header", "問題1,答え1\n", CSVColumnMapping{"question": "問題文"}, true},
+		{"same column", "問題文,正解\n", CSVColumnMapping{"question": "1", "answer": "問題文"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := ConvertOptions{CSV: CSVOptions{ColumnMapping: tt.mapping, NoHeader: tt.noHeader}}
+			if _, err := ReadCSV(strings.NewReader(tt.input), opts); err == nil {
+				t.Errorf("ReadCSV(%q) expected error, got nil", tt.input)
+			}
+		})
+	}
+}
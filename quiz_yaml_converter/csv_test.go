@@ -0,0 +1,525 @@
+package quiz_yaml_converter
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseCSVColumns(t *testing.T) {
+	tests := []struct {
+		name     string
+		spec     string
+		expected []string
+	}{
+		{"default layout", "question,answer,spell,criteria", []string{"question", "answer", "spell", "criteria"}},
+		{"reordered with spaces", " answer , question ", []string{"answer", "question"}},
+		{"new columns", "id,genre,comments", []string{"id", "genre", "comments"}},
+		{"upper case", "Question,ANSWER", []string{"question", "answer"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ParseCSVColumns(tt.spec)
+			if err != nil {
+				t.Fatalf("ParseCSVColumns(%q) error = %v", tt.spec, err)
+			}
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("ParseCSVColumns(%q) = %v, want %v", tt.spec, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParseCSVColumns_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		spec string
+	}{
+		{"unknown column", "question,unknown"},
+		{"duplicate column", "question,question"},
+		{"empty", " , "},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseCSVColumns(tt.spec); err == nil {
+				t.Errorf("ParseCSVColumns(%q) expected error, got nil", tt.spec)
+			}
+		})
+	}
+}
+
+func TestWriteCSV_Columns(t *testing.T) {
+	data := []QuizItem{
+		{
+			ID:       "q1",
+			Question: "問題1",
+			Answer:   "答え1",
+			Genre:    "科学",
+			Tags:     []string{"a", "b"},
+			Comments: []string{"コメント1", "コメント2"},
+			Criteria: map[string][]string{"ng": {"ng1"}},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		columns  []string
+		expected string
+	}{
+		{
+			name:     "default columns",
+			columns:  nil,
+			expected: "question,answer,spell,criteria\n問題1,答え1,,「ng1」は誤答\n",
+		},
+		{
+			name:     "reordered subset",
+			columns:  []string{"answer", "question"},
+			expected: "answer,question\n答え1,問題1\n",
+		},
+		{
+			name:     "extra columns",
+			columns:  []string{"id", "genre", "tags", "comments"},
+			expected: "id,genre,tags,comments\nq1,科学,\"a,b\",\"コメント1\nコメント2\"\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			err := WriteCSV(&buf, data, ConvertOptions{CSV: CSVOptions{Columns: tt.columns}})
+			if err != nil {
+				t.Fatalf("WriteCSV() error = %v", err)
+			}
+			if buf.String() != tt.expected {
+				t.Errorf("WriteCSV() = %q, want %q", buf.String(), tt.expected)
+			}
+		})
+	}
+}
+
+func TestWriteCSV_ComputedColumns(t *testing.T) {
+	data := []QuizItem{
+		{Question: "日本の／首都は？", Answer: "東京", Criteria: map[string][]string{"ok": {"東京都", "トーキョー"}, "ng": {"京都"}}},
+		{Question: "q2", Answer: "a2"},
+	}
+	opts := ConvertOptions{CSV: CSVOptions{Columns: []string{"question_length", "answer_length", "ok_count"}, HeaderLabels: JapaneseCSVHeaderLabels}}
+	var buf bytes.Buffer
+	if err := WriteCSV(&buf, data, opts); err != nil {
+		t.Fatalf("WriteCSV() error = %v", err)
+	}
+	expected := "問題文字数,答え文字数,別解数\n7,2,2\n2,2,0\n"
+	if buf.String() != expected {
+		t.Errorf("WriteCSV() = %q, want %q", buf.String(), expected)
+	}
+}
+
+func TestWriteCSV_UnknownColumn(t *testing.T) {
+	var buf bytes.Buffer
+	err := WriteCSV(&buf, nil, ConvertOptions{CSV: CSVOptions{Columns: []string{"unknown"}}})
+	if err == nil {
+		t.Error("Expected error, but got nil")
+	}
+}
+
+func TestParseCSVHeaderLabels(t *testing.T) {
+	tests := []struct {
+		name     string
+		spec     string
+		expected map[string]string
+	}{
+		{"japanese preset", "ja", JapaneseCSVHeaderLabels},
+		{"custom labels", "question=問題, answer = 答え", map[string]string{"question": "問題", "answer": "答え"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ParseCSVHeaderLabels(tt.spec)
+			if err != nil {
+				t.Fatalf("ParseCSVHeaderLabels(%q) error = %v", tt.spec, err)
+			}
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("ParseCSVHeaderLabels(%q) = %v, want %v", tt.spec, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParseCSVHeaderLabels_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		spec string
+	}{
+		{"missing label", "question"},
+		{"unknown column", "unknown=不明"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseCSVHeaderLabels(tt.spec); err == nil {
+				t.Errorf("ParseCSVHeaderLabels(%q) expected error, got nil", tt.spec)
+			}
+		})
+	}
+}
+
+func TestWriteCSV_Header(t *testing.T) {
+	data := []QuizItem{{Question: "問題1", Answer: "答え1"}}
+
+	tests := []struct {
+		name     string
+		csv      CSVOptions
+		expected string
+	}{
+		{
+			name:     "no header",
+			csv:      CSVOptions{Columns: []string{"question", "answer"}, NoHeader: true},
+			expected: "問題1,答え1\n",
+		},
+		{
+			name:     "japanese labels",
+			csv:      CSVOptions{HeaderLabels: JapaneseCSVHeaderLabels},
+			expected: "問題,答え,原語,判定\n問題1,答え1,,\n",
+		},
+		{
+			name:     "partial labels",
+			csv:      CSVOptions{Columns: []string{"question", "answer"}, HeaderLabels: map[string]string{"answer": "A"}},
+			expected: "question,A\n問題1,答え1\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := WriteCSV(&buf, data, ConvertOptions{CSV: tt.csv}); err != nil {
+				t.Fatalf("WriteCSV() error = %v", err)
+			}
+			if buf.String() != tt.expected {
+				t.Errorf("WriteCSV() = %q, want %q", buf.String(), tt.expected)
+			}
+		})
+	}
+}
+
+func TestParseEncoding(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected Encoding
+	}{
+		{"", EncodingUTF8},
+		{"UTF-8", EncodingUTF8},
+		{"utf8-bom", EncodingUTF8BOM},
+		{"sjis", EncodingShiftJIS},
+		{"cp932", EncodingShiftJIS},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			result, err := ParseEncoding(tt.input)
+			if err != nil {
+				t.Fatalf("ParseEncoding(%q) error = %v", tt.input, err)
+			}
+			if result != tt.expected {
+				t.Errorf("ParseEncoding(%q) = %q, want %q", tt.input, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParseEncoding_Invalid(t *testing.T) {
+	if _, err := ParseEncoding("euc-jp"); err == nil {
+		t.Error("Expected error, but got nil")
+	}
+}
+
+func TestWriteCSV_Encoding(t *testing.T) {
+	data := []QuizItem{{Question: "問題", Answer: "答え"}}
+	opts := ConvertOptions{CSV: CSVOptions{Columns: []string{"question", "answer"}, NoHeader: true}}
+
+	tests := []struct {
+		name     string
+		encoding Encoding
+		expected []byte
+	}{
+		{"utf8", EncodingUTF8, []byte("問題,答え\n")},
+		{"utf8 with BOM", EncodingUTF8BOM, append([]byte{0xEF, 0xBB, 0xBF}, []byte("問題,答え\n")...)},
+		{"shift_jis", EncodingShiftJIS, []byte{0x96, 0xe2, 0x91, 0xe8, ',', 0x93, 0x9a, 0x82, 0xa6, '\n'}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			opts.CSV.Encoding = tt.encoding
+			if err := WriteCSV(&buf, data, opts); err != nil {
+				t.Fatalf("WriteCSV() error = %v", err)
+			}
+			if !bytes.Equal(buf.Bytes(), tt.expected) {
+				t.Errorf("WriteCSV() = %x, want %x", buf.Bytes(), tt.expected)
+			}
+		})
+	}
+}
+
+func TestWriteCSV_ShiftJISUnencodable(t *testing.T) {
+	data := []QuizItem{{Question: "問題", Answer: "答え"}, {Question: "絵文字😀", Answer: "答え"}}
+	opts := ConvertOptions{CSV: CSVOptions{Encoding: EncodingShiftJIS}}
+
+	var buf bytes.Buffer
+	err := WriteCSV(&buf, data, opts)
+	if err == nil {
+		t.Fatal("Expected error, but got nil")
+	}
+	var itemErr *ItemError
+	if !errors.As(err, &itemErr) || itemErr.Index != 2 {
+		t.Fatalf("error = %v, want ItemError for item 2", err)
+	}
+	if !strings.Contains(err.Error(), "question") || !strings.Contains(err.Error(), "U+1F600") {
+		t.Errorf("error = %q, want column name and code point", err.Error())
+	}
+}
+
+func TestWriteCSV_Dialect(t *testing.T) {
+	data := []QuizItem{{Question: "=1+1", Answer: "複数\n行", Spell: `say "hi"`}}
+	columns := []string{"question", "answer", "spell"}
+
+	tests := []struct {
+		name     string
+		csv      CSVOptions
+		expected string
+	}{
+		{
+			name:     "default",
+			csv:      CSVOptions{},
+			expected: "question,answer,spell\n=1+1,\"複数\n行\",\"say \"\"hi\"\"\"\n",
+		},
+		{
+			name:     "crlf",
+			csv:      CSVOptions{CRLF: true},
+			expected: "question,answer,spell\r\n=1+1,\"複数\r\n行\",\"say \"\"hi\"\"\"\r\n",
+		},
+		{
+			name:     "always quote",
+			csv:      CSVOptions{AlwaysQuote: true},
+			expected: "\"question\",\"answer\",\"spell\"\n\"=1+1\",\"複数\n行\",\"say \"\"hi\"\"\"\n",
+		},
+		{
+			name:     "escape formulas",
+			csv:      CSVOptions{EscapeFormulas: true},
+			expected: "question,answer,spell\n'=1+1,\"複数\n行\",\"say \"\"hi\"\"\"\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			tt.csv.Columns = columns
+			if err := WriteCSV(&buf, data, ConvertOptions{CSV: tt.csv}); err != nil {
+				t.Fatalf("WriteCSV() error = %v", err)
+			}
+			if buf.String() != tt.expected {
+				t.Errorf("WriteCSV() = %q, want %q", buf.String(), tt.expected)
+			}
+		})
+	}
+}
+
+func TestEscapeFormula(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"=SUM(A1)", "'=SUM(A1)"},
+		{"+81", "'+81"},
+		{"-1", "'-1"},
+		{"@user", "'@user"},
+		{"普通の文字列", "普通の文字列"},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			if result := escapeFormula(tt.input); result != tt.expected {
+				t.Errorf("escapeFormula(%q) = %q, want %q", tt.input, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestWriteCSV_Comments(t *testing.T) {
+	data := []QuizItem{{Question: "問題1", Answer: "答え1", Comments: []string{"c1", "c2"}}}
+
+	tests := []struct {
+		name     string
+		csv      CSVOptions
+		expected string
+	}{
+		{
+			name:     "include comments appends column",
+			csv:      CSVOptions{Columns: []string{"question"}, IncludeComments: true},
+			expected: "question,comments\n問題1,\"c1\nc2\"\n",
+		},
+		{
+			name:     "custom separator",
+			csv:      CSVOptions{Columns: []string{"question"}, IncludeComments: true, CommentSeparator: " / "},
+			expected: "question,comments\n問題1,c1 / c2\n",
+		},
+		{
+			name:     "column already present",
+			csv:      CSVOptions{Columns: []string{"comments", "question"}, IncludeComments: true, CommentSeparator: ";"},
+			expected: "comments,question\nc1;c2,問題1\n",
+		},
+		{
+			name:     "default columns",
+			csv:      CSVOptions{IncludeComments: true, CommentSeparator: ";"},
+			expected: "question,answer,spell,criteria,comments\n問題1,答え1,,,c1;c2\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := WriteCSV(&buf, data, ConvertOptions{CSV: tt.csv}); err != nil {
+				t.Fatalf("WriteCSV() error = %v", err)
+			}
+			if buf.String() != tt.expected {
+				t.Errorf("WriteCSV() = %q, want %q", buf.String(), tt.expected)
+			}
+		})
+	}
+}
+
+func TestWriteCSV_CriteriaSeparator(t *testing.T) {
+	data := []QuizItem{{
+		Question: "問題1", Answer: "答え1",
+		Criteria:      map[string][]string{"ok": {"別解1", "別解2"}, "ng": {"誤答"}},
+		CriteriaOrder: []string{"ng", "ok"},
+	}}
+
+	tests := []struct {
+		name     string
+		opts     ConvertOptions
+		expected string
+	}{
+		{
+			name:     "default",
+			opts:     ConvertOptions{},
+			expected: "criteria\n「別解1」「別解2」／「誤答」は誤答\n",
+		},
+		{
+			name:     "section separator",
+			opts:     ConvertOptions{CSV: CSVOptions{CriteriaSeparator: " / "}},
+			expected: "criteria\n「別解1」「別解2」 / 「誤答」は誤答\n",
+		},
+		{
+			name:     "unquoted items",
+			opts:     ConvertOptions{CSV: CSVOptions{CriteriaSeparator: "|", CriteriaItemSeparator: ";"}},
+			expected: "criteria\n別解1;別解2|誤答は誤答\n",
+		},
+		{
+			name:     "preserve order",
+			opts:     ConvertOptions{PreserveCriteriaOrder: true, CSV: CSVOptions{CriteriaItemSeparator: ";"}},
+			expected: "criteria\n誤答は誤答／別解1;別解2\n",
+		},
+		{
+			name:     "english",
+			opts:     ConvertOptions{CriteriaLanguage: LanguageEnglish},
+			expected: "criteria\n\"\"\"別解1\"\", \"\"別解2\"\" / \"\"誤答\"\" is incorrect\"\n",
+		},
+		{
+			name:     "english with section separator",
+			opts:     ConvertOptions{CriteriaLanguage: LanguageEnglish, CSV: CSVOptions{CriteriaSeparator: "|", CriteriaItemSeparator: ";"}},
+			expected: "criteria\n別解1;別解2|誤答 is incorrect\n",
+		},
+		{
+			name:     "quotes",
+			opts:     ConvertOptions{Quotes: [2]string{"『", "』"}},
+			expected: "criteria\n『別解1』『別解2』／『誤答』は誤答\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.opts.CSV.Columns = []string{"criteria"}
+			var buf bytes.Buffer
+			if err := WriteCSV(&buf, data, tt.opts); err != nil {
+				t.Fatalf("WriteCSV() error = %v", err)
+			}
+			if buf.String() != tt.expected {
+				t.Errorf("WriteCSV() = %q, want %q", buf.String(), tt.expected)
+			}
+		})
+	}
+}
+
+func TestParseNewlineMode(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected NewlineMode
+		wantErr  bool
+	}{
+		{"", NewlinesKeep, false},
+		{"keep", NewlinesKeep, false},
+		{"Escape", NewlinesEscape, false},
+		{"space", NewlinesSpace, false},
+		{"strip", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			result, err := ParseNewlineMode(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseNewlineMode(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if result != tt.expected {
+				t.Errorf("ParseNewlineMode(%q) = %q, want %q", tt.input, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestWriteCSV_Newlines(t *testing.T) {
+	data := []QuizItem{{Question: "1行目\n2行目\r\n\r\n3行目\n", Answer: "答え", Comments: []string{"c1", "c2"}}}
+
+	tests := []struct {
+		name     string
+		csv      CSVOptions
+		expected string
+	}{
+		{
+			name:     "keep",
+			csv:      CSVOptions{},
+			expected: "question,answer,comments\n\"1行目\n2行目\r\n\r\n3行目\n\",答え,\"c1\nc2\"\n",
+		},
+		{
+			name:     "escape",
+			csv:      CSVOptions{Newlines: NewlinesEscape},
+			expected: "question,answer,comments\n1行目\\n2行目\\n\\n3行目\\n,答え,c1\\nc2\n",
+		},
+		{
+			name:     "space",
+			csv:      CSVOptions{Newlines: NewlinesSpace},
+			expected: "question,answer,comments\n1行目 2行目 3行目,答え,c1 c2\n",
+		},
+		{
+			name:     "escape with CRLF",
+			csv:      CSVOptions{Newlines: NewlinesEscape, CRLF: true},
+			expected: "question,answer,comments\r\n1行目\\n2行目\\n\\n3行目\\n,答え,c1\\nc2\r\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.csv.Columns = []string{"question", "answer", "comments"}
+			var buf bytes.Buffer
+			if err := WriteCSV(&buf, data, ConvertOptions{CSV: tt.csv}); err != nil {
+				t.Fatalf("WriteCSV() error = %v", err)
+			}
+			if buf.String() != tt.expected {
+				t.Errorf("WriteCSV() = %q, want %q", buf.String(), tt.expected)
+			}
+		})
+	}
+}
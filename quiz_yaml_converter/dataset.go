@@ -0,0 +1,136 @@
+// 問題データを機械学習用の学習・検証・評価データに分割する処理です．
+package quiz_yaml_converter
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"math/rand/v2"
+	"sort"
+	"strings"
+)
+
+// DatasetOptions は問題を学習・検証・評価データに分割するときの設定．
+// 割合は合計が1でなくてもよく，合計に対する比で分割する．
+type DatasetOptions struct {
+	Train      float64 // 学習データ（train）の割合
+	Validation float64 // 検証データ（validation）の割合
+	Test       float64 // 評価データ（test）の割合
+	Stratify   bool    // trueの場合，ジャンルごとに同じ割合で分割する
+	Seed       int64   // 0以外の場合，この値を乱数のシードとして同じ分割にする
+}
+
+// DatasetSplits は分割した問題．各データの問題は入力での順序を保つ．
+type DatasetSplits struct {
+	Train      []QuizItem
+	Validation []QuizItem
+	Test       []QuizItem
+}
+
+// SplitDataset は問題を無作為に学習・検証・評価データに分割する．
+// 各データの問題数は割合に従って最大剰余方式で決め，opts.Stratifyの場合はジャンルごとに決める．
+// ジャンルごとの端数は次のジャンルに繰り越すため，問題の少ないジャンルが多くても全体の割合は指定に近くなる．
+// YAMLのドキュメントの区切り（Document）は取り除く．
+func SplitDataset(data []QuizItem, opts DatasetOptions) (DatasetSplits, error) {
+	ratios := []float64{opts.Train, opts.Validation, opts.Test}
+	sum := 0.0
+	for _, r := range ratios {
+		if r < 0 || math.IsNaN(r) || math.IsInf(r, 0) {
+			return DatasetSplits{}, fmt.Errorf("split ratios must be non-negative numbers: %v", ratios)
+		}
+		sum += r
+	}
+	if sum == 0 {
+		return DatasetSplits{}, fmt.Errorf("at least one split ratio must be positive")
+	}
+
+	r := rand.New(rand.NewPCG(rand.Uint64(), rand.Uint64()))
+	if opts.Seed != 0 {
+		r = rand.New(rand.NewPCG(uint64(opts.Seed), 0))
+	}
+
+	// 問題をジャンルごと（Stratifyでない場合はまとめて1つ）にまとめる（ジャンルは最初に現れた順）
+	var groups [][]int
+	groupIndex := map[string]int{}
+	for i, item := range data {
+		key := ""
+		if opts.Stratify {
+			key = strings.TrimSpace(item.Genre)
+		}
+		g, ok := groupIndex[key]
+		if !ok {
+			g = len(groups)
+			groupIndex[key] = g
+			groups = append(groups, nil)
+		}
+		groups[g] = append(groups[g], i)
+	}
+
+	split := make([]int, len(data)) // 問題ごとの分割先（0: train, 1: validation, 2: test）
+	carry := make([]float64, len(ratios))
+	for _, group := range groups {
+		r.Shuffle(len(group), func(i, j int) { group[i], group[j] = group[j], group[i] })
+		start := 0
+		for s, n := range splitCounts(len(group), ratios, sum, carry) {
+			for _, i := range group[start : start+n] {
+				split[i] = s
+			}
+			start += n
+		}
+	}
+
+	var splits DatasetSplits
+	dst := []*[]QuizItem{&splits.Train, &splits.Validation, &splits.Test}
+	for i, item := range data {
+		item.Document = 0
+		*dst[split[i]] = append(*dst[split[i]], item)
+	}
+	return splits, nil
+}
+
+// splitCounts はn問を割合に従って分けたときの各データの問題数を最大剰余方式で返す．
+// carryはそれまでのグループで割合より少なく（負の場合は多く）割り当てた問題数で，剰余に加えたうえで
+// 今回の端数で更新する．剰余が同じ場合は割合の大きいデータ，割合も同じ場合は前のデータを優先する．
+func splitCounts(n int, ratios []float64, sum float64, carry []float64) []int {
+	counts := make([]int, len(ratios))
+	exact := make([]float64, len(ratios))
+	remainders := make([]float64, len(ratios))
+	total := 0
+	for i, r := range ratios {
+		exact[i] = float64(n) * r / sum
+		counts[i] = int(exact[i])
+		remainders[i] = exact[i] - float64(counts[i]) + carry[i]
+		total += counts[i]
+	}
+	order := []int{0, 1, 2}
+	sort.SliceStable(order, func(a, b int) bool {
+		if remainders[order[a]] != remainders[order[b]] {
+			return remainders[order[a]] > remainders[order[b]]
+		}
+		return ratios[order[a]] > ratios[order[b]]
+	})
+	for i := 0; total < n; i++ {
+		if ratios[order[i%len(order)]] > 0 {
+			counts[order[i%len(order)]]++
+			total++
+		}
+	}
+	for i := range carry {
+		carry[i] += exact[i] - float64(counts[i])
+	}
+	return counts
+}
+
+// writeJSONLItems は問題データを1行に1問のJSON（JSON Lines）として書き出す．
+// 各行はJSON出力の配列の要素と同じ形式．
+func writeJSONLItems(w io.Writer, items []QuizItem, opts ConvertOptions) error {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	for _, item := range withAssignedIDs(items, opts) {
+		if err := enc.Encode(item); err != nil {
+			return fmt.Errorf("failed to write JSON Lines: %w", err)
+		}
+	}
+	return nil
+}
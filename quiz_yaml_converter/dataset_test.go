@@ -0,0 +1,156 @@
+package quiz_yaml_converter
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"slices"
+	"testing"
+)
+
+func TestSplitDataset(t *testing.T) {
+	var items []QuizItem
+	for i := range 20 {
+		genre := "歴史"
+		if i%4 == 0 {
+			genre = "科学"
+		}
+		items = append(items, QuizItem{ID: fmt.Sprintf("q%02d", i), Question: "q", Answer: "a", Genre: genre, Document: 1})
+	}
+
+	tests := []struct {
+		name     string
+		opts     DatasetOptions
+		expected [3]int
+	}{
+		{"80/10/10", DatasetOptions{Train: 0.8, Validation: 0.1, Test: 0.1, Seed: 1}, [3]int{16, 2, 2}},
+		{"stratified", DatasetOptions{Train: 0.8, Validation: 0.1, Test: 0.1, Stratify: true, Seed: 1}, [3]int{16, 2, 2}},
+		{"not normalized", DatasetOptions{Train: 3, Test: 1, Seed: 2}, [3]int{15, 0, 5}},
+		{"train only", DatasetOptions{Train: 1}, [3]int{20, 0, 0}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			splits, err := SplitDataset(items, tt.opts)
+			if err != nil {
+				t.Fatalf("SplitDataset() error = %v", err)
+			}
+			got := [3]int{len(splits.Train), len(splits.Validation), len(splits.Test)}
+			if got != tt.expected {
+				t.Errorf("split sizes = %v, want %v", got, tt.expected)
+			}
+			var ids []string
+			for _, split := range [][]QuizItem{splits.Train, splits.Validation, splits.Test} {
+				for i, item := range split {
+					if item.Document != 0 {
+						t.Errorf("Document = %d, want 0", item.Document)
+					}
+					if i > 0 && split[i-1].ID > item.ID {
+						t.Errorf("items are not in input order: %s before %s", split[i-1].ID, item.ID)
+					}
+					ids = append(ids, item.ID)
+				}
+			}
+			slices.Sort(ids)
+			if len(slices.Compact(ids)) != len(items) {
+				t.Errorf("splits contain %d distinct items, want %d", len(ids), len(items))
+			}
+			if tt.opts.Stratify {
+				// 科学5問は4:0.5:0.5，歴史15問は12:1.5:1.5に分かれる
+				if n := countGenre(splits.Train, "科学"); n != 4 {
+					t.Errorf("train has %d science items, want 4", n)
+				}
+			}
+		})
+	}
+}
+
+func countGenre(items []QuizItem, genre string) int {
+	n := 0
+	for _, item := range items {
+		if item.Genre == genre {
+			n++
+		}
+	}
+	return n
+}
+
+func TestSplitDataset_Seed(t *testing.T) {
+	var items []QuizItem
+	for i := range 50 {
+		items = append(items, QuizItem{ID: fmt.Sprint(i), Question: "q", Answer: "a"})
+	}
+	opts := DatasetOptions{Train: 0.6, Validation: 0.2, Test: 0.2, Seed: 42}
+	a, _ := SplitDataset(items, opts)
+	b, _ := SplitDataset(items, opts)
+	if !reflect.DeepEqual(a, b) {
+		t.Errorf("SplitDataset() with the same seed returned different splits")
+	}
+	opts.Seed = 43
+	c, _ := SplitDataset(items, opts)
+	if reflect.DeepEqual(a, c) {
+		t.Errorf("SplitDataset() with different seeds returned the same splits")
+	}
+}
+
+func TestSplitDataset_InvalidRatios(t *testing.T) {
+	for _, opts := range []DatasetOptions{
+		{},
+		{Train: 1, Test: -0.1},
+	} {
+		if _, err := SplitDataset([]QuizItem{{Question: "q", Answer: "a"}}, opts); err == nil {
+			t.Errorf("SplitDataset(%+v) error = nil, want error", opts)
+		}
+	}
+}
+
+func TestSplitCounts(t *testing.T) {
+	tests := []struct {
+		n        int
+		ratios   []float64
+		expected []int
+	}{
+		{10, []float64{0.8, 0.1, 0.1}, []int{8, 1, 1}},
+		{1, []float64{0.8, 0.1, 0.1}, []int{1, 0, 0}},
+		{3, []float64{0.8, 0.1, 0.1}, []int{3, 0, 0}},
+		{5, []float64{0.8, 0.1, 0.1}, []int{4, 1, 0}},
+		{2, []float64{0, 1, 1}, []int{0, 1, 1}},
+		{0, []float64{1, 1, 1}, []int{0, 0, 0}},
+	}
+	for _, tt := range tests {
+		sum := tt.ratios[0] + tt.ratios[1] + tt.ratios[2]
+		if got := splitCounts(tt.n, tt.ratios, sum, make([]float64, 3)); !reflect.DeepEqual(got, tt.expected) {
+			t.Errorf("splitCounts(%d, %v) = %v, want %v", tt.n, tt.ratios, got, tt.expected)
+		}
+	}
+}
+
+func TestSplitCounts_Carry(t *testing.T) {
+	// 1問ずつのグループを10個分けると，全体で8:1:1になる
+	ratios := []float64{0.8, 0.1, 0.1}
+	carry := make([]float64, 3)
+	var total [3]int
+	for range 10 {
+		for i, n := range splitCounts(1, ratios, 1, carry) {
+			total[i] += n
+		}
+	}
+	if total != [3]int{8, 1, 1} {
+		t.Errorf("total = %v, want [8 1 1]", total)
+	}
+}
+
+func TestWriteJSONLItems(t *testing.T) {
+	items := []QuizItem{
+		{ID: "q1", Question: "<b>日本</b>の首都は？", Answer: "東京"},
+		{Question: "1+1は？", Answer: "2"},
+	}
+	var buf bytes.Buffer
+	if err := writeJSONLItems(&buf, items, ConvertOptions{AssignIDs: true}); err != nil {
+		t.Fatalf("writeJSONLItems() error = %v", err)
+	}
+	expected := `{"id":"q1","question":"<b>日本</b>の首都は？","answer":"東京","spell":""}` + "\n" +
+		`{"id":"` + ContentID(items[1]) + `","question":"1+1は？","answer":"2","spell":""}` + "\n"
+	if buf.String() != expected {
+		t.Errorf("writeJSONLItems() = %q, want %q", buf.String(), expected)
+	}
+}
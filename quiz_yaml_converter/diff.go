@@ -0,0 +1,144 @@
+package quiz_yaml_converter
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// FieldChange は1つのフィールドの変更内容．
+type FieldChange struct {
+	Field string `json:"field"` // フィールド名（criteriaの場合は"criteria.ok"など）
+	Old   string `json:"old"`   // 変更前の値
+	New   string `json:"new"`   // 変更後の値
+}
+
+// ItemDiff は追加・削除・変更された1問分の差分．
+// インデックスは1から始まり，該当しない側（追加された問題のOldIndexなど）は0となる．
+type ItemDiff struct {
+	Key      string        `json:"key"`                 // 問題の対応付けに使ったキー（IDまたは問題文）
+	OldIndex int           `json:"old_index,omitempty"` // 変更前のファイルでの位置
+	NewIndex int           `json:"new_index,omitempty"` // 変更後のファイルでの位置
+	Question string        `json:"question"`            // 問題文（変更された場合は変更後）
+	Changes  []FieldChange `json:"changes,omitempty"`   // 変更されたフィールド（変更の場合のみ）
+}
+
+// DiffResult は2つの問題データの差分．
+type DiffResult struct {
+	Added    []ItemDiff `json:"added"`
+	Removed  []ItemDiff `json:"removed"`
+	Modified []ItemDiff `json:"modified"`
+}
+
+// HasChanges は差分が1つでもあるかを返す．
+func (d DiffResult) HasChanges() bool {
+	return len(d.Added) > 0 || len(d.Removed) > 0 || len(d.Modified) > 0
+}
+
+// diffKey は問題を対応付けるためのキーを返す．IDがあればIDを，なければ問題文を使う．
+func diffKey(item QuizItem) string {
+	if item.ID != "" {
+		return "id:" + item.ID
+	}
+	return "question:" + strings.TrimSpace(item.Question)
+}
+
+// DiffItems は変更前後の問題データを比較し，追加・削除・変更された問題を返す．
+// 問題はIDがあればIDで，なければ問題文で対応付けるため，IDのない問題の問題文を
+// 変更した場合は削除と追加として扱われる．同じキーの問題が複数ある場合は出現順に対応付ける．
+func DiffItems(oldItems, newItems []QuizItem) DiffResult {
+	result := DiffResult{Added: []ItemDiff{}, Removed: []ItemDiff{}, Modified: []ItemDiff{}}
+
+	oldByKey := map[string][]int{}
+	for i, item := range oldItems {
+		key := diffKey(item)
+		oldByKey[key] = append(oldByKey[key], i)
+	}
+
+	matched := make([]bool, len(oldItems))
+	for j, item := range newItems {
+		key := diffKey(item)
+		candidates := oldByKey[key]
+		if len(candidates) == 0 {
+			result.Added = append(result.Added, ItemDiff{Key: key, NewIndex: j + 1, Question: item.Question})
+			continue
+		}
+		i := candidates[0]
+		oldByKey[key] = candidates[1:]
+		matched[i] = true
+		if changes := diffFields(oldItems[i], item); len(changes) > 0 {
+			result.Modified = append(result.Modified, ItemDiff{Key: key, OldIndex: i + 1, NewIndex: j + 1, Question: item.Question, Changes: changes})
+		}
+	}
+
+	for i, item := range oldItems {
+		if !matched[i] {
+			result.Removed = append(result.Removed, ItemDiff{Key: diffKey(item), OldIndex: i + 1, Question: item.Question})
+		}
+	}
+	return result
+}
+
+// diffFields はフィールドごとに値を比較し，異なるものを返す．
+func diffFields(oldItem, newItem QuizItem) []FieldChange {
+	oldFields, newFields := diffFieldValues(oldItem), diffFieldValues(newItem)
+	var changes []FieldChange
+	for _, name := range diffFieldNames(oldItem, newItem) {
+		if oldFields[name] != newFields[name] {
+			changes = append(changes, FieldChange{Field: name, Old: oldFields[name], New: newFields[name]})
+		}
+	}
+	return changes
+}
+
+// diffFieldNames は比較するフィールド名を出力順に返す．
+func diffFieldNames(items ...QuizItem) []string {
+	names := []string{"id", "question", "segments", "answer", "reading", "spell", "genre", "difficulty", "tags", "comments", "choices", "source", "reference", "author", "license"}
+	keys := map[string]bool{}
+	for _, item := range items {
+		for key := range item.Criteria {
+			keys[key] = true
+		}
+	}
+	var extra []string
+	for _, key := range defaultCriteriaOrder {
+		if keys[key] {
+			names = append(names, "criteria."+key)
+			delete(keys, key)
+		}
+	}
+	for key := range keys {
+		extra = append(extra, "criteria."+key)
+	}
+	sort.Strings(extra)
+	return append(names, extra...)
+}
+
+// diffFieldValues は比較のために各フィールドを文字列にしたものを返す．
+// リストは" / "でつないで比較する．
+func diffFieldValues(item QuizItem) map[string]string {
+	values := map[string]string{
+		"id":         item.ID,
+		"question":   item.Question,
+		"segments":   strings.Join(item.Segments, " / "),
+		"answer":     item.Answer,
+		"reading":    item.Reading,
+		"spell":      item.FlatSpell(),
+		"genre":      item.Genre,
+		"difficulty": "",
+		"tags":       strings.Join(item.Tags, " / "),
+		"comments":   strings.Join(item.Comments, " / "),
+		"choices":    strings.Join(item.Choices, " / "),
+		"source":     item.Source,
+		"reference":  item.Reference,
+		"author":     item.Author,
+		"license":    item.License,
+	}
+	if item.Difficulty != 0 {
+		values["difficulty"] = strconv.Itoa(item.Difficulty)
+	}
+	for key, answers := range item.Criteria {
+		values["criteria."+key] = strings.Join(answers, " / ")
+	}
+	return values
+}
@@ -0,0 +1,68 @@
+package quiz_yaml_converter
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiffItems(t *testing.T) {
+	oldItems := []QuizItem{
+		{ID: "q1", Question: "日本の首都は？", Answer: "東京"},
+		{Question: "消える問題", Answer: "X"},
+		{Question: "そのまま", Answer: "Y", Criteria: map[string][]string{"ok": {"a"}}},
+		{Question: "変更なし", Answer: "Z"},
+	}
+	newItems := []QuizItem{
+		{Question: "そのまま", Answer: "Y", Criteria: map[string][]string{"ok": {"a", "b"}}},
+		{ID: "q1", Question: "日本の首都はどこ？", Answer: "東京都"},
+		{Question: "変更なし", Answer: "Z"},
+		{Question: "新しい問題", Answer: "W"},
+	}
+
+	result := DiffItems(oldItems, newItems)
+
+	expected := DiffResult{
+		Added:   []ItemDiff{{Key: "question:新しい問題", NewIndex: 4, Question: "新しい問題"}},
+		Removed: []ItemDiff{{Key: "question:消える問題", OldIndex: 2, Question: "消える問題"}},
+		Modified: []ItemDiff{
+			{
+				Key: "question:そのまま", OldIndex: 3, NewIndex: 1, Question: "そのまま",
+				Changes: []FieldChange{{Field: "criteria.ok", Old: "a", New: "a / b"}},
+			},
+			{
+				Key: "id:q1", OldIndex: 1, NewIndex: 2, Question: "日本の首都はどこ？",
+				Changes: []FieldChange{
+					{Field: "question", Old: "日本の首都は？", New: "日本の首都はどこ？"},
+					{Field: "answer", Old: "東京", New: "東京都"},
+				},
+			},
+		},
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("DiffItems() = %+v, want %+v", result, expected)
+	}
+	if !result.HasChanges() {
+		t.Error("HasChanges() = false, want true")
+	}
+}
+
+func TestDiffItems_NoChanges(t *testing.T) {
+	items := []QuizItem{{Question: "q", Answer: "a", Tags: []string{"t"}}}
+	result := DiffItems(items, items)
+	if result.HasChanges() {
+		t.Errorf("DiffItems() = %+v, want no changes", result)
+	}
+}
+
+func TestDiffItems_DuplicateQuestions(t *testing.T) {
+	oldItems := []QuizItem{{Question: "q", Answer: "a1"}, {Question: "q", Answer: "a2"}}
+	newItems := []QuizItem{{Question: "q", Answer: "a1"}}
+
+	result := DiffItems(oldItems, newItems)
+	if len(result.Removed) != 1 || result.Removed[0].OldIndex != 2 {
+		t.Errorf("Removed = %+v, want the second item", result.Removed)
+	}
+	if len(result.Modified) != 0 || len(result.Added) != 0 {
+		t.Errorf("DiffItems() = %+v, want only one removal", result)
+	}
+}
@@ -0,0 +1,76 @@
+package quiz_yaml_converter
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// RuleDuplicateAnswer は同じ答えの問題が上限を超えて含まれていることを表す警告の規則名．
+const RuleDuplicateAnswer = "duplicate-answer"
+
+// NormalizeAnswer は答えを比較するための形に正規化する．
+// 全角・半角を揃え，英字を小文字に，カタカナをひらがなにし，空白と記号（・や-など）を取り除く．
+func NormalizeAnswer(answer string) string {
+	answer = NormalizeReading(strings.ToLower(norm.NFKC.String(answer)))
+	return strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) || unicode.IsPunct(r) || unicode.IsSymbol(r) {
+			return -1
+		}
+		return r
+	}, answer)
+}
+
+// AnswerGroup は正規化した答え（NormalizeAnswer参照）が同じ問題のまとまり．
+type AnswerGroup struct {
+	Answer  string // 最初に現れた問題の答え
+	Indices []int  // 問題の番号（1始まり）
+}
+
+// DuplicateAnswers は正規化した答えが同じ問題をまとめ，maxCount問を超えるまとまりを最初に現れた順に返す．
+// 答えが空の問題は対象外とする．maxCountが0以下の場合はnilを返す．
+func DuplicateAnswers(data []QuizItem, maxCount int) []AnswerGroup {
+	if maxCount <= 0 {
+		return nil
+	}
+	var groups []AnswerGroup
+	positions := map[string]int{}
+	for i, item := range data {
+		key := NormalizeAnswer(item.Answer)
+		if key == "" {
+			continue
+		}
+		pos, ok := positions[key]
+		if !ok {
+			pos = len(groups)
+			positions[key] = pos
+			groups = append(groups, AnswerGroup{Answer: strings.TrimSpace(item.Answer)})
+		}
+		groups[pos].Indices = append(groups[pos].Indices, i+1)
+	}
+	var duplicates []AnswerGroup
+	for _, group := range groups {
+		if len(group.Indices) > maxCount {
+			duplicates = append(duplicates, group)
+		}
+	}
+	return duplicates
+}
+
+// DuplicateAnswerWarnings は同じ答えの問題がmaxCount問を超えて含まれている場合の警告を返す．
+// 警告は答えごとに1件とし，上限を超えた最初の問題（maxCount+1問目）の番号に付ける．
+// 1つの問題集（パケット）で同じ答えを何度も出題しないよう確認するのに使う．
+func DuplicateAnswerWarnings(data []QuizItem, maxCount int) []ValidationError {
+	var warnings []ValidationError
+	for _, group := range DuplicateAnswers(data, maxCount) {
+		indices := make([]string, len(group.Indices))
+		for i, index := range group.Indices {
+			indices[i] = strconv.Itoa(index)
+		}
+		warnings = append(warnings, newValidationError(group.Indices[maxCount], "answer", RuleDuplicateAnswer, nil,
+			"答え「%s」の問題が%d問あります（問題 %s．上限は%d問）", group.Answer, len(group.Indices), strings.Join(indices, ", "), maxCount))
+	}
+	return warnings
+}
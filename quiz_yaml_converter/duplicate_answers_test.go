@@ -0,0 +1,76 @@
+package quiz_yaml_converter
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNormalizeAnswer(t *testing.T) {
+	tests := []struct {
+		answer   string
+		expected string
+	}{
+		{"ベルリン", "べるりん"},
+		{" べるりん ", "べるりん"},
+		{"ﾍﾞﾙﾘﾝ", "べるりん"},
+		{"レオナルド・ダ・ヴィンチ", "れおなるどだゔぃんち"},
+		{"ＤＮＡ", "dna"},
+		{"T-REX", "trex"},
+		{"東京", "東京"},
+		{"・", ""},
+	}
+	for _, tt := range tests {
+		if got := NormalizeAnswer(tt.answer); got != tt.expected {
+			t.Errorf("NormalizeAnswer(%q) = %q, want %q", tt.answer, got, tt.expected)
+		}
+	}
+}
+
+func TestDuplicateAnswers(t *testing.T) {
+	data := []QuizItem{
+		{Question: "q1", Answer: "ベルリン"},
+		{Question: "q2", Answer: "パリ"},
+		{Question: "q3", Answer: "べるりん"},
+		{Question: "q4", Answer: ""},
+		{Question: "q5", Answer: "ﾍﾞﾙﾘﾝ"},
+		{Question: "q6", Answer: "パリ"},
+		{Question: "q7", Answer: ""},
+	}
+	tests := []struct {
+		maxCount int
+		expected []AnswerGroup
+	}{
+		{0, nil},
+		{1, []AnswerGroup{{Answer: "ベルリン", Indices: []int{1, 3, 5}}, {Answer: "パリ", Indices: []int{2, 6}}}},
+		{2, []AnswerGroup{{Answer: "ベルリン", Indices: []int{1, 3, 5}}}},
+		{3, nil},
+	}
+	for _, tt := range tests {
+		if got := DuplicateAnswers(data, tt.maxCount); !reflect.DeepEqual(got, tt.expected) {
+			t.Errorf("DuplicateAnswers(%d) = %+v, want %+v", tt.maxCount, got, tt.expected)
+		}
+	}
+}
+
+func TestDuplicateAnswerWarnings(t *testing.T) {
+	data := []QuizItem{
+		{Question: "q1", Answer: "ベルリン"},
+		{Question: "q2", Answer: "パリ"},
+		{Question: "q3", Answer: "ベルリン"},
+		{Question: "q4", Answer: "ベルリン "},
+	}
+	warnings := DuplicateAnswerWarnings(data, 2)
+	if len(warnings) != 1 {
+		t.Fatalf("DuplicateAnswerWarnings() = %+v, want 1 warning", warnings)
+	}
+	w := warnings[0]
+	if w.Index != 4 || w.Field != "answer" || w.Rule != RuleDuplicateAnswer {
+		t.Errorf("DuplicateAnswerWarnings() = %+v, want a duplicate-answer warning for item 4", w)
+	}
+	if expected := "問題 4: 答え「ベルリン」の問題が3問あります（問題 1, 3, 4．上限は2問）"; w.Error() != expected {
+		t.Errorf("Error() = %q, want %q", w.Error(), expected)
+	}
+	if expected := `item 4: the answer "ベルリン" is used by 3 items (items 1, 3, 4; the limit is 2)`; w.LocalizedError(LanguageEnglish) != expected {
+		t.Errorf("LocalizedError(en) = %q, want %q", w.LocalizedError(LanguageEnglish), expected)
+	}
+}
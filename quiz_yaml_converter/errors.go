@@ -0,0 +1,132 @@
+// ライブラリが返すエラーの種類を表す値です．
+// 呼び出し側はerrors.Isやerrors.Asでエラーの種類を判別できます．
+package quiz_yaml_converter
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// エラーの種類
+var (
+	// YAMLの構文や構造が正しくない
+	ErrInvalidYAML = errors.New("failed to parse YAML")
+	// テンプレートの構文が正しくない
+	ErrTemplateParse = errors.New("failed to parse template")
+	// テンプレートの実行に失敗した
+	ErrTemplateExecute = errors.New("failed to execute template")
+	// サポートされていない出力フォーマットやオプションが指定された
+	ErrUnsupportedFormat = errors.New("unsupported output format")
+	// CSV以外の出力でテンプレートファイルが指定されていない
+	ErrTemplateRequired = errors.New("template file is required for non-CSV output")
+	// 出力ファイルが既に存在し，上書きが許可されていない
+	ErrOutputExists = errors.New("output file already exists")
+	// 問題データのバリデーションに失敗した
+	ErrValidation = errors.New("validation failed")
+	// 外部コマンドの出力形式（exec:）の実行に失敗した
+	ErrFormatterCommand = errors.New("formatter command failed")
+	// 変換パイプラインの処理（-transform）に失敗した
+	ErrTransform = errors.New("transform failed")
+	// 絞り込み条件（-filter）の構文が正しくない
+	ErrInvalidFilter = errors.New("invalid filter expression")
+	// CSVから読み戻した問題の数が元の問題の数と一致しない
+	ErrRoundTripMismatch = errors.New("round-trip item count mismatch")
+	// テンプレートの実行が制限時間（ConvertOptions.TemplateTimeout）を超えた
+	ErrTemplateTimeout = errors.New("template execution timed out")
+	// 出力が上限（ConvertOptions.MaxOutputBytes）を超えた
+	ErrOutputTooLarge = errors.New("output exceeds the size limit")
+	// 統合したファイルに同じIDの問題がある（ConflictError）
+	ErrIDConflict = errors.New("duplicate question id")
+	// YAMLファイルのmetadataのversionがこのバージョンで読めない新しいもの
+	ErrUnsupportedVersion = errors.New("unsupported schema version")
+)
+
+// ItemError は変換中に特定の問題の処理で発生したエラーを表す．
+// 複数の問題で失敗した場合は，問題ごとのItemErrorをerrors.Joinでまとめて返す（ItemErrors参照）．
+type ItemError struct {
+	Index    int    // 問題の番号（1始まり）
+	Question string // 問題文の冒頭（QuestionSnippet参照．問題を探しやすくするためのもので，空の場合もある）
+	Err      error  // 発生したエラー
+}
+
+// Error はエラーメッセージを"item N: エラー"の形式で返す．
+// Questionがある場合は"item N (\"問題文の冒頭\"): エラー"の形式とする．
+func (e *ItemError) Error() string {
+	if e.Question != "" {
+		return fmt.Sprintf("item %d (%q): %v", e.Index, e.Question, e.Err)
+	}
+	return fmt.Sprintf("item %d: %v", e.Index, e.Err)
+}
+
+// エラーメッセージに含める問題文の最大文字数
+const questionSnippetLength = 20
+
+// QuestionSnippet はエラーメッセージで問題を示すための問題文の冒頭を返す．
+// 区切り記号を取り除き，改行を含む連続する空白を1つにまとめ，長い場合は先頭の20文字に"…"を付ける．
+func QuestionSnippet(item QuizItem) string {
+	runes := []rune(strings.Join(strings.Fields(PlainQuestion(item)), " "))
+	if len(runes) > questionSnippetLength {
+		return string(runes[:questionSnippetLength]) + "…"
+	}
+	return string(runes)
+}
+
+// Unwrap は発生したエラーを返す．
+func (e *ItemError) Unwrap() error {
+	return e.Err
+}
+
+// ItemErrors はerrに含まれるすべてのItemErrorを順に返す．
+// fmt.Errorfの%wやerrors.Joinでまとめられたエラーも辿る．
+func ItemErrors(err error) []*ItemError {
+	var found []*ItemError
+	var walk func(err error)
+	walk = func(err error) {
+		switch e := err.(type) {
+		case nil:
+		case *ItemError:
+			found = append(found, e)
+		case interface{ Unwrap() []error }:
+			for _, err := range e.Unwrap() {
+				walk(err)
+			}
+		case interface{ Unwrap() error }:
+			walk(e.Unwrap())
+		}
+	}
+	walk(err)
+	return found
+}
+
+// ValidationError はバリデーションで見つかった1件のエラーを表す．
+// errors.Is(err, ErrValidation)はtrueを返す．
+type ValidationError struct {
+	Index   int    // 問題の番号（1始まり）．ファイル全体に関するエラーの場合は0
+	Field   string // エラーの対象となったフィールド（例: "criteria.ok[0]"）
+	Rule    string // エラーの種類を表す規則名（RuleRequiredなど）
+	File    string // エラーのあったファイル（ファイルから読み込んだ場合のみ）
+	Line    int    // ファイル中の問題（またはエラー）の行番号．不明な場合は0
+	Message string // エラーメッセージ
+	Err     error  // 原因となったエラー（存在する場合）
+
+	// 翻訳用のメッセージの書式と引数（newValidationError参照）
+	format string
+	args   []any
+}
+
+// Error はエラーメッセージを"問題 N: メッセージ"の形式で返す．
+func (e *ValidationError) Error() string {
+	if e.Index > 0 {
+		return fmt.Sprintf("問題 %d: %s", e.Index, e.Message)
+	}
+	return e.Message
+}
+
+// Unwrap は原因となったエラーを返す．
+func (e *ValidationError) Unwrap() []error {
+	if e.Err != nil {
+		return []error{ErrValidation, e.Err}
+	}
+	return []error{ErrValidation}
+}
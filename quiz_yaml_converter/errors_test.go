@@ -0,0 +1,149 @@
+package quiz_yaml_converter
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestValidationError_Error(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      ValidationError
+		expected string
+	}{
+		{"item error", ValidationError{Index: 3, Field: "answer", Message: "答え (answer) が空です"}, "問題 3: 答え (answer) が空です"},
+		{"file error", ValidationError{Message: "YAMLファイルにクイズデータが含まれていません"}, "YAMLファイルにクイズデータが含まれていません"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := tt.err.Error(); result != tt.expected {
+				t.Errorf("Error() = %q, want %q", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestValidationResult_Err(t *testing.T) {
+	tempDir := t.TempDir()
+	yamlFile := filepath.Join(tempDir, "invalid.yaml")
+	yamlContent := `- question: 問題1
+  answer: 答え1
+- question: 問題2
+  answer: ""`
+	if err := os.WriteFile(yamlFile, []byte(yamlContent), 0644); err != nil {
+		t.Fatalf("Failed to create test YAML file: %v", err)
+	}
+
+	result := ValidateYAMLFile(yamlFile)
+	err := result.Err()
+
+	if !errors.Is(err, ErrValidation) {
+		t.Fatalf("Err() = %v, want ErrValidation", err)
+	}
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("Err() = %v, want *ValidationError", err)
+	}
+	if validationErr.Index != 2 || validationErr.Field != "answer" {
+		t.Errorf("ValidationError = %+v, want Index=2 Field=answer", validationErr)
+	}
+}
+
+func TestValidationResult_ErrValid(t *testing.T) {
+	result := ValidationResult{IsValid: true}
+	if err := result.Err(); err != nil {
+		t.Errorf("Err() = %v, want nil", err)
+	}
+}
+
+func TestItemErrors(t *testing.T) {
+	first := &ItemError{Index: 1, Err: errors.New("first")}
+	second := &ItemError{Index: 3, Err: errors.New("second")}
+	tests := []struct {
+		name     string
+		err      error
+		expected []*ItemError
+	}{
+		{"nil", nil, nil},
+		{"other error", errors.New("other"), nil},
+		{"single", first, []*ItemError{first}},
+		{"wrapped and joined", fmt.Errorf("%w: %w", ErrTemplateExecute, errors.Join(first, second)), []*ItemError{first, second}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ItemErrors(tt.err); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("ItemErrors() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+	if got := first.Error(); got != "item 1: first" {
+		t.Errorf("Error() = %q, want %q", got, "item 1: first")
+	}
+	withQuestion := &ItemError{Index: 2, Question: "日本の首都は？", Err: errors.New("failed")}
+	if got, want := withQuestion.Error(), `item 2 ("日本の首都は？"): failed`; got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+}
+
+func TestQuestionSnippet(t *testing.T) {
+	tests := []struct {
+		question string
+		expected string
+	}{
+		{"日本の首都は？", "日本の首都は？"},
+		{"", ""},
+		{"  改行を含む\n  問題文  ", "改行を含む 問題文"},
+		{"区切り" + SegmentMarker + "記号", "区切り記号"},
+		{"12345678901234567890", "12345678901234567890"},
+		{"ちょうど二十文字を超える長い問題文の場合は省略する", "ちょうど二十文字を超える長い問題文の場合…"},
+	}
+	for _, tt := range tests {
+		if got := QuestionSnippet(QuizItem{Question: tt.question}); got != tt.expected {
+			t.Errorf("QuestionSnippet(%q) = %q, want %q", tt.question, got, tt.expected)
+		}
+	}
+}
+
+func TestErrorKinds(t *testing.T) {
+	tempDir := t.TempDir()
+	invalidYAML := filepath.Join(tempDir, "invalid.yaml")
+	if err := os.WriteFile(invalidYAML, []byte(`invalid: yaml: content: [`), 0644); err != nil {
+		t.Fatalf("Failed to create test YAML file: %v", err)
+	}
+	invalidTemplate := filepath.Join(tempDir, "invalid.tmpl")
+	if err := os.WriteFile(invalidTemplate, []byte(`{{range .Items}`), 0644); err != nil {
+		t.Fatalf("Failed to create template file: %v", err)
+	}
+	failingTemplate := filepath.Join(tempDir, "failing.tmpl")
+	if err := os.WriteFile(failingTemplate, []byte(`{{range .Items}}{{.Missing}}{{end}}`), 0644); err != nil {
+		t.Fatalf("Failed to create template file: %v", err)
+	}
+	data := []QuizItem{{Question: "q", Answer: "a"}}
+	output := filepath.Join(tempDir, "out.txt")
+
+	tests := []struct {
+		name     string
+		run      func() error
+		expected error
+	}{
+		{"invalid YAML", func() error { _, err := LoadYAMLData(invalidYAML); return err }, ErrInvalidYAML},
+		{"validation of invalid YAML", func() error { return ValidateYAMLFile(invalidYAML).Err() }, ErrInvalidYAML},
+		{"template parse", func() error { return ConvertToTemplate(data, invalidTemplate, output) }, ErrTemplateParse},
+		{"template execute", func() error { return ConvertToTemplate(data, failingTemplate, output) }, ErrTemplateExecute},
+		{"template required", func() error { return Convert(invalidYAML, output, "") }, ErrTemplateRequired},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.run()
+			if !errors.Is(err, tt.expected) {
+				t.Errorf("error = %v, want %v", err, tt.expected)
+			}
+		})
+	}
+}
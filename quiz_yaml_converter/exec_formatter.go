@@ -0,0 +1,74 @@
+package quiz_yaml_converter
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// ExecFormatPrefix は外部コマンドを出力形式として使う場合の-formatの接頭辞．
+// "exec:./my-formatter"のように指定する．
+const ExecFormatPrefix = "exec:"
+
+// ExecFormatter は外部コマンドで出力するFormatter．
+// 問題データを-format jsonと同じJSONの配列として標準入力に渡し，
+// コマンドの標準出力をそのまま出力とする．コマンドが0以外で終了した場合は
+// 標準エラー出力の内容を含むErrFormatterCommandを返す．
+type ExecFormatter struct {
+	Path string   // 実行するコマンド
+	Args []string // コマンドの引数
+}
+
+// Format は外部コマンドを実行し，その標準出力をwに書き出す．
+func (f ExecFormatter) Format(w io.Writer, items []QuizItem, opts ConvertOptions) error {
+	var input bytes.Buffer
+	if err := writeJSONItems(&input, items, opts); err != nil {
+		return err
+	}
+
+	if err := runCommand(f.Path, f.Args, &input, w); err != nil {
+		return fmt.Errorf("%w: %w", ErrFormatterCommand, err)
+	}
+	return nil
+}
+
+// runCommand は外部コマンドを実行し，stdinの内容を標準入力に渡して標準出力をstdoutに書き出す．
+// コマンドが失敗した場合は標準エラー出力の内容をエラーメッセージに含める．
+func runCommand(path string, args []string, stdin io.Reader, stdout io.Writer) error {
+	var stderr bytes.Buffer
+	cmd := exec.Command(path, args...)
+	cmd.Stdin = stdin
+	cmd.Stdout = stdout
+	cmd.Stderr = &stderr
+	cmd.Env = append(os.Environ(), "QUIZ_YAML_FORMAT_PROTOCOL=1")
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return fmt.Errorf("%s: %w: %s", path, err, msg)
+		}
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return nil
+}
+
+// ParseExecFormatter は"exec:コマンド 引数..."形式の指定からExecFormatterを作成する．
+// コマンドと引数は空白で区切る．
+func ParseExecFormatter(spec string) (ExecFormatter, error) {
+	fields := strings.Fields(strings.TrimPrefix(spec, ExecFormatPrefix))
+	if !strings.HasPrefix(spec, ExecFormatPrefix) || len(fields) == 0 {
+		return ExecFormatter{}, fmt.Errorf("%w: %s (expected %s<command>)", ErrUnsupportedFormat, spec, ExecFormatPrefix)
+	}
+	return ExecFormatter{Path: fields[0], Args: fields[1:]}, nil
+}
+
+// ResolveFormatter はLookupFormatterに加えて"exec:コマンド"形式の指定も受け付ける．
+// 外部コマンドを実行できるため，HTTPリクエストなど信頼できない入力には
+// LookupFormatterを使用すること．
+func ResolveFormatter(name string) (Formatter, error) {
+	if strings.HasPrefix(name, ExecFormatPrefix) {
+		return ParseExecFormatter(name)
+	}
+	return LookupFormatter(name)
+}
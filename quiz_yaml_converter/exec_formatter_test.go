@@ -0,0 +1,97 @@
+package quiz_yaml_converter
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os/exec"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseExecFormatter(t *testing.T) {
+	tests := []struct {
+		spec    string
+		want    ExecFormatter
+		wantErr bool
+	}{
+		{"exec:./my-formatter", ExecFormatter{Path: "./my-formatter", Args: []string{}}, false},
+		{"exec:python3 fmt.py --anki", ExecFormatter{Path: "python3", Args: []string{"fmt.py", "--anki"}}, false},
+		{"exec:", ExecFormatter{}, true},
+		{"exec:  ", ExecFormatter{}, true},
+		{"./my-formatter", ExecFormatter{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			got, err := ParseExecFormatter(tt.spec)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseExecFormatter() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				if !errors.Is(err, ErrUnsupportedFormat) {
+					t.Errorf("ParseExecFormatter() error = %v, want ErrUnsupportedFormat", err)
+				}
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseExecFormatter() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExecFormatter_Format(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not found")
+	}
+	items := []QuizItem{{Question: "日本の首都は？", Answer: "東京"}}
+
+	t.Run("stdout", func(t *testing.T) {
+		var buf bytes.Buffer
+		f := ExecFormatter{Path: "sh", Args: []string{"-c", "cat"}}
+		if err := f.Format(&buf, items, ConvertOptions{}); err != nil {
+			t.Fatalf("Format() error = %v", err)
+		}
+		var got []QuizItem
+		if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+			t.Fatalf("stdin was not JSON: %v", err)
+		}
+		if !reflect.DeepEqual(got, items) {
+			t.Errorf("Format() = %+v, want %+v", got, items)
+		}
+	})
+
+	t.Run("failure", func(t *testing.T) {
+		var buf bytes.Buffer
+		f := ExecFormatter{Path: "sh", Args: []string{"-c", "echo broken >&2; exit 3"}}
+		err := f.Format(&buf, items, ConvertOptions{})
+		if !errors.Is(err, ErrFormatterCommand) {
+			t.Fatalf("Format() error = %v, want ErrFormatterCommand", err)
+		}
+		if !strings.Contains(err.Error(), "broken") {
+			t.Errorf("Format() error = %q, want stderr message", err)
+		}
+	})
+
+	t.Run("missing command", func(t *testing.T) {
+		err := ExecFormatter{Path: "./no-such-formatter"}.Format(&bytes.Buffer{}, items, ConvertOptions{})
+		if !errors.Is(err, ErrFormatterCommand) {
+			t.Errorf("Format() error = %v, want ErrFormatterCommand", err)
+		}
+	})
+}
+
+func TestResolveFormatter(t *testing.T) {
+	if f, err := ResolveFormatter("exec:./my-formatter"); err != nil {
+		t.Errorf("ResolveFormatter(exec) error = %v", err)
+	} else if _, ok := f.(ExecFormatter); !ok {
+		t.Errorf("ResolveFormatter(exec) = %T, want ExecFormatter", f)
+	}
+	if _, err := ResolveFormatter("csv"); err != nil {
+		t.Errorf("ResolveFormatter(csv) error = %v", err)
+	}
+	if _, err := LookupFormatter("exec:./my-formatter"); !errors.Is(err, ErrUnsupportedFormat) {
+		t.Errorf("LookupFormatter(exec) error = %v, want ErrUnsupportedFormat", err)
+	}
+}
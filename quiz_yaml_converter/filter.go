@@ -0,0 +1,595 @@
+package quiz_yaml_converter
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// FilterExpr は-filterで指定する問題の絞り込み条件を解析したもの．
+//
+// 条件は次のように書く．
+//
+//	genre == "歴史" and len(question) > 40
+//	tags contains "易" or criteria.ok =~ "^[ァ-ヶー]+$"
+//	not (answer == "" or spell)
+//
+// フィールドにはid, question（区切り記号を除いた問題文）, answer, reading, spell, genre,
+// source, reference, author, license, tags, comments, choices, segments, criteria（ok/ng/repeatのすべての値）,
+// criteria.ok, criteria.ng, criteria.repeatを使用できる．
+// リストのフィールドは，いずれかの要素が条件を満たせば真となる（!=と!~はどの要素も満たさない場合に真）．
+//
+// 演算子は==, !=, <, <=, >, >=, =~（正規表現に一致）, !~（一致しない）,
+// contains（文字列を含む，リストの場合は要素として含む）と，
+// and（&&）, or（||）, not（!）, 括弧が使える．関数はlen（文字数・要素数）, lowerがある．
+// 比較を伴わないフィールドは，空でなければ真となる．
+type FilterExpr struct {
+	source string
+	root   filterNode
+}
+
+// ParseFilter は絞り込み条件を解析する．構文に誤りがある場合はErrInvalidFilterを含むエラーを返す．
+func ParseFilter(expr string) (*FilterExpr, error) {
+	tokens, err := lexFilter(expr)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidFilter, err)
+	}
+	p := &filterParser{tokens: tokens}
+	root, err := p.parseOr()
+	if err == nil && p.peek().kind != tokEOF {
+		err = p.unexpected()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidFilter, err)
+	}
+	return &FilterExpr{source: expr, root: root}, nil
+}
+
+// String は解析前の条件を返す．
+func (f *FilterExpr) String() string {
+	return f.source
+}
+
+// Match は問題が条件を満たすかを返す．
+// 数値でない値の大小比較など，評価できない条件の場合はエラーを返す．
+func (f *FilterExpr) Match(item QuizItem) (bool, error) {
+	v, err := f.root.eval(item)
+	if err != nil {
+		return false, err
+	}
+	return truthy(v), nil
+}
+
+// Stage は条件を満たす問題だけを残すStageを返す．
+func (f *FilterExpr) Stage() Stage {
+	return EachItem(func(_ int, item *QuizItem) (bool, error) {
+		return f.Match(*item)
+	})
+}
+
+// SelectItems は条件を満たす問題だけを返す．
+func SelectItems(items []QuizItem, expr string) ([]QuizItem, error) {
+	f, err := ParseFilter(expr)
+	if err != nil {
+		return nil, err
+	}
+	return Pipeline{f.Stage()}.Apply(items)
+}
+
+// 字句解析
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokIdent
+	tokString
+	tokNumber
+	tokOp
+	tokLParen
+	tokRParen
+)
+
+type filterToken struct {
+	kind tokenKind
+	text string // tokStringの場合は引用符を外した値
+	pos  int    // 条件の中での位置（バイト）
+}
+
+// 記号の演算子（長いものから順に照合する）
+var filterSymbols = []string{"==", "!=", "=~", "!~", "<=", ">=", "&&", "||", "<", ">", "!"}
+
+func lexFilter(src string) ([]filterToken, error) {
+	var tokens []filterToken
+	for i := 0; i < len(src); {
+		r, size := utf8.DecodeRuneInString(src[i:])
+		switch {
+		case unicode.IsSpace(r):
+			i += size
+		case r == '(':
+			tokens = append(tokens, filterToken{tokLParen, "(", i})
+			i++
+		case r == ')':
+			tokens = append(tokens, filterToken{tokRParen, ")", i})
+			i++
+		case r == '"' || r == '\'':
+			end := i + 1
+			for end < len(src) && src[end] != byte(r) {
+				if src[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if end >= len(src) {
+				return nil, fmt.Errorf("unterminated string at %d", i)
+			}
+			text := src[i+1 : end]
+			if r == '"' {
+				unquoted, err := strconv.Unquote(src[i : end+1])
+				if err != nil {
+					return nil, fmt.Errorf("invalid string at %d: %w", i, err)
+				}
+				text = unquoted
+			} else {
+				text = strings.ReplaceAll(text, `\'`, `'`)
+			}
+			tokens = append(tokens, filterToken{tokString, text, i})
+			i = end + 1
+		case r >= '0' && r <= '9' || r == '-' && i+1 < len(src) && src[i+1] >= '0' && src[i+1] <= '9':
+			end := i + 1
+			for end < len(src) && (src[end] >= '0' && src[end] <= '9' || src[end] == '.') {
+				end++
+			}
+			tokens = append(tokens, filterToken{tokNumber, src[i:end], i})
+			i = end
+		case r == '_' || unicode.IsLetter(r):
+			end := i
+			for end < len(src) {
+				r, size := utf8.DecodeRuneInString(src[end:])
+				if r != '_' && r != '.' && !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+					break
+				}
+				end += size
+			}
+			tokens = append(tokens, filterToken{tokIdent, src[i:end], i})
+			i = end
+		default:
+			matched := false
+			for _, sym := range filterSymbols {
+				if strings.HasPrefix(src[i:], sym) {
+					tokens = append(tokens, filterToken{tokOp, sym, i})
+					i += len(sym)
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				return nil, fmt.Errorf("unexpected character %q at %d", r, i)
+			}
+		}
+	}
+	return append(tokens, filterToken{tokEOF, "", len(src)}), nil
+}
+
+// 構文解析
+
+type filterParser struct {
+	tokens []filterToken
+	pos    int
+}
+
+func (p *filterParser) peek() filterToken {
+	return p.tokens[p.pos]
+}
+
+func (p *filterParser) next() filterToken {
+	t := p.tokens[p.pos]
+	if t.kind != tokEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *filterParser) unexpected() error {
+	t := p.peek()
+	if t.kind == tokEOF {
+		return fmt.Errorf("unexpected end of expression")
+	}
+	return fmt.Errorf("unexpected %q at %d", t.text, t.pos)
+}
+
+// isKeyword はトークンが指定したキーワード（and, or, notなど）または記号かを返す．
+func (t filterToken) isKeyword(words ...string) bool {
+	if t.kind != tokIdent && t.kind != tokOp {
+		return false
+	}
+	for _, w := range words {
+		if strings.EqualFold(t.text, w) {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *filterParser) parseOr() (filterNode, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().isKeyword("or", "||") {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &logicalNode{or: true, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *filterParser) parseAnd() (filterNode, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().isKeyword("and", "&&") {
+		p.next()
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = &logicalNode{left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *filterParser) parseUnary() (filterNode, error) {
+	if p.peek().isKeyword("not", "!") {
+		p.next()
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &notNode{operand: operand}, nil
+	}
+	return p.parseComparison()
+}
+
+// 比較演算子
+var filterComparisons = []string{"==", "!=", "=~", "!~", "<", "<=", ">", ">=", "contains"}
+
+func (p *filterParser) parseComparison() (filterNode, error) {
+	left, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	t := p.peek()
+	if !t.isKeyword(filterComparisons...) {
+		return left, nil
+	}
+	p.next()
+	right, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	node := &compareNode{op: strings.ToLower(t.text), left: left, right: right}
+	if node.op == "=~" || node.op == "!~" {
+		if lit, ok := right.(literalNode); ok {
+			pattern, ok := lit.value.(string)
+			if !ok {
+				return nil, fmt.Errorf("regular expression must be a string at %d", t.pos)
+			}
+			if node.re, err = regexp.Compile(pattern); err != nil {
+				return nil, fmt.Errorf("invalid regular expression at %d: %w", t.pos, err)
+			}
+		}
+	}
+	return node, nil
+}
+
+func (p *filterParser) parseOperand() (filterNode, error) {
+	t := p.next()
+	switch t.kind {
+	case tokString:
+		return literalNode{t.text}, nil
+	case tokNumber:
+		n, err := strconv.ParseFloat(t.text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q at %d", t.text, t.pos)
+		}
+		return literalNode{n}, nil
+	case tokLParen:
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.next().kind != tokRParen {
+			return nil, fmt.Errorf("missing ) for ( at %d", t.pos)
+		}
+		return expr, nil
+	case tokIdent:
+		name := strings.ToLower(t.text)
+		switch name {
+		case "true", "false":
+			return literalNode{name == "true"}, nil
+		}
+		if fn, ok := filterFuncs[name]; ok && p.peek().kind == tokLParen {
+			p.next()
+			arg, err := p.parseOr()
+			if err != nil {
+				return nil, err
+			}
+			if p.next().kind != tokRParen {
+				return nil, fmt.Errorf("missing ) for %s( at %d", name, t.pos)
+			}
+			return &funcNode{name: name, fn: fn, arg: arg}, nil
+		}
+		if _, ok := filterFields[name]; !ok {
+			return nil, fmt.Errorf("unknown field %q at %d (available: %s)", t.text, t.pos, strings.Join(filterFieldNames(), ", "))
+		}
+		return fieldNode{name}, nil
+	}
+	if t.kind != tokEOF {
+		p.pos--
+	}
+	return nil, p.unexpected()
+}
+
+// 評価
+
+// filterNode は条件の構文木の節．評価結果はstring, float64, bool, []stringのいずれか．
+type filterNode interface {
+	eval(item QuizItem) (any, error)
+}
+
+type literalNode struct{ value any }
+
+func (n literalNode) eval(QuizItem) (any, error) { return n.value, nil }
+
+type fieldNode struct{ name string }
+
+func (n fieldNode) eval(item QuizItem) (any, error) { return filterFields[n.name](item), nil }
+
+// filterFields は条件で参照できるフィールド．
+var filterFields = map[string]func(item QuizItem) any{
+	"id":       func(item QuizItem) any { return item.ID },
+	"question": func(item QuizItem) any { return PlainQuestion(item) },
+	"answer":   func(item QuizItem) any { return item.Answer },
+	"reading":  func(item QuizItem) any { return item.Reading },
+	"spell":    func(item QuizItem) any { return item.Spell },
+	"spells": func(item QuizItem) any {
+		var values []string
+		for _, lang := range item.SpellLanguages() {
+			values = append(values, item.Spells[lang])
+		}
+		if values == nil && item.Spell != "" {
+			values = []string{item.Spell}
+		}
+		return values
+	},
+	"genre":      func(item QuizItem) any { return item.Genre },
+	"source":     func(item QuizItem) any { return item.Source },
+	"reference":  func(item QuizItem) any { return item.Reference },
+	"author":     func(item QuizItem) any { return item.Author },
+	"license":    func(item QuizItem) any { return item.License },
+	"difficulty": func(item QuizItem) any { return float64(item.Difficulty) },
+	"tags":       func(item QuizItem) any { return item.Tags },
+	"comments":   func(item QuizItem) any { return item.Comments },
+	"choices":    func(item QuizItem) any { return item.Choices },
+	"segments":   func(item QuizItem) any { return QuestionSegments(item) },
+	"document":   func(item QuizItem) any { return float64(item.Document) },
+	"criteria": func(item QuizItem) any {
+		var values []string
+		for _, key := range defaultCriteriaOrder {
+			values = append(values, item.Criteria[key]...)
+		}
+		return values
+	},
+	"criteria.ok":     func(item QuizItem) any { return item.Criteria["ok"] },
+	"criteria.ng":     func(item QuizItem) any { return item.Criteria["ng"] },
+	"criteria.repeat": func(item QuizItem) any { return item.Criteria["repeat"] },
+}
+
+func filterFieldNames() []string {
+	names := make([]string, 0, len(filterFields))
+	for name := range filterFields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// filterFuncs は条件で使える関数．
+var filterFuncs = map[string]func(v any) (any, error){
+	"len": func(v any) (any, error) {
+		switch v := v.(type) {
+		case string:
+			return float64(utf8.RuneCountInString(v)), nil
+		case []string:
+			return float64(len(v)), nil
+		}
+		return nil, fmt.Errorf("len: unsupported value %v", v)
+	},
+	"lower": func(v any) (any, error) {
+		switch v := v.(type) {
+		case string:
+			return strings.ToLower(v), nil
+		case []string:
+			lowered := make([]string, len(v))
+			for i, s := range v {
+				lowered[i] = strings.ToLower(s)
+			}
+			return lowered, nil
+		}
+		return nil, fmt.Errorf("lower: unsupported value %v", v)
+	},
+}
+
+type funcNode struct {
+	name string
+	fn   func(v any) (any, error)
+	arg  filterNode
+}
+
+func (n *funcNode) eval(item QuizItem) (any, error) {
+	v, err := n.arg.eval(item)
+	if err != nil {
+		return nil, err
+	}
+	return n.fn(v)
+}
+
+type notNode struct{ operand filterNode }
+
+func (n *notNode) eval(item QuizItem) (any, error) {
+	v, err := n.operand.eval(item)
+	if err != nil {
+		return nil, err
+	}
+	return !truthy(v), nil
+}
+
+type logicalNode struct {
+	or          bool
+	left, right filterNode
+}
+
+func (n *logicalNode) eval(item QuizItem) (any, error) {
+	l, err := n.left.eval(item)
+	if err != nil {
+		return nil, err
+	}
+	// 短絡評価
+	if truthy(l) == n.or {
+		return n.or, nil
+	}
+	r, err := n.right.eval(item)
+	if err != nil {
+		return nil, err
+	}
+	return truthy(r), nil
+}
+
+type compareNode struct {
+	op          string
+	left, right filterNode
+	re          *regexp.Regexp // 右辺が文字列リテラルの正規表現の場合に事前にコンパイルしたもの
+}
+
+func (n *compareNode) eval(item QuizItem) (any, error) {
+	l, err := n.left.eval(item)
+	if err != nil {
+		return nil, err
+	}
+	r, err := n.right.eval(item)
+	if err != nil {
+		return nil, err
+	}
+
+	re := n.re
+	if re == nil && (n.op == "=~" || n.op == "!~") {
+		pattern, ok := r.(string)
+		if !ok {
+			return nil, fmt.Errorf("%s: regular expression must be a string", n.op)
+		}
+		if re, err = regexp.Compile(pattern); err != nil {
+			return nil, err
+		}
+	}
+
+	// リストはいずれかの要素が条件を満たすかで判定し，否定の演算子は結果を反転する
+	if list, ok := l.([]string); ok {
+		op, negate := n.op, false
+		switch op {
+		case "!=":
+			op, negate = "==", true
+		case "!~":
+			op, negate = "=~", true
+		case "contains":
+			op = "=="
+		}
+		for _, elem := range list {
+			ok, err := compareScalar(op, elem, r, re)
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				return !negate, nil
+			}
+		}
+		return negate, nil
+	}
+	return compareScalar(n.op, l, r, re)
+}
+
+// compareScalar はリストでない値同士を比較する．
+func compareScalar(op string, l, r any, re *regexp.Regexp) (bool, error) {
+	switch op {
+	case "=~":
+		return re.MatchString(toFilterString(l)), nil
+	case "!~":
+		return !re.MatchString(toFilterString(l)), nil
+	case "contains":
+		return strings.Contains(toFilterString(l), toFilterString(r)), nil
+	case "==", "!=":
+		equal := toFilterString(l) == toFilterString(r)
+		if ln, lok := l.(float64); lok {
+			if rn, rok := r.(float64); rok {
+				equal = ln == rn
+			}
+		}
+		if lb, lok := l.(bool); lok {
+			equal = lb == truthy(r)
+		}
+		return equal == (op == "=="), nil
+	}
+
+	ln, lok := l.(float64)
+	rn, rok := r.(float64)
+	if !lok || !rok {
+		return false, fmt.Errorf("%s: both sides must be numbers (got %v and %v)", op, l, r)
+	}
+	switch op {
+	case "<":
+		return ln < rn, nil
+	case "<=":
+		return ln <= rn, nil
+	case ">":
+		return ln > rn, nil
+	default:
+		return ln >= rn, nil
+	}
+}
+
+func toFilterString(v any) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	case []string:
+		return strings.Join(v, ",")
+	}
+	return ""
+}
+
+// truthy は値を真偽値として扱う．空文字列，空のリスト，0，falseが偽となる．
+func truthy(v any) bool {
+	switch v := v.(type) {
+	case string:
+		return v != ""
+	case float64:
+		return v != 0
+	case bool:
+		return v
+	case []string:
+		return len(v) > 0
+	}
+	return false
+}
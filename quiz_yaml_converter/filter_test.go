@@ -0,0 +1,121 @@
+package quiz_yaml_converter
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestFilterExpr_Match(t *testing.T) {
+	item := QuizItem{
+		ID:       "q-1",
+		Question: "日本で一番高い山は／何でしょう？",
+		Answer:   "富士山",
+		Spell:    "Mount Fuji",
+		Genre:    "地理",
+		Tags:     []string{"易", "日本"},
+		Criteria: map[string][]string{"ok": {"ふじさん"}, "ng": {"富士"}},
+		Document: 2,
+	}
+
+	tests := []struct {
+		expr string
+		want bool
+	}{
+		{`genre == "地理"`, true},
+		{`genre != "地理"`, false},
+		{`genre == '地理' and answer == "富士山"`, true},
+		{`genre == "歴史" or answer == "富士山"`, true},
+		{`genre == "歴史" || answer == "エベレスト"`, false},
+		{`not genre == "歴史"`, true},
+		{`!(genre == "地理" && spell)`, false},
+		{`question == "日本で一番高い山は何でしょう？"`, true},
+		{`question contains "高い山"`, true},
+		{`len(question) > 10`, true},
+		{`len(question) <= 10`, false},
+		{`len(tags) == 2`, true},
+		{`tags contains "易"`, true},
+		{`tags contains "難"`, false},
+		{`tags == "日本"`, true},
+		{`tags != "日本"`, false},
+		{`tags != "難"`, true},
+		{`criteria.ok =~ "^[ぁ-ん]+$"`, true},
+		{`criteria.ng !~ "富士"`, false},
+		{`criteria contains "富士"`, true},
+		{`criteria.repeat`, false},
+		{`not criteria.repeat and not comments`, true},
+		{`comments == ""`, false},
+		{`lower(spell) =~ "fuji"`, true},
+		{`spell =~ "^mount"`, false},
+		{`segments == "何でしょう？"`, true},
+		{`id`, true},
+		{`document == 2`, true},
+		{`document < 2`, false},
+		{`true`, true},
+		{`(genre == "地理" or genre == "歴史") and len(answer) == 3`, true},
+		{`GENRE == "地理" AND Answer == "富士山"`, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			f, err := ParseFilter(tt.expr)
+			if err != nil {
+				t.Fatalf("ParseFilter() error = %v", err)
+			}
+			got, err := f.Match(item)
+			if err != nil {
+				t.Fatalf("Match() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Match() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseFilter_Errors(t *testing.T) {
+	tests := []string{
+		``,
+		`genre ==`,
+		`genre == "歴史`,
+		`unknown == "x"`,
+		`(genre == "x"`,
+		`genre == "x")`,
+		`genre =~ "["`,
+		`genre =~ 1`,
+		`genre # "x"`,
+		`len(question`,
+	}
+	for _, expr := range tests {
+		t.Run(expr, func(t *testing.T) {
+			if _, err := ParseFilter(expr); !errors.Is(err, ErrInvalidFilter) {
+				t.Errorf("ParseFilter() error = %v, want ErrInvalidFilter", err)
+			}
+		})
+	}
+}
+
+func TestFilterExpr_MatchError(t *testing.T) {
+	f, err := ParseFilter(`genre > 3`)
+	if err != nil {
+		t.Fatalf("ParseFilter() error = %v", err)
+	}
+	if _, err := f.Match(QuizItem{Genre: "歴史"}); err == nil {
+		t.Error("Match() error = nil, want error for non-numeric comparison")
+	}
+}
+
+func TestSelectItems(t *testing.T) {
+	items := []QuizItem{
+		{Question: "q1", Answer: "a1", Genre: "歴史"},
+		{Question: "q2", Answer: "a2", Genre: "地理"},
+		{Question: "q3", Answer: "a3", Genre: "歴史"},
+	}
+	got, err := SelectItems(items, `genre == "歴史"`)
+	if err != nil {
+		t.Fatalf("SelectItems() error = %v", err)
+	}
+	want := []QuizItem{items[0], items[2]}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("SelectItems() = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,173 @@
+package quiz_yaml_converter
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"text/template"
+
+	"github.com/m-uesaka/quiz-yaml-go/templates"
+)
+
+// Formatter は問題データを特定の形式で書き出す出力形式．
+// RegisterFormatterで登録すると，-formatやConvertOptions.Formatで名前を指定して使用できる．
+type Formatter interface {
+	Format(w io.Writer, items []QuizItem, opts ConvertOptions) error
+}
+
+// FormatterFunc は関数をFormatterとして扱うための型．
+type FormatterFunc func(w io.Writer, items []QuizItem, opts ConvertOptions) error
+
+// Format はf(w, items, opts)を呼び出す．
+func (f FormatterFunc) Format(w io.Writer, items []QuizItem, opts ConvertOptions) error {
+	return f(w, items, opts)
+}
+
+// MediaTyper は出力のMIMEタイプを返すFormatterが実装するインターフェース．
+// HTTPサーバーでContent-Typeを決めるために使用する．
+type MediaTyper interface {
+	MediaType() string
+}
+
+// TemplateFormatter はテンプレート文字列に従って出力するFormatter．
+// テンプレートでは-templateで指定するテンプレートファイルと同じデータと関数を使用できる．
+type TemplateFormatter struct {
+	Name string // テンプレート名（エラーメッセージに使われる）
+	Text string // テンプレートの内容
+	Type string // 出力のMIMEタイプ
+}
+
+// Format はテンプレートを解析し，問題データを適用してwに書き出す．
+func (f TemplateFormatter) Format(w io.Writer, items []QuizItem, opts ConvertOptions) error {
+	tmpl, err := template.New(f.Name).Funcs(templateFuncs(opts)).Parse(f.Text)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrTemplateParse, err)
+	}
+	return executeTemplate(w, tmpl, items, opts)
+}
+
+// MediaType は出力のMIMEタイプを返す．
+func (f TemplateFormatter) MediaType() string {
+	return f.Type
+}
+
+// mediaTypeFormatter はFormatterFuncにMIMEタイプを付けたもの．
+type mediaTypeFormatter struct {
+	FormatterFunc
+	mediaType string
+}
+
+func (f mediaTypeFormatter) MediaType() string {
+	return f.mediaType
+}
+
+// ContentType はfの出力に付けるContent-Typeを返す．
+// MIMEタイプが分からない場合はテキストとして扱う．CSVをShift_JISで出力する場合は
+// charsetをshift_jisにする．
+func ContentType(f Formatter, opts ConvertOptions) string {
+	mt, ok := f.(MediaTyper)
+	if !ok || mt.MediaType() == "" {
+		return "text/plain; charset=utf-8"
+	}
+	if strings.HasPrefix(mt.MediaType(), "text/csv") && opts.CSV.Encoding == EncodingShiftJIS {
+		return "text/csv; charset=shift_jis"
+	}
+	return mt.MediaType()
+}
+
+var (
+	formattersMu sync.RWMutex
+	formatters   = map[string]Formatter{}
+)
+
+// RegisterFormatter は出力形式を名前付きで登録する．
+// LookupFormatterと同じく名前の大文字と小文字は区別せず，小文字にして登録する．
+// 同じ名前が既に登録されている場合やfがnilの場合はpanicする．
+func RegisterFormatter(name string, f Formatter) {
+	name = strings.ToLower(name)
+	formattersMu.Lock()
+	defer formattersMu.Unlock()
+	if f == nil {
+		panic("quiz_yaml_converter: RegisterFormatter formatter is nil")
+	}
+	if _, dup := formatters[name]; dup {
+		panic("quiz_yaml_converter: RegisterFormatter called twice for " + name)
+	}
+	formatters[name] = f
+}
+
+// LookupFormatter は名前に対応する出力形式を返す．
+// 登録されていない場合はErrUnsupportedFormatを返す．
+func LookupFormatter(name string) (Formatter, error) {
+	formattersMu.RLock()
+	f, ok := formatters[strings.ToLower(name)]
+	formattersMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: %s (available: %s)", ErrUnsupportedFormat, name, strings.Join(FormatterNames(), ", "))
+	}
+	return f, nil
+}
+
+// FormatterNames は登録されている出力形式の名前をアルファベット順に返す．
+func FormatterNames() []string {
+	formattersMu.RLock()
+	defer formattersMu.RUnlock()
+	names := make([]string, 0, len(formatters))
+	for name := range formatters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// FormatForFile は出力ファイルの拡張子から出力形式の名前を返す．
+// 拡張子（.csv, .json, .htmlなど．.htmはhtmlとする）と同じ名前の出力形式が登録されていない場合は
+// ErrUnsupportedFormatを返す．
+func FormatForFile(path string) (string, error) {
+	name := strings.ToLower(strings.TrimPrefix(filepath.Ext(path), "."))
+	if name == "htm" {
+		name = "html"
+	}
+	if name == "" {
+		return "", fmt.Errorf("%w: cannot determine the output format of %s from its extension", ErrUnsupportedFormat, path)
+	}
+	if _, err := LookupFormatter(name); err != nil {
+		return "", fmt.Errorf("%s: %w", path, err)
+	}
+	return name, nil
+}
+
+// writeJSONItems は問題データをJSONの配列として書き出す．
+func writeJSONItems(w io.Writer, items []QuizItem, opts ConvertOptions) error {
+	items = withAssignedIDs(items, opts)
+	if items == nil {
+		items = []QuizItem{}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(items); err != nil {
+		return fmt.Errorf("failed to write JSON: %w", err)
+	}
+	return nil
+}
+
+// 組み込みの出力形式
+func init() {
+	RegisterFormatter("csv", mediaTypeFormatter{WriteCSV, "text/csv; charset=utf-8"})
+	RegisterFormatter("json", mediaTypeFormatter{writeJSONItems, "application/json; charset=utf-8"})
+	RegisterFormatter("jsonl", mediaTypeFormatter{writeJSONLItems, "application/jsonl; charset=utf-8"})
+	RegisterFormatter("msgpack", mediaTypeFormatter{writeMsgpackItems, msgpackMediaType})
+	RegisterFormatter("parquet", mediaTypeFormatter{writeParquet, "application/vnd.apache.parquet"})
+	markdown := TemplateFormatter{Name: "markdown", Text: templates.Markdown, Type: "text/markdown; charset=utf-8"}
+	RegisterFormatter("html", TemplateFormatter{Name: "html", Text: templates.HTML, Type: "text/html; charset=utf-8"})
+	RegisterFormatter("markdown", markdown)
+	RegisterFormatter("md", markdown)
+	RegisterFormatter("teleprompter", mediaTypeFormatter{writeTeleprompter, "text/html; charset=utf-8"})
+	RegisterFormatter("text", mediaTypeFormatter{writeText, "text/plain; charset=utf-8"})
+	RegisterFormatter("srt", mediaTypeFormatter{writeSRT, "application/x-subrip; charset=utf-8"})
+}
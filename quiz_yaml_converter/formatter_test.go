@@ -0,0 +1,198 @@
+package quiz_yaml_converter
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestLookupFormatter_Builtin(t *testing.T) {
+	items := []QuizItem{{Question: "日本の首都は？", Answer: "東京", Criteria: map[string][]string{"ok": {"とうきょう"}}}}
+
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"csv", "日本の首都は？"},
+		{"json", `"question": "日本の首都は？"`},
+		{"html", "<html"},
+		{"markdown", "日本の首都は？"},
+		{"md", "日本の首都は？"},
+		{"HTML", "<html"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := LookupFormatter(tt.name)
+			if err != nil {
+				t.Fatalf("LookupFormatter() error = %v", err)
+			}
+			var buf bytes.Buffer
+			if err := f.Format(&buf, items, ConvertOptions{}); err != nil {
+				t.Fatalf("Format() error = %v", err)
+			}
+			if !strings.Contains(buf.String(), tt.want) {
+				t.Errorf("Format() = %q, want to contain %q", buf.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestLookupFormatter_Unknown(t *testing.T) {
+	_, err := LookupFormatter("unknown")
+	if !errors.Is(err, ErrUnsupportedFormat) {
+		t.Fatalf("LookupFormatter() error = %v, want ErrUnsupportedFormat", err)
+	}
+	if !strings.Contains(err.Error(), "csv") {
+		t.Errorf("LookupFormatter() error = %q, want available formats", err)
+	}
+}
+
+func TestRegisterFormatter(t *testing.T) {
+	name := "test-count"
+	RegisterFormatter(name, FormatterFunc(func(w io.Writer, items []QuizItem, opts ConvertOptions) error {
+		_, err := io.WriteString(w, strings.Repeat("*", len(items)))
+		return err
+	}))
+	t.Cleanup(func() {
+		formattersMu.Lock()
+		delete(formatters, name)
+		formattersMu.Unlock()
+	})
+
+	if !slices.Contains(FormatterNames(), name) {
+		t.Errorf("FormatterNames() = %v, want to contain %q", FormatterNames(), name)
+	}
+
+	output := filepath.Join(t.TempDir(), "out.txt")
+	items := []QuizItem{{Question: "q1", Answer: "a1"}, {Question: "q2", Answer: "a2"}}
+	if err := ConvertItems(items, output, "", ConvertOptions{Format: name}); err != nil {
+		t.Fatalf("ConvertItems() error = %v", err)
+	}
+	content, _ := os.ReadFile(output)
+	if string(content) != "**" {
+		t.Errorf("output = %q, want %q", content, "**")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("RegisterFormatter() with duplicate name did not panic")
+		}
+	}()
+	RegisterFormatter(name, FormatterFunc(writeJSONItems))
+}
+
+func TestRegisterFormatter_CaseInsensitive(t *testing.T) {
+	RegisterFormatter("Test-Anki", FormatterFunc(writeJSONItems))
+	t.Cleanup(func() {
+		formattersMu.Lock()
+		delete(formatters, "test-anki")
+		formattersMu.Unlock()
+	})
+
+	for _, name := range []string{"Test-Anki", "test-anki", "TEST-ANKI"} {
+		if _, err := LookupFormatter(name); err != nil {
+			t.Errorf("LookupFormatter(%q) error = %v", name, err)
+		}
+	}
+	if !slices.Contains(FormatterNames(), "test-anki") {
+		t.Errorf("FormatterNames() = %v, want to contain %q", FormatterNames(), "test-anki")
+	}
+}
+
+func TestWriteJSONItems(t *testing.T) {
+	items := []QuizItem{{Question: "q", Answer: "a", CriteriaOrder: []string{"ng", "ok"}}}
+
+	var buf bytes.Buffer
+	if err := writeJSONItems(&buf, items, ConvertOptions{AssignIDs: true}); err != nil {
+		t.Fatalf("writeJSONItems() error = %v", err)
+	}
+	var got []QuizItem
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if len(got) != 1 || got[0].ID != ContentID(items[0]) || got[0].CriteriaOrder != nil {
+		t.Errorf("writeJSONItems() = %+v", got)
+	}
+
+	buf.Reset()
+	if err := writeJSONItems(&buf, nil, ConvertOptions{}); err != nil {
+		t.Fatalf("writeJSONItems() error = %v", err)
+	}
+	if strings.TrimSpace(buf.String()) != "[]" {
+		t.Errorf("writeJSONItems(nil) = %q, want []", buf.String())
+	}
+}
+
+func TestConvertFilesWithOptions_UnknownFormat(t *testing.T) {
+	output := filepath.Join(t.TempDir(), "out.txt")
+	err := ConvertFilesWithOptions([]string{"missing.yaml"}, output, "", ConvertOptions{Format: "unknown"})
+	if !errors.Is(err, ErrUnsupportedFormat) {
+		t.Errorf("ConvertFilesWithOptions() error = %v, want ErrUnsupportedFormat", err)
+	}
+}
+
+func TestContentType(t *testing.T) {
+	csv, _ := LookupFormatter("csv")
+	html, _ := LookupFormatter("html")
+	msgpack, _ := LookupFormatter("msgpack")
+	plain := FormatterFunc(writeJSONItems)
+	sjis := ConvertOptions{CSV: CSVOptions{Encoding: EncodingShiftJIS}}
+
+	tests := []struct {
+		name string
+		f    Formatter
+		opts ConvertOptions
+		want string
+	}{
+		{"csv", csv, ConvertOptions{}, "text/csv; charset=utf-8"},
+		{"csv shift_jis", csv, sjis, "text/csv; charset=shift_jis"},
+		{"html", html, sjis, "text/html; charset=utf-8"},
+		{"msgpack", msgpack, ConvertOptions{}, "application/vnd.msgpack"},
+		{"unknown", plain, ConvertOptions{}, "text/plain; charset=utf-8"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ContentType(tt.f, tt.opts); got != tt.want {
+				t.Errorf("ContentType() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatForFile(t *testing.T) {
+	tests := []struct {
+		path     string
+		expected string
+		wantErr  bool
+	}{
+		{"quiz.csv", "csv", false},
+		{"out/quiz.JSON", "json", false},
+		{"quiz.html", "html", false},
+		{"quiz.htm", "html", false},
+		{"quiz.md", "md", false},
+		{"quiz.markdown", "markdown", false},
+		{"quiz.msgpack", "msgpack", false},
+		{"quiz.tex", "", true},
+		{"quiz", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			got, err := FormatForFile(tt.path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("FormatForFile(%q) error = %v, wantErr %v", tt.path, err, tt.wantErr)
+			}
+			if tt.wantErr && !errors.Is(err, ErrUnsupportedFormat) {
+				t.Errorf("FormatForFile(%q) error = %v, want ErrUnsupportedFormat", tt.path, err)
+			}
+			if got != tt.expected {
+				t.Errorf("FormatForFile(%q) = %q, want %q", tt.path, got, tt.expected)
+			}
+		})
+	}
+}
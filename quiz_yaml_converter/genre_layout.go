@@ -0,0 +1,113 @@
+package quiz_yaml_converter
+
+import (
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+// decodeQuizItems は1つのドキュメントを問題データとしてデコードする．
+// ドキュメントが問題のリストの場合はそのままデコードする．
+// ジャンル名をキーとし，問題のリストを値とするマッピングの場合は，
+// キーの順に問題を連結し，genreが未設定の問題にキーのジャンル名を設定する．
+//
+//	歴史:
+//	  - question: ...
+//	地理:
+//	  - question: ...
+//
+// マッピングのmetadataキーにマッピングを書いた場合は，ジャンルではなくファイルのメタデータとして扱い，
+// metaに読み込む（FileMetadata参照）．metaは同じファイルの続くドキュメントにも引き継ぐ．
+func decodeQuizItems(node *yaml.Node, meta *FileMetadata) ([]QuizItem, error) {
+	var data []QuizItem
+	err := eachQuizItem(node, meta, func(item QuizItem) error {
+		data = append(data, item)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// eachQuizItem は1つのドキュメントの問題を1問ずつデコードしてfnに渡す．
+// ドキュメントの形式はdecodeQuizItemsと同じ．
+func eachQuizItem(node *yaml.Node, meta *FileMetadata, fn func(item QuizItem) error) error {
+	return eachQuizItemNode(node, meta, func(child *yaml.Node, genre string) error {
+		item, err := decodeQuizItem(child, genre, meta)
+		if err != nil {
+			return err
+		}
+		return fn(item)
+	})
+}
+
+// eachQuizItemNode は1つのドキュメントの各問題のノードを順にfnに渡す．
+// genreはジャンル名をキーとしたマッピングの場合のキー（問題のリストの場合は空）．
+// metadataキーのメタデータはmetaに読み込み，fnには渡さない．
+func eachQuizItemNode(node *yaml.Node, meta *FileMetadata, fn func(item *yaml.Node, genre string) error) error {
+	if node.Kind == yaml.DocumentNode {
+		if len(node.Content) == 0 {
+			return nil
+		}
+		node = node.Content[0]
+	}
+	if node.Kind != yaml.MappingNode {
+		return eachListItemNode(node, "", fn)
+	}
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		if key.Kind != yaml.ScalarNode || key.Value == "" {
+			return fmt.Errorf("line %d: genre name must be a non-empty string", key.Line)
+		}
+		if key.Value == metadataKey && value.Kind == yaml.MappingNode {
+			if err := value.Decode(meta); err != nil {
+				return fmt.Errorf("metadata: %w", err)
+			}
+			if meta.Version > CurrentSchemaVersion {
+				return fmt.Errorf("%w: %d (supported: %d or earlier)", ErrUnsupportedVersion, meta.Version, CurrentSchemaVersion)
+			}
+			continue
+		}
+		if value.Kind != yaml.SequenceNode && !(value.Kind == yaml.ScalarNode && value.Tag == "!!null") {
+			return fmt.Errorf("line %d: genre %q must be a list of quiz items", value.Line, key.Value)
+		}
+		if err := eachListItemNode(value, key.Value, fn); err != nil {
+			return fmt.Errorf("genre %q: %w", key.Value, err)
+		}
+	}
+	return nil
+}
+
+// eachListItemNode は問題のリストの各問題のノードをfnに渡す．
+func eachListItemNode(node *yaml.Node, genre string, fn func(item *yaml.Node, genre string) error) error {
+	if node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+	if node.Kind != yaml.SequenceNode {
+		// nullは空のリストとなり，それ以外はデコードのエラーとなる
+		var items []QuizItem
+		return node.Decode(&items)
+	}
+	for _, child := range node.Content {
+		if err := fn(child, genre); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// decodeQuizItem は問題のノードをデコードする．genreが空でない場合は，genreが未設定の問題にgenreを設定する．
+// author，licenseが未設定の問題にはmetaの値を設定する．
+func decodeQuizItem(node *yaml.Node, genre string, meta *FileMetadata) (QuizItem, error) {
+	var item QuizItem
+	if err := node.Decode(&item); err != nil {
+		return QuizItem{}, err
+	}
+	if item.Genre == "" {
+		item.Genre = genre
+	}
+	meta.apply(&item)
+	return item, nil
+}
@@ -0,0 +1,68 @@
+package quiz_yaml_converter
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParseYAMLData_GenreLayout(t *testing.T) {
+	input := `歴史:
+  - question: q1
+    answer: a1
+  - question: q2
+    answer: a2
+    genre: 日本史
+地理:
+  - question: q3
+    answer: a3
+空のジャンル:
+`
+	data, err := ParseYAMLData([]byte(input))
+	if err != nil {
+		t.Fatalf("ParseYAMLData() error = %v", err)
+	}
+	var got []string
+	for _, item := range data {
+		got = append(got, item.Question+":"+item.Genre)
+	}
+	if want := "q1:歴史,q2:日本史,q3:地理"; strings.Join(got, ",") != want {
+		t.Errorf("ParseYAMLData() = %v, want %s", got, want)
+	}
+}
+
+func TestParseYAMLData_GenreLayoutMultipleDocuments(t *testing.T) {
+	input := "歴史:\n  - question: q1\n    answer: a1\n---\n- question: q2\n  answer: a2\n  genre: 地理\n"
+	data, err := ParseYAMLData([]byte(input))
+	if err != nil {
+		t.Fatalf("ParseYAMLData() error = %v", err)
+	}
+	if len(data) != 2 || data[0].Genre != "歴史" || data[0].Document != 1 || data[1].Genre != "地理" || data[1].Document != 2 {
+		t.Errorf("ParseYAMLData() = %+v", data)
+	}
+}
+
+func TestParseYAMLData_GenreLayoutInvalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"single item mapping", "question: q1\nanswer: a1\n", `genre "question" must be a list`},
+		{"nested mapping", "歴史:\n  question: q1\n", `genre "歴史" must be a list`},
+		{"invalid item", "歴史:\n  - question: [a, b]\n", `genre "歴史"`},
+		{"empty key", "\"\":\n  - question: q1\n", "genre name must be a non-empty string"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseYAMLData([]byte(tt.input))
+			if !errors.Is(err, ErrInvalidYAML) {
+				t.Fatalf("ParseYAMLData() error = %v, want ErrInvalidYAML", err)
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("ParseYAMLData() error = %v, want to contain %q", err, tt.want)
+			}
+		})
+	}
+}
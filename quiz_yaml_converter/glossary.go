@@ -0,0 +1,148 @@
+// 問題文から主な語（キーワード）を取り出し，複数の問題に現れる語の一覧（用語集）を作る処理です．
+// 問題集の中で話題が重なっている問題を編集者が見つけるために使います．
+package quiz_yaml_converter
+
+import (
+	"slices"
+	"sort"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// DefaultGlossaryStopwords は用語集に含めない既定の語．クイズの問題文によく現れ，話題を表さない語．
+var DefaultGlossaryStopwords = []string{
+	"問題", "答え", "名前", "言葉", "一般", "現在", "最初", "最後", "以上", "以下", "正式", "名称", "通称", "一種", "一部",
+}
+
+// 用語集の作成に関するオプション
+type GlossaryOptions struct {
+	// trueの場合，組み込みのHTMLとMarkdownのテンプレートの末尾に用語集を出力する．
+	Enabled bool
+
+	// 用語集に含めない語．DefaultGlossaryStopwordsに加えて除外する（英字の大文字と小文字は区別しない）．
+	Stopwords []string
+
+	// 用語集に含める語が現れる問題の最小数．0の場合は2（複数の問題に現れる語のみ）とする．
+	MinQuestions int
+}
+
+// GlossaryEntry は用語集の1項目．
+type GlossaryEntry struct {
+	Term    string   // 語（英字の語は最初に現れた表記）
+	Numbers []string // この語が問題文に現れる問題の番号（出力順）
+}
+
+// minTermLength は語として取り出す最小の文字数（仮名・漢字）．英字の語は3文字以上とする．
+const minTermLength = 2
+
+// ExtractKeywords は問題文などのtextから主な語を現れた順に重複なく取り出す．
+// 形態素解析は使わず，2文字以上続くカタカナ（長音符・中黒を含む），2文字以上続く漢字，
+// 3文字以上の英数字（英字を含むもの）をそれぞれ1語とする．全角・半角は揃えてから取り出す．
+// stopwordsに含まれる語（英字の大文字と小文字は区別しない）は取り出さない．
+func ExtractKeywords(text string, stopwords []string) []string {
+	var terms []string
+	seen := map[string]bool{}
+	add := func(term string) {
+		term = strings.Trim(term, "・")
+		key := strings.ToLower(term)
+		if seen[key] || isStopword(key, stopwords) {
+			return
+		}
+		seen[key] = true
+		terms = append(terms, term)
+	}
+
+	runes := []rune(norm.NFKC.String(text))
+	for i := 0; i < len(runes); {
+		class := termClass(runes[i])
+		j := i + 1
+		for j < len(runes) && termClass(runes[j]) == class {
+			j++
+		}
+		run := runes[i:j]
+		switch class {
+		case termKatakana, termKanji:
+			if len([]rune(strings.Trim(string(run), "・"))) >= minTermLength {
+				add(string(run))
+			}
+		case termLatin:
+			if len(run) > minTermLength && slices.ContainsFunc(run, unicode.IsLetter) {
+				add(string(run))
+			}
+		}
+		i = j
+	}
+	return terms
+}
+
+// isStopword はkey（小文字にした語）が用語集に含めない語かを返す．
+func isStopword(key string, stopwords []string) bool {
+	for _, list := range [][]string{DefaultGlossaryStopwords, stopwords} {
+		for _, word := range list {
+			if strings.ToLower(norm.NFKC.String(strings.TrimSpace(word))) == key {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// 語を構成する文字の種類
+const (
+	termNone = iota
+	termKatakana
+	termKanji
+	termLatin
+)
+
+// termClass は文字が語のどの種類の一部となるかを返す．
+func termClass(r rune) int {
+	switch {
+	case r == '々' || r == 'ヶ' || unicode.Is(unicode.Han, r): // 霞ヶ関のようなヶは漢字の一部とする
+		return termKanji
+	case r == 'ー' || r == '・' || unicode.Is(unicode.Katakana, r):
+		return termKatakana
+	case r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)):
+		return termLatin
+	}
+	return termNone
+}
+
+// BuildGlossary は問題文から取り出した語（ExtractKeywords参照）のうち，opts.MinQuestions以上の問題に現れる語の一覧を返す．
+// numbersにはQuestionNumbersで求めた各問題の番号を渡す．語は五十音順（CompareGojuon参照）に並べる．
+func BuildGlossary(data []QuizItem, numbers []string, opts GlossaryOptions) []GlossaryEntry {
+	minQuestions := opts.MinQuestions
+	if minQuestions <= 0 {
+		minQuestions = 2
+	}
+	var entries []GlossaryEntry
+	positions := map[string]int{}
+	for i, item := range data {
+		for _, term := range ExtractKeywords(PlainQuestion(item), opts.Stopwords) {
+			key := strings.ToLower(term)
+			pos, ok := positions[key]
+			if !ok {
+				pos = len(entries)
+				positions[key] = pos
+				entries = append(entries, GlossaryEntry{Term: term})
+			}
+			number := ""
+			if i < len(numbers) {
+				number = numbers[i]
+			}
+			entries[pos].Numbers = append(entries[pos].Numbers, number)
+		}
+	}
+	entries = slices.DeleteFunc(entries, func(entry GlossaryEntry) bool {
+		return len(entry.Numbers) < minQuestions
+	})
+	sort.SliceStable(entries, func(a, b int) bool {
+		return CompareGojuon(entries[a].Term, entries[b].Term) < 0
+	})
+	if len(entries) == 0 {
+		return nil
+	}
+	return entries
+}
@@ -0,0 +1,116 @@
+package quiz_yaml_converter
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestExtractKeywords(t *testing.T) {
+	tests := []struct {
+		text      string
+		stopwords []string
+		expected  []string
+	}{
+		{"レオナルド・ダ・ヴィンチが描いた絵画は？", nil, []string{"レオナルド・ダ・ヴィンチ", "絵画"}},
+		{"霞ヶ関にある官公庁の名前は？", nil, []string{"霞ヶ関", "官公庁"}},
+		{"DNAの二重らせん構造を発見したのは？", nil, []string{"DNA", "二重", "構造", "発見"}},
+		{"ＤＮＡとdnaとＲＮＡ", nil, []string{"DNA", "RNA"}},
+		{"ｶﾒﾗとカメラ", nil, []string{"カメラ"}},
+		{"1945年の出来事は？", nil, []string{"出来事"}},
+		{"この問題の答えは？", nil, nil},
+		{"日本の首都は東京", []string{"首都", " 東京 "}, []string{"日本"}},
+		{"TCP/IPのIPとは？", []string{"tcp"}, nil},
+	}
+	for _, tt := range tests {
+		if got := ExtractKeywords(tt.text, tt.stopwords); !reflect.DeepEqual(got, tt.expected) {
+			t.Errorf("ExtractKeywords(%q, %q) = %q, want %q", tt.text, tt.stopwords, got, tt.expected)
+		}
+	}
+}
+
+func TestBuildGlossary(t *testing.T) {
+	data := []QuizItem{
+		{Question: "ノーベル物理学賞を受賞した／日本人は？"},
+		{Question: "アインシュタインがノーベル賞を受賞した理由は？"},
+		{Question: "ノーベル賞の授賞式が行われる都市は？"},
+		{Question: "相対性理論を提唱したのは？"},
+	}
+	numbers := []string{"1", "2", "3", "4"}
+	expected := []GlossaryEntry{
+		{Term: "ノーベル", Numbers: []string{"1", "2", "3"}},
+		{Term: "受賞", Numbers: []string{"1", "2"}},
+	}
+	if got := BuildGlossary(data, numbers, GlossaryOptions{}); !reflect.DeepEqual(got, expected) {
+		t.Errorf("BuildGlossary() = %+v, want %+v", got, expected)
+	}
+
+	expected = []GlossaryEntry{{Term: "ノーベル", Numbers: []string{"1", "2", "3"}}}
+	if got := BuildGlossary(data, numbers, GlossaryOptions{MinQuestions: 3}); !reflect.DeepEqual(got, expected) {
+		t.Errorf("BuildGlossary(MinQuestions: 3) = %+v, want %+v", got, expected)
+	}
+	expected = []GlossaryEntry{{Term: "受賞", Numbers: []string{"1", "2"}}}
+	if got := BuildGlossary(data, numbers, GlossaryOptions{Stopwords: []string{"ノーベル"}}); !reflect.DeepEqual(got, expected) {
+		t.Errorf("BuildGlossary(Stopwords) = %+v, want %+v", got, expected)
+	}
+	if got := BuildGlossary(data[3:], numbers[3:], GlossaryOptions{}); got != nil {
+		t.Errorf("BuildGlossary() without overlap = %+v, want nil", got)
+	}
+}
+
+func TestLoadStopwords(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "stopwords.txt")
+	if err := os.WriteFile(path, []byte("# 除外する語\nノーベル\n\n受賞\nノーベル\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	got, err := LoadStopwords([]string{path})
+	if err != nil {
+		t.Fatalf("LoadStopwords() error = %v", err)
+	}
+	if want := []string{"ノーベル", "受賞"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("LoadStopwords() = %q, want %q", got, want)
+	}
+	if _, err := LoadStopwords([]string{filepath.Join(t.TempDir(), "missing.txt")}); err == nil || !strings.Contains(err.Error(), "stopword list") {
+		t.Errorf("LoadStopwords() error = %v, want an error about the stopword list", err)
+	}
+}
+
+func TestBuiltinTemplates_Glossary(t *testing.T) {
+	items := []QuizItem{
+		{Question: "ノーベル賞を受賞した日本人は？", Answer: "湯川秀樹"},
+		{Question: "ノーベル賞の授賞式が行われる都市は？", Answer: "ストックホルム"},
+	}
+	tests := []struct {
+		format   string
+		expected string
+	}{
+		{"markdown", "## Glossary\n\n- ノーベル (Q1, Q2)\n"},
+		{"html", `<li>ノーベル（<a href="#q1">Q1</a>，<a href="#q2">Q2</a>）</li>`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			f, err := LookupFormatter(tt.format)
+			if err != nil {
+				t.Fatalf("LookupFormatter() error = %v", err)
+			}
+			var buf bytes.Buffer
+			if err := f.Format(&buf, items, ConvertOptions{Glossary: GlossaryOptions{Enabled: true}}); err != nil {
+				t.Fatalf("Format() error = %v", err)
+			}
+			if !strings.Contains(buf.String(), tt.expected) {
+				t.Errorf("output does not contain %q:\n%s", tt.expected, buf.String())
+			}
+
+			buf.Reset()
+			if err := f.Format(&buf, items, ConvertOptions{}); err != nil {
+				t.Fatalf("Format() error = %v", err)
+			}
+			if strings.Contains(buf.String(), "ノーベル (Q") || strings.Contains(buf.String(), "用語集") {
+				t.Errorf("output without Glossary.Enabled contains a glossary:\n%s", buf.String())
+			}
+		})
+	}
+}
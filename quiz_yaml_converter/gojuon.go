@@ -0,0 +1,157 @@
+// 答えの読みの五十音順（あ→ん）で問題を並べ替える処理です．
+// 文字コード順ではなく，濁音・半濁音や小書きの仮名，長音符を清音と同じ位置に並べる日本語の辞書の順序で比較します．
+package quiz_yaml_converter
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// SortKey は問題を並べ替える基準．
+type SortKey string
+
+const (
+	SortNone    SortKey = ""        // 並べ替えない（YAMLの順序のまま）
+	SortReading SortKey = "reading" // 答えの読みの五十音順（ReadingSortKey参照）
+)
+
+// ParseSortKey は並べ替えの基準の名前を解析する．空文字列の場合はSortNoneとなる．
+func ParseSortKey(name string) (SortKey, error) {
+	switch key := SortKey(strings.ToLower(strings.TrimSpace(name))); key {
+	case SortNone, SortReading:
+		return key, nil
+	default:
+		return "", fmt.Errorf("unsupported sort key: %q (available: reading)", name)
+	}
+}
+
+// SortItems は問題をkeyの順に並べ替えるStageを作成する．順序が同じ問題は元の順序を保つ．
+// SortNoneの場合は並べ替えない．
+func SortItems(key SortKey) Stage {
+	return StageFunc(func(items []QuizItem) ([]QuizItem, error) {
+		switch key {
+		case SortNone:
+			return items, nil
+		case SortReading:
+			keys := make([]string, len(items))
+			for i, item := range items {
+				keys[i] = ReadingSortKey(item)
+			}
+			order := make([]int, len(items))
+			for i := range order {
+				order[i] = i
+			}
+			sort.SliceStable(order, func(a, b int) bool {
+				return CompareGojuon(keys[order[a]], keys[order[b]]) < 0
+			})
+			sorted := make([]QuizItem, len(items))
+			for i, j := range order {
+				sorted[i] = items[j]
+			}
+			return sorted, nil
+		default:
+			return nil, fmt.Errorf("unsupported sort key: %q", key)
+		}
+	})
+}
+
+// ReadingSortKey は五十音順の並べ替えに使う問題の読み（ひらがな）を返す．
+// 読み（reading）が書かれていればそれを，なければ答えを使い，全角・半角を揃えてカタカナをひらがなに直す．
+// 漢字を含む答えは読みが分からないため仮名の後に並ぶ．-reading-command（FillReadings）で読みを補える．
+func ReadingSortKey(item QuizItem) string {
+	reading := strings.TrimSpace(item.Reading)
+	if reading == "" {
+		reading = strings.TrimSpace(item.Answer)
+	}
+	return NormalizeReading(norm.NFKC.String(reading))
+}
+
+// CompareGojuon は文字列aとbを五十音順で比較し，a<bなら-1，a>bなら1，等しければ0を返す．
+// まず濁点・半濁点と小書きを除いた清音（長音符は直前の仮名の母音）で比較し，同じ場合は
+// 清音→濁音→半濁音，小書き→通常の仮名→長音符の順とする．カタカナはひらがなと同じに扱う．
+// 仮名以外の文字は文字コード順で，英数字は仮名の前に，漢字は仮名の後に並ぶ（英字の大文字と小文字は区別しない）．
+func CompareGojuon(a, b string) int {
+	wa, wb := gojuonWeights(a), gojuonWeights(b)
+	for level := range 3 {
+		for i := 0; i < len(wa) && i < len(wb); i++ {
+			if c := compareInt(wa[i][level], wb[i][level]); c != 0 {
+				return c
+			}
+		}
+		if c := compareInt(len(wa), len(wb)); c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+func compareInt(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// 濁点・半濁点（結合文字）
+const (
+	combiningVoiced     = '゙'
+	combiningSemiVoiced = '゚'
+	prolongedSoundMark  = 'ー'
+)
+
+// 小書きの仮名から通常の仮名への対応
+var largeKana = map[rune]rune{
+	'ぁ': 'あ', 'ぃ': 'い', 'ぅ': 'う', 'ぇ': 'え', 'ぉ': 'お',
+	'っ': 'つ', 'ゃ': 'や', 'ゅ': 'ゆ', 'ょ': 'よ', 'ゎ': 'わ', 'ゕ': 'か', 'ゖ': 'け',
+}
+
+// 清音の仮名から母音への対応（長音符の読みに使う）
+var kanaVowels = func() map[rune]rune {
+	vowels := map[rune]rune{}
+	for vowel, row := range map[rune]string{
+		'あ': "あかさたなはまやらわ",
+		'い': "いきしちにひみりゐ",
+		'う': "うくすつぬふむゆる",
+		'え': "えけせてねへめれゑ",
+		'お': "おこそとのほもよろを",
+	} {
+		for _, r := range row {
+			vowels[r] = vowel
+		}
+	}
+	return vowels
+}()
+
+// gojuonWeights は文字列の各文字の比較の重み（清音，濁音の種類，小書き・長音の別）を返す．
+func gojuonWeights(s string) [][3]int {
+	var weights [][3]int
+	for _, r := range norm.NFD.String(NormalizeReading(norm.NFKC.String(s))) {
+		switch {
+		case r == combiningVoiced || r == combiningSemiVoiced:
+			if len(weights) > 0 {
+				weights[len(weights)-1][1] = 1 + int(r-combiningVoiced)
+			}
+			continue
+		case r == prolongedSoundMark && len(weights) > 0:
+			prev := rune(weights[len(weights)-1][0])
+			if vowel, ok := kanaVowels[prev]; ok {
+				prev = vowel
+			}
+			weights = append(weights, [3]int{int(prev), 0, 2})
+			continue
+		}
+		size := 1
+		if large, ok := largeKana[r]; ok {
+			r, size = large, 0
+		}
+		weights = append(weights, [3]int{int(unicode.ToLower(r)), 0, size})
+	}
+	return weights
+}
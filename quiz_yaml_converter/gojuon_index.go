@@ -0,0 +1,129 @@
+// 答えの読みの行（あ行，か行…）ごとに問題をまとめた索引を作る処理です．
+// 印刷された問題集の巻末の索引のように，HTMLの出力から各問題へリンクします．
+package quiz_yaml_converter
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// GojuonIndexEntry は五十音順の索引の1項目．
+type GojuonIndexEntry struct {
+	Answer  string // 答え
+	Reading string // 答えの読み（ReadingSortKeyのひらがな．答えを仮名にしたものと同じ場合は空）
+	Number  string // 問題番号
+	Link    string // 問題へのリンク（"#q1"．ページ分割の目次では"page-2.html#q11"）
+}
+
+// GojuonSection は五十音順の索引の1行分の項目．
+type GojuonSection struct {
+	Row     string             // 行の名前（"あ行"など．英数字で始まる答えは"英数字"，漢字などで始まる答えは"その他"）
+	Entries []GojuonIndexEntry // この行の項目（五十音順）
+}
+
+// 索引の行の名前
+const (
+	gojuonRowAlphanumeric = "英数字"
+	gojuonRowOther        = "その他"
+)
+
+// gojuonRows は索引の行の名前と，その行に含まれる清音の仮名．ゐ，ゑ，を，んはわ行とする．
+var gojuonRows = []struct {
+	name string
+	kana string
+}{
+	{"あ行", "あいうえお"},
+	{"か行", "かきくけこ"},
+	{"さ行", "さしすせそ"},
+	{"た行", "たちつてと"},
+	{"な行", "なにぬねの"},
+	{"は行", "はひふへほ"},
+	{"ま行", "まみむめも"},
+	{"や行", "やゆよ"},
+	{"ら行", "らりるれろ"},
+	{"わ行", "わゐゑをん"},
+}
+
+// GojuonRow は読みの先頭の文字が属する行の名前（"あ行"など）を返す．
+// 濁音・半濁音や小書きの仮名は清音の行とし，カタカナはひらがなと同じに扱う．
+// 英数字で始まる場合は"英数字"，それ以外（漢字や記号など）や空の場合は"その他"を返す．
+func GojuonRow(reading string) string {
+	weights := gojuonWeights(reading)
+	if len(weights) == 0 {
+		return gojuonRowOther
+	}
+	first := rune(weights[0][0])
+	for _, row := range gojuonRows {
+		if strings.ContainsRune(row.kana, first) {
+			return row.name
+		}
+	}
+	if first < unicode.MaxASCII && (unicode.IsLetter(first) || unicode.IsDigit(first)) {
+		return gojuonRowAlphanumeric
+	}
+	return gojuonRowOther
+}
+
+// QuestionAnchor は問題番号に対応するHTMLのアンカー（id属性の値）を返す．
+// 組み込みのHTMLのテンプレートでは各問題にこのidを付けている．
+func QuestionAnchor(number string) string {
+	return "q" + number
+}
+
+// Anchor は問題のHTMLのアンカー（QuestionAnchor参照）を返す．
+func (item TemplateItem) Anchor() string {
+	return QuestionAnchor(item.Number)
+}
+
+// GojuonIndex は問題を答えの読みの五十音順に並べ，行ごとにまとめた索引を返す．
+// 行は英数字，あ行〜わ行，その他の順で，問題のない行は含まない．読みの同じ問題は出力順に並べる．
+// ページ分割の目次（Pagesが設定されている場合）では，リンクに問題が含まれるページのファイル名を付ける．
+// テンプレートでは{{range .GojuonIndex}}のように使う．
+func (td TemplateData) GojuonIndex() []GojuonSection {
+	type keyedEntry struct {
+		GojuonIndexEntry
+		key string
+	}
+	entries := make([]keyedEntry, len(td.Items))
+	for i, item := range td.Items {
+		entry := GojuonIndexEntry{Answer: strings.TrimSpace(item.Answer)}
+		key := ReadingSortKey(item)
+		if key != ReadingSortKey(QuizItem{Answer: item.Answer}) {
+			entry.Reading = key
+		}
+		if i < len(td.Numbers) {
+			entry.Number = td.Numbers[i]
+			entry.Link = "#" + QuestionAnchor(entry.Number)
+		}
+		for _, page := range td.Pages {
+			if page.start <= i && i < page.end {
+				entry.Link = page.File + entry.Link
+				break
+			}
+		}
+		entries[i] = keyedEntry{entry, key}
+	}
+	sort.SliceStable(entries, func(a, b int) bool {
+		return CompareGojuon(entries[a].key, entries[b].key) < 0
+	})
+
+	rows := make([]string, 0, len(gojuonRows)+2)
+	rows = append(rows, gojuonRowAlphanumeric)
+	for _, row := range gojuonRows {
+		rows = append(rows, row.name)
+	}
+	rows = append(rows, gojuonRowOther)
+	grouped := map[string][]GojuonIndexEntry{}
+	for _, entry := range entries {
+		row := GojuonRow(entry.key)
+		grouped[row] = append(grouped[row], entry.GojuonIndexEntry)
+	}
+	var sections []GojuonSection
+	for _, row := range rows {
+		if len(grouped[row]) > 0 {
+			sections = append(sections, GojuonSection{Row: row, Entries: grouped[row]})
+		}
+	}
+	return sections
+}
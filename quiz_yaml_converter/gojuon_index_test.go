@@ -0,0 +1,140 @@
+package quiz_yaml_converter
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestGojuonRow(t *testing.T) {
+	tests := []struct {
+		reading  string
+		expected string
+	}{
+		{"あさり", "あ行"},
+		{"ガリレオ", "か行"},
+		{"ぱんだ", "は行"},
+		{"ゔぁいおりん", "あ行"},
+		{"ゃ", "や行"},
+		{"をとめ", "わ行"},
+		{"ｶﾒﾗ", "か行"},
+		{"DNA", "英数字"},
+		{"１０円", "英数字"},
+		{"東京", "その他"},
+		{"「", "その他"},
+		{"", "その他"},
+	}
+	for _, tt := range tests {
+		if got := GojuonRow(tt.reading); got != tt.expected {
+			t.Errorf("GojuonRow(%q) = %q, want %q", tt.reading, got, tt.expected)
+		}
+	}
+}
+
+func TestTemplateData_GojuonIndex(t *testing.T) {
+	td := TemplateData{
+		Items: []QuizItem{
+			{Question: "q1", Answer: "東京", Reading: "とうきょう"},
+			{Question: "q2", Answer: "ガリレオ"},
+			{Question: "q3", Answer: "仙台"},
+			{Question: "q4", Answer: "カリウム"},
+			{Question: "q5", Answer: "DNA"},
+		},
+		Numbers: []string{"1", "2", "3", "4", "5"},
+	}
+	expected := []GojuonSection{
+		{Row: "英数字", Entries: []GojuonIndexEntry{{Answer: "DNA", Number: "5", Link: "#q5"}}},
+		{Row: "か行", Entries: []GojuonIndexEntry{
+			{Answer: "カリウム", Number: "4", Link: "#q4"},
+			{Answer: "ガリレオ", Number: "2", Link: "#q2"},
+		}},
+		{Row: "た行", Entries: []GojuonIndexEntry{{Answer: "東京", Reading: "とうきょう", Number: "1", Link: "#q1"}}},
+		{Row: "その他", Entries: []GojuonIndexEntry{{Answer: "仙台", Number: "3", Link: "#q3"}}},
+	}
+	if got := td.GojuonIndex(); !reflect.DeepEqual(got, expected) {
+		t.Errorf("GojuonIndex() = %+v, want %+v", got, expected)
+	}
+
+	// ページ分割の目次では問題が含まれるページへリンクする
+	td.Pages = Paginate(td.Numbers, 2)
+	var links []string
+	for _, section := range td.GojuonIndex() {
+		for _, entry := range section.Entries {
+			links = append(links, entry.Link)
+		}
+	}
+	if want := []string{"page-3.html#q5", "page-2.html#q4", "page-1.html#q2", "page-1.html#q1", "page-2.html#q3"}; !reflect.DeepEqual(links, want) {
+		t.Errorf("GojuonIndex() links = %v, want %v", links, want)
+	}
+
+	if got := (TemplateData{}).GojuonIndex(); got != nil {
+		t.Errorf("GojuonIndex() without items = %+v, want nil", got)
+	}
+}
+
+func TestBuiltinTemplates_GojuonIndex(t *testing.T) {
+	items := []QuizItem{
+		{Question: "q1", Answer: "東京", Reading: "とうきょう"},
+		{Question: "q2", Answer: "あさり"},
+	}
+	f, err := LookupFormatter("html")
+	if err != nil {
+		t.Fatalf("LookupFormatter() error = %v", err)
+	}
+	var buf bytes.Buffer
+	if err := f.Format(&buf, items, ConvertOptions{GojuonIndex: true}); err != nil {
+		t.Fatalf("Format() error = %v", err)
+	}
+	for _, s := range []string{
+		`<div class="quiz-item" id="q1">`,
+		"<h3>あ行</h3>",
+		`<li><a href="#q2">あさり</a>（Q2）</li>`,
+		`<li><a href="#q1">東京</a><span class="reading">とうきょう</span>（Q1）</li>`,
+	} {
+		if !strings.Contains(buf.String(), s) {
+			t.Errorf("output does not contain %q:\n%s", s, buf.String())
+		}
+	}
+	if strings.Index(buf.String(), "あ行") > strings.Index(buf.String(), "た行") {
+		t.Errorf("あ行 should come before た行:\n%s", buf.String())
+	}
+
+	buf.Reset()
+	if err := f.Format(&buf, items, ConvertOptions{}); err != nil {
+		t.Fatalf("Format() error = %v", err)
+	}
+	if strings.Contains(buf.String(), "索引") {
+		t.Errorf("output without GojuonIndex contains an index:\n%s", buf.String())
+	}
+}
+
+func TestConvertToPaginatedHTML_GojuonIndex(t *testing.T) {
+	outputDir := t.TempDir()
+	items := []QuizItem{
+		{Question: "q1", Answer: "東京", Reading: "とうきょう"},
+		{Question: "q2", Answer: "あさり"},
+		{Question: "q3", Answer: "かもめ"},
+	}
+	if err := ConvertToPaginatedHTML(items, outputDir, "", 2, ConvertOptions{GojuonIndex: true}); err != nil {
+		t.Fatalf("ConvertToPaginatedHTML() error = %v", err)
+	}
+	index, err := os.ReadFile(filepath.Join(outputDir, PaginationIndexFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{`<a href="page-1.html#q2">あさり</a>`, `<a href="page-2.html#q3">かもめ</a>`} {
+		if !strings.Contains(string(index), s) {
+			t.Errorf("index.html does not contain %q:\n%s", s, index)
+		}
+	}
+	page, err := os.ReadFile(filepath.Join(outputDir, "page-2.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(page), `<div class="quiz-item" id="q3">`) {
+		t.Errorf("page-2.html does not contain the anchor of Q3:\n%s", page)
+	}
+}
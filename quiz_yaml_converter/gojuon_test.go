@@ -0,0 +1,112 @@
+package quiz_yaml_converter
+
+import (
+	"reflect"
+	"slices"
+	"testing"
+)
+
+func TestCompareGojuon(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		expected int
+	}{
+		{"あい", "あい", 0},
+		{"あ", "い", -1},
+		{"ん", "あ", 1},
+		{"わ", "を", -1},
+		{"アイ", "あい", 0},
+		{"ｱｲ", "あい", 0},
+		{"か", "が", -1},
+		{"がき", "かく", -1}, // 濁点より清音の違いを先に比較する
+		{"は", "ば", -1},
+		{"ば", "ぱ", -1},
+		{"や", "ゃ", 1},
+		{"きゃく", "きやく", -1},
+		{"かあど", "かーど", -1},
+		{"かーど", "かいと", -1}, // 長音符は直前の母音（あ）として比較する
+		{"コーヒー", "こうひい", 1},
+		{"あ", "あい", -1},
+		{"DNA", "あ", -1},
+		{"dna", "DNA", 0},
+		{"あ", "亜", -1},
+		{"", "あ", -1},
+	}
+	for _, tt := range tests {
+		if got := CompareGojuon(tt.a, tt.b); got != tt.expected {
+			t.Errorf("CompareGojuon(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.expected)
+		}
+		if got := CompareGojuon(tt.b, tt.a); got != -tt.expected {
+			t.Errorf("CompareGojuon(%q, %q) = %d, want %d", tt.b, tt.a, got, -tt.expected)
+		}
+	}
+}
+
+func TestReadingSortKey(t *testing.T) {
+	tests := []struct {
+		item     QuizItem
+		expected string
+	}{
+		{QuizItem{Answer: "東京", Reading: "とうきょう"}, "とうきょう"},
+		{QuizItem{Answer: "東京", Reading: " トウキョウ "}, "とうきょう"},
+		{QuizItem{Answer: "アインシュタイン", Spell: "Einstein"}, "あいんしゅたいん"},
+		{QuizItem{Answer: "ＤＮＡ"}, "DNA"},
+		{QuizItem{Answer: "東京"}, "東京"},
+	}
+	for _, tt := range tests {
+		if got := ReadingSortKey(tt.item); got != tt.expected {
+			t.Errorf("ReadingSortKey(%+v) = %q, want %q", tt.item, got, tt.expected)
+		}
+	}
+}
+
+func TestSortItems(t *testing.T) {
+	items := []QuizItem{
+		{Question: "q1", Answer: "東京", Reading: "とうきょう"},
+		{Question: "q2", Answer: "ガリレオ"},
+		{Question: "q3", Answer: "仙台"},
+		{Question: "q4", Answer: "カリウム"},
+		{Question: "q5", Answer: "あさり"},
+		{Question: "q6", Answer: "DNA"},
+		{Question: "q7", Answer: "アサリ"},
+	}
+	original := slices.Clone(items)
+	got, err := SortItems(SortReading).Apply(items)
+	if err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	var questions []string
+	for _, item := range got {
+		questions = append(questions, item.Question)
+	}
+	want := []string{"q6", "q5", "q7", "q4", "q2", "q1", "q3"}
+	if !reflect.DeepEqual(questions, want) {
+		t.Errorf("SortItems() order = %v, want %v", questions, want)
+	}
+	if !reflect.DeepEqual(items, original) {
+		t.Errorf("SortItems() modified the input")
+	}
+
+	if got, _ := SortItems(SortNone).Apply(items); !reflect.DeepEqual(got, original) {
+		t.Errorf("SortItems(SortNone) = %+v, want the input order", got)
+	}
+}
+
+func TestParseSortKey(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected SortKey
+		wantErr  bool
+	}{
+		{"", SortNone, false},
+		{"reading", SortReading, false},
+		{" Reading ", SortReading, false},
+		{"answer", "", true},
+	}
+	for _, tt := range tests {
+		got, err := ParseSortKey(tt.input)
+		if (err != nil) != tt.wantErr || got != tt.expected {
+			t.Errorf("ParseSortKey(%q) = %q, %v, want %q (error: %v)", tt.input, got, err, tt.expected, tt.wantErr)
+		}
+	}
+}
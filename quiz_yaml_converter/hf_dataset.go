@@ -0,0 +1,282 @@
+// Hugging Faceのdatasetsで読み込める形式（JSON Linesとデータセットカード）での出力です．
+package quiz_yaml_converter
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// HFRecord はHugging Faceのデータセットの1行（1問）．
+// すべての行が同じ列を持つよう，値がない項目も空文字列・0・空の配列として出力する．
+type HFRecord struct {
+	ID              string   `json:"id"`
+	Question        string   `json:"question"`
+	Answer          string   `json:"answer"`
+	AcceptedAnswers []string `json:"accepted_answers"` // 答えと別解（criteria.ok）
+	Reading         string   `json:"reading"`
+	Genre           string   `json:"genre"`
+	Difficulty      int      `json:"difficulty"`
+	Tags            []string `json:"tags"`
+	Source          string   `json:"source"`
+	Author          string   `json:"author"`
+	License         string   `json:"license"`
+}
+
+// hfFeatures はHFRecordの列のdatasetsでの型（データセットカードのdataset_info.features）．
+var hfFeatures = []struct {
+	name, dtype string
+	sequence    bool
+	description string
+}{
+	{"id", "string", false, "Item ID (derived from the question and answer when the YAML has no id)"},
+	{"question", "string", false, "Question text"},
+	{"answer", "string", false, "Answer"},
+	{"accepted_answers", "string", true, "The answer and alternative answers accepted as correct (criteria.ok)"},
+	{"reading", "string", false, "Reading of the answer in kana"},
+	{"genre", "string", false, "Genre"},
+	{"difficulty", "int64", false, "Difficulty (larger is harder, 0 if unset)"},
+	{"tags", "string", true, "Tags"},
+	{"source", "string", false, "Source of the question"},
+	{"author", "string", false, "Author of the question"},
+	{"license", "string", false, "License of the question"},
+}
+
+// NewHFRecord は問題からHugging Faceのデータセットの1行を作成する．idがない場合はContentIDを使う．
+func NewHFRecord(item QuizItem) HFRecord {
+	accepted := item.AllAcceptedAnswers()
+	if accepted == nil {
+		accepted = []string{}
+	}
+	tags := item.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+	return HFRecord{
+		ID:              resultsID(item),
+		Question:        item.Question,
+		Answer:          item.Answer,
+		AcceptedAnswers: accepted,
+		Reading:         item.Reading,
+		Genre:           item.Genre,
+		Difficulty:      item.Difficulty,
+		Tags:            tags,
+		Source:          item.Source,
+		Author:          item.Author,
+		License:         item.License,
+	}
+}
+
+// WriteHFJSONL は問題データをHFRecordのJSON Linesとして書き出す．
+func WriteHFJSONL(w io.Writer, items []QuizItem) error {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	for _, item := range items {
+		if err := enc.Encode(NewHFRecord(item)); err != nil {
+			return fmt.Errorf("failed to write JSON Lines: %w", err)
+		}
+	}
+	return nil
+}
+
+// HFDatasetOptions はHugging Face形式で書き出すときの設定．
+type HFDatasetOptions struct {
+	Name          string // データセットカードの見出し（空の場合は出力ディレクトリの名前）
+	Language      string // データセットカードのlanguage（空の場合は"ja"）
+	OverwriteCard bool   // trueの場合，既存のデータセットカード（README.md）を上書きする
+}
+
+// WriteHFDataset は分割した問題をHugging Faceのdatasetsで読み込める構成でdirに書き出し，書き出したファイルのパスを返す．
+// 問題のあるデータだけをdata/train.jsonl, data/validation.jsonl, data/test.jsonlに書き出し，
+// 列と分割を記載したデータセットカードの雛形をREADME.mdに書き出す．
+// README.mdが既に存在する場合は，opts.OverwriteCardでなければ書き出さない（戻り値にも含めない）．
+func WriteHFDataset(dir string, splits DatasetSplits, opts HFDatasetOptions) ([]string, error) {
+	if err := os.MkdirAll(filepath.Join(dir, "data"), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create output directory: %w", err)
+	}
+	var written []string
+	for _, split := range hfSplits(splits) {
+		path := filepath.Join(dir, "data", split.name+".jsonl")
+		err := writeFileAtomic(path, false, func(w io.Writer) error {
+			return WriteHFJSONL(w, split.items)
+		})
+		if err != nil {
+			return written, fmt.Errorf("%s: %w", path, err)
+		}
+		written = append(written, path)
+	}
+
+	var card bytes.Buffer
+	if err := WriteHFDatasetCard(&card, splits, opts); err != nil {
+		return written, err
+	}
+	path := filepath.Join(dir, "README.md")
+	err := writeFileAtomic(path, !opts.OverwriteCard, func(w io.Writer) error {
+		_, err := w.Write(card.Bytes())
+		return err
+	})
+	if errors.Is(err, ErrOutputExists) {
+		return written, nil
+	}
+	if err != nil {
+		return written, fmt.Errorf("%s: %w", path, err)
+	}
+	return append(written, path), nil
+}
+
+// hfSplit は書き出す分割の名前と問題．
+type hfSplit struct {
+	name  string
+	items []QuizItem
+}
+
+// hfSplits は問題のある分割をtrain, validation, testの順に返す．
+func hfSplits(splits DatasetSplits) []hfSplit {
+	var result []hfSplit
+	for _, split := range []hfSplit{{"train", splits.Train}, {"validation", splits.Validation}, {"test", splits.Test}} {
+		if len(split.items) > 0 {
+			result = append(result, split)
+		}
+	}
+	return result
+}
+
+// WriteHFDatasetCard はHugging Faceのデータセットカード（README.md）の雛形を書き出す．
+// YAMLのメタデータには言語，ライセンス（すべての問題のlicenseが同じ場合），問題数の区分，
+// data/以下のファイルと分割の対応，列の型を記載し，本文の説明は後から書き足す前提の見出しと列の一覧とする．
+func WriteHFDatasetCard(w io.Writer, splits DatasetSplits, opts HFDatasetOptions) error {
+	name := opts.Name
+	if name == "" {
+		name = "Quiz dataset"
+	}
+	language := opts.Language
+	if language == "" {
+		language = "ja"
+	}
+
+	type dataFile struct {
+		Split string `yaml:"split"`
+		Path  string `yaml:"path"`
+	}
+	type config struct {
+		ConfigName string     `yaml:"config_name"`
+		DataFiles  []dataFile `yaml:"data_files"`
+	}
+	type feature struct {
+		Name     string `yaml:"name"`
+		Dtype    string `yaml:"dtype,omitempty"`
+		Sequence string `yaml:"sequence,omitempty"`
+	}
+	type splitInfo struct {
+		Name        string `yaml:"name"`
+		NumExamples int    `yaml:"num_examples"`
+	}
+	type datasetInfo struct {
+		Features []feature   `yaml:"features"`
+		Splits   []splitInfo `yaml:"splits"`
+	}
+	meta := struct {
+		Language       []string    `yaml:"language"`
+		License        string      `yaml:"license,omitempty"`
+		TaskCategories []string    `yaml:"task_categories"`
+		SizeCategories []string    `yaml:"size_categories"`
+		PrettyName     string      `yaml:"pretty_name"`
+		Configs        []config    `yaml:"configs"`
+		DatasetInfo    datasetInfo `yaml:"dataset_info"`
+	}{
+		Language:       []string{language},
+		TaskCategories: []string{"question-answering"},
+		PrettyName:     name,
+		Configs:        []config{{ConfigName: "default"}},
+	}
+
+	var all []QuizItem
+	for _, split := range hfSplits(splits) {
+		meta.Configs[0].DataFiles = append(meta.Configs[0].DataFiles, dataFile{split.name, "data/" + split.name + ".jsonl"})
+		meta.DatasetInfo.Splits = append(meta.DatasetInfo.Splits, splitInfo{split.name, len(split.items)})
+		all = append(all, split.items...)
+	}
+	meta.License = hfLicense(all)
+	meta.SizeCategories = []string{hfSizeCategory(len(all))}
+	for _, f := range hfFeatures {
+		if f.sequence {
+			meta.DatasetInfo.Features = append(meta.DatasetInfo.Features, feature{Name: f.name, Sequence: f.dtype})
+		} else {
+			meta.DatasetInfo.Features = append(meta.DatasetInfo.Features, feature{Name: f.name, Dtype: f.dtype})
+		}
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("---\n")
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(meta); err != nil {
+		return fmt.Errorf("failed to write dataset card: %w", err)
+	}
+	enc.Close()
+	buf.WriteString("---\n\n")
+
+	fmt.Fprintf(&buf, "# %s\n\n", name)
+	buf.WriteString("<!-- Describe the dataset: where the questions come from and what they are suitable for. -->\n\n")
+	buf.WriteString("## Dataset Structure\n\n")
+	buf.WriteString("| Split | Examples |\n|-------|----------|\n")
+	for _, s := range meta.DatasetInfo.Splits {
+		fmt.Fprintf(&buf, "| %s | %d |\n", s.Name, s.NumExamples)
+	}
+	buf.WriteString("\n### Data Fields\n\n| Field | Type | Description |\n|-------|------|-------------|\n")
+	for _, f := range hfFeatures {
+		typ := f.dtype
+		if f.sequence {
+			typ = "list of " + f.dtype
+		}
+		fmt.Fprintf(&buf, "| `%s` | %s | %s |\n", f.name, typ, f.description)
+	}
+	buf.WriteString("\n## Dataset Creation\n\n<!-- Describe how the questions were written, reviewed and split. -->\n\n")
+	buf.WriteString("## Licensing Information\n\n<!-- State the license of the questions and any attribution requirements. -->\n")
+
+	if _, err := w.Write(buf.Bytes()); err != nil {
+		return fmt.Errorf("failed to write dataset card: %w", err)
+	}
+	return nil
+}
+
+// hfLicense はすべての問題のlicenseが同じ場合に，その値をHugging Faceのライセンスの識別子の形式
+// （小文字で空白をハイフンにしたもの．例: CC BY 4.0 → cc-by-4.0）にして返す．
+// licenseが異なる問題がある場合は"other"，licenseがない問題がある場合は空文字列を返す．
+func hfLicense(items []QuizItem) string {
+	license := ""
+	for i, item := range items {
+		l := strings.TrimSpace(item.License)
+		if l == "" {
+			return ""
+		}
+		if i > 0 && l != license {
+			return "other"
+		}
+		license = l
+	}
+	return strings.ToLower(strings.Join(strings.Fields(license), "-"))
+}
+
+// hfSizeCategory は問題数に対応するHugging Faceのsize_categoriesの値を返す．
+func hfSizeCategory(n int) string {
+	switch {
+	case n < 1000:
+		return "n<1K"
+	case n < 10000:
+		return "1K<n<10K"
+	case n < 100000:
+		return "10K<n<100K"
+	case n < 1000000:
+		return "100K<n<1M"
+	default:
+		return "1M<n<10M"
+	}
+}
@@ -0,0 +1,173 @@
+package quiz_yaml_converter
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestNewHFRecord(t *testing.T) {
+	item := QuizItem{ID: "q1", Question: "日本の首都は？", Answer: "東京", Reading: "とうきょう", Genre: "地理", Difficulty: 2,
+		Tags: []string{"日本"}, Criteria: map[string][]string{"ok": {"東京都", "東京"}, "ng": {"京都"}}, License: "CC BY 4.0"}
+	expected := HFRecord{ID: "q1", Question: "日本の首都は？", Answer: "東京", AcceptedAnswers: []string{"東京", "東京都"},
+		Reading: "とうきょう", Genre: "地理", Difficulty: 2, Tags: []string{"日本"}, License: "CC BY 4.0"}
+	if got := NewHFRecord(item); !reflect.DeepEqual(got, expected) {
+		t.Errorf("NewHFRecord() = %+v, want %+v", got, expected)
+	}
+
+	empty := QuizItem{Question: "q", Answer: " "}
+	got := NewHFRecord(empty)
+	if got.ID != ContentID(empty) || got.AcceptedAnswers == nil || got.Tags == nil {
+		t.Errorf("NewHFRecord() = %+v, want ContentID and non-nil lists", got)
+	}
+}
+
+func TestWriteHFJSONL(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteHFJSONL(&buf, []QuizItem{{ID: "q1", Question: "<q>", Answer: "a"}}); err != nil {
+		t.Fatalf("WriteHFJSONL() error = %v", err)
+	}
+	expected := `{"id":"q1","question":"<q>","answer":"a","accepted_answers":["a"],"reading":"","genre":"","difficulty":0,"tags":[],"source":"","author":"","license":""}` + "\n"
+	if buf.String() != expected {
+		t.Errorf("WriteHFJSONL() = %q, want %q", buf.String(), expected)
+	}
+}
+
+func TestWriteHFDatasetCard(t *testing.T) {
+	splits := DatasetSplits{
+		Train: []QuizItem{{Question: "q1", Answer: "a1", License: "CC BY-SA 4.0"}, {Question: "q2", Answer: "a2", License: "CC BY-SA 4.0"}},
+		Test:  []QuizItem{{Question: "q3", Answer: "a3", License: "CC BY-SA 4.0"}},
+	}
+	var buf bytes.Buffer
+	if err := WriteHFDatasetCard(&buf, splits, HFDatasetOptions{Name: "My Quiz"}); err != nil {
+		t.Fatalf("WriteHFDatasetCard() error = %v", err)
+	}
+	card := buf.String()
+	front, body, ok := strings.Cut(strings.TrimPrefix(card, "---\n"), "\n---\n")
+	if !strings.HasPrefix(card, "---\n") || !ok {
+		t.Fatalf("card has no YAML front matter:\n%s", card)
+	}
+	var meta struct {
+		Language       []string `yaml:"language"`
+		License        string   `yaml:"license"`
+		SizeCategories []string `yaml:"size_categories"`
+		PrettyName     string   `yaml:"pretty_name"`
+		Configs        []struct {
+			DataFiles []map[string]string `yaml:"data_files"`
+		} `yaml:"configs"`
+		DatasetInfo struct {
+			Features []map[string]string `yaml:"features"`
+			Splits   []struct {
+				Name        string `yaml:"name"`
+				NumExamples int    `yaml:"num_examples"`
+			} `yaml:"splits"`
+		} `yaml:"dataset_info"`
+	}
+	if err := yaml.Unmarshal([]byte(front), &meta); err != nil {
+		t.Fatalf("failed to parse front matter: %v", err)
+	}
+	if !reflect.DeepEqual(meta.Language, []string{"ja"}) || meta.License != "cc-by-sa-4.0" || meta.PrettyName != "My Quiz" ||
+		!reflect.DeepEqual(meta.SizeCategories, []string{"n<1K"}) {
+		t.Errorf("metadata = %+v", meta)
+	}
+	expectedFiles := []map[string]string{{"split": "train", "path": "data/train.jsonl"}, {"split": "test", "path": "data/test.jsonl"}}
+	if len(meta.Configs) != 1 || !reflect.DeepEqual(meta.Configs[0].DataFiles, expectedFiles) {
+		t.Errorf("configs = %+v, want data_files %v", meta.Configs, expectedFiles)
+	}
+	if len(meta.DatasetInfo.Splits) != 2 || meta.DatasetInfo.Splits[0].NumExamples != 2 || meta.DatasetInfo.Splits[1].NumExamples != 1 {
+		t.Errorf("splits = %+v", meta.DatasetInfo.Splits)
+	}
+	if f := meta.DatasetInfo.Features[3]; f["name"] != "accepted_answers" || f["sequence"] != "string" {
+		t.Errorf("features[3] = %v, want accepted_answers sequence", f)
+	}
+	for _, s := range []string{"# My Quiz", "| train | 2 |", "| `accepted_answers` | list of string |"} {
+		if !strings.Contains(body, s) {
+			t.Errorf("card body does not contain %q", s)
+		}
+	}
+}
+
+func TestWriteHFDataset(t *testing.T) {
+	dir := t.TempDir()
+	splits := DatasetSplits{Train: []QuizItem{{ID: "q1", Question: "q1", Answer: "a1"}}, Validation: []QuizItem{{ID: "q2", Question: "q2", Answer: "a2"}}}
+
+	written, err := WriteHFDataset(dir, splits, HFDatasetOptions{})
+	if err != nil {
+		t.Fatalf("WriteHFDataset() error = %v", err)
+	}
+	expected := []string{filepath.Join(dir, "data", "train.jsonl"), filepath.Join(dir, "data", "validation.jsonl"), filepath.Join(dir, "README.md")}
+	if !reflect.DeepEqual(written, expected) {
+		t.Errorf("written = %v, want %v", written, expected)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "data", "validation.jsonl")); !strings.HasPrefix(string(data), `{"id":"q2"`) {
+		t.Errorf("validation.jsonl = %q", data)
+	}
+
+	// 既存のデータセットカードは残す
+	cardPath := filepath.Join(dir, "README.md")
+	if err := os.WriteFile(cardPath, []byte("edited"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	written, err = WriteHFDataset(dir, splits, HFDatasetOptions{})
+	if err != nil {
+		t.Fatalf("WriteHFDataset() error = %v", err)
+	}
+	if len(written) != 2 {
+		t.Errorf("written = %v, want only the JSON Lines files", written)
+	}
+	if data, _ := os.ReadFile(cardPath); string(data) != "edited" {
+		t.Errorf("README.md was overwritten: %q", data)
+	}
+
+	if _, err := WriteHFDataset(dir, splits, HFDatasetOptions{OverwriteCard: true}); err != nil {
+		t.Fatalf("WriteHFDataset() error = %v", err)
+	}
+	if data, _ := os.ReadFile(cardPath); string(data) == "edited" {
+		t.Errorf("README.md was not overwritten with OverwriteCard")
+	}
+}
+
+func TestHFLicense(t *testing.T) {
+	tests := []struct {
+		licenses []string
+		expected string
+	}{
+		{[]string{"CC BY 4.0", "CC BY 4.0"}, "cc-by-4.0"},
+		{[]string{"MIT"}, "mit"},
+		{[]string{"CC BY 4.0", "CC0 1.0"}, "other"},
+		{[]string{"CC BY 4.0", ""}, ""},
+		{nil, ""},
+	}
+	for _, tt := range tests {
+		var items []QuizItem
+		for _, l := range tt.licenses {
+			items = append(items, QuizItem{License: l})
+		}
+		if got := hfLicense(items); got != tt.expected {
+			t.Errorf("hfLicense(%v) = %q, want %q", tt.licenses, got, tt.expected)
+		}
+	}
+}
+
+func TestHFSizeCategory(t *testing.T) {
+	tests := []struct {
+		n        int
+		expected string
+	}{
+		{0, "n<1K"},
+		{999, "n<1K"},
+		{1000, "1K<n<10K"},
+		{250000, "100K<n<1M"},
+		{5000000, "1M<n<10M"},
+	}
+	for _, tt := range tests {
+		if got := hfSizeCategory(tt.n); got != tt.expected {
+			t.Errorf("hfSizeCategory(%d) = %q, want %q", tt.n, got, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+// ヒントや文字数の表示に使う，答えの文字数の数え方と伏せ字の処理です．
+package quiz_yaml_converter
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// 拗音などに使う小書きの仮名．直前の仮名と合わせて1モーラとなる．
+// 促音の「っ」「ッ」は1モーラとして数えるため含めない．
+const smallKana = "ぁぃぅぇぉゃゅょゎゕゖァィゥェォャュョヮヵヶㇰㇱㇲㇳㇴㇵㇶㇷㇸㇹㇺㇻㇼㇽㇾㇿ"
+
+// MoraCount は仮名で書かれた読みのモーラ数（拍数）を返す．
+// 拗音（「きゃ」など）は1モーラ，促音（「っ」）・撥音（「ん」）・長音符（「ー」）・踊り字（「ゝ」）はそれぞれ1モーラとして数える．
+// 仮名と長音符以外の文字（漢字，英字，記号など）は数えない．
+func MoraCount(s string) int {
+	count := 0
+	for _, r := range s {
+		if strings.ContainsRune(smallKana, r) {
+			continue
+		}
+		if r == 'ー' || unicode.In(r, unicode.Hiragana, unicode.Katakana) {
+			count++
+		}
+	}
+	return count
+}
+
+// 伏せ字に使う文字
+const maskRune = '〇'
+
+// MaskAnswer は答えの各文字を伏せ字（〇）に置き換えたヒントを返す．
+// keepに指定した文字列に一致する部分はそのまま残すため，
+// MaskAnswer("国立西洋美術館", "美術館")は"〇〇〇〇美術館"となる．
+// 空白と中黒（・）は語の区切りがわかるよう伏せずに残す．
+func MaskAnswer(answer string, keep ...string) string {
+	var b strings.Builder
+	for i := 0; i < len(answer); {
+		kept := ""
+		for _, k := range keep {
+			if k != "" && strings.HasPrefix(answer[i:], k) && len(k) > len(kept) {
+				kept = k
+			}
+		}
+		if kept != "" {
+			b.WriteString(kept)
+			i += len(kept)
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(answer[i:])
+		if unicode.IsSpace(r) || r == '・' {
+			b.WriteRune(r)
+		} else {
+			b.WriteRune(maskRune)
+		}
+		i += size
+	}
+	return b.String()
+}
@@ -0,0 +1,67 @@
+package quiz_yaml_converter
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestMoraCount(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int
+	}{
+		{"ふじさん", 4},
+		{"きゃっと", 3},
+		{"トーキョー", 4},
+		{"チェッカー", 4},
+		{"いすゞ", 3},
+		{"ジョン・アタナソフ", 7},
+		{"東京タワー", 3},
+		{"ABC", 0},
+		{"", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			if result := MoraCount(tt.input); result != tt.expected {
+				t.Errorf("MoraCount(%q) = %d, want %d", tt.input, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestMaskAnswer(t *testing.T) {
+	tests := []struct {
+		name     string
+		answer   string
+		keep     []string
+		expected string
+	}{
+		{"all", "富士山", nil, "〇〇〇"},
+		{"keep suffix", "国立西洋美術館", []string{"美術館"}, "〇〇〇〇美術館"},
+		{"keep every occurrence", "ドイツ・ドイツ", []string{"ドイ"}, "ドイ〇・ドイ〇"},
+		{"longest keep", "東京都庁", []string{"東", "東京"}, "東京〇〇"},
+		{"separators", "John von Neumann", nil, "〇〇〇〇 〇〇〇 〇〇〇〇〇〇〇"},
+		{"middle dot", "ジョン・アタナソフ", []string{""}, "〇〇〇・〇〇〇〇〇"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := MaskAnswer(tt.answer, tt.keep...); result != tt.expected {
+				t.Errorf("MaskAnswer(%q, %q) = %q, want %q", tt.answer, tt.keep, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestWriteTemplate_HintFuncs(t *testing.T) {
+	data := []QuizItem{{Question: "q1", Answer: "国立西洋美術館", Spell: "こくりつせいようびじゅつかん"}}
+	opts := ConvertOptions{TemplateText: `{{range .Items}}{{maskAnswer .Answer "美術館"}}（{{runeCount .Answer}}文字，{{moraCount .Spell}}拍）{{end}}`}
+	var buf bytes.Buffer
+	if err := WriteTemplate(&buf, data, "hints.tmpl", opts); err != nil {
+		t.Fatalf("WriteTemplate() error = %v", err)
+	}
+	if expected := "〇〇〇〇美術館（7文字，13拍）"; buf.String() != expected {
+		t.Errorf("WriteTemplate() = %q, want %q", buf.String(), expected)
+	}
+}
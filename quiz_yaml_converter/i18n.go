@@ -0,0 +1,137 @@
+package quiz_yaml_converter
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Language はメッセージを表示する言語．
+type Language string
+
+// 対応している言語
+const (
+	LanguageJapanese Language = "ja" // 日本語（既定）
+	LanguageEnglish  Language = "en" // 英語
+)
+
+// ParseLanguage は言語の指定を解析する．
+// "en_US.UTF-8"や"ja-JP"のようなロケール表記も受け付け，空の場合は日本語とする．
+func ParseLanguage(name string) (Language, error) {
+	lang := strings.ToLower(name)
+	if i := strings.IndexAny(lang, "_-."); i >= 0 {
+		lang = lang[:i]
+	}
+	switch lang {
+	case "", "ja":
+		return LanguageJapanese, nil
+	case "en":
+		return LanguageEnglish, nil
+	default:
+		return "", fmt.Errorf("unsupported language: %q (available: ja, en)", name)
+	}
+}
+
+// messageCatalog は日本語のメッセージ（書式文字列）から各言語への翻訳．
+var messageCatalog = map[Language]map[string]string{
+	LanguageEnglish: {
+		"問題 %d: %s":                          "item %d: %s",
+		"ファイルが存在しません: %s":                    "file does not exist: %s",
+		"YAMLファイルの読み込みエラー: %v":               "failed to load YAML file: %v",
+		"YAMLファイルにクイズデータが含まれていません":           "YAML file contains no quiz items",
+		"ファイルの先頭にBOMがあります（取り除いて読み込みました）: %s": "file starts with a BOM (it was removed before parsing): %s",
+		"YAMLデータの先頭にBOMがあります（取り除いて読み込みました）":  "YAML data starts with a BOM (it was removed before parsing)",
+		"問題文 (question) が空です":                "question is empty",
+		"答え (answer) が空です":                   "answer is empty",
+		"出典 (source または reference) が空です":     "source is empty (set source or reference)",
+		"%s が空です":                                    "%s is empty",
+		"spellの言語コードが空です":                            "spell has an empty language code",
+		"不正なcriteriaキー: '%s' (使用可能: ok, ng, repeat)": "invalid criteria key: '%s' (available: ok, ng, repeat)",
+		"問題文 (question) の区切り記号「%s」の前後が空です":           "question has an empty segment around the marker \"%s\"",
+		"segmentsをつなげた文字列が問題文 (question) と一致しません":    "joined segments do not match the question",
+		"答え (answer) の括弧の対応が取れていません":                 "answer has unbalanced parentheses",
+		"答え (answer) に括弧書きが複数あります":                   "answer has more than one parenthesized part",
+		"答え (answer) の括弧書きが入れ子になっています":               "answer has nested parentheses",
+		"答え (answer) の括弧書きが末尾にありません":                 "answer has a parenthesized part that is not at the end",
+		"答え (answer) 全体が括弧で囲まれています":                  "the whole answer is enclosed in parentheses",
+		"答え (answer) の括弧の中が空です":                      "answer has empty parentheses",
+		"%s の改行コード（CRLFとLF）が混在しています":                 "%s mixes CRLF and LF line endings",
+		"%s の行末に空白があります":                             "%s has trailing whitespace",
+		"%s の%d文字目に禁止語「%s」が含まれています":                  "%s contains an NG word at character %d: \"%s\"",
+		"答え「%s」の問題が%d問あります（問題 %s．上限は%d問）":            "the answer \"%s\" is used by %d items (items %s; the limit is %d)",
+		"%d行目: %s": "line %d: %s",
+		"metadataを独立したドキュメントに移しました":                           "moved metadata to its own document",
+		"ジャンル「%s」の%d問を問題のリストに移し，genreを設定しました":                 "set the genre \"%s\" on %d items and moved them into the item list",
+		"%s を文字列からリストに変換しました":                                 "converted %s from a string to a list",
+		"metadataのversionを%dにしました":                            "set the metadata version to %d",
+		"%s の語の間に全角スペースがあります":                                 "%s has a full-width space between words",
+		"%s にゼロ幅文字 (U+%04X) が含まれています":                         "%s contains a zero-width character (U+%04X)",
+		"%s に「%c」が含まれています（「%c」に統一してください）":                     "%s contains \"%c\" (use \"%c\" instead)",
+		"問題文 (question) の末尾が指定された形式（%s）と一致しません":               "question does not end with any of the required endings (%s)",
+		"問題文 (question) に答え (answer) と別解が含まれていないため，穴埋めにできません": "question contains neither the answer nor its alternatives, so it cannot be turned into a cloze",
+		"読み (reading) が形態素解析で求めた読み「%s」と一致しません":                "reading differs from the reading \"%s\" produced by morphological analysis",
+		"%s が答え (answer) と同じです":                               "%s is the same as the answer",
+		"答え (answer) が %s にも含まれています":                          "answer also appears in %s",
+		"選択肢 (choices) に答え (answer) が含まれていません":                "choices do not include the answer",
+		"「%s」がcriteria.okとcriteria.ngの両方に含まれています":             "\"%s\" appears in both criteria.ok and criteria.ng",
+		"問題を読み込めません: %v":                                      "cannot read the quiz item: %v",
+		"警告: ":                                                "warning: ",
+	},
+}
+
+// Translate はメッセージの書式文字列を指定した言語に翻訳する．
+// 翻訳がない場合や日本語の場合はそのまま返す．
+func Translate(lang Language, format string) string {
+	if translated, ok := messageCatalog[lang][format]; ok {
+		return translated
+	}
+	return format
+}
+
+// newValidationError はメッセージの書式と引数を保持したValidationErrorを作成する．
+// Messageには日本語のメッセージが入り，LocalizedMessageで他の言語に翻訳できる．
+func newValidationError(index int, field, rule string, err error, format string, args ...any) ValidationError {
+	return ValidationError{
+		Index:   index,
+		Field:   field,
+		Rule:    rule,
+		Message: fmt.Sprintf(format, args...),
+		Err:     err,
+		format:  format,
+		args:    args,
+	}
+}
+
+// LocalizedMessage は指定した言語のエラーメッセージを返す．
+func (e *ValidationError) LocalizedMessage(lang Language) string {
+	if e.format == "" {
+		return Translate(lang, e.Message)
+	}
+	return fmt.Sprintf(Translate(lang, e.format), e.args...)
+}
+
+// LocalizedError は指定した言語でErrorと同じ形式のメッセージを返す．
+func (e *ValidationError) LocalizedError(lang Language) string {
+	if e.Index > 0 {
+		return fmt.Sprintf(Translate(lang, "問題 %d: %s"), e.Index, e.LocalizedMessage(lang))
+	}
+	return e.LocalizedMessage(lang)
+}
+
+// LocalizedErrors は指定した言語のエラーメッセージのリストを返す．
+// 日本語の場合はErrorsと同じ内容となる．
+func (r ValidationResult) LocalizedErrors(lang Language) []string {
+	messages := make([]string, 0, len(r.ValidationErrors))
+	for i := range r.ValidationErrors {
+		messages = append(messages, r.ValidationErrors[i].LocalizedError(lang))
+	}
+	return messages
+}
+
+// LocalizedWarnings は指定した言語の警告メッセージのリストを返す．
+func (r ValidationResult) LocalizedWarnings(lang Language) []string {
+	messages := make([]string, 0, len(r.Warnings))
+	for i := range r.Warnings {
+		messages = append(messages, r.Warnings[i].LocalizedError(lang))
+	}
+	return messages
+}
@@ -0,0 +1,73 @@
+package quiz_yaml_converter
+
+import (
+	"reflect"
+	"regexp"
+	"testing"
+)
+
+func TestParseLanguage(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected Language
+	}{
+		{"", LanguageJapanese},
+		{"ja", LanguageJapanese},
+		{"ja_JP.UTF-8", LanguageJapanese},
+		{"en", LanguageEnglish},
+		{"EN", LanguageEnglish},
+		{"en-US", LanguageEnglish},
+		{"en_US.UTF-8", LanguageEnglish},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ParseLanguage(tt.name)
+			if err != nil {
+				t.Fatalf("ParseLanguage(%q) error = %v", tt.name, err)
+			}
+			if result != tt.expected {
+				t.Errorf("ParseLanguage(%q) = %q, want %q", tt.name, result, tt.expected)
+			}
+		})
+	}
+
+	if _, err := ParseLanguage("fr"); err == nil {
+		t.Error("ParseLanguage(\"fr\") expected error")
+	}
+}
+
+func TestValidationResult_LocalizedErrors(t *testing.T) {
+	data := []QuizItem{
+		{Question: "q1", Answer: ""},
+		{Question: "q2", Answer: "a2", Criteria: map[string][]string{"ok": {""}}},
+	}
+	result := ValidateItems(data)
+
+	if !reflect.DeepEqual(result.LocalizedErrors(LanguageJapanese), result.Errors) {
+		t.Errorf("LocalizedErrors(ja) = %q, want %q", result.LocalizedErrors(LanguageJapanese), result.Errors)
+	}
+
+	expected := []string{"item 1: answer is empty", "item 2: criteria.ok[0] is empty"}
+	if got := result.LocalizedErrors(LanguageEnglish); !reflect.DeepEqual(got, expected) {
+		t.Errorf("LocalizedErrors(en) = %q, want %q", got, expected)
+	}
+}
+
+func TestValidationError_LocalizedMessageWithoutFormat(t *testing.T) {
+	e := ValidationError{Index: 1, Field: "answer", Message: "答え (answer) が空です"}
+	if got := e.LocalizedError(LanguageEnglish); got != "item 1: answer is empty" {
+		t.Errorf("LocalizedError(en) = %q", got)
+	}
+}
+
+// 翻訳の書式指定子が元のメッセージと一致していることを確認する
+func TestMessageCatalog_Verbs(t *testing.T) {
+	verbs := regexp.MustCompile(`%[a-z]`)
+	for lang, messages := range messageCatalog {
+		for format, translated := range messages {
+			if !reflect.DeepEqual(verbs.FindAllString(format, -1), verbs.FindAllString(translated, -1)) {
+				t.Errorf("%s: verbs of %q do not match %q", lang, translated, format)
+			}
+		}
+	}
+}
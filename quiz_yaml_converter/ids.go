@@ -0,0 +1,79 @@
+package quiz_yaml_converter
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// ContentIDPrefix はContentIDで生成するIDの接頭辞．
+const ContentIDPrefix = "q-"
+
+// contentIDLength はContentIDで使うハッシュ値の桁数（16進数）．
+const contentIDLength = 12
+
+// normalizeForID はIDの計算に使うために文字列を正規化する．
+// NFKC正規化（全角英数字・半角カナの統一）を行い，区切り記号と空白を取り除く．
+func normalizeForID(s string) string {
+	s = norm.NFKC.String(strings.ReplaceAll(s, SegmentMarker, ""))
+	return strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) {
+			return -1
+		}
+		return r
+	}, s)
+}
+
+// ContentID は問題文と答えから決まる問題IDを返す．
+// 正規化した問題文と答えのハッシュ値から作るため，同じ内容の問題には
+// ファイルや並び順によらず同じIDが割り当てられる．
+func ContentID(item QuizItem) string {
+	sum := sha256.Sum256([]byte(normalizeForID(item.Question) + "\x00" + normalizeForID(item.Answer)))
+	return ContentIDPrefix + hex.EncodeToString(sum[:])[:contentIDLength]
+}
+
+// AssignIDs はIDが未設定の問題にContentIDを割り当て，割り当てた数を返す．
+// overwriteがtrueの場合は既にIDがある問題もContentIDで置き換える．
+func AssignIDs(items []QuizItem, overwrite bool) int {
+	assigned := 0
+	for i := range items {
+		if items[i].ID != "" && !overwrite {
+			continue
+		}
+		if id := ContentID(items[i]); items[i].ID != id {
+			items[i].ID = id
+			assigned++
+		}
+	}
+	return assigned
+}
+
+// DuplicateContentIDs は同じContentIDを持つ（内容が同じ）問題の番号をIDごとに返す．
+// 番号は1から始まる．重複がない場合は空のmapを返す．
+func DuplicateContentIDs(items []QuizItem) map[string][]int {
+	indices := map[string][]int{}
+	for i, item := range items {
+		id := ContentID(item)
+		indices[id] = append(indices[id], i+1)
+	}
+	for id, idx := range indices {
+		if len(idx) < 2 {
+			delete(indices, id)
+		}
+	}
+	return indices
+}
+
+// withAssignedIDs はopts.AssignIDsが指定されている場合，IDが未設定の問題に
+// ContentIDを割り当てたコピーを返す．元のスライスは変更しない．
+func withAssignedIDs(data []QuizItem, opts ConvertOptions) []QuizItem {
+	if !opts.AssignIDs {
+		return data
+	}
+	items := append([]QuizItem(nil), data...)
+	AssignIDs(items, false)
+	return items
+}
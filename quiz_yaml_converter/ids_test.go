@@ -0,0 +1,84 @@
+package quiz_yaml_converter
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestContentID(t *testing.T) {
+	base := QuizItem{Question: "日本の首都は？", Answer: "東京"}
+	id := ContentID(base)
+
+	if !strings.HasPrefix(id, ContentIDPrefix) || len(id) != len(ContentIDPrefix)+contentIDLength {
+		t.Fatalf("ContentID() = %q, unexpected format", id)
+	}
+
+	tests := []struct {
+		name string
+		item QuizItem
+		same bool
+	}{
+		{"surrounding spaces", QuizItem{Question: " 日本の首都は？ ", Answer: "東京\n"}, true},
+		{"full-width question mark", QuizItem{Question: "日本の首都は?", Answer: "東京"}, true},
+		{"segment marker", QuizItem{Question: "日本の／首都は？", Answer: "東京"}, true},
+		{"other fields ignored", QuizItem{ID: "x", Question: "日本の首都は？", Answer: "東京", Comments: []string{"c"}}, true},
+		{"different answer", QuizItem{Question: "日本の首都は？", Answer: "京都"}, false},
+		{"different question", QuizItem{Question: "日本の首都はどこ？", Answer: "東京"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ContentID(tt.item); (got == id) != tt.same {
+				t.Errorf("ContentID() = %q, base = %q, want same = %v", got, id, tt.same)
+			}
+		})
+	}
+}
+
+func TestAssignIDs(t *testing.T) {
+	items := []QuizItem{
+		{ID: "keep", Question: "q1", Answer: "a1"},
+		{Question: "q2", Answer: "a2"},
+	}
+
+	if n := AssignIDs(items, false); n != 1 {
+		t.Errorf("AssignIDs() = %d, want 1", n)
+	}
+	if items[0].ID != "keep" || items[1].ID != ContentID(items[1]) {
+		t.Errorf("AssignIDs() items = %+v", items)
+	}
+	if n := AssignIDs(items, true); n != 1 || items[0].ID != ContentID(items[0]) {
+		t.Errorf("AssignIDs(overwrite) = %d, items = %+v", n, items)
+	}
+	if n := AssignIDs(items, true); n != 0 {
+		t.Errorf("AssignIDs() on assigned items = %d, want 0", n)
+	}
+}
+
+func TestDuplicateContentIDs(t *testing.T) {
+	items := []QuizItem{
+		{Question: "q1", Answer: "a1"},
+		{Question: "q2", Answer: "a2"},
+		{Question: "q1 ", Answer: "a1"},
+	}
+	expected := map[string][]int{ContentID(items[0]): {1, 3}}
+	if result := DuplicateContentIDs(items); !reflect.DeepEqual(result, expected) {
+		t.Errorf("DuplicateContentIDs() = %v, want %v", result, expected)
+	}
+}
+
+func TestWriteCSV_AssignIDs(t *testing.T) {
+	data := []QuizItem{{ID: "q1", Question: "問題1", Answer: "答え1"}, {Question: "問題2", Answer: "答え2"}}
+
+	var b strings.Builder
+	if err := WriteCSV(&b, data, ConvertOptions{AssignIDs: true, CSV: CSVOptions{NoHeader: true}}); err != nil {
+		t.Fatalf("WriteCSV() error = %v", err)
+	}
+	expected := "q1,問題1,答え1,,\n" + ContentID(data[1]) + ",問題2,答え2,,\n"
+	if b.String() != expected {
+		t.Errorf("WriteCSV() = %q, want %q", b.String(), expected)
+	}
+	if data[1].ID != "" {
+		t.Error("WriteCSV() should not modify input items")
+	}
+}
@@ -0,0 +1,52 @@
+package quiz_yaml_converter
+
+import (
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+// HasCriteria は正誤判定（ok, ng, repeatのいずれか）が1つ以上あるかを返す．
+// criteriaのキーだけがあり，リストが空の場合はfalseとなる．
+// テンプレートでは{{if .HasCriteria}}のように使う．
+func (q QuizItem) HasCriteria() bool {
+	for _, values := range q.Criteria {
+		if len(values) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// OKAnswers は正解として扱う別解（criteria.ok）を返す．
+func (q QuizItem) OKAnswers() []string {
+	return q.Criteria["ok"]
+}
+
+// NGAnswers は誤答として明示的に判定する答え（criteria.ng）を返す．
+func (q QuizItem) NGAnswers() []string {
+	return q.Criteria["ng"]
+}
+
+// RepeatAnswers はもう一度回答を求める答え（criteria.repeat）を返す．
+func (q QuizItem) RepeatAnswers() []string {
+	return q.Criteria["repeat"]
+}
+
+// AllAcceptedAnswers は答えと別解（criteria.ok）を，重複と空の文字列を除いてこの順に返す．
+// 正解として扱う文字列の一覧が必要な場合（採点など）に使う．
+func (q QuizItem) AllAcceptedAnswers() []string {
+	var answers []string
+	for _, answer := range append([]string{q.Answer}, q.OKAnswers()...) {
+		answer = strings.TrimSpace(answer)
+		if answer != "" && !slices.Contains(answers, answer) {
+			answers = append(answers, answer)
+		}
+	}
+	return answers
+}
+
+// QuestionRuneCount は問題文の前後の空白を除いた文字数を返す．
+func (q QuizItem) QuestionRuneCount() int {
+	return utf8.RuneCountInString(strings.TrimSpace(q.Question))
+}
@@ -0,0 +1,88 @@
+package quiz_yaml_converter
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestQuizItem_HasCriteria(t *testing.T) {
+	tests := []struct {
+		name     string
+		criteria map[string][]string
+		expected bool
+	}{
+		{"nil", nil, false},
+		{"empty lists", map[string][]string{"ok": {}, "ng": nil}, false},
+		{"ok", map[string][]string{"ok": {"別解"}}, true},
+		{"repeat", map[string][]string{"repeat": {"もう一度"}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := (QuizItem{Criteria: tt.criteria}).HasCriteria(); got != tt.expected {
+				t.Errorf("HasCriteria() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestQuizItem_Answers(t *testing.T) {
+	item := QuizItem{
+		Answer: "国際連合",
+		Criteria: map[string][]string{
+			"ok":     {"国連", "国際連合", " UN ", ""},
+			"ng":     {"国際連盟"},
+			"repeat": {"国際"},
+		},
+	}
+	if got := item.OKAnswers(); !reflect.DeepEqual(got, []string{"国連", "国際連合", " UN ", ""}) {
+		t.Errorf("OKAnswers() = %q", got)
+	}
+	if got := item.NGAnswers(); !reflect.DeepEqual(got, []string{"国際連盟"}) {
+		t.Errorf("NGAnswers() = %q", got)
+	}
+	if got := item.RepeatAnswers(); !reflect.DeepEqual(got, []string{"国際"}) {
+		t.Errorf("RepeatAnswers() = %q", got)
+	}
+	if got := item.AllAcceptedAnswers(); !reflect.DeepEqual(got, []string{"国際連合", "国連", "UN"}) {
+		t.Errorf("AllAcceptedAnswers() = %q", got)
+	}
+	if got := (QuizItem{}).AllAcceptedAnswers(); got != nil {
+		t.Errorf("AllAcceptedAnswers() of empty item = %q, want nil", got)
+	}
+}
+
+func TestQuizItem_QuestionRuneCount(t *testing.T) {
+	tests := []struct {
+		question string
+		expected int
+	}{
+		{"", 0},
+		{"ドイツの首都は？", 8},
+		{"ドイツの首都は？\n", 8},
+		{"What?", 5},
+	}
+	for _, tt := range tests {
+		if got := (QuizItem{Question: tt.question}).QuestionRuneCount(); got != tt.expected {
+			t.Errorf("QuestionRuneCount(%q) = %d, want %d", tt.question, got, tt.expected)
+		}
+	}
+}
+
+func TestQuizItem_MethodsInTemplate(t *testing.T) {
+	data := []QuizItem{{Question: "ドイツの首都は？", Answer: "ベルリン", Criteria: map[string][]string{"ok": {"Berlin"}}}}
+	templateFile := filepath.Join(t.TempDir(), "template.txt")
+	tmpl := `{{range .Items}}{{.QuestionRuneCount}}:{{if .HasCriteria}}{{range .AllAcceptedAnswers}}[{{.}}]{{end}}{{end}}{{end}}`
+	if err := os.WriteFile(templateFile, []byte(tmpl), 0644); err != nil {
+		t.Fatalf("Failed to create template file: %v", err)
+	}
+	var buf strings.Builder
+	if err := WriteTemplate(&buf, data, templateFile, ConvertOptions{}); err != nil {
+		t.Fatalf("WriteTemplate() error = %v", err)
+	}
+	if got := buf.String(); got != "8:[ベルリン][Berlin]" {
+		t.Errorf("WriteTemplate() = %q", got)
+	}
+}
@@ -0,0 +1,62 @@
+// 入力された解答を，答え・別解・読み・正誤判定（criteria）と照らし合わせて判定する処理です．
+package quiz_yaml_converter
+
+import (
+	"slices"
+	"strings"
+)
+
+// Judgement は解答の判定結果．
+type Judgement int
+
+const (
+	JudgeIncorrect Judgement = iota // 誤答
+	JudgeCorrect                    // 正解
+	JudgeRepeat                     // もう一度（criteria.repeat）
+)
+
+// String は判定結果を"correct"，"incorrect"，"repeat"のいずれかで返す．
+func (j Judgement) String() string {
+	switch j {
+	case JudgeCorrect:
+		return "correct"
+	case JudgeRepeat:
+		return "repeat"
+	}
+	return "incorrect"
+}
+
+// MarshalText はJSONなどで判定結果をStringの文字列として書き出す．
+func (j Judgement) MarshalText() ([]byte, error) {
+	return []byte(j.String()), nil
+}
+
+// normalizeAnswer は解答を比較できるよう，NFKC正規化・空白の除去・カタカナのひらがなへの変換・小文字化を行う．
+func normalizeAnswer(s string) string {
+	return strings.ToLower(NormalizeReading(normalizeForID(s)))
+}
+
+// JudgeAnswer は入力された解答を判定する．
+// 答え，答えの括弧書きの別解（SplitAnswer），読み，criteria.okのいずれかと一致すれば正解，
+// criteria.repeatと一致すればもう一度，それ以外は誤答とする．
+// 比較の前に，全角・半角，空白，カタカナとひらがな，大文字と小文字の違いは無視する．
+func JudgeAnswer(item QuizItem, answer string) Judgement {
+	input := normalizeAnswer(answer)
+	if input == "" {
+		return JudgeIncorrect
+	}
+	matches := func(candidates []string) bool {
+		return slices.ContainsFunc(candidates, func(c string) bool { return normalizeAnswer(c) == input })
+	}
+	primary, alternates := SplitAnswer(item.Answer)
+	accepted := append([]string{item.Answer, primary, item.Reading}, alternates...)
+	switch {
+	case matches(append(accepted, item.OKAnswers()...)):
+		return JudgeCorrect
+	case matches(item.NGAnswers()):
+		return JudgeIncorrect
+	case matches(item.RepeatAnswers()):
+		return JudgeRepeat
+	}
+	return JudgeIncorrect
+}
@@ -0,0 +1,54 @@
+package quiz_yaml_converter
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestJudgeAnswer(t *testing.T) {
+	item := QuizItem{
+		Question: "ドイツのドレスデンにある美術館は？",
+		Answer:   "アルテ・マイスター絵画館（古典絵画館）",
+		Reading:  "あるて・まいすたーかいがかん",
+		Criteria: map[string][]string{
+			"ok":     {"ツヴィンガー宮殿美術館"},
+			"ng":     {"ノイエ・マイスター"},
+			"repeat": {"マイスター"},
+		},
+	}
+	tests := []struct {
+		answer   string
+		expected Judgement
+	}{
+		{"アルテ・マイスター絵画館（古典絵画館）", JudgeCorrect},
+		{"アルテ・マイスター絵画館", JudgeCorrect},
+		{"古典絵画館", JudgeCorrect},
+		{"あるて・まいすたーかいがかん", JudgeCorrect},
+		{"アルテ・マイスター 絵画館", JudgeCorrect},
+		{"ツヴィンガー宮殿美術館", JudgeCorrect},
+		{"マイスター", JudgeRepeat},
+		{"ノイエ・マイスター", JudgeIncorrect},
+		{"ルーヴル美術館", JudgeIncorrect},
+		{"", JudgeIncorrect},
+		{"　", JudgeIncorrect},
+	}
+	for _, tt := range tests {
+		if got := JudgeAnswer(item, tt.answer); got != tt.expected {
+			t.Errorf("JudgeAnswer(%q) = %v, want %v", tt.answer, got, tt.expected)
+		}
+	}
+
+	if got := JudgeAnswer(QuizItem{Answer: "ＤＮＡ"}, "dna"); got != JudgeCorrect {
+		t.Errorf("JudgeAnswer() with width and case differences = %v, want correct", got)
+	}
+}
+
+func TestJudgementMarshalText(t *testing.T) {
+	b, err := json.Marshal([]Judgement{JudgeCorrect, JudgeIncorrect, JudgeRepeat})
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	if expected := `["correct","incorrect","repeat"]`; string(b) != expected {
+		t.Errorf("json.Marshal() = %s, want %s", b, expected)
+	}
+}
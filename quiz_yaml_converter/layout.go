@@ -0,0 +1,57 @@
+package quiz_yaml_converter
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"text/template"
+
+	"github.com/m-uesaka/quiz-yaml-go/templates"
+)
+
+// builtinLayouts は-layoutで名前を指定できる組み込みのレイアウト．
+// 組み込みのHTML・Markdownテンプレートはブロック（title, style, header, item, footer）で
+// 構成されているため，そのままレイアウトとして使用できる．
+var builtinLayouts = map[string]string{
+	"html":     templates.HTML,
+	"markdown": templates.Markdown,
+	"md":       templates.Markdown,
+}
+
+// readLayout はレイアウトの内容を返す．組み込みのレイアウト名でない場合はファイルのパスとして読み込む．
+func readLayout(name string) (string, error) {
+	if content, ok := builtinLayouts[name]; ok {
+		return content, nil
+	}
+	content, err := os.ReadFile(name)
+	if err != nil {
+		return "", fmt.Errorf("failed to read layout file: %w", err)
+	}
+	return string(content), nil
+}
+
+// parseWithLayout はレイアウトを基にテンプレートを解析する．
+// テンプレートの{{define "ブロック名"}}で，レイアウトの{{block "ブロック名"}}の内容を置き換える．
+// 実行されるのはレイアウトで，テンプレートのdefine以外の部分は出力されない．
+// opts.TemplateDelimsはテンプレートとレイアウトファイルに適用し，組み込みのレイアウトは{{ }}のまま解析する．
+// opts.SafeTemplatesの場合も，組み込みのレイアウトはすべての関数を使い，テンプレートの関数だけを制限する．
+func parseWithLayout(templateFilePath, content string, opts ConvertOptions) (*template.Template, error) {
+	layout, err := readLayout(opts.Layout)
+	if err != nil {
+		return nil, err
+	}
+	left, right := opts.TemplateDelims[0], opts.TemplateDelims[1]
+	tmpl := template.New("quiz").Funcs(templateFuncs(opts))
+	if _, builtin := builtinLayouts[opts.Layout]; !builtin {
+		tmpl.Delims(left, right).Funcs(userTemplateFuncs(opts))
+	} else if err := checkSafeTemplate(templateFilePath, content, opts); err != nil {
+		return nil, err
+	}
+	if _, err := tmpl.Parse(layout); err != nil {
+		return nil, fmt.Errorf("%w: layout %s: %w", ErrTemplateParse, opts.Layout, err)
+	}
+	if _, err := tmpl.New(filepath.Base(templateFilePath)).Delims(left, right).Parse(content); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrTemplateParse, err)
+	}
+	return tmpl, nil
+}
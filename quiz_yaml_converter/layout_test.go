@@ -0,0 +1,129 @@
+package quiz_yaml_converter
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWriteTemplate_Layout(t *testing.T) {
+	dir := t.TempDir()
+	items := []QuizItem{
+		{ID: "q-1", Question: "日本の／首都は？", Answer: "東京"},
+		{Question: "富士山の高さは？", Answer: "3776m"},
+	}
+
+	baseLayout := filepath.Join(dir, "base.txt")
+	if err := os.WriteFile(baseLayout, []byte(`[{{block "header" .}}default header{{end}}]
+{{range $i, $_ := .Items}}{{block "item" ($.Item $i)}}{{.Number}}:{{.Question}}
+{{end}}{{end}}[{{block "footer" .}}{{len .Items}} items{{end}}]`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	bracketLayout := filepath.Join(dir, "bracket.txt")
+	if err := os.WriteFile(bracketLayout, []byte(`{% raw %}[[range $i, $_ := .Items]][[block "item" ($.Item $i)]][[.Question]][[end]][[end]]`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	brackets := [2]string{"[[", "]]"}
+
+	tests := []struct {
+		name     string
+		layout   string
+		delims   [2]string
+		template string
+		want     []string
+		notWant  []string
+	}{
+		{
+			name:     "file layout with overridden item",
+			layout:   baseLayout,
+			template: `{{define "item"}}Q{{.Number}} {{plainQuestion .}}={{.Answer}}{{with .ID}}#{{.}}{{end}};{{end}}`,
+			want:     []string{"[default header]", "Q1 日本の首都は？=東京#q-1;", "Q2 富士山の高さは？=3776m;", "[2 items]"},
+		},
+		{
+			name:     "file layout with overridden header and footer",
+			layout:   baseLayout,
+			template: `{{define "header"}}例会{{end}}{{define "footer"}}end{{end}}ignored top-level text`,
+			want:     []string{"[例会]", "1:日本の／首都は？", "[end]"},
+			notWant:  []string{"ignored"},
+		},
+		{
+			name:     "builtin html layout",
+			layout:   "html",
+			template: `{{define "title"}}第1回{{end}}{{define "item"}}<p>{{.Number}}|{{range segments .}}[{{.}}]{{end}}|{{formatItemCriteria .}}</p>{{end}}`,
+			want:     []string{"<title>第1回</title>", "<p>1|[日本の][首都は？]|</p>", "総問題数"},
+			notWant:  []string{`class="quiz-item"`},
+		},
+		{
+			name:     "builtin markdown layout",
+			layout:   "markdown",
+			template: `{{define "item"}}- {{.Question}}{{end}}{{define "footer"}}(fin){{end}}`,
+			want:     []string{"# Quiz Questions", "- 日本の／首都は？", "(fin)"},
+		},
+		{
+			name:     "builtin layout with custom delimiters",
+			layout:   "markdown",
+			delims:   brackets,
+			template: `[[define "item"]]- [[.Question]] {{ answer }}[[end]]`,
+			want:     []string{"# Quiz Questions", "- 日本の／首都は？ {{ answer }}"},
+		},
+		{
+			name:     "file layout with custom delimiters",
+			layout:   bracketLayout,
+			delims:   brackets,
+			template: `[[define "item"]]{{ [[.Answer]] }};[[end]]`,
+			want:     []string{"{% raw %}{{ 東京 }};{{ 3776m }};"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmplPath := filepath.Join(dir, "child.tmpl")
+			if err := os.WriteFile(tmplPath, []byte(tt.template), 0644); err != nil {
+				t.Fatal(err)
+			}
+			var buf bytes.Buffer
+			if err := WriteTemplate(&buf, items, tmplPath, ConvertOptions{Layout: tt.layout, TemplateDelims: tt.delims}); err != nil {
+				t.Fatalf("WriteTemplate() error = %v", err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(buf.String(), want) {
+					t.Errorf("output does not contain %q:\n%s", want, buf.String())
+				}
+			}
+			for _, notWant := range tt.notWant {
+				if strings.Contains(buf.String(), notWant) {
+					t.Errorf("output unexpectedly contains %q:\n%s", notWant, buf.String())
+				}
+			}
+		})
+	}
+}
+
+func TestWriteTemplate_LayoutErrors(t *testing.T) {
+	dir := t.TempDir()
+	tmplPath := filepath.Join(dir, "child.tmpl")
+	if err := os.WriteFile(tmplPath, []byte(`{{define "item"}}x{{end}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	brokenLayout := filepath.Join(dir, "broken.tmpl")
+	if err := os.WriteFile(brokenLayout, []byte(`{{block "item" .}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := WriteTemplate(&bytes.Buffer{}, nil, tmplPath, ConvertOptions{Layout: filepath.Join(dir, "missing.tmpl")}); err == nil {
+		t.Error("WriteTemplate() with missing layout error = nil")
+	}
+	if err := WriteTemplate(&bytes.Buffer{}, nil, tmplPath, ConvertOptions{Layout: brokenLayout}); !errors.Is(err, ErrTemplateParse) {
+		t.Errorf("WriteTemplate() with broken layout error = %v, want ErrTemplateParse", err)
+	}
+}
+
+func TestTemplateData_Item(t *testing.T) {
+	td := TemplateData{Items: []QuizItem{{Question: "q1"}, {Question: "q2"}}, Numbers: []string{"1", "2"}}
+	got := td.Item(1)
+	if got.Question != "q2" || got.Index != 1 || got.Number != "2" {
+		t.Errorf("Item(1) = %+v", got)
+	}
+}
@@ -198,7 +198,7 @@ func ParseMarkdownFile(mdFilePath string) (QuizItem, error) {
 		return QuizItem{}, fmt.Errorf("failed to read markdown file: %w", err)
 	}
 
-	fmText, body, err := splitFrontmatter(string(raw))
+	fmText, body, err := splitFrontmatter(strings.TrimPrefix(string(raw), utf8BOM))
 	if err != nil {
 		return QuizItem{}, fmt.Errorf("%s: %w", mdFilePath, err)
 	}
@@ -127,6 +127,20 @@ Vessel
 	}
 }
 
+func TestParseMarkdownFile_BOM(t *testing.T) {
+	content := "\uFEFF---\ntitle: BOM\n---\n## Question\n\n問題文\n\n## Answer\n\n答え\n"
+	dir := t.TempDir()
+	path := writeTempMarkdown(t, dir, "bom.md", content)
+
+	item, err := ParseMarkdownFile(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if item.Question != "問題文" || item.Answer != "答え" {
+		t.Errorf("item = %+v, want question 問題文 and answer 答え", item)
+	}
+}
+
 func TestParseMarkdownFile_MultilineQuestionWithoutBlankLine(t *testing.T) {
 	content := `---
 title: 自作問題-ジェプツンタンパ1世
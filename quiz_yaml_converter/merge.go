@@ -0,0 +1,234 @@
+// 複数のYAMLファイルを統合するときに，同じID（id）の問題が重なった場合の扱いを決める処理です．
+package quiz_yaml_converter
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+)
+
+// ConflictStrategy は同じIDの問題が複数ある場合にどの問題を採用するかの方針．
+type ConflictStrategy string
+
+// 同じIDの問題の扱い
+const (
+	ConflictKeepAll     ConflictStrategy = ""            // すべての問題をそのまま残す（従来の動作）
+	ConflictKeepFirst   ConflictStrategy = "keep-first"  // 最初に現れた問題を採用する
+	ConflictKeepNewest  ConflictStrategy = "keep-newest" // 更新日時が最も新しいファイルの問題を採用する
+	ConflictError       ConflictStrategy = "error"       // ErrIDConflictを返す
+	ConflictInteractive ConflictStrategy = "interactive" // MergeOptions.Resolveで選ぶ
+)
+
+// ParseConflictStrategy は同じIDの問題の扱いの名前を解析する．空文字列の場合はConflictKeepAllとなる．
+func ParseConflictStrategy(name string) (ConflictStrategy, error) {
+	switch strategy := ConflictStrategy(strings.ToLower(strings.TrimSpace(name))); strategy {
+	case ConflictKeepAll, ConflictKeepFirst, ConflictKeepNewest, ConflictError, ConflictInteractive:
+		return strategy, nil
+	default:
+		return "", fmt.Errorf("unsupported conflict strategy: %q (available: keep-first, keep-newest, error, interactive)", name)
+	}
+}
+
+// MergeSource は統合する1ファイル分の問題．
+type MergeSource struct {
+	Path    string    // ファイルのパス（メッセージに使う）
+	ModTime time.Time // ファイルの更新日時（ConflictKeepNewestで使う）
+	Items   []QuizItem
+}
+
+// ConflictCandidate は同じIDを持つ問題の1つ．
+type ConflictCandidate struct {
+	Path    string    // 問題が含まれていたファイルのパス
+	Index   int       // ファイルの中での問題の番号（1始まり）
+	ModTime time.Time // ファイルの更新日時
+	Item    QuizItem
+}
+
+// String は候補の位置を"パス:番号"の形式で返す．
+func (c ConflictCandidate) String() string {
+	return fmt.Sprintf("%s:%d", c.Path, c.Index)
+}
+
+// Conflict は同じIDを持つ問題の組と，採用した問題．
+type Conflict struct {
+	ID         string              // 問題ID
+	Candidates []ConflictCandidate // 同じIDを持つ問題（現れた順）
+	Chosen     int                 // 採用した問題のCandidatesでの位置
+}
+
+// ConflictResolver はConflictInteractiveで，同じIDの問題のうち採用するもののCandidatesでの位置を返す．
+// Chosenにはkeep-firstの場合の位置（0）が設定されている．
+type ConflictResolver func(c Conflict) (int, error)
+
+// MergeOptions は問題の統合に関するオプション．
+type MergeOptions struct {
+	Strategy ConflictStrategy
+	Resolve  ConflictResolver // ConflictInteractiveの場合に必要
+}
+
+// MergeItems は複数のファイルの問題を指定した順に連結し，同じID（前後の空白を除く）の問題をopts.Strategyに従って1つにまとめる．
+// 採用した問題は，そのIDが最初に現れた位置に置く．IDのない問題はすべて残す．
+// 同じファイルの中でIDが重なっている場合も同じように扱う．
+// ConflictKeepAllの場合はまとめずに連結した問題を返すが，重なっていたIDはConflictとして返す（Chosenは0）．
+// ConflictKeepNewestで更新日時が同じ場合は，後に指定したファイルの問題を採用する．
+// ConflictErrorの場合は，最初に見つかった重なりについてErrIDConflictを返す．
+func MergeItems(sources []MergeSource, opts MergeOptions) ([]QuizItem, []Conflict, error) {
+	indices, conflicts, err := MergeItemIndices(sources, opts)
+	if err != nil {
+		return nil, conflicts, err
+	}
+	var all []QuizItem
+	for _, source := range sources {
+		all = append(all, source.Items...)
+	}
+	items := make([]QuizItem, len(indices))
+	for i, index := range indices {
+		items[i] = all[index]
+	}
+	return items, conflicts, nil
+}
+
+// MergeItemIndices はMergeItemsと同じく問題を統合し，採用した問題をsourcesの問題を順に連結したものでの位置（0始まり）で返す．
+// 統合した問題を元のYAMLのノードと対応付ける場合（ComposeYAMLEditor）に使う．
+func MergeItemIndices(sources []MergeSource, opts MergeOptions) ([]int, []Conflict, error) {
+	if _, err := ParseConflictStrategy(string(opts.Strategy)); err != nil {
+		return nil, nil, err
+	}
+	if opts.Strategy == ConflictInteractive && opts.Resolve == nil {
+		return nil, nil, fmt.Errorf("conflict strategy %q requires a resolver", opts.Strategy)
+	}
+
+	var ids []string // 連結した問題のID（前後の空白を除く）
+	var conflicts []Conflict
+	var locations [][]int // conflictsの各候補の連結した問題での位置
+	positions := map[string]int{}
+	for _, source := range sources {
+		for i, item := range source.Items {
+			candidate := ConflictCandidate{Path: source.Path, Index: i + 1, ModTime: source.ModTime, Item: item}
+			id := strings.TrimSpace(item.ID)
+			ids = append(ids, id)
+			if id == "" {
+				continue
+			}
+			pos, ok := positions[id]
+			if !ok {
+				positions[id] = len(conflicts)
+				conflicts = append(conflicts, Conflict{ID: id, Candidates: []ConflictCandidate{candidate}})
+				locations = append(locations, []int{len(ids) - 1})
+				continue
+			}
+			conflicts[pos].Candidates = append(conflicts[pos].Candidates, candidate)
+			locations[pos] = append(locations[pos], len(ids)-1)
+		}
+	}
+	locations = slices.DeleteFunc(locations, func(l []int) bool { return len(l) < 2 })
+	conflicts = slices.DeleteFunc(conflicts, func(c Conflict) bool { return len(c.Candidates) < 2 })
+
+	if opts.Strategy == ConflictKeepAll {
+		indices := make([]int, len(ids))
+		for i := range indices {
+			indices[i] = i
+		}
+		return indices, conflicts, nil
+	}
+
+	chosen := map[string]int{}
+	for i := range conflicts {
+		c := &conflicts[i]
+		switch opts.Strategy {
+		case ConflictKeepNewest:
+			for j, candidate := range c.Candidates {
+				if !candidate.ModTime.Before(c.Candidates[c.Chosen].ModTime) {
+					c.Chosen = j
+				}
+			}
+		case ConflictError:
+			return nil, conflicts[:i+1], fmt.Errorf("%w: %q (%s)", ErrIDConflict, c.ID, joinCandidates(c.Candidates))
+		case ConflictInteractive:
+			j, err := opts.Resolve(*c)
+			if err != nil {
+				return nil, conflicts[:i], err
+			}
+			if j < 0 || j >= len(c.Candidates) {
+				return nil, conflicts[:i], fmt.Errorf("invalid choice for id %q: %d", c.ID, j)
+			}
+			c.Chosen = j
+		}
+		chosen[c.ID] = locations[i][c.Chosen]
+	}
+
+	var indices []int
+	placed := map[string]bool{}
+	for i, id := range ids {
+		index, ok := chosen[id]
+		switch {
+		case !ok:
+			indices = append(indices, i)
+		case !placed[id]:
+			placed[id] = true
+			indices = append(indices, index)
+		}
+	}
+	return indices, conflicts, nil
+}
+
+// joinCandidates は候補の位置をカンマでつないで返す．
+func joinCandidates(candidates []ConflictCandidate) string {
+	locations := make([]string, len(candidates))
+	for i, c := range candidates {
+		locations[i] = c.String()
+	}
+	return strings.Join(locations, ", ")
+}
+
+// MergeYAMLFiles は複数のYAMLファイルを読み込み，MergeItemsで統合した問題データと重なっていたIDを返す．
+// ConflictKeepNewestではファイルの更新日時を使う．
+func MergeYAMLFiles(yamlFilePaths []string, opts MergeOptions) ([]QuizItem, []Conflict, error) {
+	sources := make([]MergeSource, len(yamlFilePaths))
+	for i, path := range yamlFilePaths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to open YAML file: %w", err)
+		}
+		items, err := LoadYAMLData(path)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s: %w", path, err)
+		}
+		sources[i] = MergeSource{Path: path, ModTime: info.ModTime(), Items: items}
+	}
+	return MergeItems(sources, opts)
+}
+
+// MergeYAMLEditors は複数のYAMLファイルをMergeYAMLFilesと同じく統合し，
+// 採用した問題をコメントを残したまま1つの問題のリストとして書き出すYAMLEditorを返す（ComposeYAMLEditor参照）．
+func MergeYAMLEditors(yamlFilePaths []string, opts MergeOptions) (*YAMLEditor, []Conflict, error) {
+	sources := make([]MergeSource, len(yamlFilePaths))
+	var refs []YAMLItemRef
+	for i, path := range yamlFilePaths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to open YAML file: %w", err)
+		}
+		editor, err := LoadYAMLEditor(path)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s: %w", path, err)
+		}
+		sources[i] = MergeSource{Path: path, ModTime: info.ModTime(), Items: editor.Items}
+		refs = append(refs, editor.Refs()...)
+	}
+	indices, conflicts, err := MergeItemIndices(sources, opts)
+	if err != nil {
+		return nil, conflicts, err
+	}
+	chosen := make([]YAMLItemRef, len(indices))
+	for i, index := range indices {
+		chosen[i] = refs[index]
+	}
+	editor, err := ComposeYAMLEditor(chosen)
+	if err != nil {
+		return nil, conflicts, err
+	}
+	return editor, conflicts, nil
+}
@@ -0,0 +1,235 @@
+package quiz_yaml_converter
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseConflictStrategy(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected ConflictStrategy
+		wantErr  bool
+	}{
+		{"", ConflictKeepAll, false},
+		{"keep-first", ConflictKeepFirst, false},
+		{" Keep-Newest ", ConflictKeepNewest, false},
+		{"error", ConflictError, false},
+		{"interactive", ConflictInteractive, false},
+		{"keep-last", "", true},
+	}
+	for _, tt := range tests {
+		got, err := ParseConflictStrategy(tt.input)
+		if (err != nil) != tt.wantErr || got != tt.expected {
+			t.Errorf("ParseConflictStrategy(%q) = %q, %v, want %q (error: %v)", tt.input, got, err, tt.expected, tt.wantErr)
+		}
+	}
+}
+
+func TestMergeItems(t *testing.T) {
+	older := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	newer := older.Add(time.Hour)
+	sources := []MergeSource{
+		{Path: "a.yaml", ModTime: newer, Items: []QuizItem{
+			{ID: "q1", Question: "a-q1"},
+			{Question: "a-noid"},
+			{ID: "q2", Question: "a-q2"},
+		}},
+		{Path: "b.yaml", ModTime: older, Items: []QuizItem{
+			{ID: "q3", Question: "b-q3"},
+			{ID: " q1 ", Question: "b-q1"},
+			{ID: "q2", Question: "b-q2"},
+			{Question: "b-noid"},
+		}},
+	}
+	questions := func(items []QuizItem) []string {
+		var qs []string
+		for _, item := range items {
+			qs = append(qs, item.Question)
+		}
+		return qs
+	}
+
+	tests := []struct {
+		name     string
+		opts     MergeOptions
+		expected []string
+		chosen   []int
+	}{
+		{"keep all", MergeOptions{}, []string{"a-q1", "a-noid", "a-q2", "b-q3", "b-q1", "b-q2", "b-noid"}, []int{0, 0}},
+		{"keep first", MergeOptions{Strategy: ConflictKeepFirst}, []string{"a-q1", "a-noid", "a-q2", "b-q3", "b-noid"}, []int{0, 0}},
+		{"keep newest", MergeOptions{Strategy: ConflictKeepNewest}, []string{"a-q1", "a-noid", "a-q2", "b-q3", "b-noid"}, []int{0, 0}},
+		{
+			"interactive",
+			MergeOptions{Strategy: ConflictInteractive, Resolve: func(c Conflict) (int, error) {
+				if c.ID == "q2" {
+					return 1, nil
+				}
+				return 0, nil
+			}},
+			[]string{"a-q1", "a-noid", "b-q2", "b-q3", "b-noid"},
+			[]int{0, 1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			items, conflicts, err := MergeItems(sources, tt.opts)
+			if err != nil {
+				t.Fatalf("MergeItems() error = %v", err)
+			}
+			if got := questions(items); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("MergeItems() = %q, want %q", got, tt.expected)
+			}
+			var ids []string
+			var chosen []int
+			for _, c := range conflicts {
+				ids = append(ids, c.ID)
+				chosen = append(chosen, c.Chosen)
+			}
+			if !reflect.DeepEqual(ids, []string{"q1", "q2"}) || !reflect.DeepEqual(chosen, tt.chosen) {
+				t.Errorf("conflicts = %v chosen %v, want [q1 q2] chosen %v", ids, chosen, tt.chosen)
+			}
+			if got := conflicts[0].Candidates[1].String(); got != "b.yaml:2" {
+				t.Errorf("Candidates[1] = %q, want b.yaml:2", got)
+			}
+		})
+	}
+
+	// 更新日時が新しいファイルの問題を採用し，同じ場合は後に指定したファイルの問題を採用する
+	sources[1].ModTime = newer.Add(time.Hour)
+	items, _, err := MergeItems(sources, MergeOptions{Strategy: ConflictKeepNewest})
+	if err != nil {
+		t.Fatalf("MergeItems() error = %v", err)
+	}
+	if got, want := questions(items), []string{"b-q1", "a-noid", "b-q2", "b-q3", "b-noid"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("MergeItems(keep-newest) = %q, want %q", got, want)
+	}
+	sources[1].ModTime = newer
+	if items, _, _ = MergeItems(sources, MergeOptions{Strategy: ConflictKeepNewest}); items[0].Question != "b-q1" {
+		t.Errorf("MergeItems(keep-newest) with the same time = %q, want b-q1 first", questions(items))
+	}
+
+	_, conflicts, err := MergeItems(sources, MergeOptions{Strategy: ConflictError})
+	if !errors.Is(err, ErrIDConflict) || len(conflicts) != 1 {
+		t.Errorf("MergeItems(error) = %v, %v, want ErrIDConflict for q1", conflicts, err)
+	}
+	if want := `duplicate question id: "q1" (a.yaml:1, b.yaml:2)`; err == nil || err.Error() != want {
+		t.Errorf("MergeItems(error) error = %v, want %q", err, want)
+	}
+	if _, _, err := MergeItems(sources[:1], MergeOptions{Strategy: ConflictError}); err != nil {
+		t.Errorf("MergeItems(error) without conflicts error = %v", err)
+	}
+
+	if _, _, err := MergeItems(sources, MergeOptions{Strategy: ConflictInteractive}); err == nil {
+		t.Error("MergeItems(interactive) without a resolver should fail")
+	}
+	resolveErr := errors.New("canceled")
+	if _, _, err := MergeItems(sources, MergeOptions{Strategy: ConflictInteractive, Resolve: func(Conflict) (int, error) { return 0, resolveErr }}); !errors.Is(err, resolveErr) {
+		t.Errorf("MergeItems(interactive) error = %v, want %v", err, resolveErr)
+	}
+	if _, _, err := MergeItems(sources, MergeOptions{Strategy: ConflictInteractive, Resolve: func(Conflict) (int, error) { return 2, nil }}); err == nil {
+		t.Error("MergeItems(interactive) with an out-of-range choice should fail")
+	}
+}
+
+func TestMergeYAMLFiles(t *testing.T) {
+	dir := t.TempDir()
+	first := filepath.Join(dir, "first.yaml")
+	second := filepath.Join(dir, "second.yaml")
+	if err := os.WriteFile(first, []byte("- id: q1\n  question: 古い問題\n  answer: a\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(second, []byte("- id: q1\n  question: 新しい問題\n  answer: a\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	old := time.Now().Add(-time.Hour)
+	if err := os.Chtimes(first, old, old); err != nil {
+		t.Fatal(err)
+	}
+
+	items, conflicts, err := MergeYAMLFiles([]string{second, first}, MergeOptions{Strategy: ConflictKeepNewest})
+	if err != nil {
+		t.Fatalf("MergeYAMLFiles() error = %v", err)
+	}
+	if len(items) != 1 || items[0].Question != "新しい問題" {
+		t.Errorf("MergeYAMLFiles() = %+v, want only the newer item", items)
+	}
+	if len(conflicts) != 1 || conflicts[0].Candidates[conflicts[0].Chosen].Path != second {
+		t.Errorf("conflicts = %+v, want %s chosen", conflicts, second)
+	}
+
+	if _, _, err := MergeYAMLFiles([]string{filepath.Join(dir, "missing.yaml")}, MergeOptions{}); err == nil {
+		t.Error("MergeYAMLFiles() should fail for a missing file")
+	}
+}
+
+func TestMergeItemIndices(t *testing.T) {
+	sources := []MergeSource{
+		{Path: "a.yaml", Items: []QuizItem{{ID: "q1", Question: "a1"}, {Question: "a2"}}},
+		{Path: "b.yaml", Items: []QuizItem{{ID: "q2", Question: "b1"}, {ID: " q1 ", Question: "b2"}}},
+	}
+	tests := []struct {
+		name     string
+		opts     MergeOptions
+		expected []int
+	}{
+		{"keep all", MergeOptions{}, []int{0, 1, 2, 3}},
+		{"keep first", MergeOptions{Strategy: ConflictKeepFirst}, []int{0, 1, 2}},
+		{"interactive", MergeOptions{Strategy: ConflictInteractive, Resolve: func(Conflict) (int, error) { return 1, nil }}, []int{3, 1, 2}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			indices, conflicts, err := MergeItemIndices(sources, tt.opts)
+			if err != nil {
+				t.Fatalf("MergeItemIndices() error = %v", err)
+			}
+			if !reflect.DeepEqual(indices, tt.expected) {
+				t.Errorf("MergeItemIndices() = %v, want %v", indices, tt.expected)
+			}
+			if len(conflicts) != 1 || conflicts[0].ID != "q1" {
+				t.Errorf("conflicts = %+v, want q1", conflicts)
+			}
+		})
+	}
+}
+
+func TestMergeYAMLEditors(t *testing.T) {
+	dir := t.TempDir()
+	first := filepath.Join(dir, "first.yaml")
+	second := filepath.Join(dir, "second.yaml")
+	if err := os.WriteFile(first, []byte("- id: q1 # 古い\n  question: 古い問題\n  answer: a\n- id: q2\n  question: 残る問題 # 出典を確認\n  answer: b\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(second, []byte("# 修正\n- id: q1\n  question: 新しい問題 # 直した\n  answer: a\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	old := time.Now().Add(-time.Hour)
+	if err := os.Chtimes(first, old, old); err != nil {
+		t.Fatal(err)
+	}
+
+	editor, conflicts, err := MergeYAMLEditors([]string{first, second}, MergeOptions{Strategy: ConflictKeepNewest})
+	if err != nil {
+		t.Fatalf("MergeYAMLEditors() error = %v", err)
+	}
+	if len(conflicts) != 1 {
+		t.Errorf("conflicts = %+v, want 1 conflict", conflicts)
+	}
+	var buf bytes.Buffer
+	if err := editor.Write(&buf); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	expected := "# 修正\n- id: q1\n  question: 新しい問題 # 直した\n  answer: a\n- id: q2\n  question: 残る問題 # 出典を確認\n  answer: b\n"
+	if buf.String() != expected {
+		t.Errorf("Write() =\n%s\nwant\n%s", buf.String(), expected)
+	}
+
+	if _, _, err := MergeYAMLEditors([]string{first, second}, MergeOptions{Strategy: ConflictError}); !errors.Is(err, ErrIDConflict) {
+		t.Errorf("MergeYAMLEditors() error = %v, want ErrIDConflict", err)
+	}
+}
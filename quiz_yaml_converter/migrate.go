@@ -0,0 +1,328 @@
+// 古い形式のYAMLファイルを現在の形式に書き換える処理です．
+// 形式を変えても過去の問題集を読み込めるよう，ファイルのmetadataにversionを書き，
+// 古い形式のファイルはMigrateYAMLで現在の形式に書き換えます．
+package quiz_yaml_converter
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// CurrentSchemaVersion は現在のYAMLファイルの形式のバージョン．
+// versionが書かれていないファイルはバージョン1として扱う．
+//
+//   - バージョン1: 問題のリストのほか，ジャンル名から問題のリストへのマッピングで書ける．
+//     criteriaの各キーやtags，commentsなどのリストに文字列を1つだけ書いたものも使われていた
+//   - バージョン2: 問題のリストで書き，ジャンルは各問題のgenreに書く．リストのフィールドは常にリストで書く
+//
+// 読み込みはどちらの形式にも対応し，CurrentSchemaVersionより新しいバージョンのファイルはErrUnsupportedVersionとなる．
+const CurrentSchemaVersion = 2
+
+// listFields は値がリストの問題のフィールド．古い形式では文字列を1つだけ書くことがあった．
+var listFields = []string{"segments", "tags", "comments", "choices"}
+
+// MigrationChange はMigrateYAMLで行った書き換え．
+type MigrationChange struct {
+	Line    int    // 元のファイルでの行番号（ファイル全体に関する書き換えの場合は0）
+	Message string // 書き換えの内容（日本語）
+
+	format string
+	args   []any
+}
+
+// newMigrationChange はメッセージの書式と引数を残した書き換えを作る．
+func newMigrationChange(line int, format string, args ...any) MigrationChange {
+	return MigrationChange{Line: line, Message: fmt.Sprintf(format, args...), format: format, args: args}
+}
+
+// String は"行番号: 内容"の形式のメッセージを返す．Lineが0の場合は内容だけを返す．
+func (c MigrationChange) String() string {
+	return c.Localized(LanguageJapanese)
+}
+
+// Localized は指定した言語でStringと同じ形式のメッセージを返す．
+func (c MigrationChange) Localized(lang Language) string {
+	message := c.Message
+	if c.format != "" {
+		message = fmt.Sprintf(Translate(lang, c.format), c.args...)
+	}
+	if c.Line == 0 {
+		return message
+	}
+	return fmt.Sprintf(Translate(lang, "%d行目: %s"), c.Line, message)
+}
+
+// MigrateYAML は古い形式（CurrentSchemaVersion参照）のYAMLデータを現在の形式に書き換え，書き換えた内容を返す．
+//   - ジャンル名から問題のリストへのマッピングは，問題のリストにしてgenreが未設定の問題にジャンル名を設定する
+//   - criteriaの各キーとsegments，tags，comments，choicesの値が文字列の場合は，その文字列だけのリストにする
+//   - metadataは先頭の独立したドキュメントにし，versionをCurrentSchemaVersionにする
+//
+// コメントは書き換えた問題やフィールドに付けたまま残す．書き換える必要がない場合は，changesは空となり元のデータをそのまま返す．
+func MigrateYAML(yamlData []byte) ([]byte, []MigrationChange, error) {
+	decoder := yaml.NewDecoder(bytes.NewReader(bytes.TrimPrefix(yamlData, []byte(utf8BOM))))
+	var documents []*yaml.Node
+	for {
+		var node yaml.Node
+		if err := decoder.Decode(&node); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, nil, fmt.Errorf("%w: %w", ErrInvalidYAML, err)
+		}
+		documents = append(documents, &node)
+	}
+
+	m := &migration{}
+	var migrated []*yaml.Node
+	for _, doc := range documents {
+		nodes, err := m.document(doc)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%w: %w", ErrInvalidYAML, err)
+		}
+		migrated = append(migrated, nodes...)
+	}
+	if m.metadata == nil {
+		m.metadata = &yaml.Node{Kind: yaml.MappingNode}
+		migrated = append([]*yaml.Node{metadataDocument(m.metadata)}, migrated...)
+	}
+	if err := m.setVersion(); err != nil {
+		return nil, nil, err
+	}
+	if len(m.changes) == 0 {
+		return yamlData, nil, nil
+	}
+	slices.SortStableFunc(m.changes, func(a, b MigrationChange) int { return a.Line - b.Line })
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(yamlIndent)
+	for _, doc := range migrated {
+		if err := encoder.Encode(doc); err != nil {
+			return nil, nil, fmt.Errorf("failed to marshal YAML: %w", err)
+		}
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, nil, fmt.Errorf("failed to marshal YAML: %w", err)
+	}
+	// 書き換えた結果が現在の形式として読み込めることを確認する
+	if _, err := ParseYAMLData(buf.Bytes()); err != nil {
+		return nil, nil, fmt.Errorf("migrated YAML cannot be loaded: %w", err)
+	}
+	return buf.Bytes(), m.changes, nil
+}
+
+// migration はMigrateYAMLの途中の状態．
+type migration struct {
+	metadata *yaml.Node // 最初のmetadataのマッピングノード
+	changes  []MigrationChange
+}
+
+// metadataDocument はmetadataだけのドキュメントのノードを返す．
+func metadataDocument(metadata *yaml.Node) *yaml.Node {
+	return &yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{
+		Kind:    yaml.MappingNode,
+		Content: []*yaml.Node{{Kind: yaml.ScalarNode, Tag: "!!str", Value: metadataKey}, metadata},
+	}}}
+}
+
+// document は1つのドキュメントを現在の形式のドキュメント（metadataのドキュメントと問題のリストのドキュメント）にする．
+func (m *migration) document(doc *yaml.Node) ([]*yaml.Node, error) {
+	if len(doc.Content) == 0 {
+		return []*yaml.Node{doc}, nil
+	}
+	root := doc.Content[0]
+	switch root.Kind {
+	case yaml.SequenceNode:
+		for _, item := range root.Content {
+			m.item(item)
+		}
+		return []*yaml.Node{doc}, nil
+	case yaml.MappingNode:
+	default:
+		return []*yaml.Node{doc}, nil
+	}
+
+	var nodes []*yaml.Node
+	list := &yaml.Node{Kind: yaml.SequenceNode}
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		key, value := root.Content[i], root.Content[i+1]
+		if key.Kind != yaml.ScalarNode || key.Value == "" {
+			return nil, fmt.Errorf("line %d: genre name must be a non-empty string", key.Line)
+		}
+		if key.Value == metadataKey && value.Kind == yaml.MappingNode {
+			if m.metadata == nil {
+				m.metadata = value
+			}
+			metadata := metadataDocument(value)
+			metadata.Content[0].HeadComment = joinComments(doc.HeadComment, root.HeadComment, key.HeadComment)
+			nodes = append(nodes, metadata)
+			if len(root.Content) > 2 {
+				m.changes = append(m.changes, newMigrationChange(key.Line, "metadataを独立したドキュメントに移しました"))
+			}
+			continue
+		}
+		if value.Kind == yaml.ScalarNode && value.Tag == "!!null" {
+			continue
+		}
+		if value.Kind != yaml.SequenceNode {
+			return nil, fmt.Errorf("line %d: genre %q must be a list of quiz items", value.Line, key.Value)
+		}
+		for j, item := range value.Content {
+			if item.Kind == yaml.MappingNode && mappingIndex(item, "genre") < 0 {
+				insertItemKey(item, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: "genre"}, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key.Value})
+			}
+			if j == 0 {
+				item.HeadComment = joinComments(key.HeadComment, value.HeadComment, item.HeadComment)
+			}
+			m.item(item)
+			list.Content = append(list.Content, item)
+		}
+		m.changes = append(m.changes, newMigrationChange(key.Line, "ジャンル「%s」の%d問を問題のリストに移し，genreを設定しました", key.Value, len(value.Content)))
+	}
+	if len(list.Content) > 0 {
+		if len(nodes) == 0 {
+			list.HeadComment = joinComments(doc.HeadComment, root.HeadComment)
+		}
+		list.FootComment = root.FootComment
+		nodes = append(nodes, &yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{list}})
+	}
+	return nodes, nil
+}
+
+// item は問題のリストや判定基準の値が文字列の場合に，その文字列だけのリストにする．
+func (m *migration) item(item *yaml.Node) {
+	if item.Kind != yaml.MappingNode {
+		return
+	}
+	for i := 0; i+1 < len(item.Content); i += 2 {
+		key, value := item.Content[i], item.Content[i+1]
+		switch {
+		case key.Value == "criteria" && value.Kind == yaml.MappingNode:
+			for j := 0; j+1 < len(value.Content); j += 2 {
+				if toList(value.Content, j+1) {
+					m.changes = append(m.changes, newMigrationChange(value.Content[j].Line, "%s を文字列からリストに変換しました", "criteria."+value.Content[j].Value))
+				}
+			}
+		case slices.Contains(listFields, key.Value):
+			if toList(item.Content, i+1) {
+				m.changes = append(m.changes, newMigrationChange(key.Line, "%s を文字列からリストに変換しました", key.Value))
+			}
+		}
+	}
+}
+
+// toList はnodes[i]が空でない文字列の場合に，その文字列だけのリストに置き換えてtrueを返す．
+// 値に付いていた行末のコメントはリストの要素に残す．
+func toList(nodes []*yaml.Node, i int) bool {
+	value := nodes[i]
+	if value.Kind != yaml.ScalarNode || value.Tag == "!!null" || value.Value == "" {
+		return false
+	}
+	nodes[i] = &yaml.Node{
+		Kind:        yaml.SequenceNode,
+		HeadComment: value.HeadComment,
+		FootComment: value.FootComment,
+		Content: []*yaml.Node{{
+			Kind:        yaml.ScalarNode,
+			Tag:         "!!str",
+			Value:       value.Value,
+			Style:       value.Style,
+			LineComment: value.LineComment,
+		}},
+	}
+	return true
+}
+
+// setVersion は最初のmetadataのversionをCurrentSchemaVersionにする．
+func (m *migration) setVersion() error {
+	version := strconv.Itoa(CurrentSchemaVersion)
+	if pos := mappingIndex(m.metadata, "version"); pos >= 0 {
+		value := m.metadata.Content[pos+1]
+		current, err := strconv.Atoi(value.Value)
+		if err != nil || value.Kind != yaml.ScalarNode {
+			return fmt.Errorf("line %d: metadata version must be an integer", value.Line)
+		}
+		if current > CurrentSchemaVersion {
+			return fmt.Errorf("%w: %d (supported: %d or earlier)", ErrUnsupportedVersion, current, CurrentSchemaVersion)
+		}
+		if current == CurrentSchemaVersion {
+			return nil
+		}
+		value.Value, value.Tag, value.Style = version, "!!int", 0
+		m.changes = append(m.changes, newMigrationChange(value.Line, "metadataのversionを%dにしました", CurrentSchemaVersion))
+		return nil
+	}
+	m.metadata.Content = append([]*yaml.Node{
+		{Kind: yaml.ScalarNode, Tag: "!!str", Value: "version"},
+		{Kind: yaml.ScalarNode, Tag: "!!int", Value: version},
+	}, m.metadata.Content...)
+	m.changes = append(m.changes, newMigrationChange(m.metadata.Line, "metadataのversionを%dにしました", CurrentSchemaVersion))
+	return nil
+}
+
+// insertItemKey は問題のマッピングノードに，WriteYAMLと同じキーの順序になる位置でキーと値を追加する．
+func insertItemKey(item, key, value *yaml.Node) {
+	rank := itemKeyRank(key.Value)
+	insert := len(item.Content)
+	for i := 0; i+1 < len(item.Content); i += 2 {
+		if itemKeyRank(item.Content[i].Value) > rank {
+			insert = i
+			break
+		}
+	}
+	item.Content = append(item.Content[:insert], append([]*yaml.Node{key, value}, item.Content[insert:]...)...)
+}
+
+// itemKeyRank はQuizItemのフィールドの順序でのキーの位置を返す．フィールドにないキーは末尾とする．
+func itemKeyRank(key string) int {
+	t := reflect.TypeOf(QuizItem{})
+	for i := 0; i < t.NumField(); i++ {
+		if name, _, _ := strings.Cut(t.Field(i).Tag.Get("yaml"), ","); name == key {
+			return i
+		}
+	}
+	return t.NumField()
+}
+
+// joinComments は空でないコメントを改行でつなぐ．
+func joinComments(comments ...string) string {
+	var nonEmpty []string
+	for _, c := range comments {
+		if c != "" {
+			nonEmpty = append(nonEmpty, c)
+		}
+	}
+	return strings.Join(nonEmpty, "\n")
+}
+
+// MigrateYAMLFile はYAMLファイルをMigrateYAMLで現在の形式に書き換えてoutputFilePathに書き出し，書き換えた内容を返す．
+// outputFilePathが入力ファイルと同じで書き換える必要がない場合は，ファイルを書き出さない．
+func MigrateYAMLFile(yamlFilePath, outputFilePath string) ([]MigrationChange, error) {
+	yamlData, err := os.ReadFile(yamlFilePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open YAML file: %w", err)
+	}
+	migrated, changes, err := MigrateYAML(yamlData)
+	if err != nil {
+		return nil, err
+	}
+	if len(changes) == 0 && outputFilePath == yamlFilePath {
+		return nil, nil
+	}
+	err = writeFileAtomic(outputFilePath, false, func(w io.Writer) error {
+		if _, err := w.Write(migrated); err != nil {
+			return fmt.Errorf("failed to write YAML file: %w", err)
+		}
+		return nil
+	})
+	return changes, err
+}
@@ -0,0 +1,158 @@
+package quiz_yaml_converter
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestMigrateYAML(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+		changes  []string
+	}{
+		{
+			"genre layout",
+			`# 問題集
+metadata:
+  author: 山田太郎
+# 地理の問題
+地理:
+  - question: 日本の首都は？
+    answer: 東京 # 確認済み
+    criteria:
+      ok: 東京都 # 正式名称
+  - question: フランスの首都は？
+    answer: パリ
+    tags: 首都
+歴史:
+  - question: 江戸幕府を開いたのは？
+    answer: 徳川家康
+    genre: 日本史
+`,
+			`# 問題集
+metadata:
+  version: 2
+  author: 山田太郎
+---
+# 地理の問題
+- question: 日本の首都は？
+  answer: 東京 # 確認済み
+  genre: 地理
+  criteria:
+    ok:
+      - 東京都 # 正式名称
+- question: フランスの首都は？
+  answer: パリ
+  genre: 地理
+  tags:
+    - 首都
+- question: 江戸幕府を開いたのは？
+  answer: 徳川家康
+  genre: 日本史
+`,
+			[]string{
+				"2行目: metadataを独立したドキュメントに移しました",
+				"3行目: metadataのversionを2にしました",
+				"5行目: ジャンル「地理」の2問を問題のリストに移し，genreを設定しました",
+				"9行目: criteria.ok を文字列からリストに変換しました",
+				"12行目: tags を文字列からリストに変換しました",
+				"13行目: ジャンル「歴史」の1問を問題のリストに移し，genreを設定しました",
+			},
+		},
+		{
+			"list without metadata",
+			"- question: q\n  answer: a\n  comments: c\n",
+			"metadata:\n  version: 2\n---\n- question: q\n  answer: a\n  comments:\n    - c\n",
+			[]string{"metadataのversionを2にしました", "3行目: comments を文字列からリストに変換しました"},
+		},
+		{
+			"older version",
+			"metadata:\n  version: 1\n---\n- question: q\n  answer: a\n",
+			"metadata:\n  version: 2\n---\n- question: q\n  answer: a\n",
+			[]string{"2行目: metadataのversionを2にしました"},
+		},
+		{
+			"current version",
+			"metadata:\n    version: 2\n---\n- question: q\n  answer: a\n",
+			"metadata:\n    version: 2\n---\n- question: q\n  answer: a\n",
+			nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, changes, err := MigrateYAML([]byte(tt.input))
+			if err != nil {
+				t.Fatalf("MigrateYAML() error = %v", err)
+			}
+			if string(got) != tt.expected {
+				t.Errorf("MigrateYAML() =\n%s\nwant\n%s", got, tt.expected)
+			}
+			var messages []string
+			for _, c := range changes {
+				messages = append(messages, c.String())
+			}
+			if !reflect.DeepEqual(messages, tt.changes) {
+				t.Errorf("changes = %q, want %q", messages, tt.changes)
+			}
+		})
+	}
+}
+
+func TestMigrateYAML_KeepsItems(t *testing.T) {
+	input := "metadata:\n  license: CC BY 4.0\n地理:\n  - question: 日本の首都は？\n    answer: 東京\n    spell: Tokyo\n"
+	before, err := ParseYAMLData([]byte(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	migrated, _, err := MigrateYAML([]byte(input))
+	if err != nil {
+		t.Fatalf("MigrateYAML() error = %v", err)
+	}
+	after, err := ParseYAMLData(migrated)
+	if err != nil {
+		t.Fatalf("ParseYAMLData() error = %v", err)
+	}
+	if !reflect.DeepEqual(after, before) {
+		t.Errorf("items after migration = %+v, want %+v", after, before)
+	}
+}
+
+func TestMigrateYAML_Errors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  error
+	}{
+		{"newer version", "metadata:\n  version: 3\n---\n- question: q\n  answer: a\n", ErrUnsupportedVersion},
+		{"invalid YAML", "- question: [", ErrInvalidYAML},
+		{"genre is not a list", "地理: 東京\n", ErrInvalidYAML},
+	}
+	for _, tt := range tests {
+		if _, _, err := MigrateYAML([]byte(tt.input)); !errors.Is(err, tt.want) {
+			t.Errorf("%s: MigrateYAML() error = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+	if _, _, err := MigrateYAML([]byte("metadata:\n  version: x\n")); err == nil {
+		t.Error("MigrateYAML() should fail for a non-integer version")
+	}
+}
+
+func TestParseYAMLData_SchemaVersion(t *testing.T) {
+	if _, err := ParseYAMLData([]byte("metadata:\n  version: 2\n---\n- question: q\n  answer: a\n")); err != nil {
+		t.Errorf("ParseYAMLData(version 2) error = %v", err)
+	}
+	_, err := ParseYAMLData([]byte("metadata:\n  version: 3\n---\n- question: q\n  answer: a\n"))
+	if !errors.Is(err, ErrUnsupportedVersion) || !errors.Is(err, ErrInvalidYAML) {
+		t.Errorf("ParseYAMLData(version 3) error = %v, want ErrUnsupportedVersion", err)
+	}
+}
+
+func TestMigrationChange_Localized(t *testing.T) {
+	c := newMigrationChange(5, "ジャンル「%s」の%d問を問題のリストに移し，genreを設定しました", "地理", 2)
+	if got, want := c.Localized(LanguageEnglish), `line 5: set the genre "地理" on 2 items and moved them into the item list`; got != want {
+		t.Errorf("Localized(en) = %q, want %q", got, want)
+	}
+}
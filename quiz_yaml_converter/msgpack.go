@@ -0,0 +1,138 @@
+// 問題データのMessagePack形式での出力です．
+package quiz_yaml_converter
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+)
+
+// MessagePackのMIMEタイプ
+const msgpackMediaType = "application/vnd.msgpack"
+
+// writeMsgpackItems は問題データをMessagePackの配列として書き出す．
+// 各問題はJSON出力と同じキーを持つマップとし，キーはアルファベット順に並べる．
+func writeMsgpackItems(w io.Writer, items []QuizItem, opts ConvertOptions) error {
+	items = withAssignedIDs(items, opts)
+	if items == nil {
+		items = []QuizItem{}
+	}
+	// JSON出力と同じ項目（omitemptyやspellsなど）にするため，一度JSONにしてから変換する
+	data, err := json.Marshal(items)
+	if err != nil {
+		return fmt.Errorf("failed to write MessagePack: %w", err)
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return fmt.Errorf("failed to write MessagePack: %w", err)
+	}
+	var buf bytes.Buffer
+	if err := appendMsgpack(&buf, v); err != nil {
+		return fmt.Errorf("failed to write MessagePack: %w", err)
+	}
+	if _, err := w.Write(buf.Bytes()); err != nil {
+		return fmt.Errorf("failed to write MessagePack: %w", err)
+	}
+	return nil
+}
+
+// appendMsgpack はJSONをデコードした値（nil, bool, json.Number, string, []any, map[string]any）を
+// MessagePackでbufに書き出す．数値は整数にできる場合は整数，それ以外はfloat64とする．
+func appendMsgpack(buf *bytes.Buffer, v any) error {
+	switch v := v.(type) {
+	case nil:
+		buf.WriteByte(0xc0)
+	case bool:
+		if v {
+			buf.WriteByte(0xc3)
+		} else {
+			buf.WriteByte(0xc2)
+		}
+	case json.Number:
+		if n, err := v.Int64(); err == nil {
+			appendMsgpackInt(buf, n)
+			return nil
+		}
+		f, err := v.Float64()
+		if err != nil {
+			return err
+		}
+		buf.WriteByte(0xcb)
+		buf.Write(binary.BigEndian.AppendUint64(nil, math.Float64bits(f)))
+	case string:
+		appendMsgpackHeader(buf, len(v), 0xa0, 32, 0xd9, 0xda, 0xdb)
+		buf.WriteString(v)
+	case []any:
+		appendMsgpackHeader(buf, len(v), 0x90, 16, 0, 0xdc, 0xdd)
+		for _, e := range v {
+			if err := appendMsgpack(buf, e); err != nil {
+				return err
+			}
+		}
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		appendMsgpackHeader(buf, len(v), 0x80, 16, 0, 0xde, 0xdf)
+		for _, k := range keys {
+			if err := appendMsgpack(buf, k); err != nil {
+				return err
+			}
+			if err := appendMsgpack(buf, v[k]); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("unsupported value type %T", v)
+	}
+	return nil
+}
+
+// appendMsgpackHeader は文字列・配列・マップの長さを書き出す．
+// fixMaxより短い場合はfixの下位ビットに長さを入れ，それ以外は長さに応じて8・16・32ビットの形式を使う
+// （code8が0の形式（配列とマップ）は8ビットの形式を持たない）．
+func appendMsgpackHeader(buf *bytes.Buffer, n int, fix byte, fixMax int, code8, code16, code32 byte) {
+	switch {
+	case n < fixMax:
+		buf.WriteByte(fix | byte(n))
+	case code8 != 0 && n <= math.MaxUint8:
+		buf.WriteByte(code8)
+		buf.WriteByte(byte(n))
+	case n <= math.MaxUint16:
+		buf.WriteByte(code16)
+		buf.Write(binary.BigEndian.AppendUint16(nil, uint16(n)))
+	default:
+		buf.WriteByte(code32)
+		buf.Write(binary.BigEndian.AppendUint32(nil, uint32(n)))
+	}
+}
+
+// appendMsgpackInt は整数を，値が収まる最も短い形式で書き出す．
+func appendMsgpackInt(buf *bytes.Buffer, n int64) {
+	switch {
+	case n >= 0 && n <= math.MaxInt8:
+		buf.WriteByte(byte(n))
+	case n < 0 && n >= -32:
+		buf.WriteByte(byte(int8(n)))
+	case n >= math.MinInt8 && n <= math.MaxInt8:
+		buf.WriteByte(0xd0)
+		buf.WriteByte(byte(int8(n)))
+	case n >= math.MinInt16 && n <= math.MaxInt16:
+		buf.WriteByte(0xd1)
+		buf.Write(binary.BigEndian.AppendUint16(nil, uint16(int16(n))))
+	case n >= math.MinInt32 && n <= math.MaxInt32:
+		buf.WriteByte(0xd2)
+		buf.Write(binary.BigEndian.AppendUint32(nil, uint32(int32(n))))
+	default:
+		buf.WriteByte(0xd3)
+		buf.Write(binary.BigEndian.AppendUint64(nil, uint64(n)))
+	}
+}
@@ -0,0 +1,231 @@
+package quiz_yaml_converter
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestAppendMsgpack(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    any
+		expected []byte
+	}{
+		{"nil", nil, []byte{0xc0}},
+		{"false", false, []byte{0xc2}},
+		{"true", true, []byte{0xc3}},
+		{"positive fixint", json.Number("5"), []byte{0x05}},
+		{"negative fixint", json.Number("-1"), []byte{0xff}},
+		{"int8", json.Number("-100"), []byte{0xd0, 0x9c}},
+		{"int16", json.Number("300"), []byte{0xd1, 0x01, 0x2c}},
+		{"int32", json.Number("70000"), []byte{0xd2, 0x00, 0x01, 0x11, 0x70}},
+		{"float64", json.Number("1.5"), []byte{0xcb, 0x3f, 0xf8, 0, 0, 0, 0, 0, 0}},
+		{"fixstr", "あ", []byte{0xa3, 0xe3, 0x81, 0x82}},
+		{"str8", strings.Repeat("a", 32), append([]byte{0xd9, 32}, strings.Repeat("a", 32)...)},
+		{"fixarray", []any{true, nil}, []byte{0x92, 0xc3, 0xc0}},
+		{"fixmap sorted keys", map[string]any{"b": json.Number("2"), "a": json.Number("1")}, []byte{0x82, 0xa1, 'a', 0x01, 0xa1, 'b', 0x02}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := appendMsgpack(&buf, tt.value); err != nil {
+				t.Fatalf("appendMsgpack() error = %v", err)
+			}
+			if !bytes.Equal(buf.Bytes(), tt.expected) {
+				t.Errorf("appendMsgpack() = % x, want % x", buf.Bytes(), tt.expected)
+			}
+		})
+	}
+}
+
+func TestAppendMsgpackHeader_Long(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    any
+		expected []byte
+	}{
+		{"str16", strings.Repeat("a", 256), []byte{0xda, 0x01, 0x00}},
+		{"array16", make([]any, 16), []byte{0xdc, 0x00, 0x10}},
+		{"array32", make([]any, 65536), []byte{0xdd, 0x00, 0x01, 0x00, 0x00}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := appendMsgpack(&buf, tt.value); err != nil {
+				t.Fatalf("appendMsgpack() error = %v", err)
+			}
+			if !bytes.HasPrefix(buf.Bytes(), tt.expected) {
+				t.Errorf("appendMsgpack() header = % x, want % x", buf.Bytes()[:len(tt.expected)], tt.expected)
+			}
+		})
+	}
+}
+
+func TestWriteMsgpackItems(t *testing.T) {
+	items := []QuizItem{
+		{ID: "q1", Question: "日本の首都は？", Answer: "東京", Difficulty: 2, Tags: []string{"地理"}, Criteria: map[string][]string{"ok": {"とうきょう"}}},
+		{Question: "1+1は？", Answer: "2"},
+	}
+	f, err := LookupFormatter("msgpack")
+	if err != nil {
+		t.Fatalf("LookupFormatter() error = %v", err)
+	}
+	var buf bytes.Buffer
+	if err := f.Format(&buf, items, ConvertOptions{AssignIDs: true}); err != nil {
+		t.Fatalf("Format() error = %v", err)
+	}
+	r := bytes.NewReader(buf.Bytes())
+	got, err := decodeMsgpack(r)
+	if err != nil {
+		t.Fatalf("decodeMsgpack() error = %v", err)
+	}
+	if r.Len() != 0 {
+		t.Errorf("%d trailing bytes", r.Len())
+	}
+
+	// JSON出力と同じ内容になる
+	var jsonBuf bytes.Buffer
+	if err := writeJSONItems(&jsonBuf, items, ConvertOptions{AssignIDs: true}); err != nil {
+		t.Fatalf("writeJSONItems() error = %v", err)
+	}
+	var want any
+	dec := json.NewDecoder(&jsonBuf)
+	dec.UseNumber()
+	if err := dec.Decode(&want); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, normalizeJSONNumbers(want)) {
+		t.Errorf("decoded = %#v, want %#v", got, want)
+	}
+
+	buf.Reset()
+	if err := f.Format(&buf, nil, ConvertOptions{}); err != nil {
+		t.Fatalf("Format() error = %v", err)
+	}
+	if !bytes.Equal(buf.Bytes(), []byte{0x90}) {
+		t.Errorf("Format(nil) = % x, want 90", buf.Bytes())
+	}
+}
+
+// normalizeJSONNumbers はjson.Numberをint64に変換する（テストの問題データの数値は整数のみ）．
+func normalizeJSONNumbers(v any) any {
+	switch v := v.(type) {
+	case json.Number:
+		n, _ := v.Int64()
+		return n
+	case []any:
+		for i := range v {
+			v[i] = normalizeJSONNumbers(v[i])
+		}
+	case map[string]any:
+		for k := range v {
+			v[k] = normalizeJSONNumbers(v[k])
+		}
+	}
+	return v
+}
+
+// decodeMsgpack はテスト用にwriteMsgpackItemsが使う形式のMessagePackをデコードする．
+func decodeMsgpack(r *bytes.Reader) (any, error) {
+	b, err := r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	readN := func(n int) []byte {
+		p := make([]byte, n)
+		r.Read(p)
+		return p
+	}
+	length := func(size int) int {
+		p := readN(size)
+		switch size {
+		case 1:
+			return int(p[0])
+		case 2:
+			return int(binary.BigEndian.Uint16(p))
+		default:
+			return int(binary.BigEndian.Uint32(p))
+		}
+	}
+	switch {
+	case b <= 0x7f:
+		return int64(b), nil
+	case b >= 0xe0:
+		return int64(int8(b)), nil
+	case b&0xe0 == 0xa0:
+		return string(readN(int(b & 0x1f))), nil
+	case b&0xf0 == 0x90:
+		return decodeMsgpackArray(r, int(b&0x0f))
+	case b&0xf0 == 0x80:
+		return decodeMsgpackMap(r, int(b&0x0f))
+	}
+	switch b {
+	case 0xc0:
+		return nil, nil
+	case 0xc2:
+		return false, nil
+	case 0xc3:
+		return true, nil
+	case 0xd0:
+		return int64(int8(readN(1)[0])), nil
+	case 0xd1:
+		return int64(int16(binary.BigEndian.Uint16(readN(2)))), nil
+	case 0xd2:
+		return int64(int32(binary.BigEndian.Uint32(readN(4)))), nil
+	case 0xd3:
+		return int64(binary.BigEndian.Uint64(readN(8))), nil
+	case 0xcb:
+		return math.Float64frombits(binary.BigEndian.Uint64(readN(8))), nil
+	case 0xd9:
+		return string(readN(length(1))), nil
+	case 0xda:
+		return string(readN(length(2))), nil
+	case 0xdb:
+		return string(readN(length(4))), nil
+	case 0xdc:
+		return decodeMsgpackArray(r, length(2))
+	case 0xdd:
+		return decodeMsgpackArray(r, length(4))
+	case 0xde:
+		return decodeMsgpackMap(r, length(2))
+	case 0xdf:
+		return decodeMsgpackMap(r, length(4))
+	}
+	return nil, fmt.Errorf("unexpected byte %#x", b)
+}
+
+func decodeMsgpackArray(r *bytes.Reader, n int) (any, error) {
+	a := make([]any, n)
+	for i := range a {
+		v, err := decodeMsgpack(r)
+		if err != nil {
+			return nil, err
+		}
+		a[i] = v
+	}
+	return a, nil
+}
+
+func decodeMsgpackMap(r *bytes.Reader, n int) (any, error) {
+	m := make(map[string]any, n)
+	for range n {
+		k, err := decodeMsgpack(r)
+		if err != nil {
+			return nil, err
+		}
+		key, ok := k.(string)
+		if !ok {
+			return nil, fmt.Errorf("map key %v is not a string", k)
+		}
+		if m[key], err = decodeMsgpack(r); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
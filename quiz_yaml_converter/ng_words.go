@@ -0,0 +1,114 @@
+package quiz_yaml_converter
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// RuleNGWord は問題文やコメントに禁止語が含まれていることを表す警告の規則名．
+const RuleNGWord = "ng-word"
+
+// NGWords は問題文やコメントに含めてはならない語（放送やイベントで避けたい表現など）のリスト．
+type NGWords []string
+
+// ParseNGWords は禁止語のリストを読み込む．1行に1語を書き，空行と#で始まる行は無視する．
+// 前後の空白は取り除き，重複した語は1つにまとめる．
+func ParseNGWords(r io.Reader) (NGWords, error) {
+	var words NGWords
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		word := strings.TrimSpace(strings.TrimPrefix(scanner.Text(), utf8BOM))
+		if word == "" || strings.HasPrefix(word, "#") || slices.Contains(words, word) {
+			continue
+		}
+		words = append(words, word)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return words, nil
+}
+
+// LoadNGWords は禁止語のリストのファイルを読み込み，すべてのファイルの語をまとめて返す．
+func LoadNGWords(paths []string) (NGWords, error) {
+	return loadWordLists(paths, "NG word list")
+}
+
+// LoadStopwords は用語集に含めない語（GlossaryOptions.Stopwords）のリストのファイルを読み込み，
+// すべてのファイルの語をまとめて返す．ファイルの形式は禁止語のリスト（ParseNGWords参照）と同じ．
+func LoadStopwords(paths []string) ([]string, error) {
+	return loadWordLists(paths, "stopword list")
+}
+
+// loadWordLists は1行に1語を書いたリストのファイルを読み込み，すべてのファイルの語をまとめて返す．
+// kindはエラーメッセージに使うリストの種類．
+func loadWordLists(paths []string, kind string) ([]string, error) {
+	var words []string
+	for _, path := range paths {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open %s: %w", kind, err)
+		}
+		list, err := ParseNGWords(f)
+		f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s %s: %w", kind, path, err)
+		}
+		for _, word := range list {
+			if !slices.Contains(words, word) {
+				words = append(words, word)
+			}
+		}
+	}
+	return words, nil
+}
+
+// NGWordMatch はテキスト中で見つかった禁止語．
+type NGWordMatch struct {
+	Word     string // 一致した禁止語
+	Position int    // 一致した位置（1始まりの文字数）
+}
+
+// Find はtextに含まれる禁止語を，現れた位置の順に返す．英字の大文字と小文字は区別しない．
+// 同じ語が複数回現れる場合は，それぞれの位置を返す．
+func (w NGWords) Find(text string) []NGWordMatch {
+	runes := []rune(strings.Map(unicode.ToLower, text))
+	var matches []NGWordMatch
+	for _, word := range w {
+		target := []rune(strings.Map(unicode.ToLower, word))
+		if len(target) == 0 {
+			continue
+		}
+		for i := 0; i+len(target) <= len(runes); i++ {
+			if slices.Equal(runes[i:i+len(target)], target) {
+				matches = append(matches, NGWordMatch{Word: word, Position: i + 1})
+			}
+		}
+	}
+	slices.SortStableFunc(matches, func(a, b NGWordMatch) int { return a.Position - b.Position })
+	return matches
+}
+
+// NGWordWarnings は問題文とコメントに含まれる禁止語についての警告を返す．
+// 警告は一致した箇所ごとに1件とし，フィールドと何文字目に含まれているかを示す．
+func NGWordWarnings(data []QuizItem, words NGWords) []ValidationError {
+	var warnings []ValidationError
+	for i, item := range data {
+		fields := []struct{ field, text string }{{"question", item.Question}}
+		for j, comment := range item.Comments {
+			fields = append(fields, struct{ field, text string }{fmt.Sprintf("comments[%d]", j), comment})
+		}
+		for _, f := range fields {
+			for _, m := range words.Find(f.text) {
+				warnings = append(warnings, newValidationError(i+1, f.field, RuleNGWord, nil,
+					"%s の%d文字目に禁止語「%s」が含まれています", f.field, m.Position, m.Word))
+			}
+		}
+	}
+	return warnings
+}
@@ -0,0 +1,88 @@
+package quiz_yaml_converter
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseNGWords(t *testing.T) {
+	input := utf8BOM + "# 放送で避ける語\n禁止語\n\n  NG  \n禁止語\n#コメント\n"
+	words, err := ParseNGWords(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ParseNGWords() error = %v", err)
+	}
+	if want := (NGWords{"禁止語", "NG"}); !reflect.DeepEqual(words, want) {
+		t.Errorf("ParseNGWords() = %q, want %q", words, want)
+	}
+}
+
+func TestLoadNGWords(t *testing.T) {
+	tempDir := t.TempDir()
+	first := filepath.Join(tempDir, "first.txt")
+	second := filepath.Join(tempDir, "second.txt")
+	if err := os.WriteFile(first, []byte("禁止語\nNG\n"), 0644); err != nil {
+		t.Fatalf("Failed to create NG word list: %v", err)
+	}
+	if err := os.WriteFile(second, []byte("NG\n不適切\n"), 0644); err != nil {
+		t.Fatalf("Failed to create NG word list: %v", err)
+	}
+	words, err := LoadNGWords([]string{first, second})
+	if err != nil {
+		t.Fatalf("LoadNGWords() error = %v", err)
+	}
+	if want := (NGWords{"禁止語", "NG", "不適切"}); !reflect.DeepEqual(words, want) {
+		t.Errorf("LoadNGWords() = %q, want %q", words, want)
+	}
+	if _, err := LoadNGWords([]string{filepath.Join(tempDir, "missing.txt")}); err == nil {
+		t.Error("LoadNGWords() with a missing file expected error")
+	}
+}
+
+func TestNGWords_Find(t *testing.T) {
+	words := NGWords{"禁止語", "ng", "禁止"}
+	tests := []struct {
+		text     string
+		expected []NGWordMatch
+	}{
+		{"問題文です", nil},
+		{"この禁止語は", []NGWordMatch{{"禁止語", 3}, {"禁止", 3}}},
+		{"NGとngとNg", []NGWordMatch{{"ng", 1}, {"ng", 4}, {"ng", 7}}},
+		{"", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			if got := words.Find(tt.text); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Find(%q) = %+v, want %+v", tt.text, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestNGWordWarnings(t *testing.T) {
+	words := NGWords{"禁止語"}
+	data := []QuizItem{
+		{Question: "問題文です", Answer: "答え"},
+		{Question: "禁止語を含む問題文", Answer: "禁止語", Comments: []string{"補足", "ここにも禁止語"}},
+	}
+	warnings := NGWordWarnings(data, words)
+	var got []string
+	for _, w := range warnings {
+		if w.Index != 2 || w.Rule != RuleNGWord {
+			t.Errorf("warning = %+v, want a %s warning for item 2", w, RuleNGWord)
+		}
+		got = append(got, w.Field+": "+w.Message)
+	}
+	expected := []string{
+		"question: question の1文字目に禁止語「禁止語」が含まれています",
+		"comments[1]: comments[1] の5文字目に禁止語「禁止語」が含まれています",
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("NGWordWarnings() = %q, want %q", got, expected)
+	}
+	if en := warnings[0].LocalizedMessage(LanguageEnglish); en != `question contains an NG word at character 1: "禁止語"` {
+		t.Errorf("LocalizedMessage(en) = %q", en)
+	}
+}
@@ -0,0 +1,88 @@
+package quiz_yaml_converter
+
+import (
+	"fmt"
+	"strings"
+)
+
+// 問題番号の振り直しの単位
+const (
+	NumberSectionNone     = ""         // 通し番号
+	NumberSectionGenre    = "genre"    // ジャンルが変わるたびに振り直す
+	NumberSectionDocument = "document" // YAMLのドキュメント（---区切り）が変わるたびに振り直す
+)
+
+// MaxNumberWidth は問題番号をゼロ埋めする桁数の上限．
+// 桁数に応じて出力が大きくなるため，サーバーのように外部から指定される場合にも上限を設ける．
+const MaxNumberWidth = 10
+
+// 問題番号の付け方に関するオプション
+type NumberingOptions struct {
+	// 最初の問題の番号．nilの場合は1から始まる（NewNumberingOptions参照）．
+	Start *int
+
+	// 番号をゼロ埋めする桁数．0の場合はゼロ埋めしない（上限はMaxNumberWidth）．
+	Width int
+
+	// 番号を振り直す単位（NumberSectionNone, NumberSectionGenre, NumberSectionDocument）．
+	// 振り直す場合は「セクション番号-セクション内の番号」（例: 1-1, 1-2, 2-1）となる．
+	SectionBy string
+}
+
+// NewNumberingOptions は最初の問題の番号をstartとしたNumberingOptionsを作成する．
+// 0から始める（Q0, Q1, …）こともできる．
+func NewNumberingOptions(start int) NumberingOptions {
+	return NumberingOptions{Start: &start}
+}
+
+// ParseNumberSection は-number-byで指定された振り直しの単位を検証する．
+func ParseNumberSection(name string) (string, error) {
+	switch strings.ToLower(name) {
+	case "", "none":
+		return NumberSectionNone, nil
+	case NumberSectionGenre:
+		return NumberSectionGenre, nil
+	case NumberSectionDocument:
+		return NumberSectionDocument, nil
+	default:
+		return "", fmt.Errorf("unsupported numbering section: %q (available: none, genre, document)", name)
+	}
+}
+
+// QuestionNumbers は各問題の番号をdataと同じ順序で返す．
+// セクションは連続する問題のまとまりとして扱い，同じジャンルでも
+// 間に別のジャンルが挟まれている場合は別のセクションとなる．
+func QuestionNumbers(data []QuizItem, opts NumberingOptions) []string {
+	start := 1
+	if opts.Start != nil {
+		start = *opts.Start
+	}
+
+	numbers := make([]string, len(data))
+	section, n := 0, start
+	for i, item := range data {
+		if opts.SectionBy != NumberSectionNone && (i == 0 || !sameSection(item, data[i-1], opts.SectionBy)) {
+			section++
+			n = start
+		}
+		number := fmt.Sprintf("%0*d", opts.Width, n)
+		if opts.SectionBy != NumberSectionNone {
+			number = fmt.Sprintf("%d-%s", section, number)
+		}
+		numbers[i] = number
+		n++
+	}
+	return numbers
+}
+
+// sameSection は2つの問題が同じセクションに含まれるかを返す．
+func sameSection(a, b QuizItem, sectionBy string) bool {
+	switch sectionBy {
+	case NumberSectionGenre:
+		return a.Genre == b.Genre
+	case NumberSectionDocument:
+		return a.Document == b.Document
+	default:
+		return true
+	}
+}
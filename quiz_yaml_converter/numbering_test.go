@@ -0,0 +1,68 @@
+package quiz_yaml_converter
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestQuestionNumbers(t *testing.T) {
+	data := []QuizItem{
+		{Question: "Q1", Genre: "地理", Document: 1},
+		{Question: "Q2", Genre: "地理", Document: 1},
+		{Question: "Q3", Genre: "歴史", Document: 1},
+		{Question: "Q4", Genre: "地理", Document: 2},
+	}
+
+	tests := []struct {
+		name     string
+		opts     NumberingOptions
+		expected []string
+	}{
+		{"default", NumberingOptions{}, []string{"1", "2", "3", "4"}},
+		{"start", NewNumberingOptions(101), []string{"101", "102", "103", "104"}},
+		{"start from zero", NewNumberingOptions(0), []string{"0", "1", "2", "3"}},
+		{"width", NumberingOptions{Width: 3}, []string{"001", "002", "003", "004"}},
+		{"by genre", NumberingOptions{SectionBy: NumberSectionGenre}, []string{"1-1", "1-2", "2-1", "3-1"}},
+		{"by genre with width", NumberingOptions{SectionBy: NumberSectionGenre, Width: 2}, []string{"1-01", "1-02", "2-01", "3-01"}},
+		{"by document", NumberingOptions{SectionBy: NumberSectionDocument}, []string{"1-1", "1-2", "1-3", "2-1"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := QuestionNumbers(data, tt.opts); !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("QuestionNumbers() = %q, want %q", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParseNumberSection(t *testing.T) {
+	for _, name := range []string{"", "none", "genre", "GENRE", "document"} {
+		if _, err := ParseNumberSection(name); err != nil {
+			t.Errorf("ParseNumberSection(%q) error = %v", name, err)
+		}
+	}
+	if _, err := ParseNumberSection("tag"); err == nil {
+		t.Error("ParseNumberSection(\"tag\") expected error")
+	}
+}
+
+func TestWriteTemplate_Numbers(t *testing.T) {
+	templatePath := filepath.Join(t.TempDir(), "numbers.tmpl")
+	if err := os.WriteFile(templatePath, []byte(`{{range $i, $item := .Items}}{{index $.Numbers $i}}.{{.Question}} {{end}}`), 0644); err != nil {
+		t.Fatalf("Failed to create template file: %v", err)
+	}
+	data := []QuizItem{{Question: "Q1", Genre: "A"}, {Question: "Q2", Genre: "B"}}
+
+	var b strings.Builder
+	opts := ConvertOptions{Numbering: NumberingOptions{SectionBy: NumberSectionGenre}}
+	if err := WriteTemplate(&b, data, templatePath, opts); err != nil {
+		t.Fatalf("WriteTemplate() error = %v", err)
+	}
+	if expected := "1-1.Q1 2-1.Q2 "; b.String() != expected {
+		t.Errorf("WriteTemplate() = %q, want %q", b.String(), expected)
+	}
+}
@@ -0,0 +1,172 @@
+package quiz_yaml_converter
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ParseConvertOptions はHTTPのクエリパラメータのような名前と値の組から変換オプションを組み立てる．
+// パラメータ名はコマンドラインのフラグ名（columns, encoding, number-byなど）に対応し，
+// 値が複数ある場合は最初のものを使用する．指定されていないオプションは既定値となる．
+// require-sources，fix-whitespace，fix-punctuation（punctuationの表記に修正），split-answer，cloze，filter，sortを指定した場合は，
+// 出典の確認，空白の修正，句読点の修正，答えの別解の分割，穴埋めの作成，絞り込み，並べ替えのStageをこの順にPipelineに設定する．
+func ParseConvertOptions(params map[string][]string) (ConvertOptions, error) {
+	var opts ConvertOptions
+	get := func(key string) string {
+		if v := params[key]; len(v) > 0 {
+			return v[0]
+		}
+		return ""
+	}
+	boolParam := func(key string) (bool, error) {
+		v := get(key)
+		if v == "" {
+			return false, nil
+		}
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return false, fmt.Errorf("invalid value for %s: %q", key, v)
+		}
+		return b, nil
+	}
+
+	var err error
+	var requireSources, fixWhitespace, fixPunctuation, splitAnswer, cloze bool
+	for key, dst := range map[string]*bool{
+		"require-sources":         &requireSources,
+		"fix-whitespace":          &fixWhitespace,
+		"fix-punctuation":         &fixPunctuation,
+		"split-answer":            &splitAnswer,
+		"cloze":                   &cloze,
+		"preserve-criteria-order": &opts.PreserveCriteriaOrder,
+		"assign-ids":              &opts.AssignIDs,
+		"gojuon-index":            &opts.GojuonIndex,
+		"glossary":                &opts.Glossary.Enabled,
+		"comments":                &opts.CSV.IncludeComments,
+		"no-header":               &opts.CSV.NoHeader,
+		"crlf":                    &opts.CSV.CRLF,
+		"quote-all":               &opts.CSV.AlwaysQuote,
+		"escape-formulas":         &opts.CSV.EscapeFormulas,
+	} {
+		if *dst, err = boolParam(key); err != nil {
+			return opts, err
+		}
+	}
+	opts.CSV.CommentSeparator = get("comment-sep")
+	opts.CSV.CriteriaSeparator = get("criteria-sep")
+	opts.CSV.CriteriaItemSeparator = get("criteria-item-sep")
+	opts.QRURL = get("qr-url")
+	if v := get("glossary-stopwords"); v != "" {
+		opts.Glossary.Stopwords = strings.Split(v, ",")
+	}
+	if v := get("columns"); v != "" {
+		if opts.CSV.Columns, err = ParseCSVColumns(v); err != nil {
+			return opts, err
+		}
+	}
+	if v := get("text-fields"); v != "" {
+		if opts.Text.Fields, err = ParseTextFields(v); err != nil {
+			return opts, err
+		}
+	}
+	opts.Text.Separator = get("text-sep")
+	if v := get("header-labels"); v != "" {
+		if opts.CSV.HeaderLabels, err = ParseCSVHeaderLabels(v); err != nil {
+			return opts, err
+		}
+	}
+	if opts.CSV.Encoding, err = ParseEncoding(get("encoding")); err != nil {
+		return opts, err
+	}
+	if v := get("newlines"); v != "" {
+		if opts.CSV.Newlines, err = ParseNewlineMode(v); err != nil {
+			return opts, err
+		}
+	}
+	if v := get("number-start"); v != "" {
+		start, err := strconv.Atoi(v)
+		if err != nil {
+			return opts, fmt.Errorf("invalid value for number-start: %q", v)
+		}
+		opts.Numbering.Start = &start
+	}
+	for key, dst := range map[string]*int{
+		"number-width": &opts.Numbering.Width,
+		"glossary-min": &opts.Glossary.MinQuestions,
+	} {
+		if v := get(key); v != "" {
+			if *dst, err = strconv.Atoi(v); err != nil {
+				return opts, fmt.Errorf("invalid value for %s: %q", key, v)
+			}
+		}
+	}
+	if v := get("criteria-locale"); v != "" {
+		if opts.CriteriaLanguage, err = ParseLanguage(v); err != nil {
+			return opts, err
+		}
+	}
+	if v := get("quotes"); v != "" {
+		if opts.Quotes, err = ParseQuotes(v); err != nil {
+			return opts, err
+		}
+	}
+	if v := get("reading-pace"); v != "" {
+		if opts.ReadingPace, err = strconv.ParseFloat(v, 64); err != nil || opts.ReadingPace <= 0 {
+			return opts, fmt.Errorf("invalid value for reading-pace: %q", v)
+		}
+	}
+	if v := get("slug-from"); v != "" {
+		if opts.SlugFrom, err = ParseSlugSource(v); err != nil {
+			return opts, err
+		}
+	}
+	if v := get("ruby-style"); v != "" {
+		if opts.RubyStyle, err = ParseRubyStyle(v); err != nil {
+			return opts, err
+		}
+	}
+	if opts.Numbering.Width < 0 || opts.Numbering.Width > MaxNumberWidth {
+		return opts, fmt.Errorf("invalid value for number-width: %d (must be between 0 and %d)", opts.Numbering.Width, MaxNumberWidth)
+	}
+	if opts.Numbering.SectionBy, err = ParseNumberSection(get("number-by")); err != nil {
+		return opts, err
+	}
+	if requireSources {
+		opts.Pipeline = append(opts.Pipeline, RequireSources)
+	}
+	if fixWhitespace {
+		opts.Pipeline = append(opts.Pipeline, FixWhitespace)
+	}
+	if fixPunctuation {
+		style, err := ParsePunctuationStyle(get("punctuation"))
+		if err != nil {
+			return opts, err
+		}
+		if style.IsZero() {
+			return opts, fmt.Errorf("fix-punctuation requires punctuation")
+		}
+		opts.Pipeline = append(opts.Pipeline, FixPunctuation(style))
+	}
+	if splitAnswer {
+		opts.Pipeline = append(opts.Pipeline, SplitAnswerAlternatives)
+	}
+	if cloze {
+		opts.Pipeline = append(opts.Pipeline, ClozeDeletion)
+	}
+	if v := get("filter"); v != "" {
+		expr, err := ParseFilter(v)
+		if err != nil {
+			return opts, err
+		}
+		opts.Pipeline = append(opts.Pipeline, expr.Stage())
+	}
+	sortKey, err := ParseSortKey(get("sort"))
+	if err != nil {
+		return opts, err
+	}
+	if sortKey != SortNone {
+		opts.Pipeline = append(opts.Pipeline, SortItems(sortKey))
+	}
+	return opts, nil
+}
@@ -0,0 +1,200 @@
+package quiz_yaml_converter
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestParseConvertOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		params  map[string][]string
+		want    ConvertOptions
+		wantErr bool
+	}{
+		{"empty", nil, ConvertOptions{CSV: CSVOptions{Encoding: EncodingUTF8}}, false},
+		{
+			"csv options",
+			map[string][]string{
+				"columns":           {"question,answer"},
+				"no-header":         {"true"},
+				"encoding":          {"sjis"},
+				"comment-sep":       {" / "},
+				"criteria-sep":      {"|"},
+				"criteria-item-sep": {";"},
+				"newlines":          {"escape"},
+			},
+			ConvertOptions{CSV: CSVOptions{
+				Columns:               []string{"question", "answer"},
+				NoHeader:              true,
+				Encoding:              EncodingShiftJIS,
+				CommentSeparator:      " / ",
+				CriteriaSeparator:     "|",
+				CriteriaItemSeparator: ";",
+				Newlines:              NewlinesEscape,
+			}},
+			false,
+		},
+		{
+			"numbering and first value",
+			map[string][]string{
+				"number-start": {"10", "20"},
+				"number-width": {"3"},
+				"number-by":    {"genre"},
+				"assign-ids":   {"1"},
+				"gojuon-index": {"true"},
+			},
+			ConvertOptions{AssignIDs: true, GojuonIndex: true, Numbering: NumberingOptions{Start: NewNumberingOptions(10).Start, Width: 3, SectionBy: NumberSectionGenre}, CSV: CSVOptions{Encoding: EncodingUTF8}},
+			false,
+		},
+		{
+			"criteria locale",
+			map[string][]string{"criteria-locale": {"en_US"}, "quotes": {"“,”"}, "reading-pace": {"6.5"}, "qr-url": {"https://example.com/{id}"}, "slug-from": {"answer"}, "ruby-style": {"paren"}},
+			ConvertOptions{CriteriaLanguage: LanguageEnglish, Quotes: [2]string{"“", "”"}, ReadingPace: 6.5, QRURL: "https://example.com/{id}", SlugFrom: SlugFromAnswer, RubyStyle: RubyParen, CSV: CSVOptions{Encoding: EncodingUTF8}},
+			false,
+		},
+		{
+			"glossary",
+			map[string][]string{"glossary": {"true"}, "glossary-min": {"3"}, "glossary-stopwords": {"ノーベル,受賞"}},
+			ConvertOptions{Glossary: GlossaryOptions{Enabled: true, MinQuestions: 3, Stopwords: []string{"ノーベル", "受賞"}}, CSV: CSVOptions{Encoding: EncodingUTF8}},
+			false,
+		},
+		{"invalid bool", map[string][]string{"crlf": {"yes"}}, ConvertOptions{}, true},
+		{"invalid glossary-min", map[string][]string{"glossary-min": {"two"}}, ConvertOptions{}, true},
+		{"invalid int", map[string][]string{"number-start": {"one"}}, ConvertOptions{}, true},
+		{"negative number-width", map[string][]string{"number-width": {"-3"}}, ConvertOptions{}, true},
+		{"too large number-width", map[string][]string{"number-width": {"1000000"}}, ConvertOptions{}, true},
+		{"invalid column", map[string][]string{"columns": {"unknown"}}, ConvertOptions{}, true},
+		{
+			"text options",
+			map[string][]string{"text-fields": {"genre, question,answer"}, "text-sep": {"\t"}},
+			ConvertOptions{CSV: CSVOptions{Encoding: EncodingUTF8}, Text: TextOptions{Fields: []string{"genre", "question", "answer"}, Separator: "\t"}},
+			false,
+		},
+		{"invalid text field", map[string][]string{"text-fields": {"question,question"}}, ConvertOptions{}, true},
+		{"invalid encoding", map[string][]string{"encoding": {"euc-jp"}}, ConvertOptions{}, true},
+		{"invalid newlines", map[string][]string{"newlines": {"strip"}}, ConvertOptions{}, true},
+		{"invalid criteria-locale", map[string][]string{"criteria-locale": {"fr"}}, ConvertOptions{}, true},
+		{"invalid quotes", map[string][]string{"quotes": {"「"}}, ConvertOptions{}, true},
+		{"invalid reading-pace", map[string][]string{"reading-pace": {"0"}}, ConvertOptions{}, true},
+		{"invalid slug-from", map[string][]string{"slug-from": {"title"}}, ConvertOptions{}, true},
+		{"invalid ruby-style", map[string][]string{"ruby-style": {"furigana"}}, ConvertOptions{}, true},
+		{"invalid number-by", map[string][]string{"number-by": {"tag"}}, ConvertOptions{}, true},
+		{"invalid filter", map[string][]string{"filter": {"genre =="}}, ConvertOptions{}, true},
+		{"invalid sort", map[string][]string{"sort": {"answer"}}, ConvertOptions{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseConvertOptions(tt.params)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseConvertOptions() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseConvertOptions() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseConvertOptions_Filter(t *testing.T) {
+	opts, err := ParseConvertOptions(map[string][]string{"filter": {`genre == "歴史"`}})
+	if err != nil {
+		t.Fatalf("ParseConvertOptions() error = %v", err)
+	}
+	got, err := opts.Pipeline.Apply([]QuizItem{{Question: "q1", Genre: "歴史"}, {Question: "q2", Genre: "地理"}})
+	if err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	if len(got) != 1 || got[0].Question != "q1" {
+		t.Errorf("Apply() = %+v, want only q1", got)
+	}
+}
+
+func TestParseConvertOptions_Sort(t *testing.T) {
+	opts, err := ParseConvertOptions(map[string][]string{"sort": {"reading"}, "filter": {`genre == "歴史"`}})
+	if err != nil {
+		t.Fatalf("ParseConvertOptions() error = %v", err)
+	}
+	got, err := opts.Pipeline.Apply([]QuizItem{
+		{Question: "q1", Answer: "徳川家康", Reading: "とくがわいえやす", Genre: "歴史"},
+		{Question: "q2", Answer: "ナイル川", Genre: "地理"},
+		{Question: "q3", Answer: "織田信長", Reading: "おだのぶなが", Genre: "歴史"},
+	})
+	if err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	if len(got) != 2 || got[0].Question != "q3" || got[1].Question != "q1" {
+		t.Errorf("Apply() = %+v, want q3 and q1 in this order", got)
+	}
+}
+
+func TestParseConvertOptions_SplitAnswer(t *testing.T) {
+	opts, err := ParseConvertOptions(map[string][]string{"split-answer": {"true"}, "filter": {`criteria.ok == "国連"`}})
+	if err != nil {
+		t.Fatalf("ParseConvertOptions() error = %v", err)
+	}
+	got, err := opts.Pipeline.Apply([]QuizItem{{Question: "q1", Answer: "国際連合（国連）"}, {Question: "q2", Answer: "国際連盟"}})
+	if err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	if len(got) != 1 || got[0].Answer != "国際連合" {
+		t.Errorf("Apply() = %+v, want only q1 with answer 国際連合", got)
+	}
+}
+
+func TestParseConvertOptions_Cloze(t *testing.T) {
+	opts, err := ParseConvertOptions(map[string][]string{"cloze": {"true"}, "split-answer": {"true"}})
+	if err != nil {
+		t.Fatalf("ParseConvertOptions() error = %v", err)
+	}
+	got, err := opts.Pipeline.Apply([]QuizItem{{Question: "国連の本部はニューヨークにある", Answer: "国際連合（国連）"}})
+	if err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	if got[0].Question != "{{c1::国連}}の本部はニューヨークにある" {
+		t.Errorf("Question = %q, want the alternative split from the answer turned into a cloze", got[0].Question)
+	}
+}
+
+func TestParseConvertOptions_RequireSources(t *testing.T) {
+	opts, err := ParseConvertOptions(map[string][]string{"require-sources": {"true"}})
+	if err != nil {
+		t.Fatalf("ParseConvertOptions() error = %v", err)
+	}
+	if _, err := opts.Pipeline.Apply([]QuizItem{{Question: "q1", Answer: "a1"}}); !errors.Is(err, ErrMissingSource) {
+		t.Errorf("Apply() error = %v, want ErrMissingSource", err)
+	}
+}
+
+func TestParseConvertOptions_FixWhitespace(t *testing.T) {
+	opts, err := ParseConvertOptions(map[string][]string{"fix-whitespace": {"true"}, "split-answer": {"true"}})
+	if err != nil {
+		t.Fatalf("ParseConvertOptions() error = %v", err)
+	}
+	got, err := opts.Pipeline.Apply([]QuizItem{{Question: "q1 ", Answer: "国際連合（国連） "}})
+	if err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	if got[0].Question != "q1" || got[0].Answer != "国際連合" || !reflect.DeepEqual(got[0].Criteria["ok"], []string{"国連"}) {
+		t.Errorf("Apply() = %+v, want whitespace fixed before splitting the answer", got[0])
+	}
+}
+
+func TestParseConvertOptions_FixPunctuation(t *testing.T) {
+	opts, err := ParseConvertOptions(map[string][]string{"fix-punctuation": {"true"}, "punctuation": {"academic"}})
+	if err != nil {
+		t.Fatalf("ParseConvertOptions() error = %v", err)
+	}
+	got, err := opts.Pipeline.Apply([]QuizItem{{Question: "はい、そうです。", Answer: "a"}})
+	if err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	if got[0].Question != "はい，そうです．" {
+		t.Errorf("Question = %q, want はい，そうです．", got[0].Question)
+	}
+
+	if _, err := ParseConvertOptions(map[string][]string{"fix-punctuation": {"true"}}); err == nil {
+		t.Error("ParseConvertOptions() without punctuation expected error")
+	}
+}
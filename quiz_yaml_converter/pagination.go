@@ -0,0 +1,141 @@
+package quiz_yaml_converter
+
+import (
+	"embed"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"text/template"
+)
+
+// ページ分割したHTMLと，問題用紙・解答の組の組み込みレイアウト
+//
+//go:embed layouts/page.html layouts/index.html layouts/questions.html layouts/answer_key.html
+var layoutFS embed.FS
+
+// ページ分割時の目次ページのファイル名
+const PaginationIndexFile = "index.html"
+
+// PageInfo はページ分割した出力の1ページ分の情報．
+// ページのテンプレートでは.Page，目次のテンプレートでは.Pagesの要素として参照できる．
+type PageInfo struct {
+	Number int    // ページ番号（1から始まる）
+	Total  int    // 総ページ数
+	File   string // このページのファイル名
+	Index  string // 目次ページのファイル名
+	Prev   string // 前のページのファイル名（先頭ページでは空）
+	Next   string // 次のページのファイル名（最終ページでは空）
+	First  string // このページの最初の問題番号
+	Last   string // このページの最後の問題番号
+
+	start, end int // dataの中でこのページに含まれる範囲
+}
+
+// pageFileName はページ番号に対応するファイル名を返す．
+func pageFileName(number int) string {
+	return fmt.Sprintf("page-%d.html", number)
+}
+
+// Paginate は問題データをperPage問ずつのページに分割した情報を返す．
+// numbersにはQuestionNumbersで求めた各問題の番号を渡す．
+func Paginate(numbers []string, perPage int) []PageInfo {
+	if perPage <= 0 || len(numbers) == 0 {
+		return nil
+	}
+	total := (len(numbers) + perPage - 1) / perPage
+	pages := make([]PageInfo, total)
+	for i := range pages {
+		start := i * perPage
+		end := min(start+perPage, len(numbers))
+		page := PageInfo{
+			Number: i + 1,
+			Total:  total,
+			File:   pageFileName(i + 1),
+			Index:  PaginationIndexFile,
+			First:  numbers[start],
+			Last:   numbers[end-1],
+			start:  start,
+			end:    end,
+		}
+		if i > 0 {
+			page.Prev = pageFileName(i)
+		}
+		if i+1 < total {
+			page.Next = pageFileName(i + 2)
+		}
+		pages[i] = page
+	}
+	return pages
+}
+
+// ConvertToPaginatedHTML は問題データをperPage問ずつのHTMLに分割し，
+// outputDirに目次（index.html）と各ページ（page-N.html）を書き出す．
+// templateFilePathが空の場合は組み込みのレイアウトを使用する．
+// 指定した場合は，そのテンプレートを各ページに適用する（目次は組み込みのレイアウト）．
+func ConvertToPaginatedHTML(data []QuizItem, outputDir, templateFilePath string, perPage int, opts ConvertOptions) error {
+	if perPage <= 0 {
+		return fmt.Errorf("invalid number of items per page: %d", perPage)
+	}
+
+	var pageTmpl *template.Template
+	var err error
+	if templateFilePath == "" {
+		pageTmpl, err = parseLayout("layouts/page.html", opts)
+	} else {
+		pageTmpl, err = parseTemplateFile(templateFilePath, opts)
+	}
+	if err != nil {
+		return err
+	}
+	indexTmpl, err := parseLayout("layouts/index.html", opts)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	if data, err = opts.Pipeline.Apply(data); err != nil {
+		return err
+	}
+	data = withAssignedIDs(data, opts)
+	numbers := QuestionNumbers(data, opts.Numbering)
+	pages := Paginate(numbers, perPage)
+	for i := range pages {
+		page := &pages[i]
+		td := TemplateData{
+			Items:   data[page.start:page.end],
+			Numbers: numbers[page.start:page.end],
+			Vars:    opts.Vars,
+			Page:    page,
+		}
+		if err := writeTemplateFile(filepath.Join(outputDir, page.File), pageTmpl, td, opts); err != nil {
+			return err
+		}
+	}
+
+	td := TemplateData{Items: data, Numbers: numbers, Vars: opts.Vars, Pages: pages, ShowGojuonIndex: opts.GojuonIndex}
+	return writeTemplateFile(filepath.Join(outputDir, PaginationIndexFile), indexTmpl, td, opts)
+}
+
+// parseLayout は組み込みのレイアウトをカスタム関数付きで解析する．
+func parseLayout(name string, opts ConvertOptions) (*template.Template, error) {
+	content, err := layoutFS.ReadFile(name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read layout: %w", err)
+	}
+	tmpl, err := template.New(filepath.Base(name)).Funcs(templateFuncs(opts)).Parse(string(content))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrTemplateParse, err)
+	}
+	return tmpl, nil
+}
+
+// writeTemplateFile はテンプレートにtdを適用した結果をpathに書き出す．
+func writeTemplateFile(path string, tmpl *template.Template, td TemplateData, opts ConvertOptions) error {
+	return writeFileAtomic(path, opts.NoClobber, func(w io.Writer) error {
+		return runTemplate(w, tmpl, td, opts)
+	})
+}
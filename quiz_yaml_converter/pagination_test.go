@@ -0,0 +1,111 @@
+package quiz_yaml_converter
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPaginate(t *testing.T) {
+	numbers := []string{"1", "2", "3", "4", "5"}
+	pages := Paginate(numbers, 2)
+
+	if len(pages) != 3 {
+		t.Fatalf("Paginate() returned %d pages, want 3", len(pages))
+	}
+
+	tests := []struct {
+		page                          PageInfo
+		file, prev, next, first, last string
+	}{
+		{pages[0], "page-1.html", "", "page-2.html", "1", "2"},
+		{pages[1], "page-2.html", "page-1.html", "page-3.html", "3", "4"},
+		{pages[2], "page-3.html", "page-2.html", "", "5", "5"},
+	}
+	for i, tt := range tests {
+		p := tt.page
+		if p.Number != i+1 || p.Total != 3 || p.Index != PaginationIndexFile {
+			t.Errorf("page %d: Number=%d Total=%d Index=%q", i+1, p.Number, p.Total, p.Index)
+		}
+		if p.File != tt.file || p.Prev != tt.prev || p.Next != tt.next || p.First != tt.first || p.Last != tt.last {
+			t.Errorf("page %d = %+v", i+1, p)
+		}
+	}
+}
+
+func TestPaginate_Empty(t *testing.T) {
+	if pages := Paginate(nil, 10); pages != nil {
+		t.Errorf("Paginate(nil) = %v, want nil", pages)
+	}
+	if pages := Paginate([]string{"1"}, 0); pages != nil {
+		t.Errorf("Paginate(perPage=0) = %v, want nil", pages)
+	}
+}
+
+func TestConvertToPaginatedHTML(t *testing.T) {
+	outputDir := filepath.Join(t.TempDir(), "site")
+	data := []QuizItem{
+		{Question: "問題1", Answer: "答え1"},
+		{Question: "問題2", Answer: "答え2"},
+		{Question: "問題3", Answer: "答え3"},
+	}
+
+	if err := ConvertToPaginatedHTML(data, outputDir, "", 2, ConvertOptions{}); err != nil {
+		t.Fatalf("ConvertToPaginatedHTML() error = %v", err)
+	}
+
+	checks := map[string][]string{
+		"index.html":  {`href="page-1.html"`, `href="page-2.html"`, "<strong>3</strong>問（2ページ）"},
+		"page-1.html": {"問題1", "問題2", `href="page-2.html"`},
+		"page-2.html": {"Q3:", "問題3", `href="page-1.html"`},
+	}
+	for name, wants := range checks {
+		content, err := os.ReadFile(filepath.Join(outputDir, name))
+		if err != nil {
+			t.Fatalf("Failed to read %s: %v", name, err)
+		}
+		for _, want := range wants {
+			if !strings.Contains(string(content), want) {
+				t.Errorf("%s does not contain %q", name, want)
+			}
+		}
+	}
+	if content, _ := os.ReadFile(filepath.Join(outputDir, "page-2.html")); strings.Contains(string(content), "問題1") {
+		t.Error("page-2.html should not contain items of page 1")
+	}
+}
+
+func TestConvertToPaginatedHTML_CustomTemplate(t *testing.T) {
+	tempDir := t.TempDir()
+	templateFile := filepath.Join(tempDir, "page.tmpl")
+	if err := os.WriteFile(templateFile, []byte(`{{.Page.Number}}/{{.Page.Total}}:{{range .Items}}{{.Question}};{{end}}`), 0644); err != nil {
+		t.Fatalf("Failed to create template file: %v", err)
+	}
+	outputDir := filepath.Join(tempDir, "site")
+	data := []QuizItem{{Question: "q1"}, {Question: "q2"}, {Question: "q3"}}
+
+	if err := ConvertToPaginatedHTML(data, outputDir, templateFile, 2, ConvertOptions{}); err != nil {
+		t.Fatalf("ConvertToPaginatedHTML() error = %v", err)
+	}
+	content, err := os.ReadFile(filepath.Join(outputDir, "page-2.html"))
+	if err != nil {
+		t.Fatalf("Failed to read page-2.html: %v", err)
+	}
+	if string(content) != "2/2:q3;" {
+		t.Errorf("page-2.html = %q, want %q", content, "2/2:q3;")
+	}
+}
+
+func TestConvertToPaginatedHTML_NoClobber(t *testing.T) {
+	outputDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(outputDir, "index.html"), []byte("existing"), 0644); err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+
+	err := ConvertToPaginatedHTML([]QuizItem{{Question: "q1"}}, outputDir, "", 10, ConvertOptions{NoClobber: true})
+	if !errors.Is(err, ErrOutputExists) {
+		t.Errorf("ConvertToPaginatedHTML() error = %v, want ErrOutputExists", err)
+	}
+}
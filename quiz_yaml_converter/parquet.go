@@ -0,0 +1,290 @@
+// 分析ツール（DuckDBやSparkなど）で読み込むための，Parquet形式での出力です．
+// 外部のライブラリを使わず，圧縮なし・PLAINエンコーディングのファイルを書き出す．
+package quiz_yaml_converter
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Parquetファイルの先頭と末尾に書くマジックナンバー
+const parquetMagic = "PAR1"
+
+// 1つの行グループに含める問題の最大数
+const parquetRowGroupSize = 65536
+
+// Parquetの物理型（parquet.thriftのType）
+const (
+	parquetInt32     = 1
+	parquetByteArray = 6
+)
+
+// Parquetのエンコーディング（parquet.thriftのEncoding）
+const (
+	parquetPlain = 0
+	parquetRLE   = 3
+)
+
+// parquetColumn はParquetに出力する列．
+// 文字列の列（strを指定）はUTF-8のBYTE_ARRAY，整数の列（numを指定）はINT32とする．
+type parquetColumn struct {
+	name string
+	str  func(item QuizItem) string
+	num  func(item QuizItem) int32
+}
+
+// Parquetに出力する列．すべての列は必須（null値なし）とし，値がない場合は空文字列または0とする．
+var parquetColumns = []parquetColumn{
+	{name: "id", str: resultsID},
+	{name: "question", str: func(item QuizItem) string { return item.Question }},
+	{name: "answer", str: func(item QuizItem) string { return item.Answer }},
+	{name: "reading", str: func(item QuizItem) string { return item.Reading }},
+	{name: "genre", str: func(item QuizItem) string { return item.Genre }},
+	{name: "tags", str: func(item QuizItem) string { return strings.Join(item.Tags, ",") }},
+	{name: "difficulty", num: func(item QuizItem) int32 { return int32(item.Difficulty) }},
+	{name: "ok_count", num: func(item QuizItem) int32 { return int32(len(item.OKAnswers())) }},
+	{name: "ng_count", num: func(item QuizItem) int32 { return int32(len(item.NGAnswers())) }},
+	{name: "repeat_count", num: func(item QuizItem) int32 { return int32(len(item.RepeatAnswers())) }},
+}
+
+// writeParquet は問題データをParquet形式で書き出す．
+// 列はid（idがなければContentID）, question, answer, reading, genre, tags（カンマ区切り）,
+// difficulty, ok_count, ng_count, repeat_count（正誤判定の数）で，
+// parquetRowGroupSize問ごとに行グループを分ける．
+func writeParquet(w io.Writer, items []QuizItem, opts ConvertOptions) error {
+	items = withAssignedIDs(items, opts)
+
+	var body bytes.Buffer
+	body.WriteString(parquetMagic)
+	var rowGroups []parquetRowGroup
+	for start := 0; start < len(items); start += parquetRowGroupSize {
+		group := items[start:min(start+parquetRowGroupSize, len(items))]
+		rg := parquetRowGroup{numRows: len(group)}
+		for _, col := range parquetColumns {
+			chunk := writeParquetColumnChunk(&body, col, group)
+			rg.totalSize += chunk.size
+			rg.columns = append(rg.columns, chunk)
+		}
+		rowGroups = append(rowGroups, rg)
+	}
+
+	footer := parquetFileMetaData(len(items), rowGroups)
+	body.Write(footer)
+	body.Write(binary.LittleEndian.AppendUint32(nil, uint32(len(footer))))
+	body.WriteString(parquetMagic)
+	if _, err := w.Write(body.Bytes()); err != nil {
+		return fmt.Errorf("failed to write Parquet: %w", err)
+	}
+	return nil
+}
+
+// parquetRowGroup は書き出した行グループの情報．
+type parquetRowGroup struct {
+	numRows   int
+	totalSize int
+	columns   []parquetColumnChunk
+}
+
+// parquetColumnChunk は書き出した列チャンク（1ページのみ）の情報．
+type parquetColumnChunk struct {
+	col    parquetColumn
+	offset int // ページヘッダーのファイル先頭からの位置
+	size   int // ページヘッダーを含む大きさ
+	values int
+}
+
+// writeParquetColumnChunk は問題の1列を1つのデータページとしてbufに書き出す．
+// 必須の列で入れ子もないため，定義レベルと繰り返しレベルは書かない．
+func writeParquetColumnChunk(buf *bytes.Buffer, col parquetColumn, items []QuizItem) parquetColumnChunk {
+	var data []byte
+	for _, item := range items {
+		if col.str != nil {
+			s := col.str(item)
+			data = binary.LittleEndian.AppendUint32(data, uint32(len(s)))
+			data = append(data, s...)
+		} else {
+			data = binary.LittleEndian.AppendUint32(data, uint32(col.num(item)))
+		}
+	}
+
+	// PageHeader
+	var header thriftWriter
+	header.i32(1, 0) // type: DATA_PAGE
+	header.i32(2, int32(len(data)))
+	header.i32(3, int32(len(data)))
+	header.structField(5, func() { // data_page_header
+		header.i32(1, int32(len(items)))
+		header.i32(2, parquetPlain)
+		header.i32(3, parquetRLE)
+		header.i32(4, parquetRLE)
+	})
+	header.stop()
+
+	chunk := parquetColumnChunk{col: col, offset: buf.Len(), size: header.buf.Len() + len(data), values: len(items)}
+	buf.Write(header.buf.Bytes())
+	buf.Write(data)
+	return chunk
+}
+
+// parquetFileMetaData はファイル末尾に書くFileMetaDataをThriftのコンパクトプロトコルで返す．
+func parquetFileMetaData(numRows int, rowGroups []parquetRowGroup) []byte {
+	var t thriftWriter
+	t.i32(1, 1)                                          // version
+	t.structList(2, len(parquetColumns)+1, func(i int) { // schema
+		if i == 0 {
+			t.binary(4, "schema")
+			t.i32(5, int32(len(parquetColumns)))
+			return
+		}
+		col := parquetColumns[i-1]
+		t.i32(1, col.physicalType())
+		t.i32(3, 0) // repetition_type: REQUIRED
+		t.binary(4, col.name)
+		if col.str != nil {
+			t.i32(6, 0)                // converted_type: UTF8
+			t.structField(10, func() { // logicalType
+				t.structField(1, func() {}) // STRING
+			})
+		}
+	})
+	t.i64(3, int64(numRows))
+	t.structList(4, len(rowGroups), func(i int) { // row_groups
+		rg := rowGroups[i]
+		t.structList(1, len(rg.columns), func(j int) { // columns
+			chunk := rg.columns[j]
+			t.i64(2, int64(chunk.offset)) // file_offset
+			t.structField(3, func() {     // meta_data
+				t.i32(1, chunk.col.physicalType())
+				t.i32List(2, parquetPlain, parquetRLE) // encodings
+				t.binaryList(3, chunk.col.name)        // path_in_schema
+				t.i32(4, 0)                            // codec: UNCOMPRESSED
+				t.i64(5, int64(chunk.values))
+				t.i64(6, int64(chunk.size))
+				t.i64(7, int64(chunk.size))
+				t.i64(9, int64(chunk.offset)) // data_page_offset
+			})
+		})
+		t.i64(2, int64(rg.totalSize))
+		t.i64(3, int64(rg.numRows))
+	})
+	t.binary(6, "quiz-yaml-go") // created_by
+	t.stop()
+	return t.buf.Bytes()
+}
+
+// physicalType は列のParquetの物理型を返す．
+func (c parquetColumn) physicalType() int32 {
+	if c.str != nil {
+		return parquetByteArray
+	}
+	return parquetInt32
+}
+
+// Thriftのコンパクトプロトコルの型
+const (
+	thriftI32    = 5
+	thriftI64    = 6
+	thriftBinary = 8
+	thriftList   = 9
+	thriftStruct = 12
+)
+
+// thriftWriter はThriftのコンパクトプロトコルで構造体を書き出す．
+// フィールドは番号の昇順に書き，最後にstopで構造体の終わりを書く．
+type thriftWriter struct {
+	buf    bytes.Buffer
+	lastID int16 // 直前に書いたフィールドの番号
+}
+
+// field はフィールドのヘッダー（直前のフィールドとの番号の差と型）を書く．
+func (t *thriftWriter) field(id int16, typ byte) {
+	if delta := id - t.lastID; delta > 0 && delta <= 15 {
+		t.buf.WriteByte(byte(delta)<<4 | typ)
+	} else {
+		t.buf.WriteByte(typ)
+		t.varint(zigzag(int64(id)))
+	}
+	t.lastID = id
+}
+
+func (t *thriftWriter) varint(v uint64) {
+	t.buf.Write(binary.AppendUvarint(nil, v))
+}
+
+// zigzag は符号付き整数をThriftの可変長整数にするためにZigZag符号化する．
+func zigzag(v int64) uint64 {
+	return uint64(v<<1) ^ uint64(v>>63)
+}
+
+func (t *thriftWriter) i32(id int16, v int32) {
+	t.field(id, thriftI32)
+	t.varint(zigzag(int64(v)))
+}
+
+func (t *thriftWriter) i64(id int16, v int64) {
+	t.field(id, thriftI64)
+	t.varint(zigzag(v))
+}
+
+func (t *thriftWriter) binary(id int16, s string) {
+	t.field(id, thriftBinary)
+	t.varint(uint64(len(s)))
+	t.buf.WriteString(s)
+}
+
+// stop は構造体の終わりを書く．
+func (t *thriftWriter) stop() {
+	t.buf.WriteByte(0)
+}
+
+// nested はfで入れ子の構造体のフィールドを書き，構造体の終わりを書く．
+func (t *thriftWriter) nested(f func()) {
+	outer := t.lastID
+	t.lastID = 0
+	f()
+	t.stop()
+	t.lastID = outer
+}
+
+// structField は構造体のフィールドを書く．構造体のフィールドはfで書く．
+func (t *thriftWriter) structField(id int16, f func()) {
+	t.field(id, thriftStruct)
+	t.nested(f)
+}
+
+// listHeader はn個の要素を持つリストのフィールドのヘッダーを書く．
+func (t *thriftWriter) listHeader(id int16, elemType byte, n int) {
+	t.field(id, thriftList)
+	if n < 15 {
+		t.buf.WriteByte(byte(n)<<4 | elemType)
+	} else {
+		t.buf.WriteByte(0xf0 | elemType)
+		t.varint(uint64(n))
+	}
+}
+
+// structList はn個の構造体のリストのフィールドを書く．i番目の構造体のフィールドはf(i)で書く．
+func (t *thriftWriter) structList(id int16, n int, f func(i int)) {
+	t.listHeader(id, thriftStruct, n)
+	for i := range n {
+		t.nested(func() { f(i) })
+	}
+}
+
+func (t *thriftWriter) i32List(id int16, values ...int32) {
+	t.listHeader(id, thriftI32, len(values))
+	for _, v := range values {
+		t.varint(zigzag(int64(v)))
+	}
+}
+
+func (t *thriftWriter) binaryList(id int16, values ...string) {
+	t.listHeader(id, thriftBinary, len(values))
+	for _, s := range values {
+		t.varint(uint64(len(s)))
+		t.buf.WriteString(s)
+	}
+}
@@ -0,0 +1,222 @@
+package quiz_yaml_converter
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestWriteParquet(t *testing.T) {
+	items := []QuizItem{
+		{ID: "q1", Question: "日本の首都は？", Answer: "東京", Reading: "とうきょう", Genre: "地理", Tags: []string{"日本", "首都"}, Difficulty: 2,
+			Criteria: map[string][]string{"ok": {"とうきょう", "東京都"}, "ng": {"京都"}}},
+		{Question: "1+1は？", Answer: "2", Criteria: map[string][]string{"repeat": {"に"}}},
+	}
+	f, err := LookupFormatter("parquet")
+	if err != nil {
+		t.Fatalf("LookupFormatter() error = %v", err)
+	}
+	var buf bytes.Buffer
+	if err := f.Format(&buf, items, ConvertOptions{}); err != nil {
+		t.Fatalf("Format() error = %v", err)
+	}
+
+	meta, columns := readParquet(t, buf.Bytes())
+	if meta[1] != int64(1) {
+		t.Errorf("version = %v, want 1", meta[1])
+	}
+	if meta[3] != int64(2) {
+		t.Errorf("num_rows = %v, want 2", meta[3])
+	}
+	schema := meta[2].([]any)
+	if len(schema) != len(parquetColumns)+1 || schema[0].(map[int16]any)[5] != int64(len(parquetColumns)) {
+		t.Fatalf("schema = %v", schema)
+	}
+	for i, col := range parquetColumns {
+		el := schema[i+1].(map[int16]any)
+		if string(el[4].([]byte)) != col.name || el[1] != int64(col.physicalType()) || el[3] != int64(0) {
+			t.Errorf("schema[%d] = %v, want %s", i+1, el, col.name)
+		}
+	}
+
+	expected := map[string][]any{
+		"id":           {"q1", ContentID(items[1])},
+		"question":     {"日本の首都は？", "1+1は？"},
+		"answer":       {"東京", "2"},
+		"reading":      {"とうきょう", ""},
+		"genre":        {"地理", ""},
+		"tags":         {"日本,首都", ""},
+		"difficulty":   {int32(2), int32(0)},
+		"ok_count":     {int32(2), int32(0)},
+		"ng_count":     {int32(1), int32(0)},
+		"repeat_count": {int32(0), int32(1)},
+	}
+	if !reflect.DeepEqual(columns, expected) {
+		t.Errorf("columns = %v, want %v", columns, expected)
+	}
+}
+
+func TestWriteParquet_Empty(t *testing.T) {
+	var buf bytes.Buffer
+	if err := writeParquet(&buf, nil, ConvertOptions{}); err != nil {
+		t.Fatalf("writeParquet() error = %v", err)
+	}
+	meta, columns := readParquet(t, buf.Bytes())
+	if meta[3] != int64(0) || len(meta[4].([]any)) != 0 || len(columns) != 0 {
+		t.Errorf("metadata = %v, columns = %v", meta, columns)
+	}
+}
+
+func TestWriteParquet_RowGroups(t *testing.T) {
+	items := make([]QuizItem, parquetRowGroupSize+1)
+	for i := range items {
+		items[i] = QuizItem{ID: fmt.Sprint(i), Question: "q", Answer: "a"}
+	}
+	var buf bytes.Buffer
+	if err := writeParquet(&buf, items, ConvertOptions{}); err != nil {
+		t.Fatalf("writeParquet() error = %v", err)
+	}
+	meta, columns := readParquet(t, buf.Bytes())
+	if n := len(meta[4].([]any)); n != 2 {
+		t.Errorf("row groups = %d, want 2", n)
+	}
+	ids := columns["id"]
+	if len(ids) != len(items) || ids[parquetRowGroupSize] != fmt.Sprint(parquetRowGroupSize) {
+		t.Errorf("id column has %d values, last = %v", len(ids), ids[len(ids)-1])
+	}
+}
+
+// readParquet はwriteParquetが書き出したファイルのFileMetaDataと，列ごとの値を読み取る．
+func readParquet(t *testing.T, data []byte) (map[int16]any, map[string][]any) {
+	t.Helper()
+	if string(data[:4]) != parquetMagic || string(data[len(data)-4:]) != parquetMagic {
+		t.Fatalf("missing magic number")
+	}
+	footerLen := int(binary.LittleEndian.Uint32(data[len(data)-8:]))
+	footer := data[len(data)-8-footerLen : len(data)-8]
+	r := &thriftReader{data: footer}
+	meta := r.readStruct()
+	if r.err != nil || r.pos != len(footer) {
+		t.Fatalf("failed to read FileMetaData: %v (read %d of %d bytes)", r.err, r.pos, len(footer))
+	}
+
+	columns := map[string][]any{}
+	for _, rg := range meta[4].([]any) {
+		for _, c := range rg.(map[int16]any)[1].([]any) {
+			cm := c.(map[int16]any)[3].(map[int16]any)
+			name := string(cm[3].([]any)[0].([]byte))
+			offset := int(cm[9].(int64))
+			pr := &thriftReader{data: data[offset:]}
+			header := pr.readStruct()
+			if pr.err != nil {
+				t.Fatalf("%s: failed to read PageHeader: %v", name, pr.err)
+			}
+			if int64(pr.pos)+header[3].(int64) != cm[7].(int64) {
+				t.Errorf("%s: total_compressed_size = %v, want %d", name, cm[7], int64(pr.pos)+header[3].(int64))
+			}
+			page := data[offset+pr.pos : offset+pr.pos+int(header[3].(int64))]
+			n := int(header[5].(map[int16]any)[1].(int64))
+			for range n {
+				if cm[1] == int64(parquetByteArray) {
+					l := int(binary.LittleEndian.Uint32(page))
+					columns[name] = append(columns[name], string(page[4:4+l]))
+					page = page[4+l:]
+				} else {
+					columns[name] = append(columns[name], int32(binary.LittleEndian.Uint32(page)))
+					page = page[4:]
+				}
+			}
+			if len(page) != 0 {
+				t.Errorf("%s: %d bytes left in the page", name, len(page))
+			}
+		}
+	}
+	return meta, columns
+}
+
+// thriftReader はテスト用にThriftのコンパクトプロトコルの構造体を読み取る．
+// 構造体はフィールド番号から値へのマップ，整数はint64，バイナリは[]byte，リストは[]anyとする．
+type thriftReader struct {
+	data []byte
+	pos  int
+	err  error
+}
+
+func (r *thriftReader) byte() byte {
+	if r.pos >= len(r.data) {
+		r.err = fmt.Errorf("unexpected end of data")
+		return 0
+	}
+	b := r.data[r.pos]
+	r.pos++
+	return b
+}
+
+func (r *thriftReader) varint() uint64 {
+	v, n := binary.Uvarint(r.data[r.pos:])
+	if n <= 0 {
+		r.err = fmt.Errorf("invalid varint at %d", r.pos)
+		return 0
+	}
+	r.pos += n
+	return v
+}
+
+func (r *thriftReader) zigzag() int64 {
+	v := r.varint()
+	return int64(v>>1) ^ -int64(v&1)
+}
+
+func (r *thriftReader) readStruct() map[int16]any {
+	fields := map[int16]any{}
+	var id int16
+	for r.err == nil {
+		b := r.byte()
+		if b == 0 {
+			break
+		}
+		if delta := int16(b >> 4); delta != 0 {
+			id += delta
+		} else {
+			id = int16(r.zigzag())
+		}
+		fields[id] = r.readValue(b & 0x0f)
+	}
+	return fields
+}
+
+func (r *thriftReader) readValue(typ byte) any {
+	switch typ {
+	case 1:
+		return true
+	case 2:
+		return false
+	case 4, 5, 6:
+		return r.zigzag()
+	case 8:
+		n := int(r.varint())
+		if r.pos+n > len(r.data) {
+			r.err = fmt.Errorf("binary out of range")
+			return nil
+		}
+		r.pos += n
+		return r.data[r.pos-n : r.pos]
+	case 9:
+		b := r.byte()
+		n := int(b >> 4)
+		if n == 15 {
+			n = int(r.varint())
+		}
+		list := []any{}
+		for range n {
+			list = append(list, r.readValue(b&0x0f))
+		}
+		return list
+	case 12:
+		return r.readStruct()
+	}
+	r.err = fmt.Errorf("unsupported type %d", typ)
+	return nil
+}
@@ -0,0 +1,198 @@
+package quiz_yaml_converter
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Stage は読み込んだ問題データを出力前に変換する処理．
+// 問題の書き換え，フィールドの追加，問題の削除などを行い，変換後の問題データを返す．
+type Stage interface {
+	Apply(items []QuizItem) ([]QuizItem, error)
+}
+
+// StageFunc は関数をStageとして扱うための型．
+type StageFunc func(items []QuizItem) ([]QuizItem, error)
+
+// Apply はf(items)を呼び出す．
+func (f StageFunc) Apply(items []QuizItem) ([]QuizItem, error) {
+	return f(items)
+}
+
+// Pipeline は順に適用するStageの列．
+// ConvertOptions.Pipelineに指定すると，YAMLの読み込み後，出力の前に適用される．
+type Pipeline []Stage
+
+// Apply は各Stageを順に適用した問題データを返す．元のスライスは変更しない．
+// Stageがエラーを返した場合はErrTransformを含むエラーを返す．
+func (p Pipeline) Apply(items []QuizItem) ([]QuizItem, error) {
+	if len(p) == 0 {
+		return items, nil
+	}
+	items = cloneItems(items)
+	for i, stage := range p {
+		var err error
+		if items, err = stage.Apply(items); err != nil {
+			return nil, fmt.Errorf("%w: stage %d: %w", ErrTransform, i+1, err)
+		}
+	}
+	return items, nil
+}
+
+// EachItem は問題を1問ずつ処理するStageを作成する．
+// fnには問題の番号（1から始まる）と問題へのポインタが渡され，問題を直接書き換えられる．
+// keepにfalseを返した問題は取り除かれる．
+// fnがエラーを返しても残りの問題の処理を続け，失敗したすべての問題のItemErrorをまとめて返す．
+func EachItem(fn func(index int, item *QuizItem) (keep bool, err error)) Stage {
+	return StageFunc(func(items []QuizItem) ([]QuizItem, error) {
+		kept := items[:0]
+		var errs []error
+		for i := range items {
+			keep, err := fn(i+1, &items[i])
+			if err != nil {
+				errs = append(errs, &ItemError{Index: i + 1, Question: QuestionSnippet(items[i]), Err: err})
+				continue
+			}
+			if keep {
+				kept = append(kept, items[i])
+			}
+		}
+		if len(errs) > 0 {
+			return nil, errors.Join(errs...)
+		}
+		return kept, nil
+	})
+}
+
+// Filter は条件を満たす問題だけを残すStageを作成する．
+func Filter(keep func(item QuizItem) bool) Stage {
+	return EachItem(func(_ int, item *QuizItem) (bool, error) {
+		return keep(*item), nil
+	})
+}
+
+// ItemHook は変換中に問題を1問ずつ処理する関数．問題の伏せ字化，注記の追加，番号の振り直しなどに使う．
+// indexは1から始まる問題の番号で，itemを直接書き換えられる．
+// エラーを返した問題があると，変換はErrTransformと問題ごとのItemErrorを含むエラーで失敗する．
+type ItemHook func(index int, item *QuizItem) error
+
+// Apply はすべての問題にhを適用する（ItemHookはStageとしても使える）．
+func (h ItemHook) Apply(items []QuizItem) ([]QuizItem, error) {
+	return EachItem(func(index int, item *QuizItem) (bool, error) {
+		return true, h(index, item)
+	}).Apply(items)
+}
+
+// WithItemHook はPipelineの最後（ほかのStageの後，出力の直前）にhookを加えたConvertOptionsを返す．
+// hookは取り除かれずに残った問題だけに呼ばれ，indexは残った問題の中での番号となる．
+// 元のConvertOptionsのPipelineは変更しない．
+func (o ConvertOptions) WithItemHook(hook ItemHook) ConvertOptions {
+	o.Pipeline = append(slices.Clip(o.Pipeline), hook)
+	return o
+}
+
+// WithPreItemHook はPipelineの最初（YAMLの読み込み直後，ほかのStageの前）にhookを加えたConvertOptionsを返す．
+// 元のConvertOptionsのPipelineは変更しない．
+func (o ConvertOptions) WithPreItemHook(hook ItemHook) ConvertOptions {
+	o.Pipeline = append(Pipeline{hook}, o.Pipeline...)
+	return o
+}
+
+// TrimSpace は問題の各テキストの前後の空白を取り除くStage．
+// YAMLのブロック記法で入りがちな末尾の改行などを取り除くのに使う．
+var TrimSpace Stage = EachItem(func(_ int, item *QuizItem) (bool, error) {
+	item.ID = strings.TrimSpace(item.ID)
+	item.Question = strings.TrimSpace(item.Question)
+	item.Answer = strings.TrimSpace(item.Answer)
+	item.Reading = strings.TrimSpace(item.Reading)
+	item.Spell = strings.TrimSpace(item.Spell)
+	for lang, text := range item.Spells {
+		item.Spells[lang] = strings.TrimSpace(text)
+	}
+	item.Genre = strings.TrimSpace(item.Genre)
+	item.Source = strings.TrimSpace(item.Source)
+	item.Reference = strings.TrimSpace(item.Reference)
+	item.Author = strings.TrimSpace(item.Author)
+	item.License = strings.TrimSpace(item.License)
+	trimAll(item.Segments)
+	trimAll(item.Tags)
+	trimAll(item.Comments)
+	trimAll(item.Choices)
+	for _, values := range item.Criteria {
+		trimAll(values)
+	}
+	return true, nil
+})
+
+func trimAll(values []string) {
+	for i := range values {
+		values[i] = strings.TrimSpace(values[i])
+	}
+}
+
+// cloneItems はStageが元の問題データを書き換えないよう，スライスとmapを複製する．
+func cloneItems(items []QuizItem) []QuizItem {
+	cloned := make([]QuizItem, len(items))
+	for i, item := range items {
+		item.Segments = append([]string(nil), item.Segments...)
+		item.Tags = append([]string(nil), item.Tags...)
+		item.Comments = append([]string(nil), item.Comments...)
+		item.Choices = append([]string(nil), item.Choices...)
+		item.CriteriaOrder = append([]string(nil), item.CriteriaOrder...)
+		if item.Spells != nil {
+			spells := make(map[string]string, len(item.Spells))
+			for lang, text := range item.Spells {
+				spells[lang] = text
+			}
+			item.Spells = spells
+		}
+		if item.Criteria != nil {
+			criteria := make(map[string][]string, len(item.Criteria))
+			for key, values := range item.Criteria {
+				criteria[key] = append([]string(nil), values...)
+			}
+			item.Criteria = criteria
+		}
+		cloned[i] = item
+	}
+	return cloned
+}
+
+// ExecTransform は外部コマンドで問題データを変換するStage．
+// 問題データを-format jsonと同じJSONの配列として標準入力に渡し，
+// 標準出力に書き出された同じ形式のJSONの配列を変換後の問題データとする．
+// 問題を取り除く場合は配列から除けばよい．
+type ExecTransform struct {
+	Path string   // 実行するコマンド
+	Args []string // コマンドの引数
+}
+
+// ParseExecTransform は"コマンド 引数..."形式の指定からExecTransformを作成する．
+// コマンドと引数は空白で区切る．
+func ParseExecTransform(spec string) (ExecTransform, error) {
+	fields := strings.Fields(spec)
+	if len(fields) == 0 {
+		return ExecTransform{}, fmt.Errorf("%w: empty command", ErrTransform)
+	}
+	return ExecTransform{Path: fields[0], Args: fields[1:]}, nil
+}
+
+// Apply は外部コマンドを実行し，その出力を問題データとして読み込む．
+func (t ExecTransform) Apply(items []QuizItem) ([]QuizItem, error) {
+	var input, output bytes.Buffer
+	if err := writeJSONItems(&input, items, ConvertOptions{}); err != nil {
+		return nil, err
+	}
+	if err := runCommand(t.Path, t.Args, &input, &output); err != nil {
+		return nil, err
+	}
+	var transformed []QuizItem
+	if err := json.Unmarshal(output.Bytes(), &transformed); err != nil {
+		return nil, fmt.Errorf("%s: invalid JSON output: %w", t.Path, err)
+	}
+	return transformed, nil
+}
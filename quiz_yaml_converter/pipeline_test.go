@@ -0,0 +1,203 @@
+package quiz_yaml_converter
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestPipeline_Apply(t *testing.T) {
+	items := []QuizItem{
+		{Question: " q1 \n", Answer: "a1", Tags: []string{" t "}, Criteria: map[string][]string{"ok": {" x "}}},
+		{Question: "q2", Answer: "a2", Genre: "drop"},
+		{Question: "q3", Answer: "a3"},
+	}
+	original := cloneItems(items)
+
+	annotate := EachItem(func(index int, item *QuizItem) (bool, error) {
+		item.Comments = append(item.Comments, "checked")
+		return true, nil
+	})
+	p := Pipeline{
+		TrimSpace,
+		Filter(func(item QuizItem) bool { return item.Genre != "drop" }),
+		annotate,
+	}
+	got, err := p.Apply(items)
+	if err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	want := []QuizItem{
+		{Question: "q1", Answer: "a1", Tags: []string{"t"}, Comments: []string{"checked"}, Criteria: map[string][]string{"ok": {"x"}}},
+		{Question: "q3", Answer: "a3", Comments: []string{"checked"}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Apply() = %+v, want %+v", got, want)
+	}
+	if !reflect.DeepEqual(items, original) {
+		t.Errorf("Apply() modified the input: %+v", items)
+	}
+}
+
+func TestPipeline_ApplyError(t *testing.T) {
+	p := Pipeline{EachItem(func(index int, item *QuizItem) (bool, error) {
+		if index == 2 {
+			return false, errors.New("boom")
+		}
+		return true, nil
+	})}
+	_, err := p.Apply([]QuizItem{{Question: "q1"}, {Question: "q2"}})
+	if !errors.Is(err, ErrTransform) {
+		t.Fatalf("Apply() error = %v, want ErrTransform", err)
+	}
+	if !strings.Contains(err.Error(), "item 2") {
+		t.Errorf("Apply() error = %q, want item index", err)
+	}
+}
+
+func TestEachItem_AllErrors(t *testing.T) {
+	stage := EachItem(func(index int, item *QuizItem) (bool, error) {
+		if item.Answer == "" {
+			return false, errors.New("empty answer")
+		}
+		return true, nil
+	})
+	_, err := stage.Apply([]QuizItem{{Question: "q1"}, {Question: "q2", Answer: "a2"}, {Question: "q3"}})
+	var indices []int
+	for _, e := range ItemErrors(err) {
+		indices = append(indices, e.Index)
+	}
+	if !reflect.DeepEqual(indices, []int{1, 3}) {
+		t.Errorf("Apply() item errors = %v, want errors for items [1 3] (error = %v)", indices, err)
+	}
+}
+
+func TestWithItemHook(t *testing.T) {
+	items := []QuizItem{
+		{Question: "q1", Answer: "secret"},
+		{Question: "q2", Answer: "a2", Genre: "drop"},
+		{Question: "q3", Answer: "a3"},
+	}
+	var calls []string
+	base := ConvertOptions{Pipeline: Pipeline{Filter(func(item QuizItem) bool { return item.Genre != "drop" })}}
+	opts := base.
+		WithItemHook(func(index int, item *QuizItem) error {
+			calls = append(calls, fmt.Sprintf("post %d %s", index, item.Question))
+			item.ID = fmt.Sprintf("Q%03d", index)
+			return nil
+		}).
+		WithPreItemHook(func(index int, item *QuizItem) error {
+			calls = append(calls, fmt.Sprintf("pre %d %s", index, item.Question))
+			item.Answer = strings.ReplaceAll(item.Answer, "secret", "***")
+			return nil
+		})
+	if len(base.Pipeline) != 1 {
+		t.Errorf("WithItemHook() modified the original Pipeline: %d stages", len(base.Pipeline))
+	}
+
+	got, err := opts.Pipeline.Apply(items)
+	if err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	want := []QuizItem{{ID: "Q001", Question: "q1", Answer: "***"}, {ID: "Q002", Question: "q3", Answer: "a3"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Apply() = %+v, want %+v", got, want)
+	}
+	wantCalls := []string{"pre 1 q1", "pre 2 q2", "pre 3 q3", "post 1 q1", "post 2 q3"}
+	if !reflect.DeepEqual(calls, wantCalls) {
+		t.Errorf("hooks were called %v, want %v", calls, wantCalls)
+	}
+	if items[0].Answer != "secret" {
+		t.Errorf("hooks modified the input: %+v", items[0])
+	}
+}
+
+func TestWithItemHook_Error(t *testing.T) {
+	opts := ConvertOptions{}.WithItemHook(func(index int, item *QuizItem) error {
+		if item.Answer == "" {
+			return errors.New("answer is empty")
+		}
+		return nil
+	})
+	_, err := opts.Pipeline.Apply([]QuizItem{{Question: "q1", Answer: "a1"}, {Question: "q2"}})
+	var itemErr *ItemError
+	if !errors.Is(err, ErrTransform) || !errors.As(err, &itemErr) || itemErr.Index != 2 {
+		t.Errorf("Apply() error = %v, want ErrTransform with an ItemError for item 2", err)
+	}
+}
+
+func TestPipeline_Empty(t *testing.T) {
+	items := []QuizItem{{Question: "q"}}
+	got, err := Pipeline(nil).Apply(items)
+	if err != nil || !reflect.DeepEqual(got, items) {
+		t.Errorf("Apply() = %+v, %v", got, err)
+	}
+}
+
+func TestExecTransform(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not found")
+	}
+	items := []QuizItem{{Question: "q", Answer: "a"}}
+
+	t.Run("replace items", func(t *testing.T) {
+		stage := ExecTransform{Path: "sh", Args: []string{"-c", `cat >/dev/null; echo '[{"question":"new","answer":"b","genre":"g"}]'`}}
+		got, err := Pipeline{stage}.Apply(items)
+		if err != nil {
+			t.Fatalf("Apply() error = %v", err)
+		}
+		want := []QuizItem{{Question: "new", Answer: "b", Genre: "g"}}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("Apply() = %+v, want %+v", got, want)
+		}
+	})
+
+	t.Run("invalid output", func(t *testing.T) {
+		stage := ExecTransform{Path: "sh", Args: []string{"-c", "echo not-json"}}
+		if _, err := (Pipeline{stage}).Apply(items); !errors.Is(err, ErrTransform) {
+			t.Errorf("Apply() error = %v, want ErrTransform", err)
+		}
+	})
+
+	t.Run("command failure", func(t *testing.T) {
+		stage := ExecTransform{Path: "sh", Args: []string{"-c", "echo oops >&2; exit 1"}}
+		_, err := Pipeline{stage}.Apply(items)
+		if !errors.Is(err, ErrTransform) || !strings.Contains(err.Error(), "oops") {
+			t.Errorf("Apply() error = %v, want ErrTransform with stderr", err)
+		}
+	})
+}
+
+func TestParseExecTransform(t *testing.T) {
+	got, err := ParseExecTransform("python3 normalize.py --strict")
+	if err != nil {
+		t.Fatalf("ParseExecTransform() error = %v", err)
+	}
+	if want := (ExecTransform{Path: "python3", Args: []string{"normalize.py", "--strict"}}); !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseExecTransform() = %+v, want %+v", got, want)
+	}
+	if _, err := ParseExecTransform("  "); !errors.Is(err, ErrTransform) {
+		t.Errorf("ParseExecTransform(empty) error = %v, want ErrTransform", err)
+	}
+}
+
+func TestConvertItems_Pipeline(t *testing.T) {
+	output := filepath.Join(t.TempDir(), "out.csv")
+	items := []QuizItem{{Question: "keep", Answer: "a"}, {Question: "drop", Answer: "b"}}
+	opts := ConvertOptions{
+		CSV:      CSVOptions{Columns: []string{"question"}, NoHeader: true},
+		Pipeline: Pipeline{Filter(func(item QuizItem) bool { return item.Question != "drop" })},
+	}
+	if err := ConvertItems(items, output, "", opts); err != nil {
+		t.Fatalf("ConvertItems() error = %v", err)
+	}
+	content, _ := os.ReadFile(output)
+	if got := strings.TrimSpace(string(content)); got != "keep" {
+		t.Errorf("output = %q, want %q", got, "keep")
+	}
+}
@@ -0,0 +1,156 @@
+package quiz_yaml_converter
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// RulePunctuation は句読点などの表記が指定したスタイルと異なることを表す警告の規則名．
+const RulePunctuation = "punctuation"
+
+// PunctuationStyle はプロジェクトで統一する句読点・記号の表記．
+// 空のフィールドはチェックしない．
+type PunctuationStyle struct {
+	Comma       string // 読点（"、"または"，"）
+	Period      string // 句点（"。"または"．"）
+	Question    string // 疑問符（"？"または"?"）
+	Exclamation string // 感嘆符（"！"または"!"）
+	Parens      string // 丸括弧（"（）"または"()"）
+}
+
+// punctuationRule は統一の対象となる記号の種類と，使用できる表記の候補．
+// 括弧のように複数の文字からなる表記は，同じ位置の文字どうしを置き換える．
+type punctuationRule struct {
+	key     string
+	choices []string
+	field   func(s *PunctuationStyle) *string
+}
+
+var punctuationRules = []punctuationRule{
+	{"comma", []string{"、", "，"}, func(s *PunctuationStyle) *string { return &s.Comma }},
+	{"period", []string{"。", "．"}, func(s *PunctuationStyle) *string { return &s.Period }},
+	{"question", []string{"？", "?"}, func(s *PunctuationStyle) *string { return &s.Question }},
+	{"exclamation", []string{"！", "!"}, func(s *PunctuationStyle) *string { return &s.Exclamation }},
+	{"parens", []string{"（）", "()"}, func(s *PunctuationStyle) *string { return &s.Parens }},
+}
+
+// punctuationPresets はParsePunctuationStyleで指定できる表記の組み合わせ．
+var punctuationPresets = map[string]PunctuationStyle{
+	"japanese": {Comma: "、", Period: "。", Question: "？", Exclamation: "！", Parens: "（）"},
+	"academic": {Comma: "，", Period: "．", Question: "？", Exclamation: "！", Parens: "（）"},
+}
+
+// ParsePunctuationStyle は"academic,question=?"形式の表記の指定を解析する．
+// カンマ区切りの各項目はプリセット名（japanese: 、。，academic: ，．）か，
+// comma, period, question, exclamation, parensのいずれかの"種類=表記"とし，後の指定で上書きする．
+func ParsePunctuationStyle(spec string) (PunctuationStyle, error) {
+	var style PunctuationStyle
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		key, value, found := strings.Cut(part, "=")
+		if !found {
+			preset, ok := punctuationPresets[part]
+			if !ok {
+				return style, fmt.Errorf("unknown punctuation preset: %q (available: academic, japanese)", part)
+			}
+			style = preset
+			continue
+		}
+		key, value = strings.ToLower(strings.TrimSpace(key)), strings.TrimSpace(value)
+		i := slices.IndexFunc(punctuationRules, func(r punctuationRule) bool { return r.key == key })
+		if i < 0 {
+			return style, fmt.Errorf("unknown punctuation kind: %q (available: comma, period, question, exclamation, parens)", key)
+		}
+		rule := punctuationRules[i]
+		if !slices.Contains(rule.choices, value) {
+			return style, fmt.Errorf("invalid %s style: %q (available: %s)", key, value, strings.Join(rule.choices, ", "))
+		}
+		*rule.field(&style) = value
+	}
+	return style, nil
+}
+
+// IsZero は統一する表記が1つも指定されていないかを返す．
+func (s PunctuationStyle) IsZero() bool {
+	return s == PunctuationStyle{}
+}
+
+// replacements はスタイルと異なる表記の文字から，スタイルの表記の文字への対応を返す．
+func (s PunctuationStyle) replacements() map[rune]rune {
+	replace := map[rune]rune{}
+	for _, rule := range punctuationRules {
+		want := *rule.field(&s)
+		if want == "" {
+			continue
+		}
+		wantRunes := []rune(want)
+		for _, choice := range rule.choices {
+			if choice == want {
+				continue
+			}
+			for i, r := range []rune(choice) {
+				replace[r] = wantRunes[i]
+			}
+		}
+	}
+	return replace
+}
+
+// PunctuationWarnings は問題文と答えで，スタイルと異なる句読点・記号が使われている箇所についての警告を返す．
+// 警告は問題・フィールド・文字ごとに1件とする．FixPunctuationで自動的に修正できる．
+func PunctuationWarnings(data []QuizItem, style PunctuationStyle) []ValidationError {
+	replace := style.replacements()
+	if len(replace) == 0 {
+		return nil
+	}
+	var warnings []ValidationError
+	for i, item := range data {
+		for _, f := range []struct{ field, text string }{
+			{"question", item.Question},
+			{"answer", item.Answer},
+		} {
+			var seen []rune
+			for _, r := range f.text {
+				if want, ok := replace[r]; ok && !slices.Contains(seen, r) {
+					seen = append(seen, r)
+					warnings = append(warnings, newValidationError(i+1, f.field, RulePunctuation, nil,
+						"%s に「%c」が含まれています（「%c」に統一してください）", f.field, r, want))
+				}
+			}
+		}
+	}
+	return warnings
+}
+
+// FixPunctuationText はテキストの句読点・記号をスタイルの表記に置き換える．
+func (s PunctuationStyle) FixPunctuationText(text string) string {
+	return replaceRunes(text, s.replacements())
+}
+
+// replaceRunes はreplaceに含まれる文字を対応する文字に置き換える．
+func replaceRunes(text string, replace map[rune]rune) string {
+	return strings.Map(func(r rune) rune {
+		if want, ok := replace[r]; ok {
+			return want
+		}
+		return r
+	}, text)
+}
+
+// FixPunctuation は問題文と答え（問題文の区切りを含む）の句読点・記号をスタイルの表記に置き換えるStageを作成する．
+// 1文字ずつ置き換えるため，区切りをつなげた文字列と問題文の対応は保たれる．
+func FixPunctuation(style PunctuationStyle) Stage {
+	replace := style.replacements()
+	return EachItem(func(_ int, item *QuizItem) (bool, error) {
+		item.Question = replaceRunes(item.Question, replace)
+		item.Answer = replaceRunes(item.Answer, replace)
+		for i := range item.Segments {
+			item.Segments[i] = replaceRunes(item.Segments[i], replace)
+		}
+		return true, nil
+	})
+}
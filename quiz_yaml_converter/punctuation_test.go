@@ -0,0 +1,103 @@
+package quiz_yaml_converter
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParsePunctuationStyle(t *testing.T) {
+	tests := []struct {
+		spec     string
+		expected PunctuationStyle
+		wantErr  bool
+	}{
+		{spec: "", expected: PunctuationStyle{}},
+		{spec: "academic", expected: PunctuationStyle{Comma: "，", Period: "．", Question: "？", Exclamation: "！", Parens: "（）"}},
+		{spec: "japanese", expected: PunctuationStyle{Comma: "、", Period: "。", Question: "？", Exclamation: "！", Parens: "（）"}},
+		{spec: "academic, question=?", expected: PunctuationStyle{Comma: "，", Period: "．", Question: "?", Exclamation: "！", Parens: "（）"}},
+		{spec: "comma=，,period=．", expected: PunctuationStyle{Comma: "，", Period: "．"}},
+		{spec: "PARENS=()", expected: PunctuationStyle{Parens: "()"}},
+		{spec: "unknown", wantErr: true},
+		{spec: "colon=：", wantErr: true},
+		{spec: "comma=,", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			style, err := ParsePunctuationStyle(tt.spec)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParsePunctuationStyle(%q) error = %v, wantErr %v", tt.spec, err, tt.wantErr)
+			}
+			if !tt.wantErr && style != tt.expected {
+				t.Errorf("ParsePunctuationStyle(%q) = %+v, want %+v", tt.spec, style, tt.expected)
+			}
+		})
+	}
+}
+
+func TestPunctuationWarnings(t *testing.T) {
+	style := PunctuationStyle{Comma: "，", Period: "．", Parens: "（）"}
+	data := []QuizItem{
+		{Question: "ドイツの首都は，どこでしょう？", Answer: "ベルリン"},
+		{Question: "日本の首都は、どこでしょう、", Answer: "東京(Tokyo)"},
+	}
+	var got []string
+	for _, w := range PunctuationWarnings(data, style) {
+		if w.Rule != RulePunctuation {
+			t.Errorf("Rule = %q, want %q", w.Rule, RulePunctuation)
+		}
+		got = append(got, w.Error())
+	}
+	expected := []string{
+		"問題 2: question に「、」が含まれています（「，」に統一してください）",
+		"問題 2: answer に「(」が含まれています（「（」に統一してください）",
+		"問題 2: answer に「)」が含まれています（「）」に統一してください）",
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("PunctuationWarnings() = %q, want %q", got, expected)
+	}
+
+	if warnings := PunctuationWarnings(data, PunctuationStyle{}); warnings != nil {
+		t.Errorf("PunctuationWarnings() with empty style = %v, want nil", warnings)
+	}
+}
+
+func TestPunctuationStyle_FixPunctuationText(t *testing.T) {
+	tests := []struct {
+		name     string
+		style    PunctuationStyle
+		input    string
+		expected string
+	}{
+		{"academic", PunctuationStyle{Comma: "，", Period: "．"}, "はい、そうです。", "はい，そうです．"},
+		{"japanese", PunctuationStyle{Comma: "、", Period: "。"}, "はい，そうです．", "はい、そうです。"},
+		{"keeps ascii punctuation", PunctuationStyle{Comma: "，", Period: "．"}, "Ph.D., 3.14", "Ph.D., 3.14"},
+		{"question", PunctuationStyle{Question: "？", Exclamation: "！"}, "本当?はい!", "本当？はい！"},
+		{"parens", PunctuationStyle{Parens: "()"}, "国際連合（国連）", "国際連合(国連)"},
+		{"empty style", PunctuationStyle{}, "はい、そうです。", "はい、そうです。"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.style.FixPunctuationText(tt.input); got != tt.expected {
+				t.Errorf("FixPunctuationText(%q) = %q, want %q", tt.input, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestFixPunctuation(t *testing.T) {
+	items := []QuizItem{{
+		Question: "ドイツの首都は、どこでしょう？",
+		Segments: []string{"ドイツの首都は、", "どこでしょう？"},
+		Answer:   "ベルリン",
+	}}
+	got, err := Pipeline{FixPunctuation(PunctuationStyle{Comma: "，"})}.Apply(items)
+	if err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	if got[0].Question != "ドイツの首都は，どこでしょう？" || !reflect.DeepEqual(got[0].Segments, []string{"ドイツの首都は，", "どこでしょう？"}) {
+		t.Errorf("Apply() = %+v", got[0])
+	}
+	if errs := validateSegments(got[0], 1); len(errs) != 0 {
+		t.Errorf("validateSegments() = %v, want no errors", errs)
+	}
+}
@@ -0,0 +1,540 @@
+// 問題ごとのページへのリンクや問題IDを埋め込むQRコードを作成する処理です．
+// 外部のライブラリを使わず，バイトモード・誤り訂正レベルM（約15%の復元）のQRコード（モデル2，型番1〜40）を生成します．
+package quiz_yaml_converter
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"strings"
+)
+
+// DefaultQRScale はQRコードのPNG画像で1モジュールに使う画素数の既定値．
+const DefaultQRScale = 4
+
+// QRコードの周囲に置く余白（クワイエットゾーン）のモジュール数
+const qrQuietZone = 4
+
+// 型番ごとの誤り訂正レベルMの1ブロックあたりの誤り訂正コード語数（添字は型番）
+var qrECCPerBlock = [41]int{-1,
+	10, 16, 26, 18, 24, 16, 18, 22, 22, 26, 30, 22, 22, 24, 24, 28, 28, 26, 26, 26,
+	26, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28}
+
+// 型番ごとの誤り訂正レベルMのブロック数（添字は型番）
+var qrECCBlocks = [41]int{-1,
+	1, 1, 1, 2, 2, 4, 4, 4, 5, 5, 5, 8, 9, 9, 10, 10, 11, 13, 14, 16,
+	17, 17, 18, 20, 21, 23, 25, 26, 28, 29, 31, 33, 35, 37, 38, 40, 43, 45, 47, 49}
+
+// QRCode は生成したQRコードのモジュール（白黒の升目）．
+type QRCode struct {
+	version int
+	size    int
+	modules [][]bool // [y][x]，trueが黒
+}
+
+// qrBuilder はQRコードを組み立てる途中の状態．
+type qrBuilder struct {
+	QRCode
+	isFunction [][]bool // 位置検出パターンなど，データを置かない機能パターンのモジュール
+}
+
+// EncodeQR はtextをUTF-8のバイト列として埋め込んだQRコードを生成する．
+// 型番は埋め込めるもののうち最も小さいものを選ぶ．textが長すぎて型番40にも収まらない場合はエラーとなる．
+func EncodeQR(text string) (*QRCode, error) {
+	data := []byte(text)
+	version := 0
+	for v := 1; v <= 40; v++ {
+		if qrDataBits(v, len(data)) <= qrDataCodewords(v)*8 {
+			version = v
+			break
+		}
+	}
+	if version == 0 {
+		return nil, fmt.Errorf("text is too long for a QR code: %d bytes", len(data))
+	}
+
+	// モード指示子（バイトモード），文字数指示子，データ，終端パターン，埋め草コード語
+	var bits qrBitBuffer
+	bits.append(0x4, 4)
+	bits.append(len(data), qrCountBits(version))
+	for _, b := range data {
+		bits.append(int(b), 8)
+	}
+	capacity := qrDataCodewords(version) * 8
+	bits.append(0, min(4, capacity-bits.len()))
+	bits.append(0, (8-bits.len()%8)%8)
+	for pad := 0xEC; bits.len() < capacity; pad ^= 0xEC ^ 0x11 {
+		bits.append(pad, 8)
+	}
+
+	b := newQRBuilder(version)
+	b.drawFunctionPatterns()
+	b.drawCodewords(qrAddECCAndInterleave(version, bits.bytes()))
+
+	// 評価が最も良い（失点の少ない）マスクを選ぶ
+	bestMask, bestPenalty := 0, -1
+	for mask := 0; mask < 8; mask++ {
+		b.applyMask(mask)
+		b.drawFormatBits(mask)
+		if penalty := b.penalty(); bestPenalty < 0 || penalty < bestPenalty {
+			bestMask, bestPenalty = mask, penalty
+		}
+		b.applyMask(mask) // マスクはXORのため，もう一度適用すると元に戻る
+	}
+	b.applyMask(bestMask)
+	b.drawFormatBits(bestMask)
+	return &b.QRCode, nil
+}
+
+// Version はQRコードの型番（1〜40）を返す．
+func (q *QRCode) Version() int {
+	return q.version
+}
+
+// Size は1辺のモジュール数（余白を除く）を返す．
+func (q *QRCode) Size() int {
+	return q.size
+}
+
+// Dark は(x, y)のモジュールが黒かを返す．範囲外の場合はfalseを返す．
+func (q *QRCode) Dark(x, y int) bool {
+	return x >= 0 && y >= 0 && x < q.size && y < q.size && q.modules[y][x]
+}
+
+// SVG はQRコードを，周囲に4モジュールの余白を付けたSVG画像として返す．
+// 大きさはviewBoxのみで指定するため，HTMLに埋め込む場合はCSSで幅を指定する．
+func (q *QRCode) SVG() string {
+	full := q.size + qrQuietZone*2
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 %d %d" shape-rendering="crispEdges">`, full, full)
+	b.WriteString(`<rect width="100%" height="100%" fill="#fff"/><path fill="#000" d="`)
+	for y := 0; y < q.size; y++ {
+		for x := 0; x < q.size; x++ {
+			if q.modules[y][x] {
+				fmt.Fprintf(&b, "M%d,%dh1v1h-1z", x+qrQuietZone, y+qrQuietZone)
+			}
+		}
+	}
+	b.WriteString(`"/></svg>`)
+	return b.String()
+}
+
+// PNG はQRコードを，周囲に4モジュールの余白を付け，1モジュールをscale画素四方としたPNG画像として返す．
+// scaleが0以下の場合はDefaultQRScaleとする．
+func (q *QRCode) PNG(scale int) ([]byte, error) {
+	if scale <= 0 {
+		scale = DefaultQRScale
+	}
+	full := (q.size + qrQuietZone*2) * scale
+	img := image.NewPaletted(image.Rect(0, 0, full, full), color.Palette{color.White, color.Black})
+	for y := 0; y < full; y++ {
+		for x := 0; x < full; x++ {
+			if q.Dark(x/scale-qrQuietZone, y/scale-qrQuietZone) {
+				img.SetColorIndex(x, y, 1)
+			}
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, fmt.Errorf("failed to encode QR code: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// DataURI はQRコードのPNG画像（1モジュールをDefaultQRScale画素四方とする）を，
+// HTMLのimg要素のsrcに指定できるdata URIとして返す．
+func (q *QRCode) DataURI() (string, error) {
+	b, err := q.PNG(DefaultQRScale)
+	if err != nil {
+		return "", err
+	}
+	return "data:image/png;base64," + base64.StdEncoding.EncodeToString(b), nil
+}
+
+// QRURL は問題のQRコードに埋め込む文字列を返す．
+// urlFormatの"{id}"を問題ID（idがなければContentID）に置き換える．urlFormatが空の場合は問題IDを返す．
+func QRURL(item QuizItem, urlFormat string) string {
+	if urlFormat == "" {
+		return resultsID(item)
+	}
+	return strings.ReplaceAll(urlFormat, "{id}", resultsID(item))
+}
+
+// qrCountBits はバイトモードの文字数指示子のビット数を返す．
+func qrCountBits(version int) int {
+	if version <= 9 {
+		return 8
+	}
+	return 16
+}
+
+// qrDataBits はバイトモードでnバイトを埋め込むのに必要なビット数（終端パターンを除く）を返す．
+func qrDataBits(version, n int) int {
+	return 4 + qrCountBits(version) + n*8
+}
+
+// qrRawDataModules は機能パターンを除いた，コード語を置けるモジュールの数を返す．
+func qrRawDataModules(version int) int {
+	result := (16*version+128)*version + 64
+	if version >= 2 {
+		numAlign := version/7 + 2
+		result -= (25*numAlign-10)*numAlign - 55
+		if version >= 7 {
+			result -= 36
+		}
+	}
+	return result
+}
+
+// qrDataCodewords は誤り訂正レベルMで埋め込めるデータコード語の数を返す．
+func qrDataCodewords(version int) int {
+	return qrRawDataModules(version)/8 - qrECCPerBlock[version]*qrECCBlocks[version]
+}
+
+// qrAlignmentPositions は位置合わせパターンの中心の座標（行と列で共通）を返す．
+func qrAlignmentPositions(version int) []int {
+	if version == 1 {
+		return nil
+	}
+	numAlign := version/7 + 2
+	step := (version*4 + numAlign*2 + 1) / (numAlign*2 - 2) * 2
+	if version == 32 {
+		step = 26
+	}
+	size := version*4 + 17
+	result := make([]int, numAlign)
+	result[0] = 6
+	for i, pos := numAlign-1, size-7; i >= 1; i, pos = i-1, pos-step {
+		result[i] = pos
+	}
+	return result
+}
+
+// qrAddECCAndInterleave はデータコード語をブロックに分けて誤り訂正コード語を加え，ブロックを交互に並べる．
+func qrAddECCAndInterleave(version int, data []byte) []byte {
+	numBlocks := qrECCBlocks[version]
+	eccLen := qrECCPerBlock[version]
+	rawCodewords := qrRawDataModules(version) / 8
+	numShortBlocks := numBlocks - rawCodewords%numBlocks
+	shortBlockLen := rawCodewords / numBlocks
+
+	divisor := qrReedSolomonDivisor(eccLen)
+	blocks := make([][]byte, numBlocks)
+	for i, k := 0, 0; i < numBlocks; i++ {
+		n := shortBlockLen - eccLen
+		if i >= numShortBlocks {
+			n++
+		}
+		block := append([]byte(nil), data[k:k+n]...)
+		k += n
+		ecc := qrReedSolomonRemainder(block, divisor)
+		if i < numShortBlocks {
+			block = append(block, 0) // 短いブロックの長さを揃えるための詰め物（並べるときに飛ばす）
+		}
+		blocks[i] = append(block, ecc...)
+	}
+
+	result := make([]byte, 0, rawCodewords)
+	for i := 0; i < len(blocks[0]); i++ {
+		for j, block := range blocks {
+			if i != shortBlockLen-eccLen || j >= numShortBlocks {
+				result = append(result, block[i])
+			}
+		}
+	}
+	return result
+}
+
+// qrReedSolomonDivisor はdegree次のリード・ソロモン符号の生成多項式の係数（最高次を除く）を返す．
+func qrReedSolomonDivisor(degree int) []byte {
+	result := make([]byte, degree)
+	result[degree-1] = 1
+	root := byte(1)
+	for i := 0; i < degree; i++ {
+		for j := range result {
+			result[j] = qrMultiply(result[j], root)
+			if j+1 < len(result) {
+				result[j] ^= result[j+1]
+			}
+		}
+		root = qrMultiply(root, 0x02)
+	}
+	return result
+}
+
+// qrReedSolomonRemainder はdataを生成多項式で割った余り（誤り訂正コード語）を返す．
+func qrReedSolomonRemainder(data, divisor []byte) []byte {
+	result := make([]byte, len(divisor))
+	for _, b := range data {
+		factor := b ^ result[0]
+		copy(result, result[1:])
+		result[len(result)-1] = 0
+		for i, d := range divisor {
+			result[i] ^= qrMultiply(d, factor)
+		}
+	}
+	return result
+}
+
+// qrMultiply はGF(2^8)（原始多項式x^8+x^4+x^3+x^2+1）での積を返す．
+func qrMultiply(x, y byte) byte {
+	z := 0
+	for i := 7; i >= 0; i-- {
+		z = (z << 1) ^ ((z >> 7) * 0x11D)
+		z ^= int((y>>i)&1) * int(x)
+	}
+	return byte(z)
+}
+
+// qrBitBuffer はビット単位で書き込むバッファ．
+type qrBitBuffer []bool
+
+func (b *qrBitBuffer) append(value, n int) {
+	for i := n - 1; i >= 0; i-- {
+		*b = append(*b, (value>>i)&1 != 0)
+	}
+}
+
+func (b qrBitBuffer) len() int {
+	return len(b)
+}
+
+// bytes は8ビットずつ上位ビットから詰めたバイト列を返す．長さは8の倍数とする．
+func (b qrBitBuffer) bytes() []byte {
+	result := make([]byte, len(b)/8)
+	for i, bit := range b {
+		if bit {
+			result[i/8] |= 0x80 >> (i % 8)
+		}
+	}
+	return result
+}
+
+func newQRBuilder(version int) *qrBuilder {
+	size := version*4 + 17
+	b := &qrBuilder{QRCode: QRCode{version: version, size: size}}
+	b.modules = make([][]bool, size)
+	b.isFunction = make([][]bool, size)
+	for y := range size {
+		b.modules[y] = make([]bool, size)
+		b.isFunction[y] = make([]bool, size)
+	}
+	return b
+}
+
+// setFunction は機能パターンのモジュールを置く．
+func (b *qrBuilder) setFunction(x, y int, dark bool) {
+	b.modules[y][x] = dark
+	b.isFunction[y][x] = true
+}
+
+// drawFunctionPatterns はタイミングパターン，位置検出パターン，位置合わせパターン，
+// 形式情報（仮の値）と型番情報を置く．
+func (b *qrBuilder) drawFunctionPatterns() {
+	for i := 0; i < b.size; i++ {
+		b.setFunction(6, i, i%2 == 0)
+		b.setFunction(i, 6, i%2 == 0)
+	}
+	for _, c := range [][2]int{{3, 3}, {b.size - 4, 3}, {3, b.size - 4}} {
+		for dy := -4; dy <= 4; dy++ {
+			for dx := -4; dx <= 4; dx++ {
+				x, y := c[0]+dx, c[1]+dy
+				if x < 0 || y < 0 || x >= b.size || y >= b.size {
+					continue
+				}
+				dist := max(abs(dx), abs(dy))
+				b.setFunction(x, y, dist != 2 && dist != 4)
+			}
+		}
+	}
+	positions := qrAlignmentPositions(b.version)
+	last := len(positions) - 1
+	for i, cx := range positions {
+		for j, cy := range positions {
+			// 位置検出パターンと重なる3隅には置かない
+			if (i == 0 && j == 0) || (i == 0 && j == last) || (i == last && j == 0) {
+				continue
+			}
+			for dy := -2; dy <= 2; dy++ {
+				for dx := -2; dx <= 2; dx++ {
+					b.setFunction(cx+dx, cy+dy, max(abs(dx), abs(dy)) != 1)
+				}
+			}
+		}
+	}
+	b.drawFormatBits(0)
+	b.drawVersion()
+}
+
+// drawFormatBits は誤り訂正レベルMとmaskの形式情報を2か所に置く．
+func (b *qrBuilder) drawFormatBits(mask int) {
+	data := mask // 誤り訂正レベルMの指示子は00
+	rem := data
+	for i := 0; i < 10; i++ {
+		rem = (rem << 1) ^ ((rem >> 9) * 0x537)
+	}
+	bits := (data<<10 | rem) ^ 0x5412
+	bit := func(i int) bool { return (bits>>i)&1 != 0 }
+
+	for i := 0; i <= 5; i++ {
+		b.setFunction(8, i, bit(i))
+	}
+	b.setFunction(8, 7, bit(6))
+	b.setFunction(8, 8, bit(7))
+	b.setFunction(7, 8, bit(8))
+	for i := 9; i < 15; i++ {
+		b.setFunction(14-i, 8, bit(i))
+	}
+	for i := 0; i < 8; i++ {
+		b.setFunction(b.size-1-i, 8, bit(i))
+	}
+	for i := 8; i < 15; i++ {
+		b.setFunction(8, b.size-15+i, bit(i))
+	}
+	b.setFunction(8, b.size-8, true) // 常に黒のモジュール
+}
+
+// drawVersion は型番7以上の場合に型番情報を2か所に置く．
+func (b *qrBuilder) drawVersion() {
+	if b.version < 7 {
+		return
+	}
+	rem := b.version
+	for i := 0; i < 12; i++ {
+		rem = (rem << 1) ^ ((rem >> 11) * 0x1F25)
+	}
+	bits := b.version<<12 | rem
+	for i := 0; i < 18; i++ {
+		dark := (bits>>i)&1 != 0
+		x, y := b.size-11+i%3, i/3
+		b.setFunction(x, y, dark)
+		b.setFunction(y, x, dark)
+	}
+}
+
+// drawCodewords はコード語を右下から2列ずつジグザグに，機能パターン以外のモジュールに置く．
+func (b *qrBuilder) drawCodewords(data []byte) {
+	i := 0
+	for right := b.size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5 // 縦のタイミングパターンの列は飛ばす
+		}
+		upward := (right+1)&2 == 0
+		for vert := 0; vert < b.size; vert++ {
+			y := vert
+			if upward {
+				y = b.size - 1 - vert
+			}
+			for j := 0; j < 2; j++ {
+				x := right - j
+				if b.isFunction[y][x] || i >= len(data)*8 {
+					continue
+				}
+				b.modules[y][x] = (data[i/8]>>(7-i%8))&1 != 0
+				i++
+			}
+		}
+	}
+}
+
+// applyMask は機能パターン以外のモジュールをmaskの条件で反転する．
+func (b *qrBuilder) applyMask(mask int) {
+	for y := 0; y < b.size; y++ {
+		for x := 0; x < b.size; x++ {
+			if b.isFunction[y][x] {
+				continue
+			}
+			var invert bool
+			switch mask {
+			case 0:
+				invert = (x+y)%2 == 0
+			case 1:
+				invert = y%2 == 0
+			case 2:
+				invert = x%3 == 0
+			case 3:
+				invert = (x+y)%3 == 0
+			case 4:
+				invert = (x/3+y/2)%2 == 0
+			case 5:
+				invert = x*y%2+x*y%3 == 0
+			case 6:
+				invert = (x*y%2+x*y%3)%2 == 0
+			case 7:
+				invert = ((x+y)%2+x*y%3)%2 == 0
+			}
+			if invert {
+				b.modules[y][x] = !b.modules[y][x]
+			}
+		}
+	}
+}
+
+// 位置検出パターンと紛らわしい並び（黒白黒黒黒白黒の前後に白4つ）
+var qrFinderLike = [2][]bool{
+	{true, false, true, true, true, false, true, false, false, false, false},
+	{false, false, false, false, true, false, true, true, true, false, true},
+}
+
+// penalty はマスクを選ぶための失点（同色の連続，2×2の同色，位置検出パターンに似た並び，黒の割合の偏り）を返す．
+func (b *qrBuilder) penalty() int {
+	result := 0
+	line := make([]bool, b.size)
+	for _, vertical := range []bool{false, true} {
+		for i := 0; i < b.size; i++ {
+			for j := 0; j < b.size; j++ {
+				if vertical {
+					line[j] = b.modules[j][i]
+				} else {
+					line[j] = b.modules[i][j]
+				}
+			}
+			run := 1
+			for j := 1; j <= b.size; j++ {
+				if j < b.size && line[j] == line[j-1] {
+					run++
+					continue
+				}
+				if run >= 5 {
+					result += run - 2
+				}
+				run = 1
+			}
+			for j := 0; j+len(qrFinderLike[0]) <= b.size; j++ {
+				for _, pattern := range qrFinderLike {
+					match := true
+					for k, dark := range pattern {
+						if line[j+k] != dark {
+							match = false
+							break
+						}
+					}
+					if match {
+						result += 40
+					}
+				}
+			}
+		}
+	}
+	dark := 0
+	for y := 0; y < b.size; y++ {
+		for x := 0; x < b.size; x++ {
+			if b.modules[y][x] {
+				dark++
+			}
+			if x+1 < b.size && y+1 < b.size {
+				c := b.modules[y][x]
+				if b.modules[y][x+1] == c && b.modules[y+1][x] == c && b.modules[y+1][x+1] == c {
+					result += 3
+				}
+			}
+		}
+	}
+	total := b.size * b.size
+	percent := dark * 100 / total
+	result += abs(percent-50) / 5 * 10
+	return result
+}
@@ -0,0 +1,255 @@
+package quiz_yaml_converter
+
+import (
+	"bytes"
+	"image/png"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestQRDataCodewords(t *testing.T) {
+	// 誤り訂正レベルMのデータコード語数（JIS X 0510の表）
+	for version, want := range map[int]int{1: 16, 2: 28, 3: 44, 4: 64, 5: 86, 7: 124, 10: 216, 20: 669, 40: 2334} {
+		if got := qrDataCodewords(version); got != want {
+			t.Errorf("qrDataCodewords(%d) = %d, want %d", version, got, want)
+		}
+	}
+}
+
+func TestQRReedSolomon(t *testing.T) {
+	// 型番1-Mの"HELLO WORLD"（英数字モード）のデータコード語と誤り訂正コード語
+	data := []byte{32, 91, 11, 120, 209, 114, 220, 77, 67, 64, 236, 17, 236, 17, 236, 17}
+	expected := []byte{196, 35, 39, 119, 235, 215, 231, 226, 93, 23}
+	if got := qrReedSolomonRemainder(data, qrReedSolomonDivisor(10)); !reflect.DeepEqual(got, expected) {
+		t.Errorf("qrReedSolomonRemainder() = %v, want %v", got, expected)
+	}
+}
+
+func TestQRAlignmentPositions(t *testing.T) {
+	tests := map[int][]int{1: nil, 2: {6, 18}, 7: {6, 22, 38}, 22: {6, 26, 50, 74, 98}, 32: {6, 34, 60, 86, 112, 138}, 40: {6, 30, 58, 86, 114, 142, 170}}
+	for version, expected := range tests {
+		if got := qrAlignmentPositions(version); !reflect.DeepEqual(got, expected) {
+			t.Errorf("qrAlignmentPositions(%d) = %v, want %v", version, got, expected)
+		}
+	}
+}
+
+func TestEncodeQR(t *testing.T) {
+	tests := []struct {
+		text    string
+		version int
+	}{
+		{"q-0123456789", 1},
+		{strings.Repeat("a", 14), 1},
+		{strings.Repeat("a", 15), 2},
+		{"https://example.com/answers/q-aa5cc3e0954e", 3},
+		{strings.Repeat("あ", 100), 13},
+		{strings.Repeat("a", 2331), 40},
+	}
+	for _, tt := range tests {
+		q, err := EncodeQR(tt.text)
+		if err != nil {
+			t.Fatalf("EncodeQR(%d bytes) error = %v", len(tt.text), err)
+		}
+		if q.Version() != tt.version || q.Size() != tt.version*4+17 {
+			t.Errorf("EncodeQR(%d bytes) version = %d, size = %d, want version %d", len(tt.text), q.Version(), q.Size(), tt.version)
+		}
+		if got := decodeQRForTest(t, q); got != tt.text {
+			t.Errorf("decoded %q, want %q", got, tt.text)
+		}
+	}
+	if _, err := EncodeQR(strings.Repeat("a", 2332)); err == nil {
+		t.Error("EncodeQR() with too long text expected error")
+	}
+}
+
+func TestQRVersionInformation(t *testing.T) {
+	q, err := EncodeQR(strings.Repeat("a", 110))
+	if err != nil {
+		t.Fatalf("EncodeQR() error = %v", err)
+	}
+	if q.Version() != 7 {
+		t.Fatalf("EncodeQR() version = %d, want 7", q.Version())
+	}
+	// 型番7の型番情報は000111110010010100
+	bits := 0
+	for i := 17; i >= 0; i-- {
+		bits <<= 1
+		if q.Dark(q.Size()-11+i%3, i/3) {
+			bits |= 1
+		}
+	}
+	if bits != 0x07C94 {
+		t.Errorf("version information = %018b, want %018b", bits, 0x07C94)
+	}
+}
+
+func TestQRImages(t *testing.T) {
+	q, err := EncodeQR("q-aa5cc3e0954e")
+	if err != nil {
+		t.Fatalf("EncodeQR() error = %v", err)
+	}
+	svg := q.SVG()
+	if !strings.HasPrefix(svg, `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 29 29"`) || !strings.Contains(svg, "M4,4h1v1h-1z") {
+		t.Errorf("SVG() = %s", svg)
+	}
+	b, err := q.PNG(2)
+	if err != nil {
+		t.Fatalf("PNG() error = %v", err)
+	}
+	img, err := png.Decode(bytes.NewReader(b))
+	if err != nil {
+		t.Fatalf("png.Decode() error = %v", err)
+	}
+	if size := img.Bounds().Dx(); size != 58 {
+		t.Errorf("PNG() width = %d, want 58", size)
+	}
+	uri, err := q.DataURI()
+	if err != nil || !strings.HasPrefix(uri, "data:image/png;base64,") {
+		t.Errorf("DataURI() = %q, %v", uri, err)
+	}
+}
+
+func TestQRURL(t *testing.T) {
+	item := QuizItem{Question: "問題", Answer: "答え"}
+	if got := QRURL(item, ""); got != ContentID(item) {
+		t.Errorf("QRURL() = %q, want %q", got, ContentID(item))
+	}
+	item.ID = "q1"
+	if got := QRURL(item, "https://example.com/answers/{id}.html"); got != "https://example.com/answers/q1.html" {
+		t.Errorf("QRURL() = %q", got)
+	}
+}
+
+func TestTemplateFuncs_QR(t *testing.T) {
+	items := []QuizItem{{ID: "q1", Question: "問題", Answer: "答え"}}
+	opts := ConvertOptions{
+		QRURL:        "https://example.com/answers/{id}",
+		TemplateText: `{{range .Items}}{{qrURL .}}|{{qrSVG (qrURL .)}}|{{qrDataURI .ID}}{{end}}`,
+	}
+	var buf bytes.Buffer
+	if err := WriteTemplate(&buf, items, "qr.html", opts); err != nil {
+		t.Fatalf("WriteTemplate() error = %v", err)
+	}
+	parts := strings.Split(buf.String(), "|")
+	if len(parts) != 3 {
+		t.Fatalf("template output = %q", buf.String())
+	}
+	q, _ := EncodeQR("https://example.com/answers/q1")
+	if parts[0] != "https://example.com/answers/q1" || parts[1] != q.SVG() || !strings.HasPrefix(parts[2], "data:image/png;base64,") {
+		t.Errorf("template output = %q", buf.String())
+	}
+}
+
+// decodeQRForTest は形式情報を読み，マスクを外してコード語を取り出し，
+// 誤り訂正コード語の検査（シンドロームが0）をしたうえで，バイトモードのデータを返す．
+func decodeQRForTest(t *testing.T, q *QRCode) string {
+	t.Helper()
+	// 左上の形式情報（0〜5はx=8の列，以降は位置検出パターンを避けて並ぶ）
+	coords := [][2]int{{8, 0}, {8, 1}, {8, 2}, {8, 3}, {8, 4}, {8, 5}, {8, 7}, {8, 8}, {7, 8}, {5, 8}, {4, 8}, {3, 8}, {2, 8}, {1, 8}, {0, 8}}
+	format := 0
+	for i, c := range coords {
+		if q.Dark(c[0], c[1]) {
+			format |= 1 << i
+		}
+	}
+	format ^= 0x5412
+	if level := format >> 13; level != 0 {
+		t.Fatalf("error correction level = %02b, want 00 (M)", level)
+	}
+	mask := (format >> 10) & 7
+
+	// 機能パターンの位置は同じ型番のqrBuilderから求める
+	b := newQRBuilder(q.Version())
+	b.drawFunctionPatterns()
+	b.modules = q.modules
+	b.applyMask(mask)
+	defer b.applyMask(mask)
+
+	var codewords []byte
+	var cur byte
+	n := 0
+	for right := q.size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5
+		}
+		upward := (right+1)&2 == 0
+		for vert := 0; vert < q.size; vert++ {
+			y := vert
+			if upward {
+				y = q.size - 1 - vert
+			}
+			for j := 0; j < 2; j++ {
+				if b.isFunction[y][right-j] {
+					continue
+				}
+				cur <<= 1
+				if q.modules[y][right-j] {
+					cur |= 1
+				}
+				if n++; n%8 == 0 {
+					codewords = append(codewords, cur)
+				}
+			}
+		}
+	}
+
+	// ブロックに戻して誤り訂正コード語を検査する
+	version := q.Version()
+	numBlocks, eccLen := qrECCBlocks[version], qrECCPerBlock[version]
+	rawCodewords := qrRawDataModules(version) / 8
+	numShort := numBlocks - rawCodewords%numBlocks
+	shortLen := rawCodewords / numBlocks
+	blocks := make([][]byte, numBlocks)
+	for j := range blocks {
+		blocks[j] = make([]byte, shortLen+1)
+	}
+	k := 0
+	for i := 0; i < shortLen+1; i++ {
+		for j := range blocks {
+			if i != shortLen-eccLen || j >= numShort {
+				blocks[j][i] = codewords[k]
+				k++
+			}
+		}
+	}
+	for j := 0; j < numShort; j++ {
+		blocks[j] = append(blocks[j][:shortLen-eccLen], blocks[j][shortLen-eccLen+1:]...)
+	}
+	var data []byte
+	for j, block := range blocks {
+		for e := 0; e < eccLen; e++ {
+			alpha := byte(1)
+			for range e {
+				alpha = qrMultiply(alpha, 2)
+			}
+			syndrome := byte(0)
+			for _, c := range block {
+				syndrome = qrMultiply(syndrome, alpha) ^ c
+			}
+			if syndrome != 0 {
+				t.Fatalf("block %d: syndrome %d is %d", j, e, syndrome)
+			}
+		}
+		data = append(data, block[:len(block)-eccLen]...)
+	}
+
+	bits := func(start, count int) int {
+		v := 0
+		for i := start; i < start+count; i++ {
+			v = v<<1 | int(data[i/8]>>(7-i%8))&1
+		}
+		return v
+	}
+	if mode := bits(0, 4); mode != 4 {
+		t.Fatalf("mode = %04b, want 0100", mode)
+	}
+	countBits := qrCountBits(version)
+	length := bits(4, countBits)
+	text := make([]byte, length)
+	for i := range text {
+		text[i] = byte(bits(4+countBits+i*8, 8))
+	}
+	return string(text)
+}
@@ -0,0 +1,65 @@
+package quiz_yaml_converter
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// RuleQuestionEnding は問題文の末尾が指定した形式と一致しないことを表す警告の規則名．
+const RuleQuestionEnding = "question-ending"
+
+// QuestionEndings は問題文の末尾として認める形式の正規表現のリスト．
+type QuestionEndings []*regexp.Regexp
+
+// ParseQuestionEndings は問題文の末尾の形式を表す正規表現のリストを解析する．
+// 各正規表現は問題文の末尾（前後の空白を除く）に一致するかを調べるため，末尾の$は不要．
+func ParseQuestionEndings(patterns []string) (QuestionEndings, error) {
+	endings := make(QuestionEndings, 0, len(patterns))
+	for _, pattern := range patterns {
+		re, err := regexp.Compile("(?:" + pattern + ")$")
+		if err != nil {
+			return nil, fmt.Errorf("invalid question ending %q: %w", pattern, err)
+		}
+		endings = append(endings, re)
+	}
+	return endings, nil
+}
+
+// Match は問題文の末尾がいずれかの形式に一致するかを返す．形式が1つもない場合はtrueを返す．
+func (e QuestionEndings) Match(question string) bool {
+	if len(e) == 0 {
+		return true
+	}
+	question = strings.TrimSpace(question)
+	for _, re := range e {
+		if re.MatchString(question) {
+			return true
+		}
+	}
+	return false
+}
+
+// String は形式の一覧を" | "でつないで返す．
+func (e QuestionEndings) String() string {
+	patterns := make([]string, len(e))
+	for i, re := range e {
+		patterns[i] = strings.TrimSuffix(strings.TrimPrefix(re.String(), "(?:"), ")$")
+	}
+	return strings.Join(patterns, " | ")
+}
+
+// QuestionEndingWarnings は問題文の末尾がいずれの形式にも一致しない問題についての警告を返す．
+// 「〜である。」のような平叙文で終わる問題を，読み手に渡る前に見つけるのに使う．
+// 問題文が空の問題はバリデーションエラーとなるため対象外とする．
+func QuestionEndingWarnings(data []QuizItem, endings QuestionEndings) []ValidationError {
+	var warnings []ValidationError
+	for i, item := range data {
+		if strings.TrimSpace(item.Question) == "" || endings.Match(item.Question) {
+			continue
+		}
+		warnings = append(warnings, newValidationError(i+1, "question", RuleQuestionEnding, nil,
+			"問題文 (question) の末尾が指定された形式（%s）と一致しません", endings))
+	}
+	return warnings
+}
@@ -0,0 +1,70 @@
+package quiz_yaml_converter
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestQuestionEndings_Match(t *testing.T) {
+	endings, err := ParseQuestionEndings([]string{"でしょう？", "何(年|人)？"})
+	if err != nil {
+		t.Fatalf("ParseQuestionEndings() error = %v", err)
+	}
+	tests := []struct {
+		question string
+		expected bool
+	}{
+		{"ドイツの首都はどこでしょう？", true},
+		{"ドイツの首都はどこでしょう？\n", true},
+		{"日本の人口は何人？", true},
+		{"ドイツの首都はベルリンである。", false},
+		{"どこでしょう？と聞かれた", false},
+		{"何でしょう", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.question, func(t *testing.T) {
+			if got := endings.Match(tt.question); got != tt.expected {
+				t.Errorf("Match(%q) = %v, want %v", tt.question, got, tt.expected)
+			}
+		})
+	}
+
+	if !(QuestionEndings{}).Match("ベルリンである。") {
+		t.Error("Match() with no endings should be true")
+	}
+	if got := endings.String(); got != "でしょう？ | 何(年|人)？" {
+		t.Errorf("String() = %q", got)
+	}
+	if _, err := ParseQuestionEndings([]string{"("}); err == nil {
+		t.Error("ParseQuestionEndings(\"(\") expected error")
+	}
+}
+
+func TestQuestionEndingWarnings(t *testing.T) {
+	endings, err := ParseQuestionEndings([]string{"？"})
+	if err != nil {
+		t.Fatalf("ParseQuestionEndings() error = %v", err)
+	}
+	data := []QuizItem{
+		{Question: "ドイツの首都はどこでしょう？", Answer: "ベルリン"},
+		{Question: "ドイツの首都はベルリンである。", Answer: "ベルリン"},
+		{Question: "", Answer: "空"},
+	}
+	warnings := QuestionEndingWarnings(data, endings)
+	var got []string
+	for _, w := range warnings {
+		got = append(got, w.Error())
+	}
+	expected := []string{"問題 2: 問題文 (question) の末尾が指定された形式（？）と一致しません"}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("QuestionEndingWarnings() = %q, want %q", got, expected)
+	}
+	if len(warnings) == 1 {
+		if warnings[0].Rule != RuleQuestionEnding {
+			t.Errorf("Rule = %q, want %q", warnings[0].Rule, RuleQuestionEnding)
+		}
+		if msg := warnings[0].LocalizedError(LanguageEnglish); msg != "item 2: question does not end with any of the required endings (？)" {
+			t.Errorf("LocalizedError(en) = %q", msg)
+		}
+	}
+}
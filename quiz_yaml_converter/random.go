@@ -0,0 +1,63 @@
+// 問題データから条件に合う問題を無作為に選ぶ処理です．
+package quiz_yaml_converter
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"strings"
+)
+
+// RandomOptions は問題を無作為に選ぶときの条件．
+type RandomOptions struct {
+	Count   int         // 選ぶ問題の数（0の場合は1問）
+	Genres  []string    // 選ぶ問題のジャンル（空の場合はすべてのジャンル）
+	Exclude []string    // 選ばない問題のID（idがない問題はContentIDで照合する）
+	Filter  *FilterExpr // 指定した場合，条件に合う問題だけから選ぶ
+	Seed    int64       // 0以外の場合，この値を乱数のシードとして同じ問題を選ぶ
+}
+
+// RandomItems は条件に合う問題から，重複しないようにopts.Count問を無作為に選んで返す．
+// 条件に合う問題がopts.Countより少ない場合は，条件に合うすべての問題を無作為な順で返す．
+// 2つ目の戻り値は条件に合う問題の数．
+func RandomItems(items []QuizItem, opts RandomOptions) ([]QuizItem, int, error) {
+	if opts.Count < 0 {
+		return nil, 0, fmt.Errorf("count must not be negative: %d", opts.Count)
+	}
+	count := opts.Count
+	if count == 0 {
+		count = 1
+	}
+
+	var candidates []QuizItem
+	for _, item := range items {
+		if len(opts.Genres) > 0 && !slices.Contains(opts.Genres, strings.TrimSpace(item.Genre)) {
+			continue
+		}
+		if len(opts.Exclude) > 0 && slices.Contains(opts.Exclude, resultsID(item)) {
+			continue
+		}
+		if opts.Filter != nil {
+			ok, err := opts.Filter.Match(item)
+			if err != nil {
+				return nil, 0, err
+			}
+			if !ok {
+				continue
+			}
+		}
+		candidates = append(candidates, item)
+	}
+
+	r := rand.New(rand.NewPCG(rand.Uint64(), rand.Uint64()))
+	if opts.Seed != 0 {
+		r = rand.New(rand.NewPCG(uint64(opts.Seed), 0))
+	}
+	// 先頭からcount問だけを確定させる部分的なシャッフル
+	count = min(count, len(candidates))
+	for i := 0; i < count; i++ {
+		j := i + r.IntN(len(candidates)-i)
+		candidates[i], candidates[j] = candidates[j], candidates[i]
+	}
+	return candidates[:count], len(candidates), nil
+}
@@ -0,0 +1,70 @@
+package quiz_yaml_converter
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRandomItems(t *testing.T) {
+	items := []QuizItem{
+		{ID: "q1", Question: "問題1", Answer: "答え1", Genre: "歴史", Difficulty: 1},
+		{ID: "q2", Question: "問題2", Answer: "答え2", Genre: "歴史", Difficulty: 3},
+		{ID: "q3", Question: "問題3", Answer: "答え3", Genre: "科学", Difficulty: 2},
+		{Question: "問題4", Answer: "答え4", Genre: "科学"},
+	}
+	filter, err := ParseFilter("difficulty >= 2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name      string
+		opts      RandomOptions
+		wantIDs   []string // 選ばれうる問題のID（idがない問題はContentID）
+		wantCount int
+		available int
+	}{
+		{"default one", RandomOptions{Seed: 1}, []string{"q1", "q2", "q3", ContentID(items[3])}, 1, 4},
+		{"genre", RandomOptions{Count: 5, Genres: []string{"歴史"}, Seed: 1}, []string{"q1", "q2"}, 2, 2},
+		{"exclude", RandomOptions{Count: 5, Exclude: []string{"q1", ContentID(items[3])}, Seed: 1}, []string{"q2", "q3"}, 2, 2},
+		{"filter", RandomOptions{Count: 2, Filter: filter, Seed: 1}, []string{"q2", "q3"}, 2, 2},
+		{"no match", RandomOptions{Genres: []string{"地理"}}, nil, 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, available, err := RandomItems(items, tt.opts)
+			if err != nil {
+				t.Fatalf("RandomItems() error = %v", err)
+			}
+			if len(got) != tt.wantCount || available != tt.available {
+				t.Fatalf("RandomItems() returned %d of %d items, want %d of %d", len(got), available, tt.wantCount, tt.available)
+			}
+			seen := map[string]bool{}
+			for _, item := range got {
+				id := resultsID(item)
+				if seen[id] {
+					t.Errorf("RandomItems() returned %s twice", id)
+				}
+				seen[id] = true
+				found := false
+				for _, want := range tt.wantIDs {
+					found = found || want == id
+				}
+				if !found {
+					t.Errorf("RandomItems() returned %s, want one of %v", id, tt.wantIDs)
+				}
+			}
+		})
+	}
+
+	first, _, _ := RandomItems(items, RandomOptions{Count: 3, Seed: 42})
+	second, _, _ := RandomItems(items, RandomOptions{Count: 3, Seed: 42})
+	if !reflect.DeepEqual(first, second) {
+		t.Errorf("RandomItems() with the same seed = %v and %v", first, second)
+	}
+	if !reflect.DeepEqual(items[0].ID, "q1") || items[3].ID != "" {
+		t.Error("RandomItems() modified the input")
+	}
+	if _, _, err := RandomItems(items, RandomOptions{Count: -1}); err == nil {
+		t.Error("RandomItems() with a negative count expected error")
+	}
+}
@@ -0,0 +1,148 @@
+// 形態素解析器を使って答えの読み（reading）を求める処理です．
+// 形態素解析器は辞書が大きいため標準では組み込まず，MeCabなどの外部コマンドを使います．
+// kagomeビルドタグを指定してビルドした場合は，Pure Goのkagomeも使えます（reading_kagome.go）．
+package quiz_yaml_converter
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// RuleReadingMismatch は作成者が書いた読みが形態素解析で求めた読みと異なることを表す警告の規則名．
+const RuleReadingMismatch = "reading-mismatch"
+
+// ReadingAnalyzer は漢字を含む文字列の読みを求める形態素解析器．
+// Readingsはtextsの各文字列の読みを同じ順序で返す．読みはひらがなでもカタカナでもよい．
+type ReadingAnalyzer interface {
+	Readings(texts []string) ([]string, error)
+}
+
+// ExecReadingAnalyzer は外部コマンドで読みを求めるReadingAnalyzer．
+// 文字列を1行に1つずつ標準入力に渡し，標準出力の各行をその読みとする．
+// "mecab -Oyomi"のように，1行の入力に対して1行の読みを出力するコマンドを指定する．
+type ExecReadingAnalyzer struct {
+	Path string   // 実行するコマンド
+	Args []string // コマンドの引数
+}
+
+// KagomeReadingAnalyzerSpec はParseReadingAnalyzerで組み込みのkagomeを指定する名前．
+const KagomeReadingAnalyzerSpec = "builtin:kagome"
+
+// ParseReadingAnalyzer は指定からReadingAnalyzerを作成する．
+// KagomeReadingAnalyzerSpecの場合は組み込みのkagome，それ以外は外部コマンドとして扱う．
+func ParseReadingAnalyzer(spec string) (ReadingAnalyzer, error) {
+	if strings.TrimSpace(spec) == KagomeReadingAnalyzerSpec {
+		return NewKagomeReadingAnalyzer()
+	}
+	return ParseExecReadingAnalyzer(spec)
+}
+
+// ParseExecReadingAnalyzer は"コマンド 引数..."形式の指定からExecReadingAnalyzerを作成する．
+// コマンドと引数は空白で区切る．
+func ParseExecReadingAnalyzer(spec string) (ExecReadingAnalyzer, error) {
+	fields := strings.Fields(spec)
+	if len(fields) == 0 {
+		return ExecReadingAnalyzer{}, fmt.Errorf("empty reading command")
+	}
+	return ExecReadingAnalyzer{Path: fields[0], Args: fields[1:]}, nil
+}
+
+// Readings は外部コマンドを1回だけ実行し，textsの各文字列の読みを返す．
+// 文字列中の改行は空白に置き換えて渡す．
+func (a ExecReadingAnalyzer) Readings(texts []string) ([]string, error) {
+	if len(texts) == 0 {
+		return nil, nil
+	}
+	var input, output bytes.Buffer
+	for _, text := range texts {
+		input.WriteString(strings.Join(strings.Fields(text), " "))
+		input.WriteByte('\n')
+	}
+	if err := runCommand(a.Path, a.Args, &input, &output); err != nil {
+		return nil, err
+	}
+	lines := strings.Split(strings.TrimSuffix(strings.ReplaceAll(output.String(), "\r\n", "\n"), "\n"), "\n")
+	if len(lines) < len(texts) {
+		return nil, fmt.Errorf("%s: expected %d lines of readings, got %d", a.Path, len(texts), len(lines))
+	}
+	return lines[:len(texts)], nil
+}
+
+// needsReading は答えに漢字が含まれ，読みを求める必要があるかを返す．
+func needsReading(answer string) bool {
+	return strings.ContainsFunc(answer, func(r rune) bool { return unicode.Is(unicode.Han, r) })
+}
+
+// NormalizeReading は読みを比較できるよう，カタカナをひらがなに直し，空白を取り除く．
+func NormalizeReading(reading string) string {
+	var b strings.Builder
+	for _, r := range reading {
+		if !unicode.IsSpace(r) {
+			b.WriteRune(toHiragana(r))
+		}
+	}
+	return b.String()
+}
+
+// analyzeReadings は漢字を含む答えの読みをanalyzerで求め，問題の位置から読み（ひらがな）への対応を返す．
+// onlyMissingがtrueの場合は，読みが書かれていない問題だけを対象とする．
+func analyzeReadings(items []QuizItem, analyzer ReadingAnalyzer, onlyMissing bool) (map[int]string, error) {
+	var indices []int
+	var texts []string
+	for i, item := range items {
+		if !needsReading(item.Answer) || onlyMissing != (strings.TrimSpace(item.Reading) == "") {
+			continue
+		}
+		indices = append(indices, i)
+		texts = append(texts, strings.TrimSpace(item.Answer))
+	}
+	readings, err := analyzer.Readings(texts)
+	if err != nil {
+		return nil, err
+	}
+	if len(readings) != len(texts) {
+		return nil, fmt.Errorf("expected %d readings, got %d", len(texts), len(readings))
+	}
+	result := make(map[int]string, len(indices))
+	for j, i := range indices {
+		result[i] = NormalizeReading(readings[j])
+	}
+	return result, nil
+}
+
+// FillReadings は読み（reading）が書かれておらず，答えに漢字が含まれる問題に，
+// analyzerで求めた読みをひらがなで設定するStageを作成する．作成者が書いた読みは変更しない．
+func FillReadings(analyzer ReadingAnalyzer) Stage {
+	return StageFunc(func(items []QuizItem) ([]QuizItem, error) {
+		readings, err := analyzeReadings(items, analyzer, true)
+		if err != nil {
+			return nil, err
+		}
+		for i, reading := range readings {
+			items[i].Reading = reading
+		}
+		return items, nil
+	})
+}
+
+// ReadingWarnings は作成者が書いた読みがanalyzerで求めた読みと異なる問題についての警告を返す．
+// 読みはカタカナとひらがな，空白の違いを無視して比較する．
+// 形態素解析の読みは誤ることもあるため，バリデーションエラーではなく警告とする．
+func ReadingWarnings(data []QuizItem, analyzer ReadingAnalyzer) ([]ValidationError, error) {
+	readings, err := analyzeReadings(data, analyzer, false)
+	if err != nil {
+		return nil, err
+	}
+	var warnings []ValidationError
+	for i, item := range data {
+		reading, ok := readings[i]
+		if !ok || reading == "" || reading == NormalizeReading(item.Reading) {
+			continue
+		}
+		warnings = append(warnings, newValidationError(i+1, "reading", RuleReadingMismatch, nil,
+			"読み (reading) が形態素解析で求めた読み「%s」と一致しません", reading))
+	}
+	return warnings, nil
+}
@@ -0,0 +1,46 @@
+//go:build kagome
+
+package quiz_yaml_converter
+
+import (
+	"strings"
+
+	"github.com/ikawaha/kagome-dict/ipa"
+	"github.com/ikawaha/kagome/v2/tokenizer"
+)
+
+// kagomeReadingAnalyzer はPure Goの形態素解析器kagomeとIPA辞書で読みを求めるReadingAnalyzer．
+type kagomeReadingAnalyzer struct {
+	tokenizer *tokenizer.Tokenizer
+}
+
+// NewKagomeReadingAnalyzer はkagomeで読みを求めるReadingAnalyzerを作成する．
+// 外部コマンドを使わないため，MeCabがない環境でも読みを求められる．
+func NewKagomeReadingAnalyzer() (ReadingAnalyzer, error) {
+	t, err := tokenizer.New(ipa.Dict(), tokenizer.OmitBosEos())
+	if err != nil {
+		return nil, err
+	}
+	return kagomeReadingAnalyzer{tokenizer: t}, nil
+}
+
+// Readings はtextsの各文字列を形態素に分け，各形態素の読み（カタカナ）をつなげて返す．
+// 辞書にない語など読みが得られない形態素は，表記をそのまま使う．
+func (a kagomeReadingAnalyzer) Readings(texts []string) ([]string, error) {
+	if len(texts) == 0 {
+		return nil, nil
+	}
+	readings := make([]string, len(texts))
+	for i, text := range texts {
+		var b strings.Builder
+		for _, token := range a.tokenizer.Tokenize(strings.Join(strings.Fields(text), " ")) {
+			if reading, ok := token.Reading(); ok && reading != "*" {
+				b.WriteString(reading)
+			} else {
+				b.WriteString(token.Surface)
+			}
+		}
+		readings[i] = b.String()
+	}
+	return readings, nil
+}
@@ -0,0 +1,39 @@
+//go:build kagome
+
+package quiz_yaml_converter
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestKagomeReadingAnalyzer_Readings(t *testing.T) {
+	analyzer, err := ParseReadingAnalyzer(KagomeReadingAnalyzerSpec)
+	if err != nil {
+		t.Fatalf("ParseReadingAnalyzer() error = %v", err)
+	}
+	tests := []struct {
+		name  string
+		texts []string
+		want  []string
+	}{
+		{"kanji", []string{"国際連合", "東京"}, []string{"こくさいれんごう", "とうきょう"}},
+		{"unknown word keeps surface", []string{"ABC"}, []string{"ABC"}},
+		{"empty", nil, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			readings, err := analyzer.Readings(tt.texts)
+			if err != nil {
+				t.Fatalf("Readings() error = %v", err)
+			}
+			var got []string
+			for _, r := range readings {
+				got = append(got, NormalizeReading(r))
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Readings() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
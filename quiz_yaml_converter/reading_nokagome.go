@@ -0,0 +1,11 @@
+//go:build !kagome
+
+package quiz_yaml_converter
+
+import "errors"
+
+// NewKagomeReadingAnalyzer はkagomeビルドタグなしでビルドした場合，常にエラーを返す．
+// kagomeを使うには"go build -tags kagome"でビルドする．
+func NewKagomeReadingAnalyzer() (ReadingAnalyzer, error) {
+	return nil, errors.New("kagome reading analyzer is not available; rebuild with -tags kagome")
+}
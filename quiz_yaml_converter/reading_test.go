@@ -0,0 +1,158 @@
+package quiz_yaml_converter
+
+import (
+	"errors"
+	"os/exec"
+	"reflect"
+	"testing"
+)
+
+// fakeReadingAnalyzer はテスト用に，決まった読みを返すReadingAnalyzer．
+type fakeReadingAnalyzer struct {
+	readings map[string]string
+	calls    [][]string
+	err      error
+}
+
+func (a *fakeReadingAnalyzer) Readings(texts []string) ([]string, error) {
+	a.calls = append(a.calls, texts)
+	if a.err != nil {
+		return nil, a.err
+	}
+	var result []string
+	for _, text := range texts {
+		result = append(result, a.readings[text])
+	}
+	return result, nil
+}
+
+func TestNormalizeReading(t *testing.T) {
+	tests := []struct {
+		reading string
+		want    string
+	}{
+		{"トウキョウ", "とうきょう"},
+		{"こくさい れんごう", "こくさいれんごう"},
+		{"ジョン・アタナソフ", "じょん・あたなそふ"},
+		{"コンピューター", "こんぴゅーたー"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := NormalizeReading(tt.reading); got != tt.want {
+			t.Errorf("NormalizeReading(%q) = %q, want %q", tt.reading, got, tt.want)
+		}
+	}
+}
+
+func TestFillReadings(t *testing.T) {
+	analyzer := &fakeReadingAnalyzer{readings: map[string]string{
+		"東京":   "トウキョウ",
+		"国際連合": "コクサイレンゴウ",
+	}}
+	items := []QuizItem{
+		{Question: "q1", Answer: "東京"},
+		{Question: "q2", Answer: "ミュンヘン"},
+		{Question: "q3", Answer: "国際連合", Reading: "こくさいれんごう"},
+		{Question: "q4", Answer: " 国際連合 "},
+	}
+	got, err := Pipeline{FillReadings(analyzer)}.Apply(items)
+	if err != nil {
+		t.Fatalf("FillReadings() error = %v", err)
+	}
+	want := []string{"とうきょう", "", "こくさいれんごう", "こくさいれんごう"}
+	for i, item := range got {
+		if item.Reading != want[i] {
+			t.Errorf("item %d Reading = %q, want %q", i+1, item.Reading, want[i])
+		}
+	}
+	if items[0].Reading != "" {
+		t.Errorf("FillReadings() modified the original items")
+	}
+	// 漢字を含み，読みが書かれていない答えだけを1回で解析する
+	if wantCalls := [][]string{{"東京", "国際連合"}}; !reflect.DeepEqual(analyzer.calls, wantCalls) {
+		t.Errorf("Readings() calls = %q, want %q", analyzer.calls, wantCalls)
+	}
+
+	analyzer.err = errors.New("analyzer failed")
+	if _, err := (Pipeline{FillReadings(analyzer)}).Apply(items); err == nil {
+		t.Errorf("FillReadings() with a failing analyzer error = nil, want error")
+	}
+}
+
+func TestReadingWarnings(t *testing.T) {
+	analyzer := &fakeReadingAnalyzer{readings: map[string]string{
+		"東京":   "トウキョウ",
+		"日本":   "ニホン",
+		"国際連合": "コクサイレンゴウ",
+	}}
+	data := []QuizItem{
+		{Question: "q1", Answer: "東京", Reading: "トウキョウ"},
+		{Question: "q2", Answer: "日本", Reading: "にっぽん"},
+		{Question: "q3", Answer: "国際連合"},
+		{Question: "q4", Answer: "ミュンヘン", Reading: "みゅんへん"},
+		{Question: "q5", Answer: "国際連合", Reading: "こくさい れんごう"},
+	}
+	warnings, err := ReadingWarnings(data, analyzer)
+	if err != nil {
+		t.Fatalf("ReadingWarnings() error = %v", err)
+	}
+	if len(warnings) != 1 {
+		t.Fatalf("ReadingWarnings() = %v, want 1 warning", warnings)
+	}
+	if w := warnings[0]; w.Index != 2 || w.Field != "reading" || w.Rule != RuleReadingMismatch {
+		t.Errorf("ReadingWarnings() = %+v, want a %s warning for item 2", w, RuleReadingMismatch)
+	}
+}
+
+func TestParseExecReadingAnalyzer(t *testing.T) {
+	got, err := ParseExecReadingAnalyzer("mecab -Oyomi")
+	if err != nil {
+		t.Fatalf("ParseExecReadingAnalyzer() error = %v", err)
+	}
+	if want := (ExecReadingAnalyzer{Path: "mecab", Args: []string{"-Oyomi"}}); !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseExecReadingAnalyzer() = %+v, want %+v", got, want)
+	}
+	if _, err := ParseExecReadingAnalyzer("  "); err == nil {
+		t.Errorf("ParseExecReadingAnalyzer() with an empty command error = nil, want error")
+	}
+}
+
+func TestParseReadingAnalyzer(t *testing.T) {
+	got, err := ParseReadingAnalyzer("mecab -Oyomi")
+	if err != nil {
+		t.Fatalf("ParseReadingAnalyzer() error = %v", err)
+	}
+	if want := (ExecReadingAnalyzer{Path: "mecab", Args: []string{"-Oyomi"}}); !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseReadingAnalyzer() = %+v, want %+v", got, want)
+	}
+	// kagomeはビルドタグによって使えない場合があるが，使える場合は外部コマンドとして扱わない
+	if a, err := ParseReadingAnalyzer(KagomeReadingAnalyzerSpec); err == nil {
+		if _, ok := a.(ExecReadingAnalyzer); ok {
+			t.Errorf("ParseReadingAnalyzer(%q) = ExecReadingAnalyzer, want the kagome analyzer", KagomeReadingAnalyzerSpec)
+		}
+	}
+}
+
+func TestExecReadingAnalyzer_Readings(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not found")
+	}
+	// 入力をそのまま返すコマンドで，1行に1つずつ渡されることを確かめる
+	a := ExecReadingAnalyzer{Path: "sh", Args: []string{"-c", "cat"}}
+	got, err := a.Readings([]string{"東京", "国際\n連合"})
+	if err != nil {
+		t.Fatalf("Readings() error = %v", err)
+	}
+	if want := []string{"東京", "国際 連合"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Readings() = %q, want %q", got, want)
+	}
+
+	short := ExecReadingAnalyzer{Path: "sh", Args: []string{"-c", "head -n 1"}}
+	if _, err := short.Readings([]string{"東京", "日本", "大阪"}); err == nil {
+		t.Errorf("Readings() with missing lines error = nil, want error")
+	}
+	failing := ExecReadingAnalyzer{Path: "sh", Args: []string{"-c", "exit 1"}}
+	if _, err := failing.Readings([]string{"東京"}); err == nil {
+		t.Errorf("Readings() with a failing command error = nil, want error")
+	}
+}
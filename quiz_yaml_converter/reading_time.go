@@ -0,0 +1,64 @@
+// 問題文の読み上げ時間の見積もりです．
+package quiz_yaml_converter
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+	"unicode"
+)
+
+// DefaultReadingPace は既定の読み上げの速さ（1秒あたりのモーラ数）．
+const DefaultReadingPace = 8.0
+
+// 読み上げ時間の見積もりの最小値
+const minReadingDuration = time.Second
+
+// estimateMora は問題文を読み上げるときのモーラ数を見積もる．
+// 読み（reading）が設定されていればそのモーラ数を使い，設定されていなければ問題文の仮名を1モーラ，
+// 漢字を2モーラ，英数字を1モーラとして数える．
+func estimateMora(item QuizItem) int {
+	if item.Reading != "" {
+		return MoraCount(item.Reading)
+	}
+	question := PlainQuestion(item)
+	count := MoraCount(question)
+	for _, r := range question {
+		switch {
+		case unicode.Is(unicode.Han, r):
+			count += 2
+		case r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)):
+			count++
+		}
+	}
+	return count
+}
+
+// ReadingTime は問題文の読み上げにかかる時間を0.1秒単位で見積もる（最短1秒）．
+// paceは読み上げの速さ（1秒あたりのモーラ数）で，0以下の場合はDefaultReadingPaceとする．
+func ReadingTime(item QuizItem, pace float64) time.Duration {
+	if pace <= 0 {
+		pace = DefaultReadingPace
+	}
+	d := time.Duration(float64(estimateMora(item)) / pace * float64(time.Second))
+	return max(d.Round(100*time.Millisecond), minReadingDuration)
+}
+
+// TotalReadingTime は問題の読み上げ時間の見積もり（ReadingTime）の合計を返す．
+// 1ラウンドの問題の読み上げにかかる時間の目安として使う．
+func TotalReadingTime(items []QuizItem, pace float64) time.Duration {
+	var total time.Duration
+	for _, item := range items {
+		total += ReadingTime(item, pace)
+	}
+	return total
+}
+
+// FormatDuration は時間を"6.5秒"や"12分05秒"のように表す．1分以上の場合は秒未満を四捨五入する．
+func FormatDuration(d time.Duration) string {
+	if d < time.Minute {
+		return strconv.FormatFloat(d.Round(100*time.Millisecond).Seconds(), 'f', -1, 64) + "秒"
+	}
+	s := int64(d.Round(time.Second) / time.Second)
+	return fmt.Sprintf("%d分%02d秒", s/60, s%60)
+}
@@ -0,0 +1,108 @@
+package quiz_yaml_converter
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEstimateMora(t *testing.T) {
+	tests := []struct {
+		name     string
+		item     QuizItem
+		expected int
+	}{
+		{"kana", QuizItem{Question: "きょうはなんにち？"}, 7},
+		{"kanji and kana", QuizItem{Question: "日本の首都は？"}, 10},
+		{"alphanumeric", QuizItem{Question: "ABCは3文字"}, 9},
+		{"segments", QuizItem{Question: "あい／うえ"}, 4},
+		{"reading", QuizItem{Question: "日本の首都は？", Reading: "にっぽんのしゅとは"}, 8},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := estimateMora(tt.item); got != tt.expected {
+				t.Errorf("estimateMora() = %d, want %d", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestReadingTime(t *testing.T) {
+	tests := []struct {
+		name     string
+		question string
+		pace     float64
+		expected time.Duration
+	}{
+		{"minimum", "あ", 0, time.Second},
+		{"default pace", strings.Repeat("あ", 20), 0, 2500 * time.Millisecond},
+		{"rounded", strings.Repeat("あ", 21), 0, 2600 * time.Millisecond},
+		{"slow pace", strings.Repeat("あ", 20), 5, 4 * time.Second},
+		{"negative pace", strings.Repeat("あ", 20), -1, 2500 * time.Millisecond},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ReadingTime(QuizItem{Question: tt.question}, tt.pace); got != tt.expected {
+				t.Errorf("ReadingTime() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestTotalReadingTime(t *testing.T) {
+	items := []QuizItem{{Question: strings.Repeat("あ", 20)}, {Question: "あ"}}
+	if got := TotalReadingTime(items, 0); got != 3500*time.Millisecond {
+		t.Errorf("TotalReadingTime() = %v, want %v", got, 3500*time.Millisecond)
+	}
+	if got := TotalReadingTime(nil, 0); got != 0 {
+		t.Errorf("TotalReadingTime(nil) = %v, want 0", got)
+	}
+}
+
+func TestFormatDuration(t *testing.T) {
+	tests := []struct {
+		d        time.Duration
+		expected string
+	}{
+		{0, "0秒"},
+		{6500 * time.Millisecond, "6.5秒"},
+		{59 * time.Second, "59秒"},
+		{time.Minute, "1分00秒"},
+		{12*time.Minute + 4500*time.Millisecond, "12分05秒"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expected, func(t *testing.T) {
+			if got := FormatDuration(tt.d); got != tt.expected {
+				t.Errorf("FormatDuration(%v) = %q, want %q", tt.d, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestTemplateFuncs_ReadingTime(t *testing.T) {
+	items := []QuizItem{{Question: strings.Repeat("あ", 20), Answer: "a1"}, {Question: strings.Repeat("あ", 40), Answer: "a2"}}
+	opts := ConvertOptions{
+		ReadingPace:  10,
+		TemplateText: `{{range .Items}}{{readingTime .}} {{end}}{{formatDuration (totalReadingTime .Items)}}`,
+	}
+	var buf bytes.Buffer
+	if err := WriteTemplate(&buf, items, "reading.txt", opts); err != nil {
+		t.Fatalf("WriteTemplate() error = %v", err)
+	}
+	if want := "2s 4s 6秒"; buf.String() != want {
+		t.Errorf("template output = %q, want %q", buf.String(), want)
+	}
+
+	buf.Reset()
+	f, err := LookupFormatter("html")
+	if err != nil {
+		t.Fatalf("LookupFormatter() error = %v", err)
+	}
+	if err := f.Format(&buf, items, ConvertOptions{}); err != nil {
+		t.Fatalf("Format() error = %v", err)
+	}
+	if want := "<p>読み上げ時間の目安: 約7.5秒</p>"; !strings.Contains(buf.String(), want) {
+		t.Errorf("output does not contain %q:\n%s", want, buf.String())
+	}
+}
@@ -0,0 +1,190 @@
+// バリデーション結果をCIや編集ツールから扱いやすい形式で出力する処理です．
+package quiz_yaml_converter
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// バリデーションエラーの規則名（ValidationError.Rule）
+const (
+	RuleFileNotFound = "file-not-found" // ファイルが存在しない
+	RuleRead         = "read"           // ファイルを読み込めない
+	RuleSyntax       = "syntax"         // YAMLの構文や構造が正しくない
+	RuleNoItems      = "no-items"       // 問題が1つも含まれていない
+	RuleRequired     = "required"       // 必須フィールドが空
+	RuleEmpty        = "empty"          // リストの要素などが空
+	RuleCriteriaKey  = "criteria-key"   // criteriaのキーが正しくない
+	RuleSegments     = "segments"       // 問題文の区切りが正しくない
+	RuleBOM          = "bom"            // ファイルの先頭にBOMがある（警告）
+	RuleAnswerParens = "answer-parens"  // 答えの括弧書きが曖昧（警告）
+	RuleChoices      = "choices"        // 選択肢に答えが含まれていない
+)
+
+// ValidationReport はToJSONで出力するバリデーション結果．
+type ValidationReport struct {
+	Valid    bool                    `json:"valid"`
+	Items    int                     `json:"items"`
+	Errors   []ValidationReportEntry `json:"errors"`
+	Warnings []ValidationReportEntry `json:"warnings"`
+}
+
+// ValidationReportEntry はValidationReportの1件のエラーまたは警告．
+type ValidationReportEntry struct {
+	ItemIndex int    `json:"item_index,omitempty"` // 問題の番号（1始まり）．ファイル全体に関する場合は省略
+	Field     string `json:"field,omitempty"`
+	Rule      string `json:"rule,omitempty"`
+	File      string `json:"file,omitempty"`
+	Line      int    `json:"line,omitempty"`
+	Message   string `json:"message"`
+}
+
+// Report は指定した言語のメッセージでValidationReportを作成する．
+func (r ValidationResult) Report(lang Language) ValidationReport {
+	entries := func(errs []ValidationError) []ValidationReportEntry {
+		list := make([]ValidationReportEntry, 0, len(errs))
+		for i := range errs {
+			e := &errs[i]
+			list = append(list, ValidationReportEntry{
+				ItemIndex: e.Index,
+				Field:     e.Field,
+				Rule:      e.Rule,
+				File:      e.File,
+				Line:      e.Line,
+				Message:   e.LocalizedMessage(lang),
+			})
+		}
+		return list
+	}
+	return ValidationReport{
+		Valid:    r.IsValid,
+		Items:    r.Items,
+		Errors:   entries(r.ValidationErrors),
+		Warnings: entries(r.Warnings),
+	}
+}
+
+// ToJSON はバリデーション結果を日本語のメッセージでJSONにする．
+func (r ValidationResult) ToJSON() ([]byte, error) {
+	return r.LocalizedJSON(LanguageJapanese)
+}
+
+// LocalizedJSON はバリデーション結果を指定した言語のメッセージでJSONにする．
+func (r ValidationResult) LocalizedJSON(lang Language) ([]byte, error) {
+	return json.MarshalIndent(r.Report(lang), "", "  ")
+}
+
+// ToText はバリデーション結果を日本語のテキストにする．エラー，警告の順に1行ずつ出力する．
+// verboseがtrueの場合は各行の先頭に"ファイル:行: "を，末尾に規則名とフィールドを付ける．
+func (r ValidationResult) ToText(verbose bool) string {
+	return r.LocalizedText(LanguageJapanese, verbose)
+}
+
+// LocalizedText は指定した言語でToTextと同じ形式のテキストを返す．
+func (r ValidationResult) LocalizedText(lang Language, verbose bool) string {
+	var b strings.Builder
+	write := func(e *ValidationError, prefix string) {
+		if verbose {
+			if location := e.location(); location != "" {
+				b.WriteString(location + ": ")
+			}
+		}
+		b.WriteString(prefix + e.LocalizedError(lang))
+		if verbose && (e.Rule != "" || e.Field != "") {
+			b.WriteString(" [" + strings.TrimSpace(e.Rule+" "+e.Field) + "]")
+		}
+		b.WriteString("\n")
+	}
+	for i := range r.ValidationErrors {
+		write(&r.ValidationErrors[i], "")
+	}
+	for i := range r.Warnings {
+		write(&r.Warnings[i], Translate(lang, "警告: "))
+	}
+	return b.String()
+}
+
+// location はエラーの位置を"ファイル:行"の形式で返す．位置が不明な場合は空文字列を返す．
+func (e *ValidationError) location() string {
+	switch {
+	case e.File != "" && e.Line > 0:
+		return e.File + ":" + strconv.Itoa(e.Line)
+	case e.File != "":
+		return e.File
+	case e.Line > 0:
+		return "line " + strconv.Itoa(e.Line)
+	}
+	return ""
+}
+
+// yamlErrorLine はYAMLの解析エラーのメッセージに含まれる行番号を取り出す．
+var yamlErrorLine = regexp.MustCompile(`line (\d+)`)
+
+// errorLine はエラーメッセージから行番号を取り出す．見つからない場合は0を返す．
+func errorLine(err error) int {
+	m := yamlErrorLine.FindStringSubmatch(err.Error())
+	if m == nil {
+		return 0
+	}
+	line, _ := strconv.Atoi(m[1])
+	return line
+}
+
+// yamlItemLines はParseYAMLDataで読み込まれる各問題の開始行を，問題と同じ順序で返す．
+// 解析できない場合はnilを返す．
+func yamlItemLines(yamlData []byte) []int {
+	decoder := yaml.NewDecoder(bytes.NewReader(bytes.TrimPrefix(yamlData, []byte(utf8BOM))))
+	var lines []int
+	for {
+		var node yaml.Node
+		if err := decoder.Decode(&node); err != nil {
+			if errors.Is(err, io.EOF) {
+				return lines
+			}
+			return nil
+		}
+		if len(node.Content) == 0 {
+			continue
+		}
+		root := node.Content[0]
+		sequences := []*yaml.Node{root}
+		if root.Kind == yaml.MappingNode {
+			// ジャンルごとの記述（decodeQuizItems参照）
+			sequences = nil
+			for i := 1; i < len(root.Content); i += 2 {
+				sequences = append(sequences, root.Content[i])
+			}
+		}
+		for _, seq := range sequences {
+			if seq.Kind != yaml.SequenceNode {
+				continue
+			}
+			for _, item := range seq.Content {
+				lines = append(lines, item.Line)
+			}
+		}
+	}
+}
+
+// itemLocation は通し番号の問題が読み込まれたファイルと行．
+type itemLocation struct {
+	file string
+	line int
+}
+
+// locate は問題の番号が付いたエラーにファイルと行を設定する．
+func locate(errs []ValidationError, locations []itemLocation) {
+	for i := range errs {
+		if n := errs[i].Index; n > 0 && n <= len(locations) {
+			errs[i].File = locations[n-1].file
+			errs[i].Line = locations[n-1].line
+		}
+	}
+}
@@ -0,0 +1,157 @@
+package quiz_yaml_converter
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestValidateYAMLFiles_Locations(t *testing.T) {
+	tempDir := t.TempDir()
+	first := filepath.Join(tempDir, "first.yaml")
+	second := filepath.Join(tempDir, "second.yaml")
+	if err := os.WriteFile(first, []byte("- question: q1\n  answer: a1\n"), 0644); err != nil {
+		t.Fatalf("Failed to create YAML file: %v", err)
+	}
+	content := "歴史:\n  - question: q2\n    answer: a2\n\n  - question: \"\"\n    answer: a3\n"
+	if err := os.WriteFile(second, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create YAML file: %v", err)
+	}
+
+	result := ValidateYAMLFiles([]string{first, second})
+	expected := []ValidationReportEntry{
+		{ItemIndex: 3, Field: "question", Rule: RuleRequired, File: second, Line: 5, Message: "問題文 (question) が空です"},
+	}
+	if got := result.Report(LanguageJapanese).Errors; !reflect.DeepEqual(got, expected) {
+		t.Errorf("Report().Errors = %+v, want %+v", got, expected)
+	}
+}
+
+func TestValidateYAMLFiles_SyntaxErrorLine(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "broken.yaml")
+	if err := os.WriteFile(path, []byte("- question: q1\n  answer: a1\n- question: [\n"), 0644); err != nil {
+		t.Fatalf("Failed to create YAML file: %v", err)
+	}
+
+	result := ValidateYAMLFiles([]string{path})
+	if len(result.ValidationErrors) != 1 {
+		t.Fatalf("ValidationErrors = %+v, want one error", result.ValidationErrors)
+	}
+	e := result.ValidationErrors[0]
+	if e.Rule != RuleSyntax || e.File != path || e.Line != 3 {
+		t.Errorf("error = {Rule: %q, File: %q, Line: %d}, want {%q, %q, 3}", e.Rule, e.File, e.Line, RuleSyntax, path)
+	}
+}
+
+func TestValidationResult_ToJSON(t *testing.T) {
+	result := ValidateItems([]QuizItem{{Question: "q1", Answer: ""}})
+	result.Warnings = []ValidationError{{Index: 1, Field: "answer", Rule: RuleAnswerParens, Message: "答え (answer) の括弧の中が空です"}}
+
+	data, err := result.ToJSON()
+	if err != nil {
+		t.Fatalf("ToJSON() error = %v", err)
+	}
+	var report ValidationReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	expected := ValidationReport{
+		Valid:    false,
+		Items:    1,
+		Errors:   []ValidationReportEntry{{ItemIndex: 1, Field: "answer", Rule: RuleRequired, Message: "答え (answer) が空です"}},
+		Warnings: []ValidationReportEntry{{ItemIndex: 1, Field: "answer", Rule: RuleAnswerParens, Message: "答え (answer) の括弧の中が空です"}},
+	}
+	if !reflect.DeepEqual(report, expected) {
+		t.Errorf("ToJSON() = %+v, want %+v", report, expected)
+	}
+
+	data, err = ValidateItems([]QuizItem{{Question: "q", Answer: "a"}}).LocalizedJSON(LanguageEnglish)
+	if err != nil {
+		t.Fatalf("LocalizedJSON() error = %v", err)
+	}
+	if err := json.Unmarshal(data, &report); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if !report.Valid || report.Errors == nil || len(report.Errors) != 0 {
+		t.Errorf("LocalizedJSON() = %s, want valid report with empty errors", data)
+	}
+}
+
+func TestValidationResult_ToText(t *testing.T) {
+	result := ValidationResult{
+		ValidationErrors: []ValidationError{
+			newValidationError(2, "answer", RuleRequired, nil, "答え (answer) が空です"),
+			newValidationError(0, "", RuleFileNotFound, nil, "ファイルが存在しません: %s", "missing.yaml"),
+		},
+		Warnings: []ValidationError{
+			newValidationError(0, "", RuleBOM, nil, "ファイルの先頭にBOMがあります（取り除いて読み込みました）: %s", "bom.yaml"),
+		},
+	}
+	result.ValidationErrors[0].File = "quiz.yaml"
+	result.ValidationErrors[0].Line = 7
+	result.Warnings[0].File = "bom.yaml"
+	result.Warnings[0].Line = 1
+
+	tests := []struct {
+		name     string
+		lang     Language
+		verbose  bool
+		expected string
+	}{
+		{
+			name: "ja",
+			lang: LanguageJapanese,
+			expected: "問題 2: 答え (answer) が空です\n" +
+				"ファイルが存在しません: missing.yaml\n" +
+				"警告: ファイルの先頭にBOMがあります（取り除いて読み込みました）: bom.yaml\n",
+		},
+		{
+			name:    "ja verbose",
+			lang:    LanguageJapanese,
+			verbose: true,
+			expected: "quiz.yaml:7: 問題 2: 答え (answer) が空です [required answer]\n" +
+				"ファイルが存在しません: missing.yaml [file-not-found]\n" +
+				"bom.yaml:1: 警告: ファイルの先頭にBOMがあります（取り除いて読み込みました）: bom.yaml [bom]\n",
+		},
+		{
+			name:    "en verbose",
+			lang:    LanguageEnglish,
+			verbose: true,
+			expected: "quiz.yaml:7: item 2: answer is empty [required answer]\n" +
+				"file does not exist: missing.yaml [file-not-found]\n" +
+				"bom.yaml:1: warning: file starts with a BOM (it was removed before parsing): bom.yaml [bom]\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := result.LocalizedText(tt.lang, tt.verbose); got != tt.expected {
+				t.Errorf("LocalizedText() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+	if got := result.ToText(false); got != tests[0].expected {
+		t.Errorf("ToText(false) = %q, want %q", got, tests[0].expected)
+	}
+}
+
+func TestYAMLItemLines(t *testing.T) {
+	tests := []struct {
+		name     string
+		yaml     string
+		expected []int
+	}{
+		{"list", "- question: q1\n  answer: a1\n- question: q2\n  answer: a2\n", []int{1, 3}},
+		{"documents", "- question: q1\n  answer: a1\n---\n---\n- question: q2\n  answer: a2\n", []int{1, 5}},
+		{"genres", "歴史:\n  - question: q1\n    answer: a1\n地理:\n  - question: q2\n    answer: a2\n", []int{2, 5}},
+		{"invalid", "- question: [\n", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := yamlItemLines([]byte(tt.yaml)); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("yamlItemLines() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,225 @@
+// 大会や練習会の成績（各プレイヤーの正誤）を読み込み，問題ごとの正答率から難易度を見積もる処理です．
+package quiz_yaml_converter
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+)
+
+// DefaultDifficultyLevels はDifficultyFromRateで使う難易度の段階数の既定値．
+const DefaultDifficultyLevels = 5
+
+// Outcome は1人のプレイヤーの1問に対する結果．
+type Outcome int8
+
+const (
+	OutcomeNone      Outcome = iota // 解答していない（結果が空）
+	OutcomeCorrect                  // 正解
+	OutcomeIncorrect                // 不正解
+)
+
+// 成績のセルの値と結果の対応（大文字・小文字は区別しない）
+var outcomeValues = map[string]Outcome{
+	"":          OutcomeNone,
+	"-":         OutcomeNone,
+	"1":         OutcomeCorrect,
+	"o":         OutcomeCorrect,
+	"○":         OutcomeCorrect,
+	"◯":         OutcomeCorrect,
+	"true":      OutcomeCorrect,
+	"correct":   OutcomeCorrect,
+	"0":         OutcomeIncorrect,
+	"x":         OutcomeIncorrect,
+	"×":         OutcomeIncorrect,
+	"false":     OutcomeIncorrect,
+	"incorrect": OutcomeIncorrect,
+}
+
+// PlayResults は成績ファイルの内容．
+type PlayResults struct {
+	Players  []string             // プレイヤー名（成績ファイルの列順）
+	IDs      []string             // 問題ID（成績ファイルの行順）
+	Outcomes map[string][]Outcome // 問題IDごとの，プレイヤー順の結果
+}
+
+// ReadPlayResults は成績のCSVを読み込む．
+// 1行目はヘッダーで，1列目が問題ID（列名はidまたはID），2列目以降がプレイヤー名となる．
+// 2行目以降の各行は1問分の結果で，セルは正解なら1・o・○・true・correct，
+// 不正解なら0・x・×・false・incorrect，解答していない場合は空か-とする．
+// 問題IDには問題のidか，idがない場合はContentIDを使う．先頭のBOMは取り除く．
+func ReadPlayResults(r io.Reader) (*PlayResults, error) {
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read results: %w", err)
+	}
+	reader := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(content, []byte(utf8BOM))))
+	reader.FieldsPerRecord = -1
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse results CSV: %w", err)
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("results CSV has no header row")
+	}
+	header := records[0]
+	if !strings.EqualFold(strings.TrimSpace(header[0]), "id") {
+		return nil, fmt.Errorf("the first column of the results CSV must be id: %q", header[0])
+	}
+
+	results := &PlayResults{Outcomes: map[string][]Outcome{}}
+	for _, name := range header[1:] {
+		results.Players = append(results.Players, strings.TrimSpace(name))
+	}
+	for i, record := range records[1:] {
+		line := i + 2
+		id := strings.TrimSpace(record[0])
+		if id == "" {
+			return nil, fmt.Errorf("line %d: question id is empty", line)
+		}
+		if _, ok := results.Outcomes[id]; ok {
+			return nil, fmt.Errorf("line %d: duplicate question id %q", line, id)
+		}
+		if len(record)-1 > len(results.Players) {
+			return nil, fmt.Errorf("line %d: %d results for %d players", line, len(record)-1, len(results.Players))
+		}
+		outcomes := make([]Outcome, len(results.Players))
+		for j, cell := range record[1:] {
+			outcome, ok := outcomeValues[strings.ToLower(strings.TrimSpace(cell))]
+			if !ok {
+				return nil, fmt.Errorf("line %d: invalid result %q for player %q", line, cell, results.Players[j])
+			}
+			outcomes[j] = outcome
+		}
+		results.IDs = append(results.IDs, id)
+		results.Outcomes[id] = outcomes
+	}
+	return results, nil
+}
+
+// WritePlayResults は成績をReadPlayResultsで読み込める形式のCSVとして書き出す．
+// 正解は1，不正解は0，解答していない場合は空とする．
+func WritePlayResults(w io.Writer, results *PlayResults) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(append([]string{"id"}, results.Players...)); err != nil {
+		return fmt.Errorf("failed to write results: %w", err)
+	}
+	for _, id := range results.IDs {
+		record := make([]string, len(results.Players)+1)
+		record[0] = id
+		for j, o := range results.Outcomes[id] {
+			if j >= len(results.Players) {
+				break
+			}
+			switch o {
+			case OutcomeCorrect:
+				record[j+1] = "1"
+			case OutcomeIncorrect:
+				record[j+1] = "0"
+			}
+		}
+		if err := cw.Write(record); err != nil {
+			return fmt.Errorf("failed to write results: %w", err)
+		}
+	}
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return fmt.Errorf("failed to write results: %w", err)
+	}
+	return nil
+}
+
+// SavePlayResults は成績をWritePlayResultsの形式でpathに書き出す．
+func SavePlayResults(results *PlayResults, path string) error {
+	return writeFileAtomic(path, false, func(w io.Writer) error {
+		return WritePlayResults(w, results)
+	})
+}
+
+// QuestionStats は1問分の成績の集計．
+type QuestionStats struct {
+	Index    int      `json:"index"`    // 問題の位置（1始まり）
+	ID       string   `json:"id"`       // 成績との対応付けに使った問題ID
+	Item     QuizItem `json:"-"`        // 問題
+	Attempts int      `json:"attempts"` // 解答したプレイヤーの数
+	Correct  int      `json:"correct"`  // 正解したプレイヤーの数
+}
+
+// CorrectRate は正答率（0〜1）を返す．解答したプレイヤーがいない場合は0を返す．
+func (s QuestionStats) CorrectRate() float64 {
+	if s.Attempts == 0 {
+		return 0
+	}
+	return float64(s.Correct) / float64(s.Attempts)
+}
+
+// resultsID は成績との対応付けに使う問題IDを返す．idがあればid，なければContentIDを使う．
+func resultsID(item QuizItem) string {
+	if item.ID != "" {
+		return item.ID
+	}
+	return ContentID(item)
+}
+
+// QuestionResults は問題ごとに成績を集計し，問題の順に返す．
+// 成績に含まれない問題のAttemptsは0となる．2つ目の戻り値は，どの問題にも対応しなかった成績の問題ID．
+func QuestionResults(items []QuizItem, results *PlayResults) ([]QuestionStats, []string) {
+	stats := make([]QuestionStats, len(items))
+	matched := map[string]bool{}
+	for i, item := range items {
+		id := resultsID(item)
+		stats[i] = QuestionStats{Index: i + 1, ID: id, Item: item}
+		outcomes, ok := results.Outcomes[id]
+		if !ok {
+			continue
+		}
+		matched[id] = true
+		for _, o := range outcomes {
+			switch o {
+			case OutcomeCorrect:
+				stats[i].Attempts++
+				stats[i].Correct++
+			case OutcomeIncorrect:
+				stats[i].Attempts++
+			}
+		}
+	}
+	var unmatched []string
+	for _, id := range results.IDs {
+		if !matched[id] {
+			unmatched = append(unmatched, id)
+		}
+	}
+	return stats, unmatched
+}
+
+// DifficultyFromRate は正答率（0〜1）を1からlevelsまでの難易度に換算する．
+// 正答率が低いほど難易度は大きくなり，正答率の範囲をlevels等分してどこに入るかで決める．
+// levelsが0以下の場合はDefaultDifficultyLevelsを使う．
+func DifficultyFromRate(rate float64, levels int) int {
+	if levels <= 0 {
+		levels = DefaultDifficultyLevels
+	}
+	// 0.8*5のような境界の値が浮動小数点数の誤差で下の段階に入らないよう，わずかに切り上げる
+	d := int(math.Floor((1-rate)*float64(levels)+1e-9)) + 1
+	return min(max(d, 1), levels)
+}
+
+// ApplyDifficulty は解答したプレイヤーがいる問題の難易度（difficulty）を正答率から見積もった値で置き換え，
+// 値が変わった問題の数を返す．statsはQuestionResultsでitemsから集計したものとする．
+func ApplyDifficulty(items []QuizItem, stats []QuestionStats, levels int) int {
+	changed := 0
+	for _, s := range stats {
+		if s.Attempts == 0 || s.Index < 1 || s.Index > len(items) {
+			continue
+		}
+		if d := DifficultyFromRate(s.CorrectRate(), levels); items[s.Index-1].Difficulty != d {
+			items[s.Index-1].Difficulty = d
+			changed++
+		}
+	}
+	return changed
+}
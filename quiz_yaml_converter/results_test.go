@@ -0,0 +1,152 @@
+package quiz_yaml_converter
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReadPlayResults(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected *PlayResults
+		wantErr  bool
+	}{
+		{
+			name:  "basic",
+			input: utf8BOM + "id,山田,佐藤,鈴木\nq1,1,0,\nq2,○,×,-\nq3,TRUE,x\n",
+			expected: &PlayResults{
+				Players: []string{"山田", "佐藤", "鈴木"},
+				IDs:     []string{"q1", "q2", "q3"},
+				Outcomes: map[string][]Outcome{
+					"q1": {OutcomeCorrect, OutcomeIncorrect, OutcomeNone},
+					"q2": {OutcomeCorrect, OutcomeIncorrect, OutcomeNone},
+					"q3": {OutcomeCorrect, OutcomeIncorrect, OutcomeNone},
+				},
+			},
+		},
+		{name: "empty", input: "", wantErr: true},
+		{name: "first column is not id", input: "問題,山田\nq1,1\n", wantErr: true},
+		{name: "invalid result", input: "id,山田\nq1,?\n", wantErr: true},
+		{name: "duplicate id", input: "id,山田\nq1,1\nq1,0\n", wantErr: true},
+		{name: "empty id", input: "id,山田\n,1\n", wantErr: true},
+		{name: "too many results", input: "id,山田\nq1,1,0\n", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ReadPlayResults(strings.NewReader(tt.input))
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("ReadPlayResults() expected error, got %+v", result)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ReadPlayResults() error = %v", err)
+			}
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("ReadPlayResults() = %+v, want %+v", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestQuestionResults(t *testing.T) {
+	items := []QuizItem{
+		{ID: "q1", Question: "問題1", Answer: "答え1"},
+		{Question: "問題2", Answer: "答え2"},
+		{ID: "q3", Question: "問題3", Answer: "答え3"},
+	}
+	results := &PlayResults{
+		Players: []string{"山田", "佐藤", "鈴木"},
+		IDs:     []string{"q1", ContentID(items[1]), "q9"},
+		Outcomes: map[string][]Outcome{
+			"q1":                {OutcomeCorrect, OutcomeIncorrect, OutcomeCorrect},
+			ContentID(items[1]): {OutcomeIncorrect, OutcomeNone, OutcomeIncorrect},
+			"q9":                {OutcomeCorrect, OutcomeCorrect, OutcomeCorrect},
+		},
+	}
+	stats, unmatched := QuestionResults(items, results)
+	expected := []QuestionStats{
+		{Index: 1, ID: "q1", Item: items[0], Attempts: 3, Correct: 2},
+		{Index: 2, ID: ContentID(items[1]), Item: items[1], Attempts: 2, Correct: 0},
+		{Index: 3, ID: "q3", Item: items[2]},
+	}
+	if !reflect.DeepEqual(stats, expected) {
+		t.Errorf("QuestionResults() = %+v, want %+v", stats, expected)
+	}
+	if !reflect.DeepEqual(unmatched, []string{"q9"}) {
+		t.Errorf("QuestionResults() unmatched = %v, want [q9]", unmatched)
+	}
+
+	if got := stats[0].CorrectRate(); got != 2.0/3 {
+		t.Errorf("CorrectRate() = %v, want %v", got, 2.0/3)
+	}
+	if got := stats[2].CorrectRate(); got != 0 {
+		t.Errorf("CorrectRate() without attempts = %v, want 0", got)
+	}
+
+	changed := ApplyDifficulty(items, stats, 0)
+	if changed != 2 {
+		t.Errorf("ApplyDifficulty() = %d, want 2", changed)
+	}
+	for i, want := range []int{2, 5, 0} {
+		if items[i].Difficulty != want {
+			t.Errorf("items[%d].Difficulty = %d, want %d", i, items[i].Difficulty, want)
+		}
+	}
+	if changed := ApplyDifficulty(items, stats, 0); changed != 0 {
+		t.Errorf("ApplyDifficulty() again = %d, want 0", changed)
+	}
+}
+
+func TestDifficultyFromRate(t *testing.T) {
+	tests := []struct {
+		rate     float64
+		levels   int
+		expected int
+	}{
+		{1, 5, 1},
+		{0.81, 5, 1},
+		{0.8, 5, 2},
+		{0.5, 5, 3},
+		{0.1, 5, 5},
+		{0, 5, 5},
+		{0, 0, 5},
+		{0.5, 3, 2},
+		{0.5, 1, 1},
+	}
+	for _, tt := range tests {
+		if got := DifficultyFromRate(tt.rate, tt.levels); got != tt.expected {
+			t.Errorf("DifficultyFromRate(%v, %d) = %d, want %d", tt.rate, tt.levels, got, tt.expected)
+		}
+	}
+}
+
+func TestWritePlayResults(t *testing.T) {
+	results := &PlayResults{
+		Players: []string{"山田", "佐藤, 次郎"},
+		IDs:     []string{"q1", "q2"},
+		Outcomes: map[string][]Outcome{
+			"q1": {OutcomeCorrect, OutcomeIncorrect},
+			"q2": {OutcomeNone, OutcomeCorrect},
+		},
+	}
+	var buf bytes.Buffer
+	if err := WritePlayResults(&buf, results); err != nil {
+		t.Fatalf("WritePlayResults() error = %v", err)
+	}
+	expected := "id,山田,\"佐藤, 次郎\"\nq1,1,0\nq2,,1\n"
+	if buf.String() != expected {
+		t.Errorf("WritePlayResults() = %q, want %q", buf.String(), expected)
+	}
+	read, err := ReadPlayResults(&buf)
+	if err != nil {
+		t.Fatalf("ReadPlayResults() error = %v", err)
+	}
+	if !reflect.DeepEqual(read, results) {
+		t.Errorf("ReadPlayResults(WritePlayResults()) = %+v, want %+v", read, results)
+	}
+}
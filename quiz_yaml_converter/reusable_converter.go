@@ -0,0 +1,84 @@
+// 設定を固定して繰り返し使う変換器です．
+// サーバーのように複数のリクエストを並行して変換する場合に，出力形式やテンプレートの解決を1度で済ませます．
+package quiz_yaml_converter
+
+import (
+	"bytes"
+	"io"
+)
+
+// Converter は出力形式と変換オプションを固定した変換器．NewConverterで作成する．
+// 作成後は設定を変更せず，変換ごとのバッファは呼び出しごとに確保するため，
+// 複数のゴルーチンから同時に使用できる（ロックは使わない）．
+// ただし，作成後にoptsのスライスやマップ（Pipeline，Varsなど）を書き換えてはならず，
+// Pipelineの各Stageも並行して呼び出されても安全である必要がある（組み込みのStageは安全）．
+type Converter struct {
+	opts      ConvertOptions
+	formatter Formatter         // 登録された出力形式（テンプレートの場合はnil）
+	tmpl      *CompiledTemplate // 解析済みのテンプレート（出力形式の場合はnil）
+}
+
+// NewConverter は出力形式と変換オプションを固定したConverterを作成する．
+// formatには登録された出力形式の名前（空の場合はCSV）を指定する．
+// FormatTemplateの場合はtemplateFilePathのテンプレートファイル（opts.TemplateTextが指定されている場合はその内容）を
+// 作成時に1度だけ解析する．外部コマンド（exec:）は使えない．
+func NewConverter(format OutputFormat, templateFilePath string, opts ConvertOptions) (*Converter, error) {
+	if format == "" {
+		format = FormatCSV
+	}
+	c := &Converter{opts: opts}
+	if format != FormatTemplate {
+		var err error
+		if c.formatter, err = LookupFormatter(string(format)); err != nil {
+			return nil, err
+		}
+		return c, nil
+	}
+	if templateFilePath == "" && opts.TemplateText == "" {
+		return nil, ErrTemplateRequired
+	}
+	var err error
+	if c.tmpl, err = CompileTemplate(templateFilePath, opts); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Convert は問題データにopts.Pipelineを適用し，出力形式に従ってwに書き出す．
+// 渡した問題データは書き換えない．opts.MaxOutputBytesはテンプレート以外の出力形式の出力にも適用する．
+func (c *Converter) Convert(w io.Writer, items []QuizItem) error {
+	items, err := c.opts.Pipeline.Apply(items)
+	if err != nil {
+		return err
+	}
+	if c.tmpl != nil {
+		return c.tmpl.Execute(w, items)
+	}
+	if c.opts.MaxOutputBytes > 0 {
+		w = &limitedWriter{w: w, max: c.opts.MaxOutputBytes}
+	}
+	return c.formatter.Format(w, items, c.opts)
+}
+
+// ConvertBytes はメモリ上のYAMLデータを変換し，出力をバイト列で返す．
+// エラーの場合は途中までの出力は返さない．
+func (c *Converter) ConvertBytes(yamlData []byte) ([]byte, error) {
+	items, err := ParseYAMLData(yamlData)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := c.Convert(&buf, items); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// ContentType は出力のContent-Typeを返す（ContentType参照）．
+// テンプレートの場合は"text/plain; charset=utf-8"を返す．
+func (c *Converter) ContentType() string {
+	if c.tmpl != nil {
+		return "text/plain; charset=utf-8"
+	}
+	return ContentType(c.formatter, c.opts)
+}
@@ -0,0 +1,160 @@
+package quiz_yaml_converter
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestNewConverter(t *testing.T) {
+	templateFile := filepath.Join(t.TempDir(), "quiz.tmpl")
+	if err := os.WriteFile(templateFile, []byte(`{{range .Items}}{{.Answer}};{{end}}`), 0644); err != nil {
+		t.Fatalf("Failed to create template file: %v", err)
+	}
+	items := []QuizItem{{Question: " 日本の首都は？ ", Answer: "東京"}}
+	tests := []struct {
+		name         string
+		format       OutputFormat
+		templateFile string
+		opts         ConvertOptions
+		expected     string
+		contentType  string
+		err          error
+	}{
+		{"default csv", "", "", ConvertOptions{}, "日本の首都は？", "text/csv; charset=utf-8", nil},
+		{"pipeline", "jsonl", "", ConvertOptions{Pipeline: Pipeline{TrimSpace}}, `"question":"日本の首都は？"`, "application/jsonl; charset=utf-8", nil},
+		{"template file", FormatTemplate, templateFile, ConvertOptions{}, "東京;", "text/plain; charset=utf-8", nil},
+		{"template text", FormatTemplate, "", ConvertOptions{TemplateText: "{{len .Items}}"}, "1", "text/plain; charset=utf-8", nil},
+		{"template required", FormatTemplate, "", ConvertOptions{}, "", "", ErrTemplateRequired},
+		{"template parse error", FormatTemplate, "", ConvertOptions{TemplateText: "{{"}, "", "", ErrTemplateParse},
+		{"unknown format", "unknown", "", ConvertOptions{}, "", "", ErrUnsupportedFormat},
+		{"exec is not allowed", "exec:cat", "", ConvertOptions{}, "", "", ErrUnsupportedFormat},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := NewConverter(tt.format, tt.templateFile, tt.opts)
+			if tt.err != nil {
+				if !errors.Is(err, tt.err) {
+					t.Fatalf("NewConverter() error = %v, want %v", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NewConverter() error = %v", err)
+			}
+			var buf bytes.Buffer
+			if err := c.Convert(&buf, items); err != nil {
+				t.Fatalf("Convert() error = %v", err)
+			}
+			if !strings.Contains(buf.String(), tt.expected) {
+				t.Errorf("Convert() = %q, want it to contain %q", buf.String(), tt.expected)
+			}
+			if got := c.ContentType(); got != tt.contentType {
+				t.Errorf("ContentType() = %q, want %q", got, tt.contentType)
+			}
+			if items[0].Question != " 日本の首都は？ " {
+				t.Errorf("Convert() modified the input: %q", items[0].Question)
+			}
+		})
+	}
+}
+
+func TestConverter_MaxOutputBytes(t *testing.T) {
+	items := []QuizItem{{Question: "日本の首都は？", Answer: "東京"}}
+	tests := []struct {
+		format  OutputFormat
+		max     int64
+		wantErr bool
+	}{
+		{FormatCSV, 10, true},
+		{"json", 10, true},
+		{"html", 10, true},
+		{FormatCSV, 1 << 20, false},
+		{"html", 1 << 20, false},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s/%d", tt.format, tt.max), func(t *testing.T) {
+			c, err := NewConverter(tt.format, "", ConvertOptions{MaxOutputBytes: tt.max})
+			if err != nil {
+				t.Fatalf("NewConverter() error = %v", err)
+			}
+			_, err = c.ConvertBytes([]byte("- question: 日本の首都は？\n  answer: 東京\n"))
+			if got := errors.Is(err, ErrOutputTooLarge); got != tt.wantErr {
+				t.Errorf("ConvertBytes() error = %v, want ErrOutputTooLarge: %v", err, tt.wantErr)
+			}
+			if err := c.Convert(io.Discard, items); tt.wantErr && !errors.Is(err, ErrOutputTooLarge) {
+				t.Errorf("Convert() error = %v, want ErrOutputTooLarge", err)
+			}
+		})
+	}
+}
+
+func TestConverter_Concurrent(t *testing.T) {
+	converters := map[string]*Converter{}
+	for name, format := range map[string]OutputFormat{"csv": FormatCSV, "json": "json", "template": FormatTemplate} {
+		c, err := NewConverter(format, "", ConvertOptions{
+			Pipeline:     Pipeline{TrimSpace},
+			TemplateText: "{{range .Items}}{{.Question}}={{.Answer}}{{end}}",
+		})
+		if err != nil {
+			t.Fatalf("NewConverter(%s) error = %v", name, err)
+		}
+		converters[name] = c
+	}
+
+	var wg sync.WaitGroup
+	for name, c := range converters {
+		for i := range 16 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				answer := fmt.Sprintf("answer%d", i)
+				out, err := c.ConvertBytes([]byte(fmt.Sprintf("- question: q%d\n  answer: \" %s \"\n", i, answer)))
+				if err != nil {
+					t.Errorf("%s: ConvertBytes() error = %v", name, err)
+					return
+				}
+				if !strings.Contains(string(out), answer) || strings.Contains(string(out), " "+answer) {
+					t.Errorf("%s: ConvertBytes() = %q, want the trimmed %s", name, out, answer)
+				}
+			}()
+		}
+	}
+	wg.Wait()
+}
+
+func BenchmarkConverter(b *testing.B) {
+	var yamlData bytes.Buffer
+	for i := range 100 {
+		fmt.Fprintf(&yamlData, "- question: 問題%d\n  answer: 答え%d\n  criteria:\n    ok: [別解%d]\n", i, i, i)
+	}
+	for _, format := range []OutputFormat{FormatCSV, "json", "html"} {
+		c, err := NewConverter(format, "", ConvertOptions{})
+		if err != nil {
+			b.Fatal(err)
+		}
+		b.Run(string(format)+"/serial", func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if _, err := c.ConvertBytes(yamlData.Bytes()); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+		b.Run(string(format)+"/parallel", func(b *testing.B) {
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					if _, err := c.ConvertBytes(yamlData.Bytes()); err != nil {
+						b.Error(err)
+						return
+					}
+				}
+			})
+		})
+	}
+}
@@ -0,0 +1,217 @@
+// 仮名で書かれた読みをローマ字に変換する処理です．
+package quiz_yaml_converter
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ローマ字の方式
+type RomajiStyle string
+
+// ローマ字の方式
+const (
+	RomajiHepburn  RomajiStyle = "hepburn"  // ヘボン式．長音は長音記号（ō）で表す
+	RomajiPassport RomajiStyle = "passport" // パスポートのヘボン式．長音を表記せず，b, m, pの前の「ん」はmとする
+	RomajiKunrei   RomajiStyle = "kunrei"   // 訓令式．長音はアクサン・シルコンフレクス（ô）で表す
+)
+
+// ParseRomajiStyle はローマ字の方式の名前を解析する．空文字列の場合はRomajiHepburnとなる．
+func ParseRomajiStyle(name string) (RomajiStyle, error) {
+	switch style := RomajiStyle(strings.ToLower(strings.TrimSpace(name))); style {
+	case "":
+		return RomajiHepburn, nil
+	case RomajiHepburn, RomajiPassport, RomajiKunrei:
+		return style, nil
+	default:
+		return "", fmt.Errorf("unsupported romaji style: %q (available: hepburn, passport, kunrei)", name)
+	}
+}
+
+// ヘボン式での仮名1文字の綴り（カタカナはひらがなに直してから引く）
+var hepburnKana = map[rune]string{
+	'あ': "a", 'い': "i", 'う': "u", 'え': "e", 'お': "o",
+	'か': "ka", 'き': "ki", 'く': "ku", 'け': "ke", 'こ': "ko",
+	'が': "ga", 'ぎ': "gi", 'ぐ': "gu", 'げ': "ge", 'ご': "go",
+	'さ': "sa", 'し': "shi", 'す': "su", 'せ': "se", 'そ': "so",
+	'ざ': "za", 'じ': "ji", 'ず': "zu", 'ぜ': "ze", 'ぞ': "zo",
+	'た': "ta", 'ち': "chi", 'つ': "tsu", 'て': "te", 'と': "to",
+	'だ': "da", 'ぢ': "ji", 'づ': "zu", 'で': "de", 'ど': "do",
+	'な': "na", 'に': "ni", 'ぬ': "nu", 'ね': "ne", 'の': "no",
+	'は': "ha", 'ひ': "hi", 'ふ': "fu", 'へ': "he", 'ほ': "ho",
+	'ば': "ba", 'び': "bi", 'ぶ': "bu", 'べ': "be", 'ぼ': "bo",
+	'ぱ': "pa", 'ぴ': "pi", 'ぷ': "pu", 'ぺ': "pe", 'ぽ': "po",
+	'ま': "ma", 'み': "mi", 'む': "mu", 'め': "me", 'も': "mo",
+	'や': "ya", 'ゆ': "yu", 'よ': "yo",
+	'ら': "ra", 'り': "ri", 'る': "ru", 'れ': "re", 'ろ': "ro",
+	'わ': "wa", 'ゐ': "i", 'ゑ': "e", 'を': "o", 'ん': "n", 'ゔ': "vu",
+	'ぁ': "a", 'ぃ': "i", 'ぅ': "u", 'ぇ': "e", 'ぉ': "o", 'ゎ': "wa",
+	'ゃ': "ya", 'ゅ': "yu", 'ょ': "yo",
+}
+
+// 訓令式でヘボン式と綴りが異なる仮名
+var kunreiKana = map[rune]string{
+	'し': "si", 'ち': "ti", 'つ': "tu", 'ふ': "hu", 'じ': "zi", 'ぢ': "zi", 'づ': "zu",
+}
+
+// 小書きの母音と組み合わせた外来語の音の綴り（「ふぁ」→fa など）
+var kanaWithSmallVowel = map[string]string{
+	"ふぁ": "fa", "ふぃ": "fi", "ふぇ": "fe", "ふぉ": "fo",
+	"ゔぁ": "va", "ゔぃ": "vi", "ゔぇ": "ve", "ゔぉ": "vo",
+	"てぃ": "ti", "でぃ": "di", "とぅ": "tu", "どぅ": "du",
+	"しぇ": "she", "ちぇ": "che", "じぇ": "je", "いぇ": "ye",
+	"うぃ": "wi", "うぇ": "we", "うぉ": "wo",
+	"つぁ": "tsa", "つぃ": "tsi", "つぇ": "tse", "つぉ": "tso",
+}
+
+// 長音記号を付けた母音
+var (
+	macronVowels     = map[byte]string{'a': "ā", 'i': "ī", 'u': "ū", 'e': "ē", 'o': "ō"}
+	circumflexVowels = map[byte]string{'a': "â", 'i': "î", 'u': "û", 'e': "ê", 'o': "ô"}
+)
+
+// ToRomaji は仮名（ひらがな・カタカナ）をstyleの方式でローマ字に変換する．
+// 拗音（「きゃ」）や外来語の音（「ふぁ」）は1音節として綴り，促音（「っ」）は次の子音を重ねる．
+// 「おう」「おお」「うう」と長音符（「ー」）は長音として扱う．
+// 仮名以外の文字（漢字，英数字，記号など）はそのまま出力する．
+func ToRomaji(s string, style RomajiStyle) string {
+	original := []rune(s)
+	kana := []rune(s)
+	for i, r := range kana {
+		// カタカナはひらがなとして扱う
+		kana[i] = toHiragana(r)
+	}
+
+	var b strings.Builder
+	sokuon := false // 直前が促音
+	for i := 0; i < len(kana); i++ {
+		r := kana[i]
+		switch {
+		case r == 'っ':
+			sokuon = true
+			continue
+		case r == 'ー':
+			lengthenVowel(&b, style)
+			continue
+		case (r == 'う' || r == 'お') && i > 0 && isLongVowelPair(kana[i-1], r):
+			lengthenVowel(&b, style)
+			continue
+		}
+
+		syllable, size := romajiSyllable(kana[i:], style)
+		if syllable == "" {
+			b.WriteRune(original[i])
+			sokuon = false
+			continue
+		}
+		i += size - 1
+
+		if r == 'ん' {
+			syllable = romajiN(kana[i+1:], style)
+		}
+		if sokuon {
+			switch {
+			case strings.HasPrefix(syllable, "ch"):
+				b.WriteByte('t')
+			case !strings.ContainsRune("aiueon", rune(syllable[0])):
+				b.WriteByte(syllable[0])
+			}
+			sokuon = false
+		}
+		b.WriteString(syllable)
+	}
+	return b.String()
+}
+
+// romajiSyllable はkanaの先頭の音節の綴りと，その音節に使った仮名の数を返す．
+// 先頭が仮名でない場合は空文字列を返す．
+func romajiSyllable(kana []rune, style RomajiStyle) (string, int) {
+	base, ok := hepburnKana[kana[0]]
+	if !ok {
+		return "", 0
+	}
+	if style == RomajiKunrei {
+		if k, ok := kunreiKana[kana[0]]; ok {
+			base = k
+		}
+	}
+	if len(kana) < 2 {
+		return base, 1
+	}
+	switch next := kana[1]; next {
+	case 'ゃ', 'ゅ', 'ょ':
+		// 拗音．イ段の仮名の母音を取り除き，し・ち・じ以外はyを付ける
+		if !strings.HasSuffix(base, "i") || len(base) < 2 {
+			return base, 1
+		}
+		stem := strings.TrimSuffix(base, "i")
+		if base != "shi" && base != "chi" && base != "ji" {
+			stem += "y"
+		}
+		return stem + hepburnKana[next][1:], 2
+	case 'ぁ', 'ぃ', 'ぅ', 'ぇ', 'ぉ':
+		if syllable, ok := kanaWithSmallVowel[string(kana[:2])]; ok {
+			return syllable, 2
+		}
+	}
+	return base, 1
+}
+
+// romajiN は「ん」の綴りを，後ろに続く仮名restに応じて返す．
+// 母音とヤ行の前ではヘボン式・訓令式ともn'とし，パスポートのヘボン式ではb, m, pの前をmとする．
+func romajiN(rest []rune, style RomajiStyle) string {
+	if len(rest) == 0 {
+		return "n"
+	}
+	next, _ := romajiSyllable(rest, style)
+	if next == "" {
+		return "n"
+	}
+	if style == RomajiPassport {
+		if strings.ContainsRune("bmp", rune(next[0])) {
+			return "m"
+		}
+		return "n"
+	}
+	if strings.ContainsRune("aiueoy", rune(next[0])) {
+		return "n'"
+	}
+	return "n"
+}
+
+// isLongVowelPair は仮名prevに続くrが長音を表すか（「おう」「おお」「うう」）を返す．
+func isLongVowelPair(prev, r rune) bool {
+	vowel := hepburnKana[prev]
+	if vowel == "" || prev == 'ん' || prev == 'っ' {
+		return false
+	}
+	last := vowel[len(vowel)-1]
+	return last == 'o' && (r == 'う' || r == 'お') || last == 'u' && r == 'う'
+}
+
+// lengthenVowel はbの末尾の母音を，styleに従って長音として表記し直す．
+// パスポートのヘボン式では長音を表記しない．
+func lengthenVowel(b *strings.Builder, style RomajiStyle) {
+	s := b.String()
+	if s == "" || style == RomajiPassport {
+		return
+	}
+	marks := macronVowels
+	if style == RomajiKunrei {
+		marks = circumflexVowels
+	}
+	long, ok := marks[s[len(s)-1]]
+	if !ok {
+		return
+	}
+	b.Reset()
+	b.WriteString(s[:len(s)-1] + long)
+}
+
+// toHiragana はカタカナ（ァ〜ヶ）を対応するひらがなに変換する．それ以外の文字はそのまま返す．
+func toHiragana(r rune) rune {
+	if r >= 'ァ' && r <= 'ヶ' {
+		return r - 'ァ' + 'ぁ'
+	}
+	return r
+}
@@ -0,0 +1,96 @@
+package quiz_yaml_converter
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestToRomaji(t *testing.T) {
+	tests := []struct {
+		input    string
+		style    RomajiStyle
+		expected string
+	}{
+		{"ふじさん", RomajiHepburn, "fujisan"},
+		{"とうきょう", RomajiHepburn, "tōkyō"},
+		{"とうきょう", RomajiPassport, "tokyo"},
+		{"とうきょう", RomajiKunrei, "tôkyô"},
+		{"おおさか", RomajiHepburn, "ōsaka"},
+		{"しんぶん", RomajiKunrei, "sinbun"},
+		{"ちゃっと", RomajiHepburn, "chatto"},
+		{"まっちゃ", RomajiHepburn, "matcha"},
+		{"まっちゃ", RomajiKunrei, "mattya"},
+		{"しんいち", RomajiHepburn, "shin'ichi"},
+		{"しんいち", RomajiPassport, "shinichi"},
+		{"なんば", RomajiPassport, "namba"},
+		{"なんば", RomajiHepburn, "nanba"},
+		{"コンピューター", RomajiHepburn, "konpyūtā"},
+		{"コンピューター", RomajiPassport, "kompyuta"},
+		{"フィレンツェ", RomajiHepburn, "firentse"},
+		{"ヴァイオリン", RomajiHepburn, "vaiorin"},
+		{"ジョン・アタナソフ", RomajiHepburn, "jon・atanasofu"},
+		{"東京タワー", RomajiHepburn, "東京tawā"},
+		{"ヵ月", RomajiHepburn, "ヵ月"},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.style)+"/"+tt.input, func(t *testing.T) {
+			if result := ToRomaji(tt.input, tt.style); result != tt.expected {
+				t.Errorf("ToRomaji(%q, %q) = %q, want %q", tt.input, tt.style, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParseRomajiStyle(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected RomajiStyle
+		wantErr  bool
+	}{
+		{"", RomajiHepburn, false},
+		{"Hepburn", RomajiHepburn, false},
+		{"passport", RomajiPassport, false},
+		{"kunrei", RomajiKunrei, false},
+		{"nihon", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			result, err := ParseRomajiStyle(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseRomajiStyle(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if result != tt.expected {
+				t.Errorf("ParseRomajiStyle(%q) = %q, want %q", tt.input, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestWriteTemplate_ToRomaji(t *testing.T) {
+	data := []QuizItem{{Question: "q1", Answer: "東京", Spell: "とうきょう"}}
+	tests := []struct {
+		template string
+		expected string
+		wantErr  bool
+	}{
+		{`{{range .Items}}{{toRomaji .Spell}}{{end}}`, "tōkyō", false},
+		{`{{range .Items}}{{toRomaji .Spell "passport"}}{{end}}`, "tokyo", false},
+		{`{{range .Items}}{{toRomaji .Spell (index $.Vars "romaji")}}{{end}}`, "tôkyô", false},
+		{`{{range .Items}}{{toRomaji .Spell "nihon"}}{{end}}`, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.template, func(t *testing.T) {
+			var buf bytes.Buffer
+			err := WriteTemplate(&buf, data, "romaji.tmpl", ConvertOptions{TemplateText: tt.template, Vars: map[string]string{"romaji": "kunrei"}})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("WriteTemplate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && buf.String() != tt.expected {
+				t.Errorf("WriteTemplate() = %q, want %q", buf.String(), tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,131 @@
+package quiz_yaml_converter
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"sort"
+	"strings"
+)
+
+// RoundOptions は問題をラウンドに振り分けるときの設定．
+type RoundOptions struct {
+	Rounds   int   // ラウンド数
+	PerRound int   // 1ラウンドあたりの問題数（0の場合はすべての問題をできるだけ均等に振り分ける）
+	Seed     int64 // 0以外の場合，ジャンル内の問題の順序をこの値でシャッフルしてから選ぶ
+}
+
+// DistributeRounds は問題をopts.Rounds個のラウンドに振り分ける．
+// 各ラウンドのジャンルの数ができるだけ揃い，難易度（difficulty）の合計ができるだけ近くなるように振り分ける．
+// opts.PerRoundを指定した場合は，ジャンルが偏らないように各ジャンルから順に1問ずつ選んだ問題だけを使う．
+// 各ラウンドの問題は入力での順序を保ち，YAMLのドキュメントの区切り（Document）は取り除く．
+func DistributeRounds(data []QuizItem, opts RoundOptions) ([][]QuizItem, error) {
+	assigned, err := DistributeRoundIndices(data, opts)
+	if err != nil {
+		return nil, err
+	}
+	rounds := make([][]QuizItem, len(assigned))
+	for r, indices := range assigned {
+		rounds[r] = make([]QuizItem, len(indices))
+		for j, i := range indices {
+			rounds[r][j] = data[i]
+			rounds[r][j].Document = 0
+		}
+	}
+	return rounds, nil
+}
+
+// DistributeRoundIndices はDistributeRoundsと同じく問題を振り分け，各ラウンドの問題をdataでの位置（0始まり，昇順）で返す．
+// 振り分けた問題を元のYAMLのノードと対応付ける場合（ComposeYAMLEditor）に使う．
+func DistributeRoundIndices(data []QuizItem, opts RoundOptions) ([][]int, error) {
+	if opts.Rounds <= 0 {
+		return nil, fmt.Errorf("number of rounds must be positive: %d", opts.Rounds)
+	}
+	if opts.PerRound < 0 {
+		return nil, fmt.Errorf("number of questions per round must not be negative: %d", opts.PerRound)
+	}
+	total := len(data)
+	if opts.PerRound > 0 {
+		total = opts.Rounds * opts.PerRound
+		if total > len(data) {
+			return nil, fmt.Errorf("not enough questions: %d rounds of %d questions need %d, but only %d are available", opts.Rounds, opts.PerRound, total, len(data))
+		}
+	}
+
+	order := make([]int, len(data))
+	for i := range order {
+		order[i] = i
+	}
+	if opts.Seed != 0 {
+		r := rand.New(rand.NewPCG(uint64(opts.Seed), 0))
+		r.Shuffle(len(order), func(i, j int) { order[i], order[j] = order[j], order[i] })
+	}
+
+	// ジャンルごとに問題をまとめる（ジャンルは最初に現れた順）
+	var genres [][]int
+	genreIndex := map[string]int{}
+	for _, i := range order {
+		genre := strings.TrimSpace(data[i].Genre)
+		g, ok := genreIndex[genre]
+		if !ok {
+			g = len(genres)
+			genreIndex[genre] = g
+			genres = append(genres, nil)
+		}
+		genres[g] = append(genres[g], i)
+	}
+
+	// 各ジャンルから順に1問ずつ選ぶ
+	selected := make([][]int, len(genres))
+	for n, depth := 0, 0; n < total; depth++ {
+		for g := range genres {
+			if n < total && depth < len(genres[g]) {
+				selected[g] = append(selected[g], genres[g][depth])
+				n++
+			}
+		}
+	}
+
+	// 問題の多いジャンルから，難しい問題から順に，空きのあるラウンドのうち
+	// そのジャンルの問題が最も少なく，難易度の合計が最も小さいラウンドに入れる
+	capacity := make([]int, opts.Rounds)
+	for r := range capacity {
+		capacity[r] = total / opts.Rounds
+		if r < total%opts.Rounds {
+			capacity[r]++
+		}
+	}
+	genreOrder := make([]int, len(genres))
+	for g := range genreOrder {
+		genreOrder[g] = g
+	}
+	sort.SliceStable(genreOrder, func(a, b int) bool { return len(selected[genreOrder[a]]) > len(selected[genreOrder[b]]) })
+
+	assigned := make([][]int, opts.Rounds)
+	difficulty := make([]int, opts.Rounds)
+	for _, g := range genreOrder {
+		items := selected[g]
+		sort.SliceStable(items, func(a, b int) bool { return data[items[a]].Difficulty > data[items[b]].Difficulty })
+		genreCount := make([]int, opts.Rounds)
+		for _, i := range items {
+			best := -1
+			for r := range assigned {
+				if len(assigned[r]) >= capacity[r] {
+					continue
+				}
+				if best < 0 || genreCount[r] < genreCount[best] ||
+					genreCount[r] == genreCount[best] && (difficulty[r] < difficulty[best] ||
+						difficulty[r] == difficulty[best] && len(assigned[r]) < len(assigned[best])) {
+					best = r
+				}
+			}
+			assigned[best] = append(assigned[best], i)
+			genreCount[best]++
+			difficulty[best] += data[i].Difficulty
+		}
+	}
+
+	for _, indices := range assigned {
+		sort.Ints(indices)
+	}
+	return assigned, nil
+}
@@ -0,0 +1,136 @@
+package quiz_yaml_converter
+
+import (
+	"reflect"
+	"testing"
+)
+
+func roundQuestions(rounds [][]QuizItem) [][]string {
+	questions := make([][]string, len(rounds))
+	for r, items := range rounds {
+		questions[r] = []string{}
+		for _, item := range items {
+			questions[r] = append(questions[r], item.Question)
+		}
+	}
+	return questions
+}
+
+func TestDistributeRounds(t *testing.T) {
+	data := []QuizItem{
+		{Question: "h1", Answer: "a", Genre: "歴史", Difficulty: 1},
+		{Question: "h2", Answer: "a", Genre: "歴史", Difficulty: 3},
+		{Question: "h3", Answer: "a", Genre: "歴史", Difficulty: 2},
+		{Question: "h4", Answer: "a", Genre: "歴史", Difficulty: 2},
+		{Question: "s1", Answer: "a", Genre: "科学", Difficulty: 1},
+		{Question: "s2", Answer: "a", Genre: "科学", Difficulty: 3},
+		{Question: "n1", Answer: "a", Difficulty: 1, Document: 2},
+	}
+
+	tests := []struct {
+		name     string
+		opts     RoundOptions
+		expected [][]string
+	}{
+		{
+			name:     "all items",
+			opts:     RoundOptions{Rounds: 2},
+			expected: [][]string{{"h1", "h2", "s2", "n1"}, {"h3", "h4", "s1"}},
+		},
+		{
+			name:     "per round picks genres in turn",
+			opts:     RoundOptions{Rounds: 2, PerRound: 2},
+			expected: [][]string{{"h2", "n1"}, {"h1", "s1"}},
+		},
+		{
+			name:     "one round",
+			opts:     RoundOptions{Rounds: 1, PerRound: 3},
+			expected: [][]string{{"h1", "s1", "n1"}},
+		},
+		{
+			name:     "more rounds than items",
+			opts:     RoundOptions{Rounds: 8},
+			expected: [][]string{{"h2"}, {"h3"}, {"h4"}, {"h1"}, {"s2"}, {"s1"}, {"n1"}, {}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rounds, err := DistributeRounds(data, tt.opts)
+			if err != nil {
+				t.Fatalf("DistributeRounds() error = %v", err)
+			}
+			if got := roundQuestions(rounds); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("DistributeRounds() = %v, want %v", got, tt.expected)
+			}
+			for _, items := range rounds {
+				for _, item := range items {
+					if item.Document != 0 {
+						t.Errorf("Document = %d, want 0", item.Document)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestDistributeRounds_Seed(t *testing.T) {
+	var data []QuizItem
+	for i := range 12 {
+		data = append(data, QuizItem{Question: string(rune('a' + i)), Answer: "a", Genre: []string{"歴史", "科学", "文学"}[i%3]})
+	}
+	opts := RoundOptions{Rounds: 2, PerRound: 3, Seed: 42}
+	first, err := DistributeRounds(data, opts)
+	if err != nil {
+		t.Fatalf("DistributeRounds() error = %v", err)
+	}
+	second, _ := DistributeRounds(data, opts)
+	if !reflect.DeepEqual(first, second) {
+		t.Errorf("DistributeRounds() with the same seed = %v, then %v", roundQuestions(first), roundQuestions(second))
+	}
+	for r, items := range first {
+		genres := map[string]int{}
+		for _, item := range items {
+			genres[item.Genre]++
+		}
+		if len(items) != 3 || len(genres) != 3 {
+			t.Errorf("round %d = %v, want one item of each genre", r+1, roundQuestions(first)[r])
+		}
+	}
+}
+
+func TestDistributeRounds_Errors(t *testing.T) {
+	data := []QuizItem{{Question: "q1", Answer: "a1"}, {Question: "q2", Answer: "a2"}}
+	tests := []struct {
+		name string
+		opts RoundOptions
+	}{
+		{"no rounds", RoundOptions{Rounds: 0}},
+		{"negative per round", RoundOptions{Rounds: 1, PerRound: -1}},
+		{"not enough items", RoundOptions{Rounds: 2, PerRound: 2}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := DistributeRounds(data, tt.opts); err == nil {
+				t.Errorf("DistributeRounds() error = nil, want error")
+			}
+		})
+	}
+}
+
+func TestDistributeRoundIndices(t *testing.T) {
+	data := []QuizItem{
+		{Question: "h1", Answer: "a", Genre: "歴史", Difficulty: 1},
+		{Question: "h2", Answer: "a", Genre: "歴史", Difficulty: 3},
+		{Question: "s1", Answer: "a", Genre: "科学", Difficulty: 2},
+	}
+	indices, err := DistributeRoundIndices(data, RoundOptions{Rounds: 2})
+	if err != nil {
+		t.Fatalf("DistributeRoundIndices() error = %v", err)
+	}
+	if expected := [][]int{{1, 2}, {0}}; !reflect.DeepEqual(indices, expected) {
+		t.Errorf("DistributeRoundIndices() = %v, want %v", indices, expected)
+	}
+	if _, err := DistributeRoundIndices(data, RoundOptions{}); err == nil {
+		t.Error("DistributeRoundIndices() with no rounds expected error")
+	}
+}
@@ -0,0 +1,81 @@
+package quiz_yaml_converter
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// RoundTripResult はYAML→CSV→YAMLの往復変換の結果．
+type RoundTripResult struct {
+	Items    int        `json:"items"`        // 変換した問題の数
+	Lossy    []string   `json:"lossy_fields"` // 往復で値が失われた，または変わったフィールド名
+	Changes  []ItemDiff `json:"changes"`      // 値が変わった問題ごとの差分
+	Restored []QuizItem `json:"-"`            // CSVから読み戻した問題データ
+}
+
+// IsLossless は往復変換で値が変わったフィールドがないかを返す．
+func (r RoundTripResult) IsLossless() bool {
+	return len(r.Lossy) == 0
+}
+
+// RoundTripCSV は問題データをoptsに従ってCSVに変換し，ReadCSVで読み戻した結果を
+// 元の問題データと比較する．CSVは問題の順序を保つため，問題は位置で対応付ける．
+// opts.AssignIDsがtrueの場合は，IDを割り当てた後の問題データと比較する．
+// 正誤判定の各項目はCSVで「」を付けて出力されるため，「」の有無は区別しない．
+func RoundTripCSV(data []QuizItem, opts ConvertOptions) (RoundTripResult, error) {
+	var buf bytes.Buffer
+	if err := WriteCSV(&buf, data, opts); err != nil {
+		return RoundTripResult{}, err
+	}
+	restored, err := ReadCSV(&buf, opts)
+	if err != nil {
+		return RoundTripResult{}, err
+	}
+	original := withAssignedIDs(data, opts)
+	if len(restored) != len(original) {
+		return RoundTripResult{}, fmt.Errorf("%w: wrote %d, read %d", ErrRoundTripMismatch, len(original), len(restored))
+	}
+
+	result := RoundTripResult{Items: len(original), Lossy: []string{}, Changes: []ItemDiff{}, Restored: restored}
+	lossy := map[string]bool{}
+	for i := range original {
+		changes := diffFields(quotedCriteria(original[i]), quotedCriteria(restored[i]))
+		if len(changes) == 0 {
+			continue
+		}
+		result.Changes = append(result.Changes, ItemDiff{
+			Key:      diffKey(original[i]),
+			OldIndex: i + 1,
+			NewIndex: i + 1,
+			Question: original[i].Question,
+			Changes:  changes,
+		})
+		for _, c := range changes {
+			lossy[c.Field] = true
+		}
+	}
+	all := append(append([]QuizItem{}, original...), restored...)
+	for _, name := range diffFieldNames(all...) {
+		if lossy[name] {
+			result.Lossy = append(result.Lossy, name)
+		}
+	}
+	return result, nil
+}
+
+// quotedCriteria は正誤判定の各項目にCSVと同じ規則で「」を付けた問題データを返す．
+func quotedCriteria(item QuizItem) QuizItem {
+	if item.Criteria == nil {
+		return item
+	}
+	criteria := make(map[string][]string, len(item.Criteria))
+	for key, answers := range item.Criteria {
+		quoted := make([]string, len(answers))
+		for i, answer := range answers {
+			quoted[i] = AddQuotesIfNeeded(answer)
+		}
+		criteria[key] = quoted
+	}
+	item.Criteria = criteria
+	return item
+}
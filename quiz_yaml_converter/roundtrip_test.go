@@ -0,0 +1,86 @@
+package quiz_yaml_converter
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRoundTripCSV(t *testing.T) {
+	data := []QuizItem{
+		{
+			ID:       "q1",
+			Question: "問題1",
+			Answer:   "答え1",
+			Spell:    "Answer 1",
+			Genre:    "科学",
+			Tags:     []string{"easy"},
+			Comments: []string{"コメント1", "コメント2"},
+			Criteria: map[string][]string{"ok": {"別解"}, "ng": {"誤答"}},
+		},
+		{Question: "問題2", Answer: "答え2", Criteria: map[string][]string{"ok": {"「引用済み」", "「美術館」（おまけ）"}}},
+	}
+
+	tests := []struct {
+		name     string
+		opts     ConvertOptions
+		lossy    []string
+		changes  int
+		lossless bool
+	}{
+		{
+			name:    "default columns",
+			lossy:   []string{"id", "genre", "tags", "comments"},
+			changes: 1,
+		},
+		{
+			name:     "all columns",
+			opts:     ConvertOptions{CSV: CSVOptions{Columns: []string{"id", "question", "answer", "spell", "genre", "tags", "comments", "criteria"}}},
+			lossy:    []string{},
+			lossless: true,
+		},
+		{
+			name:    "separator appears in comments",
+			opts:    ConvertOptions{CSV: CSVOptions{Columns: []string{"id", "question", "answer", "spell", "genre", "tags", "criteria"}, IncludeComments: true, CommentSeparator: "コメント"}},
+			lossy:   []string{"comments"},
+			changes: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := RoundTripCSV(data, tt.opts)
+			if err != nil {
+				t.Fatalf("RoundTripCSV() error = %v", err)
+			}
+			if result.Items != len(data) {
+				t.Errorf("Items = %d, want %d", result.Items, len(data))
+			}
+			if !reflect.DeepEqual(result.Lossy, tt.lossy) {
+				t.Errorf("Lossy = %v, want %v", result.Lossy, tt.lossy)
+			}
+			if len(result.Changes) != tt.changes {
+				t.Errorf("Changes = %+v, want %d items", result.Changes, tt.changes)
+			}
+			if result.IsLossless() != tt.lossless {
+				t.Errorf("IsLossless() = %v, want %v", result.IsLossless(), tt.lossless)
+			}
+		})
+	}
+}
+
+func TestRoundTripCSV_AssignIDs(t *testing.T) {
+	data := []QuizItem{{Question: "問題1", Answer: "答え1"}}
+	result, err := RoundTripCSV(data, ConvertOptions{AssignIDs: true})
+	if err != nil {
+		t.Fatalf("RoundTripCSV() error = %v", err)
+	}
+	if !result.IsLossless() {
+		t.Errorf("RoundTripCSV() with AssignIDs lossy fields = %v", result.Lossy)
+	}
+	if result.Restored[0].ID == "" {
+		t.Error("restored item has no ID")
+	}
+	if data[0].ID != "" {
+		t.Errorf("RoundTripCSV() modified input: ID = %q", data[0].ID)
+	}
+}
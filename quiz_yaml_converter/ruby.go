@@ -0,0 +1,61 @@
+// 読み（ルビ）をHTMLで表示する処理です．
+// 答えの読み（reading）と，問題文やコメント中の「｜漢字《かんじ》」形式のルビを，
+// <ruby>要素か括弧書きの読みとして出力します．
+package quiz_yaml_converter
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// 読みの表示方法
+type RubyStyle string
+
+// 読みの表示方法
+const (
+	RubyTags  RubyStyle = "ruby"  // <ruby>東京<rt>とうきょう</rt></ruby>のように，ルビとして表示する
+	RubyParen RubyStyle = "paren" // 東京（とうきょう）のように，括弧書きで表示する
+)
+
+// ParseRubyStyle は読みの表示方法の名前を解析する．空文字列の場合はRubyTagsとなる．
+func ParseRubyStyle(name string) (RubyStyle, error) {
+	switch style := RubyStyle(strings.ToLower(strings.TrimSpace(name))); style {
+	case "":
+		return RubyTags, nil
+	case RubyTags, RubyParen:
+		return style, nil
+	default:
+		return "", fmt.Errorf("unsupported ruby style: %q (available: ruby, paren)", name)
+	}
+}
+
+// Ruby はtextに読みを付けたHTMLを返す．
+// 読みが空の場合や，読みがtextと同じ場合（仮名だけの答えなど）はtextをそのまま返す．
+func Ruby(text, reading string, style RubyStyle) string {
+	if strings.TrimSpace(reading) == "" || NormalizeReading(reading) == NormalizeReading(text) {
+		return text
+	}
+	if style == RubyParen {
+		return text + "（" + reading + "）"
+	}
+	return "<ruby>" + text + "<rp>（</rp><rt>" + reading + "</rt><rp>）</rp></ruby>"
+}
+
+// furiganaPattern は青空文庫形式のルビ．「｜親字《よみ》」と，漢字の連続に続く「漢字《よみ》」に一致する．
+var furiganaPattern = regexp.MustCompile(`[｜|]([^｜|《》]+)《([^《》]*)》|([\p{Han}々〆ヶ]+)《([^《》]*)》`)
+
+// Furigana はtext中の「｜親字《よみ》」「漢字《よみ》」形式のルビを，styleに従ったHTMLに置き換える（Ruby参照）．
+// 「｜」を省略した場合は，「《」の直前に続く漢字を親字とする．
+func Furigana(text string, style RubyStyle) string {
+	if !strings.Contains(text, "《") {
+		return text
+	}
+	return furiganaPattern.ReplaceAllStringFunc(text, func(m string) string {
+		sub := furiganaPattern.FindStringSubmatch(m)
+		if sub[1] != "" {
+			return Ruby(sub[1], sub[2], style)
+		}
+		return Ruby(sub[3], sub[4], style)
+	})
+}
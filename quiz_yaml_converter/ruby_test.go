@@ -0,0 +1,95 @@
+package quiz_yaml_converter
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestParseRubyStyle(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    RubyStyle
+		wantErr bool
+	}{
+		{"", RubyTags, false},
+		{"ruby", RubyTags, false},
+		{" Paren ", RubyParen, false},
+		{"furigana", "", true},
+	}
+	for _, tt := range tests {
+		got, err := ParseRubyStyle(tt.name)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseRubyStyle(%q) = %q, %v, want %q (error: %v)", tt.name, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestRuby(t *testing.T) {
+	tests := []struct {
+		text, reading string
+		style         RubyStyle
+		want          string
+	}{
+		{"東京", "とうきょう", RubyTags, "<ruby>東京<rp>（</rp><rt>とうきょう</rt><rp>）</rp></ruby>"},
+		{"東京", "とうきょう", RubyParen, "東京（とうきょう）"},
+		{"東京", "", RubyTags, "東京"},
+		{"カステラ", "かすてら", RubyTags, "カステラ"},
+		{"さくら", "さくら", RubyParen, "さくら"},
+	}
+	for _, tt := range tests {
+		if got := Ruby(tt.text, tt.reading, tt.style); got != tt.want {
+			t.Errorf("Ruby(%q, %q, %q) = %q, want %q", tt.text, tt.reading, tt.style, got, tt.want)
+		}
+	}
+}
+
+func TestFurigana(t *testing.T) {
+	tests := []struct {
+		text  string
+		style RubyStyle
+		want  string
+	}{
+		{"ルビのない問題文", RubyTags, "ルビのない問題文"},
+		{"「邂逅《かいこう》」の意味は？", RubyParen, "「邂逅（かいこう）」の意味は？"},
+		{"その後に邂逅《かいこう》した", RubyParen, "その後に邂逅（かいこう）した"},
+		{"｜常陸国《ひたちのくに》の国府", RubyTags, "<ruby>常陸国<rp>（</rp><rt>ひたちのくに</rt><rp>）</rp></ruby>の国府"},
+		{"明日|香村《あすかむら》と佐々木《ささき》", RubyParen, "明日香村（あすかむら）と佐々木（ささき）"},
+		{"第1《いち》問", RubyParen, "第1《いち》問"},
+		{"かな《かな》", RubyParen, "かな《かな》"},
+	}
+	for _, tt := range tests {
+		if got := Furigana(tt.text, tt.style); got != tt.want {
+			t.Errorf("Furigana(%q, %q) = %q, want %q", tt.text, tt.style, got, tt.want)
+		}
+	}
+}
+
+func TestRubyStyle_HTML(t *testing.T) {
+	data := []QuizItem{{Question: "奈良県の｜明日香村《あすかむら》にある／古墳は？", Answer: "石舞台古墳", Reading: "いしぶたいこふん", Comments: []string{"蘇我馬子《そがのうまこ》の墓とされる"}}}
+	tests := []struct {
+		style RubyStyle
+		want  []string
+	}{
+		{RubyTags, []string{"<ruby>明日香村<rp>（</rp><rt>あすかむら</rt><rp>）</rp></ruby>", "<ruby>石舞台古墳<rp>（</rp><rt>いしぶたいこふん</rt><rp>）</rp></ruby>", "<ruby>蘇我馬子<rp>"}},
+		{RubyParen, []string{"明日香村（あすかむら）にある", "石舞台古墳（いしぶたいこふん）", "蘇我馬子（そがのうまこ）の墓"}},
+	}
+	html, err := LookupFormatter("html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		if err := html.Format(&buf, data, ConvertOptions{RubyStyle: tt.style}); err != nil {
+			t.Fatalf("Format() error = %v", err)
+		}
+		for _, want := range tt.want {
+			if !strings.Contains(buf.String(), want) {
+				t.Errorf("HTML with ruby style %q does not contain %q", tt.style, want)
+			}
+		}
+		if strings.Contains(buf.String(), "《") {
+			t.Errorf("HTML with ruby style %q contains the ruby notation", tt.style)
+		}
+	}
+}
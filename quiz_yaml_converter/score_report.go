@@ -0,0 +1,138 @@
+// 成績から，プレイヤーごと・問題ごとの成績表をテンプレートで出力する処理です．
+package quiz_yaml_converter
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+
+	"github.com/m-uesaka/quiz-yaml-go/templates"
+)
+
+// String はテンプレートで結果を表示するための記号（正解は○，不正解は×，解答なしは空）を返す．
+func (o Outcome) String() string {
+	switch o {
+	case OutcomeCorrect:
+		return "○"
+	case OutcomeIncorrect:
+		return "×"
+	}
+	return ""
+}
+
+// formatPercent は0〜1の割合を"66.7%"のように小数点以下1桁の百分率にする．
+func formatPercent(rate float64) string {
+	return strconv.FormatFloat(rate*100, 'f', 1, 64) + "%"
+}
+
+// PlayerStats は1人のプレイヤーの成績の集計．
+type PlayerStats struct {
+	Rank     int       // 順位（正解数が多い順．正解数と不正解数が同じ場合は同順位）
+	Name     string    // プレイヤー名
+	Correct  int       // 正解した問題の数
+	Attempts int       // 解答した問題の数
+	Outcomes []Outcome // 問題の順（QuestionResultsと同じ順）の結果
+}
+
+// Incorrect は不正解の問題の数を返す．
+func (s PlayerStats) Incorrect() int {
+	return s.Attempts - s.Correct
+}
+
+// CorrectRate は正答率（0〜1）を返す．解答した問題がない場合は0を返す．
+func (s PlayerStats) CorrectRate() float64 {
+	if s.Attempts == 0 {
+		return 0
+	}
+	return float64(s.Correct) / float64(s.Attempts)
+}
+
+// PlayerResults はプレイヤーごとに，itemsに含まれる問題の成績を集計し，順位の順に返す．
+// 正解数が多いほど，正解数が同じ場合は不正解数が少ないほど上位とし，
+// どちらも同じ場合は同順位として成績ファイルの列順に並べる．
+func PlayerResults(items []QuizItem, results *PlayResults) []PlayerStats {
+	players := make([]PlayerStats, len(results.Players))
+	for p, name := range results.Players {
+		players[p] = PlayerStats{Name: name, Outcomes: make([]Outcome, len(items))}
+	}
+	for i, item := range items {
+		outcomes, ok := results.Outcomes[resultsID(item)]
+		if !ok {
+			continue
+		}
+		for p, o := range outcomes[:min(len(outcomes), len(players))] {
+			players[p].Outcomes[i] = o
+			switch o {
+			case OutcomeCorrect:
+				players[p].Attempts++
+				players[p].Correct++
+			case OutcomeIncorrect:
+				players[p].Attempts++
+			}
+		}
+	}
+	sort.SliceStable(players, func(i, j int) bool {
+		if players[i].Correct != players[j].Correct {
+			return players[i].Correct > players[j].Correct
+		}
+		return players[i].Incorrect() < players[j].Incorrect()
+	})
+	for i := range players {
+		players[i].Rank = i + 1
+		if i > 0 && players[i].Correct == players[i-1].Correct && players[i].Incorrect() == players[i-1].Incorrect() {
+			players[i].Rank = players[i-1].Rank
+		}
+	}
+	return players
+}
+
+// ScoreReportData は成績表のテンプレートに渡すデータ．
+type ScoreReportData struct {
+	Items     []QuizItem        // 問題データのリスト
+	Numbers   []string          // 各問題の番号（Itemsと同じ順序）
+	Questions []QuestionStats   // 問題ごとの成績（Itemsと同じ順序）
+	Players   []PlayerStats     // プレイヤーごとの成績（順位の順）
+	Unmatched []string          // どの問題にも対応しなかった成績の問題ID
+	Vars      map[string]string // 変換時に指定した変数（ConvertOptions.Vars）
+}
+
+// NewScoreReportData は問題データと成績から成績表のテンプレートに渡すデータを作成する．
+func NewScoreReportData(items []QuizItem, results *PlayResults, opts ConvertOptions) ScoreReportData {
+	questions, unmatched := QuestionResults(items, results)
+	return ScoreReportData{
+		Items:     items,
+		Numbers:   QuestionNumbers(items, opts.Numbering),
+		Questions: questions,
+		Players:   PlayerResults(items, results),
+		Unmatched: unmatched,
+		Vars:      opts.Vars,
+	}
+}
+
+// WriteScoreReport は問題データと成績から，プレイヤーごと・問題ごとの成績表を
+// テンプレートファイルに従って整形し，wに書き出す．テンプレートにはScoreReportDataが渡される．
+// templateFilePathとopts.TemplateTextがどちらも空の場合は組み込みのHTMLテンプレートを使う．
+// opts.Layoutは使わない．
+func WriteScoreReport(w io.Writer, items []QuizItem, results *PlayResults, templateFilePath string, opts ConvertOptions) error {
+	if templateFilePath == "" && opts.TemplateText == "" {
+		opts.TemplateText = templates.ScoreReport
+		opts.SafeTemplates = false // 組み込みのテンプレートは信頼できる
+	}
+	opts.Layout = ""
+	tmpl, err := parseTemplateFile(templateFilePath, opts)
+	if err != nil {
+		return err
+	}
+	if err := executeWithLimits(w, tmpl, NewScoreReportData(items, results, opts), opts); err != nil {
+		return fmt.Errorf("%w: %w", ErrTemplateExecute, err)
+	}
+	return nil
+}
+
+// ConvertToScoreReport はWriteScoreReportの成績表をoutputFilePathに書き出す．
+func ConvertToScoreReport(items []QuizItem, results *PlayResults, templateFilePath, outputFilePath string, opts ConvertOptions) error {
+	return writeFileAtomic(outputFilePath, opts.NoClobber, func(w io.Writer) error {
+		return WriteScoreReport(w, items, results, templateFilePath, opts)
+	})
+}
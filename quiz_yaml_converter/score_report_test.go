@@ -0,0 +1,118 @@
+package quiz_yaml_converter
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestPlayerResults(t *testing.T) {
+	items := []QuizItem{
+		{ID: "q1", Question: "問題1", Answer: "答え1"},
+		{ID: "q2", Question: "問題2", Answer: "答え2"},
+		{ID: "q3", Question: "問題3", Answer: "答え3"},
+	}
+	results := &PlayResults{
+		Players: []string{"山田", "佐藤", "鈴木", "田中"},
+		IDs:     []string{"q1", "q2", "q9"},
+		Outcomes: map[string][]Outcome{
+			"q1": {OutcomeCorrect, OutcomeCorrect, OutcomeIncorrect, OutcomeCorrect},
+			"q2": {OutcomeIncorrect, OutcomeCorrect, OutcomeNone, OutcomeNone},
+			"q9": {OutcomeCorrect, OutcomeCorrect, OutcomeCorrect, OutcomeCorrect},
+		},
+	}
+	expected := []PlayerStats{
+		{Rank: 1, Name: "佐藤", Correct: 2, Attempts: 2, Outcomes: []Outcome{OutcomeCorrect, OutcomeCorrect, OutcomeNone}},
+		{Rank: 2, Name: "田中", Correct: 1, Attempts: 1, Outcomes: []Outcome{OutcomeCorrect, OutcomeNone, OutcomeNone}},
+		{Rank: 3, Name: "山田", Correct: 1, Attempts: 2, Outcomes: []Outcome{OutcomeCorrect, OutcomeIncorrect, OutcomeNone}},
+		{Rank: 4, Name: "鈴木", Correct: 0, Attempts: 1, Outcomes: []Outcome{OutcomeIncorrect, OutcomeNone, OutcomeNone}},
+	}
+	got := PlayerResults(items, results)
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("PlayerResults() = %+v, want %+v", got, expected)
+	}
+	if got[2].Incorrect() != 1 || got[2].CorrectRate() != 0.5 {
+		t.Errorf("Incorrect() = %d, CorrectRate() = %v, want 1, 0.5", got[2].Incorrect(), got[2].CorrectRate())
+	}
+
+	// 正解数と不正解数が同じプレイヤーは同順位
+	tied := PlayerResults(items[:1], &PlayResults{
+		Players:  []string{"A", "B", "C"},
+		Outcomes: map[string][]Outcome{"q1": {OutcomeCorrect, OutcomeIncorrect, OutcomeCorrect}},
+	})
+	var ranks []int
+	for _, p := range tied {
+		ranks = append(ranks, p.Rank)
+	}
+	if !reflect.DeepEqual(ranks, []int{1, 1, 3}) {
+		t.Errorf("PlayerResults() ranks = %v, want [1 1 3]", ranks)
+	}
+}
+
+func TestWriteScoreReport(t *testing.T) {
+	items := []QuizItem{
+		{ID: "q1", Question: "前半／後半", Answer: "答え1"},
+		{ID: "q2", Question: "問題2", Answer: "答え2"},
+	}
+	results := &PlayResults{
+		Players: []string{"山田", "佐藤"},
+		IDs:     []string{"q1", "q9"},
+		Outcomes: map[string][]Outcome{
+			"q1": {OutcomeCorrect, OutcomeIncorrect},
+			"q9": {OutcomeCorrect, OutcomeCorrect},
+		},
+	}
+
+	t.Run("builtin", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := WriteScoreReport(&buf, items, results, "", ConvertOptions{Vars: map[string]string{"title": "第1回大会"}}); err != nil {
+			t.Fatalf("WriteScoreReport() error = %v", err)
+		}
+		for _, s := range []string{
+			"<title>第1回大会</title>",
+			"<td>山田</td>",
+			`<td class="rate">100.0%</td>`,
+			`<td class="outcome">○</td><td class="outcome"></td>`,
+			`<td class="question">前半後半</td>`,
+			`<span class="none">成績なし</span>`,
+			"問題に対応しない成績: q9",
+		} {
+			if !strings.Contains(buf.String(), s) {
+				t.Errorf("output does not contain %q:\n%s", s, buf.String())
+			}
+		}
+	})
+
+	t.Run("custom template", func(t *testing.T) {
+		dir := t.TempDir()
+		tmplPath := filepath.Join(dir, "report.txt")
+		text := "{{range .Players}}{{.Rank}}. {{.Name}} {{.Correct}}/{{.Attempts}} {{range .Outcomes}}[{{.}}]{{end}}\n{{end}}" +
+			"{{range $i, $q := .Questions}}Q{{index $.Numbers $i}} {{percent .CorrectRate}}\n{{end}}"
+		if err := os.WriteFile(tmplPath, []byte(text), 0644); err != nil {
+			t.Fatal(err)
+		}
+		outPath := filepath.Join(dir, "report.out")
+		if err := ConvertToScoreReport(items, results, tmplPath, outPath, ConvertOptions{}); err != nil {
+			t.Fatalf("ConvertToScoreReport() error = %v", err)
+		}
+		got, err := os.ReadFile(outPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected := "1. 山田 1/1 [○][]\n2. 佐藤 0/1 [×][]\nQ1 50.0%\nQ2 0.0%\n"
+		if string(got) != expected {
+			t.Errorf("ConvertToScoreReport() = %q, want %q", got, expected)
+		}
+	})
+
+	t.Run("execute error", func(t *testing.T) {
+		err := WriteScoreReport(&bytes.Buffer{}, items, results, "", ConvertOptions{TemplateText: "{{.Missing}}"})
+		if !errors.Is(err, ErrTemplateExecute) {
+			t.Errorf("WriteScoreReport() error = %v, want ErrTemplateExecute", err)
+		}
+	})
+}
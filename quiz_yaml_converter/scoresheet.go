@@ -0,0 +1,148 @@
+// 大会の進行中に，問題を1問ずつ進めながら各プレイヤーの正誤を記録する処理です．
+package quiz_yaml_converter
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Scoresheet は問題を1問ずつ進めながら，各プレイヤーの正誤を記録する．
+// 記録した結果はResultsでPlayResultsとして取り出せる．
+type Scoresheet struct {
+	items    []QuizItem
+	players  []string
+	ids      []string    // 問題ごとの成績の問題ID（resultsID参照）
+	outcomes [][]Outcome // 問題ごとの，プレイヤー順の結果
+	current  int         // 出題中の問題の位置（0始まり）
+	reached  int         // これまでに出題した最も後ろの問題の位置（0始まり）
+}
+
+// NewScoresheet は問題とプレイヤー名から記録用のScoresheetを作成する．
+// 問題とプレイヤーはそれぞれ1つ以上必要で，プレイヤー名は空や重複があってはならない．
+// 成績の問題ID（idがあればid，なければContentID）が重複する問題がある場合もエラーとなる．
+func NewScoresheet(items []QuizItem, players []string) (*Scoresheet, error) {
+	if len(items) == 0 {
+		return nil, fmt.Errorf("no items")
+	}
+	if len(players) == 0 {
+		return nil, fmt.Errorf("no players")
+	}
+	seenPlayers := map[string]bool{}
+	for _, name := range players {
+		if strings.TrimSpace(name) == "" {
+			return nil, fmt.Errorf("player name is empty")
+		}
+		if seenPlayers[name] {
+			return nil, fmt.Errorf("duplicate player name %q", name)
+		}
+		seenPlayers[name] = true
+	}
+	s := &Scoresheet{items: items, players: players, ids: make([]string, len(items)), outcomes: make([][]Outcome, len(items))}
+	seenIDs := map[string]int{}
+	for i, item := range items {
+		id := resultsID(item)
+		if prev, ok := seenIDs[id]; ok {
+			return nil, fmt.Errorf("items %d and %d have the same id %q", prev+1, i+1, id)
+		}
+		seenIDs[id] = i
+		s.ids[i] = id
+		s.outcomes[i] = make([]Outcome, len(players))
+	}
+	return s, nil
+}
+
+// Items は問題を返す．
+func (s *Scoresheet) Items() []QuizItem {
+	return s.items
+}
+
+// Players はプレイヤー名を返す．
+func (s *Scoresheet) Players() []string {
+	return s.players
+}
+
+// Len は問題の数を返す．
+func (s *Scoresheet) Len() int {
+	return len(s.items)
+}
+
+// Current は出題中の問題の位置（0始まり）を返す．
+func (s *Scoresheet) Current() int {
+	return s.current
+}
+
+// Item は出題中の問題を返す．
+func (s *Scoresheet) Item() QuizItem {
+	return s.items[s.current]
+}
+
+// Outcomes は出題中の問題の，プレイヤー順の結果を返す．
+func (s *Scoresheet) Outcomes() []Outcome {
+	return s.outcomes[s.current]
+}
+
+// Next は次の問題に進む．最後の問題を出題中の場合は進まずにfalseを返す．
+func (s *Scoresheet) Next() bool {
+	if s.current+1 >= len(s.items) {
+		return false
+	}
+	s.current++
+	s.reached = max(s.reached, s.current)
+	return true
+}
+
+// Prev は前の問題に戻る．最初の問題を出題中の場合は戻らずにfalseを返す．
+func (s *Scoresheet) Prev() bool {
+	if s.current == 0 {
+		return false
+	}
+	s.current--
+	return true
+}
+
+// Mark は出題中の問題のplayer番目（0始まり）のプレイヤーの結果を記録する．
+func (s *Scoresheet) Mark(player int, outcome Outcome) error {
+	if player < 0 || player >= len(s.players) {
+		return fmt.Errorf("player %d out of range", player+1)
+	}
+	s.outcomes[s.current][player] = outcome
+	return nil
+}
+
+// Toggle は出題中の問題のplayer番目（0始まり）のプレイヤーの結果を，
+// 解答なし → 正解 → 不正解 → 解答なしの順に切り替え，切り替えた後の結果を返す．
+func (s *Scoresheet) Toggle(player int) (Outcome, error) {
+	if player < 0 || player >= len(s.players) {
+		return OutcomeNone, fmt.Errorf("player %d out of range", player+1)
+	}
+	next := map[Outcome]Outcome{OutcomeNone: OutcomeCorrect, OutcomeCorrect: OutcomeIncorrect, OutcomeIncorrect: OutcomeNone}
+	o := next[s.outcomes[s.current][player]]
+	s.outcomes[s.current][player] = o
+	return o, nil
+}
+
+// Scores はプレイヤー順の正解数を返す．
+func (s *Scoresheet) Scores() []int {
+	scores := make([]int, len(s.players))
+	for _, outcomes := range s.outcomes {
+		for p, o := range outcomes {
+			if o == OutcomeCorrect {
+				scores[p]++
+			}
+		}
+	}
+	return scores
+}
+
+// Results は記録した結果を返す．出題した最も後ろの問題までを含み，まだ出題していない問題は含まない．
+func (s *Scoresheet) Results() *PlayResults {
+	results := &PlayResults{
+		Players:  append([]string(nil), s.players...),
+		IDs:      append([]string(nil), s.ids[:s.reached+1]...),
+		Outcomes: make(map[string][]Outcome, s.reached+1),
+	}
+	for i, id := range results.IDs {
+		results.Outcomes[id] = append([]Outcome(nil), s.outcomes[i]...)
+	}
+	return results
+}
@@ -0,0 +1,92 @@
+package quiz_yaml_converter
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNewScoresheet(t *testing.T) {
+	items := []QuizItem{{ID: "q1", Question: "問題1", Answer: "答え1"}, {Question: "問題2", Answer: "答え2"}}
+	tests := []struct {
+		name    string
+		items   []QuizItem
+		players []string
+		wantErr bool
+	}{
+		{"valid", items, []string{"山田", "佐藤"}, false},
+		{"no items", nil, []string{"山田"}, true},
+		{"no players", items, nil, true},
+		{"empty player", items, []string{"山田", " "}, true},
+		{"duplicate player", items, []string{"山田", "山田"}, true},
+		{"duplicate id", []QuizItem{items[1], items[1]}, []string{"山田"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewScoresheet(tt.items, tt.players)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewScoresheet() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestScoresheet(t *testing.T) {
+	items := []QuizItem{
+		{ID: "q1", Question: "問題1", Answer: "答え1"},
+		{ID: "q2", Question: "問題2", Answer: "答え2"},
+		{ID: "q3", Question: "問題3", Answer: "答え3"},
+	}
+	s, err := NewScoresheet(items, []string{"山田", "佐藤"})
+	if err != nil {
+		t.Fatalf("NewScoresheet() error = %v", err)
+	}
+	if s.Prev() {
+		t.Error("Prev() at the first item = true")
+	}
+	for _, want := range []Outcome{OutcomeCorrect, OutcomeIncorrect, OutcomeNone, OutcomeCorrect} {
+		if got, _ := s.Toggle(0); got != want {
+			t.Errorf("Toggle() = %v, want %v", got, want)
+		}
+	}
+	if err := s.Mark(1, OutcomeIncorrect); err != nil {
+		t.Fatalf("Mark() error = %v", err)
+	}
+	if err := s.Mark(2, OutcomeCorrect); err == nil {
+		t.Error("Mark() with an unknown player expected error")
+	}
+	if _, err := s.Toggle(-1); err == nil {
+		t.Error("Toggle() with an unknown player expected error")
+	}
+	if !s.Next() || s.Current() != 1 || s.Item().ID != "q2" {
+		t.Fatalf("Next() moved to %d", s.Current())
+	}
+	s.Mark(1, OutcomeCorrect)
+	s.Prev()
+	if !reflect.DeepEqual(s.Outcomes(), []Outcome{OutcomeCorrect, OutcomeIncorrect}) {
+		t.Errorf("Outcomes() = %v", s.Outcomes())
+	}
+	if !reflect.DeepEqual(s.Scores(), []int{1, 1}) {
+		t.Errorf("Scores() = %v, want [1 1]", s.Scores())
+	}
+
+	expected := &PlayResults{
+		Players: []string{"山田", "佐藤"},
+		IDs:     []string{"q1", "q2"},
+		Outcomes: map[string][]Outcome{
+			"q1": {OutcomeCorrect, OutcomeIncorrect},
+			"q2": {OutcomeNone, OutcomeCorrect},
+		},
+	}
+	if got := s.Results(); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Results() = %+v, want %+v", got, expected)
+	}
+
+	s.Next()
+	s.Next()
+	if s.Next() {
+		t.Error("Next() at the last item = true")
+	}
+	if got := s.Results(); len(got.IDs) != s.Len() {
+		t.Errorf("Results() IDs = %v, want all %d items", got.IDs, s.Len())
+	}
+}
@@ -0,0 +1,57 @@
+package quiz_yaml_converter
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SegmentMarker は問題文中で並列の節（「〜ですが」「一方」など）が始まる
+// 位置，いわゆる早押しポイントを示す区切り記号．
+const SegmentMarker = "／"
+
+// QuestionSegments は問題文を区切りごとに分割して返す．
+// segmentsフィールドが指定されている場合はそれを優先し，指定されていない場合は
+// 問題文をSegmentMarkerで分割する．区切りがない場合は問題文全体の1要素を返す．
+func QuestionSegments(item QuizItem) []string {
+	if len(item.Segments) > 0 {
+		return item.Segments
+	}
+	return strings.Split(item.Question, SegmentMarker)
+}
+
+// PlainQuestion は区切り記号を取り除いた問題文を返す．
+// segmentsフィールドのみが指定されている場合も同じ結果となる．
+func PlainQuestion(item QuizItem) string {
+	return strings.ReplaceAll(item.Question, SegmentMarker, "")
+}
+
+// validateSegments は問題文の区切り記号とsegmentsフィールドをバリデーションする．
+func validateSegments(item QuizItem, index int) []ValidationError {
+	var errors []ValidationError
+	add := func(field, format string, args ...any) {
+		errors = append(errors, newValidationError(index, field, RuleSegments, nil, format, args...))
+	}
+
+	if strings.Contains(item.Question, SegmentMarker) {
+		for _, segment := range strings.Split(item.Question, SegmentMarker) {
+			if strings.TrimSpace(segment) == "" {
+				add("question", "問題文 (question) の区切り記号「%s」の前後が空です", SegmentMarker)
+				break
+			}
+		}
+	}
+
+	if len(item.Segments) == 0 {
+		return errors
+	}
+	for j, segment := range item.Segments {
+		if strings.TrimSpace(segment) == "" {
+			field := fmt.Sprintf("segments[%d]", j)
+			add(field, "%s が空です", field)
+		}
+	}
+	if strings.TrimSpace(item.Question) != "" && strings.Join(item.Segments, "") != PlainQuestion(item) {
+		add("segments", "segmentsをつなげた文字列が問題文 (question) と一致しません")
+	}
+	return errors
+}
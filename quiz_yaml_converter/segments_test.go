@@ -0,0 +1,104 @@
+package quiz_yaml_converter
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestQuestionSegments(t *testing.T) {
+	tests := []struct {
+		name     string
+		item     QuizItem
+		expected []string
+	}{
+		{
+			name:     "no marker",
+			item:     QuizItem{Question: "日本の首都は？"},
+			expected: []string{"日本の首都は？"},
+		},
+		{
+			name:     "marker in question",
+			item:     QuizItem{Question: "日本の首都は東京ですが、／アメリカの首都は？"},
+			expected: []string{"日本の首都は東京ですが、", "アメリカの首都は？"},
+		},
+		{
+			name: "segments field takes precedence",
+			item: QuizItem{
+				Question: "日本の首都は東京ですが、アメリカの首都は？",
+				Segments: []string{"日本の首都は東京ですが、", "アメリカの首都は？"},
+			},
+			expected: []string{"日本の首都は東京ですが、", "アメリカの首都は？"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := QuestionSegments(tt.item); !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("QuestionSegments() = %q, want %q", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestPlainQuestion(t *testing.T) {
+	item := QuizItem{Question: "日本の首都は東京ですが、／アメリカの首都は？"}
+	if result := PlainQuestion(item); result != "日本の首都は東京ですが、アメリカの首都は？" {
+		t.Errorf("PlainQuestion() = %q", result)
+	}
+}
+
+func TestValidateSegments(t *testing.T) {
+	tests := []struct {
+		name   string
+		item   QuizItem
+		fields []string
+	}{
+		{
+			name:   "valid marker",
+			item:   QuizItem{Question: "前振り／後半"},
+			fields: nil,
+		},
+		{
+			name:   "marker at end",
+			item:   QuizItem{Question: "前振り／"},
+			fields: []string{"question"},
+		},
+		{
+			name:   "consecutive markers",
+			item:   QuizItem{Question: "前振り／／後半"},
+			fields: []string{"question"},
+		},
+		{
+			name:   "valid segments field",
+			item:   QuizItem{Question: "前振り後半", Segments: []string{"前振り", "後半"}},
+			fields: nil,
+		},
+		{
+			name:   "segments field with marker in question",
+			item:   QuizItem{Question: "前振り／後半", Segments: []string{"前振り", "後半"}},
+			fields: nil,
+		},
+		{
+			name:   "segments do not match question",
+			item:   QuizItem{Question: "前振り後半", Segments: []string{"前振り", "後"}},
+			fields: []string{"segments"},
+		},
+		{
+			name:   "empty segment",
+			item:   QuizItem{Question: "前振り後半", Segments: []string{"前振り後半", " "}},
+			fields: []string{"segments[1]", "segments"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var fields []string
+			for _, e := range validateSegments(tt.item, 1) {
+				fields = append(fields, e.Field)
+			}
+			if !reflect.DeepEqual(fields, tt.fields) {
+				t.Errorf("validateSegments() fields = %q, want %q", fields, tt.fields)
+			}
+		})
+	}
+}
@@ -0,0 +1,108 @@
+// 不備のある問題を取り除いて読み込む処理です．
+// 編集途中のファイルからプレビューを生成する場合などに，一部の問題の不備で変換全体が失敗しないようにします．
+package quiz_yaml_converter
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// LoadValidItems はLoadYAMLFilesと同様に複数のYAMLファイルを読み込んで連結するが，
+// デコードできない問題とバリデーションに失敗する問題を取り除き，取り除いた問題のエラーを返す．
+// エラーのIndexはすべてのファイルを連結したときの問題の番号，File・Lineは問題の位置となる．
+// YAMLの構文エラーなどでファイル全体を読み込めない場合は，LoadYAMLFilesと同じくエラーを返す．
+func LoadValidItems(yamlFilePaths []string) ([]QuizItem, []ValidationError, error) {
+	var data []QuizItem
+	var skipped []ValidationError
+	count := 0
+	for _, path := range yamlFilePaths {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			err = fmt.Errorf("failed to read YAML file: %w", err)
+		}
+		var items []QuizItem
+		var errs []ValidationError
+		n := 0
+		if err == nil {
+			items, errs, n, err = parseValidItems(content, count)
+		}
+		if err != nil {
+			if len(yamlFilePaths) > 1 {
+				return nil, nil, fmt.Errorf("%s: %w", path, err)
+			}
+			return nil, nil, err
+		}
+		for i := range errs {
+			errs[i].File = path
+		}
+		data = append(data, items...)
+		skipped = append(skipped, errs...)
+		count += n
+	}
+	return data, skipped, nil
+}
+
+// parseValidItems はParseYAMLDataと同様にYAMLデータを解析し，不備のある問題を取り除いた問題データと，
+// 取り除いた問題のエラー，取り除いた問題を含むすべての問題の数を返す．
+// エラーのIndexは，offsetにこのデータ中の問題の番号を足したものとなる．
+func parseValidItems(yamlData []byte, offset int) ([]QuizItem, []ValidationError, int, error) {
+	decoder := yaml.NewDecoder(bytes.NewReader(bytes.TrimPrefix(yamlData, []byte(utf8BOM))))
+	var documents [][]QuizItem
+	var skipped []ValidationError
+	var meta FileMetadata
+	count := 0
+	for {
+		var node yaml.Node
+		if err := decoder.Decode(&node); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, nil, 0, fmt.Errorf("%w: %w", ErrInvalidYAML, err)
+		}
+		// 問題を含むドキュメントは，すべての問題を取り除いた場合もドキュメントの番号に数える
+		items := []QuizItem{}
+		start := count
+		err := eachQuizItemNode(&node, &meta, func(child *yaml.Node, genre string) error {
+			count++
+			index := offset + count
+			item, err := decodeQuizItem(child, genre, &meta)
+			if err != nil {
+				e := newValidationError(index, "", RuleSyntax, err, "問題を読み込めません: %v", err)
+				e.Line = child.Line
+				skipped = append(skipped, e)
+				return nil
+			}
+			if errs := validateQuizItem(item, index); len(errs) > 0 {
+				for i := range errs {
+					errs[i].Line = child.Line
+				}
+				skipped = append(skipped, errs...)
+				return nil
+			}
+			items = append(items, item)
+			return nil
+		})
+		if err != nil {
+			return nil, nil, 0, fmt.Errorf("%w: %w", ErrInvalidYAML, err)
+		}
+		if count > start {
+			documents = append(documents, items)
+		}
+	}
+
+	var data []QuizItem
+	for i, items := range documents {
+		if len(documents) > 1 {
+			for j := range items {
+				items[j].Document = i + 1
+			}
+		}
+		data = append(data, items...)
+	}
+	return data, skipped, count, nil
+}
@@ -0,0 +1,83 @@
+package quiz_yaml_converter
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLoadValidItems(t *testing.T) {
+	tempDir := t.TempDir()
+	first := filepath.Join(tempDir, "first.yaml")
+	second := filepath.Join(tempDir, "second.yaml")
+	files := map[string]string{
+		first: "- question: q1\n  answer: a1\n" +
+			"- question: q2\n  answer: \"\"\n" +
+			"---\n" +
+			"- question: q3\n  answer: a3\n",
+		second: "歴史:\n" +
+			"  - question: [q4]\n    answer: a4\n" +
+			"  - question: q5\n    answer: a5\n",
+	}
+	for path, content := range files {
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create YAML file: %v", err)
+		}
+	}
+
+	data, skipped, err := LoadValidItems([]string{first, second})
+	if err != nil {
+		t.Fatalf("LoadValidItems() error = %v", err)
+	}
+	expected := []QuizItem{
+		{Question: "q1", Answer: "a1", Document: 1},
+		{Question: "q3", Answer: "a3", Document: 2},
+		{Question: "q5", Answer: "a5", Genre: "歴史"},
+	}
+	if !reflect.DeepEqual(data, expected) {
+		t.Errorf("LoadValidItems() = %+v, want %+v", data, expected)
+	}
+
+	type location struct {
+		Index int
+		Rule  string
+		File  string
+		Line  int
+	}
+	var locations []location
+	for _, e := range skipped {
+		locations = append(locations, location{e.Index, e.Rule, e.File, e.Line})
+	}
+	expectedLocations := []location{
+		{2, RuleRequired, first, 3},
+		{4, RuleSyntax, second, 2},
+	}
+	if !reflect.DeepEqual(locations, expectedLocations) {
+		t.Errorf("LoadValidItems() skipped = %+v, want %+v", locations, expectedLocations)
+	}
+}
+
+func TestLoadValidItems_Errors(t *testing.T) {
+	tempDir := t.TempDir()
+	broken := filepath.Join(tempDir, "broken.yaml")
+	if err := os.WriteFile(broken, []byte("- question: [\n"), 0644); err != nil {
+		t.Fatalf("Failed to create YAML file: %v", err)
+	}
+	tests := []struct {
+		name  string
+		paths []string
+		isErr error
+	}{
+		{"syntax error", []string{broken}, ErrInvalidYAML},
+		{"missing file", []string{filepath.Join(tempDir, "missing.yaml")}, os.ErrNotExist},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := LoadValidItems(tt.paths); !errors.Is(err, tt.isErr) {
+				t.Errorf("LoadValidItems() error = %v, want %v", err, tt.isErr)
+			}
+		})
+	}
+}
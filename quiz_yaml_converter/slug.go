@@ -0,0 +1,88 @@
+// 静的サイトのURLやページ間の参照に使う，問題ごとの短いスラッグを作成する処理です．
+package quiz_yaml_converter
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// スラッグの元にする値
+type SlugSource string
+
+// スラッグの元にする値
+const (
+	SlugFromID     SlugSource = "id"     // 問題ID（idがなければContentID）
+	SlugFromAnswer SlugSource = "answer" // 答えの読み（読みがなければ答え）のローマ字と，問題IDから決まる4桁の英数字
+)
+
+// スラッグの最大の長さ（バイト数）
+const maxSlugLength = 48
+
+// ParseSlugSource はスラッグの元にする値の名前を解析する．空文字列の場合はSlugFromIDとなる．
+func ParseSlugSource(name string) (SlugSource, error) {
+	switch source := SlugSource(strings.ToLower(strings.TrimSpace(name))); source {
+	case "":
+		return SlugFromID, nil
+	case SlugFromID, SlugFromAnswer:
+		return source, nil
+	default:
+		return "", fmt.Errorf("unsupported slug source: %q (available: id, answer)", name)
+	}
+}
+
+// Slug は文字列をURLに使える小文字の英数字とハイフンだけのスラッグにする．
+// 全角英数字は半角に，仮名はパスポートのヘボン式のローマ字に直し，それ以外の文字（漢字，記号，空白など）は
+// ハイフンで区切る．長い場合は48バイトまでに切り詰める．英数字と仮名を含まない場合は空文字列を返す．
+func Slug(s string) string {
+	romaji := ToRomaji(norm.NFKC.String(s), RomajiPassport)
+	var b strings.Builder
+	hyphen := false
+	for _, r := range strings.ToLower(romaji) {
+		switch {
+		case r >= 'a' && r <= 'z' || r >= '0' && r <= '9':
+			if hyphen && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			hyphen = false
+			b.WriteRune(r)
+		case r == '\'':
+			// 「ん」の後のアポストロフィ（n'a）は区切りにしない
+		default:
+			hyphen = true
+		}
+	}
+	slug := b.String()
+	if len(slug) > maxSlugLength {
+		slug = strings.TrimRight(slug[:maxSlugLength], "-")
+	}
+	return slug
+}
+
+// ItemSlug は問題のスラッグをsourceに従って返す．同じ問題からは常に同じスラッグが作られる．
+// SlugFromAnswerでは，同じ答えの問題を区別できるよう，答えのスラッグに問題IDから決まる4桁の英数字を付ける．
+// 答えにローマ字にできる文字がない場合（漢字だけで読みがない場合など）は問題IDのスラッグを返す．
+func ItemSlug(item QuizItem, source SlugSource) string {
+	id := resultsID(item)
+	idSlug := Slug(id)
+	if idSlug == "" {
+		idSlug = Slug(ContentID(item))
+	}
+	if source != SlugFromAnswer {
+		return idSlug
+	}
+	base := Slug(item.Reading)
+	if base == "" {
+		primary, _ := SplitAnswer(item.Answer)
+		base = Slug(primary)
+	}
+	if base == "" {
+		return idSlug
+	}
+	sum := sha256.Sum256([]byte(id))
+	suffix := hex.EncodeToString(sum[:2])
+	return strings.TrimRight(base[:min(len(base), maxSlugLength-len(suffix)-1)], "-") + "-" + suffix
+}
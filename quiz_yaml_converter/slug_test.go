@@ -0,0 +1,73 @@
+package quiz_yaml_converter
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestSlug(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"ふじさん", "fujisan"},
+		{"アルテ・マイスター", "arute-maisuta"},
+		{"しんいち", "shinichi"},
+		{"Hello, World!", "hello-world"},
+		{"ＤＮＡ　２重らせん", "dna-2-rasen"},
+		{"  --q-aa5cc3e0954e--  ", "q-aa5cc3e0954e"},
+		{"富士山", ""},
+		{"", ""},
+		{strings.Repeat("ab ", 30), strings.TrimRight(strings.Repeat("ab-", 16), "-")},
+	}
+	for _, tt := range tests {
+		if got := Slug(tt.input); got != tt.expected {
+			t.Errorf("Slug(%q) = %q, want %q", tt.input, got, tt.expected)
+		}
+	}
+}
+
+func TestItemSlug(t *testing.T) {
+	withID := QuizItem{ID: "Q001", Question: "日本一高い山は？", Answer: "富士山", Reading: "ふじさん"}
+	noReading := QuizItem{Question: "日本一高い山は？", Answer: "富士山"}
+	kana := QuizItem{Question: "ドイツの首都は？", Answer: "ベルリン（Berlin）"}
+
+	if got := ItemSlug(withID, SlugFromID); got != "q001" {
+		t.Errorf("ItemSlug(id) = %q, want q001", got)
+	}
+	if got := ItemSlug(noReading, ""); got != ContentID(noReading) {
+		t.Errorf("ItemSlug() without id = %q, want %q", got, ContentID(noReading))
+	}
+
+	got := ItemSlug(withID, SlugFromAnswer)
+	if !strings.HasPrefix(got, "fujisan-") || len(got) != len("fujisan-")+4 {
+		t.Errorf("ItemSlug(answer) = %q, want fujisan-xxxx", got)
+	}
+	if again := ItemSlug(withID, SlugFromAnswer); again != got {
+		t.Errorf("ItemSlug(answer) is not stable: %q and %q", got, again)
+	}
+	other := withID
+	other.ID = "Q002"
+	if ItemSlug(other, SlugFromAnswer) == got {
+		t.Errorf("ItemSlug(answer) is the same for different ids: %q", got)
+	}
+	if got := ItemSlug(kana, SlugFromAnswer); !strings.HasPrefix(got, "berurin-") {
+		t.Errorf("ItemSlug(answer) = %q, want berurin-xxxx", got)
+	}
+	if got := ItemSlug(noReading, SlugFromAnswer); got != ContentID(noReading) {
+		t.Errorf("ItemSlug(answer) without reading = %q, want %q", got, ContentID(noReading))
+	}
+}
+
+func TestTemplateFuncs_Slug(t *testing.T) {
+	items := []QuizItem{{ID: "q1", Question: "問題", Answer: "答え", Reading: "こたえ", Genre: "Science & Nature"}}
+	opts := ConvertOptions{SlugFrom: SlugFromAnswer, TemplateText: `{{range .Items}}{{slug .Genre}}/{{slug .}}{{end}}`}
+	var buf bytes.Buffer
+	if err := WriteTemplate(&buf, items, "slug.txt", opts); err != nil {
+		t.Fatalf("WriteTemplate() error = %v", err)
+	}
+	if want := "science-nature/" + ItemSlug(items[0], SlugFromAnswer); buf.String() != want {
+		t.Errorf("template output = %q, want %q", buf.String(), want)
+	}
+}
@@ -0,0 +1,66 @@
+// 問題の出典（source, reference）の確認と参考文献の一覧を作る処理です．
+package quiz_yaml_converter
+
+import (
+	"errors"
+	"strings"
+)
+
+// ErrMissingSource は出典（sourceとreference）が書かれていない問題があることを表す．
+var ErrMissingSource = errors.New("source or reference is required")
+
+// hasSource は問題に出典（sourceまたはreference）が書かれているかを返す．
+func hasSource(item QuizItem) bool {
+	return strings.TrimSpace(item.Source) != "" || strings.TrimSpace(item.Reference) != ""
+}
+
+// MissingSourceErrors は出典（sourceとreferenceのどちらも）が書かれていない問題についてのバリデーションエラーを返す．
+// 出典を必須とする大会などで，ValidateYAMLFilesの結果にValidationResult.AddErrorで加えて使う．
+func MissingSourceErrors(data []QuizItem) []ValidationError {
+	var errors []ValidationError
+	for i, item := range data {
+		if !hasSource(item) {
+			errors = append(errors, newValidationError(i+1, "source", RuleRequired, nil, "出典 (source または reference) が空です"))
+		}
+	}
+	return errors
+}
+
+// RequireSources は出典（sourceとreferenceのどちらも）が書かれていない問題があればエラーとするStage．
+// エラーはErrMissingSourceを含むItemErrorとなり，問題は変更しない．
+var RequireSources Stage = EachItem(func(_ int, item *QuizItem) (bool, error) {
+	if !hasSource(*item) {
+		return false, ErrMissingSource
+	}
+	return true, nil
+})
+
+// SourceEntry は参考文献の一覧の1項目．同じ出典を使った問題をまとめたもの．
+type SourceEntry struct {
+	Source    string   // 出典（書籍・記事・Webサイトの名前など）
+	Reference string   // 出典の参照先（URLやページ番号など）
+	Numbers   []string // この出典を使った問題の番号（出力順）
+}
+
+// Sources は問題の出典を，sourceとreferenceの組ごとに初めて現れた順にまとめた参考文献の一覧を返す．
+// 出典が書かれていない問題は含まない．テンプレートでは{{range .Sources}}のように使う．
+func (td TemplateData) Sources() []SourceEntry {
+	var entries []SourceEntry
+	positions := map[[2]string]int{}
+	for i, item := range td.Items {
+		if !hasSource(item) {
+			continue
+		}
+		key := [2]string{strings.TrimSpace(item.Source), strings.TrimSpace(item.Reference)}
+		pos, ok := positions[key]
+		if !ok {
+			pos = len(entries)
+			positions[key] = pos
+			entries = append(entries, SourceEntry{Source: key[0], Reference: key[1]})
+		}
+		if i < len(td.Numbers) {
+			entries[pos].Numbers = append(entries[pos].Numbers, td.Numbers[i])
+		}
+	}
+	return entries
+}
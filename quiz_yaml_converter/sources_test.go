@@ -0,0 +1,118 @@
+package quiz_yaml_converter
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMissingSourceErrors(t *testing.T) {
+	data := []QuizItem{
+		{Question: "q1", Answer: "a1", Source: "広辞苑 第七版"},
+		{Question: "q2", Answer: "a2", Reference: "https://example.com/"},
+		{Question: "q3", Answer: "a3", Source: "  "},
+		{Question: "q4", Answer: "a4"},
+	}
+	errs := MissingSourceErrors(data)
+	var indices []int
+	for _, e := range errs {
+		if e.Field != "source" || e.Rule != RuleRequired {
+			t.Errorf("MissingSourceErrors() = %+v, want a required error for source", e)
+		}
+		indices = append(indices, e.Index)
+	}
+	if want := []int{3, 4}; !reflect.DeepEqual(indices, want) {
+		t.Errorf("MissingSourceErrors() indices = %v, want %v", indices, want)
+	}
+
+	result := ValidateItems(data)
+	for _, e := range errs {
+		result.AddError(e)
+	}
+	if result.IsValid || len(result.Errors) != 2 {
+		t.Errorf("ValidationResult after AddError = %+v, want 2 errors", result)
+	}
+}
+
+func TestRequireSources(t *testing.T) {
+	items := []QuizItem{{Question: "q1", Answer: "a1", Source: "s1"}, {Question: "q2", Answer: "a2"}}
+	_, err := Pipeline{RequireSources}.Apply(items)
+	var itemErr *ItemError
+	if !errors.Is(err, ErrMissingSource) || !errors.As(err, &itemErr) || itemErr.Index != 2 {
+		t.Errorf("RequireSources error = %v, want ErrMissingSource for item 2", err)
+	}
+
+	got, err := Pipeline{RequireSources}.Apply(items[:1])
+	if err != nil {
+		t.Fatalf("RequireSources error = %v", err)
+	}
+	if !reflect.DeepEqual(got, items[:1]) {
+		t.Errorf("RequireSources = %+v, want %+v", got, items[:1])
+	}
+}
+
+func TestTemplateData_Sources(t *testing.T) {
+	td := TemplateData{
+		Items: []QuizItem{
+			{Question: "q1", Source: "広辞苑", Reference: "p. 12"},
+			{Question: "q2"},
+			{Question: "q3", Source: "理科年表"},
+			{Question: "q4", Source: " 広辞苑", Reference: "p. 12 "},
+			{Question: "q5", Reference: "https://example.com/"},
+		},
+		Numbers: []string{"1", "2", "3", "4", "5"},
+	}
+	expected := []SourceEntry{
+		{Source: "広辞苑", Reference: "p. 12", Numbers: []string{"1", "4"}},
+		{Source: "理科年表", Numbers: []string{"3"}},
+		{Reference: "https://example.com/", Numbers: []string{"5"}},
+	}
+	if got := td.Sources(); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Sources() = %+v, want %+v", got, expected)
+	}
+	if got := (TemplateData{Items: []QuizItem{{Question: "q1"}}}).Sources(); got != nil {
+		t.Errorf("Sources() without sources = %+v, want nil", got)
+	}
+}
+
+func TestBuiltinTemplates_Sources(t *testing.T) {
+	items := []QuizItem{
+		{Question: "q1", Answer: "a1", Source: "広辞苑", Reference: "p. 12"},
+		{Question: "q2", Answer: "a2"},
+		{Question: "q3", Answer: "a3", Source: "広辞苑", Reference: "p. 12"},
+	}
+	tests := []struct {
+		format   string
+		expected []string
+	}{
+		{"markdown", []string{"**Source:** 広辞苑 p. 12\n", "## Sources\n\n1. 広辞苑 p. 12 (Q1, Q3)\n"}},
+		{"html", []string{"<strong>出典:</strong> 広辞苑 p. 12\n", "<li>広辞苑 p. 12（Q1，Q3）</li>"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			f, err := LookupFormatter(tt.format)
+			if err != nil {
+				t.Fatalf("LookupFormatter() error = %v", err)
+			}
+			var buf bytes.Buffer
+			if err := f.Format(&buf, items, ConvertOptions{}); err != nil {
+				t.Fatalf("Format() error = %v", err)
+			}
+			for _, s := range tt.expected {
+				if !strings.Contains(buf.String(), s) {
+					t.Errorf("output does not contain %q:\n%s", s, buf.String())
+				}
+			}
+
+			buf.Reset()
+			if err := f.Format(&buf, items[1:2], ConvertOptions{}); err != nil {
+				t.Fatalf("Format() error = %v", err)
+			}
+			if strings.Contains(buf.String(), "Source") || strings.Contains(buf.String(), "出典") {
+				t.Errorf("output without sources contains a source section:\n%s", buf.String())
+			}
+		})
+	}
+}
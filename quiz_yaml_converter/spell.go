@@ -0,0 +1,125 @@
+package quiz_yaml_converter
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FlatSpellSeparator は複数言語の原語表記を1つの文字列にまとめるときの区切り．
+const FlatSpellSeparator = " / "
+
+// extractSpells は問題のマッピングノードのspellが言語コードから表記へのマッピングの場合，
+// その表記と言語の順序を取り出し，spellを取り除いたノードを返す．
+// spellが文字列の場合や存在しない場合はノードをそのまま返す．
+func extractSpells(item *yaml.Node) (*yaml.Node, map[string]string, []string, error) {
+	if item.Kind != yaml.MappingNode {
+		return item, nil, nil, nil
+	}
+	for i := 0; i+1 < len(item.Content); i += 2 {
+		key, value := item.Content[i], item.Content[i+1]
+		if key.Value != "spell" || value.Kind != yaml.MappingNode {
+			continue
+		}
+		spells := map[string]string{}
+		var order []string
+		for j := 0; j+1 < len(value.Content); j += 2 {
+			lang, text := value.Content[j], value.Content[j+1]
+			if text.Kind != yaml.ScalarNode {
+				return nil, nil, nil, fmt.Errorf("line %d: spell.%s must be a string", text.Line, lang.Value)
+			}
+			if _, dup := spells[lang.Value]; !dup {
+				order = append(order, lang.Value)
+			}
+			spells[lang.Value] = text.Value
+		}
+
+		rest := *item
+		rest.Content = append(append([]*yaml.Node{}, item.Content[:i]...), item.Content[i+2:]...)
+		return &rest, spells, order, nil
+	}
+	return item, nil, nil, nil
+}
+
+// SpellLanguages は原語表記の言語コードを返す．
+// YAMLに書かれていた順序を優先し，それ以外（JSONから読み込んだ場合など）はアルファベット順とする．
+// spellが文字列の場合はnilを返す．
+func (q QuizItem) SpellLanguages() []string {
+	if len(q.Spells) == 0 {
+		return nil
+	}
+	var langs []string
+	seen := map[string]bool{}
+	for _, lang := range q.spellOrder {
+		if _, ok := q.Spells[lang]; ok && !seen[lang] {
+			seen[lang] = true
+			langs = append(langs, lang)
+		}
+	}
+	var rest []string
+	for lang := range q.Spells {
+		if !seen[lang] {
+			rest = append(rest, lang)
+		}
+	}
+	sort.Strings(rest)
+	return append(langs, rest...)
+}
+
+// SpellIn は指定した言語の原語表記を返す．該当する言語がない場合は空文字列を返す．
+// テンプレートでは{{.SpellIn "de"}}のように使う．
+func (q QuizItem) SpellIn(lang string) string {
+	return q.Spells[lang]
+}
+
+// FlatSpell は原語表記を1つの文字列として返す．
+// 複数の言語の表記がある場合は，SpellLanguagesの順にFlatSpellSeparatorでつなぐ．
+// CSVのspell列やテンプレートでの表示に使う．
+func (q QuizItem) FlatSpell() string {
+	if len(q.Spells) == 0 {
+		return q.Spell
+	}
+	var texts []string
+	for _, lang := range q.SpellLanguages() {
+		if text := q.Spells[lang]; text != "" {
+			texts = append(texts, text)
+		}
+	}
+	return strings.Join(texts, FlatSpellSeparator)
+}
+
+// primarySpell は複数言語の原語表記のうち，最初の言語の表記を返す．
+func (q QuizItem) primarySpell() string {
+	if langs := q.SpellLanguages(); len(langs) > 0 {
+		return q.Spells[langs[0]]
+	}
+	return ""
+}
+
+// spellMappingNode は複数言語の原語表記をSpellLanguagesの順のマッピングノードにする．
+func (q QuizItem) spellMappingNode() *yaml.Node {
+	node := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	for _, lang := range q.SpellLanguages() {
+		node.Content = append(node.Content,
+			&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: lang},
+			&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: q.Spells[lang]},
+		)
+	}
+	return node
+}
+
+// validateSpells は複数言語の原語表記をバリデーションする．
+func validateSpells(item QuizItem, index int) []ValidationError {
+	var errors []ValidationError
+	for _, lang := range item.SpellLanguages() {
+		field := "spell." + lang
+		if strings.TrimSpace(lang) == "" {
+			errors = append(errors, newValidationError(index, "spell", RuleEmpty, nil, "spellの言語コードが空です"))
+		} else if strings.TrimSpace(item.Spells[lang]) == "" {
+			errors = append(errors, newValidationError(index, field, RuleEmpty, nil, "%s が空です", field))
+		}
+	}
+	return errors
+}
@@ -0,0 +1,104 @@
+package quiz_yaml_converter
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestParseYAMLData_Spells(t *testing.T) {
+	input := `- question: q1
+  answer: ミュンヘン
+  spell:
+    de: München
+    en: Munich
+- question: q2
+  answer: 東京
+  spell: Tokyo
+`
+	data, err := ParseYAMLData([]byte(input))
+	if err != nil {
+		t.Fatalf("ParseYAMLData() error = %v", err)
+	}
+
+	item := data[0]
+	if item.Spell != "München" {
+		t.Errorf("Spell = %q, want the first language's spelling", item.Spell)
+	}
+	if want := map[string]string{"de": "München", "en": "Munich"}; !reflect.DeepEqual(item.Spells, want) {
+		t.Errorf("Spells = %v, want %v", item.Spells, want)
+	}
+	if got := item.SpellLanguages(); !reflect.DeepEqual(got, []string{"de", "en"}) {
+		t.Errorf("SpellLanguages() = %v, want [de en]", got)
+	}
+	if got := item.SpellIn("en"); got != "Munich" {
+		t.Errorf("SpellIn(en) = %q, want Munich", got)
+	}
+	if got := item.FlatSpell(); got != "München / Munich" {
+		t.Errorf("FlatSpell() = %q", got)
+	}
+
+	if data[1].Spells != nil || data[1].FlatSpell() != "Tokyo" || data[1].SpellLanguages() != nil {
+		t.Errorf("string spell = %+v", data[1])
+	}
+}
+
+func TestParseYAMLData_SpellsInvalid(t *testing.T) {
+	input := "- question: q1\n  answer: a1\n  spell:\n    en: [a, b]\n"
+	if _, err := ParseYAMLData([]byte(input)); err == nil || !strings.Contains(err.Error(), "spell.en must be a string") {
+		t.Errorf("ParseYAMLData() error = %v", err)
+	}
+}
+
+func TestQuizItem_MarshalYAMLSpells(t *testing.T) {
+	input := "- question: q1\n  answer: a1\n  spell:\n    it: Firenze\n    en: Florence\n"
+	data, err := ParseYAMLData([]byte(input))
+	if err != nil {
+		t.Fatalf("ParseYAMLData() error = %v", err)
+	}
+	out, err := yaml.Marshal(data)
+	if err != nil {
+		t.Fatalf("yaml.Marshal() error = %v", err)
+	}
+	if i, j := strings.Index(string(out), "it: Firenze"), strings.Index(string(out), "en: Florence"); i < 0 || j < i {
+		t.Errorf("yaml.Marshal() = %s, want spell mapping in the original order", out)
+	}
+	reloaded, err := ParseYAMLData(out)
+	if err != nil {
+		t.Fatalf("ParseYAMLData() error = %v", err)
+	}
+	if !reflect.DeepEqual(reloaded, data) {
+		t.Errorf("reloaded = %+v, want %+v", reloaded, data)
+	}
+}
+
+func TestFlatSpell_Unordered(t *testing.T) {
+	// JSONから読み込んだ場合など，YAML上の順序がない場合は言語コード順
+	item := QuizItem{Spell: "Munich", Spells: map[string]string{"en": "Munich", "de": "München", "fr": ""}}
+	if got := item.FlatSpell(); got != "München / Munich" {
+		t.Errorf("FlatSpell() = %q", got)
+	}
+}
+
+func TestWriteCSV_Spells(t *testing.T) {
+	data := []QuizItem{{Question: "q1", Answer: "a1", Spell: "Munich", Spells: map[string]string{"en": "Munich", "de": "München"}}}
+	var buf bytes.Buffer
+	opts := ConvertOptions{CSV: CSVOptions{Columns: []string{"question", "spell"}, NoHeader: true}}
+	if err := WriteCSV(&buf, data, opts); err != nil {
+		t.Fatalf("WriteCSV() error = %v", err)
+	}
+	if got := buf.String(); got != "q1,München / Munich\n" {
+		t.Errorf("WriteCSV() = %q", got)
+	}
+}
+
+func TestValidateItems_Spells(t *testing.T) {
+	data := []QuizItem{{Question: "q1", Answer: "a1", Spell: "Munich", Spells: map[string]string{"en": "Munich", "de": " "}}}
+	result := ValidateItems(data)
+	if result.IsValid || len(result.ValidationErrors) != 1 || result.ValidationErrors[0].Field != "spell.de" {
+		t.Errorf("ValidateItems() = %+v, want one error for spell.de", result.ValidationErrors)
+	}
+}
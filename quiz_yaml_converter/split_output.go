@@ -0,0 +1,185 @@
+package quiz_yaml_converter
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// GroupBy は出力を分割する単位．
+type GroupBy string
+
+// 出力を分割する単位
+const (
+	GroupByTag   GroupBy = "tag"   // タグごと（複数のタグを持つ問題はそれぞれのファイルに含める）
+	GroupByGenre GroupBy = "genre" // ジャンルごと
+)
+
+// 分割した出力の一覧を書き出すファイルの名前
+const SplitIndexFile = "index.csv"
+
+// ungroupedName はタグやジャンルが設定されていない問題をまとめるファイルの名前（拡張子を除く）．
+const ungroupedName = "_other"
+
+// ParseGroupBy は出力を分割する単位の指定を解析する．
+func ParseGroupBy(name string) (GroupBy, error) {
+	switch by := GroupBy(strings.ToLower(strings.TrimSpace(name))); by {
+	case GroupByTag, GroupByGenre:
+		return by, nil
+	default:
+		return "", fmt.Errorf("unknown split unit: %q (available: tag, genre)", name)
+	}
+}
+
+// OutputGroup は分割した出力の1ファイル分の問題．
+type OutputGroup struct {
+	Name  string     // タグまたはジャンルの名前（未設定の問題をまとめたものは空）
+	File  string     // 出力ファイルの名前
+	Items []QuizItem // このファイルに含まれる問題
+}
+
+// GroupItems は問題をタグまたはジャンルごとにまとめる．
+// グループは最初に現れた順に並べ，タグやジャンルが設定されていない問題は最後のグループにまとめる．
+// ファイル名はグループ名のファイル名に使えない文字を_に置き換えたものにextを付けたものとする．
+func GroupItems(data []QuizItem, by GroupBy, ext string) ([]OutputGroup, error) {
+	if _, err := ParseGroupBy(string(by)); err != nil {
+		return nil, err
+	}
+	var groups []OutputGroup
+	index := map[string]int{}
+	var ungrouped []QuizItem
+	add := func(name string, item QuizItem) {
+		i, ok := index[name]
+		if !ok {
+			i = len(groups)
+			index[name] = i
+			groups = append(groups, OutputGroup{Name: name})
+		}
+		groups[i].Items = append(groups[i].Items, item)
+	}
+	for _, item := range data {
+		var names []string
+		switch by {
+		case GroupByTag:
+			for _, tag := range item.Tags {
+				if tag = strings.TrimSpace(tag); tag != "" && !slices.Contains(names, tag) {
+					names = append(names, tag)
+				}
+			}
+		case GroupByGenre:
+			if genre := strings.TrimSpace(item.Genre); genre != "" {
+				names = append(names, genre)
+			}
+		}
+		if len(names) == 0 {
+			ungrouped = append(ungrouped, item)
+		}
+		for _, name := range names {
+			add(name, item)
+		}
+	}
+	if len(ungrouped) > 0 {
+		groups = append(groups, OutputGroup{Items: ungrouped})
+	}
+
+	// ファイル名が重複しないよう，置き換え後に同じ名前になる場合は番号を付ける
+	used := map[string]bool{}
+	if indexExt := filepath.Ext(SplitIndexFile); strings.EqualFold(ext, indexExt) {
+		used[strings.TrimSuffix(SplitIndexFile, indexExt)] = true
+	}
+	for i := range groups {
+		base := ungroupedName
+		if groups[i].Name != "" {
+			base = groupFileName(groups[i].Name)
+		}
+		name := base
+		for n := 2; used[strings.ToLower(name)]; n++ {
+			name = base + "-" + strconv.Itoa(n)
+		}
+		used[strings.ToLower(name)] = true
+		groups[i].File = name + ext
+	}
+	return groups, nil
+}
+
+// groupFileName はグループ名からファイル名に使えない文字を_に置き換える．
+func groupFileName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`/\:*?"<>|`, r) || unicode.IsControl(r) || unicode.IsSpace(r) {
+			return '_'
+		}
+		return r
+	}, name)
+	name = strings.Trim(name, ".")
+	if name == "" || name == ungroupedName {
+		return "_" + name
+	}
+	return name
+}
+
+// ConvertGrouped は問題データをタグまたはジャンルごとに分割し，outputDirに1ファイルずつ変換して書き出す．
+// 各ファイルの名前はグループ名にextを付けたもので，変換方法はConvertItemsと同じく
+// templateFilePathとopts.Formatで決まる．opts.Pipelineは分割の前に1度だけ適用する．
+// あわせて，各ファイルのグループ名・ファイル名・問題数の一覧をSplitIndexFileに書き出す．
+func ConvertGrouped(data []QuizItem, outputDir, ext, templateFilePath string, by GroupBy, opts ConvertOptions) ([]OutputGroup, error) {
+	data, err := opts.Pipeline.Apply(data)
+	if err != nil {
+		return nil, err
+	}
+	groups, err := GroupItems(data, by, ext)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	groupOpts := opts
+	groupOpts.Pipeline = nil
+	// テンプレートはグループごとに解析し直さないよう，1度だけ解析する
+	var tmpl *CompiledTemplate
+	if templateFilePath != "" {
+		if tmpl, err = CompileTemplate(templateFilePath, groupOpts); err != nil {
+			return nil, err
+		}
+	}
+	for _, group := range groups {
+		path := filepath.Join(outputDir, group.File)
+		if tmpl != nil {
+			err = tmpl.WriteFile(group.Items, path)
+		} else {
+			err = ConvertItems(group.Items, path, "", groupOpts)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", group.File, err)
+		}
+	}
+
+	err = writeFileAtomic(filepath.Join(outputDir, SplitIndexFile), opts.NoClobber, func(w io.Writer) error {
+		return writeSplitIndex(w, groups, by)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return groups, nil
+}
+
+// writeSplitIndex は分割した出力の一覧をCSVとして書き出す．
+func writeSplitIndex(w io.Writer, groups []OutputGroup, by GroupBy) error {
+	writer := csv.NewWriter(w)
+	writer.Write([]string{string(by), "file", "items"})
+	for _, group := range groups {
+		writer.Write([]string{group.Name, group.File, strconv.Itoa(len(group.Items))})
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return fmt.Errorf("failed to write split index: %w", err)
+	}
+	return nil
+}
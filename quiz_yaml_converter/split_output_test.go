@@ -0,0 +1,121 @@
+package quiz_yaml_converter
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestGroupItems(t *testing.T) {
+	data := []QuizItem{
+		{Question: "q1", Tags: []string{"science", "history"}, Genre: "理科"},
+		{Question: "q2", Tags: []string{"history", " history "}},
+		{Question: "q3"},
+		{Question: "q4", Tags: []string{"a/b"}, Genre: "理科"},
+		{Question: "q5", Tags: []string{"a:b", "index"}},
+	}
+	summary := func(groups []OutputGroup) []string {
+		var lines []string
+		for _, g := range groups {
+			var questions []string
+			for _, item := range g.Items {
+				questions = append(questions, item.Question)
+			}
+			lines = append(lines, g.Name+"="+g.File+":"+strings.Join(questions, ","))
+		}
+		return lines
+	}
+
+	tests := []struct {
+		name     string
+		by       GroupBy
+		ext      string
+		expected []string
+	}{
+		{
+			name: "tag",
+			by:   GroupByTag,
+			ext:  ".csv",
+			expected: []string{
+				"science=science.csv:q1",
+				"history=history.csv:q1,q2",
+				"a/b=a_b.csv:q4",
+				"a:b=a_b-2.csv:q5",
+				"index=index-2.csv:q5",
+				"=_other.csv:q3",
+			},
+		},
+		{
+			name:     "tag with another extension",
+			by:       GroupByTag,
+			ext:      ".md",
+			expected: []string{"science=science.md:q1", "history=history.md:q1,q2", "a/b=a_b.md:q4", "a:b=a_b-2.md:q5", "index=index.md:q5", "=_other.md:q3"},
+		},
+		{
+			name:     "genre",
+			by:       GroupByGenre,
+			ext:      ".csv",
+			expected: []string{"理科=理科.csv:q1,q4", "=_other.csv:q2,q3,q5"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			groups, err := GroupItems(data, tt.by, tt.ext)
+			if err != nil {
+				t.Fatalf("GroupItems() error = %v", err)
+			}
+			if got := summary(groups); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("GroupItems() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+
+	if _, err := GroupItems(data, "author", ".csv"); err == nil {
+		t.Error("GroupItems() with unknown unit expected error")
+	}
+}
+
+func TestParseGroupBy(t *testing.T) {
+	for name, expected := range map[string]GroupBy{"tag": GroupByTag, " Genre ": GroupByGenre} {
+		if got, err := ParseGroupBy(name); err != nil || got != expected {
+			t.Errorf("ParseGroupBy(%q) = %q, %v, want %q", name, got, err, expected)
+		}
+	}
+	if _, err := ParseGroupBy("tags"); err == nil {
+		t.Error("ParseGroupBy(\"tags\") expected error")
+	}
+}
+
+func TestConvertGrouped(t *testing.T) {
+	outputDir := filepath.Join(t.TempDir(), "out")
+	data := []QuizItem{
+		{Question: "q1", Answer: "a1", Tags: []string{"science"}},
+		{Question: "q2", Answer: "a2", Tags: []string{"science", "history"}},
+		{Question: "q3", Answer: "a3", Tags: []string{"history"}},
+	}
+	opts := ConvertOptions{Pipeline: Pipeline{Filter(func(item QuizItem) bool { return item.Question != "q3" })}}
+	groups, err := ConvertGrouped(data, outputDir, ".csv", "", GroupByTag, opts)
+	if err != nil {
+		t.Fatalf("ConvertGrouped() error = %v", err)
+	}
+	if len(groups) != 2 {
+		t.Fatalf("ConvertGrouped() returned %d groups, want 2", len(groups))
+	}
+
+	expected := map[string]string{
+		"science.csv": "question,answer,spell,criteria\nq1,a1,,\nq2,a2,,\n",
+		"history.csv": "question,answer,spell,criteria\nq2,a2,,\n",
+		"index.csv":   "tag,file,items\nscience,science.csv,2\nhistory,history.csv,1\n",
+	}
+	for name, want := range expected {
+		content, err := os.ReadFile(filepath.Join(outputDir, name))
+		if err != nil {
+			t.Fatalf("Failed to read %s: %v", name, err)
+		}
+		if string(content) != want {
+			t.Errorf("%s = %q, want %q", name, content, want)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+// 問題データを1問ずつ読み込みながら変換する処理です．
+// 大きな問題集でも，すべての問題を[]QuizItemに読み込まずにCSVへ変換できるようにします．
+package quiz_yaml_converter
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DecodeYAMLItems はrからYAMLを読み込み，問題を1問ずつデコードしてfnに渡す．
+// ParseYAMLDataと同じ形式（問題のリスト，ジャンル名をキーとしたマッピング，---で区切った複数のドキュメント）を
+// 受け付けるが，問題のDocumentは設定しない．YAMLの構文解析はドキュメント単位で行うため，
+// 一度にメモリに保持するのは1つのドキュメントの構文木と1問分の問題データとなる．
+// fnがエラーを返した場合は読み込みを中止し，そのエラーをそのまま返す．
+func DecodeYAMLItems(r io.Reader, fn func(item QuizItem) error) error {
+	br := bufio.NewReader(r)
+	if prefix, err := br.Peek(len(utf8BOM)); err == nil && string(prefix) == utf8BOM {
+		br.Discard(len(utf8BOM))
+	}
+	decoder := yaml.NewDecoder(br)
+	var meta FileMetadata
+	for {
+		var node yaml.Node
+		if err := decoder.Decode(&node); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return fmt.Errorf("%w: %w", ErrInvalidYAML, err)
+		}
+		// fnのエラーはYAMLのエラーと区別してそのまま返す
+		var fnErr error
+		err := eachQuizItem(&node, &meta, func(item QuizItem) error {
+			fnErr = fn(item)
+			return fnErr
+		})
+		if fnErr != nil {
+			return fnErr
+		}
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrInvalidYAML, err)
+		}
+	}
+}
+
+// streamYAMLFilesToCSV は複数のYAMLファイルを順に読み込みながら，問題を1問ずつCSVとしてwに書き出す．
+// エラーメッセージはLoadYAMLFilesと同じく，複数のファイルの場合はどのファイルで発生したかを含む．
+func streamYAMLFilesToCSV(w io.Writer, yamlFilePaths []string, opts ConvertOptions) error {
+	cw, err := newCSVItemWriter(w, opts)
+	if err != nil {
+		return err
+	}
+	for _, path := range yamlFilePaths {
+		if err := streamYAMLFile(path, cw.Write); err != nil {
+			if len(yamlFilePaths) > 1 {
+				return fmt.Errorf("%s: %w", path, err)
+			}
+			return err
+		}
+	}
+	return cw.Close()
+}
+
+// streamYAMLFile はYAMLファイルを開き，DecodeYAMLItemsで問題を1問ずつfnに渡す．
+func streamYAMLFile(yamlFilePath string, fn func(item QuizItem) error) error {
+	f, err := os.Open(yamlFilePath)
+	if err != nil {
+		return fmt.Errorf("failed to open YAML file: %w", err)
+	}
+	defer f.Close()
+	return DecodeYAMLItems(f, fn)
+}
+
+// canStreamCSV は変換をstreamYAMLFilesToCSVで行えるかを返す．
+// 組み込みのCSV形式で出力し，問題データ全体を必要とするPipelineが指定されていない場合に限る．
+func canStreamCSV(outputFilePath, templateFilePath string, opts ConvertOptions) bool {
+	if templateFilePath != "" || len(opts.Pipeline) > 0 {
+		return false
+	}
+	if opts.Format != "" {
+		return strings.EqualFold(opts.Format, "csv")
+	}
+	return DetectOutputFormat(outputFilePath, "") == FormatCSV
+}
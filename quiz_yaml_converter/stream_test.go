@@ -0,0 +1,158 @@
+package quiz_yaml_converter
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDecodeYAMLItems(t *testing.T) {
+	tests := []struct {
+		name string
+		yaml string
+	}{
+		{"list", "- question: q1\n  answer: a1\n- question: q2\n  answer: a2\n  genre: 科学\n"},
+		{"genres", "歴史:\n  - question: q1\n    answer: a1\n地理:\n  - question: q2\n    answer: a2\n    genre: 日本地理\n空:\n"},
+		{"documents", "- question: q1\n  answer: a1\n---\n---\n- question: q2\n  answer: a2\n"},
+		{"bom", utf8BOM + "- question: q1\n  answer: a1\n"},
+		{"alias", "- &item\n  question: q1\n  answer: a1\n- *item\n"},
+		{"empty", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expected, err := ParseYAMLData([]byte(tt.yaml))
+			if err != nil {
+				t.Fatalf("ParseYAMLData() error = %v", err)
+			}
+			for i := range expected {
+				expected[i].Document = 0
+			}
+
+			var result []QuizItem
+			err = DecodeYAMLItems(strings.NewReader(tt.yaml), func(item QuizItem) error {
+				result = append(result, item)
+				return nil
+			})
+			if err != nil {
+				t.Fatalf("DecodeYAMLItems() error = %v", err)
+			}
+			if !reflect.DeepEqual(result, expected) {
+				t.Errorf("DecodeYAMLItems() = %+v, want %+v", result, expected)
+			}
+		})
+	}
+}
+
+func TestDecodeYAMLItems_Errors(t *testing.T) {
+	if err := DecodeYAMLItems(strings.NewReader("- question: [\n"), func(QuizItem) error { return nil }); !errors.Is(err, ErrInvalidYAML) {
+		t.Errorf("DecodeYAMLItems() with a syntax error = %v, want ErrInvalidYAML", err)
+	}
+
+	stop := errors.New("stop")
+	calls := 0
+	err := DecodeYAMLItems(strings.NewReader("- question: q1\n  answer: a1\n- question: q2\n  answer: a2\n"), func(QuizItem) error {
+		calls++
+		return stop
+	})
+	if err != stop || errors.Is(err, ErrInvalidYAML) {
+		t.Errorf("DecodeYAMLItems() error = %v, want the error returned by fn", err)
+	}
+	if calls != 1 {
+		t.Errorf("fn was called %d times, want 1", calls)
+	}
+}
+
+func TestConvertFilesWithOptions_StreamCSV(t *testing.T) {
+	tempDir := t.TempDir()
+	first := filepath.Join(tempDir, "first.yaml")
+	second := filepath.Join(tempDir, "second.yaml")
+	if err := os.WriteFile(first, []byte("- question: q1\n  answer: a1\n  spell: s1\n"), 0644); err != nil {
+		t.Fatalf("Failed to create YAML file: %v", err)
+	}
+	if err := os.WriteFile(second, []byte("歴史:\n  - question: q2\n    answer: a2\n"), 0644); err != nil {
+		t.Fatalf("Failed to create YAML file: %v", err)
+	}
+	opts := ConvertOptions{Format: "csv", AssignIDs: true}
+	opts.CSV.Encoding = EncodingShiftJIS
+
+	data, err := LoadYAMLFiles([]string{first, second})
+	if err != nil {
+		t.Fatalf("LoadYAMLFiles() error = %v", err)
+	}
+	var expected bytes.Buffer
+	if err := WriteCSV(&expected, data, opts); err != nil {
+		t.Fatalf("WriteCSV() error = %v", err)
+	}
+
+	output := filepath.Join(tempDir, "quiz.csv")
+	if err := ConvertFilesWithOptions([]string{first, second}, output, "", opts); err != nil {
+		t.Fatalf("ConvertFilesWithOptions() error = %v", err)
+	}
+	result, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatalf("Failed to read CSV file: %v", err)
+	}
+	if !bytes.Equal(result, expected.Bytes()) {
+		t.Errorf("ConvertFilesWithOptions() wrote %q, want %q", result, expected.Bytes())
+	}
+
+	missing := filepath.Join(tempDir, "missing.yaml")
+	err = ConvertFilesWithOptions([]string{first, missing}, filepath.Join(tempDir, "error.csv"), "", opts)
+	if err == nil || !strings.Contains(err.Error(), missing) {
+		t.Errorf("ConvertFilesWithOptions() with a missing file error = %v, want error mentioning %s", err, missing)
+	}
+	if _, err := os.Stat(filepath.Join(tempDir, "error.csv")); err == nil {
+		t.Errorf("ConvertFilesWithOptions() left an output file after an error")
+	}
+}
+
+// largeQuizYAML はベンチマーク用に，n問を1つのドキュメントにつきperDocument問ずつ書いたYAMLを返す．
+func largeQuizYAML(n, perDocument int) []byte {
+	var b bytes.Buffer
+	for i := 0; i < n; i++ {
+		if i > 0 && i%perDocument == 0 {
+			b.WriteString("---\n")
+		}
+		fmt.Fprintf(&b, "- question: ベンチマーク問題%dの問題文はここに続きます。これは何でしょう？\n", i)
+		fmt.Fprintf(&b, "  answer: 答え%d\n  spell: answer%d\n  tags: [tag%d]\n", i, i, i%10)
+		b.WriteString("  criteria:\n    ok:\n      - 別解\n    ng:\n      - 誤答\n")
+	}
+	return b.Bytes()
+}
+
+// BenchmarkCSVLarge は大きな問題集のCSVへの変換で，1問ずつ書き出す場合と
+// 問題データ全体を読み込んでから書き出す場合の割り当てを比較する．
+// go test -bench CSVLarge -benchmem ./quiz_yaml_converter で実行する．
+func BenchmarkCSVLarge(b *testing.B) {
+	yamlFile := filepath.Join(b.TempDir(), "large.yaml")
+	if err := os.WriteFile(yamlFile, largeQuizYAML(20000, 500), 0644); err != nil {
+		b.Fatalf("Failed to create benchmark YAML file: %v", err)
+	}
+
+	b.Run("stream", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if err := streamYAMLFilesToCSV(io.Discard, []string{yamlFile}, ConvertOptions{}); err != nil {
+				b.Fatalf("streamYAMLFilesToCSV() error = %v", err)
+			}
+		}
+	})
+	b.Run("load", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			data, err := LoadYAMLData(yamlFile)
+			if err != nil {
+				b.Fatalf("LoadYAMLData() error = %v", err)
+			}
+			if err := WriteCSV(io.Discard, data, ConvertOptions{}); err != nil {
+				b.Fatalf("WriteCSV() error = %v", err)
+			}
+		}
+	})
+}
@@ -0,0 +1,146 @@
+// 変換結果の集計（読み込んだ問題の数，出力ファイルのサイズ，所要時間など）を扱う処理です．
+// ビルドのパイプラインで問題集の統計を記録し，変換ごとの推移を追えるようにJSONで出力します．
+package quiz_yaml_converter
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// ConversionSummary は1回の変換の集計．
+// ItemsReadからItemsSkippedとItemsWrittenを引いた残りは，絞り込み（-filterなど）や同じidの問題の統合で除かれた問題の数となる．
+type ConversionSummary struct {
+	Inputs       []string     `json:"inputs"`
+	ItemsRead    int          `json:"items_read"`    // 入力ファイルに含まれていた問題の数
+	ItemsWritten int          `json:"items_written"` // 出力した問題の数
+	ItemsSkipped int          `json:"items_skipped"` // 不備があるため取り除いた問題の数
+	Warnings     []string     `json:"warnings"`      // 変換中に表示した警告
+	Outputs      []OutputFile `json:"outputs"`
+	OutputBytes  int64        `json:"output_bytes"` // 出力ファイルのサイズの合計
+	Cached       bool         `json:"cached,omitempty"`
+	StartedAt    time.Time    `json:"started_at"`
+	DurationMS   int64        `json:"duration_ms"`
+
+	mu sync.Mutex
+}
+
+// OutputFile はConversionSummaryの出力ファイル1つ．
+type OutputFile struct {
+	Path  string `json:"path"`
+	Bytes int64  `json:"bytes"`
+}
+
+// NewConversionSummary は入力ファイルと開始時刻を設定した集計を作成する．
+func NewConversionSummary(inputs []string, startedAt time.Time) *ConversionSummary {
+	return &ConversionSummary{
+		Inputs:    append([]string{}, inputs...),
+		Warnings:  []string{},
+		Outputs:   []OutputFile{},
+		StartedAt: startedAt,
+	}
+}
+
+// TrackItems は読み込んだ問題と出力した問題を数えるフックを加えたConvertOptionsを返す．
+// 読み込んだ問題はPipelineの最初，出力した問題はPipelineの最後（WithPreItemHook，WithItemHook参照）で数える．
+func (s *ConversionSummary) TrackItems(opts ConvertOptions) ConvertOptions {
+	return opts.WithPreItemHook(func(int, *QuizItem) error {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.ItemsRead++
+		return nil
+	}).WithItemHook(func(int, *QuizItem) error {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.ItemsWritten++
+		return nil
+	})
+}
+
+// AddSkipped は不備があるため読み込み時に取り除いた問題をn問加える（ItemsReadにも加える）．
+func (s *ConversionSummary) AddSkipped(n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ItemsRead += n
+	s.ItemsSkipped += n
+}
+
+// AddMerged は同じidの問題の統合で読み込み時に除いた問題をn問加える（ItemsReadにだけ加える）．
+func (s *ConversionSummary) AddMerged(n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ItemsRead += n
+}
+
+// AddWarning は変換中に表示した警告を加える．
+func (s *ConversionSummary) AddWarning(message string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Warnings = append(s.Warnings, message)
+}
+
+// Finish は出力ファイルのサイズと所要時間を記録する．
+// 出力先がディレクトリ（-per-page，-split-by）の場合は，その中のファイルをすべて記録する．
+func (s *ConversionSummary) Finish(outputs []string, finishedAt time.Time) error {
+	files, err := OutputSizes(outputs)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Outputs = files
+	s.OutputBytes = 0
+	for _, f := range files {
+		s.OutputBytes += f.Bytes
+	}
+	s.DurationMS = finishedAt.Sub(s.StartedAt).Milliseconds()
+	return nil
+}
+
+// OutputSizes は出力ファイルのサイズを返す．ディレクトリの場合は，その中のファイルをパスの順に返す．
+func OutputSizes(paths []string) ([]OutputFile, error) {
+	files := []OutputFile{}
+	for _, path := range paths {
+		err := filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if !d.Type().IsRegular() {
+				return nil
+			}
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			files = append(files, OutputFile{Path: p, Bytes: info.Size()})
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to stat output: %w", err)
+		}
+	}
+	return files, nil
+}
+
+// WriteJSON は集計をJSONでwに書き出す．
+func (s *ConversionSummary) WriteJSON(w io.Writer) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal summary: %w", err)
+	}
+	if _, err := w.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write summary: %w", err)
+	}
+	return nil
+}
+
+// WriteJSONFile は集計をJSONファイルに書き出す．
+func (s *ConversionSummary) WriteJSONFile(path string) error {
+	return writeFileAtomic(path, false, s.WriteJSON)
+}
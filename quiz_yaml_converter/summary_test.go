@@ -0,0 +1,122 @@
+package quiz_yaml_converter
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestConversionSummary(t *testing.T) {
+	dir := t.TempDir()
+	output := filepath.Join(dir, "quiz.csv")
+	started := time.Date(2024, 4, 1, 10, 0, 0, 0, time.UTC)
+	summary := NewConversionSummary([]string{"quiz.yaml"}, started)
+
+	items := []QuizItem{
+		{Question: "日本の首都は？", Answer: "東京", Genre: "地理"},
+		{Question: "江戸幕府を開いたのは？", Answer: "徳川家康", Genre: "歴史"},
+		{Question: "フランスの首都は？", Answer: "パリ", Genre: "地理"},
+	}
+	opts := ConvertOptions{Pipeline: Pipeline{Filter(func(item QuizItem) bool { return item.Genre == "地理" })}}
+	if err := ConvertItems(items, output, "", summary.TrackItems(opts)); err != nil {
+		t.Fatalf("ConvertItems() error = %v", err)
+	}
+	if len(opts.Pipeline) != 1 {
+		t.Errorf("TrackItems() modified the original Pipeline: %d stages", len(opts.Pipeline))
+	}
+	summary.AddSkipped(2)
+	summary.AddMerged(1)
+	summary.AddWarning("問題 2: 答え (answer) が空です")
+	if err := summary.Finish([]string{output}, started.Add(1500*time.Millisecond)); err != nil {
+		t.Fatalf("Finish() error = %v", err)
+	}
+	info, err := os.Stat(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := summary.WriteJSON(&buf); err != nil {
+		t.Fatalf("WriteJSON() error = %v", err)
+	}
+	var got map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("WriteJSON() wrote invalid JSON: %v\n%s", err, buf.String())
+	}
+	want := map[string]any{
+		"inputs":        []any{"quiz.yaml"},
+		"items_read":    float64(6),
+		"items_written": float64(2),
+		"items_skipped": float64(2),
+		"warnings":      []any{"問題 2: 答え (answer) が空です"},
+		"outputs":       []any{map[string]any{"path": output, "bytes": float64(info.Size())}},
+		"output_bytes":  float64(info.Size()),
+		"started_at":    "2024-04-01T10:00:00Z",
+		"duration_ms":   float64(1500),
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("WriteJSON() =\n%v\nwant\n%v", got, want)
+	}
+}
+
+func TestOutputSizes(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"quiz.csv":          "question,answer\n",
+		"site/index.html":   "<html></html>",
+		"site/page-1.html":  "<p>1</p>",
+		"site/media/a.png":  "png",
+		"unrelated/skip.md": "# skip",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, err := OutputSizes([]string{filepath.Join(dir, "quiz.csv"), filepath.Join(dir, "site")})
+	if err != nil {
+		t.Fatalf("OutputSizes() error = %v", err)
+	}
+	want := []OutputFile{
+		{Path: filepath.Join(dir, "quiz.csv"), Bytes: 16},
+		{Path: filepath.Join(dir, "site", "index.html"), Bytes: 13},
+		{Path: filepath.Join(dir, "site", "media", "a.png"), Bytes: 3},
+		{Path: filepath.Join(dir, "site", "page-1.html"), Bytes: 8},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("OutputSizes() = %+v, want %+v", got, want)
+	}
+
+	if _, err := OutputSizes([]string{filepath.Join(dir, "missing.csv")}); err == nil {
+		t.Error("OutputSizes() should fail for a missing output")
+	}
+}
+
+func TestConversionSummary_WriteJSONFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "summary.json")
+	summary := NewConversionSummary(nil, time.Now())
+	summary.Cached = true
+	if err := summary.WriteJSONFile(path); err != nil {
+		t.Fatalf("WriteJSONFile() error = %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got ConversionSummary
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("WriteJSONFile() wrote invalid JSON: %v", err)
+	}
+	if !got.Cached || got.Inputs == nil || got.Warnings == nil || got.Outputs == nil {
+		t.Errorf("WriteJSONFile() wrote %s", data)
+	}
+}
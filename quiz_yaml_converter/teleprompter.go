@@ -0,0 +1,89 @@
+// 問題の読み手向けの出力形式（teleprompter，srt）です．
+package quiz_yaml_converter
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/m-uesaka/quiz-yaml-go/templates"
+)
+
+// SRT形式の出力で，問題文に続けて答えを表示する時間
+const srtAnswerDuration = 5 * time.Second
+
+// teleprompterItem はteleprompter形式のテンプレートに渡す1問分のデータ．
+type teleprompterItem struct {
+	Number   string   // 問題番号
+	Position int      // 出力中の位置（1始まり）
+	Segments []string // 問題文の区切り（QuestionSegments参照）
+	Reading  string   // 問題文の読み
+	Answer   string   // 答え
+	Criteria string   // 正誤判定
+	Duration string   // 読み上げ時間の見積もり（FormatDuration参照）
+}
+
+var teleprompterTemplate = template.Must(template.New("teleprompter").Parse(templates.Teleprompter))
+
+// writeTeleprompter は問題の読み手向けに，1問ずつ大きな文字で表示するHTMLを書き出す．
+// 答えはキーを押すまで表示しない．
+func writeTeleprompter(w io.Writer, items []QuizItem, opts ConvertOptions) error {
+	numbers := QuestionNumbers(items, opts.Numbering)
+	data := struct{ Items []teleprompterItem }{}
+	for i, item := range items {
+		data.Items = append(data.Items, teleprompterItem{
+			Number:   numbers[i],
+			Position: i + 1,
+			Segments: QuestionSegments(item),
+			Reading:  item.Reading,
+			Answer:   item.Answer,
+			Criteria: formatItemCriteria(item, opts),
+			Duration: FormatDuration(ReadingTime(item, opts.ReadingPace)),
+		})
+	}
+	if err := teleprompterTemplate.Execute(w, data); err != nil {
+		return fmt.Errorf("failed to write teleprompter HTML: %w", err)
+	}
+	return nil
+}
+
+// writeSRT は問題文と答えを，読み上げ時間の見積もりに合わせたSRT形式の字幕として書き出す．
+// 各問題は問題文（読み上げ時間の見積もりの間）と答え（srtAnswerDurationの間）の2つの字幕になる．
+func writeSRT(w io.Writer, items []QuizItem, opts ConvertOptions) error {
+	numbers := QuestionNumbers(items, opts.Numbering)
+	var b strings.Builder
+	var start time.Duration
+	cue := 0
+	add := func(d time.Duration, text string) {
+		cue++
+		fmt.Fprintf(&b, "%d\n%s --> %s\n%s\n\n", cue, srtTimestamp(start), srtTimestamp(start+d), srtText(text))
+		start += d
+	}
+	for i, item := range items {
+		add(ReadingTime(item, opts.ReadingPace), "Q"+numbers[i]+". "+PlainQuestion(item))
+		add(srtAnswerDuration, "A. "+item.Answer)
+	}
+	if _, err := io.WriteString(w, b.String()); err != nil {
+		return fmt.Errorf("failed to write SRT: %w", err)
+	}
+	return nil
+}
+
+// srtTimestamp はSRT形式の時刻（00:01:02,500）を返す．
+func srtTimestamp(d time.Duration) string {
+	ms := d.Milliseconds()
+	return fmt.Sprintf("%02d:%02d:%02d,%03d", ms/3600000, ms/60000%60, ms/1000%60, ms%1000)
+}
+
+// srtText は字幕の区切りとなる空行ができないよう，空の行を取り除く．
+func srtText(s string) string {
+	var lines []string
+	for _, line := range strings.Split(strings.ReplaceAll(s, "\r\n", "\n"), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,64 @@
+package quiz_yaml_converter
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriteSRT(t *testing.T) {
+	items := []QuizItem{
+		{Question: strings.Repeat("あ", 20), Answer: "答え1"},
+		{Question: "い\n\nう", Answer: "答え2"},
+	}
+	var buf bytes.Buffer
+	if err := writeSRT(&buf, items, ConvertOptions{}); err != nil {
+		t.Fatalf("writeSRT() error = %v", err)
+	}
+	expected := "1\n00:00:00,000 --> 00:00:02,500\nQ1. " + strings.Repeat("あ", 20) + "\n\n" +
+		"2\n00:00:02,500 --> 00:00:07,500\nA. 答え1\n\n" +
+		"3\n00:00:07,500 --> 00:00:08,500\nQ2. い\nう\n\n" +
+		"4\n00:00:08,500 --> 00:00:13,500\nA. 答え2\n\n"
+	if buf.String() != expected {
+		t.Errorf("writeSRT() = %q, want %q", buf.String(), expected)
+	}
+}
+
+func TestSRTTimestamp(t *testing.T) {
+	if got := srtTimestamp(time.Hour + 2*time.Minute + 3*time.Second + 45*time.Millisecond); got != "01:02:03,045" {
+		t.Errorf("srtTimestamp() = %q, want %q", got, "01:02:03,045")
+	}
+}
+
+func TestWriteTeleprompter(t *testing.T) {
+	items := []QuizItem{
+		{Question: "<b>前半</b>／後半", Answer: "答え", Reading: "ぜんはんこうはん", Criteria: map[string][]string{"ng": {"誤答"}}},
+		{Question: "q2", Answer: "a2"},
+	}
+	f, err := LookupFormatter("teleprompter")
+	if err != nil {
+		t.Fatalf("LookupFormatter() error = %v", err)
+	}
+	var buf bytes.Buffer
+	if err := f.Format(&buf, items, ConvertOptions{}); err != nil {
+		t.Fatalf("Format() error = %v", err)
+	}
+	for _, s := range []string{
+		`<div class="card current">`,
+		"Q1（1 / 2）　読み上げ目安: 約1秒",
+		`&lt;b&gt;前半&lt;/b&gt;<span class="pivot">／</span>後半`,
+		`<div class="reading">ぜんはんこうはん</div>`,
+		`<div class="answer">`,
+		"A. 答え",
+		"「誤答」は誤答",
+		"Q2（2 / 2）",
+	} {
+		if !strings.Contains(buf.String(), s) {
+			t.Errorf("output does not contain %q:\n%s", s, buf.String())
+		}
+	}
+	if got := ContentType(f, ConvertOptions{}); got != "text/html; charset=utf-8" {
+		t.Errorf("ContentType() = %q", got)
+	}
+}
@@ -0,0 +1,149 @@
+// テンプレートの実行時間と出力の大きさ，使える関数を制限する処理です．
+// 無限ループや巨大な出力を含むテンプレートでも変換から戻り，ディスクを使い果たさないようにします．
+// 制限時間を超えた実行は取り消せないため，何も書き出さないループはバックグラウンドで続きます．
+package quiz_yaml_converter
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+)
+
+// limitedWriter はテンプレートの出力を書き出すWriter．
+// 出力が上限を超える書き込みや，実行を打ち切った（stop）後の書き込みはエラーとなり，テンプレートの実行が止まる．
+type limitedWriter struct {
+	mu      sync.Mutex
+	w       io.Writer
+	max     int64 // 出力の最大バイト数（0は無制限）
+	written int64
+	err     error // 設定されている場合，以降の書き込みはこのエラーを返す
+}
+
+// Write は出力の大きさを確認してからwに書き出す．
+func (lw *limitedWriter) Write(p []byte) (int, error) {
+	lw.mu.Lock()
+	defer lw.mu.Unlock()
+	if lw.err != nil {
+		return 0, lw.err
+	}
+	if lw.max > 0 && lw.written+int64(len(p)) > lw.max {
+		lw.err = fmt.Errorf("%w (%d bytes)", ErrOutputTooLarge, lw.max)
+		return 0, lw.err
+	}
+	n, err := lw.w.Write(p)
+	lw.written += int64(n)
+	return n, err
+}
+
+// stop は以降の書き込みをerrで失敗させる．stopから戻った後はwに書き込まれない．
+func (lw *limitedWriter) stop(err error) {
+	lw.mu.Lock()
+	defer lw.mu.Unlock()
+	if lw.err == nil {
+		lw.err = err
+	}
+}
+
+// executeWithLimits はopts.TemplateTimeoutとopts.MaxOutputBytesの制限のもとでテンプレートを実行する．
+// 制限時間を超えた場合は，以降のテンプレートの出力を捨ててErrTemplateTimeoutを返す．
+// 実行は取り消さずに待つのをやめるだけのため，次に書き出すまで（何も書き出さないループでは終わるまで）
+// テンプレートはバックグラウンドで実行され続ける．
+func executeWithLimits(w io.Writer, tmpl *template.Template, data any, opts ConvertOptions) error {
+	if opts.TemplateTimeout <= 0 && opts.MaxOutputBytes <= 0 {
+		return tmpl.Execute(w, data)
+	}
+	lw := &limitedWriter{w: w, max: opts.MaxOutputBytes}
+	if opts.TemplateTimeout <= 0 {
+		return tmpl.Execute(lw, data)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- tmpl.Execute(lw, data)
+	}()
+	timer := time.NewTimer(opts.TemplateTimeout)
+	defer timer.Stop()
+	select {
+	case err := <-done:
+		return err
+	case <-timer.C:
+		err := fmt.Errorf("%w (%v)", ErrTemplateTimeout, opts.TemplateTimeout)
+		lw.stop(err)
+		return err
+	}
+}
+
+// ConvertOptions.SafeTemplatesの場合に使える関数．
+// 結果が引数と変換オプションだけで決まり，実行環境（現在時刻，環境変数，ファイルなど）に依存しない関数だけを含める．
+// 関数を追加した場合は，ここに含めるかを判断する（含めない関数は使えない）．
+var safeTemplateFuncs = map[string]bool{
+	"formatCriteria": true, "formatCriteriaInOrder": true, "formatItemCriteria": true,
+	"segments": true, "plainQuestion": true,
+	"addQuotes": true, "nestQuotes": true, "quoteWith": true,
+	"runeCount": true, "moraCount": true, "maskAnswer": true, "percent": true, "toRomaji": true,
+	"ruby": true, "furigana": true, "tateChuYoko": true,
+	"join": true, "upper": true, "lower": true, "replace": true,
+	"readingTime": true, "totalReadingTime": true, "formatDuration": true, "glossary": true,
+	"slug": true, "qrURL": true, "qrSVG": true, "qrDataURI": true,
+	"add": true, "len": true,
+}
+
+// restrictTemplateFuncs はfuncsからsafeTemplateFuncsに含まれる関数だけを返す．
+func restrictTemplateFuncs(funcs template.FuncMap) template.FuncMap {
+	safe := template.FuncMap{}
+	for name, f := range funcs {
+		if safeTemplateFuncs[name] {
+			safe[name] = f
+		}
+	}
+	return safe
+}
+
+// userTemplateFuncs は利用者のテンプレートで使えるカスタム関数を返す．
+// opts.SafeTemplatesの場合はsafeTemplateFuncsの関数だけとなる．
+func userTemplateFuncs(opts ConvertOptions) template.FuncMap {
+	if opts.SafeTemplates {
+		return restrictTemplateFuncs(templateFuncs(opts))
+	}
+	return templateFuncs(opts)
+}
+
+// checkSafeTemplate はopts.SafeTemplatesの場合に，テンプレートがsafeTemplateFuncs以外の関数を
+// 使っていないかを解析して確かめる．組み込みのレイアウトと組み合わせるテンプレートのように，
+// すべての関数で解析するテンプレートの内容を事前に確認するために使う．
+func checkSafeTemplate(name, content string, opts ConvertOptions) error {
+	if !opts.SafeTemplates {
+		return nil
+	}
+	_, err := template.New(filepath.Base(name)).Delims(opts.TemplateDelims[0], opts.TemplateDelims[1]).Funcs(userTemplateFuncs(opts)).Parse(content)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrTemplateParse, err)
+	}
+	return nil
+}
+
+// ParseByteSize は"10MB"のようなバイト数の指定を解析する．
+// 単位はB，KB，MB，GB（1024倍ずつ，大文字と小文字を区別しない）で，省略した場合はバイトとなる．
+func ParseByteSize(s string) (int64, error) {
+	spec := strings.ToUpper(strings.TrimSpace(s))
+	multiplier := int64(1)
+	for _, unit := range []struct {
+		suffix string
+		size   int64
+	}{{"GB", 1 << 30}, {"MB", 1 << 20}, {"KB", 1 << 10}, {"B", 1}} {
+		if strings.HasSuffix(spec, unit.suffix) {
+			spec, multiplier = strings.TrimSpace(strings.TrimSuffix(spec, unit.suffix)), unit.size
+			break
+		}
+	}
+	n, err := strconv.ParseInt(spec, 10, 64)
+	if err != nil || n < 0 || n > (1<<63-1)/multiplier {
+		return 0, fmt.Errorf("invalid size: %q", s)
+	}
+	return n * multiplier, nil
+}
@@ -0,0 +1,176 @@
+package quiz_yaml_converter
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriteTemplate_Limits(t *testing.T) {
+	tempDir := t.TempDir()
+	data := []QuizItem{{Question: "q1", Answer: "a1"}, {Question: "q2", Answer: "a2"}}
+	tests := []struct {
+		name     string
+		template string
+		opts     ConvertOptions
+		expected error
+		output   string
+	}{
+		{"within limits", `{{range .Items}}{{.Question}}{{end}}`, ConvertOptions{TemplateTimeout: time.Minute, MaxOutputBytes: 4}, nil, "q1q2"},
+		{"output too large", `{{range .Items}}{{.Question}}{{end}}`, ConvertOptions{MaxOutputBytes: 3}, ErrOutputTooLarge, "q1"},
+		{"endless output", `{{range 1000000000000}}x{{end}}`, ConvertOptions{MaxOutputBytes: 1 << 10}, ErrOutputTooLarge, ""},
+		{"timeout", `{{range 1000000000000}}x{{end}}`, ConvertOptions{TemplateTimeout: 50 * time.Millisecond}, ErrTemplateTimeout, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			templateFile := filepath.Join(tempDir, "quiz.tmpl")
+			if err := os.WriteFile(templateFile, []byte(tt.template), 0644); err != nil {
+				t.Fatalf("Failed to create template file: %v", err)
+			}
+			var buf bytes.Buffer
+			err := WriteTemplate(&buf, data, templateFile, tt.opts)
+			if tt.expected == nil {
+				if err != nil {
+					t.Fatalf("WriteTemplate() error = %v", err)
+				}
+			} else if !errors.Is(err, tt.expected) || !errors.Is(err, ErrTemplateExecute) {
+				t.Fatalf("WriteTemplate() error = %v, want %v and ErrTemplateExecute", err, tt.expected)
+			}
+			if tt.output != "" && buf.String() != tt.output {
+				t.Errorf("WriteTemplate() wrote %q, want %q", buf.String(), tt.output)
+			}
+			if tt.opts.MaxOutputBytes > 0 && int64(buf.Len()) > tt.opts.MaxOutputBytes {
+				t.Errorf("WriteTemplate() wrote %d bytes, want at most %d", buf.Len(), tt.opts.MaxOutputBytes)
+			}
+		})
+	}
+}
+
+func TestWriteTemplate_LimitsWhileFindingItemErrors(t *testing.T) {
+	data := []QuizItem{{Question: "q1", Answer: "a1"}, {Question: "q2", Answer: "a2"}}
+	// 問題を含むデータでは失敗し，問題ごとの原因を調べるための実行し直しでは終わらないテンプレート
+	tests := []struct {
+		name     string
+		template string
+		opts     ConvertOptions
+	}{
+		{"empty data", `{{if .Items}}{{index .Items 5}}{{else}}{{range 100000000000}}x{{end}}{{end}}`, ConvertOptions{TemplateTimeout: 200 * time.Millisecond}},
+		{"single item", `{{if eq (len .Items) 2}}{{index .Items 5}}{{else}}{{range 100000000000}}x{{end}}{{end}}`, ConvertOptions{TemplateTimeout: 200 * time.Millisecond}},
+		{"output", `{{if eq (len .Items) 2}}{{index .Items 5}}{{else}}{{range 100000000000}}x{{end}}{{end}}`, ConvertOptions{MaxOutputBytes: 1 << 10}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			templateFile := filepath.Join(t.TempDir(), "quiz.tmpl")
+			if err := os.WriteFile(templateFile, []byte(tt.template), 0644); err != nil {
+				t.Fatalf("Failed to create template file: %v", err)
+			}
+			started := time.Now()
+			err := WriteTemplate(&bytes.Buffer{}, data, templateFile, tt.opts)
+			if !errors.Is(err, ErrTemplateExecute) {
+				t.Fatalf("WriteTemplate() error = %v, want ErrTemplateExecute", err)
+			}
+			if len(ItemErrors(err)) != 0 {
+				t.Errorf("WriteTemplate() error = %v, want no ItemError", err)
+			}
+			if elapsed := time.Since(started); elapsed > 5*time.Second {
+				t.Errorf("WriteTemplate() took %v, want it to stop within the time limit", elapsed)
+			}
+		})
+	}
+}
+
+func TestParseByteSize(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+		wantErr  bool
+	}{
+		{"0", 0, false},
+		{"512", 512, false},
+		{"512B", 512, false},
+		{"10KB", 10 << 10, false},
+		{"10 mb", 10 << 20, false},
+		{"2GB", 2 << 30, false},
+		{"", 0, true},
+		{"-1MB", 0, true},
+		{"1.5MB", 0, true},
+		{"10TB", 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseByteSize(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseByteSize(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if got != tt.expected {
+				t.Errorf("ParseByteSize(%q) = %d, want %d", tt.input, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestWriteTemplate_SafeTemplates(t *testing.T) {
+	data := []QuizItem{{Question: "日本の首都は？", Answer: "東京"}}
+	tests := []struct {
+		name     string
+		template string
+		opts     ConvertOptions
+		wantErr  bool
+		contains string
+	}{
+		{"safe functions", `{{range .Items}}{{upper "q"}}:{{.Answer | addQuotes}}{{end}}`, ConvertOptions{SafeTemplates: true}, false, "Q:「東京」"},
+		{"now", `{{now}}`, ConvertOptions{SafeTemplates: true}, true, ""},
+		{"now without safe templates", `{{now}}`, ConvertOptions{}, false, "年"},
+		{"now in template for built-in layout", `{{define "footer"}}{{now}}{{end}}`, ConvertOptions{SafeTemplates: true, Layout: "html"}, true, ""},
+		{"built-in layout", `{{define "title"}}安全{{end}}`, ConvertOptions{SafeTemplates: true, Layout: "html"}, false, "生成日時"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.opts.TemplateText = tt.template
+			var buf bytes.Buffer
+			err := WriteTemplate(&buf, data, "quiz.tmpl", tt.opts)
+			if tt.wantErr {
+				if !errors.Is(err, ErrTemplateParse) {
+					t.Fatalf("WriteTemplate() error = %v, want ErrTemplateParse", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("WriteTemplate() error = %v", err)
+			}
+			if !strings.Contains(buf.String(), tt.contains) {
+				t.Errorf("WriteTemplate() wrote %q, want it to contain %q", buf.String(), tt.contains)
+			}
+		})
+	}
+}
+
+func TestSafeTemplateFuncs(t *testing.T) {
+	// 実行環境に依存するため，信頼できないテンプレートでは使えない関数
+	unsafe := map[string]bool{"now": true}
+	for name := range templateFuncs(ConvertOptions{}) {
+		if safeTemplateFuncs[name] == unsafe[name] {
+			t.Errorf("template function %q must be either in safeTemplateFuncs or unsafe", name)
+		}
+	}
+	for name := range safeTemplateFuncs {
+		if _, ok := templateFuncs(ConvertOptions{})[name]; !ok {
+			t.Errorf("safeTemplateFuncs contains unknown function %q", name)
+		}
+	}
+}
+
+func TestFormatter_SafeTemplates(t *testing.T) {
+	f, err := LookupFormatter("html")
+	if err != nil {
+		t.Fatalf("LookupFormatter() error = %v", err)
+	}
+	var buf bytes.Buffer
+	if err := f.Format(&buf, []QuizItem{{Question: "q", Answer: "a"}}, ConvertOptions{SafeTemplates: true}); err != nil {
+		t.Errorf("Format() error = %v, want built-in templates to keep all functions", err)
+	}
+}
@@ -0,0 +1,88 @@
+// 検索や埋め込み（embedding）の索引を作るための，1行に1問のテキスト出力です．
+package quiz_yaml_converter
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// TextOptions はテキスト出力（-format text）のオプション．
+type TextOptions struct {
+	Fields    []string // 1行に出力する項目（CSVの列名）．空の場合はDefaultTextFields
+	Separator string   // 項目の区切り．空の場合はDefaultTextSeparator
+}
+
+// テキスト出力で既定で出力する項目
+var DefaultTextFields = []string{"question", "answer"}
+
+// テキスト出力の既定の項目の区切り
+const DefaultTextSeparator = " [SEP] "
+
+// ParseTextFields はテキスト出力の項目のカンマ区切りのリストを解析する．
+// 項目名にはCSVの列名（ParseCSVColumns参照）を使う．未知の項目名や重複した項目名が含まれている場合はエラーを返す．
+func ParseTextFields(spec string) ([]string, error) {
+	var fields []string
+	seen := map[string]bool{}
+	for _, name := range strings.Split(spec, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		if _, ok := csvColumnValues[name]; !ok {
+			return nil, fmt.Errorf("unknown text field: %q (available: %s)", name, strings.Join(availableCSVColumns, ", "))
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("duplicate text field: %q", name)
+		}
+		seen[name] = true
+		fields = append(fields, name)
+	}
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("no text fields specified")
+	}
+	return fields, nil
+}
+
+// writeText は問題データを1行に1問のテキストとして書き出す．
+// 各行はopts.Text.Fieldsの項目をopts.Text.Separatorでつないだもので，値がない項目も空文字列として位置を保つ．
+// 各項目はNormalizeTextで正規化し，問題文の区切り記号は取り除く．
+func writeText(w io.Writer, items []QuizItem, opts ConvertOptions) error {
+	fields := opts.Text.Fields
+	if len(fields) == 0 {
+		fields = DefaultTextFields
+	}
+	sep := opts.Text.Separator
+	if sep == "" {
+		sep = DefaultTextSeparator
+	}
+	for _, name := range fields {
+		if _, ok := csvColumnValues[name]; !ok {
+			return fmt.Errorf("unknown text field: %q", name)
+		}
+	}
+
+	bw := bufio.NewWriter(w)
+	values := make([]string, len(fields))
+	for _, item := range withAssignedIDs(items, opts) {
+		item.Question = PlainQuestion(item)
+		for i, name := range fields {
+			values[i] = NormalizeText(csvColumnValues[name](item, opts))
+		}
+		bw.WriteString(strings.Join(values, sep))
+		bw.WriteByte('\n')
+	}
+	if err := bw.Flush(); err != nil {
+		return fmt.Errorf("failed to write text: %w", err)
+	}
+	return nil
+}
+
+// NormalizeText は検索や埋め込みに使うためにテキストを正規化する．
+// NFKCで全角英数字・記号を半角にし，改行を含む連続する空白を1つの半角スペースにまとめ，前後の空白を取り除く．
+func NormalizeText(s string) string {
+	return strings.Join(strings.Fields(norm.NFKC.String(s)), " ")
+}
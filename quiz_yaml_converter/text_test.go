@@ -0,0 +1,88 @@
+package quiz_yaml_converter
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestWriteText(t *testing.T) {
+	items := []QuizItem{
+		{ID: "q1", Question: "ＤＮＡの二重らせん構造を／\n発見したのは誰？", Answer: "ワトソン　と　クリック", Genre: "科学"},
+		{Question: "日本の首都は？", Answer: "東京"},
+	}
+	tests := []struct {
+		name     string
+		opts     TextOptions
+		expected string
+	}{
+		{"default", TextOptions{}, "DNAの二重らせん構造を 発見したのは誰? [SEP] ワトソン と クリック\n日本の首都は? [SEP] 東京\n"},
+		{"fields and separator", TextOptions{Fields: []string{"id", "genre", "answer"}, Separator: "\t"}, "q1\t科学\tワトソン と クリック\n\t\t東京\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := LookupFormatter("text")
+			if err != nil {
+				t.Fatalf("LookupFormatter() error = %v", err)
+			}
+			var buf bytes.Buffer
+			if err := f.Format(&buf, items, ConvertOptions{Text: tt.opts}); err != nil {
+				t.Fatalf("Format() error = %v", err)
+			}
+			if buf.String() != tt.expected {
+				t.Errorf("Format() = %q, want %q", buf.String(), tt.expected)
+			}
+		})
+	}
+
+	var buf bytes.Buffer
+	if err := writeText(&buf, items, ConvertOptions{Text: TextOptions{Fields: []string{"unknown"}}}); err == nil {
+		t.Errorf("writeText() with an unknown field error = nil, want error")
+	}
+}
+
+func TestParseTextFields(t *testing.T) {
+	tests := []struct {
+		spec     string
+		expected []string
+		wantErr  bool
+	}{
+		{"question,answer", []string{"question", "answer"}, false},
+		{" Genre , question ", []string{"genre", "question"}, false},
+		{"question,unknown", nil, true},
+		{"answer,answer", nil, true},
+		{" , ", nil, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseTextFields(tt.spec)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseTextFields(%q) error = %v, wantErr %v", tt.spec, err, tt.wantErr)
+			continue
+		}
+		if len(got) != len(tt.expected) {
+			t.Errorf("ParseTextFields(%q) = %v, want %v", tt.spec, got, tt.expected)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.expected[i] {
+				t.Errorf("ParseTextFields(%q) = %v, want %v", tt.spec, got, tt.expected)
+			}
+		}
+	}
+}
+
+func TestNormalizeText(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"  ＡＢＣ　１２３  ", "ABC 123"},
+		{"一行目\n\n二行目\t三", "一行目 二行目 三"},
+		{"ｶﾀｶﾅ", "カタカナ"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := NormalizeText(tt.input); got != tt.expected {
+			t.Errorf("NormalizeText(%q) = %q, want %q", tt.input, got, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+// 縦書きのHTML（builtin:vertical）で数字を読みやすく並べる処理です．
+// 縦書きでは半角の数字が横倒しになるため，短い数字は縦中横に，それ以外は全角にして縦に並べます．
+package quiz_yaml_converter
+
+import "strings"
+
+// maxTateChuYokoDigits は縦中横（1文字分の幅に横に並べる）にする数字の最大の桁数．
+// これより長い数字は全角にして1文字ずつ縦に並べる．
+const maxTateChuYokoDigits = 2
+
+// TateChuYoko は縦書きで表示するHTMLの半角数字を整える．
+// 2桁の数字は<span class="tcy">で囲み（CSSのtext-combine-uprightで縦中横にする），
+// 1桁の数字と3桁以上の数字は全角にする．HTMLのタグ（<ruby>など）の中と
+// 文字参照（&#12354;，&#x3042;，&amp;など）は変更しない．
+func TateChuYoko(s string) string {
+	var b strings.Builder
+	runes := []rune(s)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case r == '<':
+			end := i + 1
+			for end < len(runes) && runes[end] != '>' {
+				end++
+			}
+			if end < len(runes) {
+				end++
+			}
+			b.WriteString(string(runes[i:end]))
+			i = end
+		case r == '&' && characterReferenceEnd(runes, i) > 0:
+			end := characterReferenceEnd(runes, i)
+			b.WriteString(string(runes[i:end]))
+			i = end
+		case isASCIIDigit(r):
+			end := i + 1
+			for end < len(runes) && isASCIIDigit(runes[end]) {
+				end++
+			}
+			digits := string(runes[i:end])
+			if end-i > 1 && end-i <= maxTateChuYokoDigits {
+				b.WriteString(`<span class="tcy">` + digits + `</span>`)
+			} else {
+				for _, d := range digits {
+					b.WriteRune(d - '0' + '０')
+				}
+			}
+			i = end
+		default:
+			b.WriteRune(r)
+			i++
+		}
+	}
+	return b.String()
+}
+
+// characterReferenceEnd はrunes[start]の&から始まる文字参照（&名前;，&#10進数;，&#x16進数;）の
+// 末尾の;の次の位置を返す．文字参照でない場合は0を返す．
+func characterReferenceEnd(runes []rune, start int) int {
+	i := start + 1
+	valid := func(r rune) bool { return isASCIIDigit(r) || 'a' <= r && r <= 'z' || 'A' <= r && r <= 'Z' }
+	if i < len(runes) && runes[i] == '#' {
+		i++
+		valid = isASCIIDigit
+		if i < len(runes) && (runes[i] == 'x' || runes[i] == 'X') {
+			i++
+			valid = func(r rune) bool { return isASCIIDigit(r) || 'a' <= r && r <= 'f' || 'A' <= r && r <= 'F' }
+		}
+	}
+	first := i
+	for i < len(runes) && valid(runes[i]) {
+		i++
+	}
+	if i == first || i >= len(runes) || runes[i] != ';' {
+		return 0
+	}
+	return i + 1
+}
+
+// isASCIIDigit は半角数字かを返す．
+func isASCIIDigit(r rune) bool {
+	return '0' <= r && r <= '9'
+}
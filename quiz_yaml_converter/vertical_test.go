@@ -0,0 +1,27 @@
+package quiz_yaml_converter
+
+import "testing"
+
+func TestTateChuYoko(t *testing.T) {
+	tests := []struct {
+		input, want string
+	}{
+		{"縦書きの問題", "縦書きの問題"},
+		{"第3回", "第３回"},
+		{"12月25日", `<span class="tcy">12</span>月<span class="tcy">25</span>日`},
+		{"1868年", "１８６８年"},
+		{"1-12", `１-<span class="tcy">12</span>`},
+		{"<ruby>平成<rp>（</rp><rt>へいせい</rt><rp>）</rp></ruby>31年", `<ruby>平成<rp>（</rp><rt>へいせい</rt><rp>）</rp></ruby><span class="tcy">31</span>年`},
+		{`<span data-n="10">10</span>`, `<span data-n="10"><span class="tcy">10</span></span>`},
+		{"全角の１２はそのまま", "全角の１２はそのまま"},
+		{"&#12354;と&#x3042;は12", `&#12354;と&#x3042;は<span class="tcy">12</span>`},
+		{"&amp;&lt;3&gt;", "&amp;&lt;３&gt;"},
+		{"&#;と&#x;と& 10", `&#;と&#x;と& <span class="tcy">10</span>`},
+		{"&1", "&１"},
+	}
+	for _, tt := range tests {
+		if got := TateChuYoko(tt.input); got != tt.want {
+			t.Errorf("TateChuYoko(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package quiz_yaml_converter
+
+import (
+	"strings"
+	"unicode"
+)
+
+// 空白や不可視文字に関する警告の規則名（ValidationError.Rule）
+const (
+	RuleTrailingSpace  = "trailing-space"  // 行末の空白
+	RuleFullWidthSpace = "fullwidth-space" // 語の間の全角スペース
+	RuleZeroWidth      = "zero-width"      // ゼロ幅文字
+	RuleMixedNewlines  = "mixed-newlines"  // 改行コードの混在
+)
+
+// zeroWidthChars は見た目では分からないゼロ幅文字．
+const zeroWidthChars = "\u200b\u200c\u200d\u2060\ufeff"
+
+// WhitespaceWarnings は問題文と答えに含まれる行末の空白，語の間の全角スペース，
+// ゼロ幅文字，改行コード（CRLFとLF）の混在についての警告を返す．
+// 警告は問題・フィールド・規則ごとに1件とする．FixWhitespaceで自動的に修正できる．
+func WhitespaceWarnings(data []QuizItem) []ValidationError {
+	var warnings []ValidationError
+	for i, item := range data {
+		for _, f := range []struct{ field, text string }{
+			{"question", item.Question},
+			{"answer", item.Answer},
+		} {
+			warnings = append(warnings, lintWhitespace(i+1, f.field, f.text)...)
+		}
+	}
+	return warnings
+}
+
+// lintWhitespace は1つのテキストの空白や不可視文字を調べる．
+func lintWhitespace(index int, field, text string) []ValidationError {
+	var warnings []ValidationError
+	if hasMixedNewlines(text) {
+		warnings = append(warnings, newValidationError(index, field, RuleMixedNewlines, nil, "%s の改行コード（CRLFとLF）が混在しています", field))
+	}
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSuffix(line, "\r")
+		if line != strings.TrimRightFunc(line, isLintSpace) {
+			warnings = append(warnings, newValidationError(index, field, RuleTrailingSpace, nil, "%s の行末に空白があります", field))
+			break
+		}
+	}
+	if fullWidthSpaceInWord(text) {
+		warnings = append(warnings, newValidationError(index, field, RuleFullWidthSpace, nil, "%s の語の間に全角スペースがあります", field))
+	}
+	if i := strings.IndexAny(text, zeroWidthChars); i >= 0 {
+		r := []rune(text[i:])[0]
+		warnings = append(warnings, newValidationError(index, field, RuleZeroWidth, nil, "%s にゼロ幅文字 (U+%04X) が含まれています", field, r))
+	}
+	return warnings
+}
+
+// hasMixedNewlines はCRLFとLFの改行が混在しているかを返す．
+func hasMixedNewlines(text string) bool {
+	return strings.Contains(text, "\r\n") && strings.Contains(strings.ReplaceAll(text, "\r\n", ""), "\n")
+}
+
+// isLintSpace は行末の空白として扱う文字（改行を除く空白）かを返す．
+func isLintSpace(r rune) bool {
+	return r != '\n' && r != '\r' && unicode.IsSpace(r)
+}
+
+// fullWidthSpaceInWord は前後を空白以外の文字に挟まれた全角スペースがあるかを返す．
+func fullWidthSpaceInWord(text string) bool {
+	runes := []rune(text)
+	for i := 1; i+1 < len(runes); i++ {
+		if runes[i] == '\u3000' && !unicode.IsSpace(runes[i-1]) && !unicode.IsSpace(runes[i+1]) {
+			return true
+		}
+	}
+	return false
+}
+
+// FixWhitespaceText はWhitespaceWarningsで警告される空白や不可視文字を修正したテキストを返す．
+// 改行コードが混在している場合はLFに揃え，ゼロ幅文字と行末の空白を取り除く．
+// 語の間の全角スペースは，前後が英数字の場合は半角スペースに置き換え，それ以外は取り除く．
+func FixWhitespaceText(text string) string {
+	return fixWhitespace(text, true)
+}
+
+// fixWhitespace はFixWhitespaceTextの処理を行う．trimEndがfalseの場合は最後の行の行末の空白を残す．
+// 問題文の区切り（segments）の途中の区切りでは，区切りの後ろに文章が続くため行末として扱わない．
+func fixWhitespace(text string, trimEnd bool) string {
+	if hasMixedNewlines(text) {
+		text = strings.ReplaceAll(text, "\r\n", "\n")
+	}
+	text = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(zeroWidthChars, r) {
+			return -1
+		}
+		return r
+	}, text)
+
+	runes := []rune(text)
+	var b strings.Builder
+	for i, r := range runes {
+		if r == '\u3000' && i > 0 && i+1 < len(runes) && !unicode.IsSpace(runes[i-1]) && !unicode.IsSpace(runes[i+1]) {
+			if isASCIIAlnum(runes[i-1]) && isASCIIAlnum(runes[i+1]) {
+				b.WriteRune(' ')
+			}
+			continue
+		}
+		b.WriteRune(r)
+	}
+
+	lines := strings.Split(b.String(), "\n")
+	for i, line := range lines {
+		if i == len(lines)-1 && !trimEnd {
+			break
+		}
+		cr := strings.HasSuffix(line, "\r")
+		line = strings.TrimRightFunc(strings.TrimSuffix(line, "\r"), isLintSpace)
+		if cr {
+			line += "\r"
+		}
+		lines[i] = line
+	}
+	return strings.Join(lines, "\n")
+}
+
+// isASCIIAlnum はrがASCIIの英数字かを返す．
+func isASCIIAlnum(r rune) bool {
+	return r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r))
+}
+
+// FixWhitespace は問題文と答えの空白や不可視文字をFixWhitespaceTextで修正するStage．
+// 問題文の区切り（segments）も同じように修正する．
+var FixWhitespace Stage = EachItem(func(_ int, item *QuizItem) (bool, error) {
+	item.Question = FixWhitespaceText(item.Question)
+	item.Answer = FixWhitespaceText(item.Answer)
+	for i := range item.Segments {
+		item.Segments[i] = fixWhitespace(item.Segments[i], i == len(item.Segments)-1)
+	}
+	return true, nil
+})
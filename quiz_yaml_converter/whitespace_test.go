@@ -0,0 +1,99 @@
+package quiz_yaml_converter
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestWhitespaceWarnings(t *testing.T) {
+	tests := []struct {
+		name     string
+		item     QuizItem
+		expected []string // 規則名
+	}{
+		{"clean", QuizItem{Question: "ドイツの首都は？", Answer: "ベルリン"}, nil},
+		{"block scalar", QuizItem{Question: "ドイツの\n首都は？\n", Answer: "ベルリン"}, nil},
+		{"trailing space", QuizItem{Question: "ドイツの首都は？ ", Answer: "ベルリン"}, []string{RuleTrailingSpace}},
+		{"trailing space before newline", QuizItem{Question: "ドイツの　\n首都は？", Answer: "ベルリン"}, []string{RuleTrailingSpace}},
+		{"fullwidth space", QuizItem{Question: "夏目　漱石の代表作は？", Answer: "坊っちゃん"}, []string{RuleFullWidthSpace}},
+		{"leading fullwidth space", QuizItem{Question: "　夏目漱石の代表作は？", Answer: "坊っちゃん"}, nil},
+		{"zero width", QuizItem{Question: "q", Answer: "ベル\u200bリン"}, []string{RuleZeroWidth}},
+		{"mixed newlines", QuizItem{Question: "a\r\nb\nc", Answer: "a"}, []string{RuleMixedNewlines}},
+		{"crlf only", QuizItem{Question: "a\r\nb\r\n", Answer: "a"}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var rules []string
+			for _, w := range WhitespaceWarnings([]QuizItem{tt.item}) {
+				rules = append(rules, w.Rule)
+			}
+			if !reflect.DeepEqual(rules, tt.expected) {
+				t.Errorf("WhitespaceWarnings() rules = %v, want %v", rules, tt.expected)
+			}
+		})
+	}
+}
+
+func TestWhitespaceWarnings_Message(t *testing.T) {
+	warnings := WhitespaceWarnings([]QuizItem{{Question: "q", Answer: "ベル\u200bリン"}})
+	if len(warnings) != 1 {
+		t.Fatalf("WhitespaceWarnings() = %+v, want one warning", warnings)
+	}
+	if got := warnings[0].Error(); got != "問題 1: answer にゼロ幅文字 (U+200B) が含まれています" {
+		t.Errorf("Error() = %q", got)
+	}
+	if got := warnings[0].LocalizedError(LanguageEnglish); got != "item 1: answer contains a zero-width character (U+200B)" {
+		t.Errorf("LocalizedError(en) = %q", got)
+	}
+}
+
+func TestFixWhitespaceText(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"clean", "ドイツの首都は？", "ドイツの首都は？"},
+		{"trailing space", "ドイツの首都は？ 　", "ドイツの首都は？"},
+		{"trailing space per line", "ドイツの \n首都は？\t\n", "ドイツの\n首都は？\n"},
+		{"fullwidth space between japanese", "夏目　漱石", "夏目漱石"},
+		{"fullwidth space between latin", "Botchan　Book", "Botchan Book"},
+		{"leading fullwidth space", "　夏目漱石", "　夏目漱石"},
+		{"zero width", "ベル\u200bリン\ufeff", "ベルリン"},
+		{"mixed newlines", "a \r\nb\nc", "a\nb\nc"},
+		{"crlf only", "a \r\nb\r\n", "a\r\nb\r\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FixWhitespaceText(tt.input); got != tt.expected {
+				t.Errorf("FixWhitespaceText(%q) = %q, want %q", tt.input, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestFixWhitespace(t *testing.T) {
+	items := []QuizItem{{
+		Question: "ドイツの 首都は？ ",
+		Segments: []string{"ドイツの ", "首都は？ "},
+		Answer:   "ベル\u200bリン",
+	}}
+	got, err := Pipeline{FixWhitespace}.Apply(items)
+	if err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	expected := []QuizItem{{
+		Question: "ドイツの 首都は？",
+		Segments: []string{"ドイツの ", "首都は？"},
+		Answer:   "ベルリン",
+	}}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Apply() = %+v, want %+v", got, expected)
+	}
+	if errs := validateSegments(got[0], 1); len(errs) != 0 {
+		t.Errorf("validateSegments() = %v, want no errors", errs)
+	}
+	if items[0].Answer != "ベル\u200bリン" {
+		t.Errorf("Apply() modified the original item: %+v", items[0])
+	}
+}
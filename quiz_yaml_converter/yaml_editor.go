@@ -0,0 +1,295 @@
+// YAMLファイルのコメントを残したまま問題データを書き換えるための処理です．
+// 問題データをデコードしてから書き出すとYAML中のコメント（# TODOや# 出典など）が失われるため，
+// 読み込んだノードを残しておき，変更したフィールドのノードだけを置き換えて書き出します．
+package quiz_yaml_converter
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+
+	"gopkg.in/yaml.v3"
+)
+
+// YAMLEditor は読み込んだYAMLのノード（コメントを含む）と問題データ．
+// Itemsの問題を書き換えてからWriteまたはWriteFileを呼ぶと，変更したフィールドだけを元のYAMLに反映して書き出す．
+// 問題の追加・削除・並べ替えには対応しないため，Itemsの長さと順序は変えないこと．
+type YAMLEditor struct {
+	Items []QuizItem // 問題データ（ParseYAMLDataと同じ内容）
+
+	documents []*yaml.Node // ドキュメントのノード
+	nodes     []*yaml.Node // 各問題のマッピングノード（Itemsと同じ順）
+	encoded   []*yaml.Node // 前回書き出したとき（読み込んだとき）の問題をencodeItemNodeで変換したもの
+}
+
+// NewYAMLEditor はメモリ上のYAMLデータを，コメントを残したまま書き換えられるように解析する．
+// 形式はParseYAMLDataと同じ（問題のリスト，ジャンル名から問題のリストへのマッピング，複数のドキュメント）．
+func NewYAMLEditor(yamlData []byte) (*YAMLEditor, error) {
+	decoder := yaml.NewDecoder(bytes.NewReader(bytes.TrimPrefix(yamlData, []byte(utf8BOM))))
+	e := &YAMLEditor{}
+	var meta FileMetadata
+	var itemDocuments []int
+	for {
+		var node yaml.Node
+		if err := decoder.Decode(&node); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, fmt.Errorf("%w: %w", ErrInvalidYAML, err)
+		}
+		err := eachQuizItemNode(&node, &meta, func(child *yaml.Node, genre string) error {
+			item, err := decodeQuizItem(child, genre, &meta)
+			if err != nil {
+				return err
+			}
+			if child.Kind != yaml.MappingNode {
+				return fmt.Errorf("line %d: quiz item must be a mapping", child.Line)
+			}
+			encoded, err := encodeItemNode(item)
+			if err != nil {
+				return err
+			}
+			e.Items = append(e.Items, item)
+			e.nodes = append(e.nodes, child)
+			e.encoded = append(e.encoded, encoded)
+			itemDocuments = append(itemDocuments, len(e.documents))
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidYAML, err)
+		}
+		e.documents = append(e.documents, &node)
+	}
+
+	// ParseYAMLDataと同じく，問題を含むドキュメントが複数ある場合はDocumentを設定する
+	numbers := map[int]int{}
+	for _, doc := range itemDocuments {
+		if _, ok := numbers[doc]; !ok {
+			numbers[doc] = len(numbers) + 1
+		}
+	}
+	if len(numbers) > 1 {
+		for i, doc := range itemDocuments {
+			e.Items[i].Document = numbers[doc]
+		}
+	}
+	return e, nil
+}
+
+// LoadYAMLEditor はYAMLファイルを，コメントを残したまま書き換えられるように読み込む．
+func LoadYAMLEditor(yamlFilePath string) (*YAMLEditor, error) {
+	yamlData, err := os.ReadFile(yamlFilePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open YAML file: %w", err)
+	}
+	return NewYAMLEditor(yamlData)
+}
+
+// LoadYAMLEditors は複数のYAMLファイルを，コメントを残したまま書き換えられるようにそれぞれ読み込む．
+func LoadYAMLEditors(yamlFilePaths []string) ([]*YAMLEditor, error) {
+	editors := make([]*YAMLEditor, len(yamlFilePaths))
+	for i, path := range yamlFilePaths {
+		editor, err := LoadYAMLEditor(path)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		editors[i] = editor
+	}
+	return editors, nil
+}
+
+// Write はItemsの変更を反映したYAMLをwに書き出す．
+// 変更のないフィールドは元の書き方（引用符や改行の形式）とコメントをそのまま残し，
+// 変更したフィールドは値のノードだけを置き換える（値に付いていたコメントは残す）．
+// 新しいフィールドはWriteYAMLと同じキーの順序になる位置に追加する．インデントは2文字に揃える．
+func (e *YAMLEditor) Write(w io.Writer) error {
+	if len(e.Items) != len(e.nodes) {
+		return fmt.Errorf("the number of quiz items changed from %d to %d", len(e.nodes), len(e.Items))
+	}
+	for i, item := range e.Items {
+		after, err := encodeItemNode(item)
+		if err != nil {
+			return err
+		}
+		if sameNode(e.encoded[i], after) {
+			continue
+		}
+		if err := patchMapping(e.nodes[i], e.encoded[i], after); err != nil {
+			return fmt.Errorf("item %d: %w", i+1, err)
+		}
+		e.encoded[i] = after
+	}
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(yamlIndent)
+	for _, doc := range e.documents {
+		if err := encoder.Encode(doc); err != nil {
+			return fmt.Errorf("failed to marshal YAML: %w", err)
+		}
+	}
+	if err := encoder.Close(); err != nil {
+		return fmt.Errorf("failed to marshal YAML: %w", err)
+	}
+	if _, err := w.Write(buf.Bytes()); err != nil {
+		return fmt.Errorf("failed to write YAML: %w", err)
+	}
+	return nil
+}
+
+// WriteFile はItemsの変更を反映したYAMLをファイルに書き出す（Write参照）．
+func (e *YAMLEditor) WriteFile(yamlFilePath string) error {
+	return writeFileAtomic(yamlFilePath, false, e.Write)
+}
+
+// YAMLItemRef はYAMLEditorの問題の1つを指す．
+type YAMLItemRef struct {
+	Editor *YAMLEditor
+	Index  int // Editor.Itemsでの位置（0始まり）
+}
+
+// Refs はItemsの各問題を指すYAMLItemRefをItemsと同じ順に返す．
+func (e *YAMLEditor) Refs() []YAMLItemRef {
+	refs := make([]YAMLItemRef, len(e.Items))
+	for i := range refs {
+		refs[i] = YAMLItemRef{Editor: e, Index: i}
+	}
+	return refs
+}
+
+// ComposeYAMLEditor はrefsが指す問題のノードをコピーして順に並べ，1つの問題のリストとして書き出すYAMLEditorを作成する．
+// 複数のファイルの問題を統合したり，一部の問題を別のファイルに書き出したりする場合に使う．
+// 各問題のコメントと書き方は元のまま残り，ジャンル名をキーとしたマッピングやmetadataから引き継いでいた
+// genre・author・licenseは各問題に書き出す．元のYAMLEditorのItemsの変更（書き出していないものを含む）も反映する．
+// 元のYAMLEditorは変更しない．
+func ComposeYAMLEditor(refs []YAMLItemRef) (*YAMLEditor, error) {
+	list := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+	e := &YAMLEditor{documents: []*yaml.Node{{Kind: yaml.DocumentNode, Content: []*yaml.Node{list}}}}
+	for _, ref := range refs {
+		if ref.Editor == nil || ref.Index < 0 || ref.Index >= len(ref.Editor.nodes) {
+			return nil, fmt.Errorf("invalid quiz item reference: %d", ref.Index)
+		}
+		node := copyNode(ref.Editor.nodes[ref.Index])
+		// 引き継いでいた値を各問題に書き出すよう，ノードに書かれている値だけを変更前の内容とする
+		written, err := decodeQuizItem(node, "", nil)
+		if err != nil {
+			return nil, err
+		}
+		encoded, err := encodeItemNode(written)
+		if err != nil {
+			return nil, err
+		}
+		item := ref.Editor.Items[ref.Index]
+		item.Document = 0
+		list.Content = append(list.Content, node)
+		e.Items = append(e.Items, item)
+		e.nodes = append(e.nodes, node)
+		e.encoded = append(e.encoded, encoded)
+	}
+	return e, nil
+}
+
+// copyNode はノードを子ノードまで含めてコピーする．
+// 別のドキュメントに移してもアンカーが重ならないよう，エイリアスは参照先の内容に置き換え，アンカーは取り除く．
+func copyNode(node *yaml.Node) *yaml.Node {
+	if node.Kind == yaml.AliasNode && node.Alias != nil {
+		return copyNode(node.Alias)
+	}
+	c := *node
+	c.Anchor = ""
+	c.Content = make([]*yaml.Node, len(node.Content))
+	for i, child := range node.Content {
+		c.Content[i] = copyNode(child)
+	}
+	return &c
+}
+
+// encodeItemNode は問題をWriteYAMLと同じ形式のマッピングノードにする．
+func encodeItemNode(item QuizItem) (*yaml.Node, error) {
+	var node yaml.Node
+	if err := node.Encode(item); err != nil {
+		return nil, fmt.Errorf("failed to marshal YAML: %w", err)
+	}
+	return &node, nil
+}
+
+// patchMapping はbeforeからafterへの変更（キーごとの値の違い）をtargetのマッピングノードに反映する．
+// 値がマッピングの場合（criteriaなど）は，その中のキーごとに反映する．
+func patchMapping(target, before, after *yaml.Node) error {
+	if target.Kind != yaml.MappingNode || before.Kind != yaml.MappingNode || after.Kind != yaml.MappingNode {
+		return errors.New("quiz item must be a mapping")
+	}
+	var previous string // afterの直前のキーのうちtargetにあるもの（新しいキーを追加する位置に使う）
+	for i := 0; i+1 < len(after.Content); i += 2 {
+		key, value := after.Content[i].Value, after.Content[i+1]
+		old := mappingValue(before, key)
+		pos := mappingIndex(target, key)
+		switch {
+		case old != nil && sameNode(old, value):
+			// 変更のないキーはそのまま残す（継承したgenreなど，targetにないキーも追加しない）
+		case pos < 0:
+			insert := 0
+			if p := mappingIndex(target, previous); p >= 0 {
+				insert = p + 2
+			}
+			target.Content = append(target.Content[:insert], append([]*yaml.Node{after.Content[i], value}, target.Content[insert:]...)...)
+		case old != nil && old.Kind == yaml.MappingNode && value.Kind == yaml.MappingNode && target.Content[pos+1].Kind == yaml.MappingNode:
+			if err := patchMapping(target.Content[pos+1], old, value); err != nil {
+				return err
+			}
+		default:
+			current := target.Content[pos+1]
+			value.HeadComment, value.LineComment, value.FootComment = current.HeadComment, current.LineComment, current.FootComment
+			target.Content[pos+1] = value
+		}
+		if mappingIndex(target, key) >= 0 {
+			previous = key
+		}
+	}
+
+	// afterにないキーは取り除く．キーの前のコメントは次のキーに移す
+	for i := 0; i+1 < len(before.Content); i += 2 {
+		key := before.Content[i].Value
+		if mappingValue(after, key) != nil {
+			continue
+		}
+		if pos := mappingIndex(target, key); pos >= 0 {
+			comment := target.Content[pos].HeadComment
+			target.Content = append(target.Content[:pos], target.Content[pos+2:]...)
+			if comment != "" && pos < len(target.Content) && target.Content[pos].HeadComment == "" {
+				target.Content[pos].HeadComment = comment
+			}
+		}
+	}
+	return nil
+}
+
+// mappingIndex はマッピングノードでキーkeyの位置（キーのノードの位置）を返す．ない場合は-1を返す．
+func mappingIndex(mapping *yaml.Node, key string) int {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return i
+		}
+	}
+	return -1
+}
+
+// mappingValue はマッピングノードのキーkeyの値のノードを返す．ない場合はnilを返す．
+func mappingValue(mapping *yaml.Node, key string) *yaml.Node {
+	if i := mappingIndex(mapping, key); i >= 0 {
+		return mapping.Content[i+1]
+	}
+	return nil
+}
+
+// sameNode は2つのノードが同じ値を表すかを返す．
+func sameNode(a, b *yaml.Node) bool {
+	var va, vb any
+	if a.Decode(&va) != nil || b.Decode(&vb) != nil {
+		return false
+	}
+	return reflect.DeepEqual(va, vb)
+}
@@ -0,0 +1,265 @@
+package quiz_yaml_converter
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestYAMLEditor_Write(t *testing.T) {
+	input := `# 問題集
+- question: "日本の首都は？" # 易しい
+  # TODO: 出典を確認
+  answer: 東京
+  spell: Tokyo
+  criteria:
+    ok:
+      - 東京都 # 正式名称
+- question: フランスの首都は？
+  answer: パリ
+  spell: Paris
+  tags: [首都]
+`
+	tests := []struct {
+		name     string
+		edit     func(items []QuizItem)
+		expected string
+	}{
+		{"no changes", func([]QuizItem) {}, input},
+		{
+			"change values",
+			func(items []QuizItem) {
+				items[0].Answer = "東京都"
+				items[1].Spell = "Paris (France)"
+			},
+			`# 問題集
+- question: "日本の首都は？" # 易しい
+  # TODO: 出典を確認
+  answer: 東京都
+  spell: Tokyo
+  criteria:
+    ok:
+      - 東京都 # 正式名称
+- question: フランスの首都は？
+  answer: パリ
+  spell: Paris (France)
+  tags: [首都]
+`,
+		},
+		{
+			"add and remove fields",
+			func(items []QuizItem) {
+				items[0].ID = "q1"
+				items[0].Difficulty = 2
+				items[0].Criteria["ng"] = []string{"京都"}
+				items[1].Tags = nil
+			},
+			`# 問題集
+- id: q1
+  question: "日本の首都は？" # 易しい
+  # TODO: 出典を確認
+  answer: 東京
+  spell: Tokyo
+  difficulty: 2
+  criteria:
+    ok:
+      - 東京都 # 正式名称
+    ng:
+      - 京都
+- question: フランスの首都は？
+  answer: パリ
+  spell: Paris
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := NewYAMLEditor([]byte(input))
+			if err != nil {
+				t.Fatalf("NewYAMLEditor() error = %v", err)
+			}
+			tt.edit(e.Items)
+			var buf bytes.Buffer
+			if err := e.Write(&buf); err != nil {
+				t.Fatalf("Write() error = %v", err)
+			}
+			if buf.String() != tt.expected {
+				t.Errorf("Write() =\n%s\nwant\n%s", buf.String(), tt.expected)
+			}
+		})
+	}
+}
+
+func TestYAMLEditor_GenreLayout(t *testing.T) {
+	input := `地理:
+  # 首都の問題
+  - question: 日本の首都は？
+    answer: 東京
+    spell: Tokyo
+---
+歴史:
+  - question: 江戸幕府を開いたのは？
+    answer: 徳川家康
+    spell: Tokugawa Ieyasu
+`
+	e, err := NewYAMLEditor([]byte(input))
+	if err != nil {
+		t.Fatalf("NewYAMLEditor() error = %v", err)
+	}
+	expected, err := ParseYAMLData([]byte(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(e.Items, expected) {
+		t.Errorf("Items = %+v, want %+v", e.Items, expected)
+	}
+
+	// ジャンルのキーから設定されたgenreは書き出さない
+	AssignIDs(e.Items, false)
+	var buf bytes.Buffer
+	if err := e.Write(&buf); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	want := `地理:
+  # 首都の問題
+  - id: ` + ContentID(e.Items[0]) + `
+    question: 日本の首都は？
+    answer: 東京
+    spell: Tokyo
+---
+歴史:
+  - id: ` + ContentID(e.Items[1]) + `
+    question: 江戸幕府を開いたのは？
+    answer: 徳川家康
+    spell: Tokugawa Ieyasu
+`
+	if buf.String() != want {
+		t.Errorf("Write() =\n%s\nwant\n%s", buf.String(), want)
+	}
+
+	e.Items = e.Items[:1]
+	if err := e.Write(&buf); err == nil {
+		t.Error("Write() should fail when the number of items changed")
+	}
+}
+
+func TestLoadYAMLEditor(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "quiz.yaml")
+	if err := os.WriteFile(path, []byte("# コメント\n- question: 問題\n  answer: 答え # 確認済み\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	e, err := LoadYAMLEditor(path)
+	if err != nil {
+		t.Fatalf("LoadYAMLEditor() error = %v", err)
+	}
+	e.Items[0].Reading = "こたえ"
+	if err := e.WriteFile(path); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "# コメント\n- question: 問題\n  answer: 答え # 確認済み\n  reading: こたえ\n"; string(content) != want {
+		t.Errorf("WriteFile() wrote\n%s\nwant\n%s", content, want)
+	}
+
+	if _, err := LoadYAMLEditor(filepath.Join(t.TempDir(), "missing.yaml")); err == nil {
+		t.Error("LoadYAMLEditor() should fail for a missing file")
+	}
+	if _, err := NewYAMLEditor([]byte("- question: [")); err == nil {
+		t.Error("NewYAMLEditor() should fail for invalid YAML")
+	}
+}
+
+func TestComposeYAMLEditor(t *testing.T) {
+	history, err := NewYAMLEditor([]byte(`metadata:
+  author: 山田
+歴史:
+  # TODO: 出典を確認
+  - question: "鎌倉幕府を開いたのは？" # 1192年
+    answer: 源頼朝
+    tags: &shogunate [幕府]
+  - question: 江戸幕府を開いたのは？
+    answer: 徳川家康
+    tags: *shogunate
+`))
+	if err != nil {
+		t.Fatalf("NewYAMLEditor() error = %v", err)
+	}
+	geography, err := NewYAMLEditor([]byte("- question: 日本一高い山は？ # 出典: 国土地理院\n  answer: 富士山\n  tags: [山]\n"))
+	if err != nil {
+		t.Fatalf("NewYAMLEditor() error = %v", err)
+	}
+	// 書き出していない変更も反映する
+	geography.Items[0].Difficulty = 2
+
+	e, err := ComposeYAMLEditor([]YAMLItemRef{{Editor: geography, Index: 0}, {Editor: history, Index: 0}})
+	if err != nil {
+		t.Fatalf("ComposeYAMLEditor() error = %v", err)
+	}
+	e.Items[1].Reading = "みなもとのよりとも"
+	var buf bytes.Buffer
+	if err := e.Write(&buf); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	expected := `- question: 日本一高い山は？ # 出典: 国土地理院
+  answer: 富士山
+  difficulty: 2
+  tags: [山]
+# TODO: 出典を確認
+- question: "鎌倉幕府を開いたのは？" # 1192年
+  answer: 源頼朝
+  reading: みなもとのよりとも
+  genre: 歴史
+  tags: [幕府]
+  author: 山田
+`
+	if buf.String() != expected {
+		t.Errorf("Write() =\n%s\nwant\n%s", buf.String(), expected)
+	}
+	if history.Items[0].Reading != "" {
+		t.Errorf("ComposeYAMLEditor() changed the source editor: %+v", history.Items[0])
+	}
+
+	// 別のファイルに移す問題のエイリアスは，参照先の内容に置き換える
+	e, err = ComposeYAMLEditor(history.Refs()[1:])
+	if err != nil {
+		t.Fatalf("ComposeYAMLEditor() error = %v", err)
+	}
+	buf.Reset()
+	if err := e.Write(&buf); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if expected := "- question: 江戸幕府を開いたのは？\n  answer: 徳川家康\n  genre: 歴史\n  tags: [幕府]\n  author: 山田\n"; buf.String() != expected {
+		t.Errorf("Write() =\n%s\nwant\n%s", buf.String(), expected)
+	}
+
+	refs := history.Refs()
+	if len(refs) != 2 || refs[1] != (YAMLItemRef{Editor: history, Index: 1}) {
+		t.Errorf("Refs() = %+v", refs)
+	}
+	if _, err := ComposeYAMLEditor([]YAMLItemRef{{Editor: history, Index: 2}}); err == nil {
+		t.Error("ComposeYAMLEditor() with an invalid reference expected error")
+	}
+}
+
+func TestLoadYAMLEditors(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "quiz.yaml")
+	if err := os.WriteFile(path, []byte("- question: 問題\n  answer: 答え\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	editors, err := LoadYAMLEditors([]string{path, path})
+	if err != nil {
+		t.Fatalf("LoadYAMLEditors() error = %v", err)
+	}
+	if len(editors) != 2 || len(editors[1].Items) != 1 {
+		t.Errorf("LoadYAMLEditors() = %+v", editors)
+	}
+	if _, err := LoadYAMLEditors([]string{path, filepath.Join(dir, "missing.yaml")}); err == nil {
+		t.Error("LoadYAMLEditors() should fail for a missing file")
+	}
+}
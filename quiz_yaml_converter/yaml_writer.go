@@ -0,0 +1,45 @@
+// 問題データをYAMLとして書き戻すための処理です．
+// 問題の追加・並べ替え・修正などを行うコマンドは，結果をこの形式で書き出します．
+package quiz_yaml_converter
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+
+	"gopkg.in/yaml.v3"
+)
+
+// yamlIndent は書き出すYAMLのインデント幅．
+const yamlIndent = 2
+
+// WriteYAML は問題データを正規化したYAMLとしてwに書き出す．
+// キーはQuizItemのフィールドの順（id, question, segments, answer, reading, spell, ...）に並べ，
+// criteriaのキーはCriteriaOrder（未設定の場合はok, ng, repeat）の順とする．インデントは2文字とする．
+// 同じ問題データからは常に同じ内容を書き出すため，書き戻したファイルの差分は変更した箇所だけになる．
+// 問題のDocumentが設定されている場合は，Documentが変わるごとに---で区切った複数のドキュメントとして書き出す．
+func WriteYAML(w io.Writer, items []QuizItem) error {
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(yamlIndent)
+	for _, document := range splitDocuments(items) {
+		if err := encoder.Encode(document); err != nil {
+			return fmt.Errorf("failed to marshal YAML: %w", err)
+		}
+	}
+	if err := encoder.Close(); err != nil {
+		return fmt.Errorf("failed to marshal YAML: %w", err)
+	}
+	if _, err := w.Write(buf.Bytes()); err != nil {
+		return fmt.Errorf("failed to write YAML: %w", err)
+	}
+	return nil
+}
+
+// WriteYAMLFile は問題データをWriteYAMLと同じ形式でYAMLファイルに書き出す．
+// 一時ファイルに書き出してから置き換えるため，エラーの場合は既存のファイルはそのまま残る．
+func WriteYAMLFile(yamlFilePath string, items []QuizItem) error {
+	return writeFileAtomic(yamlFilePath, false, func(w io.Writer) error {
+		return WriteYAML(w, items)
+	})
+}
@@ -0,0 +1,96 @@
+package quiz_yaml_converter
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestWriteYAML(t *testing.T) {
+	items := []QuizItem{
+		{
+			ID:            "q1",
+			Question:      "日本の首都は？",
+			Answer:        "東京",
+			Reading:       "とうきょう",
+			Spell:         "Tokyo",
+			Genre:         "地理",
+			Tags:          []string{"首都", "日本"},
+			Criteria:      map[string][]string{"repeat": {"江戸"}, "ok": {"東京都"}},
+			CriteriaOrder: []string{"repeat", "ok"},
+		},
+		{Question: "問題2", Answer: "答え2", Spell: "Answer 2", Comments: []string{"コメント"}},
+	}
+	expected := `- id: q1
+  question: 日本の首都は？
+  answer: 東京
+  reading: とうきょう
+  spell: Tokyo
+  genre: 地理
+  tags:
+    - 首都
+    - 日本
+  criteria:
+    repeat:
+      - 江戸
+    ok:
+      - 東京都
+- question: 問題2
+  answer: 答え2
+  spell: Answer 2
+  comments:
+    - コメント
+`
+	var buf bytes.Buffer
+	if err := WriteYAML(&buf, items); err != nil {
+		t.Fatalf("WriteYAML() error = %v", err)
+	}
+	if buf.String() != expected {
+		t.Errorf("WriteYAML() =\n%s\nwant\n%s", buf.String(), expected)
+	}
+
+	// 読み込んで書き戻しても同じ内容になる
+	loaded, err := ParseYAMLData(buf.Bytes())
+	if err != nil {
+		t.Fatalf("ParseYAMLData() error = %v", err)
+	}
+	var again bytes.Buffer
+	if err := WriteYAML(&again, loaded); err != nil {
+		t.Fatalf("WriteYAML() error = %v", err)
+	}
+	if again.String() != expected {
+		t.Errorf("WriteYAML() after reloading =\n%s\nwant\n%s", again.String(), expected)
+	}
+}
+
+func TestWriteYAMLFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rounds.yaml")
+	items := []QuizItem{
+		{Question: "q1", Answer: "a1", Document: 1},
+		{Question: "q2", Answer: "a2", Document: 2},
+	}
+	if err := WriteYAMLFile(path, items); err != nil {
+		t.Fatalf("WriteYAMLFile() error = %v", err)
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "- question: q1\n  answer: a1\n  spell: \"\"\n---\n- question: q2\n  answer: a2\n  spell: \"\"\n"
+	if string(content) != expected {
+		t.Errorf("WriteYAMLFile() wrote\n%s\nwant\n%s", content, expected)
+	}
+	loaded, err := LoadYAMLData(path)
+	if err != nil {
+		t.Fatalf("LoadYAMLData() error = %v", err)
+	}
+	if !reflect.DeepEqual(loaded, items) {
+		t.Errorf("LoadYAMLData() = %+v, want %+v", loaded, items)
+	}
+
+	if err := WriteYAMLFile(filepath.Join(t.TempDir(), "missing", "quiz.yaml"), items); err == nil {
+		t.Error("WriteYAMLFile() should fail for a missing directory")
+	}
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/m-uesaka/quiz-yaml-go/quiz_yaml_converter"
+)
+
+// resultsEntry はresultsサブコマンドのJSON出力での1問分の集計．
+type resultsEntry struct {
+	Index       int     `json:"index"`
+	ID          string  `json:"id"`
+	Question    string  `json:"question"`
+	Attempts    int     `json:"attempts"`
+	Correct     int     `json:"correct"`
+	CorrectRate float64 `json:"correct_rate"`
+	Difficulty  int     `json:"difficulty,omitempty"` // 正答率から見積もった難易度（解答がない場合は省略）
+}
+
+// runResults はresultsサブコマンドを実行する．
+// 成績のCSVを読み込み，問題ごとの正答率を表示する．指定した場合は正答率から見積もった難易度をYAMLファイルに書き戻す．
+func runResults(args []string) {
+	fs := flag.NewFlagSet("results", flag.ExitOnError)
+	var (
+		resultsFile     = fs.String("results", "", T("成績のCSVファイル（必須）"))
+		format          = fs.String("format", "text", T("出力形式（text, json）"))
+		writeDifficulty = fs.Bool("write-difficulty", false, T("正答率から見積もった難易度（difficulty）をYAMLファイルに書き戻す"))
+		levels          = fs.Int("levels", quiz_yaml_converter.DefaultDifficultyLevels, T("見積もる難易度の段階数"))
+		output          = fs.String("output", "", T("-write-difficulty で書き出すYAMLファイルのパス（未指定時は入力ファイルを上書き）"))
+		quiet           = fs.Bool("quiet", false, T("エラー以外のメッセージを出力しない"))
+		verbose         = fs.Bool("verbose", false, T("詳細なメッセージを出力する"))
+		logFormat       = fs.String("log-format", logFormatText, T("メッセージの出力形式（text, json）"))
+	)
+	addLangFlag(fs)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, T("使用法: %s results [オプション] -results results.csv quiz.yaml\n\n"), filepath.Base(os.Args[0]))
+		fmt.Fprint(os.Stderr, T("成績のCSV（1列目が問題ID，2列目以降がプレイヤーごとの正誤）を読み込み，問題ごとの正答率を表示します。\n"))
+		fmt.Fprint(os.Stderr, T("問題はidがあればidで，なければ問題文と答えから決まるIDで対応付けます。\n\n"))
+		fmt.Fprint(os.Stderr, T("オプション:\n"))
+		fs.PrintDefaults()
+		fmt.Fprint(os.Stderr, T("\n例:\n"))
+		fmt.Fprintf(os.Stderr, "  %s results -results results.csv quiz.yaml\n", filepath.Base(os.Args[0]))
+		fmt.Fprintf(os.Stderr, "  %s results -results results.csv -write-difficulty -output calibrated.yaml quiz.yaml\n", filepath.Base(os.Args[0]))
+	}
+	fs.Parse(args)
+
+	log, err := newLogger(os.Stderr, *logFormat, *quiet, *verbose)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, T("エラー: %v\n"), err)
+		os.Exit(exitUsage)
+	}
+	if fs.NArg() != 1 || *resultsFile == "" {
+		log.Error(T("成績のCSVファイル（-results）とYAMLファイルを1つ指定してください"))
+		fs.Usage()
+		os.Exit(exitUsage)
+	}
+	if *format != "text" && *format != "json" {
+		log.Error(fmt.Sprintf(T("サポートされていない出力形式です: %s（text, json）"), *format))
+		os.Exit(exitUsage)
+	}
+	if *levels <= 0 {
+		log.Error(fmt.Sprintf(T("難易度の段階数は1以上を指定してください: %d"), *levels))
+		os.Exit(exitUsage)
+	}
+
+	inputFile := fs.Arg(0)
+	// 書き戻すときにYAML中のコメントを残すため，ノードごと読み込む
+	editor, err := quiz_yaml_converter.LoadYAMLEditor(inputFile)
+	if err != nil {
+		log.Error(T("YAMLファイルの読み込みに失敗しました"), "error", err)
+		os.Exit(exitCodeFor(err))
+	}
+	items := editor.Items
+	results, err := loadPlayResults(*resultsFile)
+	if err != nil {
+		log.Error(T("成績のCSVファイルの読み込みに失敗しました"), "error", err)
+		os.Exit(exitCodeFor(err))
+	}
+
+	stats, unmatched := quiz_yaml_converter.QuestionResults(items, results)
+	for _, id := range unmatched {
+		log.Warn(fmt.Sprintf(T("成績の問題IDに対応する問題がありません: %s"), id), "id", id)
+	}
+
+	if *format == "json" {
+		entries := make([]resultsEntry, len(stats))
+		for i, s := range stats {
+			entries[i] = resultsEntry{Index: s.Index, ID: s.ID, Question: s.Item.Question, Attempts: s.Attempts, Correct: s.Correct, CorrectRate: s.CorrectRate()}
+			if s.Attempts > 0 {
+				entries[i].Difficulty = quiz_yaml_converter.DifficultyFromRate(s.CorrectRate(), *levels)
+			}
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		enc.Encode(entries)
+	} else {
+		writeResultsText(os.Stdout, stats, len(results.Players), *levels)
+	}
+
+	if !*writeDifficulty {
+		return
+	}
+	outputFile := *output
+	if outputFile == "" {
+		outputFile = inputFile
+	}
+	changed := quiz_yaml_converter.ApplyDifficulty(items, stats, *levels)
+	if changed == 0 && outputFile == inputFile {
+		log.Info(T("難易度を変更する問題はありませんでした"), "input", inputFile)
+		return
+	}
+	if err := editor.WriteFile(outputFile); err != nil {
+		log.Error(T("YAMLファイルの書き出しに失敗しました"), "error", err)
+		os.Exit(exitCodeFor(err))
+	}
+	log.Info(fmt.Sprintf(T("%d問の難易度を書き換えました: %s"), changed, outputFile), "input", inputFile, "output", outputFile, "changed", changed)
+}
+
+// loadPlayResults は成績のCSVファイルを読み込む．
+func loadPlayResults(path string) (*quiz_yaml_converter.PlayResults, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return quiz_yaml_converter.ReadPlayResults(f)
+}
+
+// writeResultsText は問題ごとの正答率を人が読むための形式で書き出す．
+func writeResultsText(w io.Writer, stats []quiz_yaml_converter.QuestionStats, players, levels int) {
+	answered := 0
+	for _, s := range stats {
+		if s.Attempts > 0 {
+			answered++
+		}
+	}
+	fmt.Fprintf(w, T("プレイヤー: %d人, 成績のある問題: %d / %d問\n"), players, answered, len(stats))
+	for _, s := range stats {
+		if s.Attempts == 0 {
+			fmt.Fprintf(w, T("\n[問題 %d] %s\n    成績なし\n"), s.Index, s.Item.Question)
+			continue
+		}
+		fmt.Fprintf(w, T("\n[問題 %d] %s\n    正答率: %.1f%%（%d / %d）, 難易度: %d\n"), s.Index, s.Item.Question,
+			s.CorrectRate()*100, s.Correct, s.Attempts, quiz_yaml_converter.DifficultyFromRate(s.CorrectRate(), levels))
+	}
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/m-uesaka/quiz-yaml-go/quiz_yaml_converter"
+)
+
+// runRounds はroundsサブコマンドを実行する．
+// 問題をジャンルと難易度が偏らないように複数のラウンドに振り分け，ラウンドごとのYAMLファイルに書き出す．
+func runRounds(args []string) {
+	fs := flag.NewFlagSet("rounds", flag.ExitOnError)
+	var (
+		rounds    = fs.Int("rounds", 0, T("ラウンド数（必須）"))
+		perRound  = fs.Int("per-round", 0, T("1ラウンドあたりの問題数（未指定時はすべての問題を振り分ける）"))
+		output    = fs.String("output", ".", T("ラウンドごとのYAMLファイル（round-1.yamlなど）を書き出すディレクトリ"))
+		seed      = fs.Int64("seed", 0, T("問題を選ぶ順序をシャッフルする乱数のシード（0の場合は入力の順に選ぶ）"))
+		quiet     = fs.Bool("quiet", false, T("エラー以外のメッセージを出力しない"))
+		verbose   = fs.Bool("verbose", false, T("詳細なメッセージを出力する"))
+		logFormat = fs.String("log-format", logFormatText, T("メッセージの出力形式（text, json）"))
+	)
+	addLangFlag(fs)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, T("使用法: %s rounds [オプション] quiz.yaml [quiz2.yaml ...]\n\n"), filepath.Base(os.Args[0]))
+		fmt.Fprint(os.Stderr, T("問題を複数のラウンドに振り分け，ラウンドごとのYAMLファイルに書き出します。\n"))
+		fmt.Fprint(os.Stderr, T("各ラウンドのジャンルの数と難易度（difficulty）の合計ができるだけ揃うように振り分けます。\n\n"))
+		fmt.Fprint(os.Stderr, T("オプション:\n"))
+		fs.PrintDefaults()
+		fmt.Fprint(os.Stderr, T("\n例:\n"))
+		fmt.Fprintf(os.Stderr, "  %s rounds -rounds 3 quiz.yaml\n", filepath.Base(os.Args[0]))
+		fmt.Fprintf(os.Stderr, "  %s rounds -rounds 4 -per-round 10 -seed 1 -output rounds quiz.yaml\n", filepath.Base(os.Args[0]))
+	}
+	fs.Parse(args)
+
+	log, err := newLogger(os.Stderr, *logFormat, *quiet, *verbose)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, T("エラー: %v\n"), err)
+		os.Exit(exitUsage)
+	}
+	if fs.NArg() == 0 {
+		log.Error(T("振り分けるYAMLファイルを指定してください"))
+		fs.Usage()
+		os.Exit(exitUsage)
+	}
+	if *rounds <= 0 {
+		log.Error(T("-roundsには1以上の数を指定してください"))
+		fs.Usage()
+		os.Exit(exitUsage)
+	}
+
+	// 問題に付いたコメントを残すよう，YAMLをノードのまま読み込んでラウンドごとに組み直す
+	editors, err := quiz_yaml_converter.LoadYAMLEditors(fs.Args())
+	if err != nil {
+		log.Error(T("YAMLファイルの読み込みに失敗しました"), "error", err)
+		os.Exit(exitCodeFor(err))
+	}
+	var refs []quiz_yaml_converter.YAMLItemRef
+	var items []quiz_yaml_converter.QuizItem
+	for _, editor := range editors {
+		refs = append(refs, editor.Refs()...)
+		items = append(items, editor.Items...)
+	}
+	distributed, err := quiz_yaml_converter.DistributeRoundIndices(items, quiz_yaml_converter.RoundOptions{
+		Rounds:   *rounds,
+		PerRound: *perRound,
+		Seed:     *seed,
+	})
+	if err != nil {
+		log.Error(T("問題の振り分けに失敗しました"), "error", err)
+		os.Exit(exitUsage)
+	}
+
+	if err := os.MkdirAll(*output, 0755); err != nil {
+		log.Error(T("出力ディレクトリの作成に失敗しました"), "error", err)
+		os.Exit(exitCodeFor(err))
+	}
+	for r, indices := range distributed {
+		path := filepath.Join(*output, fmt.Sprintf("round-%d.yaml", r+1))
+		roundRefs := make([]quiz_yaml_converter.YAMLItemRef, len(indices))
+		for j, i := range indices {
+			roundRefs[j] = refs[i]
+		}
+		round, err := quiz_yaml_converter.ComposeYAMLEditor(roundRefs)
+		if err == nil {
+			err = round.WriteFile(path)
+		}
+		if err != nil {
+			log.Error(T("YAMLファイルの書き出しに失敗しました"), "error", err)
+			os.Exit(exitCodeFor(err))
+		}
+		genres, difficulty := roundSummary(round.Items)
+		log.Info(fmt.Sprintf(T("ラウンド %d: %d問（%s，難易度の合計 %d）: %s"), r+1, len(round.Items), genres, difficulty, path),
+			"round", r+1, "items", len(round.Items), "difficulty", difficulty, "output", path)
+	}
+}
+
+// roundSummary はラウンドに含まれるジャンルごとの問題数（"歴史 2，科学 1"の形式）と難易度の合計を返す．
+func roundSummary(items []quiz_yaml_converter.QuizItem) (string, int) {
+	var genres []string
+	counts := map[string]int{}
+	difficulty := 0
+	for _, item := range items {
+		genre := strings.TrimSpace(item.Genre)
+		if genre == "" {
+			genre = T("ジャンルなし")
+		}
+		if counts[genre] == 0 {
+			genres = append(genres, genre)
+		}
+		counts[genre]++
+		difficulty += item.Difficulty
+	}
+	parts := make([]string, len(genres))
+	for i, genre := range genres {
+		parts[i] = fmt.Sprintf("%s %d", genre, counts[genre])
+	}
+	return strings.Join(parts, T("，")), difficulty
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/m-uesaka/quiz-yaml-go/quiz_yaml_converter"
+)
+
+// runRoundTrip はroundtripサブコマンドを実行する．
+// YAMLをCSVに変換してから読み戻し，元のYAMLと比較して失われるフィールドを表示する．
+func runRoundTrip(args []string) {
+	fs := flag.NewFlagSet("roundtrip", flag.ExitOnError)
+	var (
+		format      = fs.String("format", "text", T("出力形式（text, json）"))
+		output      = fs.String("output", "", T("CSVから読み戻した問題データを書き出すYAMLファイルのパス"))
+		exitCode    = fs.Bool("exit-code", false, T("失われるフィールドがある場合に終了コード8で終了する"))
+		columns     = fs.String("columns", "", T("CSVに出力する列をカンマ区切りで指定（id, question, answer, reading, spell, genre, difficulty, tags, comments, criteria, source, reference, author, license, question_length, answer_length, ok_count）"))
+		comments    = fs.Bool("comments", false, T("CSVの末尾にcomments列を追加する"))
+		commentSep  = fs.String("comment-sep", "", T("CSVのcomments列で複数のコメントをつなぐ文字列（未指定時は改行）"))
+		criteriaSep = fs.String("criteria-sep", "", T("CSVのcriteria列で正誤判定の区分をつなぐ文字列（未指定時は／）"))
+		critItemSep = fs.String("criteria-item-sep", "", T("CSVのcriteria列で各区分の項目をつなぐ文字列（指定時は項目を「」で囲まない）"))
+		noHeader    = fs.Bool("no-header", false, T("CSVのヘッダー行を出力しない"))
+		headers     = fs.String("header-labels", "", T("CSVのヘッダー名を変更（例: question=問題,answer=答え．jaで日本語ラベル）"))
+		encoding    = fs.String("encoding", "utf8", T("CSVの文字コード（utf8, utf8-bom, sjis）"))
+		newlines    = fs.String("newlines", "keep", T("CSVのフィールド内の改行の扱い（keep: そのまま，escape: \\nに置き換え，space: 空白にまとめる）"))
+		escapeFx    = fs.Bool("escape-formulas", false, T("=, +, -, @で始まるCSVフィールドの先頭に'を付けて数式として解釈されないようにする"))
+		assignIDs   = fs.Bool("assign-ids", false, T("idが未設定の問題に問題文と答えから決まるIDを割り当てて出力する（CSVで-columns未指定時はid列を追加）"))
+	)
+	addLangFlag(fs)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, T("使用法: %s roundtrip [オプション] quiz.yaml\n\n"), filepath.Base(os.Args[0]))
+		fmt.Fprint(os.Stderr, T("YAMLをCSVに変換してから読み戻し，元のYAMLと比較します。\n"))
+		fmt.Fprint(os.Stderr, T("CSVへの出力で失われる，または値が変わるフィールドを表示します。\n\n"))
+		fmt.Fprint(os.Stderr, T("オプション:\n"))
+		fs.PrintDefaults()
+		fmt.Fprint(os.Stderr, T("\n例:\n"))
+		fmt.Fprintf(os.Stderr, "  %s roundtrip quiz.yaml\n", filepath.Base(os.Args[0]))
+		fmt.Fprintf(os.Stderr, "  %s roundtrip -columns id,question,answer,criteria -exit-code quiz.yaml\n", filepath.Base(os.Args[0]))
+	}
+	fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		fmt.Fprint(os.Stderr, T("エラー: 往復変換するYAMLファイルを1つ指定してください\n\n"))
+		fs.Usage()
+		os.Exit(exitUsage)
+	}
+	if *format != "text" && *format != "json" {
+		fmt.Fprintf(os.Stderr, T("エラー: サポートされていない出力形式です: %s（text, json）\n"), *format)
+		os.Exit(exitUsage)
+	}
+
+	opts := quiz_yaml_converter.ConvertOptions{AssignIDs: *assignIDs}
+	opts.CSV.IncludeComments = *comments
+	opts.CSV.CommentSeparator = *commentSep
+	opts.CSV.CriteriaSeparator = *criteriaSep
+	opts.CSV.CriteriaItemSeparator = *critItemSep
+	opts.CSV.NoHeader = *noHeader
+	opts.CSV.EscapeFormulas = *escapeFx
+	enc, err := quiz_yaml_converter.ParseEncoding(*encoding)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, T("エラー: %v\n"), err)
+		os.Exit(exitUsage)
+	}
+	opts.CSV.Encoding = enc
+	if opts.CSV.Newlines, err = quiz_yaml_converter.ParseNewlineMode(*newlines); err != nil {
+		fmt.Fprintf(os.Stderr, T("エラー: %v\n"), err)
+		os.Exit(exitUsage)
+	}
+	if *headers != "" {
+		if opts.CSV.HeaderLabels, err = quiz_yaml_converter.ParseCSVHeaderLabels(*headers); err != nil {
+			fmt.Fprintf(os.Stderr, T("エラー: %v\n"), err)
+			os.Exit(exitUsage)
+		}
+	}
+	if *columns != "" {
+		if opts.CSV.Columns, err = quiz_yaml_converter.ParseCSVColumns(*columns); err != nil {
+			fmt.Fprintf(os.Stderr, T("エラー: %v\n"), err)
+			os.Exit(exitUsage)
+		}
+	}
+
+	inputFile := fs.Arg(0)
+	items, err := quiz_yaml_converter.LoadYAMLData(inputFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, T("エラー: %sの読み込みに失敗しました: %v\n"), inputFile, err)
+		os.Exit(exitCodeFor(err))
+	}
+	result, err := quiz_yaml_converter.RoundTripCSV(items, opts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, T("エラー: 往復変換に失敗しました: %v\n"), err)
+		os.Exit(exitCodeFor(err))
+	}
+	if *output != "" {
+		if err := quiz_yaml_converter.WriteYAMLFile(*output, result.Restored); err != nil {
+			fmt.Fprintf(os.Stderr, T("エラー: %sの書き出しに失敗しました: %v\n"), *output, err)
+			os.Exit(exitCodeFor(err))
+		}
+	}
+
+	if *format == "json" {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		enc.Encode(result)
+	} else {
+		writeRoundTripText(os.Stdout, result)
+	}
+
+	if *exitCode && !result.IsLossless() {
+		os.Exit(exitDifferent)
+	}
+}
+
+// writeRoundTripText は往復変換の結果を人が読むための形式で書き出す．
+func writeRoundTripText(w io.Writer, result quiz_yaml_converter.RoundTripResult) {
+	if result.IsLossless() {
+		fmt.Fprintf(w, T("%d問すべてのフィールドがCSVから復元できました\n"), result.Items)
+		return
+	}
+	fmt.Fprintf(w, T("%d問中%d問で値が失われました: %s\n"), result.Items, len(result.Changes), strings.Join(result.Lossy, ", "))
+	for _, d := range result.Changes {
+		fmt.Fprintf(w, T("\n~ [問題 %d] %s\n"), d.OldIndex, d.Question)
+		for _, c := range d.Changes {
+			fmt.Fprintf(w, "    %s: %q → %q\n", c.Field, c.Old, c.New)
+		}
+	}
+}
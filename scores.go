@@ -0,0 +1,81 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/m-uesaka/quiz-yaml-go/quiz_yaml_converter"
+)
+
+// runScores はscoresサブコマンドを実行する．
+// 問題データと成績のCSVから，プレイヤーごと・問題ごとの成績表をテンプレートで整形して書き出す．
+func runScores(args []string) {
+	fs := flag.NewFlagSet("scores", flag.ExitOnError)
+	var (
+		resultsFile  = fs.String("results", "", T("成績のCSVファイル（必須）"))
+		output       = fs.String("output", "", T("書き出す成績表のパス（必須）"))
+		templateFile = fs.String("template", "", T("成績表のテンプレートファイル（未指定時は組み込みのHTMLテンプレート）"))
+		quiet        = fs.Bool("quiet", false, T("エラー以外のメッセージを出力しない"))
+		verbose      = fs.Bool("verbose", false, T("詳細なメッセージを出力する"))
+		logFormat    = fs.String("log-format", logFormatText, T("メッセージの出力形式（text, json）"))
+	)
+	var templateVars commandList
+	fs.Var(&templateVars, "var", T("テンプレートに{{.Vars.名前}}として渡す変数（名前=値．=を省略すると同じ名前の環境変数の値を使う．複数回指定できる）"))
+	addLangFlag(fs)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, T("使用法: %s scores [オプション] -results results.csv -output report.html quiz.yaml [quiz2.yaml ...]\n\n"), filepath.Base(os.Args[0]))
+		fmt.Fprint(os.Stderr, T("問題データと成績のCSVから，プレイヤーごと・問題ごとの成績表を書き出します。\n"))
+		fmt.Fprint(os.Stderr, T("成績のCSVの形式はresultsサブコマンドと同じです。\n\n"))
+		fmt.Fprint(os.Stderr, T("オプション:\n"))
+		fs.PrintDefaults()
+		fmt.Fprint(os.Stderr, T("\n例:\n"))
+		fmt.Fprintf(os.Stderr, "  %s scores -results results.csv -output report.html quiz.yaml\n", filepath.Base(os.Args[0]))
+		fmt.Fprintf(os.Stderr, "  %s scores -results results.csv -var title=第1回大会 -template report.md -output report.md quiz.yaml\n", filepath.Base(os.Args[0]))
+	}
+	fs.Parse(args)
+
+	log, err := newLogger(os.Stderr, *logFormat, *quiet, *verbose)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, T("エラー: %v\n"), err)
+		os.Exit(exitUsage)
+	}
+	if fs.NArg() == 0 || *resultsFile == "" {
+		log.Error(T("成績のCSVファイル（-results）とYAMLファイルを指定してください"))
+		fs.Usage()
+		os.Exit(exitUsage)
+	}
+	if *output == "" {
+		log.Error(T("-outputを指定してください"))
+		fs.Usage()
+		os.Exit(exitUsage)
+	}
+	vars, err := quiz_yaml_converter.ParseTemplateVars(templateVars, os.LookupEnv)
+	if err != nil {
+		log.Error(T("-varの指定が正しくありません"), "error", err)
+		os.Exit(exitUsage)
+	}
+
+	items, err := quiz_yaml_converter.LoadYAMLFiles(fs.Args())
+	if err != nil {
+		log.Error(T("YAMLファイルの読み込みに失敗しました"), "error", err)
+		os.Exit(exitCodeFor(err))
+	}
+	results, err := loadPlayResults(*resultsFile)
+	if err != nil {
+		log.Error(T("成績のCSVファイルの読み込みに失敗しました"), "error", err)
+		os.Exit(exitCodeFor(err))
+	}
+
+	opts := quiz_yaml_converter.ConvertOptions{Vars: vars}
+	data := quiz_yaml_converter.NewScoreReportData(items, results, opts)
+	for _, id := range data.Unmatched {
+		log.Warn(fmt.Sprintf(T("成績の問題IDに対応する問題がありません: %s"), id), "id", id)
+	}
+	if err := quiz_yaml_converter.ConvertToScoreReport(items, results, *templateFile, *output, opts); err != nil {
+		log.Error(T("成績表の書き出しに失敗しました"), "error", err)
+		os.Exit(exitCodeFor(err))
+	}
+	log.Info(fmt.Sprintf(T("%d人・%d問の成績表を書き出しました: %s"), len(data.Players), len(items), *output), "players", len(data.Players), "items", len(items), "output", *output)
+}
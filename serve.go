@@ -0,0 +1,428 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/m-uesaka/quiz-yaml-go/quiz_yaml_converter"
+)
+
+// 既定のリクエストボディの上限（10MiB）
+const defaultMaxBodyBytes = 10 << 20
+
+// runServe はserveサブコマンドを実行する．
+// 変換・バリデーションを行うHTTP APIを提供するサーバーを起動する．
+func runServe(args []string) {
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	var (
+		addr      = fs.String("addr", ":8080", T("待ち受けるアドレス"))
+		maxBody   = fs.Int64("max-body", defaultMaxBodyBytes, T("リクエストボディの最大サイズ（バイト）"))
+		quiet     = fs.Bool("quiet", false, T("エラー以外のメッセージを出力しない"))
+		verbose   = fs.Bool("verbose", false, T("リクエストごとのログを出力する"))
+		logFormat = fs.String("log-format", logFormatText, T("メッセージの出力形式（text, json）"))
+		ui        = fs.Bool("ui", false, T("テンプレートのプレビュー画面を有効にする（-inputが必要）"))
+		inputFile = fs.String("input", "", T("-ui指定時にプレビューするYAMLファイルのパス"))
+		template  = fs.String("template", "", T("-ui指定時に使用するテンプレートファイルのパス（未指定時は-formatの組み込みテンプレート）"))
+		format    = fs.String("format", "html", T("-ui指定時に-templateが未指定の場合の出力フォーマット"))
+	)
+	var questionFiles inputList
+	fs.Var(&questionFiles, "questions", T("/questionsで出題するYAMLファイルのパス（複数回またはカンマ区切りで指定可能）"))
+	addLangFlag(fs)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, T("使用法: %s serve [オプション]\n\n"), filepath.Base(os.Args[0]))
+		fmt.Fprint(os.Stderr, T("変換・バリデーションを行うHTTP APIサーバーを起動します。\n\n"))
+		fmt.Fprint(os.Stderr, T("オプション:\n"))
+		fs.PrintDefaults()
+		fmt.Fprint(os.Stderr, T("\nエンドポイント:\n"))
+		fmt.Fprint(os.Stderr, T("  POST /convert?format=csv|html|markdown  YAMLを受け取り変換結果を返す\n"))
+		fmt.Fprint(os.Stderr, T("  POST /validate                          YAMLを受け取りバリデーション結果をJSONで返す\n"))
+		fmt.Fprint(os.Stderr, T("  GET  /healthz                           稼働確認\n"))
+		fmt.Fprint(os.Stderr, T("  GET  /questions/random?genre=&exclude=  無作為に選んだ問題をJSONで返す（-questions指定時）\n"))
+		fmt.Fprint(os.Stderr, T("  GET  /questions/{id}                    IDで指定した問題をJSONで返す（-questions指定時）\n"))
+		fmt.Fprint(os.Stderr, T("  GET  /                                  プレビュー画面（-ui指定時）\n"))
+		fmt.Fprint(os.Stderr, T("\n例:\n"))
+		fmt.Fprintf(os.Stderr, "  %s serve -addr :8080\n", filepath.Base(os.Args[0]))
+		fmt.Fprintf(os.Stderr, "  %s serve -ui -input quiz.yaml -template my_template.html\n", filepath.Base(os.Args[0]))
+		fmt.Fprintf(os.Stderr, "  %s serve -questions quiz1.yaml,quiz2.yaml\n", filepath.Base(os.Args[0]))
+	}
+	fs.Parse(args)
+
+	log, err := newLogger(os.Stderr, *logFormat, *quiet, *verbose)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, T("エラー: %v\n"), err)
+		os.Exit(exitUsage)
+	}
+
+	var questions []quiz_yaml_converter.QuizItem
+	if len(questionFiles) > 0 {
+		questions, err = quiz_yaml_converter.LoadYAMLFiles(questionFiles)
+		if err != nil {
+			log.Error(T("YAMLファイルの読み込みに失敗しました"), "error", err)
+			os.Exit(exitCodeFor(err))
+		}
+		// exclude=で除外できるよう，idのない問題にもContentIDを割り当てて返す
+		quiz_yaml_converter.AssignIDs(questions, false)
+		log.Info(fmt.Sprintf(T("%d問を出題できます"), len(questions)), "questions", len(questions))
+	}
+
+	mux := newServeMux(log, *maxBody, questions)
+	srv := &http.Server{
+		Addr:              *addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+		ReadTimeout:       30 * time.Second,
+		WriteTimeout:      60 * time.Second,
+	}
+
+	stopWatch := make(chan struct{})
+	defer close(stopWatch)
+	if *ui {
+		if *inputFile == "" {
+			fmt.Fprint(os.Stderr, T("エラー: -uiを指定する場合は-inputが必要です\n\n"))
+			fs.Usage()
+			os.Exit(exitUsage)
+		}
+		if *template == "" {
+			if _, err := quiz_yaml_converter.LookupFormatter(*format); err != nil {
+				fmt.Fprintf(os.Stderr, T("エラー: プレビューできないフォーマットです: %s（%s）\n"), *format, strings.Join(quiz_yaml_converter.FormatterNames(), ", "))
+				os.Exit(exitUsage)
+			}
+		}
+		preview := newPreviewUI(*inputFile, *template, *format, log)
+		preview.register(mux)
+		go preview.watch(stopWatch)
+		// Server-Sent Eventsの接続を維持するため書き込みのタイムアウトは設けない
+		srv.WriteTimeout = 0
+		log.Info(fmt.Sprintf(T("プレビュー画面: http://%s/"), displayAddr(*addr)), "input", *inputFile, "template", *template, "format", *format)
+	}
+
+	// Ctrl+CやSIGTERMで処理中のリクエストを待ってから終了する．
+	// プレビュー画面のイベント接続のような長時間のリクエストも終了できるよう，
+	// リクエストのコンテキストもシグナルでキャンセルされるようにする．
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	srv.BaseContext = func(net.Listener) context.Context { return ctx }
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		srv.Shutdown(shutdownCtx)
+	}()
+
+	log.Info(fmt.Sprintf(T("サーバーを起動しました: %s"), *addr), "addr", *addr)
+	if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		log.Error(T("サーバーの起動に失敗しました"), "error", err)
+		os.Exit(exitError)
+	}
+	log.Info(T("サーバーを停止しました"))
+}
+
+// displayAddr はブラウザで開くためのホスト名付きアドレスを返す．
+func displayAddr(addr string) string {
+	if strings.HasPrefix(addr, ":") {
+		return "localhost" + addr
+	}
+	return addr
+}
+
+// newServeMux はAPIのルーティングを設定したハンドラーを返す．
+// questionsは/questionsで出題する問題で，空の場合は/questionsが404を返す．
+func newServeMux(log *slog.Logger, maxBody int64, questions []quiz_yaml_converter.QuizItem) *http.ServeMux {
+	mux := http.NewServeMux()
+	converters := &converterCache{}
+	mux.HandleFunc("POST /convert", func(w http.ResponseWriter, r *http.Request) {
+		handleConvert(w, r, log, maxBody, converters)
+	})
+	mux.HandleFunc("POST /validate", func(w http.ResponseWriter, r *http.Request) {
+		handleValidate(w, r, log, maxBody)
+	})
+	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+	})
+	mux.HandleFunc("GET /questions/random", func(w http.ResponseWriter, r *http.Request) {
+		handleRandomQuestions(w, r, log, questions)
+	})
+	mux.HandleFunc("GET /questions/{id}", func(w http.ResponseWriter, r *http.Request) {
+		handleQuestion(w, r, questions)
+	})
+	return mux
+}
+
+// readRequestBody はリクエストボディを上限サイズまで読み込む．
+func readRequestBody(w http.ResponseWriter, r *http.Request, maxBody int64) ([]byte, error) {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBody))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read request body: %w", err)
+	}
+	return body, nil
+}
+
+// bodyErrorStatus はリクエストボディの読み込みエラーに対応するステータスを返す．
+func bodyErrorStatus(err error) int {
+	var maxErr *http.MaxBytesError
+	if errors.As(err, &maxErr) {
+		return http.StatusRequestEntityTooLarge
+	}
+	return http.StatusBadRequest
+}
+
+// handleConvert はPOST /convertを処理する．
+// クエリパラメータでformat（登録されている出力形式の名前）とCSVのオプションを指定できる．
+func handleConvert(w http.ResponseWriter, r *http.Request, log *slog.Logger, maxBody int64, converters *converterCache) {
+	query := r.URL.Query()
+	format := query.Get("format")
+	if format == "" {
+		format = "csv"
+	}
+	// Converterは同じ出力形式とオプションのリクエストで共有する．Converterは並行して使用できる
+	converter, err := converters.get(format, query)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	body, err := readRequestBody(w, r, maxBody)
+	if err != nil {
+		writeError(w, bodyErrorStatus(err), err)
+		return
+	}
+	// エラー時に途中までの出力を返さないよう，ConvertBytesで変換を終えてから書き出す
+	out, err := converter.ConvertBytes(body)
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, quiz_yaml_converter.ErrInvalidYAML), errors.Is(err, quiz_yaml_converter.ErrTransform):
+			status = http.StatusBadRequest
+		case errors.Is(err, quiz_yaml_converter.ErrOutputTooLarge), errors.Is(err, quiz_yaml_converter.ErrTemplateTimeout):
+			// サーバーの上限（serverMaxOutputBytes，serverTemplateTimeout）を超えた
+			status = http.StatusUnprocessableEntity
+		case errors.Is(err, quiz_yaml_converter.ErrTemplateExecute):
+			status = http.StatusUnprocessableEntity
+		}
+		if status != http.StatusBadRequest {
+			log.Error(T("変換に失敗しました"), "error", err, "format", format)
+		}
+		writeError(w, status, err)
+		return
+	}
+
+	log.Debug(T("変換しました"), "format", format, "bytes", len(out))
+	w.Header().Set("Content-Type", converter.ContentType())
+	w.Write(out)
+}
+
+// キャッシュするConverterの最大数．任意のクエリでメモリを使い切られないよう，超えた分は共有せずに作成する
+const maxCachedConverters = 256
+
+// サーバーで1回の変換に許す出力の大きさと時間．クエリで指定されたオプション（number-widthなど）で
+// 出力が膨らむ場合や，テンプレートの実行が終わらない場合に打ち切る
+const (
+	serverMaxOutputBytes  = 10 << 20
+	serverTemplateTimeout = 10 * time.Second
+)
+
+// converterCache は出力形式と変換オプションの組ごとに作成したConverterを共有する．ゼロ値で使用できる．
+type converterCache struct {
+	converters sync.Map // キー（出力形式とオプションのクエリ文字列）→ *quiz_yaml_converter.Converter
+	size       atomic.Int64
+}
+
+// get は出力形式とオプションに対応するConverterを返す．まだない場合は作成してキャッシュする．
+// 作成するConverterには出力の大きさと実行時間の上限（serverMaxOutputBytes，serverTemplateTimeout）を設定する．
+// オプションが正しくない場合や出力形式が登録されていない場合はエラーを返し，キャッシュしない．
+func (c *converterCache) get(format string, params map[string][]string) (*quiz_yaml_converter.Converter, error) {
+	values := url.Values{}
+	for key, v := range params {
+		values[key] = v
+	}
+	values.Set("format", format)
+	key := values.Encode()
+	if converter, ok := c.converters.Load(key); ok {
+		return converter.(*quiz_yaml_converter.Converter), nil
+	}
+
+	opts, err := quiz_yaml_converter.ParseConvertOptions(params)
+	if err != nil {
+		return nil, err
+	}
+	opts.MaxOutputBytes = serverMaxOutputBytes
+	opts.TemplateTimeout = serverTemplateTimeout
+	converter, err := quiz_yaml_converter.NewConverter(quiz_yaml_converter.OutputFormat(format), "", opts)
+	if err != nil {
+		return nil, err
+	}
+	if c.size.Load() >= maxCachedConverters {
+		return converter, nil
+	}
+	actual, loaded := c.converters.LoadOrStore(key, converter)
+	if !loaded {
+		c.size.Add(1)
+	}
+	return actual.(*quiz_yaml_converter.Converter), nil
+}
+
+// validationErrorResponse はバリデーションエラー1件分のレスポンス
+type validationErrorResponse struct {
+	Index   int    `json:"index,omitempty"`
+	Field   string `json:"field,omitempty"`
+	Rule    string `json:"rule,omitempty"`
+	Message string `json:"message"`
+}
+
+// validateResponse はPOST /validateのレスポンス
+type validateResponse struct {
+	Valid  bool                      `json:"valid"`
+	Items  int                       `json:"items"`
+	Errors []validationErrorResponse `json:"errors"`
+}
+
+// handleValidate はPOST /validateを処理する．
+// バリデーションに失敗した場合もステータスは200で，validがfalseになる．
+// メッセージの言語はクエリパラメータlang（ja, en）で指定できる．
+func handleValidate(w http.ResponseWriter, r *http.Request, log *slog.Logger, maxBody int64) {
+	msgLang, err := quiz_yaml_converter.ParseLanguage(r.URL.Query().Get("lang"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	body, err := readRequestBody(w, r, maxBody)
+	if err != nil {
+		writeError(w, bodyErrorStatus(err), err)
+		return
+	}
+
+	result := quiz_yaml_converter.ValidateYAML(body)
+
+	resp := validateResponse{
+		Valid:  result.IsValid,
+		Items:  result.Items,
+		Errors: []validationErrorResponse{},
+	}
+	for _, e := range result.ValidationErrors {
+		resp.Errors = append(resp.Errors, validationErrorResponse{Index: e.Index, Field: e.Field, Rule: e.Rule, Message: e.LocalizedMessage(msgLang)})
+	}
+	log.Debug(T("バリデーションしました"), "items", resp.Items, "errors", len(resp.Errors))
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// 1回のリクエストで返す問題の最大数
+const maxRandomQuestions = 100
+
+// errNoQuestions は出題する問題が読み込まれていない場合のエラー
+var errNoQuestions = errors.New("no questions are loaded (start the server with -questions)")
+
+// randomQuestionsResponse はGET /questions/randomのレスポンス
+type randomQuestionsResponse struct {
+	Items     []quiz_yaml_converter.QuizItem `json:"items"`
+	Available int                            `json:"available"` // 条件に合う問題の数
+}
+
+// handleRandomQuestions はGET /questions/randomを処理する．
+// クエリパラメータでcount（問題数），genre（ジャンル），exclude（除外する問題のID），
+// filter（-filterと同じ条件式），seed（乱数のシード）を指定できる．
+// genreとexcludeは複数回，またはカンマ区切りで指定できる．
+func handleRandomQuestions(w http.ResponseWriter, r *http.Request, log *slog.Logger, questions []quiz_yaml_converter.QuizItem) {
+	if len(questions) == 0 {
+		writeError(w, http.StatusNotFound, errNoQuestions)
+		return
+	}
+	query := r.URL.Query()
+	opts := quiz_yaml_converter.RandomOptions{
+		Genres:  splitQueryValues(query["genre"]),
+		Exclude: splitQueryValues(query["exclude"]),
+	}
+	if v := query.Get("count"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxRandomQuestions {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("count must be between 1 and %d: %q", maxRandomQuestions, v))
+			return
+		}
+		opts.Count = n
+	}
+	if v := query.Get("seed"); v != "" {
+		seed, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid seed: %q", v))
+			return
+		}
+		opts.Seed = seed
+	}
+	if v := query.Get("filter"); v != "" {
+		filter, err := quiz_yaml_converter.ParseFilter(v)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		opts.Filter = filter
+	}
+
+	items, available, err := quiz_yaml_converter.RandomItems(questions, opts)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if available == 0 {
+		writeError(w, http.StatusNotFound, errors.New("no questions match the conditions"))
+		return
+	}
+	log.Debug(T("問題を選びました"), "items", len(items), "available", available)
+	writeJSON(w, http.StatusOK, randomQuestionsResponse{Items: items, Available: available})
+}
+
+// handleQuestion はGET /questions/{id}を処理する．
+func handleQuestion(w http.ResponseWriter, r *http.Request, questions []quiz_yaml_converter.QuizItem) {
+	if len(questions) == 0 {
+		writeError(w, http.StatusNotFound, errNoQuestions)
+		return
+	}
+	id := r.PathValue("id")
+	for _, item := range questions {
+		if item.ID == id {
+			writeJSON(w, http.StatusOK, item)
+			return
+		}
+	}
+	writeError(w, http.StatusNotFound, fmt.Errorf("question not found: %q", id))
+}
+
+// splitQueryValues は複数回，またはカンマ区切りで指定されたクエリパラメータの値を1つのリストにする．
+func splitQueryValues(values []string) []string {
+	var result []string
+	for _, v := range values {
+		for _, s := range strings.Split(v, ",") {
+			if s = strings.TrimSpace(s); s != "" {
+				result = append(result, s)
+			}
+		}
+	}
+	return result
+}
+
+// writeJSON は値をJSONとして書き出す．
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeError はエラーを{"error": "..."}形式のJSONとして書き出す．
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/m-uesaka/quiz-yaml-go/quiz_yaml_converter"
+)
+
+// endlessFormat は書き込みが失敗するまで出力し続ける出力形式．サーバーの出力の上限を確かめるために使う
+const endlessFormat = "test-endless"
+
+func init() {
+	quiz_yaml_converter.RegisterFormatter(endlessFormat, quiz_yaml_converter.FormatterFunc(func(w io.Writer, _ []quiz_yaml_converter.QuizItem, _ quiz_yaml_converter.ConvertOptions) error {
+		chunk := make([]byte, 64<<10)
+		for {
+			if _, err := w.Write(chunk); err != nil {
+				return err
+			}
+		}
+	}))
+}
+
+func TestConverterCache(t *testing.T) {
+	var cache converterCache
+	first, err := cache.get("csv", map[string][]string{"no-header": {"true"}})
+	if err != nil {
+		t.Fatalf("get() error = %v", err)
+	}
+	tests := []struct {
+		name    string
+		format  string
+		params  map[string][]string
+		same    bool
+		wantErr bool
+	}{
+		{"same format and options", "csv", map[string][]string{"no-header": {"true"}}, true, false},
+		{"different options", "csv", map[string][]string{"quote-all": {"true"}}, false, false},
+		{"different format", "json", map[string][]string{"no-header": {"true"}}, false, false},
+		{"invalid option", "csv", map[string][]string{"encoding": {"ebcdic"}}, false, true},
+		{"unknown format", "pdf", nil, false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := cache.get(tt.format, tt.params)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("get() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && (got == first) != tt.same {
+				t.Errorf("get() returned the cached converter = %v, want %v", got == first, tt.same)
+			}
+		})
+	}
+	if size := cache.size.Load(); size != 3 {
+		t.Errorf("cached converters = %d, want 3", size)
+	}
+}
+
+func TestConverterCacheLimit(t *testing.T) {
+	var cache converterCache
+	cache.size.Store(maxCachedConverters)
+	first, err := cache.get("csv", nil)
+	if err != nil {
+		t.Fatalf("get() error = %v", err)
+	}
+	if second, _ := cache.get("csv", nil); second == first {
+		t.Error("get() over the limit returned a cached converter")
+	}
+}
+
+func TestServeMux(t *testing.T) {
+	questions := []quiz_yaml_converter.QuizItem{
+		{ID: "q1", Question: "日本の首都は？", Answer: "東京", Genre: "地理"},
+		{ID: "q2", Question: "フランスの首都は？", Answer: "パリ", Genre: "地理"},
+	}
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	ts := httptest.NewServer(newServeMux(log, 1024, questions))
+	t.Cleanup(ts.Close)
+	empty := httptest.NewServer(newServeMux(log, 1024, nil))
+	t.Cleanup(empty.Close)
+
+	valid := "- question: 日本の首都は？\n  answer: 東京\n"
+	tests := []struct {
+		name        string
+		server      *httptest.Server
+		method      string
+		path        string
+		body        string
+		status      int
+		contentType string
+		contains    string
+	}{
+		{"convert to csv", ts, http.MethodPost, "/convert", valid, http.StatusOK, "text/csv", "東京"},
+		{"convert with options", ts, http.MethodPost, "/convert?format=csv&no-header=true", valid, http.StatusOK, "text/csv", "日本の首都は？,東京"},
+		{"convert to json", ts, http.MethodPost, "/convert?format=json", valid, http.StatusOK, "application/json", `"answer": "東京"`},
+		{"unknown format", ts, http.MethodPost, "/convert?format=pdf", valid, http.StatusBadRequest, "application/json", `"error"`},
+		{"invalid option", ts, http.MethodPost, "/convert?encoding=ebcdic", valid, http.StatusBadRequest, "application/json", `"error"`},
+		{"output too large", ts, http.MethodPost, "/convert?format=" + endlessFormat, valid, http.StatusUnprocessableEntity, "application/json", "size limit"},
+		{"invalid yaml", ts, http.MethodPost, "/convert", "- question: [", http.StatusBadRequest, "application/json", `"error"`},
+		{"body too large", ts, http.MethodPost, "/convert", strings.Repeat("a", 2048), http.StatusRequestEntityTooLarge, "application/json", `"error"`},
+		{"convert with get", ts, http.MethodGet, "/convert", "", http.StatusMethodNotAllowed, "", ""},
+		{"validate", ts, http.MethodPost, "/validate", valid, http.StatusOK, "application/json", `"valid":true`},
+		{"validate errors", ts, http.MethodPost, "/validate?lang=en", "- question: q\n", http.StatusOK, "application/json", `"valid":false`},
+		{"validate unknown lang", ts, http.MethodPost, "/validate?lang=fr", valid, http.StatusBadRequest, "application/json", `"error"`},
+		{"healthz", ts, http.MethodGet, "/healthz", "", http.StatusOK, "application/json", `"status":"ok"`},
+		{"random question", ts, http.MethodGet, "/questions/random?count=1&exclude=q1", "", http.StatusOK, "application/json", `"id":"q2"`},
+		{"random question with genre", ts, http.MethodGet, "/questions/random?genre=歴史", "", http.StatusNotFound, "application/json", `"error"`},
+		{"random question count out of range", ts, http.MethodGet, "/questions/random?count=0", "", http.StatusBadRequest, "application/json", `"error"`},
+		{"random question invalid filter", ts, http.MethodGet, "/questions/random?filter=genre%20%3D%3D", "", http.StatusBadRequest, "application/json", `"error"`},
+		{"question by id", ts, http.MethodGet, "/questions/q1", "", http.StatusOK, "application/json", `"answer":"東京"`},
+		{"unknown question", ts, http.MethodGet, "/questions/q9", "", http.StatusNotFound, "application/json", `"error"`},
+		{"no questions loaded", empty, http.MethodGet, "/questions/random", "", http.StatusNotFound, "application/json", "-questions"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(tt.method, tt.server.URL+tt.path, strings.NewReader(tt.body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatalf("%s %s error = %v", tt.method, tt.path, err)
+			}
+			defer resp.Body.Close()
+			body, err := io.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if resp.StatusCode != tt.status {
+				t.Errorf("%s %s status = %d, want %d (body: %s)", tt.method, tt.path, resp.StatusCode, tt.status, body)
+			}
+			if got := resp.Header.Get("Content-Type"); !strings.HasPrefix(got, tt.contentType) {
+				t.Errorf("%s %s Content-Type = %q, want %q", tt.method, tt.path, got, tt.contentType)
+			}
+			if !strings.Contains(string(body), tt.contains) {
+				t.Errorf("%s %s body = %s, want it to contain %q", tt.method, tt.path, body, tt.contains)
+			}
+		})
+	}
+}
@@ -0,0 +1,40 @@
+// Package templates は変換ツールに組み込まれているテンプレートを提供します．
+// 実行時のカレントディレクトリによらず使用できるよう，バイナリに埋め込んでいます．
+package templates
+
+import _ "embed"
+
+// HTML出力用のテンプレート
+//
+//go:embed quiz_template.html
+var HTML string
+
+// Markdown出力用のテンプレート
+//
+//go:embed quiz_template.md
+var Markdown string
+
+// 読み上げ用（teleprompter）出力のHTMLテンプレート
+//
+//go:embed teleprompter.html
+var Teleprompter string
+
+// 成績表（scoresサブコマンド）のHTMLテンプレート
+//
+//go:embed score_report.html
+var ScoreReport string
+
+// 単語カード（-template builtin:flashcards）のHTMLテンプレート
+//
+//go:embed flashcards.html
+var Flashcards string
+
+// 印刷用（-template builtin:print）のHTMLテンプレート
+//
+//go:embed print.html
+var Print string
+
+// 縦書きの問題カード（-template builtin:vertical）のHTMLテンプレート
+//
+//go:embed vertical.html
+var Vertical string
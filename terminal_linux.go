@@ -0,0 +1,9 @@
+package main
+
+import "golang.org/x/sys/unix"
+
+// 端末の設定を取得・変更するioctlの要求
+const (
+	ioctlGetTermios = unix.TCGETS
+	ioctlSetTermios = unix.TCSETS
+)
@@ -0,0 +1,11 @@
+//go:build !(linux || darwin || dragonfly || freebsd || netbsd || openbsd)
+
+package main
+
+import "errors"
+
+// makeRaw はこのOSでは対応していないため，常にエラーを返す．
+// liveサブコマンドは，キーを押した後にEnterで入力を受け付ける．
+func makeRaw(fd int) (func(), error) {
+	return nil, errors.New("raw terminal mode is not supported on this platform")
+}
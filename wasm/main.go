@@ -0,0 +1,144 @@
+//go:build js && wasm
+
+// Command wasm はブラウザ上で変換とバリデーションを行うためのWebAssembly版です．
+// ファイルシステムには触れず，YAMLの内容を受け取って変換結果を返します．
+//
+// ビルド方法:
+//
+//	GOOS=js GOARCH=wasm go build -o quiz.wasm ./wasm
+//	cp "$(go env GOROOT)/lib/wasm/wasm_exec.js" .
+//
+// 読み込むとグローバルオブジェクトquizYAMLに以下の関数が登録されます．
+//
+//	quizYAML.convert(yaml, format, options) // => {output, text, contentType} または {error}
+//	quizYAML.validate(yaml, lang)           // => {valid, items, errors: [{index, field, message}]}
+//	quizYAML.formats()                      // => ["csv", "html", ...]
+//
+// yamlには文字列またはUint8Arrayを渡せます．optionsはserveの/convertの
+// クエリパラメータと同じ名前のキーを持つオブジェクトです（例: {columns: "question,answer"}）．
+package main
+
+import (
+	"syscall/js"
+
+	"github.com/m-uesaka/quiz-yaml-go/quiz_yaml_converter"
+)
+
+func main() {
+	api := js.Global().Get("Object").New()
+	api.Set("convert", js.FuncOf(convert))
+	api.Set("validate", js.FuncOf(validate))
+	api.Set("formats", js.FuncOf(formats))
+	js.Global().Set("quizYAML", api)
+
+	// Goのプログラムが終了すると関数を呼び出せなくなるため待ち続ける
+	select {}
+}
+
+// convert は quizYAML.convert(yaml, format, options) の実装．
+func convert(this js.Value, args []js.Value) any {
+	format := "csv"
+	if len(args) > 1 && args[1].Type() == js.TypeString && args[1].String() != "" {
+		format = args[1].String()
+	}
+	// 外部コマンドを実行するexec:はブラウザでは使えないため，登録済みの出力形式のみ受け付ける
+	f, err := quiz_yaml_converter.LookupFormatter(format)
+	if err != nil {
+		return errorResult(err)
+	}
+	var params map[string][]string
+	if len(args) > 2 {
+		params = objectToParams(args[2])
+	}
+	opts, err := quiz_yaml_converter.ParseConvertOptions(params)
+	if err != nil {
+		return errorResult(err)
+	}
+
+	out, err := quiz_yaml_converter.ConvertBytes(inputBytes(args), quiz_yaml_converter.OutputFormat(format), opts)
+	if err != nil {
+		return errorResult(err)
+	}
+	output := js.Global().Get("Uint8Array").New(len(out))
+	js.CopyBytesToJS(output, out)
+	return map[string]any{
+		"output":      output,
+		"text":        string(out),
+		"contentType": quiz_yaml_converter.ContentType(f, opts),
+	}
+}
+
+// validate は quizYAML.validate(yaml, lang) の実装．
+// 戻り値の形式はserveの/validateと同じ．
+func validate(this js.Value, args []js.Value) any {
+	lang := quiz_yaml_converter.LanguageJapanese
+	if len(args) > 1 && args[1].Type() == js.TypeString {
+		var err error
+		if lang, err = quiz_yaml_converter.ParseLanguage(args[1].String()); err != nil {
+			return errorResult(err)
+		}
+	}
+
+	result := quiz_yaml_converter.ValidateYAML(inputBytes(args))
+
+	errors := make([]any, 0, len(result.ValidationErrors))
+	for _, e := range result.ValidationErrors {
+		errors = append(errors, map[string]any{
+			"index":   e.Index,
+			"field":   e.Field,
+			"message": e.LocalizedMessage(lang),
+		})
+	}
+	return map[string]any{
+		"valid":  result.IsValid,
+		"items":  result.Items,
+		"errors": errors,
+	}
+}
+
+// formats は quizYAML.formats() の実装．
+func formats(this js.Value, args []js.Value) any {
+	names := quiz_yaml_converter.FormatterNames()
+	list := make([]any, len(names))
+	for i, name := range names {
+		list[i] = name
+	}
+	return list
+}
+
+// inputBytes は最初の引数（文字列またはUint8Array）をバイト列として返す．
+func inputBytes(args []js.Value) []byte {
+	if len(args) == 0 {
+		return nil
+	}
+	v := args[0]
+	if v.Type() == js.TypeString {
+		return []byte(v.String())
+	}
+	if v.InstanceOf(js.Global().Get("Uint8Array")) {
+		b := make([]byte, v.Get("length").Int())
+		js.CopyBytesToGo(b, v)
+		return b
+	}
+	return nil
+}
+
+// objectToParams はJavaScriptのオブジェクトをParseConvertOptionsに渡せる形に変換する．
+// 値は文字列に変換する（trueは"true"，10は"10"となる）．
+func objectToParams(obj js.Value) map[string][]string {
+	if obj.Type() != js.TypeObject {
+		return nil
+	}
+	params := map[string][]string{}
+	keys := js.Global().Get("Object").Call("keys", obj)
+	for i := 0; i < keys.Length(); i++ {
+		key := keys.Index(i).String()
+		params[key] = []string{js.Global().Call("String", obj.Get(key)).String()}
+	}
+	return params
+}
+
+// errorResult はエラーを{error: "..."}形式で返す．
+func errorResult(err error) any {
+	return map[string]any{"error": err.Error()}
+}